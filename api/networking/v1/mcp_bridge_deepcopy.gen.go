@@ -68,6 +68,27 @@ func (in *RegistryConfig_VPort) DeepCopyInterface() interface{} {
 	return in.DeepCopy()
 }
 
+// DeepCopyInto supports using RegistryConfig_HealthCheck within kubernetes types, where deepcopy-gen is used.
+func (in *RegistryConfig_HealthCheck) DeepCopyInto(out *RegistryConfig_HealthCheck) {
+	p := proto.Clone(in).(*RegistryConfig_HealthCheck)
+	*out = *p
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryConfig_HealthCheck. Required by controller-gen.
+func (in *RegistryConfig_HealthCheck) DeepCopy() *RegistryConfig_HealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryConfig_HealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInterface is an autogenerated deepcopy function, copying the receiver, creating a new RegistryConfig_HealthCheck. Required by controller-gen.
+func (in *RegistryConfig_HealthCheck) DeepCopyInterface() interface{} {
+	return in.DeepCopy()
+}
+
 // DeepCopyInto supports using RegistryConfig_VPort_Services within kubernetes types, where deepcopy-gen is used.
 func (in *RegistryConfig_VPort_Services) DeepCopyInto(out *RegistryConfig_VPort_Services) {
 	p := proto.Clone(in).(*RegistryConfig_VPort_Services)
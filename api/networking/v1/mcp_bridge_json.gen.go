@@ -39,6 +39,17 @@ func (this *RegistryConfig_VPort) UnmarshalJSON(b []byte) error {
 	return McpBridgeUnmarshaler.Unmarshal(bytes.NewReader(b), this)
 }
 
+// MarshalJSON is a custom marshaler for RegistryConfig_HealthCheck
+func (this *RegistryConfig_HealthCheck) MarshalJSON() ([]byte, error) {
+	str, err := McpBridgeMarshaler.MarshalToString(this)
+	return []byte(str), err
+}
+
+// UnmarshalJSON is a custom unmarshaler for RegistryConfig_HealthCheck
+func (this *RegistryConfig_HealthCheck) UnmarshalJSON(b []byte) error {
+	return McpBridgeUnmarshaler.Unmarshal(bytes.NewReader(b), this)
+}
+
 // MarshalJSON is a custom marshaler for RegistryConfig_VPort_Services
 func (this *RegistryConfig_VPort_Services) MarshalJSON() ([]byte, error) {
 	str, err := McpBridgeMarshaler.MarshalToString(this)
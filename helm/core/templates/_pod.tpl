@@ -158,6 +158,12 @@ template:
           hostPort:  {{ .Values.gateway.httpsPort }}
           name: https
           protocol: TCP
+        {{- if .Values.gateway.http3.enabled }}
+        - containerPort:  {{ .Values.gateway.httpsPort }}
+          hostPort:  {{ .Values.gateway.httpsPort }}
+          name: http3
+          protocol: UDP
+        {{- end }}
         {{- end }}
         readinessProbe:
           failureThreshold: {{ .Values.gateway.readinessFailureThreshold }}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alibaba/higress/v2/pkg/cmd/options"
+	"github.com/alibaba/higress/v2/pkg/ingress/render"
+	higresskube "github.com/alibaba/higress/v2/pkg/kube"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// renderArgs are the flags of the translation, which should be the same as the controller of the cluster
+type renderArgs struct {
+	ingressClass         string
+	watchNamespace       string
+	systemNamespace      string
+	gatewaySelectorKey   string
+	gatewaySelectorValue string
+	gatewayHttpPort      uint32
+	gatewayHttpsPort     uint32
+	timeout              time.Duration
+}
+
+func NewCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Render the gateway configuration of manifests offline",
+		Long: `Run the translation of Higress offline, from the Ingress, Gateway API and Higress resources to the Istio
+configs and then to the listeners, routes and clusters of the gateway, without applying the manifests.`,
+	}
+
+	configCmd.AddCommand(newRenderCommand())
+	configCmd.AddCommand(newDiffCommand())
+
+	return configCmd
+}
+
+func addRenderFlags(flags *pflag.FlagSet, args *renderArgs) {
+	defaults := render.DefaultOptions()
+	flags.StringVar(&args.ingressClass, "ingress-class", defaults.IngressClass,
+		"Only translate the ingresses of the ingress class, all the ingresses if empty")
+	flags.StringVar(&args.watchNamespace, "watch-namespace", "",
+		"Only translate the resources in the namespace, all the namespaces if empty")
+	flags.StringVar(&args.systemNamespace, "system-namespace", defaults.SystemNamespace, "Namespace where Higress is installed")
+	flags.StringVar(&args.gatewaySelectorKey, "gateway-selector-key", defaults.GatewaySelectorKey, "Label key of the gateway pods")
+	flags.StringVar(&args.gatewaySelectorValue, "gateway-selector-value", defaults.GatewaySelectorValue, "Label value of the gateway pods")
+	flags.Uint32Var(&args.gatewayHttpPort, "gateway-http-port", defaults.GatewayHttpPort, "HTTP port of the gateway")
+	flags.Uint32Var(&args.gatewayHttpsPort, "gateway-https-port", defaults.GatewayHttpsPort, "HTTPS port of the gateway")
+	flags.DurationVar(&args.timeout, "timeout", defaults.Timeout, "Timeout of the translation")
+}
+
+func (a *renderArgs) options() render.Options {
+	return render.Options{
+		IngressClass:         a.ingressClass,
+		WatchNamespace:       a.watchNamespace,
+		SystemNamespace:      a.systemNamespace,
+		GatewaySelectorKey:   a.gatewaySelectorKey,
+		GatewaySelectorValue: a.gatewaySelectorValue,
+		GatewayHttpPort:      a.gatewayHttpPort,
+		GatewayHttpsPort:     a.gatewayHttpsPort,
+		Timeout:              a.timeout,
+	}
+}
+
+// renderDir renders the manifests in the directory, the files failed to load are printed as warnings.
+func renderDir(dir string, args *renderArgs, errW io.Writer) (*render.Result, error) {
+	result, err := render.RenderDir(dir, args.options())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to render %s", dir)
+	}
+	for _, file := range result.Files {
+		if file.Error != "" {
+			fmt.Fprintf(errW, "Warning: failed to load %s: %s\n", file.Path, file.Error)
+		}
+	}
+	return result, nil
+}
+
+// renderCluster renders the resources in the cluster of the kubeconfig, the resources are only read.
+func renderCluster(args *renderArgs) (*render.Result, error) {
+	client, err := higresskube.NewClient(options.DefaultConfigFlags.ToRawKubeConfigLoader(), "higress")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build kubernetes client")
+	}
+	result, err := render.Render(higresskube.EnableCrdWatcher(client), args.options())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the cluster")
+	}
+	return result, nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pkg/util/protomarshal"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/alibaba/higress/v2/pkg/cmd/options"
+	"github.com/alibaba/higress/v2/pkg/ingress/render"
+)
+
+const liveClusterName = "cluster"
+
+func newDiffCommand() *cobra.Command {
+	args := &renderArgs{}
+	var baseDir, dir string
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show the changes of the listeners, routes and clusters of the gateway made by manifests",
+		Long: `Render the manifests and compare the listeners, routes and clusters with the ones rendered from the base
+manifests, or from the resources of the live cluster if no base is specified. Both sides are rendered offline
+in the same way, so the differences are made by the manifests only.`,
+		Example: `  # Compare two manifest sets
+  hgctl config diff --base main/manifests/ -f manifests/
+
+  # Compare the manifests with the live cluster
+  hgctl config diff -f manifests/`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmdutil.CheckErr(runDiff(cmd.OutOrStdout(), cmd.ErrOrStderr(), baseDir, dir, args))
+		},
+	}
+
+	flags := diffCmd.Flags()
+	options.AddKubeConfigFlags(flags)
+	flags.StringVar(&baseDir, "base", "", "Directory of the base manifests, the live cluster is the base if not specified")
+	flags.StringVarP(&dir, "filename", "f", "", "Directory of the manifests to compare")
+	addRenderFlags(flags, args)
+	_ = diffCmd.MarkFlagRequired("filename")
+
+	return diffCmd
+}
+
+func runDiff(w, errW io.Writer, baseDir, dir string, args *renderArgs) error {
+	var base *render.Result
+	var err error
+	baseName := baseDir
+	if baseDir == "" {
+		baseName = liveClusterName
+		base, err = renderCluster(args)
+	} else {
+		base, err = renderDir(baseDir, args, errW)
+	}
+	if err != nil {
+		return err
+	}
+	target, err := renderDir(dir, args, errW)
+	if err != nil {
+		return err
+	}
+
+	baseResources, err := resourcesOf(base)
+	if err != nil {
+		return err
+	}
+	targetResources, err := resourcesOf(target)
+	if err != nil {
+		return err
+	}
+	return writeDiff(w, baseName, dir, baseResources, targetResources)
+}
+
+// resourcesOf returns the rendered resources in YAML keyed by type/name.
+func resourcesOf(result *render.Result) (map[string]string, error) {
+	resources := map[string]string{}
+	add := func(typ, name string, msg proto.Message) error {
+		content, err := protomarshal.ToYAML(msg)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s %s", typ, name)
+		}
+		resources[typ+"/"+name] = content
+		return nil
+	}
+	for _, l := range result.Listeners {
+		if err := add(listenerType, l.Name, l); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range result.Routes {
+		if err := add(routeType, r.Name, r); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range result.Clusters {
+		if err := add(clusterType, c.Name, c); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// writeDiff writes the unified diff of every changed resource and a summary of the changes.
+func writeDiff(w io.Writer, baseName, targetName string, base, target map[string]string) error {
+	keys := make([]string, 0, len(base)+len(target))
+	for key := range base {
+		keys = append(keys, key)
+	}
+	for key := range target {
+		if _, ok := base[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var added, removed, changed int
+	for _, key := range keys {
+		baseContent, inBase := base[key]
+		targetContent, inTarget := target[key]
+		switch {
+		case !inBase:
+			added++
+		case !inTarget:
+			removed++
+		case baseContent == targetContent:
+			continue
+		default:
+			changed++
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(baseContent),
+			B:        splitLines(targetContent),
+			FromFile: baseName + "/" + key,
+			ToFile:   targetName + "/" + key,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintln(w, diff); err != nil {
+			return err
+		}
+	}
+	if added+removed+changed == 0 {
+		_, err := fmt.Fprintln(w, "No differences.")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d changed.\n", added, removed, changed)
+	return err
+}
+
+// splitLines splits the content into lines ending with a newline, there is no empty line at the end unlike
+// difflib.SplitLines.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
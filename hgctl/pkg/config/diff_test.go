@@ -0,0 +1,62 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDiff(t *testing.T) {
+	base := map[string]string{
+		"cluster/outbound|80||foo.default.svc.cluster.local": "name: foo\n",
+		"cluster/outbound|80||bar.default.svc.cluster.local": "name: bar\n",
+		"route/http.80": "name: http.80\ntimeout: 5s\n",
+	}
+	target := map[string]string{
+		"cluster/outbound|80||foo.default.svc.cluster.local": "name: foo\n",
+		"cluster/outbound|80||baz.default.svc.cluster.local": "name: baz\n",
+		"route/http.80": "name: http.80\ntimeout: 10s\n",
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeDiff(&out, "base", "target", base, target))
+	assert.Equal(t, `--- base/cluster/outbound|80||bar.default.svc.cluster.local
++++ target/cluster/outbound|80||bar.default.svc.cluster.local
+@@ -1 +0,0 @@
+-name: bar
+
+--- base/cluster/outbound|80||baz.default.svc.cluster.local
++++ target/cluster/outbound|80||baz.default.svc.cluster.local
+@@ -0,0 +1 @@
++name: baz
+
+--- base/route/http.80
++++ target/route/http.80
+@@ -1,2 +1,2 @@
+ name: http.80
+-timeout: 5s
++timeout: 10s
+
+1 added, 1 removed, 1 changed.
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeDiff(&out, "base", "target", base, base))
+	assert.Equal(t, "No differences.\n", out.String())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"istio.io/istio/istioctl/pkg/writer/envoy/configdump"
+	"istio.io/istio/pkg/util/protomarshal"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/render"
+)
+
+const (
+	summaryOutput = "short"
+	yamlOutput    = "yaml"
+	jsonOutput    = "json"
+
+	allType      = "all"
+	listenerType = "listener"
+	routeType    = "route"
+	clusterType  = "cluster"
+)
+
+func newRenderCommand() *cobra.Command {
+	args := &renderArgs{}
+	var dir, output, resourceType string
+	renderCmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render the listeners, routes and clusters of the gateway from manifests",
+		Example: `  # Print the summary of the listeners, routes and clusters
+  hgctl config render -f manifests/
+
+  # Print the full routes as YAML
+  hgctl config render -f manifests/ --type route -o yaml`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmdutil.CheckErr(runRender(cmd.OutOrStdout(), cmd.ErrOrStderr(), dir, output, resourceType, args))
+		},
+	}
+
+	flags := renderCmd.Flags()
+	flags.StringVarP(&dir, "filename", "f", "", "Directory of the manifests to render")
+	flags.StringVarP(&output, "output", "o", summaryOutput, "Output format: one of json|yaml|short")
+	flags.StringVar(&resourceType, "type", allType, "Resources to print: one of all|listener|route|cluster")
+	addRenderFlags(flags, args)
+	_ = renderCmd.MarkFlagRequired("filename")
+
+	return renderCmd
+}
+
+func runRender(w, errW io.Writer, dir, output, resourceType string, args *renderArgs) error {
+	if output != summaryOutput && output != yamlOutput && output != jsonOutput {
+		return errors.Errorf("output format %q not supported", output)
+	}
+	if resourceType != allType && resourceType != listenerType && resourceType != routeType && resourceType != clusterType {
+		return errors.Errorf("resource type %q not supported", resourceType)
+	}
+	result, err := renderDir(dir, args, errW)
+	if err != nil {
+		return err
+	}
+	dump, err := marshalConfigDump(result)
+	if err != nil {
+		return err
+	}
+	if resourceType == allType && output != summaryOutput {
+		// The same as `hgctl gateway-config all`
+		if output == yamlOutput {
+			if dump, err = yaml.JSONToYAML(dump); err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintln(w, string(dump))
+		return err
+	}
+
+	configWriter := &configdump.ConfigWriter{Stdout: w}
+	if err = configWriter.Prime(dump); err != nil {
+		return err
+	}
+	if resourceType != allType {
+		return printResources(configWriter, resourceType, output)
+	}
+	for i, typ := range []string{listenerType, routeType, clusterType} {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := printResources(configWriter, typ, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalConfigDump returns the rendered resources in the format of the config dump of Envoy, so that they are
+// printed the same as `hgctl gateway-config` prints a running gateway.
+func marshalConfigDump(result *render.Result) ([]byte, error) {
+	dump, err := result.ConfigDump()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build config dump")
+	}
+	b, err := protomarshal.Marshal(dump)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal config dump")
+	}
+	return b, nil
+}
+
+func printResources(configWriter *configdump.ConfigWriter, resourceType, output string) error {
+	switch resourceType {
+	case listenerType:
+		if output == summaryOutput {
+			return configWriter.PrintListenerSummary(configdump.ListenerFilter{Verbose: true})
+		}
+		return configWriter.PrintListenerDump(configdump.ListenerFilter{Verbose: true}, output)
+	case routeType:
+		if output == summaryOutput {
+			return configWriter.PrintRouteSummary(configdump.RouteFilter{Verbose: true})
+		}
+		return configWriter.PrintRouteDump(configdump.RouteFilter{Verbose: true}, output)
+	default:
+		if output == summaryOutput {
+			return configWriter.PrintClusterSummary(configdump.ClusterFilter{})
+		}
+		return configWriter.PrintClusterDump(configdump.ClusterFilter{}, output)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	nginxAnnotationPrefix   = "nginx.ingress.kubernetes.io/"
+	higressAnnotationPrefix = "higress.io/"
+)
+
+// The annotations converted to Gateway API, see pkg/ingress/kube/annotations for their semantics
+const (
+	canary                = "canary"
+	canaryByHeader        = "canary-by-header"
+	canaryByHeaderValue   = "canary-by-header-value"
+	canaryByHeaderPattern = "canary-by-header-pattern"
+	canaryByCookie        = "canary-by-cookie"
+	canaryWeight          = "canary-weight"
+	canaryWeightTotal     = "canary-weight-total"
+
+	rewritePath   = "rewrite-path"
+	rewriteTarget = "rewrite-target"
+	useRegex      = "use-regex"
+	fullPathRegex = "full-path-regex"
+	upstreamVhost = "upstream-vhost"
+
+	appRoot               = "app-root"
+	temporalRedirect      = "temporal-redirect"
+	permanentRedirect     = "permanent-redirect"
+	permanentRedirectCode = "permanent-redirect-code"
+	sslRedirect           = "ssl-redirect"
+	forceSSLRedirect      = "force-ssl-redirect"
+
+	requestHeaderAdd     = "request-header-control-add"
+	requestHeaderUpdate  = "request-header-control-update"
+	requestHeaderRemove  = "request-header-control-remove"
+	responseHeaderAdd    = "response-header-control-add"
+	responseHeaderUpdate = "response-header-control-update"
+	responseHeaderRemove = "response-header-control-remove"
+
+	mirrorTargetService = "mirror-target-service"
+	mirrorPercentage    = "mirror-percentage"
+
+	timeout = "timeout"
+
+	retryCount      = "proxy-next-upstream-tries"
+	perRetryTimeout = "proxy-next-upstream-timeout"
+	retryOn         = "proxy-next-upstream"
+
+	backendProtocol = "backend-protocol"
+
+	enableCors       = "enable-cors"
+	allowOrigin      = "cors-allow-origin"
+	allowMethods     = "cors-allow-methods"
+	allowHeaders     = "cors-allow-headers"
+	exposeHeaders    = "cors-expose-headers"
+	allowCredentials = "cors-allow-credentials"
+	maxAge           = "cors-max-age"
+
+	whitelist = "whitelist-source-range"
+)
+
+// ingressAnnotations reads the annotations of an ingress like the controller does, and records which of them
+// are read so that the others can be reported as not converted.
+type ingressAnnotations struct {
+	annotations map[string]string
+	used        map[string]bool
+}
+
+func newIngressAnnotations(annotations map[string]string) *ingressAnnotations {
+	return &ingressAnnotations{
+		annotations: annotations,
+		used:        map[string]bool{},
+	}
+}
+
+// get returns the annotation with the nginx prefix, or the higress prefix if absent.
+func (a *ingressAnnotations) get(key string) (string, bool) {
+	if value, ok := a.annotations[nginxAnnotationPrefix+key]; ok {
+		a.used[nginxAnnotationPrefix+key] = true
+		a.used[higressAnnotationPrefix+key] = true
+		return value, true
+	}
+	return a.getHigress(key)
+}
+
+// getHigress returns the annotation which is only supported with the higress prefix.
+func (a *ingressAnnotations) getHigress(key string) (string, bool) {
+	value, ok := a.annotations[higressAnnotationPrefix+key]
+	if ok {
+		a.used[higressAnnotationPrefix+key] = true
+	}
+	return value, ok
+}
+
+func (a *ingressAnnotations) getBool(key string) bool {
+	value, ok := a.get(key)
+	if !ok {
+		return false
+	}
+	b, _ := strconv.ParseBool(value)
+	return b
+}
+
+// getInt returns the integer annotation, ok is false if it is absent or invalid.
+func (a *ingressAnnotations) getInt(key string, higressOnly bool) (int, bool) {
+	var value string
+	var ok bool
+	if higressOnly {
+		value, ok = a.getHigress(key)
+	} else {
+		value, ok = a.get(key)
+	}
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// unused returns the nginx and higress annotations which are not converted.
+func (a *ingressAnnotations) unused() []string {
+	var keys []string
+	for key := range a.annotations {
+		if a.used[key] {
+			continue
+		}
+		if strings.HasPrefix(key, nginxAnnotationPrefix) || strings.HasPrefix(key, higressAnnotationPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitAndTrim(s, sep string) []string {
+	var result []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,942 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	DefaultGatewayName      = "higress-gateway"
+	DefaultGatewayNamespace = "higress-system"
+	DefaultGatewayClass     = "higress-gateway"
+	DefaultPluginRegistry   = "oci://higress-registry.cn-hangzhou.cr.aliyuncs.com/plugins"
+
+	httpListenerName = "http"
+
+	defaultCanaryWeightTotal     = 100
+	defaultPermanentRedirectCode = 301
+	defaultTemporalRedirectCode  = 302
+	defaultRetryCount            = 3
+	defaultCorsMaxAge            = 1728000
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// PortResolver resolves the number of a named service port, which is required by Gateway API backend refs.
+type PortResolver func(namespace, service, port string) (int32, error)
+
+type Options struct {
+	GatewayName      string
+	GatewayNamespace string
+	GatewayClass     string
+	// PluginRegistry is where the images of the wasm plugins replacing the annotations are pulled from
+	PluginRegistry string
+	PortResolver   PortResolver
+}
+
+// Result is the Gateway API resources converted from the ingresses.
+type Result struct {
+	Gateway         *gatewayv1.Gateway
+	ReferenceGrants []*gatewayv1beta1.ReferenceGrant
+	HTTPRoutes      []*gatewayv1.HTTPRoute
+	GRPCRoutes      []*gatewayv1.GRPCRoute
+	WasmPlugins     []*unstructured.Unstructured
+	Report          *Report
+}
+
+// Objects returns all the converted resources in the order to apply them.
+func (r *Result) Objects() []runtime.Object {
+	objects := []runtime.Object{r.Gateway}
+	for _, grant := range r.ReferenceGrants {
+		objects = append(objects, grant)
+	}
+	for _, route := range r.HTTPRoutes {
+		objects = append(objects, route)
+	}
+	for _, route := range r.GRPCRoutes {
+		objects = append(objects, route)
+	}
+	for _, plugin := range r.WasmPlugins {
+		objects = append(objects, plugin)
+	}
+	return objects
+}
+
+type ruleKey struct {
+	host     string
+	pathType gatewayv1.PathMatchType
+	path     string
+}
+
+type ruleRef struct {
+	route *gatewayv1.HTTPRoute
+	index int
+}
+
+// pluginRule is a match rule of the WasmPlugin replacing the annotations
+type pluginRule struct {
+	route     string
+	routeType string
+	config    map[string]interface{}
+}
+
+type converter struct {
+	opts            Options
+	result          *Result
+	report          *Report
+	referenceGrants map[string]*gatewayv1beta1.ReferenceGrant
+	rules           map[ruleKey]ruleRef
+	pluginRules     map[string][]pluginRule
+}
+
+// Convert converts the ingresses into Gateway API resources attached to one Gateway. The annotations that have no
+// equivalent in Gateway API are converted into WasmPlugins if possible, the others are listed in the report.
+func Convert(ingresses []networkingv1.Ingress, opts Options) *Result {
+	if opts.GatewayName == "" {
+		opts.GatewayName = DefaultGatewayName
+	}
+	if opts.GatewayNamespace == "" {
+		opts.GatewayNamespace = DefaultGatewayNamespace
+	}
+	if opts.GatewayClass == "" {
+		opts.GatewayClass = DefaultGatewayClass
+	}
+	if opts.PluginRegistry == "" {
+		opts.PluginRegistry = DefaultPluginRegistry
+	}
+	c := &converter{
+		opts:            opts,
+		report:          &Report{},
+		referenceGrants: map[string]*gatewayv1beta1.ReferenceGrant{},
+		rules:           map[ruleKey]ruleRef{},
+		pluginRules:     map[string][]pluginRule{},
+	}
+	c.result = &Result{
+		Gateway: &gatewayv1.Gateway{
+			TypeMeta: metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "Gateway"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      opts.GatewayName,
+				Namespace: opts.GatewayNamespace,
+			},
+			Spec: gatewayv1.GatewaySpec{
+				GatewayClassName: gatewayv1.ObjectName(opts.GatewayClass),
+				Listeners: []gatewayv1.Listener{{
+					Name:          httpListenerName,
+					Port:          80,
+					Protocol:      gatewayv1.HTTPProtocolType,
+					AllowedRoutes: allowedRoutesFromAll(),
+				}},
+			},
+		},
+		Report: c.report,
+	}
+
+	// Convert in the same order as the controller, the older ingress wins on conflicts
+	sorted := make([]*networkingv1.Ingress, 0, len(ingresses))
+	for i := range ingresses {
+		sorted = append(sorted, &ingresses[i])
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+		}
+		return sorted[i].Namespace+"/"+sorted[i].Name < sorted[j].Namespace+"/"+sorted[j].Name
+	})
+
+	var canaries []*networkingv1.Ingress
+	for _, ing := range sorted {
+		if isCanary(ing) {
+			canaries = append(canaries, ing)
+			continue
+		}
+		c.convertIngress(ing)
+	}
+	for _, ing := range canaries {
+		c.convertCanary(ing)
+	}
+
+	c.buildWasmPlugins()
+	grantKeys := make([]string, 0, len(c.referenceGrants))
+	for key := range c.referenceGrants {
+		grantKeys = append(grantKeys, key)
+	}
+	sort.Strings(grantKeys)
+	for _, key := range grantKeys {
+		c.result.ReferenceGrants = append(c.result.ReferenceGrants, c.referenceGrants[key])
+	}
+	return c.result
+}
+
+func isCanary(ing *networkingv1.Ingress) bool {
+	return newIngressAnnotations(ing.Annotations).getBool(canary)
+}
+
+func ingressName(ing *networkingv1.Ingress) string {
+	return ing.Namespace + "/" + ing.Name
+}
+
+func allowedRoutesFromAll() *gatewayv1.AllowedRoutes {
+	from := gatewayv1.NamespacesFromAll
+	return &gatewayv1.AllowedRoutes{
+		Namespaces: &gatewayv1.RouteNamespaces{From: &from},
+	}
+}
+
+func (c *converter) convertIngress(ing *networkingv1.Ingress) {
+	name := ingressName(ing)
+	a := newIngressAnnotations(ing.Annotations)
+	a.get(canary)
+
+	grpc := false
+	if protocol, ok := a.get(backendProtocol); ok {
+		switch strings.ToUpper(protocol) {
+		case "HTTP":
+		case "GRPC":
+			grpc = true
+		case "GRPCS":
+			grpc = true
+			c.report.add(name, backendProtocol, "TLS to the backend is not converted, create a BackendTLSPolicy for the service")
+		case "HTTPS":
+			c.report.add(name, backendProtocol, "TLS to the backend is not converted, create a BackendTLSPolicy for the service")
+		default:
+			c.report.add(name, backendProtocol, "backend protocol %s is not converted, set the appProtocol of the service port instead", protocol)
+		}
+	}
+
+	httpsListeners := c.convertTLS(ing)
+	httpsRedirect := a.getBool(sslRedirect) || a.getBool(forceSSLRedirect)
+
+	var routeNames []string
+	for i, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			c.report.add(name, fmt.Sprintf("spec.rules[%d]", i), "rule without http paths is skipped")
+			continue
+		}
+		routeName := ing.Name
+		if len(ing.Spec.Rules) > 1 {
+			routeName = fmt.Sprintf("%s-%d", ing.Name, i)
+		}
+		parentRefs := c.parentRefs(ing, rule.Host, httpsListeners, httpsRedirect, routeName)
+		if grpc {
+			c.convertGRPCRoute(ing, a, routeName, rule.Host, rule.HTTP.Paths, parentRefs)
+		} else {
+			c.convertHTTPRoute(ing, a, routeName, rule.Host, rule.HTTP.Paths, parentRefs)
+		}
+		routeNames = append(routeNames, routeName)
+	}
+	if backend := ing.Spec.DefaultBackend; backend != nil {
+		routeName := ing.Name + "-default-backend"
+		prefix := networkingv1.PathTypePrefix
+		paths := []networkingv1.HTTPIngressPath{{Path: "/", PathType: &prefix, Backend: *backend}}
+		parentRefs := []gatewayv1.ParentReference{c.parentRef("")}
+		if grpc {
+			c.convertGRPCRoute(ing, a, routeName, "", paths, parentRefs)
+		} else {
+			c.convertHTTPRoute(ing, a, routeName, "", paths, parentRefs)
+		}
+		routeNames = append(routeNames, routeName)
+	}
+
+	routeType := "HTTP"
+	if grpc {
+		routeType = "GRPC"
+	}
+	c.convertPlugins(ing, a, routeNames, routeType)
+
+	for _, key := range a.unused() {
+		c.report.add(name, key, "annotation is not converted, configure it with a Higress plugin or policy manually")
+	}
+}
+
+// convertTLS adds the HTTPS listeners of the TLS hosts of the ingress, and returns the listener names by host.
+func (c *converter) convertTLS(ing *networkingv1.Ingress) map[string]gatewayv1.SectionName {
+	listeners := map[string]gatewayv1.SectionName{}
+	for i, tls := range ing.Spec.TLS {
+		if tls.SecretName == "" {
+			c.report.add(ingressName(ing), fmt.Sprintf("spec.tls[%d]", i), "TLS without secret is skipped")
+			continue
+		}
+		for _, host := range tls.Hosts {
+			listenerName := gatewayv1.SectionName("https-" + strings.Trim(invalidNameChars.ReplaceAllString(
+				strings.ReplaceAll(strings.ToLower(host), "*", "wildcard"), "-"), "-"))
+			listeners[host] = listenerName
+			if c.hasListener(listenerName) {
+				continue
+			}
+			hostname := gatewayv1.Hostname(host)
+			namespace := gatewayv1.Namespace(ing.Namespace)
+			mode := gatewayv1.TLSModeTerminate
+			c.result.Gateway.Spec.Listeners = append(c.result.Gateway.Spec.Listeners, gatewayv1.Listener{
+				Name:     listenerName,
+				Hostname: &hostname,
+				Port:     443,
+				Protocol: gatewayv1.HTTPSProtocolType,
+				TLS: &gatewayv1.ListenerTLSConfig{
+					Mode: &mode,
+					CertificateRefs: []gatewayv1.SecretObjectReference{{
+						Name:      gatewayv1.ObjectName(tls.SecretName),
+						Namespace: &namespace,
+					}},
+				},
+				AllowedRoutes: allowedRoutesFromAll(),
+			})
+			if ing.Namespace != c.opts.GatewayNamespace {
+				c.addReferenceGrant(ing.Namespace, gatewayv1.GroupName, "Gateway", c.opts.GatewayNamespace, "Secret")
+			}
+		}
+	}
+	return listeners
+}
+
+func (c *converter) hasListener(name gatewayv1.SectionName) bool {
+	for _, listener := range c.result.Gateway.Spec.Listeners {
+		if listener.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addReferenceGrant allows the kind of fromGroup in fromNamespace to refer to the core kind toKind in namespace.
+func (c *converter) addReferenceGrant(namespace, fromGroup, fromKind, fromNamespace, toKind string) {
+	key := namespace + "/" + strings.ToLower(fromKind) + "-" + fromNamespace + "-" + strings.ToLower(toKind)
+	if _, ok := c.referenceGrants[key]; ok {
+		return
+	}
+	c.referenceGrants[key] = &gatewayv1beta1.ReferenceGrant{
+		TypeMeta: metav1.TypeMeta{APIVersion: gatewayv1beta1.GroupVersion.String(), Kind: "ReferenceGrant"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.ToLower(fromKind) + "-" + fromNamespace + "-" + strings.ToLower(toKind),
+			Namespace: namespace,
+		},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     gatewayv1.Group(fromGroup),
+				Kind:      gatewayv1.Kind(fromKind),
+				Namespace: gatewayv1.Namespace(fromNamespace),
+			}},
+			To: []gatewayv1beta1.ReferenceGrantTo{{
+				Kind: gatewayv1.Kind(toKind),
+			}},
+		},
+	}
+}
+
+func (c *converter) parentRef(section gatewayv1.SectionName) gatewayv1.ParentReference {
+	namespace := gatewayv1.Namespace(c.opts.GatewayNamespace)
+	ref := gatewayv1.ParentReference{
+		Name:      gatewayv1.ObjectName(c.opts.GatewayName),
+		Namespace: &namespace,
+	}
+	if section != "" {
+		ref.SectionName = &section
+	}
+	return ref
+}
+
+// parentRefs attaches the route to all the listeners, or only the HTTPS listener if the HTTP requests are
+// redirected to HTTPS, in which case a route redirecting the HTTP requests is added.
+func (c *converter) parentRefs(ing *networkingv1.Ingress, host string,
+	httpsListeners map[string]gatewayv1.SectionName, httpsRedirect bool, routeName string) []gatewayv1.ParentReference {
+	if !httpsRedirect {
+		return []gatewayv1.ParentReference{c.parentRef("")}
+	}
+	listener, ok := httpsListeners[host]
+	if !ok {
+		c.report.add(ingressName(ing), sslRedirect, "host %q has no TLS, HTTPS redirect is skipped", host)
+		return []gatewayv1.ParentReference{c.parentRef("")}
+	}
+
+	scheme := "https"
+	statusCode := defaultPermanentRedirectCode
+	redirect := &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "HTTPRoute"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      routeName + "-https-redirect",
+			Namespace: ing.Namespace,
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{c.parentRef(httpListenerName)},
+			},
+			Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(host)},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				Filters: []gatewayv1.HTTPRouteFilter{{
+					Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+						Scheme:     &scheme,
+						StatusCode: &statusCode,
+					},
+				}},
+			}},
+		},
+	}
+	c.result.HTTPRoutes = append(c.result.HTTPRoutes, redirect)
+	return []gatewayv1.ParentReference{c.parentRef(listener)}
+}
+
+func (c *converter) convertHTTPRoute(ing *networkingv1.Ingress, a *ingressAnnotations, routeName, host string,
+	paths []networkingv1.HTTPIngressPath, parentRefs []gatewayv1.ParentReference) {
+	route := &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "HTTPRoute"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      routeName,
+			Namespace: ing.Namespace,
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs},
+		},
+	}
+	if host != "" {
+		route.Spec.Hostnames = []gatewayv1.Hostname{gatewayv1.Hostname(host)}
+	}
+
+	timeouts := c.convertTimeouts(a)
+	retry := c.convertRetry(ing, a)
+	for i, path := range paths {
+		match := c.convertPathMatch(a, path)
+		rule := gatewayv1.HTTPRouteRule{
+			Matches:  []gatewayv1.HTTPRouteMatch{{Path: match}},
+			Timeouts: timeouts,
+			Retry:    retry,
+		}
+		filters, redirect := c.convertFilters(ing, a, match)
+		rule.Filters = filters
+		if !redirect {
+			backendRef, ok := c.convertBackend(ing, fmt.Sprintf("spec.rules.http.paths[%d].backend", i), path.Backend)
+			if !ok {
+				continue
+			}
+			rule.BackendRefs = []gatewayv1.HTTPBackendRef{{BackendRef: backendRef}}
+		}
+		key := ruleKey{host: host, pathType: *match.Type, path: *match.Value}
+		if existing, ok := c.rules[key]; ok {
+			c.report.add(ingressName(ing), "spec.rules.http.paths", "path %s of host %q is already defined by HTTPRoute %s/%s",
+				*match.Value, host, existing.route.Namespace, existing.route.Name)
+		} else {
+			c.rules[key] = ruleRef{route: route, index: len(route.Spec.Rules)}
+		}
+		route.Spec.Rules = append(route.Spec.Rules, rule)
+	}
+
+	if root, ok := a.get(appRoot); ok && root != "" {
+		exact := gatewayv1.PathMatchExact
+		slash := "/"
+		statusCode := defaultTemporalRedirectCode
+		route.Spec.Rules = append(route.Spec.Rules, gatewayv1.HTTPRouteRule{
+			Matches: []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &exact, Value: &slash}}},
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+					Path: &gatewayv1.HTTPPathModifier{
+						Type:            gatewayv1.FullPathHTTPPathModifier,
+						ReplaceFullPath: &root,
+					},
+					StatusCode: &statusCode,
+				},
+			}},
+		})
+	}
+	if len(route.Spec.Rules) > 0 {
+		c.result.HTTPRoutes = append(c.result.HTTPRoutes, route)
+	}
+}
+
+func (c *converter) convertPathMatch(a *ingressAnnotations, path networkingv1.HTTPIngressPath) *gatewayv1.HTTPPathMatch {
+	value := path.Path
+	if value == "" {
+		value = "/"
+	}
+	matchType := gatewayv1.PathMatchPathPrefix
+	if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+		matchType = gatewayv1.PathMatchExact
+	}
+	if full, ok := a.getHigress(fullPathRegex); ok && full == "true" {
+		matchType = gatewayv1.PathMatchRegularExpression
+	} else if a.getBool(useRegex) {
+		matchType = gatewayv1.PathMatchRegularExpression
+		value += ".*"
+	}
+	return &gatewayv1.HTTPPathMatch{Type: &matchType, Value: &value}
+}
+
+func (c *converter) convertBackend(ing *networkingv1.Ingress, field string, backend networkingv1.IngressBackend) (gatewayv1.BackendRef, bool) {
+	if backend.Service == nil {
+		c.report.add(ingressName(ing), field, "resource backend is not supported by Gateway API")
+		return gatewayv1.BackendRef{}, false
+	}
+	port := backend.Service.Port.Number
+	if port == 0 {
+		if c.opts.PortResolver == nil {
+			c.report.add(ingressName(ing), field, "port %s of service %s is referred by name, Gateway API requires the port number",
+				backend.Service.Port.Name, backend.Service.Name)
+			return gatewayv1.BackendRef{}, false
+		}
+		number, err := c.opts.PortResolver(ing.Namespace, backend.Service.Name, backend.Service.Port.Name)
+		if err != nil {
+			c.report.add(ingressName(ing), field, "failed to resolve port %s of service %s: %v",
+				backend.Service.Port.Name, backend.Service.Name, err)
+			return gatewayv1.BackendRef{}, false
+		}
+		port = number
+	}
+	portNumber := gatewayv1.PortNumber(port)
+	return gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{
+			Name: gatewayv1.ObjectName(backend.Service.Name),
+			Port: &portNumber,
+		},
+	}, true
+}
+
+// convertFilters converts the annotations applied to the matched route, redirect tells whether the requests are
+// redirected instead of being forwarded to the backend.
+func (c *converter) convertFilters(ing *networkingv1.Ingress, a *ingressAnnotations, match *gatewayv1.HTTPPathMatch) ([]gatewayv1.HTTPRouteFilter, bool) {
+	var filters []gatewayv1.HTTPRouteFilter
+	if filter := c.convertRedirect(ing, a); filter != nil {
+		return append(filters, *filter), true
+	}
+	if filter := c.convertRewrite(ing, a, match); filter != nil {
+		filters = append(filters, *filter)
+	}
+	if modifier := headerModifier(a, requestHeaderAdd, requestHeaderUpdate, requestHeaderRemove); modifier != nil {
+		filters = append(filters, gatewayv1.HTTPRouteFilter{
+			Type:                  gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: modifier,
+		})
+	}
+	if modifier := headerModifier(a, responseHeaderAdd, responseHeaderUpdate, responseHeaderRemove); modifier != nil {
+		filters = append(filters, gatewayv1.HTTPRouteFilter{
+			Type:                   gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: modifier,
+		})
+	}
+	if filter := c.convertMirror(ing, a); filter != nil {
+		filters = append(filters, *filter)
+	}
+	return filters, false
+}
+
+func (c *converter) convertRedirect(ing *networkingv1.Ingress, a *ingressAnnotations) *gatewayv1.HTTPRouteFilter {
+	target, code := "", defaultTemporalRedirectCode
+	if tr, ok := a.get(temporalRedirect); ok && tr != "" {
+		target = tr
+	} else if pr, ok := a.get(permanentRedirect); ok && pr != "" {
+		target, code = pr, defaultPermanentRedirectCode
+		if prc, ok := a.getInt(permanentRedirectCode, false); ok {
+			switch prc {
+			case 301, 302:
+				code = prc
+			case 308:
+				c.report.add(ingressName(ing), permanentRedirectCode, "status code 308 is converted to 301 which Gateway API supports")
+			default:
+				c.report.add(ingressName(ing), permanentRedirectCode, "status code %d is converted to 302 which Gateway API supports", prc)
+				code = defaultTemporalRedirectCode
+			}
+		}
+	} else {
+		a.getInt(permanentRedirectCode, false)
+		return nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || !strings.HasPrefix(u.Scheme, "http") {
+		c.report.add(ingressName(ing), "redirect", "invalid redirect url %s", target)
+		return nil
+	}
+	redirect := &gatewayv1.HTTPRequestRedirectFilter{
+		Scheme:     &u.Scheme,
+		StatusCode: &code,
+	}
+	if hostname := u.Hostname(); hostname != "" {
+		preciseHostname := gatewayv1.PreciseHostname(hostname)
+		redirect.Hostname = &preciseHostname
+	}
+	if port := u.Port(); port != "" {
+		number, _ := strconv.Atoi(port)
+		portNumber := gatewayv1.PortNumber(number)
+		redirect.Port = &portNumber
+	}
+	if u.Path != "" {
+		redirect.Path = &gatewayv1.HTTPPathModifier{
+			Type:            gatewayv1.FullPathHTTPPathModifier,
+			ReplaceFullPath: &u.Path,
+		}
+	}
+	if u.RawQuery != "" {
+		c.report.add(ingressName(ing), "redirect", "query string of redirect url %s is dropped", target)
+	}
+	return &gatewayv1.HTTPRouteFilter{
+		Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+		RequestRedirect: redirect,
+	}
+}
+
+func (c *converter) convertRewrite(ing *networkingv1.Ingress, a *ingressAnnotations, match *gatewayv1.HTTPPathMatch) *gatewayv1.HTTPRouteFilter {
+	rewrite := &gatewayv1.HTTPURLRewriteFilter{}
+	path, _ := a.getHigress(rewritePath)
+	if target, ok := a.get(rewriteTarget); ok && target != "" && path == "" {
+		if strings.ContainsAny(target, `$\`) {
+			c.report.add(ingressName(ing), rewriteTarget, "rewrite with capture groups %s is not supported by Gateway API", target)
+		} else {
+			path = target
+		}
+	}
+	if path != "" {
+		switch *match.Type {
+		case gatewayv1.PathMatchExact:
+			rewrite.Path = &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: &path}
+		case gatewayv1.PathMatchPathPrefix:
+			rewrite.Path = &gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: &path}
+		default:
+			c.report.add(ingressName(ing), "rewrite", "rewrite of regular expression path %s is not supported by Gateway API", *match.Value)
+		}
+	}
+	if host, ok := a.get(upstreamVhost); ok && host != "" {
+		hostname := gatewayv1.PreciseHostname(host)
+		rewrite.Hostname = &hostname
+	}
+	if rewrite.Path == nil && rewrite.Hostname == nil {
+		return nil
+	}
+	return &gatewayv1.HTTPRouteFilter{
+		Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+		URLRewrite: rewrite,
+	}
+}
+
+func headerModifier(a *ingressAnnotations, addKey, updateKey, removeKey string) *gatewayv1.HTTPHeaderFilter {
+	modifier := &gatewayv1.HTTPHeaderFilter{}
+	if add, ok := a.getHigress(addKey); ok {
+		modifier.Add = parseHeaders(add)
+	}
+	if update, ok := a.getHigress(updateKey); ok {
+		modifier.Set = parseHeaders(update)
+	}
+	if remove, ok := a.getHigress(removeKey); ok {
+		modifier.Remove = splitAndTrim(remove, ",")
+	}
+	if len(modifier.Add) == 0 && len(modifier.Set) == 0 && len(modifier.Remove) == 0 {
+		return nil
+	}
+	return modifier
+}
+
+// parseHeaders parses the headers in the format of one "name value" per line
+func parseHeaders(headers string) []gatewayv1.HTTPHeader {
+	var result []gatewayv1.HTTPHeader
+	for _, line := range strings.Split(headers, "\n") {
+		parts := strings.Fields(strings.TrimSpace(line))
+		if len(parts) < 2 {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), parts[0]))
+		result = append(result, gatewayv1.HTTPHeader{
+			Name:  gatewayv1.HTTPHeaderName(strings.Trim(parts[0], `"`)),
+			Value: strings.Trim(value, `"`),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (c *converter) convertMirror(ing *networkingv1.Ingress, a *ingressAnnotations) *gatewayv1.HTTPRouteFilter {
+	target, ok := a.get(mirrorTargetService)
+	if !ok {
+		a.getInt(mirrorPercentage, false)
+		return nil
+	}
+	namespace, service, port := ing.Namespace, target, ""
+	if i := strings.LastIndex(service, ":"); i >= 0 {
+		service, port = service[:i], service[i+1:]
+	}
+	if i := strings.Index(service, "/"); i >= 0 {
+		namespace, service = service[:i], service[i+1:]
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil || portNumber <= 0 {
+		c.report.add(ingressName(ing), mirrorTargetService, "mirror target %s has no port, Gateway API requires the port number", target)
+		return nil
+	}
+	gatewayPort := gatewayv1.PortNumber(portNumber)
+	mirror := &gatewayv1.HTTPRequestMirrorFilter{
+		BackendRef: gatewayv1.BackendObjectReference{
+			Name: gatewayv1.ObjectName(service),
+			Port: &gatewayPort,
+		},
+	}
+	if namespace != ing.Namespace {
+		gatewayNamespace := gatewayv1.Namespace(namespace)
+		mirror.BackendRef.Namespace = &gatewayNamespace
+		c.addReferenceGrant(namespace, gatewayv1.GroupName, "HTTPRoute", ing.Namespace, "Service")
+	}
+	if percentage, ok := a.getInt(mirrorPercentage, false); ok && percentage < 100 {
+		percent := int32(percentage)
+		mirror.Percent = &percent
+	}
+	return &gatewayv1.HTTPRouteFilter{
+		Type:          gatewayv1.HTTPRouteFilterRequestMirror,
+		RequestMirror: mirror,
+	}
+}
+
+func (c *converter) convertTimeouts(a *ingressAnnotations) *gatewayv1.HTTPRouteTimeouts {
+	var timeouts *gatewayv1.HTTPRouteTimeouts
+	if seconds, ok := a.getInt(timeout, true); ok && seconds > 0 {
+		request := gatewayv1.Duration(fmt.Sprintf("%ds", seconds))
+		timeouts = &gatewayv1.HTTPRouteTimeouts{Request: &request}
+	}
+	if seconds, ok := a.getInt(perRetryTimeout, false); ok && seconds > 0 {
+		if timeouts == nil {
+			timeouts = &gatewayv1.HTTPRouteTimeouts{}
+		}
+		backendRequest := gatewayv1.Duration(fmt.Sprintf("%ds", seconds))
+		timeouts.BackendRequest = &backendRequest
+	}
+	return timeouts
+}
+
+func (c *converter) convertRetry(ing *networkingv1.Ingress, a *ingressAnnotations) *gatewayv1.HTTPRouteRetry {
+	count, hasCount := a.getInt(retryCount, false)
+	conditions, hasConditions := a.get(retryOn)
+	if !hasCount && !hasConditions {
+		return nil
+	}
+	if !hasCount {
+		count = defaultRetryCount
+	}
+	retry := &gatewayv1.HTTPRouteRetry{Attempts: &count}
+	codes := map[int]bool{}
+	items := strings.Fields(strings.ReplaceAll(conditions, ",", " "))
+	if !hasConditions {
+		items = []string{"error"}
+	}
+	for _, item := range items {
+		switch {
+		case item == "off":
+			zero := 0
+			return &gatewayv1.HTTPRouteRetry{Attempts: &zero}
+		case item == "error" || item == "timeout" || item == "invalid_header":
+			for _, code := range []int{500, 502, 503, 504} {
+				codes[code] = true
+			}
+		case strings.HasPrefix(item, "http_"):
+			code, err := strconv.Atoi(strings.TrimPrefix(item, "http_"))
+			if err != nil || code < 400 || code > 599 {
+				c.report.add(ingressName(ing), retryOn, "retry condition %s is not converted", item)
+				continue
+			}
+			codes[code] = true
+		default:
+			c.report.add(ingressName(ing), retryOn, "retry condition %s is not converted", item)
+		}
+	}
+	for code := range codes {
+		retry.Codes = append(retry.Codes, gatewayv1.HTTPRouteRetryStatusCode(code))
+	}
+	sort.Slice(retry.Codes, func(i, j int) bool {
+		return retry.Codes[i] < retry.Codes[j]
+	})
+	return retry
+}
+
+func (c *converter) convertGRPCRoute(ing *networkingv1.Ingress, a *ingressAnnotations, routeName, host string,
+	paths []networkingv1.HTTPIngressPath, parentRefs []gatewayv1.ParentReference) {
+	name := ingressName(ing)
+	route := &gatewayv1.GRPCRoute{
+		TypeMeta: metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "GRPCRoute"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      routeName,
+			Namespace: ing.Namespace,
+		},
+		Spec: gatewayv1.GRPCRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs},
+		},
+	}
+	if host != "" {
+		route.Spec.Hostnames = []gatewayv1.Hostname{gatewayv1.Hostname(host)}
+	}
+
+	// Only the filters supported by GRPCRoute are converted, the others are reported as unused annotations
+	var filters []gatewayv1.GRPCRouteFilter
+	if modifier := headerModifier(a, requestHeaderAdd, requestHeaderUpdate, requestHeaderRemove); modifier != nil {
+		filters = append(filters, gatewayv1.GRPCRouteFilter{
+			Type:                  gatewayv1.GRPCRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: modifier,
+		})
+	}
+	if modifier := headerModifier(a, responseHeaderAdd, responseHeaderUpdate, responseHeaderRemove); modifier != nil {
+		filters = append(filters, gatewayv1.GRPCRouteFilter{
+			Type:                   gatewayv1.GRPCRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: modifier,
+		})
+	}
+	if filter := c.convertMirror(ing, a); filter != nil {
+		filters = append(filters, gatewayv1.GRPCRouteFilter{
+			Type:          gatewayv1.GRPCRouteFilterRequestMirror,
+			RequestMirror: filter.RequestMirror,
+		})
+	}
+
+	for i, path := range paths {
+		match, ok := grpcMethodMatch(path)
+		if !ok {
+			c.report.add(name, fmt.Sprintf("spec.rules.http.paths[%d].path", i),
+				"path %s can not be converted to a gRPC service or method match", path.Path)
+			continue
+		}
+		backendRef, ok := c.convertBackend(ing, fmt.Sprintf("spec.rules.http.paths[%d].backend", i), path.Backend)
+		if !ok {
+			continue
+		}
+		rule := gatewayv1.GRPCRouteRule{
+			Filters:     filters,
+			BackendRefs: []gatewayv1.GRPCBackendRef{{BackendRef: backendRef}},
+		}
+		if match != nil {
+			rule.Matches = []gatewayv1.GRPCRouteMatch{{Method: match}}
+		}
+		route.Spec.Rules = append(route.Spec.Rules, rule)
+	}
+	if len(route.Spec.Rules) > 0 {
+		c.result.GRPCRoutes = append(c.result.GRPCRoutes, route)
+	}
+}
+
+// grpcMethodMatch converts the path in the format of /service/method into the method match, the match is nil
+// if all the services are matched.
+func grpcMethodMatch(path networkingv1.HTTPIngressPath) (*gatewayv1.GRPCMethodMatch, bool) {
+	value := strings.Trim(path.Path, "/")
+	if value == "" {
+		return nil, true
+	}
+	exact := gatewayv1.GRPCMethodMatchExact
+	parts := strings.Split(value, "/")
+	switch len(parts) {
+	case 1:
+		return &gatewayv1.GRPCMethodMatch{Type: &exact, Service: &parts[0]}, true
+	case 2:
+		if path.PathType != nil && *path.PathType != networkingv1.PathTypeExact {
+			return nil, false
+		}
+		return &gatewayv1.GRPCMethodMatch{Type: &exact, Service: &parts[0], Method: &parts[1]}, true
+	default:
+		return nil, false
+	}
+}
+
+// convertCanary merges the canary ingress into the rules of the non-canary ingress with the same host and path.
+func (c *converter) convertCanary(ing *networkingv1.Ingress) {
+	name := ingressName(ing)
+	a := newIngressAnnotations(ing.Annotations)
+	a.get(canary)
+
+	var headerMatch *gatewayv1.HTTPHeaderMatch
+	exact := gatewayv1.HeaderMatchExact
+	regularExpression := gatewayv1.HeaderMatchRegularExpression
+	if header, ok := a.get(canaryByHeader); ok && header != "" {
+		headerMatch = &gatewayv1.HTTPHeaderMatch{Type: &exact, Name: gatewayv1.HTTPHeaderName(header), Value: "always"}
+		if value, ok := a.get(canaryByHeaderValue); ok && value != "" {
+			headerMatch.Type = &regularExpression
+			headerMatch.Value = "always|" + value
+		} else if pattern, ok := a.get(canaryByHeaderPattern); ok && pattern != "" {
+			headerMatch.Type = &regularExpression
+			headerMatch.Value = ".*" + pattern + ".*"
+		}
+	} else if cookie, ok := a.get(canaryByCookie); ok && cookie != "" {
+		headerMatch = &gatewayv1.HTTPHeaderMatch{
+			Type:  &regularExpression,
+			Name:  "cookie",
+			Value: `^(.*?;\s*)?(` + cookie + `=always)(;.*)?$`,
+		}
+	}
+	weight, hasWeight := a.getInt(canaryWeight, false)
+	total, ok := a.getInt(canaryWeightTotal, false)
+	if !ok || total <= 0 {
+		total = defaultCanaryWeightTotal
+	}
+	if headerMatch == nil && !hasWeight {
+		c.report.add(name, canary, "canary ingress without header, cookie or weight is skipped")
+		return
+	}
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i, path := range rule.HTTP.Paths {
+			field := fmt.Sprintf("spec.rules.http.paths[%d]", i)
+			match := c.convertPathMatch(a, path)
+			ref, ok := c.rules[ruleKey{host: rule.Host, pathType: *match.Type, path: *match.Value}]
+			if !ok {
+				c.report.add(name, field, "no HTTPRoute of non-canary ingress is found for host %q and path %s", rule.Host, *match.Value)
+				continue
+			}
+			backendRef, ok := c.convertBackend(ing, field+".backend", path.Backend)
+			if !ok {
+				continue
+			}
+			main := &ref.route.Spec.Rules[ref.index]
+			if len(main.BackendRefs) == 0 {
+				c.report.add(name, field, "HTTPRoute %s/%s redirects the requests, canary is skipped", ref.route.Namespace, ref.route.Name)
+				continue
+			}
+			if headerMatch != nil {
+				canaryRule := *main.DeepCopy()
+				for j := range canaryRule.Matches {
+					canaryRule.Matches[j].Headers = append(canaryRule.Matches[j].Headers, *headerMatch)
+				}
+				canaryRule.BackendRefs = []gatewayv1.HTTPBackendRef{{BackendRef: backendRef}}
+				ref.route.Spec.Rules = append(ref.route.Spec.Rules, canaryRule)
+				main = &ref.route.Spec.Rules[ref.index]
+			}
+			if hasWeight && weight > 0 {
+				// The weight of the main backend is what the canaries leave
+				primary := &main.BackendRefs[0].BackendRef
+				remaining := int32(total)
+				if primary.Weight != nil {
+					remaining = *primary.Weight
+				}
+				canaryWeight := int32(weight)
+				if canaryWeight > remaining {
+					canaryWeight = remaining
+				}
+				remaining -= canaryWeight
+				primary.Weight = &remaining
+				backendRef.Weight = &canaryWeight
+				main.BackendRefs = append(main.BackendRefs, gatewayv1.HTTPBackendRef{BackendRef: backendRef})
+			}
+		}
+	}
+
+	for _, key := range a.unused() {
+		c.report.add(name, key, "annotation of canary ingress is not converted")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func newIngress(namespace, name string, annotations map[string]string, host, path, service string, port int32) networkingv1.Ingress {
+	prefix := networkingv1.PathTypePrefix
+	return networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     path,
+							PathType: &prefix,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: service,
+									Port: networkingv1.ServiceBackendPort{Number: port},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func TestConvertFilters(t *testing.T) {
+	ing := newIngress("default", "foo", map[string]string{
+		"higress.io/rewrite-path":                               "/v2",
+		"nginx.ingress.kubernetes.io/upstream-vhost":            "internal.example.com",
+		"higress.io/request-header-control-add":                 "x-foo foo\nx-bar \"bar baz\"",
+		"higress.io/response-header-control-remove":             "server, x-powered-by",
+		"higress.io/mirror-target-service":                      "mirror/shadow:8080",
+		"higress.io/mirror-percentage":                          "10",
+		"higress.io/timeout":                                    "5",
+		"nginx.ingress.kubernetes.io/proxy-next-upstream-tries": "2",
+		"nginx.ingress.kubernetes.io/proxy-next-upstream":       "http_404 timeout",
+		"nginx.ingress.kubernetes.io/limit-rps":                 "10",
+	}, "example.com", "/v1", "foo", 80)
+
+	result := Convert([]networkingv1.Ingress{ing}, Options{})
+	require.Len(t, result.HTTPRoutes, 1)
+	route := result.HTTPRoutes[0]
+	assert.Equal(t, []gatewayv1.Hostname{"example.com"}, route.Spec.Hostnames)
+	require.Len(t, route.Spec.Rules, 1)
+	rule := route.Spec.Rules[0]
+
+	require.Len(t, rule.Filters, 4)
+	assert.Equal(t, gatewayv1.PrefixMatchHTTPPathModifier, rule.Filters[0].URLRewrite.Path.Type)
+	assert.Equal(t, "/v2", *rule.Filters[0].URLRewrite.Path.ReplacePrefixMatch)
+	assert.Equal(t, gatewayv1.PreciseHostname("internal.example.com"), *rule.Filters[0].URLRewrite.Hostname)
+	assert.Equal(t, []gatewayv1.HTTPHeader{{Name: "x-bar", Value: "bar baz"}, {Name: "x-foo", Value: "foo"}},
+		rule.Filters[1].RequestHeaderModifier.Add)
+	assert.Equal(t, []string{"server", "x-powered-by"}, rule.Filters[2].ResponseHeaderModifier.Remove)
+	assert.Equal(t, gatewayv1.ObjectName("shadow"), rule.Filters[3].RequestMirror.BackendRef.Name)
+	assert.Equal(t, int32(10), *rule.Filters[3].RequestMirror.Percent)
+
+	assert.Equal(t, gatewayv1.Duration("5s"), *rule.Timeouts.Request)
+	assert.Equal(t, 2, *rule.Retry.Attempts)
+	assert.Equal(t, []gatewayv1.HTTPRouteRetryStatusCode{404, 500, 502, 503, 504}, rule.Retry.Codes)
+
+	// The mirror service in another namespace requires a reference grant
+	require.Len(t, result.ReferenceGrants, 1)
+	assert.Equal(t, "mirror", result.ReferenceGrants[0].Namespace)
+
+	require.Len(t, result.Report.Items, 1)
+	assert.Equal(t, "nginx.ingress.kubernetes.io/limit-rps", result.Report.Items[0].Field)
+}
+
+func TestConvertRedirect(t *testing.T) {
+	ing := newIngress("default", "foo", map[string]string{
+		"nginx.ingress.kubernetes.io/permanent-redirect":      "https://example.org/new?a=b",
+		"nginx.ingress.kubernetes.io/permanent-redirect-code": "308",
+	}, "example.com", "/", "foo", 80)
+
+	result := Convert([]networkingv1.Ingress{ing}, Options{})
+	require.Len(t, result.HTTPRoutes, 1)
+	rule := result.HTTPRoutes[0].Spec.Rules[0]
+	assert.Empty(t, rule.BackendRefs)
+	redirect := rule.Filters[0].RequestRedirect
+	assert.Equal(t, "https", *redirect.Scheme)
+	assert.Equal(t, gatewayv1.PreciseHostname("example.org"), *redirect.Hostname)
+	assert.Equal(t, "/new", *redirect.Path.ReplaceFullPath)
+	assert.Equal(t, 301, *redirect.StatusCode)
+	assert.Len(t, result.Report.Items, 2)
+}
+
+func TestConvertCanary(t *testing.T) {
+	main := newIngress("default", "foo", nil, "example.com", "/", "foo", 80)
+	weight := newIngress("default", "foo-weight", map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	}, "example.com", "/", "foo-v2", 80)
+	header := newIngress("default", "foo-header", map[string]string{
+		"nginx.ingress.kubernetes.io/canary":                 "true",
+		"nginx.ingress.kubernetes.io/canary-by-header":       "x-canary",
+		"nginx.ingress.kubernetes.io/canary-by-header-value": "v3",
+	}, "example.com", "/", "foo-v3", 80)
+	orphan := newIngress("default", "bar-weight", map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	}, "bar.example.com", "/", "bar-v2", 80)
+
+	result := Convert([]networkingv1.Ingress{weight, header, main, orphan}, Options{})
+	require.Len(t, result.HTTPRoutes, 1)
+	rules := result.HTTPRoutes[0].Spec.Rules
+	require.Len(t, rules, 2)
+
+	require.Len(t, rules[0].BackendRefs, 2)
+	assert.Equal(t, int32(80), *rules[0].BackendRefs[0].Weight)
+	assert.Equal(t, gatewayv1.ObjectName("foo-v2"), rules[0].BackendRefs[1].Name)
+	assert.Equal(t, int32(20), *rules[0].BackendRefs[1].Weight)
+
+	require.Len(t, rules[1].Matches[0].Headers, 1)
+	assert.Equal(t, "always|v3", rules[1].Matches[0].Headers[0].Value)
+	assert.Equal(t, gatewayv1.ObjectName("foo-v3"), rules[1].BackendRefs[0].Name)
+
+	require.Len(t, result.Report.Items, 1)
+	assert.Equal(t, "default/bar-weight", result.Report.Items[0].Ingress)
+}
+
+func TestConvertTLSAndGRPC(t *testing.T) {
+	ing := newIngress("default", "foo", map[string]string{
+		"nginx.ingress.kubernetes.io/ssl-redirect":     "true",
+		"nginx.ingress.kubernetes.io/backend-protocol": "GRPC",
+	}, "example.com", "/helloworld.Greeter", "greeter", 9090)
+	ing.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+
+	result := Convert([]networkingv1.Ingress{ing}, Options{})
+	listeners := result.Gateway.Spec.Listeners
+	require.Len(t, listeners, 2)
+	assert.Equal(t, gatewayv1.SectionName("https-example-com"), listeners[1].Name)
+	assert.Equal(t, gatewayv1.ObjectName("example-cert"), listeners[1].TLS.CertificateRefs[0].Name)
+	require.Len(t, result.ReferenceGrants, 1)
+
+	require.Len(t, result.GRPCRoutes, 1)
+	route := result.GRPCRoutes[0]
+	assert.Equal(t, gatewayv1.SectionName("https-example-com"), *route.Spec.ParentRefs[0].SectionName)
+	assert.Equal(t, "helloworld.Greeter", *route.Spec.Rules[0].Matches[0].Method.Service)
+
+	require.Len(t, result.HTTPRoutes, 1)
+	assert.Equal(t, "foo-https-redirect", result.HTTPRoutes[0].Name)
+	assert.Empty(t, result.Report.Items)
+}
+
+func TestConvertPlugins(t *testing.T) {
+	ing := newIngress("default", "foo", map[string]string{
+		"nginx.ingress.kubernetes.io/enable-cors":            "true",
+		"nginx.ingress.kubernetes.io/cors-allow-origin":      "https://example.com, https://*.example.org",
+		"nginx.ingress.kubernetes.io/whitelist-source-range": "10.0.0.0/8,192.168.1.1",
+	}, "example.com", "/", "foo", 80)
+
+	result := Convert([]networkingv1.Ingress{ing}, Options{})
+	require.Len(t, result.WasmPlugins, 2)
+	cors := result.WasmPlugins[0]
+	assert.Equal(t, "cors-migrated", cors.GetName())
+	assert.True(t, strings.HasSuffix(cors.Object["spec"].(map[string]interface{})["url"].(string), "/cors:1.0.0"))
+	matchRule := cors.Object["spec"].(map[string]interface{})["matchRules"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"default/foo"}, matchRule["ingress"])
+	config := matchRule["config"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"https://example.com"}, config["allow_origins"])
+	assert.Equal(t, []interface{}{"https://*.example.org"}, config["allow_origin_patterns"])
+
+	ipRestriction := result.WasmPlugins[1]
+	matchRule = ipRestriction.Object["spec"].(map[string]interface{})["matchRules"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"10.0.0.0/8", "192.168.1.1"}, matchRule["config"].(map[string]interface{})["allow"])
+	assert.Empty(t, result.Report.Items)
+}
+
+func TestDecodeIngresses(t *testing.T) {
+	content := `apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: foo
+spec:
+  ingressClassName: higress
+  rules:
+  - host: example.com
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: networking.k8s.io/v1
+  kind: Ingress
+  metadata:
+    name: bar
+    namespace: test
+    annotations:
+      kubernetes.io/ingress.class: nginx
+`
+	ingresses, err := decodeIngresses(strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, ingresses, 2)
+	assert.Equal(t, "default", ingresses[0].Namespace)
+	assert.Equal(t, "bar", ingresses[1].Name)
+
+	filtered := filterIngresses(ingresses, "", "nginx")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "bar", filtered[0].Name)
+}
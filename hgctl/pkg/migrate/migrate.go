@@ -0,0 +1,316 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	k8s "github.com/alibaba/higress/hgctl/pkg/kubernetes"
+	"github.com/alibaba/higress/v2/pkg/cmd/options"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+)
+
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+type ingressToGatewayArgs struct {
+	filenames        []string
+	namespace        string
+	ingressClass     string
+	gatewayName      string
+	gatewayNamespace string
+	gatewayClass     string
+	pluginRegistry   string
+	output           string
+	reportOutput     string
+}
+
+func NewCommand() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the configurations of Higress",
+	}
+
+	migrateCmd.AddCommand(newIngressToGatewayCommand())
+
+	return migrateCmd
+}
+
+func newIngressToGatewayCommand() *cobra.Command {
+	args := &ingressToGatewayArgs{}
+	cmd := &cobra.Command{
+		Use:   "ingress-to-gateway",
+		Short: "Convert the ingresses and their annotations into Gateway API resources",
+		Long: `Convert the ingresses and their annotations into Gateway, HTTPRoute and GRPCRoute resources.
+The annotations of canary, rewrite, redirect, header control, mirror, timeout and retry are converted into
+the native fields or filters of Gateway API, cors and whitelist are converted into WasmPlugins. The
+annotations which are not converted are listed in the report.`,
+		Example: `  # Convert all the ingresses in the cluster
+  hgctl migrate ingress-to-gateway > gateway.yaml
+
+  # Convert the ingresses in the files
+  hgctl migrate ingress-to-gateway -f ingress.yaml -f ./ingresses/ --report report.txt`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmdutil.CheckErr(runIngressToGateway(cmd.OutOrStdout(), cmd.ErrOrStderr(), args))
+		},
+	}
+
+	flags := cmd.Flags()
+	options.AddKubeConfigFlags(flags)
+	flags.StringSliceVarP(&args.filenames, "filename", "f", nil,
+		"Files or directories containing the ingresses, the ingresses are read from the cluster if not specified")
+	flags.StringVarP(&args.namespace, "namespace", "n", "",
+		"Namespace of the ingresses to convert, all the namespaces if not specified")
+	flags.StringVar(&args.ingressClass, "ingress-class", "",
+		"Only convert the ingresses of the ingress class, all the ingresses if not specified")
+	flags.StringVar(&args.gatewayName, "gateway-name", DefaultGatewayName, "Name of the generated Gateway")
+	flags.StringVar(&args.gatewayNamespace, "gateway-namespace", DefaultGatewayNamespace, "Namespace of the generated Gateway")
+	flags.StringVar(&args.gatewayClass, "gateway-class", DefaultGatewayClass, "GatewayClass of the generated Gateway")
+	flags.StringVar(&args.pluginRegistry, "plugin-registry", DefaultPluginRegistry,
+		"Registry of the WasmPlugins converted from the annotations")
+	flags.StringVarP(&args.output, "output", "o", "yaml", "Output format, one of yaml or json")
+	flags.StringVar(&args.reportOutput, "report", "",
+		"File to write the report to, the report is printed to stderr if not specified")
+
+	return cmd
+}
+
+func runIngressToGateway(w, errW io.Writer, args *ingressToGatewayArgs) error {
+	if args.output != "yaml" && args.output != "json" {
+		return errors.Errorf("unsupported output format %q", args.output)
+	}
+	opts := Options{
+		GatewayName:      args.gatewayName,
+		GatewayNamespace: args.gatewayNamespace,
+		GatewayClass:     args.gatewayClass,
+		PluginRegistry:   args.pluginRegistry,
+	}
+
+	var ingresses []networkingv1.Ingress
+	var err error
+	if len(args.filenames) > 0 {
+		ingresses, err = readIngressFiles(args.filenames)
+	} else {
+		var cli kubernetes.Interface
+		cli, err = newKubernetesClient()
+		if err == nil {
+			ingresses, err = listIngresses(cli, args.namespace)
+			opts.PortResolver = servicePortResolver(cli)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	ingresses = filterIngresses(ingresses, args.namespace, args.ingressClass)
+
+	result := Convert(ingresses, opts)
+	if err = writeObjects(w, result.Objects(), args.output); err != nil {
+		return errors.Wrap(err, "failed to write the converted resources")
+	}
+
+	reportW := errW
+	if args.reportOutput != "" {
+		f, err := os.Create(args.reportOutput)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the report file")
+		}
+		defer f.Close()
+		reportW = f
+	}
+	return result.Report.Print(reportW)
+}
+
+func newKubernetesClient() (kubernetes.Interface, error) {
+	cli, err := k8s.NewCLIClient(options.DefaultConfigFlags.ToRawKubeConfigLoader())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build kubernetes client")
+	}
+	return cli.KubernetesInterface(), nil
+}
+
+func listIngresses(cli kubernetes.Interface, namespace string) ([]networkingv1.Ingress, error) {
+	list, err := cli.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ingresses")
+	}
+	return list.Items, nil
+}
+
+// servicePortResolver resolves the named service ports with the services in the cluster.
+func servicePortResolver(cli kubernetes.Interface) PortResolver {
+	return func(namespace, service, port string) (int32, error) {
+		svc, err := cli.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range svc.Spec.Ports {
+			if p.Name == port {
+				return p.Port, nil
+			}
+		}
+		return 0, fmt.Errorf("port %s not found", port)
+	}
+}
+
+func filterIngresses(ingresses []networkingv1.Ingress, namespace, ingressClass string) []networkingv1.Ingress {
+	var result []networkingv1.Ingress
+	for _, ing := range ingresses {
+		if namespace != "" && ing.Namespace != namespace {
+			continue
+		}
+		if ingressClass != "" {
+			class := ing.Annotations[ingressClassAnnotation]
+			if ing.Spec.IngressClassName != nil {
+				class = *ing.Spec.IngressClassName
+			}
+			if class != ingressClass {
+				continue
+			}
+		}
+		result = append(result, ing)
+	}
+	return result
+}
+
+// readIngressFiles reads the ingresses in the yaml or json files, the other resources are ignored.
+func readIngressFiles(filenames []string) ([]networkingv1.Ingress, error) {
+	var ingresses []networkingv1.Ingress
+	for _, filename := range filenames {
+		err := filepath.Walk(filename, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// The files in the directories are filtered by extension, the files specified explicitly are always read
+			if path != filename {
+				ext := filepath.Ext(path)
+				if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+					return nil
+				}
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			items, err := decodeIngresses(f)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", path)
+			}
+			ingresses = append(ingresses, items...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ingresses, nil
+}
+
+func decodeIngresses(r io.Reader) ([]networkingv1.Ingress, error) {
+	var ingresses []networkingv1.Ingress
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bufio.NewReader(r), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return ingresses, nil
+			}
+			return nil, err
+		}
+		if obj.Object == nil {
+			continue
+		}
+		objects := []unstructured.Unstructured{*obj}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, err
+			}
+			objects = list.Items
+		}
+		for _, item := range objects {
+			gvk := item.GroupVersionKind()
+			if gvk.Group != networkingv1.GroupName || gvk.Kind != "Ingress" {
+				continue
+			}
+			if gvk.Version != networkingv1.SchemeGroupVersion.Version {
+				return nil, errors.Errorf("ingress %s/%s of version %s is not supported, convert it to %s first",
+					item.GetNamespace(), item.GetName(), gvk.Version, networkingv1.SchemeGroupVersion)
+			}
+			ing := networkingv1.Ingress{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &ing); err != nil {
+				return nil, err
+			}
+			if ing.Namespace == "" {
+				ing.Namespace = metav1.NamespaceDefault
+			}
+			ingresses = append(ingresses, ing)
+		}
+	}
+}
+
+func writeObjects(w io.Writer, objects []runtime.Object, format string) error {
+	var items []interface{}
+	for _, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		// The generated resources have no status and creation timestamp
+		delete(content, "status")
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		items = append(items, content)
+	}
+
+	if format == "json" {
+		list := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	}
+	docs := make([]string, 0, len(items))
+	for _, item := range items {
+		content, err := yaml.Marshal(item)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, string(content))
+	}
+	_, err := fmt.Fprint(w, strings.Join(docs, "---\n"))
+	return err
+}
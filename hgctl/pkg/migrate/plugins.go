@@ -0,0 +1,182 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	wasmPluginNameLabel  = "higress.io/wasm-plugin-name"
+	migratedPluginSuffix = "-migrated"
+)
+
+// wasmPlugin is the plugin replacing the annotations which have no equivalent in Gateway API
+type wasmPlugin struct {
+	name     string
+	phase    string
+	priority int64
+}
+
+var (
+	corsPlugin          = wasmPlugin{name: "cors", phase: "AUTHZ", priority: 340}
+	ipRestrictionPlugin = wasmPlugin{name: "ip-restriction", phase: "AUTHN", priority: 210}
+
+	wasmPlugins = []wasmPlugin{corsPlugin, ipRestrictionPlugin}
+)
+
+func (c *converter) convertPlugins(ing *networkingv1.Ingress, a *ingressAnnotations, routeNames []string, routeType string) {
+	if len(routeNames) == 0 {
+		return
+	}
+	if config := convertCors(a); config != nil {
+		c.addPluginRules(corsPlugin, ing.Namespace, routeNames, routeType, config)
+	}
+	if config := convertWhitelist(a); config != nil {
+		c.addPluginRules(ipRestrictionPlugin, ing.Namespace, routeNames, routeType, config)
+	}
+}
+
+func (c *converter) addPluginRules(plugin wasmPlugin, namespace string, routeNames []string, routeType string, config map[string]interface{}) {
+	for _, name := range routeNames {
+		// The same as the route names generated by the controller, assuming it runs in the namespace of the gateway
+		if namespace != c.opts.GatewayNamespace {
+			name = path.Join(namespace, name)
+		}
+		c.pluginRules[plugin.name] = append(c.pluginRules[plugin.name], pluginRule{
+			route:     name,
+			routeType: routeType,
+			config:    config,
+		})
+	}
+}
+
+func (c *converter) buildWasmPlugins() {
+	for _, plugin := range wasmPlugins {
+		rules := c.pluginRules[plugin.name]
+		if len(rules) == 0 {
+			continue
+		}
+		var matchRules []interface{}
+		for _, rule := range rules {
+			matchRule := map[string]interface{}{
+				"ingress": []interface{}{rule.route},
+				"config":  rule.config,
+			}
+			if rule.routeType != "HTTP" {
+				matchRule["routeType"] = rule.routeType
+			}
+			matchRules = append(matchRules, matchRule)
+		}
+		c.result.WasmPlugins = append(c.result.WasmPlugins, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "extensions.higress.io/v1alpha1",
+				"kind":       "WasmPlugin",
+				"metadata": map[string]interface{}{
+					"name":      plugin.name + migratedPluginSuffix,
+					"namespace": c.opts.GatewayNamespace,
+					"labels": map[string]interface{}{
+						wasmPluginNameLabel: plugin.name,
+					},
+				},
+				"spec": map[string]interface{}{
+					"url":                  c.opts.PluginRegistry + "/" + plugin.name + ":1.0.0",
+					"phase":                plugin.phase,
+					"priority":             plugin.priority,
+					"defaultConfigDisable": true,
+					"matchRules":           matchRules,
+				},
+			},
+		})
+	}
+}
+
+// convertCors converts the cors annotations into the config of the cors plugin, the defaults are the same as
+// the controller.
+func convertCors(a *ingressAnnotations) map[string]interface{} {
+	if !a.getBool(enableCors) {
+		for _, key := range []string{allowOrigin, allowMethods, allowHeaders, exposeHeaders, allowCredentials, maxAge} {
+			a.get(key)
+		}
+		return nil
+	}
+	config := map[string]interface{}{
+		"allow_origins":     []interface{}{"*"},
+		"allow_methods":     []interface{}{"GET", "PUT", "POST", "DELETE", "PATCH", "OPTIONS"},
+		"allow_headers":     []interface{}{"DNT", "X-CustomHeader", "Keep-Alive", "User-Agent", "X-Requested-With", "If-Modified-Since", "Cache-Control", "Content-Type", "Authorization"},
+		"allow_credentials": true,
+		"max_age":           int64(defaultCorsMaxAge),
+	}
+	if origins, ok := a.get(allowOrigin); ok {
+		var allowOrigins, patterns []interface{}
+		for _, origin := range splitAndTrim(origins, ",") {
+			if strings.Contains(origin, "*") && origin != "*" {
+				patterns = append(patterns, origin)
+			} else {
+				allowOrigins = append(allowOrigins, origin)
+			}
+		}
+		config["allow_origins"] = allowOrigins
+		if len(patterns) > 0 {
+			config["allow_origin_patterns"] = patterns
+		}
+	}
+	if methods, ok := a.get(allowMethods); ok {
+		config["allow_methods"] = toInterfaces(splitAndTrim(methods, ","))
+	}
+	if headers, ok := a.get(allowHeaders); ok {
+		config["allow_headers"] = toInterfaces(splitAndTrim(headers, ","))
+	}
+	if headers, ok := a.get(exposeHeaders); ok {
+		config["expose_headers"] = toInterfaces(splitAndTrim(headers, ","))
+	}
+	if credentials, ok := a.get(allowCredentials); ok {
+		config["allow_credentials"] = strings.TrimSpace(credentials) == "true"
+	}
+	if age, ok := a.getInt(maxAge, false); ok {
+		config["max_age"] = int64(age)
+	}
+	return config
+}
+
+// convertWhitelist converts the whitelist into the config of the ip-restriction plugin which uses the source ip.
+func convertWhitelist(a *ingressAnnotations) map[string]interface{} {
+	ranges, ok := a.get(whitelist)
+	if !ok {
+		return nil
+	}
+	allow := splitAndTrim(ranges, ",")
+	if len(allow) == 0 {
+		return nil
+	}
+	sort.Strings(allow)
+	return map[string]interface{}{
+		"ip_source_type": "origin-source",
+		"allow":          toInterfaces(allow),
+	}
+}
+
+func toInterfaces(items []string) []interface{} {
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// ReportItem is something of an ingress which is not converted, or converted with a different behavior.
+type ReportItem struct {
+	// Ingress is namespace/name of the ingress
+	Ingress string `json:"ingress"`
+	// Field is the annotation or the field of the ingress spec
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Report struct {
+	Items []ReportItem `json:"items"`
+}
+
+func (r *Report) add(ingress, field, format string, args ...interface{}) {
+	item := ReportItem{
+		Ingress: ingress,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+	// The same annotation is converted for each path of the ingress
+	for _, existing := range r.Items {
+		if existing == item {
+			return
+		}
+	}
+	r.Items = append(r.Items, item)
+}
+
+func (r *Report) Print(w io.Writer) error {
+	if len(r.Items) == 0 {
+		_, err := fmt.Fprintln(w, "All the ingresses are converted.")
+		return err
+	}
+	sort.SliceStable(r.Items, func(i, j int) bool {
+		return r.Items[i].Ingress < r.Items[j].Ingress
+	})
+	printer := printers.GetNewTabWriter(w)
+	fmt.Fprintf(printer, "INGRESS\tFIELD\tMESSAGE\n")
+	for _, item := range r.Items {
+		fmt.Fprintf(printer, "%s\t%s\t%s\n", item.Ingress, item.Field, item.Message)
+	}
+	return printer.Flush()
+}
@@ -45,6 +45,12 @@ type TestOptions struct {
 	TestPath    string `json:"test-path" yaml:"test-path" mapstructure:"test-path"`
 	ComposeFile string `json:"compose-file" yaml:"compose-file" mapstructure:"compose-file"`
 	Detach      bool   `json:"detach" yaml:"detach" mapstructure:"detach"`
+
+	Specs        []string `json:"specs" yaml:"specs" mapstructure:"specs"`
+	JUnitReport  string   `json:"junit-report" yaml:"junit-report" mapstructure:"junit-report"`
+	Port         int      `json:"port" yaml:"port" mapstructure:"port"`
+	UpstreamHost string   `json:"upstream-host" yaml:"upstream-host" mapstructure:"upstream-host"`
+	Keep         bool     `json:"keep" yaml:"keep" mapstructure:"keep"`
 }
 
 type InstallOptions struct {
@@ -59,6 +59,17 @@ test:
   compose-file:
   # Detached mode: Run containers in the background
   detach: false
+  # The test specs executed by 'hgctl plugin test run'
+  specs:
+  - ./test/cases.yaml
+  # The JUnit report of 'hgctl plugin test run'
+  junit-report: ./test/junit.xml
+  # The local port of the gateway started by 'hgctl plugin test run'
+  port: 10000
+  # The address of this host as seen from the gateway container, which reaches the mock upstream
+  upstream-host: host.docker.internal
+  # Keep the gateway started by 'hgctl plugin test run' after the run
+  keep: false
 
 install:
   # The namespace of the installation
@@ -0,0 +1,242 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// caseHeader routes a request to the route of its case, the route also sets it on the upstream request
+// so the mock upstream knows which response to send
+const caseHeader = "x-hgctl-test-case"
+
+// testCaseID identifies a case across all suites, it is also used in the route name
+func testCaseID(suite, index int) string {
+	return fmt.Sprintf("%d-%d", suite, index)
+}
+
+func testRouteName(id string) string {
+	return "hgctl-test-" + id
+}
+
+type upstreamRequest struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Host    string      `json:"host"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+// mockUpstream answers each case with its configured response and records the request it received
+type mockUpstream struct {
+	mu        sync.Mutex
+	responses map[string]*UpstreamResponse
+	received  map[string]*upstreamRequest
+}
+
+func newMockUpstream(specs []*TestSpec) *mockUpstream {
+	m := &mockUpstream{
+		responses: make(map[string]*UpstreamResponse),
+		received:  make(map[string]*upstreamRequest),
+	}
+	for i, spec := range specs {
+		for j := range spec.Cases {
+			m.responses[testCaseID(i, j)] = spec.Cases[j].Upstream
+		}
+	}
+	return m
+}
+
+func (m *mockUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	id := r.Header.Get(caseHeader)
+	req := &upstreamRequest{
+		Method:  r.Method,
+		Path:    r.URL.RequestURI(),
+		Host:    r.Host,
+		Headers: r.Header.Clone(),
+		Body:    string(body),
+	}
+	req.Headers.Del(caseHeader)
+
+	m.mu.Lock()
+	m.received[id] = req
+	resp, ok := m.responses[id]
+	m.mu.Unlock()
+
+	if !ok || resp == nil {
+		// Echo the request like httpbin does
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(req)
+		return
+	}
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = io.WriteString(w, resp.Body)
+}
+
+// take returns and forgets the request received for a case
+func (m *mockUpstream) take(id string) *upstreamRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	req := m.received[id]
+	delete(m.received, id)
+	return req
+}
+
+// executor sends the requests of the cases to the gateway and checks the results
+type executor struct {
+	gateway  string
+	client   *http.Client
+	upstream *mockUpstream
+}
+
+func newExecutor(gateway string, upstream *mockUpstream, timeout time.Duration) *executor {
+	return &executor{
+		gateway:  strings.TrimSuffix(gateway, "/"),
+		upstream: upstream,
+		client: &http.Client{
+			Timeout: timeout,
+			// Redirects are asserted on, not followed
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// waitReady waits until the gateway answers HTTP requests
+func (e *executor) waitReady(ctx context.Context, interval time.Duration) error {
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.gateway+"/", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := e.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(err, "gateway %s is not ready", e.gateway)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (e *executor) runSuite(index int, spec *TestSpec) JUnitTestSuite {
+	start := time.Now()
+	suite := JUnitTestSuite{
+		Name:      spec.Name,
+		Timestamp: start.Format(time.RFC3339),
+	}
+	for j := range spec.Cases {
+		c := &spec.Cases[j]
+		tc := JUnitTestCase{
+			Name:      c.Name,
+			Classname: spec.Name,
+		}
+		suite.Tests++
+		if c.Skip {
+			tc.Skipped = &JUnitSkipped{Message: "skipped by the test spec"}
+			tc.Time = formatSeconds(0)
+			suite.Skipped++
+			suite.TestCases = append(suite.TestCases, tc)
+			continue
+		}
+
+		caseStart := time.Now()
+		failures, err := e.runCase(testCaseID(index, j), c)
+		tc.Time = formatSeconds(time.Since(caseStart))
+		switch {
+		case err != nil:
+			tc.Failure = &JUnitFailure{Message: err.Error(), Type: "error", Contents: err.Error()}
+		case len(failures) > 0:
+			tc.Failure = &JUnitFailure{
+				Message:  fmt.Sprintf("%d assertion(s) failed", len(failures)),
+				Type:     "assertion",
+				Contents: strings.Join(failures, "\n"),
+			}
+		}
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Time = formatSeconds(time.Since(start))
+
+	return suite
+}
+
+// runCase returns the failed assertions of a case, or an error if the request could not be sent
+func (e *executor) runCase(id string, c *TestCase) ([]string, error) {
+	req, err := http.NewRequest(c.Request.Method, e.gateway+c.Request.Path, strings.NewReader(c.Request.Body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the request")
+	}
+	for k, v := range c.Request.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.Request.Host != "" {
+		req.Host = c.Request.Host
+	}
+	req.Header.Set(caseHeader, id)
+
+	e.upstream.take(id)
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send the request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the response body")
+	}
+
+	var failures []string
+	if c.Expect.Status != 0 && resp.StatusCode != c.Expect.Status {
+		failures = append(failures, fmt.Sprintf("status: expected %d, got %d", c.Expect.Status, resp.StatusCode))
+	}
+	failures = append(failures, c.Expect.Check(resp.Header, body)...)
+
+	if expect := c.Expect.Upstream; expect != nil {
+		called := expect.Called == nil || *expect.Called
+		received := e.upstream.take(id)
+		switch {
+		case received == nil && called:
+			failures = append(failures, "upstream: expected to receive the request, but it was not called")
+		case received != nil && !called:
+			failures = append(failures, "upstream: expected not to be called, but it received the request")
+		case received != nil:
+			for _, f := range expect.Check(received.Headers, []byte(received.Body)) {
+				failures = append(failures, "upstream "+f)
+			}
+		}
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSpecYAML = `name: request-id
+config:
+  header: x-request-id
+cases:
+- name: add header
+  request:
+    path: /get
+  expect:
+    status: 200
+    jsonPath:
+      headers.X-Request-Id[0]: generated
+    upstream:
+      headers:
+        x-request-id: generated
+- name: deny
+  config:
+    deny: true
+  request:
+    method: POST
+    path: /post
+    body: hello
+  expect:
+    status: 403
+    bodyContains:
+    - denied
+    upstream:
+      called: false
+- name: mock upstream
+  request:
+    path: /models
+  upstream:
+    headers:
+      content-type: application/json
+    body: '{"data":[{"id":"qwen"}]}'
+  expect:
+    status: 200
+    headers:
+      content-type: application/json
+    absentHeaders:
+    - x-powered-by
+    jsonPath:
+      data[0].id: wrong
+- name: skipped
+  skip: true
+  expect:
+    status: 200
+`
+
+// newFakeGateway emulates a plugin adding a header, and rejecting requests of cases configured with deny
+func newFakeGateway(t *testing.T, spec *TestSpec, upstream *mockUpstream) *httptest.Server {
+	upstreamServer := httptest.NewServer(upstream)
+	t.Cleanup(upstreamServer.Close)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(caseHeader)
+		var conf map[string]interface{}
+		for j := range spec.Cases {
+			if testCaseID(0, j) == id {
+				conf = spec.PluginConfig(&spec.Cases[j])
+			}
+		}
+		if conf == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if conf["deny"] == true {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "denied by plugin")
+			return
+		}
+		req, _ := http.NewRequest(r.Method, upstreamServer.URL+r.URL.RequestURI(), r.Body)
+		req.Header = r.Header.Clone()
+		req.Header.Set(conf["header"].(string), "generated")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+}
+
+func TestRunSuite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testSpecYAML), 0o644))
+	spec, err := ParseTestSpec(path)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodGet, spec.Cases[0].Request.Method)
+	require.Equal(t, http.StatusOK, spec.Cases[2].Upstream.Status)
+
+	upstream := newMockUpstream([]*TestSpec{spec})
+	gateway := newFakeGateway(t, spec, upstream)
+	defer gateway.Close()
+
+	exec := newExecutor(gateway.URL, upstream, 5*time.Second)
+	suite := exec.runSuite(0, spec)
+	require.Equal(t, 4, suite.Tests)
+	require.Equal(t, 1, suite.Failures)
+	require.Equal(t, 1, suite.Skipped)
+
+	cases := make(map[string]JUnitTestCase)
+	for _, tc := range suite.TestCases {
+		cases[tc.Name] = tc
+	}
+	require.Nil(t, cases["add header"].Failure)
+	require.Nil(t, cases["deny"].Failure)
+	require.NotNil(t, cases["skipped"].Skipped)
+	failure := cases["mock upstream"].Failure
+	require.NotNil(t, failure)
+	require.Equal(t, `jsonPath "data[0].id": expected "wrong", got "qwen"`, failure.Contents)
+
+	report := &JUnitTestSuites{}
+	report.Add(suite)
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	require.True(t, strings.HasPrefix(buf.String(), xml.Header))
+	var decoded JUnitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, 4, decoded.Tests)
+	require.Equal(t, 1, decoded.Failures)
+	require.Len(t, decoded.Suites[0].TestCases, 4)
+}
+
+func TestParseTestSpecInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown field":  "name: x\ncases:\n- name: a\n  expect:\n    code: 200\n",
+		"no cases":       "name: x\n",
+		"duplicate case": "name: x\ncases:\n- name: a\n- name: a\n",
+		"relative path":  "name: x\ncases:\n- name: a\n  request:\n    path: get\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cases.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+			_, err := ParseTestSpec(path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := normalizeJSON(map[string]interface{}{
+		"choices": []interface{}{
+			map[string]interface{}{"message": map[string]interface{}{"content": "hi"}},
+		},
+		"matrix": []interface{}{[]interface{}{1, 2}},
+	})
+
+	v, err := lookupJSONPath(doc, "choices[0].message.content")
+	require.NoError(t, err)
+	require.Equal(t, "hi", v)
+
+	v, err = lookupJSONPath(doc, "$.matrix[0][1]")
+	require.NoError(t, err)
+	require.True(t, jsonEqual(v, 2))
+
+	_, err = lookupJSONPath(doc, "choices[1]")
+	require.Error(t, err)
+	_, err = lookupJSONPath(doc, "choices.message")
+	require.Error(t, err)
+}
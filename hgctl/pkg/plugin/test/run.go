@@ -0,0 +1,242 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alibaba/higress/hgctl/pkg/docker"
+	"github.com/alibaba/higress/hgctl/pkg/plugin/option"
+	"github.com/alibaba/higress/hgctl/pkg/plugin/utils"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	readyTimeout   = 60 * time.Second
+	requestTimeout = 30 * time.Second
+)
+
+type runner struct {
+	optionFile string
+	option.TestOptions
+
+	w io.Writer
+}
+
+func newRunCommand() *cobra.Command {
+	var r runner
+	v := viper.New()
+
+	runCmd := &cobra.Command{
+		Use:     "run",
+		Aliases: []string{"r"},
+		Short:   "Run the test cases of the test specs and report the results in JUnit format",
+		Example: `  # If the option.yaml file exists in the current path, do the following:
+  hgctl plugin test run
+
+  # Run the cases of several test specs against the build products in ./out
+  hgctl plugin test run -d ./out -s ./test/auth.yaml -s ./test/rewrite.yaml --junit-report ./junit.xml
+  `,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(r.config(v, cmd))
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(r.run())
+		},
+	}
+
+	flags := runCmd.PersistentFlags()
+	option.AddOptionFileFlag(&r.optionFile, flags)
+	v.BindPFlags(flags)
+
+	flags.StringP("name", "p", "wasm-test", "Test environment name, the run environment is named <name>-run")
+	v.BindPFlag("test.name", flags.Lookup("name"))
+	v.SetDefault("test.name", "wasm-test")
+
+	flags.StringP("from-path", "d", "./out", "Path of storing the build products")
+	v.BindPFlag("test.from-path", flags.Lookup("from-path"))
+	v.SetDefault("test.from-path", "./out")
+
+	flags.StringP("test-path", "t", "./test", "Path for storing the test configuration, the run environment is generated in its run directory")
+	v.BindPFlag("test.test-path", flags.Lookup("test-path"))
+	v.SetDefault("test.test-path", "./test")
+
+	flags.StringSliceP("spec", "s", []string{"./test/cases.yaml"}, "Test spec files, can be repeated")
+	v.BindPFlag("test.specs", flags.Lookup("spec"))
+	v.SetDefault("test.specs", []string{"./test/cases.yaml"})
+
+	flags.String("junit-report", "./test/junit.xml", "Path of the JUnit report")
+	v.BindPFlag("test.junit-report", flags.Lookup("junit-report"))
+	v.SetDefault("test.junit-report", "./test/junit.xml")
+
+	flags.Int("port", 10000, "Local port of the gateway")
+	v.BindPFlag("test.port", flags.Lookup("port"))
+	v.SetDefault("test.port", 10000)
+
+	flags.String("upstream-host", "host.docker.internal", "Address of this host as seen from the gateway container")
+	v.BindPFlag("test.upstream-host", flags.Lookup("upstream-host"))
+	v.SetDefault("test.upstream-host", "host.docker.internal")
+
+	flags.Bool("keep", false, "Keep the gateway running after the run")
+	v.BindPFlag("test.keep", flags.Lookup("keep"))
+	v.SetDefault("test.keep", false)
+
+	return runCmd
+}
+
+func (r *runner) config(v *viper.Viper, cmd *cobra.Command) error {
+	allOpt, err := option.ParseOptions(r.optionFile, v, cmd.PersistentFlags())
+	if err != nil {
+		return err
+	}
+	r.TestOptions = allOpt.Test
+
+	r.w = cmd.OutOrStdout()
+
+	return nil
+}
+
+func (r *runner) run() (err error) {
+	source, err := utils.GetAbsolutePath(r.FromPath)
+	if err != nil {
+		return errors.Wrapf(err, "invalid build products path %q", r.FromPath)
+	}
+	target, err := utils.GetAbsolutePath(r.TestPath)
+	if err != nil {
+		return errors.Wrapf(err, "invalid test path %q", r.TestPath)
+	}
+	if _, err = os.Stat(filepath.Join(source, "plugin.wasm")); err != nil {
+		return errors.Wrapf(err, "no plugin.wasm in the build products path %q", source)
+	}
+
+	// 1. parse the test specs
+	if len(r.Specs) == 0 {
+		return errors.New("no test spec is specified")
+	}
+	specs := make([]*TestSpec, 0, len(r.Specs))
+	for _, path := range r.Specs {
+		spec, err := ParseTestSpec(path)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, spec)
+	}
+
+	// 2. start the mock upstream
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for the mock upstream")
+	}
+	upstream := newMockUpstream(specs)
+	srv := &http.Server{Handler: upstream}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	// 3. generate and start the run environment
+	env, err := newRunEnv(specs)
+	if err != nil {
+		return err
+	}
+	env.RunPath = filepath.Join(target, "run")
+	env.ProductPath = source
+	env.Port = r.Port
+	env.UpstreamHost = r.UpstreamHost
+	env.UpstreamPort = l.Addr().(*net.TCPAddr).Port
+	if err = os.MkdirAll(env.RunPath, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create the run environment")
+	}
+	if err = genRunEnvFiles(env); err != nil {
+		return errors.Wrap(err, "failed to create the run environment")
+	}
+
+	cli, err := docker.NewCompose(r.w)
+	if err != nil {
+		return errors.Wrap(err, "failed to build the docker compose client")
+	}
+	name := fmt.Sprintf("%s-run", r.Name)
+	if err = cli.Up(context.TODO(), name, nil, env.RunPath, true); err != nil {
+		return errors.Wrap(err, "failed to start the run environment")
+	}
+	if !r.Keep {
+		defer func() {
+			if downErr := cli.Down(context.TODO(), name); downErr != nil && err == nil {
+				err = errors.Wrapf(downErr, "failed to stop the run environment %q", name)
+			}
+		}()
+	}
+
+	exec := newExecutor(fmt.Sprintf("http://127.0.0.1:%d", r.Port), upstream, requestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	if err = exec.waitReady(ctx, time.Second); err != nil {
+		return err
+	}
+
+	// 4. run the cases and report
+	start := time.Now()
+	report := &JUnitTestSuites{}
+	for i, spec := range specs {
+		suite := exec.runSuite(i, spec)
+		report.Add(suite)
+		r.printSuite(suite)
+	}
+	report.Time = formatSeconds(time.Since(start))
+
+	if r.JUnitReport != "" {
+		if err = report.WriteFile(r.JUnitReport); err != nil {
+			return errors.Wrapf(err, "failed to write the JUnit report %q", r.JUnitReport)
+		}
+	}
+	fmt.Fprintf(r.w, "\n%d tests, %d failures, %d skipped\n", report.Tests, report.Failures, report.Skipped)
+	if report.Failures > 0 {
+		return errors.Errorf("%d of %d test cases failed", report.Failures, report.Tests)
+	}
+
+	return nil
+}
+
+func (r *runner) printSuite(suite JUnitTestSuite) {
+	printer := utils.NewPrinter(r.w, utils.NewIndent(strings.Repeat(" ", 2), 0), utils.DefaultYes, utils.DefaultNo)
+	printer.Printf("%s\n", suite.Name)
+	printer.IncIdentRepeat()
+	for _, tc := range suite.TestCases {
+		switch {
+		case tc.Skipped != nil:
+			printer.PrintWithIndentf("SKIP %s\n", tc.Name)
+		case tc.Failure != nil:
+			printer.NoWithIndentf("FAIL %s (%ss)\n", tc.Name, tc.Time)
+			printer.IncIdentRepeat()
+			for _, line := range strings.Split(tc.Failure.Contents, "\n") {
+				printer.PrintWithIndentln(line)
+			}
+			printer.DecIndentRepeat()
+		default:
+			printer.YesWithIndentf("PASS %s (%ss)\n", tc.Name, tc.Time)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// TestSpec is a declarative test suite of a WASM plugin. Each case runs a request through the plugin
+// configured with the case configuration, against a mock upstream answering with the case response
+type TestSpec struct {
+	Name string `json:"name" yaml:"name"`
+	// Config is the plugin configuration of the cases without their own configuration
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	Cases  []TestCase             `json:"cases" yaml:"cases"`
+}
+
+type TestCase struct {
+	Name string `json:"name" yaml:"name"`
+	Skip bool   `json:"skip,omitempty" yaml:"skip,omitempty"`
+	// Config overrides the plugin configuration of the spec
+	Config   map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	Request  TestRequest            `json:"request" yaml:"request"`
+	Upstream *UpstreamResponse      `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+	Expect   Expectation            `json:"expect" yaml:"expect"`
+}
+
+type TestRequest struct {
+	Method  string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Path    string            `json:"path,omitempty" yaml:"path,omitempty"`
+	Host    string            `json:"host,omitempty" yaml:"host,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// UpstreamResponse is the response of the mock upstream. Without it the mock upstream answers
+// 200 with a JSON echo of the request it received
+type UpstreamResponse struct {
+	Status  int               `json:"status,omitempty" yaml:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// MessageExpectation holds the assertions on the headers and body of an HTTP message
+type MessageExpectation struct {
+	Headers       map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	AbsentHeaders []string          `json:"absentHeaders,omitempty" yaml:"absentHeaders,omitempty"`
+	Body          *string           `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyContains  []string          `json:"bodyContains,omitempty" yaml:"bodyContains,omitempty"`
+	// JSONPath maps paths such as `data.items[0].name` of a JSON body to their expected value
+	JSONPath map[string]interface{} `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+}
+
+type Expectation struct {
+	Status             int `json:"status,omitempty" yaml:"status,omitempty"`
+	MessageExpectation `json:",inline" yaml:",inline"`
+	// Upstream asserts on the request received by the mock upstream, that is after the plugin processed it
+	Upstream *UpstreamExpectation `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+}
+
+type UpstreamExpectation struct {
+	// Called asserts whether the request reached the upstream, it defaults to true
+	Called             *bool `json:"called,omitempty" yaml:"called,omitempty"`
+	MessageExpectation `json:",inline" yaml:",inline"`
+}
+
+// ParseTestSpec reads a test spec from a YAML file
+func ParseTestSpec(path string) (*TestSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec TestSpec
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err = dec.Decode(&spec); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse test spec %q", path)
+	}
+	if err = spec.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid test spec %q", path)
+	}
+
+	return &spec, nil
+}
+
+func (s *TestSpec) validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(s.Cases) == 0 {
+		return errors.New("at least one case is required")
+	}
+	names := make(map[string]bool, len(s.Cases))
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Name == "" {
+			return errors.Errorf("cases[%d]: name is required", i)
+		}
+		if names[c.Name] {
+			return errors.Errorf("cases[%d]: duplicate name %q", i, c.Name)
+		}
+		names[c.Name] = true
+
+		if c.Request.Method == "" {
+			c.Request.Method = http.MethodGet
+		}
+		if c.Request.Path == "" {
+			c.Request.Path = "/"
+		}
+		if !strings.HasPrefix(c.Request.Path, "/") {
+			return errors.Errorf("case %q: request path must start with '/'", c.Name)
+		}
+		if c.Upstream != nil && c.Upstream.Status == 0 {
+			c.Upstream.Status = http.StatusOK
+		}
+	}
+
+	return nil
+}
+
+// PluginConfig returns the plugin configuration used by the case
+func (s *TestSpec) PluginConfig(c *TestCase) map[string]interface{} {
+	if c.Config != nil {
+		return c.Config
+	}
+	if s.Config != nil {
+		return s.Config
+	}
+	return map[string]interface{}{}
+}
+
+// Check returns the failed assertions on a message
+func (e *MessageExpectation) Check(header http.Header, body []byte) []string {
+	var failures []string
+	for name, want := range e.Headers {
+		values, ok := header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("header %q: expected %q, but it is absent", name, want))
+			continue
+		}
+		if got := strings.Join(values, ","); got != want {
+			failures = append(failures, fmt.Sprintf("header %q: expected %q, got %q", name, want, got))
+		}
+	}
+	for _, name := range e.AbsentHeaders {
+		if got := header.Get(name); got != "" {
+			failures = append(failures, fmt.Sprintf("header %q: expected absent, got %q", name, got))
+		}
+	}
+
+	if e.Body != nil && string(body) != *e.Body {
+		failures = append(failures, fmt.Sprintf("body: expected %q, got %q", *e.Body, truncate(body)))
+	}
+	for _, sub := range e.BodyContains {
+		if !bytes.Contains(body, []byte(sub)) {
+			failures = append(failures, fmt.Sprintf("body: expected to contain %q, got %q", sub, truncate(body)))
+		}
+	}
+
+	if len(e.JSONPath) > 0 {
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return append(failures, fmt.Sprintf("body: expected JSON, got %q", truncate(body)))
+		}
+		for path, want := range e.JSONPath {
+			got, err := lookupJSONPath(doc, path)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("jsonPath %q: %v", path, err))
+				continue
+			}
+			if !jsonEqual(got, want) {
+				failures = append(failures, fmt.Sprintf("jsonPath %q: expected %s, got %s", path, toJSON(want), toJSON(got)))
+			}
+		}
+	}
+
+	return failures
+}
+
+// lookupJSONPath resolves a dotted path with optional array indexes, e.g. `choices[0].message.content`
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	cur := doc
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "$."), ".") {
+		key := segment
+		var indexes []string
+		if i := strings.Index(segment, "["); i >= 0 {
+			key = segment[:i]
+			for _, idx := range strings.Split(segment[i:], "]") {
+				if idx == "" {
+					continue
+				}
+				if !strings.HasPrefix(idx, "[") {
+					return nil, errors.Errorf("invalid path segment %q", segment)
+				}
+				indexes = append(indexes, strings.TrimPrefix(idx, "["))
+			}
+		}
+
+		if key != "" {
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("%q is not an object", key)
+			}
+			if cur, ok = obj[key]; !ok {
+				return nil, errors.Errorf("key %q not found", key)
+			}
+		}
+		for _, idx := range indexes {
+			i, err := strconv.Atoi(idx)
+			if err != nil {
+				return nil, errors.Errorf("invalid index %q", idx)
+			}
+			arr, ok := cur.([]interface{})
+			if !ok {
+				return nil, errors.Errorf("%q is not an array", segment)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, errors.Errorf("index %d out of range", i)
+			}
+			cur = arr[i]
+		}
+	}
+
+	return cur, nil
+}
+
+// jsonEqual compares values after a JSON round trip, so numbers decoded from YAML and JSON are comparable
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(a), normalizeJSON(b))
+}
+
+func normalizeJSON(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err = json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+func truncate(body []byte) string {
+	const max = 512
+	if len(body) > max {
+		return string(body[:max]) + "..."
+	}
+	return string(body)
+}
@@ -15,9 +15,15 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"text/template"
+
+	"github.com/alibaba/higress/hgctl/pkg/plugin/utils"
+
+	"github.com/pkg/errors"
 )
 
 const (
@@ -133,6 +139,103 @@ static_resources:
                       address: httpbin
                       port_value: 80
 `
+
+	runDockerComposeYAML = `# File generated by hgctl plugin test run. Do not modify.
+
+version: '3.7'
+services:
+  envoy:
+    image: higress-registry.cn-hangzhou.cr.aliyuncs.com/higress/envoy:1.20
+    command: envoy -c /etc/envoy/envoy.yaml --component-log-level wasm:debug
+    extra_hosts:
+    - "host.docker.internal:host-gateway"
+    ports:
+    - "{{ .Port }}:10000"
+    volumes:
+    - {{ .RunPath }}/envoy.yaml:/etc/envoy/envoy.yaml
+    - {{ .ProductPath }}/plugin.wasm:/etc/envoy/plugin.wasm
+`
+
+	runEnvoyYAML = `# File generated by hgctl plugin test run. Do not modify.
+
+admin:
+  address:
+    socket_address:
+      protocol: TCP
+      address: 0.0.0.0
+      port_value: 9901
+static_resources:
+  listeners:
+    - name: listener_0
+      address:
+        socket_address:
+          protocol: TCP
+          address: 0.0.0.0
+          port_value: 10000
+      filter_chains:
+        - filters:
+            - name: envoy.filters.network.http_connection_manager
+              typed_config:
+                "@type": type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager
+                stat_prefix: ingress_http
+                route_config:
+                  name: local_route
+                  virtual_hosts:
+                    - name: local_service
+                      domains: ["*"]
+                      routes:
+{{- range .Routes }}
+                        - name: {{ .Name }}
+                          match:
+                            prefix: "/"
+                            headers:
+                              - name: {{ $.CaseHeader }}
+                                string_match:
+                                  exact: "{{ .CaseID }}"
+                          route:
+                            cluster: mock_upstream
+                          request_headers_to_add:
+                            - header:
+                                key: {{ $.CaseHeader }}
+                                value: "{{ .CaseID }}"
+                              append: false
+{{- end }}
+                http_filters:
+                  - name: wasmtest
+                    typed_config:
+                      "@type": type.googleapis.com/udpa.type.v1.TypedStruct
+                      type_url: type.googleapis.com/envoy.extensions.filters.http.wasm.v3.Wasm
+                      value:
+                        config:
+                          name: wasmtest
+                          vm_config:
+                            runtime: envoy.wasm.runtime.v8
+                            code:
+                              local:
+                                filename: /etc/envoy/plugin.wasm
+                          configuration:
+                            "@type": "type.googleapis.com/google.protobuf.StringValue"
+                            value: |
+{{ .JSONConfig }}
+                  - name: envoy.filters.http.router
+                    typed_config:
+                      "@type": type.googleapis.com/envoy.extensions.filters.http.router.v3.Router
+  clusters:
+    - name: mock_upstream
+      connect_timeout: 30s
+      type: LOGICAL_DNS
+      dns_lookup_family: V4_ONLY
+      lb_policy: ROUND_ROBIN
+      load_assignment:
+        cluster_name: mock_upstream
+        endpoints:
+          - lb_endpoints:
+              - endpoint:
+                  address:
+                    socket_address:
+                      address: {{ .UpstreamHost }}
+                      port_value: {{ .UpstreamPort }}
+`
 )
 
 type DockerCompose struct {
@@ -173,3 +276,70 @@ func genEnvoyYAML(e *Envoy, dir string) error {
 
 	return nil
 }
+
+// RunEnv is the environment of `hgctl plugin test run`: a single Envoy with one route per case and
+// the plugin configured per route through the `_rules_` of the plugin configuration
+type RunEnv struct {
+	RunPath      string
+	ProductPath  string
+	Port         int
+	UpstreamHost string
+	UpstreamPort int
+	CaseHeader   string
+	Routes       []RunRoute
+	JSONConfig   string
+}
+
+type RunRoute struct {
+	Name   string
+	CaseID string
+}
+
+func genRunEnvFiles(e *RunEnv) error {
+	files := map[string]string{
+		"docker-compose.yaml": runDockerComposeYAML,
+		"envoy.yaml":          runEnvoyYAML,
+	}
+	for name, tmpl := range files {
+		path := fmt.Sprintf("%s/%s", e.RunPath, name)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = template.Must(template.New(name).Parse(tmpl)).Execute(f, e)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newRunEnv routes each case to its own route and matches the case plugin configuration to that route
+func newRunEnv(specs []*TestSpec) (*RunEnv, error) {
+	e := &RunEnv{CaseHeader: caseHeader}
+	rules := make([]map[string]interface{}, 0)
+	for i, spec := range specs {
+		for j := range spec.Cases {
+			id := testCaseID(i, j)
+			route := RunRoute{Name: testRouteName(id), CaseID: id}
+			e.Routes = append(e.Routes, route)
+
+			rule := make(map[string]interface{})
+			for k, v := range spec.PluginConfig(&spec.Cases[j]) {
+				rule[k] = v
+			}
+			rule["_match_route_"] = []string{route.Name}
+			rules = append(rules, rule)
+		}
+	}
+
+	b, err := json.MarshalIndent(map[string]interface{}{"_rules_": rules}, "", strings.Repeat(" ", 2))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the plugin configuration to json")
+	}
+	e.JSONConfig = utils.AddIndent(string(b), strings.Repeat(" ", 30))
+
+	return e, nil
+}
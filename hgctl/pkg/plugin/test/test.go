@@ -30,6 +30,7 @@ func NewCommand() *cobra.Command {
 	testCmd.AddCommand(newStopCommand())
 	testCmd.AddCommand(newCleanCommand())
 	testCmd.AddCommand(newLsCommand())
+	testCmd.AddCommand(newRunCommand())
 
 	return testCmd
 }
@@ -18,6 +18,8 @@ import (
 	"os"
 
 	"github.com/alibaba/higress/hgctl/pkg/agent"
+	"github.com/alibaba/higress/hgctl/pkg/config"
+	"github.com/alibaba/higress/hgctl/pkg/migrate"
 	"github.com/alibaba/higress/hgctl/pkg/plugin"
 	"github.com/spf13/cobra"
 )
@@ -41,6 +43,8 @@ func GetRootCommand() *cobra.Command {
 	rootCmd.AddCommand(newDashboardCmd())
 	rootCmd.AddCommand(newManifestCmd())
 	rootCmd.AddCommand(plugin.NewCommand())
+	rootCmd.AddCommand(migrate.NewCommand())
+	rootCmd.AddCommand(config.NewCommand())
 	rootCmd.AddCommand(newCompletionCmd(os.Stdout))
 	rootCmd.AddCommand(newCodeDebugCmd())
 	rootCmd.AddCommand(agent.NewMCPCmd())
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"istio.io/istio/pkg/config/mesh/meshwatcher"
@@ -50,10 +51,13 @@ import (
 
 	"github.com/alibaba/higress/v2/pkg/cert"
 	higressconfig "github.com/alibaba/higress/v2/pkg/config"
+	ingressconfig "github.com/alibaba/higress/v2/pkg/ingress/config"
 	"github.com/alibaba/higress/v2/pkg/ingress/kube/common"
 	"github.com/alibaba/higress/v2/pkg/ingress/mcp"
 	"github.com/alibaba/higress/v2/pkg/ingress/translation"
 	higresskube "github.com/alibaba/higress/v2/pkg/kube"
+	"github.com/alibaba/higress/v2/pkg/kube/filesource"
+	"github.com/alibaba/higress/v2/pkg/webhook"
 )
 
 type XdsOptions struct {
@@ -73,8 +77,9 @@ type XdsOptions struct {
 }
 
 // RegistryOptions provide configuration options for the configuration controller. If FileDir is set, that directory will
-// be monitored for CRD yaml files and will update the controller as those files change (This is used for testing
-// purposes). Otherwise, a CRD client is created based on the configuration.
+// be monitored for yaml files of Ingress, Gateway API, Higress CRDs, Secrets and ConfigMaps, and will update the
+// controller as those files change (This is used for standalone deployments and testing purposes). Otherwise, a
+// CRD client is created based on the configuration.
 type RegistryOptions struct {
 	// If FileDir is set, the below kubernetes options are ignored
 	FileDir string
@@ -123,6 +128,8 @@ type ServerArgs struct {
 	EnableAutomaticHttps bool
 	AutomaticHttpsEmail  string
 	CertHttpAddress      string
+	// WebhookOptions configures the validating admission webhook, which is disabled if the address is empty
+	WebhookOptions webhook.Options
 }
 
 type readinessProbe func() (bool, error)
@@ -146,6 +153,8 @@ type Server struct {
 	server           server.Instance
 	readinessProbes  map[string]readinessProbe
 	certServer       *cert.Server
+	fileSource       *filesource.Source
+	webhookServer    *webhook.Server
 }
 
 func NewServer(args *ServerArgs) (*Server, error) {
@@ -173,6 +182,7 @@ func NewServer(args *ServerArgs) (*Server, error) {
 		s.initRegistryEventHandlers,
 		s.initAuthenticators,
 		s.initAutomaticHttps,
+		s.initWebhookServer,
 	}
 
 	for _, f := range initFuncList {
@@ -408,11 +418,37 @@ func (s *Server) initAutomaticHttps() error {
 	return s.certServer.InitServer()
 }
 
+func (s *Server) initWebhookServer() error {
+	if s.WebhookOptions.Address == "" {
+		log.Info("validating webhook is disabled")
+		return nil
+	}
+	s.WebhookOptions.IngressClass = s.IngressClass
+	s.WebhookOptions.WatchNamespace = s.WatchNamespace
+	webhookServer, err := webhook.NewServer(s.WebhookOptions)
+	if err != nil {
+		return fmt.Errorf("failed creating validating webhook: %v", err)
+	}
+	s.webhookServer = webhookServer
+	s.server.RunComponent("validating-webhook", func(stop <-chan struct{}) error {
+		go func() {
+			if err := webhookServer.Run(stop); err != nil {
+				log.Errorf("error serving validating webhook: %v", err)
+			}
+		}()
+		return nil
+	})
+	return nil
+}
+
 func (s *Server) initKubeClient() error {
 	if s.kubeClient != nil {
 		// Already initialized by startup arguments
 		return nil
 	}
+	if s.RegistryOptions.FileDir != "" {
+		return s.initFileSource()
+	}
 	kubeRestConfig, err := istiokube.DefaultRestConfig(s.RegistryOptions.KubeConfig, "", func(config *rest.Config) {
 		config.QPS = s.RegistryOptions.KubeOptions.KubernetesAPIQPS
 		config.Burst = s.RegistryOptions.KubeOptions.KubernetesAPIBurst
@@ -428,6 +464,27 @@ func (s *Server) initKubeClient() error {
 	return nil
 }
 
+// initFileSource feeds the controllers from the manifests in FileDir through a fake kube client instead of
+// a Kubernetes API server.
+func (s *Server) initFileSource() error {
+	kubeClient := higresskube.NewFakeClient()
+	source, err := filesource.NewSource(s.RegistryOptions.FileDir, kubeClient)
+	if err != nil {
+		return fmt.Errorf("failed creating file source: %v", err)
+	}
+	if err := source.Load(); err != nil {
+		return fmt.Errorf("failed loading files from %s: %v", s.RegistryOptions.FileDir, err)
+	}
+	s.kubeClient = kubeClient
+	s.fileSource = source
+	s.server.RunComponent("file-source", func(stop <-chan struct{}) error {
+		go source.Run(stop)
+		return nil
+	})
+	log.Infof("loading config from directory %s instead of kubernetes", s.RegistryOptions.FileDir)
+	return nil
+}
+
 func (s *Server) initHttpServer() error {
 	s.httpServer = &http.Server{
 		Addr:        s.HttpAddress,
@@ -438,6 +495,11 @@ func (s *Server) initHttpServer() error {
 	s.xdsServer.AddDebugHandlers(s.httpMux, nil, true, nil)
 	s.httpMux.HandleFunc("/ready", s.readyHandler)
 	s.httpMux.HandleFunc("/registry/watcherStatus", s.withConditionalAuth(s.registryWatcherStatusHandler))
+	s.httpMux.HandleFunc("/debug/explain", s.withConditionalAuth(s.explainHandler))
+	s.httpMux.HandleFunc("/debug/certificates", s.withConditionalAuth(s.certificatesHandler))
+	if s.fileSource != nil {
+		s.httpMux.HandleFunc("/debug/configFiles", s.withConditionalAuth(s.configFilesHandler))
+	}
 	return nil
 }
 
@@ -460,6 +522,8 @@ func (s *Server) readyHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// registryWatcherStatusHandler reports the status of the registry watchers. The health check status of the endpoints
+// is probed from the controller, the health of the gateway clusters is available from the admin API of the gateway
 func (s *Server) registryWatcherStatusHandler(w http.ResponseWriter, _ *http.Request) {
 	ingressTranslation, ok := s.environment.IngressStore.(*translation.IngressTranslation)
 	if !ok {
@@ -483,6 +547,60 @@ func (s *Server) registryWatcherStatusHandler(w http.ResponseWriter, _ *http.Req
 	writeJSON(w, watcherStatusList)
 }
 
+// certificatesHandler serves the status of the certificates managed by automatic https
+func (s *Server) certificatesHandler(w http.ResponseWriter, r *http.Request) {
+	if s.certServer == nil || s.certServer.Inventory() == nil {
+		http.Error(w, "automatic https is disabled", http.StatusNotFound)
+		return
+	}
+	s.certServer.Inventory().ServeHTTP(w, r)
+}
+
+// explainHandler explains the configs generated from an ingress, selected by ingress=namespace/name,
+// or from the ingresses matching host and path
+func (s *Server) explainHandler(w http.ResponseWriter, r *http.Request) {
+	ingressTranslation, ok := s.environment.IngressStore.(*translation.IngressTranslation)
+	if !ok {
+		http.Error(w, "IngressStore not found", http.StatusNotFound)
+		return
+	}
+
+	ingressConfig := ingressTranslation.GetIngressConfig()
+	if ingressConfig == nil {
+		http.Error(w, "IngressConfig not found", http.StatusNotFound)
+		return
+	}
+
+	query := ingressconfig.ExplainQuery{
+		Host: r.URL.Query().Get("host"),
+		Path: r.URL.Query().Get("path"),
+	}
+	if ingress := r.URL.Query().Get("ingress"); ingress != "" {
+		namespace, name, found := strings.Cut(ingress, "/")
+		if !found || namespace == "" || name == "" {
+			http.Error(w, "ingress should be in the format of namespace/name", http.StatusBadRequest)
+			return
+		}
+		query.Namespace, query.Name = namespace, name
+	}
+	if query.Name == "" && query.Host == "" {
+		http.Error(w, "either ingress or host is required", http.StatusBadRequest)
+		return
+	}
+
+	result := ingressConfig.Explain(query)
+	if len(result.Ingresses) == 0 {
+		http.Error(w, "no ingress matched", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// configFilesHandler reports the load result of every file of the file source
+func (s *Server) configFilesHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, s.fileSource.Status())
+}
+
 func writeJSON(w http.ResponseWriter, obj interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	b, err := config.ToJSON(obj)
@@ -0,0 +1,191 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/mholt/acmez"
+	"github.com/mholt/acmez/acme"
+)
+
+const (
+	EABSecretKeyID  = "keyId"
+	EABSecretMACKey = "macKey"
+	CACertSecretKey = "ca.crt"
+)
+
+var acmeKeyTypes = map[string]certmagic.KeyType{
+	string(certmagic.ED25519): certmagic.ED25519,
+	string(certmagic.P256):    certmagic.P256,
+	string(certmagic.P384):    certmagic.P384,
+	string(certmagic.RSA2048): certmagic.RSA2048,
+	string(certmagic.RSA4096): certmagic.RSA4096,
+	string(certmagic.RSA8192): certmagic.RSA8192,
+}
+
+// IsACME reports whether the issuer obtains certificates with the ACME protocol
+func (e *ACMEIssuerEntry) IsACME() bool {
+	return e.Name == IssuerTypeLetsencrypt || e.Directory != ""
+}
+
+// GetDirectory returns the ACME directory URL of the issuer
+func (e *ACMEIssuerEntry) GetDirectory() string {
+	if e.Directory == "" && e.Name == IssuerTypeLetsencrypt {
+		return certmagic.LetsEncryptProductionCA
+	}
+	return e.Directory
+}
+
+// Validate checks the settings of an ACME issuer, the secrets it refers to are checked when the issuer is built
+func (e *ACMEIssuerEntry) Validate() error {
+	if e.Name == IssuerTypeLetsencrypt && e.Email == "" {
+		return fmt.Errorf("acmeIssuer %s email is empty", e.Name)
+	}
+	if e.Email != "" && !ValidateEmail(e.Email) {
+		return fmt.Errorf("acmeIssuer %s email %s is invalid", e.Name, e.Email)
+	}
+	if e.Directory != "" {
+		u, err := url.Parse(e.Directory)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("acmeIssuer %s directory %s is invalid, an https URL is required", e.Name, e.Directory)
+		}
+	}
+	for _, secret := range []string{e.EABSecret, e.CACertSecret} {
+		if secret == "" {
+			continue
+		}
+		if ns, name := ParseTLSSecret(secret); ns == "" && name == "" {
+			return fmt.Errorf("acmeIssuer %s secret %s is not supported", e.Name, secret)
+		}
+	}
+	if e.KeyType != "" {
+		if _, ok := acmeKeyTypes[e.KeyType]; !ok {
+			keyTypes := make([]string, 0, len(acmeKeyTypes))
+			for keyType := range acmeKeyTypes {
+				keyTypes = append(keyTypes, keyType)
+			}
+			sort.Strings(keyTypes)
+			return fmt.Errorf("acmeIssuer %s keyType %s is not supported, supported key types: %s", e.Name, e.KeyType, strings.Join(keyTypes, ", "))
+		}
+	}
+	if e.DNS01 != nil {
+		if err := e.DNS01.Validate(); err != nil {
+			return fmt.Errorf("acmeIssuer %s dns01 is invalid: %v", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// acmeIssuerOptions are the settings of an ACME issuer together with the content of the secrets it refers to,
+// the issuer is rebuilt when any of them changes
+type acmeIssuerOptions struct {
+	Entry  ACMEIssuerEntry
+	EAB    *acme.EAB
+	CACert []byte
+}
+
+func loadACMEIssuerOptions(secretMgr *SecretMgr, issuer *ACMEIssuerEntry) (*acmeIssuerOptions, error) {
+	options := &acmeIssuerOptions{Entry: *issuer}
+	if issuer.EABSecret != "" {
+		secret, err := secretMgr.Get(issuer.EABSecret)
+		if err != nil {
+			return nil, fmt.Errorf("get eab secret %s error: %v", issuer.EABSecret, err)
+		}
+		keyID := strings.TrimSpace(string(secret.Data[EABSecretKeyID]))
+		macKey := strings.TrimSpace(string(secret.Data[EABSecretMACKey]))
+		if keyID == "" || macKey == "" {
+			return nil, fmt.Errorf("eab secret %s should have both %s and %s", issuer.EABSecret, EABSecretKeyID, EABSecretMACKey)
+		}
+		options.EAB = &acme.EAB{KeyID: keyID, MACKey: macKey}
+	}
+	if issuer.CACertSecret != "" {
+		secret, err := secretMgr.Get(issuer.CACertSecret)
+		if err != nil {
+			return nil, fmt.Errorf("get cacert secret %s error: %v", issuer.CACertSecret, err)
+		}
+		options.CACert = secret.Data[CACertSecretKey]
+		if len(options.CACert) == 0 {
+			return nil, fmt.Errorf("cacert secret %s has no %s", issuer.CACertSecret, CACertSecretKey)
+		}
+	}
+	return options, nil
+}
+
+// acmeManager obtains and renews the certificates of an ACME issuer. The domains solved with the dns-01
+// challenge are managed by dnsCfg, since the dns-01 solver of certmagic is exclusive of the other challenges.
+type acmeManager struct {
+	options *acmeIssuerOptions
+	cfg     *certmagic.Config
+	myACME  *certmagic.ACMEIssuer
+	dnsCfg  *certmagic.Config
+	dnsACME *certmagic.ACMEIssuer
+}
+
+func newACMEManager(cache *certmagic.Cache, template certmagic.Config, http01Solver acmez.Solver, secretMgr *SecretMgr, options *acmeIssuerOptions) (*acmeManager, error) {
+	entry := options.Entry
+	if entry.KeyType != "" {
+		template.KeySource = certmagic.StandardKeyGenerator{KeyType: acmeKeyTypes[entry.KeyType]}
+	}
+	issuerTemplate := certmagic.ACMEIssuer{
+		CA:                      entry.GetDirectory(),
+		Email:                   entry.Email,
+		Agreed:                  true,
+		ExternalAccount:         options.EAB,
+		DisableHTTPChallenge:    false,
+		DisableTLSALPNChallenge: true,
+	}
+	if len(options.CACert) > 0 {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(options.CACert) {
+			return nil, fmt.Errorf("no valid PEM certificate found in cacert secret %s", entry.CACertSecret)
+		}
+		issuerTemplate.TrustedRoots = roots
+	}
+	if entry.PreferredChain != "" {
+		issuerTemplate.PreferredChains = certmagic.ChainPreference{RootCommonName: []string{entry.PreferredChain}}
+	}
+
+	m := &acmeManager{options: options}
+	m.cfg = certmagic.New(cache, template)
+	m.myACME = certmagic.NewACMEIssuer(m.cfg, issuerTemplate)
+	m.myACME.Http01Solver = http01Solver
+	m.cfg.Issuers = []certmagic.Issuer{m.myACME}
+
+	dnsTemplate := issuerTemplate
+	dnsTemplate.DisableHTTPChallenge = true
+	if entry.DNS01 != nil {
+		solver, err := newDNS01SolverWithSecret(secretMgr, entry.DNS01)
+		if err != nil {
+			return nil, fmt.Errorf("init dns01 solver error: %v", err)
+		}
+		dnsTemplate.DNS01Solver = solver
+	}
+	m.dnsCfg = certmagic.New(cache, template)
+	m.dnsACME = certmagic.NewACMEIssuer(m.dnsCfg, dnsTemplate)
+	m.dnsCfg.Issuers = []certmagic.Issuer{m.dnsACME}
+	return m, nil
+}
+
+// setRenewalWindowRatio updates the renewal window of the managed certificates
+func (m *acmeManager) setRenewalWindowRatio(ratio float64) {
+	m.cfg.RenewalWindowRatio = ratio
+	m.dnsCfg.RenewalWindowRatio = ratio
+}
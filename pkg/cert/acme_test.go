@@ -0,0 +1,223 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+	"github.com/mholt/acmez/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestCACert(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Internal Root CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newTestSecret(namespace, name string, data map[string]string) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       make(map[string][]byte, len(data)),
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+func TestLoadACMEIssuerOptions(t *testing.T) {
+	caCert := newTestCACert(t)
+	client := fake.NewSimpleClientset(
+		newTestSecret("higress-system", "eab", map[string]string{EABSecretKeyID: "kid-1", EABSecretMACKey: "bWFjLWtleQ"}),
+		newTestSecret("higress-system", "eab-no-mac", map[string]string{EABSecretKeyID: "kid-1"}),
+		newTestSecret("default", "root-ca", map[string]string{CACertSecretKey: string(caCert)}),
+	)
+	secretMgr, _ := NewSecretMgr("higress-system", client)
+
+	tests := []struct {
+		name           string
+		issuer         ACMEIssuerEntry
+		expectedEAB    *acme.EAB
+		expectedCACert []byte
+		expectedErr    bool
+	}{
+		{
+			name: "eab and root ca",
+			issuer: ACMEIssuerEntry{
+				Name:         "internal-ca",
+				Directory:    "https://ca.internal/directory",
+				EABSecret:    "eab",
+				CACertSecret: "default/root-ca",
+			},
+			expectedEAB:    &acme.EAB{KeyID: "kid-1", MACKey: "bWFjLWtleQ"},
+			expectedCACert: caCert,
+		},
+		{
+			name: "eab without mac key",
+			issuer: ACMEIssuerEntry{
+				Name:      "internal-ca",
+				Directory: "https://ca.internal/directory",
+				EABSecret: "eab-no-mac",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "missing root ca secret",
+			issuer: ACMEIssuerEntry{
+				Name:         "internal-ca",
+				Directory:    "https://ca.internal/directory",
+				CACertSecret: "root-ca",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := loadACMEIssuerOptions(secretMgr, &tt.issuer)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.issuer, options.Entry)
+			assert.Equal(t, tt.expectedEAB, options.EAB)
+			assert.Equal(t, tt.expectedCACert, options.CACert)
+		})
+	}
+}
+
+func TestNewACMEManager(t *testing.T) {
+	cache := certmagic.NewCache(certmagic.CacheOptions{
+		GetConfigForCert: func(certmagic.Certificate) (*certmagic.Config, error) { return nil, nil },
+	})
+	defer cache.Stop()
+	template := certmagic.Config{Storage: &certmagic.FileStorage{Path: t.TempDir()}}
+
+	manager, err := newACMEManager(cache, template, nil, nil, &acmeIssuerOptions{
+		Entry: ACMEIssuerEntry{
+			Name:           "internal-ca",
+			Directory:      "https://ca.internal/directory",
+			KeyType:        "rsa2048",
+			PreferredChain: "Internal Root CA",
+			DNS01: &DNS01Config{
+				Provider: DNSProviderRFC2136,
+				Config:   map[string]string{"nameserver": "10.0.0.53"},
+			},
+		},
+		EAB:    &acme.EAB{KeyID: "kid-1", MACKey: "bWFjLWtleQ"},
+		CACert: newTestCACert(t),
+	})
+	require.NoError(t, err)
+	for _, issuer := range []*certmagic.ACMEIssuer{manager.myACME, manager.dnsACME} {
+		assert.Equal(t, "https://ca.internal/directory", issuer.CA)
+		assert.Empty(t, issuer.TestCA)
+		assert.Equal(t, "kid-1", issuer.ExternalAccount.KeyID)
+		assert.NotNil(t, issuer.TrustedRoots)
+		assert.Equal(t, []string{"Internal Root CA"}, issuer.PreferredChains.RootCommonName)
+	}
+	assert.False(t, manager.myACME.DisableHTTPChallenge)
+	assert.Nil(t, manager.myACME.DNS01Solver)
+	assert.True(t, manager.dnsACME.DisableHTTPChallenge)
+	assert.NotNil(t, manager.dnsACME.DNS01Solver)
+	assert.Equal(t, certmagic.StandardKeyGenerator{KeyType: certmagic.RSA2048}, manager.cfg.KeySource)
+
+	_, err = newACMEManager(cache, template, nil, nil, &acmeIssuerOptions{
+		Entry:  ACMEIssuerEntry{Name: "internal-ca", Directory: "https://ca.internal/directory", CACertSecret: "root-ca"},
+		CACert: []byte("not a certificate"),
+	})
+	assert.Error(t, err)
+}
+
+// noopDNSProvider pretends to publish the challenge records, it only works with a CA skipping validation
+type noopDNSProvider struct{}
+
+func (noopDNSProvider) AppendRecords(_ context.Context, _ string, recs []libdns.Record) ([]libdns.Record, error) {
+	return recs, nil
+}
+
+func (noopDNSProvider) DeleteRecords(_ context.Context, _ string, recs []libdns.Record) ([]libdns.Record, error) {
+	return recs, nil
+}
+
+// TestACMEManagerPebble obtains a wildcard certificate from a local Pebble instance started with
+// PEBBLE_VA_ALWAYS_VALID=1, e.g. PEBBLE_DIRECTORY=https://localhost:14000/dir PEBBLE_CA_CERT=test/certs/pebble.minica.pem
+func TestACMEManagerPebble(t *testing.T) {
+	directory := os.Getenv("PEBBLE_DIRECTORY")
+	caCertFile := os.Getenv("PEBBLE_CA_CERT")
+	if directory == "" || caCertFile == "" {
+		t.Skip("PEBBLE_DIRECTORY and PEBBLE_CA_CERT are not set")
+	}
+	caCert, err := os.ReadFile(caCertFile)
+	require.NoError(t, err)
+	RegisterDNSProvider("noop", func(map[string]string) (certmagic.ACMEDNSProvider, error) {
+		return noopDNSProvider{}, nil
+	})
+
+	var manager *acmeManager
+	cache := certmagic.NewCache(certmagic.CacheOptions{
+		GetConfigForCert: func(certmagic.Certificate) (*certmagic.Config, error) { return manager.dnsCfg, nil },
+	})
+	defer cache.Stop()
+	template := certmagic.Config{Storage: &certmagic.FileStorage{Path: t.TempDir()}}
+	manager, err = newACMEManager(cache, template, nil, nil, &acmeIssuerOptions{
+		Entry: ACMEIssuerEntry{
+			Name:      "pebble",
+			Email:     "admin@example.com",
+			Directory: directory,
+			KeyType:   "rsa2048",
+			DNS01:     &DNS01Config{Provider: "noop", PropagationTimeoutSeconds: -1},
+		},
+		CACert: caCert,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(t, manager.dnsCfg.ObtainCertSync(ctx, "*.example.com"))
+	cert, err := manager.dnsCfg.CacheManagedCertificate(ctx, "*.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*.example.com"}, cert.Names)
+	assert.IsType(t, &rsa.PrivateKey{}, cert.PrivateKey)
+}
@@ -19,18 +19,34 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/caddyserver/certmagic"
 	"github.com/mholt/acmez"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	istiomodel "istio.io/istio/pilot/pkg/model"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
-	EventCertObtained = "cert_obtained"
+	EventCertObtaining = "cert_obtaining"
+	EventCertObtained  = "cert_obtained"
+	EventCertFailed    = "cert_failed"
+)
+
+// Reasons of the kubernetes events raised on the higress-https configmap
+const (
+	ReasonCertObtained       = "CertificateObtained"
+	ReasonCertObtainFailed   = "CertificateObtainFailed"
+	ReasonCertRenewFailed    = "CertificateRenewFailed"
+	ReasonSecretUpdateFailed = "CertificateSecretUpdateFailed"
 )
 
 var (
@@ -49,6 +65,16 @@ type CertMgr struct {
 	configMgr     *ConfigMgr
 	secretMgr     *SecretMgr
 	XDSUpdater    istiomodel.XDSUpdater
+
+	// magicConfig is the template of the certmagic configs of the ACME issuers
+	magicConfig certmagic.Config
+	// managers are keyed by the ACME issuer names
+	managers map[IssuerName]*acmeManager
+	// domains maps the managed domains to the certmagic config obtaining their certificates
+	domains map[string]*certmagic.Config
+	// inventory keeps the status of the managed certificates
+	inventory *Inventory
+	recorder  record.EventRecorder
 }
 
 func InitCertMgr(opts *Option, clientSet kubernetes.Interface, config *Config, XDSUpdater istiomodel.XDSUpdater, configMgr *ConfigMgr) (*CertMgr, error) {
@@ -58,6 +84,7 @@ func InitCertMgr(opts *Option, clientSet kubernetes.Interface, config *Config, X
 	// GetConfigForCert below.
 	var cache *certmagic.Cache
 	var storage certmagic.Storage
+	var certMgr *CertMgr
 	storage, _ = NewConfigmapStorage(opts.Namespace, clientSet)
 	renewalWindowRatio := float64(config.RenewBeforeDays) / float64(RenewMaxDays)
 	logger := zap.New(zapcore.NewCore(
@@ -75,6 +102,9 @@ func InitCertMgr(opts *Option, clientSet kubernetes.Interface, config *Config, X
 			// Here we use New to get a valid Config associated with the same cache.
 			// The provided Config is used as a template and will be completed with
 			// any defaults that are set in the Default config.
+			if certMgr != nil {
+				return certMgr.getConfigForCert(cert), nil
+			}
 			return cfg, nil
 		},
 		Logger: logger,
@@ -82,17 +112,16 @@ func InitCertMgr(opts *Option, clientSet kubernetes.Interface, config *Config, X
 	// init certmagic
 	cfg = certmagic.New(cache, magicConfig)
 
-	// Init certmagic acme
-	issuer := config.GetIssuer(IssuerTypeLetsencrypt)
-	if issuer == nil {
-		// should never happen here
-		return nil, fmt.Errorf("there is no Letsencrypt Issuer found in config")
+	// Init certmagic acme, it serves the http01 challenges of all the ACME issuers
+	// and maintains the certificates of the domains no longer configured
+	email := ""
+	if issuer := config.GetIssuer(IssuerTypeLetsencrypt); issuer != nil {
+		email = issuer.Email
 	}
-
 	myACME := certmagic.NewACMEIssuer(cfg, certmagic.ACMEIssuer{
 		//CA:                      certmagic.LetsEncryptStagingCA,
 		CA:                      certmagic.LetsEncryptProductionCA,
-		Email:                   issuer.Email,
+		Email:                   email,
 		Agreed:                  true,
 		DisableHTTPChallenge:    false,
 		DisableTLSALPNChallenge: true,
@@ -105,7 +134,7 @@ func InitCertMgr(opts *Option, clientSet kubernetes.Interface, config *Config, X
 
 	secretMgr, _ := NewSecretMgr(opts.Namespace, clientSet)
 
-	certMgr := &CertMgr{
+	certMgr = &CertMgr{
 		cfg:           cfg,
 		client:        clientSet,
 		namespace:     opts.Namespace,
@@ -115,31 +144,37 @@ func InitCertMgr(opts *Option, clientSet kubernetes.Interface, config *Config, X
 		secretMgr:     secretMgr,
 		cache:         cache,
 		XDSUpdater:    XDSUpdater,
+		magicConfig:   magicConfig,
+		managers:      make(map[IssuerName]*acmeManager),
+		domains:       make(map[string]*certmagic.Config),
+		inventory:     NewInventory(),
+		recorder:      newEventRecorder(clientSet),
 	}
 	certMgr.cfg.OnEvent = certMgr.OnEvent
+	certMgr.magicConfig.OnEvent = certMgr.OnEvent
+	// the issuers are synced again on reconcile, so a missing secret is not fatal here
+	if err := certMgr.syncIssuers(config); err != nil {
+		CertLog.Errorf("certmgr init acme issuers error: %v", err)
+	}
 	return certMgr, nil
 }
 func (s *CertMgr) Reconcile(ctx context.Context, oldConfig *Config, newConfig *Config) error {
 	CertLog.Infof("cermgr reconcile old config:%+v to new config:%+v", oldConfig, newConfig)
-	// sync email
-	if oldConfig != nil && newConfig != nil {
-		oldIssuer := oldConfig.GetIssuer(IssuerTypeLetsencrypt)
-		newIssuer := newConfig.GetIssuer(IssuerTypeLetsencrypt)
-		if oldIssuer.Email != newIssuer.Email {
-			// TODO before sync email, maybe need to clean up cache and account
-		}
-	}
-
 	// sync domains
-	newDomains := make([]string, 0)
+	newDomains := make(map[IssuerName][]string, 0)
+	newDNSDomains := make(map[IssuerName][]string, 0)
 	newDomainsMap := make(map[string]string, 0)
 	removeDomains := make([]string, 0)
 
 	if newConfig != nil {
 		for _, config := range newConfig.CredentialConfig {
-			if config.TLSIssuer == IssuerTypeLetsencrypt {
+			if newConfig.IsACMEIssuer(config.TLSIssuer) {
 				for _, newDomain := range config.Domains {
-					newDomains = append(newDomains, newDomain)
+					if config.GetACMEChallenge() == ACMEChallengeDNS01 {
+						newDNSDomains[config.TLSIssuer] = append(newDNSDomains[config.TLSIssuer], newDomain)
+					} else {
+						newDomains[config.TLSIssuer] = append(newDomains[config.TLSIssuer], newDomain)
+					}
 					newDomainsMap[newDomain] = newDomain
 				}
 
@@ -149,7 +184,7 @@ func (s *CertMgr) Reconcile(ctx context.Context, oldConfig *Config, newConfig *C
 
 	if oldConfig != nil {
 		for _, config := range oldConfig.CredentialConfig {
-			if config.TLSIssuer == IssuerTypeLetsencrypt {
+			if oldConfig.IsACMEIssuer(config.TLSIssuer) {
 				for _, oldDomain := range config.Domains {
 					if _, ok := newDomainsMap[oldDomain]; !ok {
 						removeDomains = append(removeDomains, oldDomain)
@@ -161,26 +196,37 @@ func (s *CertMgr) Reconcile(ctx context.Context, oldConfig *Config, newConfig *C
 	}
 
 	if newConfig.AutomaticHttps == true {
-		newIssuer := newConfig.GetIssuer(IssuerTypeLetsencrypt)
 		// clean up  unused domains
 		s.cleanSync(context.Background(), removeDomains)
-		// sync email
-		s.myACME.Email = newIssuer.Email
 		// sync RenewalWindowRatio
 		renewalWindowRatio := float64(newConfig.RenewBeforeDays) / float64(RenewMaxDays)
 		s.cfg.RenewalWindowRatio = renewalWindowRatio
+		s.magicConfig.RenewalWindowRatio = renewalWindowRatio
+		// sync email
+		if issuer := newConfig.GetIssuer(IssuerTypeLetsencrypt); issuer != nil {
+			s.myACME.Email = issuer.Email
+		}
+		// sync issuers, including email, directory, eab and dns01 solver
+		if err := s.syncIssuers(newConfig); err != nil {
+			return err
+		}
 		// start cache
 		s.cache.Start()
 		// sync domains
 		s.configMgr.SetConfig(newConfig)
-		CertLog.Infof("certMgr start to manageSync domains: %+v", newDomains)
-		s.manageSync(context.Background(), newDomains)
+		s.setDomains(newDomains, newDNSDomains)
+		CertLog.Infof("certMgr start to manageSync domains: %+v, dns01 domains: %+v", newDomains, newDNSDomains)
+		for name, manager := range s.getManagers() {
+			s.manageSync(context.Background(), manager.cfg, newDomains[name])
+			s.manageSync(context.Background(), manager.dnsCfg, newDNSDomains[name])
+		}
 		CertLog.Infof("certMgr manageSync domains done")
 	} else {
 		// stop cache  maintainAssets
 		s.cache.Stop()
 		s.configMgr.SetConfig(newConfig)
 	}
+	s.inventory.Sync(newConfig, s.secretMgr)
 
 	if oldConfig != nil && newConfig != nil {
 		if oldConfig.FallbackForInvalidSecret != newConfig.FallbackForInvalidSecret || !reflect.DeepEqual(oldConfig.CredentialConfig, newConfig.CredentialConfig) {
@@ -195,9 +241,91 @@ func (s *CertMgr) Reconcile(ctx context.Context, oldConfig *Config, newConfig *C
 	return nil
 }
 
-func (s *CertMgr) manageSync(ctx context.Context, domainNames []string) error {
+func (s *CertMgr) manageSync(ctx context.Context, cfg *certmagic.Config, domainNames []string) error {
+	if len(domainNames) == 0 {
+		return nil
+	}
 	CertLog.Infof("cert manage sync domains:%v", domainNames)
-	return s.cfg.ManageSync(ctx, domainNames)
+	return cfg.ManageSync(ctx, domainNames)
+}
+
+// syncIssuers builds the managers of the ACME issuers, an issuer is only rebuilt when its settings
+// or the secrets it refers to change
+func (s *CertMgr) syncIssuers(config *Config) error {
+	oldManagers := s.getManagers()
+	managers := make(map[IssuerName]*acmeManager, len(config.ACMEIssuer))
+	for i := range config.ACMEIssuer {
+		issuer := &config.ACMEIssuer[i]
+		if !issuer.IsACME() {
+			continue
+		}
+		options, err := loadACMEIssuerOptions(s.secretMgr, issuer)
+		if err != nil {
+			return fmt.Errorf("init acmeIssuer %s error: %v", issuer.Name, err)
+		}
+		if manager, ok := oldManagers[issuer.Name]; ok && reflect.DeepEqual(manager.options, options) {
+			manager.setRenewalWindowRatio(s.magicConfig.RenewalWindowRatio)
+			managers[issuer.Name] = manager
+			continue
+		}
+		manager, err := newACMEManager(s.cache, s.magicConfig, s.ingressSolver, s.secretMgr, options)
+		if err != nil {
+			return fmt.Errorf("init acmeIssuer %s error: %v", issuer.Name, err)
+		}
+		CertLog.Infof("certmgr use acme issuer %s with directory %s", issuer.Name, issuer.GetDirectory())
+		managers[issuer.Name] = manager
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.managers = managers
+	return nil
+}
+
+func (s *CertMgr) getManagers() map[IssuerName]*acmeManager {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.managers
+}
+
+func (s *CertMgr) setDomains(domains map[IssuerName][]string, dnsDomains map[IssuerName][]string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.domains = make(map[string]*certmagic.Config)
+	for name, manager := range s.managers {
+		for _, domain := range domains[name] {
+			s.domains[strings.ToLower(domain)] = manager.cfg
+		}
+		for _, domain := range dnsDomains[name] {
+			s.domains[strings.ToLower(domain)] = manager.dnsCfg
+		}
+	}
+}
+
+// getConfigForCert returns the certmagic config maintaining the certificate, so renewals use the
+// issuer and the challenge which obtained it
+func (s *CertMgr) getConfigForCert(cert certmagic.Certificate) *certmagic.Config {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	for _, name := range cert.Names {
+		if cfg, ok := s.domains[strings.ToLower(name)]; ok {
+			return cfg
+		}
+	}
+	return s.cfg
+}
+
+// getIssuerNameByDomain returns the name of the ACME issuer managing the domain
+func (s *CertMgr) getIssuerNameByDomain(domain string) IssuerName {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	if cfg, ok := s.domains[strings.ToLower(domain)]; ok {
+		for name, manager := range s.managers {
+			if manager.cfg == cfg || manager.dnsCfg == cfg {
+				return name
+			}
+		}
+	}
+	return IssuerTypeLetsencrypt
 }
 
 func (s *CertMgr) cleanSync(ctx context.Context, domainNames []string) error {
@@ -221,7 +349,25 @@ func (s *CertMgr) OnEvent(ctx context.Context, event string, data map[string]any
 		"metadata_path":    StorageKeys.SiteMeta(issuerKey, certKey),
 	})
 	*/
-	if event == EventCertObtained {
+	switch event {
+	case EventCertObtaining:
+		if domain, ok := data["identifier"].(string); ok {
+			s.inventory.RecordAttempt(domain)
+		}
+	case EventCertFailed:
+		domain, _ := data["identifier"].(string)
+		isRenew, _ := data["renewal"].(bool)
+		err, ok := data["error"].(error)
+		if !ok {
+			err = fmt.Errorf("unknown error")
+		}
+		s.inventory.RecordFailure(domain, err)
+		if isRenew {
+			s.recordEvent(v1.EventTypeWarning, ReasonCertRenewFailed, "renew certificate for domain %s failed: %v", domain, err)
+		} else {
+			s.recordEvent(v1.EventTypeWarning, ReasonCertObtainFailed, "obtain certificate for domain %s failed: %v", domain, err)
+		}
+	case EventCertObtained:
 		// obtain certificate and update secret
 		domain := data["identifier"].(string)
 		isRenew := data["renewal"].(bool)
@@ -235,16 +381,39 @@ func (s *CertMgr) OnEvent(ctx context.Context, event string, data map[string]any
 		}
 		notAfterTime := notAfter(certChain[0])
 		notBeforeTime := notBefore(certChain[0])
-		secretName := s.configMgr.GetConfig().GetSecretNameByDomain(IssuerTypeLetsencrypt, domain)
+		issuerName := s.getIssuerNameByDomain(domain)
+		secretName := s.configMgr.GetConfig().GetSecretNameByDomain(issuerName, domain)
 		if len(secretName) == 0 {
 			CertLog.Errorf("can not find secret name for domain % in config", domain)
 			return nil
 		}
-		err2 := s.secretMgr.Update(domain, secretName, privateKey, certificate, notBeforeTime, notAfterTime, isRenew)
+		err2 := s.secretMgr.Update(issuerName, domain, secretName, privateKey, certificate, notBeforeTime, notAfterTime, isRenew)
 		if err2 != nil {
 			CertLog.Errorf("update secretName %s for domain %s error: %v", secretName, domain, err2)
+			s.inventory.RecordFailure(domain, err2)
+			s.recordEvent(v1.EventTypeWarning, ReasonSecretUpdateFailed, "update secret %s for domain %s failed: %v", secretName, domain, err2)
+		} else {
+			s.inventory.RecordObtained(domain, notBeforeTime, notAfterTime)
+			s.recordEvent(v1.EventTypeNormal, ReasonCertObtained, "certificate for domain %s is stored in secret %s, valid until %s", domain, secretName, notAfterTime.Format(time.RFC3339))
 		}
 		return err
 	}
 	return nil
 }
+
+// recordEvent raises a kubernetes event on the higress-https configmap
+func (s *CertMgr) recordEvent(eventType string, reason string, messageFmt string, args ...interface{}) {
+	ref := &v1.ObjectReference{
+		Kind:       "ConfigMap",
+		APIVersion: "v1",
+		Namespace:  s.namespace,
+		Name:       ConfigmapCertName,
+	}
+	s.recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
+func newEventRecorder(client kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "higress-controller"})
+}
@@ -43,6 +43,13 @@ const (
 	IssuerTypeLetsencrypt IssuerName = "letsencrypt"
 )
 
+type ACMEChallengeType string
+
+const (
+	ACMEChallengeHTTP01 ACMEChallengeType = "http-01"
+	ACMEChallengeDNS01  ACMEChallengeType = "dns-01"
+)
+
 // Config is the configuration of automatic https.
 type Config struct {
 	AutomaticHttps           bool              `json:"automaticHttps"`
@@ -62,6 +69,15 @@ func (c *Config) GetIssuer(issuerName IssuerName) *ACMEIssuerEntry {
 	return nil
 }
 
+// IsACMEIssuer reports whether the issuer of a credential obtains certificates with the ACME protocol
+func (c *Config) IsACMEIssuer(issuerName IssuerName) bool {
+	if issuerName == IssuerTypeLetsencrypt {
+		return true
+	}
+	issuer := c.GetIssuer(issuerName)
+	return issuer != nil && issuer.IsACME()
+}
+
 func (c *Config) MatchSecretNameByDomain(domain string) string {
 	for _, credential := range c.CredentialConfig {
 		for _, credDomain := range credential.Domains {
@@ -103,17 +119,17 @@ func (c *Config) Validate() error {
 		if len(c.ACMEIssuer) == 0 {
 			return fmt.Errorf("no acmeIssuer configuration found when automaticHttps is enable")
 		}
+		issuerNames := make(map[IssuerName]struct{}, len(c.ACMEIssuer))
 		for _, issuer := range c.ACMEIssuer {
-			switch issuer.Name {
-			case IssuerTypeLetsencrypt:
-				if issuer.Email == "" {
-					return fmt.Errorf("acmeIssuer %s email is empty", issuer.Name)
-				}
-				if !ValidateEmail(issuer.Email) {
-					return fmt.Errorf("acmeIssuer %s email %s is invalid", issuer.Name, issuer.Email)
-				}
-			default:
-				return fmt.Errorf("acmeIssuer name %s is not supported", issuer.Name)
+			if _, ok := issuerNames[issuer.Name]; ok {
+				return fmt.Errorf("acmeIssuer %s is duplicated", issuer.Name)
+			}
+			issuerNames[issuer.Name] = struct{}{}
+			if issuer.Name == IssuerTypeAliyunSSL || !issuer.IsACME() {
+				return fmt.Errorf("acmeIssuer name %s is not supported, set directory for a generic ACME issuer", issuer.Name)
+			}
+			if err := issuer.Validate(); err != nil {
+				return err
 			}
 		}
 	}
@@ -131,12 +147,35 @@ func (c *Config) Validate() error {
 			}
 		}
 
-		if credential.TLSIssuer == IssuerTypeLetsencrypt {
+		for _, domain := range credential.Domains {
+			if err := ValidateDomain(domain); err != nil {
+				return fmt.Errorf("credentialConfig %v", err)
+			}
+		}
+
+		if c.IsACMEIssuer(credential.TLSIssuer) {
 			if len(credential.Domains) > 1 {
 				return fmt.Errorf("credentialConfig tlsIssuer %s only support one domain", credential.TLSIssuer)
 			}
+			switch credential.GetACMEChallenge() {
+			case ACMEChallengeHTTP01:
+				for _, domain := range credential.Domains {
+					if IsWildcardDomain(domain) {
+						return fmt.Errorf("credentialConfig wildcard domain %s requires the %s challenge", domain, ACMEChallengeDNS01)
+					}
+				}
+			case ACMEChallengeDNS01:
+				issuer := c.GetIssuer(credential.TLSIssuer)
+				if issuer == nil || issuer.DNS01 == nil {
+					return fmt.Errorf("credentialConfig domains %v use the %s challenge, but acmeIssuer %s has no dns01 configuration", credential.Domains, ACMEChallengeDNS01, credential.TLSIssuer)
+				}
+			default:
+				return fmt.Errorf("credentialConfig acmeChallenge %s is not supported", credential.ACMEChallenge)
+			}
+		} else if len(credential.ACMEChallenge) > 0 {
+			return fmt.Errorf("credentialConfig acmeChallenge is only applicable for ACME tlsIssuer")
 		}
-		if credential.TLSIssuer != IssuerTypeLetsencrypt && len(credential.TLSIssuer) > 0 {
+		if !c.IsACMEIssuer(credential.TLSIssuer) && len(credential.TLSIssuer) > 0 {
 			return fmt.Errorf("credential tls issuer %s is not supported", credential.TLSIssuer)
 		}
 	}
@@ -156,6 +195,21 @@ type CredentialEntry struct {
 	TLSIssuer    IssuerName `json:"tlsIssuer,omitempty"`
 	TLSSecret    string     `json:"tlsSecret,omitempty"`
 	CACertSecret string     `json:"cacertSecret,omitempty"`
+	// ACMEChallenge is the challenge solving the domains, http-01 by default and dns-01 for wildcard domains
+	ACMEChallenge ACMEChallengeType `json:"acmeChallenge,omitempty"`
+}
+
+// GetACMEChallenge returns the challenge type used to obtain the certificate of the credential
+func (c *CredentialEntry) GetACMEChallenge() ACMEChallengeType {
+	if len(c.ACMEChallenge) > 0 {
+		return c.ACMEChallenge
+	}
+	for _, domain := range c.Domains {
+		if IsWildcardDomain(domain) {
+			return ACMEChallengeDNS01
+		}
+	}
+	return ACMEChallengeHTTP01
 }
 
 type ACMEIssuerEntry struct {
@@ -163,6 +217,19 @@ type ACMEIssuerEntry struct {
 	Email string     `json:"email"`
 	AK    string     `json:"ak"` // Only applicable for certain issuers like 'aliyunssl'
 	SK    string     `json:"sk"` // Only applicable for certain issuers like 'aliyunssl'
+	// DNS01 enables the dns-01 challenge, only applicable for ACME issuers
+	DNS01 *DNS01Config `json:"dns01,omitempty"`
+	// Directory is the ACME directory URL, which makes the issuer a generic ACME issuer like ZeroSSL or step-ca.
+	// It defaults to the Let's Encrypt production directory for 'letsencrypt'
+	Directory string `json:"directory,omitempty"`
+	// EABSecret is the secret holding the external account binding keys keyId and macKey, like namespace/name
+	EABSecret string `json:"eabSecret,omitempty"`
+	// CACertSecret is the secret holding the root CAs in ca.crt to trust when connecting to the directory
+	CACertSecret string `json:"cacertSecret,omitempty"`
+	// KeyType is the certificate key type, one of ed25519, p256, p384, rsa2048, rsa4096 and rsa8192, p256 by default
+	KeyType string `json:"keyType,omitempty"`
+	// PreferredChain selects the alternate chain with this root common name when the CA offers several chains
+	PreferredChain string `json:"preferredChain,omitempty"`
 }
 type ConfigMgr struct {
 	client    kubernetes.Interface
@@ -153,3 +153,226 @@ func TestParseTLSSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateACMEChallenge(t *testing.T) {
+	rfc2136 := &DNS01Config{
+		Provider: DNSProviderRFC2136,
+		Config:   map[string]string{"nameserver": "127.0.0.1:53"},
+	}
+	tests := []struct {
+		name        string
+		dns01       *DNS01Config
+		credential  CredentialEntry
+		expectedErr bool
+	}{
+		{
+			name:  "http01 by default",
+			dns01: nil,
+			credential: CredentialEntry{
+				Domains:   []string{"example.com"},
+				TLSIssuer: IssuerTypeLetsencrypt,
+				TLSSecret: "example-com-tls",
+			},
+			expectedErr: false,
+		},
+		{
+			name:  "wildcard uses dns01 by default",
+			dns01: rfc2136,
+			credential: CredentialEntry{
+				Domains:   []string{"*.example.com"},
+				TLSIssuer: IssuerTypeLetsencrypt,
+				TLSSecret: "wildcard-example-com-tls",
+			},
+			expectedErr: false,
+		},
+		{
+			name:  "wildcard without dns01 issuer",
+			dns01: nil,
+			credential: CredentialEntry{
+				Domains:   []string{"*.example.com"},
+				TLSIssuer: IssuerTypeLetsencrypt,
+				TLSSecret: "wildcard-example-com-tls",
+			},
+			expectedErr: true,
+		},
+		{
+			name:  "wildcard with http01",
+			dns01: rfc2136,
+			credential: CredentialEntry{
+				Domains:       []string{"*.example.com"},
+				TLSIssuer:     IssuerTypeLetsencrypt,
+				TLSSecret:     "wildcard-example-com-tls",
+				ACMEChallenge: ACMEChallengeHTTP01,
+			},
+			expectedErr: true,
+		},
+		{
+			name:  "invalid wildcard",
+			dns01: rfc2136,
+			credential: CredentialEntry{
+				Domains:   []string{"www.*.example.com"},
+				TLSIssuer: IssuerTypeLetsencrypt,
+				TLSSecret: "example-com-tls",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "unknown dns provider",
+			dns01: &DNS01Config{
+				Provider: "unknown",
+			},
+			credential: CredentialEntry{
+				Domains:       []string{"example.com"},
+				TLSIssuer:     IssuerTypeLetsencrypt,
+				TLSSecret:     "example-com-tls",
+				ACMEChallenge: ACMEChallengeDNS01,
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newDefaultConfig("admin@example.com")
+			cfg.ACMEIssuer[0].DNS01 = tt.dns01
+			cfg.CredentialConfig = []CredentialEntry{tt.credential}
+			err := cfg.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateACMEIssuer(t *testing.T) {
+	tests := []struct {
+		name        string
+		issuers     []ACMEIssuerEntry
+		credential  CredentialEntry
+		expectedErr bool
+	}{
+		{
+			name: "generic acme issuer",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:           "internal-ca",
+					Directory:      "https://ca.internal:9000/acme/acme/directory",
+					EABSecret:      "higress-system/internal-ca-eab",
+					CACertSecret:   "internal-ca-root",
+					KeyType:        "rsa2048",
+					PreferredChain: "Internal Root CA",
+				},
+			},
+			credential: CredentialEntry{
+				Domains:   []string{"example.com"},
+				TLSIssuer: "internal-ca",
+				TLSSecret: "example-com-tls",
+			},
+			expectedErr: false,
+		},
+		{
+			name: "letsencrypt with another key type",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:    IssuerTypeLetsencrypt,
+					Email:   "admin@example.com",
+					KeyType: "p384",
+				},
+			},
+			credential: CredentialEntry{
+				Domains:   []string{"example.com"},
+				TLSIssuer: IssuerTypeLetsencrypt,
+				TLSSecret: "example-com-tls",
+			},
+			expectedErr: false,
+		},
+		{
+			name: "issuer without directory",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name: "zerossl",
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "http directory",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:      "internal-ca",
+					Directory: "http://ca.internal/directory",
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "unknown key type",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:      "zerossl",
+					Directory: "https://acme.zerossl.com/v2/DV90",
+					KeyType:   "rsa1024",
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid eab secret",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:      "zerossl",
+					Directory: "https://acme.zerossl.com/v2/DV90",
+					EABSecret: "a/b/c",
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "duplicated issuer",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:      "zerossl",
+					Directory: "https://acme.zerossl.com/v2/DV90",
+				},
+				{
+					Name:      "zerossl",
+					Directory: "https://acme.zerossl.com/v2/DV90",
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "credential with unknown issuer",
+			issuers: []ACMEIssuerEntry{
+				{
+					Name:      "zerossl",
+					Directory: "https://acme.zerossl.com/v2/DV90",
+				},
+			},
+			credential: CredentialEntry{
+				Domains:   []string{"example.com"},
+				TLSIssuer: "internal-ca",
+				TLSSecret: "example-com-tls",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newDefaultConfig("admin@example.com")
+			cfg.ACMEIssuer = tt.issuers
+			if len(tt.credential.Domains) > 0 {
+				cfg.CredentialConfig = []CredentialEntry{tt.credential}
+			}
+			err := cfg.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
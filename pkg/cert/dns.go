@@ -0,0 +1,244 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+)
+
+const (
+	DNSProviderRFC2136    = "rfc2136"
+	DNSProviderAlidns     = "alidns"
+	DNSProviderCloudflare = "cloudflare"
+)
+
+// DNS01Config is the configuration of the dns-01 challenge solver of an ACME issuer.
+type DNS01Config struct {
+	// Provider is the name of a registered DNS provider, such as rfc2136, alidns or cloudflare
+	Provider string `json:"provider"`
+	// Config holds the provider specific settings that are not sensitive, such as the nameserver of rfc2136
+	Config map[string]string `json:"config,omitempty"`
+	// CredentialSecret is the secret holding the provider credentials, like namespace/name. Each key of the secret
+	// is a provider setting, such as accessKeyId and accessKeySecret of alidns, and overrides the one in Config.
+	// The secret is read on each challenge, so rotating the credentials needs no change of the configmap
+	CredentialSecret string `json:"credentialSecret,omitempty"`
+	// TTLSeconds is the TTL of the challenge TXT records
+	TTLSeconds int `json:"ttlSeconds,omitempty"`
+	// PropagationDelaySeconds is how long to wait before checking the challenge record propagation
+	PropagationDelaySeconds int `json:"propagationDelaySeconds,omitempty"`
+	// PropagationTimeoutSeconds is how long to wait for the challenge record to propagate, -1 disables the check
+	PropagationTimeoutSeconds int `json:"propagationTimeoutSeconds,omitempty"`
+	// Resolvers are the DNS resolvers used to find the zone and check the propagation, e.g. 10.0.0.53:53
+	Resolvers []string `json:"resolvers,omitempty"`
+	// OverrideDomain delegates the challenge to another domain, which the challenge domain has a CNAME to
+	OverrideDomain string `json:"overrideDomain,omitempty"`
+}
+
+// DNSProviderFactory creates a DNS provider from its settings
+type DNSProviderFactory func(config map[string]string) (certmagic.ACMEDNSProvider, error)
+
+// dnsProviderCredentialKeys are the settings of the built-in providers that must be stored in the credential secret
+var dnsProviderCredentialKeys = []string{"accessKeySecret", "apiToken", "tsigSecret"}
+
+var (
+	dnsProvidersMu sync.RWMutex
+	dnsProviders   = map[string]DNSProviderFactory{
+		DNSProviderRFC2136:    NewRFC2136Provider,
+		DNSProviderAlidns:     NewAlidnsProvider,
+		DNSProviderCloudflare: NewCloudflareProvider,
+	}
+)
+
+// RegisterDNSProvider registers a DNS provider usable by the dns-01 challenge solver
+func RegisterDNSProvider(name string, factory DNSProviderFactory) {
+	dnsProvidersMu.Lock()
+	defer dnsProvidersMu.Unlock()
+	dnsProviders[name] = factory
+}
+
+// DNSProviders returns the names of the registered DNS providers
+func DNSProviders() []string {
+	dnsProvidersMu.RLock()
+	defer dnsProvidersMu.RUnlock()
+	names := make([]string, 0, len(dnsProviders))
+	for name := range dnsProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getDNSProviderFactory returns the factory of a registered DNS provider
+func getDNSProviderFactory(name string) (DNSProviderFactory, error) {
+	dnsProvidersMu.RLock()
+	factory, ok := dnsProviders[name]
+	dnsProvidersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("dns provider %s is not supported, supported providers: %s", name, strings.Join(DNSProviders(), ", "))
+	}
+	return factory, nil
+}
+
+func NewDNSProvider(name string, config map[string]string) (certmagic.ACMEDNSProvider, error) {
+	factory, err := getDNSProviderFactory(name)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("dns provider %s config is invalid: %v", name, err)
+	}
+	return provider, nil
+}
+
+// Validate checks the dns-01 settings. The provider settings are fully checked only when there is no
+// credential secret, otherwise they are checked once the secret is loaded
+func (c *DNS01Config) Validate() error {
+	for _, key := range dnsProviderCredentialKeys {
+		if _, ok := c.Config[key]; ok {
+			return fmt.Errorf("%s must be stored in the credentialSecret instead of config", key)
+		}
+	}
+	if c.CredentialSecret == "" {
+		_, err := NewDNS01Solver(c)
+		return err
+	}
+	if ns, name := ParseTLSSecret(c.CredentialSecret); ns == "" && name == "" {
+		return fmt.Errorf("credentialSecret %s is not supported", c.CredentialSecret)
+	}
+	_, err := getDNSProviderFactory(c.Provider)
+	return err
+}
+
+// loadDNS01Config returns the dns-01 settings with the provider credentials read from the credential secret
+func loadDNS01Config(secretMgr *SecretMgr, config *DNS01Config) (*DNS01Config, error) {
+	if config.CredentialSecret == "" {
+		return config, nil
+	}
+	secret, err := secretMgr.Get(config.CredentialSecret)
+	if err != nil {
+		return nil, fmt.Errorf("get dns01 credential secret %s error: %v", config.CredentialSecret, err)
+	}
+	loaded := *config
+	loaded.Config = make(map[string]string, len(config.Config)+len(secret.Data))
+	for key, value := range config.Config {
+		loaded.Config[key] = value
+	}
+	for key, value := range secret.Data {
+		loaded.Config[key] = string(value)
+	}
+	return &loaded, nil
+}
+
+// credentialDNSProvider creates the DNS provider with the credentials read from the credential secret on each
+// call, so a rotated or late created secret takes effect without changing the configmap
+type credentialDNSProvider struct {
+	secretMgr *SecretMgr
+	config    *DNS01Config
+}
+
+func (p *credentialDNSProvider) provider() (certmagic.ACMEDNSProvider, error) {
+	config, err := loadDNS01Config(p.secretMgr, p.config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDNSProvider(config.Provider, config.Config)
+}
+
+func (p *credentialDNSProvider) AppendRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	provider, err := p.provider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.AppendRecords(ctx, zone, recs)
+}
+
+func (p *credentialDNSProvider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	provider, err := p.provider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.DeleteRecords(ctx, zone, recs)
+}
+
+// NewDNS01Solver creates the dns-01 challenge solver of an ACME issuer
+func NewDNS01Solver(config *DNS01Config) (*certmagic.DNS01Solver, error) {
+	provider, err := NewDNSProvider(config.Provider, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	return newDNS01Solver(config, provider), nil
+}
+
+// newDNS01SolverWithSecret creates the dns-01 challenge solver of an ACME issuer, the credentials of the provider
+// are read from the credential secret on each challenge
+func newDNS01SolverWithSecret(secretMgr *SecretMgr, config *DNS01Config) (*certmagic.DNS01Solver, error) {
+	if config.CredentialSecret == "" {
+		return NewDNS01Solver(config)
+	}
+	if _, err := getDNSProviderFactory(config.Provider); err != nil {
+		return nil, err
+	}
+	return newDNS01Solver(config, &credentialDNSProvider{secretMgr: secretMgr, config: config}), nil
+}
+
+func newDNS01Solver(config *DNS01Config, provider certmagic.ACMEDNSProvider) *certmagic.DNS01Solver {
+	solver := &certmagic.DNS01Solver{
+		DNSProvider:    provider,
+		TTL:            time.Duration(config.TTLSeconds) * time.Second,
+		Resolvers:      config.Resolvers,
+		OverrideDomain: config.OverrideDomain,
+	}
+	if config.PropagationDelaySeconds > 0 {
+		solver.PropagationDelay = time.Duration(config.PropagationDelaySeconds) * time.Second
+	}
+	if config.PropagationTimeoutSeconds < 0 {
+		solver.PropagationTimeout = -1
+	} else if config.PropagationTimeoutSeconds > 0 {
+		solver.PropagationTimeout = time.Duration(config.PropagationTimeoutSeconds) * time.Second
+	}
+	return solver
+}
+
+// IsWildcardDomain reports whether the domain is a wildcard domain like *.example.com
+func IsWildcardDomain(domain string) bool {
+	return strings.HasPrefix(domain, "*.")
+}
+
+// ValidateDomain checks a domain of a credential, a wildcard is only allowed as the whole leftmost label
+func ValidateDomain(domain string) error {
+	name := strings.TrimPrefix(domain, "*.")
+	if name == "" || strings.Contains(name, "*") {
+		return fmt.Errorf("domain %s is invalid, a wildcard is only allowed as the leftmost label like *.example.com", domain)
+	}
+	return nil
+}
+
+// requireConfig returns the value of a required provider setting
+func requireConfig(config map[string]string, key string) (string, error) {
+	value := strings.TrimSpace(config[key])
+	if value == "" {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+)
+
+const (
+	alidnsDefaultRegion = "cn-hangzhou"
+	// The free edition of Alibaba Cloud DNS does not accept a TTL lower than 600 seconds
+	alidnsMinTTL = 600 * time.Second
+)
+
+// AlidnsProvider manages records of Alibaba Cloud DNS
+type AlidnsProvider struct {
+	client *alidns.Client
+}
+
+// NewAlidnsProvider creates an alidns provider from the settings accessKeyId, accessKeySecret and regionId
+func NewAlidnsProvider(config map[string]string) (certmagic.ACMEDNSProvider, error) {
+	ak, err := requireConfig(config, "accessKeyId")
+	if err != nil {
+		return nil, err
+	}
+	sk, err := requireConfig(config, "accessKeySecret")
+	if err != nil {
+		return nil, err
+	}
+	region := strings.TrimSpace(config["regionId"])
+	if region == "" {
+		region = alidnsDefaultRegion
+	}
+	client, err := alidns.NewClientWithAccessKey(region, ak, sk)
+	if err != nil {
+		return nil, err
+	}
+	return &AlidnsProvider{client: client}, nil
+}
+
+func (p *AlidnsProvider) AppendRecords(_ context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	created := make([]libdns.Record, 0, len(recs))
+	for _, rec := range recs {
+		ttl := rec.TTL
+		if ttl < alidnsMinTTL {
+			ttl = alidnsMinTTL
+		}
+		request := alidns.CreateAddDomainRecordRequest()
+		request.Scheme = "https"
+		request.DomainName = alidnsDomainName(zone)
+		request.RR = alidnsRR(rec.Name)
+		request.Type = rec.Type
+		request.Value = rec.Value
+		request.TTL = requests.NewInteger(int(ttl.Seconds()))
+		response, err := p.client.AddDomainRecord(request)
+		if err != nil {
+			return created, fmt.Errorf("add alidns record %s.%s failed: %v", request.RR, request.DomainName, err)
+		}
+		rec.ID = response.RecordId
+		rec.TTL = ttl
+		created = append(created, rec)
+	}
+	return created, nil
+}
+
+func (p *AlidnsProvider) DeleteRecords(_ context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	deleted := make([]libdns.Record, 0, len(recs))
+	for _, rec := range recs {
+		id := rec.ID
+		if id == "" {
+			found, err := p.findRecordID(zone, rec)
+			if err != nil {
+				return deleted, err
+			}
+			if found == "" {
+				continue
+			}
+			id = found
+		}
+		request := alidns.CreateDeleteDomainRecordRequest()
+		request.Scheme = "https"
+		request.RecordId = id
+		if _, err := p.client.DeleteDomainRecord(request); err != nil {
+			return deleted, fmt.Errorf("delete alidns record %s failed: %v", id, err)
+		}
+		rec.ID = id
+		deleted = append(deleted, rec)
+	}
+	return deleted, nil
+}
+
+// findRecordID looks up the ID of a record with the same name, type and value
+func (p *AlidnsProvider) findRecordID(zone string, rec libdns.Record) (string, error) {
+	request := alidns.CreateDescribeDomainRecordsRequest()
+	request.Scheme = "https"
+	request.DomainName = alidnsDomainName(zone)
+	request.RRKeyWord = alidnsRR(rec.Name)
+	request.TypeKeyWord = rec.Type
+	request.ValueKeyWord = rec.Value
+	request.SearchMode = "ADVANCED"
+	response, err := p.client.DescribeDomainRecords(request)
+	if err != nil {
+		return "", fmt.Errorf("describe alidns records of %s failed: %v", request.DomainName, err)
+	}
+	for _, record := range response.DomainRecords.Record {
+		if record.RR == request.RRKeyWord && record.Type == rec.Type && record.Value == rec.Value {
+			return record.RecordId, nil
+		}
+	}
+	return "", nil
+}
+
+func alidnsDomainName(zone string) string {
+	return strings.TrimSuffix(zone, ".")
+}
+
+func alidnsRR(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+)
+
+const (
+	cloudflareAPIURL  = "https://api.cloudflare.com/client/v4"
+	cloudflareTimeout = 30 * time.Second
+)
+
+// CloudflareProvider manages records of Cloudflare DNS with an API token granted Zone:DNS:Edit
+type CloudflareProvider struct {
+	apiURL   string
+	apiToken string
+	client   *http.Client
+
+	// zone IDs keyed by zone name, preset by the zoneId setting or looked up
+	zonesMu sync.Mutex
+	zones   map[string]string
+}
+
+type cloudflareRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result json.RawMessage `json:"result"`
+}
+
+// NewCloudflareProvider creates a cloudflare provider from the settings apiToken and zoneId.
+// zoneId is optional, the zone is looked up by name when it is not set.
+func NewCloudflareProvider(config map[string]string) (certmagic.ACMEDNSProvider, error) {
+	token, err := requireConfig(config, "apiToken")
+	if err != nil {
+		return nil, err
+	}
+	p := &CloudflareProvider{
+		apiURL:   cloudflareAPIURL,
+		apiToken: token,
+		client:   &http.Client{Timeout: cloudflareTimeout},
+		zones:    make(map[string]string),
+	}
+	if apiURL := strings.TrimSpace(config["apiURL"]); apiURL != "" {
+		p.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+	p.zones[""] = strings.TrimSpace(config["zoneId"])
+	return p, nil
+}
+
+func (p *CloudflareProvider) AppendRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	zoneID, err := p.zoneID(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]libdns.Record, 0, len(recs))
+	for _, rec := range recs {
+		// Cloudflare treats a TTL of 1 as automatic
+		ttl := 1
+		if rec.TTL > 0 {
+			ttl = int(rec.TTL.Seconds())
+		}
+		body := cloudflareRecord{
+			Type:    rec.Type,
+			Name:    cloudflareName(rec.Name, zone),
+			Content: rec.Value,
+			TTL:     ttl,
+		}
+		var result cloudflareRecord
+		if err := p.do(ctx, http.MethodPost, fmt.Sprintf("/zones/%s/dns_records", zoneID), body, &result); err != nil {
+			return created, fmt.Errorf("add cloudflare record %s failed: %v", body.Name, err)
+		}
+		rec.ID = result.ID
+		created = append(created, rec)
+	}
+	return created, nil
+}
+
+func (p *CloudflareProvider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	zoneID, err := p.zoneID(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]libdns.Record, 0, len(recs))
+	for _, rec := range recs {
+		id := rec.ID
+		if id == "" {
+			query := url.Values{}
+			query.Set("type", rec.Type)
+			query.Set("name", cloudflareName(rec.Name, zone))
+			query.Set("content", rec.Value)
+			var found []cloudflareRecord
+			if err := p.do(ctx, http.MethodGet, fmt.Sprintf("/zones/%s/dns_records?%s", zoneID, query.Encode()), nil, &found); err != nil {
+				return deleted, fmt.Errorf("list cloudflare records failed: %v", err)
+			}
+			if len(found) == 0 {
+				continue
+			}
+			id = found[0].ID
+		}
+		if err := p.do(ctx, http.MethodDelete, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, id), nil, nil); err != nil {
+			return deleted, fmt.Errorf("delete cloudflare record %s failed: %v", id, err)
+		}
+		rec.ID = id
+		deleted = append(deleted, rec)
+	}
+	return deleted, nil
+}
+
+func (p *CloudflareProvider) zoneID(ctx context.Context, zone string) (string, error) {
+	p.zonesMu.Lock()
+	defer p.zonesMu.Unlock()
+	if id := p.zones[""]; id != "" {
+		return id, nil
+	}
+	name := strings.TrimSuffix(zone, ".")
+	if id, ok := p.zones[name]; ok {
+		return id, nil
+	}
+	var zones []struct {
+		ID string `json:"id"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/zones?name="+url.QueryEscape(name), nil, &zones); err != nil {
+		return "", fmt.Errorf("look up cloudflare zone %s failed: %v", name, err)
+	}
+	if len(zones) == 0 {
+		return "", fmt.Errorf("cloudflare zone %s is not found", name)
+	}
+	p.zones[name] = zones[0].ID
+	return zones[0].ID, nil
+}
+
+func (p *CloudflareProvider) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result cloudflareResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("status %d, invalid response: %v", resp.StatusCode, err)
+	}
+	if !result.Success {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return fmt.Errorf("status %d, errors: %s", resp.StatusCode, strings.Join(messages, "; "))
+	}
+	if out != nil {
+		return json.Unmarshal(result.Result, out)
+	}
+	return nil
+}
+
+// cloudflareName returns the record name without the trailing dot expected by the API
+func cloudflareName(name, zone string) string {
+	return strings.TrimSuffix(libdns.AbsoluteName(name, zone), ".")
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+)
+
+const (
+	rfc2136DefaultTTL     = 60 * time.Second
+	rfc2136DefaultTimeout = 10 * time.Second
+	rfc2136TSIGFudge      = 300
+)
+
+// RFC2136Provider manages records with RFC 2136 dynamic updates, optionally signed with a TSIG key.
+// It works with BIND, PowerDNS, Knot and most self-hosted authoritative servers.
+type RFC2136Provider struct {
+	// Nameserver is the address of the primary server accepting updates, e.g. 10.0.0.53:53
+	Nameserver    string
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGAlgorithm string
+	// Transport is udp or tcp
+	Transport string
+	Timeout   time.Duration
+}
+
+// NewRFC2136Provider creates an rfc2136 provider from the settings
+// nameserver, tsigKeyName, tsigSecret (base64), tsigAlgorithm (default hmac-sha256), transport and timeoutSeconds.
+func NewRFC2136Provider(config map[string]string) (certmagic.ACMEDNSProvider, error) {
+	nameserver, err := requireConfig(config, "nameserver")
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		nameserver = net.JoinHostPort(nameserver, "53")
+	}
+	p := &RFC2136Provider{
+		Nameserver:    nameserver,
+		TSIGKeyName:   strings.TrimSpace(config["tsigKeyName"]),
+		TSIGSecret:    strings.TrimSpace(config["tsigSecret"]),
+		TSIGAlgorithm: strings.TrimSpace(config["tsigAlgorithm"]),
+		Transport:     strings.TrimSpace(config["transport"]),
+		Timeout:       rfc2136DefaultTimeout,
+	}
+	if (p.TSIGKeyName == "") != (p.TSIGSecret == "") {
+		return nil, fmt.Errorf("tsigKeyName and tsigSecret must be set together")
+	}
+	if p.TSIGAlgorithm == "" {
+		p.TSIGAlgorithm = dns.HmacSHA256
+	}
+	p.TSIGAlgorithm = dns.Fqdn(p.TSIGAlgorithm)
+	switch p.TSIGAlgorithm {
+	case dns.HmacSHA1, dns.HmacSHA224, dns.HmacSHA256, dns.HmacSHA384, dns.HmacSHA512:
+	default:
+		return nil, fmt.Errorf("tsigAlgorithm %s is not supported", p.TSIGAlgorithm)
+	}
+	switch p.Transport {
+	case "":
+		p.Transport = "udp"
+	case "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("transport %s is not supported, use udp or tcp", p.Transport)
+	}
+	if timeout, ok := config["timeoutSeconds"]; ok {
+		seconds, err := strconv.Atoi(strings.TrimSpace(timeout))
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("timeoutSeconds %s is invalid", timeout)
+		}
+		p.Timeout = time.Duration(seconds) * time.Second
+	}
+	return p, nil
+}
+
+func (p *RFC2136Provider) AppendRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	rrs, err := p.toRRs(zone, recs)
+	if err != nil {
+		return nil, err
+	}
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(zone))
+	msg.Insert(rrs)
+	if err := p.exchange(ctx, msg); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func (p *RFC2136Provider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	rrs, err := p.toRRs(zone, recs)
+	if err != nil {
+		return nil, err
+	}
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(zone))
+	// Remove only deletes the records with the same name, type and value
+	msg.Remove(rrs)
+	if err := p.exchange(ctx, msg); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func (p *RFC2136Provider) toRRs(zone string, recs []libdns.Record) ([]dns.RR, error) {
+	rrs := make([]dns.RR, 0, len(recs))
+	for _, rec := range recs {
+		ttl := rec.TTL
+		if ttl <= 0 {
+			ttl = rfc2136DefaultTTL
+		}
+		value := rec.Value
+		if rec.Type == "TXT" {
+			value = fmt.Sprintf("%q", rec.Value)
+		}
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", libdns.AbsoluteName(rec.Name, dns.Fqdn(zone)), int(ttl.Seconds()), rec.Type, value))
+		if err != nil {
+			return nil, fmt.Errorf("record %s %s is invalid: %v", rec.Type, rec.Name, err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+func (p *RFC2136Provider) exchange(ctx context.Context, msg *dns.Msg) error {
+	client := &dns.Client{
+		Net:     p.Transport,
+		Timeout: p.Timeout,
+	}
+	if p.TSIGKeyName != "" {
+		keyName := dns.Fqdn(p.TSIGKeyName)
+		client.TsigSecret = map[string]string{keyName: p.TSIGSecret}
+		msg.SetTsig(keyName, p.TSIGAlgorithm, rfc2136TSIGFudge, time.Now().Unix())
+	}
+	resp, _, err := client.ExchangeContext(ctx, msg, p.Nameserver)
+	if err != nil {
+		return fmt.Errorf("dns update to %s failed: %v", p.Nameserver, err)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("dns update to %s failed: %s", p.Nameserver, dns.RcodeToString[resp.Rcode])
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testTSIGKeyName = "acme-update."
+	testTSIGSecret  = "c2VjcmV0LWtleS1mb3ItdGVzdGluZy1vbmx5"
+)
+
+// testDNSServer is a stand-in authoritative server applying TSIG signed RFC 2136 updates to TXT records
+type testDNSServer struct {
+	mu      sync.Mutex
+	records map[string][]string
+}
+
+func (s *testDNSServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		m.Rcode = dns.RcodeNotAuth
+	} else if r.Opcode == dns.OpcodeUpdate {
+		s.mu.Lock()
+		for _, rr := range r.Ns {
+			txt, ok := rr.(*dns.TXT)
+			if !ok {
+				continue
+			}
+			name := txt.Hdr.Name
+			value := strings.Join(txt.Txt, "")
+			switch txt.Hdr.Class {
+			case dns.ClassINET:
+				s.records[name] = append(s.records[name], value)
+			case dns.ClassNONE:
+				values := s.records[name][:0]
+				for _, v := range s.records[name] {
+					if v != value {
+						values = append(values, v)
+					}
+				}
+				s.records[name] = values
+			}
+		}
+		s.mu.Unlock()
+	}
+	if r.IsTsig() != nil {
+		m.SetTsig(testTSIGKeyName, dns.HmacSHA256, 300, time.Now().Unix())
+	}
+	w.WriteMsg(m)
+}
+
+func (s *testDNSServer) get(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.records[name]...)
+}
+
+func startTestDNSServer(t *testing.T) (*testDNSServer, string) {
+	handler := &testDNSServer{records: make(map[string][]string)}
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        pc,
+		Handler:           handler,
+		TsigSecret:        map[string]string{testTSIGKeyName: testTSIGSecret},
+		NotifyStartedFunc: func() { close(started) },
+		MsgAcceptFunc:     func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return handler, pc.LocalAddr().String()
+}
+
+func TestRFC2136Provider(t *testing.T) {
+	server, addr := startTestDNSServer(t)
+	provider, err := NewDNSProvider(DNSProviderRFC2136, map[string]string{
+		"nameserver":  addr,
+		"tsigKeyName": "acme-update",
+		"tsigSecret":  testTSIGSecret,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	recs := []libdns.Record{
+		{Type: "TXT", Name: "_acme-challenge", Value: "token-1"},
+		{Type: "TXT", Name: "_acme-challenge", Value: "token-2"},
+	}
+	_, err = provider.AppendRecords(ctx, "example.com.", recs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token-1", "token-2"}, server.get("_acme-challenge.example.com."))
+
+	_, err = provider.DeleteRecords(ctx, "example.com.", recs[:1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token-2"}, server.get("_acme-challenge.example.com."))
+
+	// Updates signed with a wrong key are refused
+	provider, err = NewDNSProvider(DNSProviderRFC2136, map[string]string{
+		"nameserver":  addr,
+		"tsigKeyName": "acme-update",
+		"tsigSecret":  "d3Jvbmcta2V5",
+	})
+	require.NoError(t, err)
+	_, err = provider.AppendRecords(ctx, "example.com.", recs[:1])
+	assert.Error(t, err)
+	assert.Equal(t, []string{"token-2"}, server.get("_acme-challenge.example.com."))
+}
+
+func TestNewRFC2136ProviderInvalid(t *testing.T) {
+	for name, config := range map[string]map[string]string{
+		"no nameserver":      {},
+		"key without secret": {"nameserver": "127.0.0.1", "tsigKeyName": "key"},
+		"unknown algorithm":  {"nameserver": "127.0.0.1", "tsigKeyName": "key", "tsigSecret": "c2VjcmV0", "tsigAlgorithm": "hmac-md4"},
+		"unknown transport":  {"nameserver": "127.0.0.1", "transport": "quic"},
+		"invalid timeout":    {"nameserver": "127.0.0.1", "timeoutSeconds": "-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDNSProvider(DNSProviderRFC2136, config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCloudflareProvider(t *testing.T) {
+	var mu sync.Mutex
+	records := make(map[string]cloudflareRecord)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		mu.Lock()
+		defer mu.Unlock()
+		var result interface{}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/zones":
+			assert.Equal(t, "example.com", r.URL.Query().Get("name"))
+			result = []map[string]string{{"id": "zone-1"}}
+		case r.Method == http.MethodPost && r.URL.Path == "/zones/zone-1/dns_records":
+			var rec cloudflareRecord
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&rec))
+			rec.ID = "record-" + rec.Content
+			records[rec.ID] = rec
+			result = rec
+		case r.Method == http.MethodGet && r.URL.Path == "/zones/zone-1/dns_records":
+			found := make([]cloudflareRecord, 0)
+			for _, rec := range records {
+				if rec.Name == r.URL.Query().Get("name") && rec.Content == r.URL.Query().Get("content") {
+					found = append(found, rec)
+				}
+			}
+			result = found
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/zones/zone-1/dns_records/"):
+			delete(records, strings.TrimPrefix(r.URL.Path, "/zones/zone-1/dns_records/"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "errors": []map[string]interface{}{{"code": 7003, "message": "not found"}}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": result})
+	}))
+	defer server.Close()
+
+	provider, err := NewDNSProvider(DNSProviderCloudflare, map[string]string{"apiToken": "test-token", "apiURL": server.URL})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	created, err := provider.AppendRecords(ctx, "example.com.", []libdns.Record{
+		{Type: "TXT", Name: "_acme-challenge", Value: "token-1", TTL: time.Minute},
+		{Type: "TXT", Name: "_acme-challenge", Value: "token-2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, "record-token-1", created[0].ID)
+	assert.Equal(t, "_acme-challenge.example.com", records["record-token-1"].Name)
+	assert.Equal(t, 60, records["record-token-1"].TTL)
+
+	// Records are deleted by ID, or looked up by name and value without it
+	_, err = provider.DeleteRecords(ctx, "example.com.", created[:1])
+	require.NoError(t, err)
+	_, err = provider.DeleteRecords(ctx, "example.com.", []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "token-2"}})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestNewDNS01Solver(t *testing.T) {
+	solver, err := NewDNS01Solver(&DNS01Config{
+		Provider:                  DNSProviderRFC2136,
+		Config:                    map[string]string{"nameserver": "10.0.0.53"},
+		TTLSeconds:                120,
+		PropagationTimeoutSeconds: -1,
+		Resolvers:                 []string{"10.0.0.53:53"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, solver.TTL)
+	assert.Equal(t, time.Duration(-1), solver.PropagationTimeout)
+	assert.Equal(t, "10.0.0.53:53", solver.DNSProvider.(*RFC2136Provider).Nameserver)
+
+	_, err = NewDNS01Solver(&DNS01Config{Provider: "unknown"})
+	assert.Error(t, err)
+	_, err = NewDNS01Solver(&DNS01Config{Provider: DNSProviderAlidns, Config: map[string]string{"accessKeyId": "ak"}})
+	assert.Error(t, err)
+}
+
+// credentialRecorder records the credentials its providers are created with
+type credentialRecorder struct {
+	mu      sync.Mutex
+	created []map[string]string
+}
+
+func (r *credentialRecorder) factory(config map[string]string) (certmagic.ACMEDNSProvider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, config)
+	return noopDNSProvider{}, nil
+}
+
+func TestDNS01SolverWithCredentialSecret(t *testing.T) {
+	recorder := &credentialRecorder{}
+	RegisterDNSProvider("recorder", recorder.factory)
+	client := fake.NewSimpleClientset()
+	secretMgr, _ := NewSecretMgr("higress-system", client)
+	config := &DNS01Config{
+		Provider:         "recorder",
+		Config:           map[string]string{"regionId": "cn-beijing"},
+		CredentialSecret: "dns-credential",
+	}
+	solver, err := newDNS01SolverWithSecret(secretMgr, config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	recs := []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "token"}}
+	// the secret created after the solver is used by the next challenge
+	_, err = solver.DNSProvider.AppendRecords(ctx, "example.com.", recs)
+	assert.Error(t, err)
+	_, err = client.CoreV1().Secrets("higress-system").Create(ctx,
+		newTestSecret("higress-system", "dns-credential", map[string]string{"accessKeyId": "ak-1"}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = solver.DNSProvider.AppendRecords(ctx, "example.com.", recs)
+	require.NoError(t, err)
+
+	// the rotated credentials are used without rebuilding the solver
+	_, err = client.CoreV1().Secrets("higress-system").Update(ctx,
+		newTestSecret("higress-system", "dns-credential", map[string]string{"accessKeyId": "ak-2"}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = solver.DNSProvider.DeleteRecords(ctx, "example.com.", recs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]string{
+		{"regionId": "cn-beijing", "accessKeyId": "ak-1"},
+		{"regionId": "cn-beijing", "accessKeyId": "ak-2"},
+	}, recorder.created)
+	assert.Equal(t, map[string]string{"regionId": "cn-beijing"}, config.Config)
+
+	_, err = newDNS01SolverWithSecret(secretMgr, &DNS01Config{Provider: "unknown", CredentialSecret: "dns-credential"})
+	assert.Error(t, err)
+}
+
+func TestDNS01ConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      DNS01Config
+		expectedErr bool
+	}{
+		{
+			name:   "settings in config",
+			config: DNS01Config{Provider: DNSProviderRFC2136, Config: map[string]string{"nameserver": "10.0.0.53"}},
+		},
+		{
+			name:        "missing required setting",
+			config:      DNS01Config{Provider: DNSProviderAlidns, Config: map[string]string{"accessKeyId": "ak"}},
+			expectedErr: true,
+		},
+		{
+			name:   "credentials in secret",
+			config: DNS01Config{Provider: DNSProviderAlidns, CredentialSecret: "higress-system/alidns"},
+		},
+		{
+			name:        "credentials in config",
+			config:      DNS01Config{Provider: DNSProviderCloudflare, Config: map[string]string{"apiToken": "token"}},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown provider with secret",
+			config:      DNS01Config{Provider: "unknown", CredentialSecret: "alidns"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid secret name",
+			config:      DNS01Config{Provider: DNSProviderAlidns, CredentialSecret: "a/b/c"},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
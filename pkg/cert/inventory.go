@@ -0,0 +1,210 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertStatus is the status of the certificate of a domain managed by automatic https
+type CertStatus struct {
+	Domain     string     `json:"domain"`
+	Issuer     IssuerName `json:"issuer"`
+	SecretName string     `json:"secretName"`
+	NotBefore  *time.Time `json:"notBefore,omitempty"`
+	NotAfter   *time.Time `json:"notAfter,omitempty"`
+	// ExpiryDays is the days until the certificate expires, it is negative once expired
+	ExpiryDays *float64 `json:"expiryDays,omitempty"`
+	// LastAttemptTime is the time of the last attempt to obtain or renew the certificate
+	LastAttemptTime *time.Time `json:"lastAttemptTime,omitempty"`
+	// LastRenewalTime is the time the certificate was last obtained or renewed
+	LastRenewalTime *time.Time `json:"lastRenewalTime,omitempty"`
+	// LastError is the error of the last attempt, it is cleared once the certificate is obtained
+	LastError string `json:"lastError,omitempty"`
+	// Failures is the number of consecutive failed attempts
+	Failures int `json:"failures"`
+}
+
+// Inventory keeps the status of the certificates of all the managed domains
+type Inventory struct {
+	mux   sync.RWMutex
+	certs map[string]*CertStatus
+}
+
+func NewInventory() *Inventory {
+	return &Inventory{
+		certs: make(map[string]*CertStatus),
+	}
+}
+
+// Sync updates the managed domains from the credentials of the ACME issuers, the validity of the
+// certificates not seen yet is read from their secrets
+func (i *Inventory) Sync(config *Config, secretMgr *SecretMgr) {
+	certs := make(map[string]*CertStatus)
+	if config != nil {
+		for _, credential := range config.CredentialConfig {
+			if !config.IsACMEIssuer(credential.TLSIssuer) {
+				continue
+			}
+			for _, domain := range credential.Domains {
+				certs[strings.ToLower(domain)] = &CertStatus{
+					Domain:     domain,
+					Issuer:     credential.TLSIssuer,
+					SecretName: credential.TLSSecret,
+				}
+			}
+		}
+	}
+
+	i.mux.RLock()
+	for key, status := range certs {
+		// keep the status unless the domain moves to another issuer or secret
+		if old, ok := i.certs[key]; ok && old.Issuer == status.Issuer && old.SecretName == status.SecretName {
+			domain := status.Domain
+			*status = *old
+			status.Domain = domain
+		}
+	}
+	i.mux.RUnlock()
+
+	for _, status := range certs {
+		if status.NotAfter != nil || secretMgr == nil {
+			continue
+		}
+		secret, err := secretMgr.Get(status.SecretName)
+		if err != nil {
+			continue
+		}
+		certChain, err := parseCertsFromPEMBundle(secret.Data["tls.crt"])
+		if err != nil {
+			continue
+		}
+		notBeforeTime, notAfterTime := notBefore(certChain[0]), notAfter(certChain[0])
+		status.NotBefore, status.NotAfter = &notBeforeTime, &notAfterTime
+	}
+
+	i.mux.Lock()
+	for key, old := range i.certs {
+		// drop the expiry metric of the removed domains, or of the domains moved to another issuer
+		if status, ok := certs[key]; !ok || status.Domain != old.Domain || status.Issuer != old.Issuer {
+			DeleteCertExpiry(old.Domain, old.Issuer)
+		}
+	}
+	i.certs = certs
+	i.mux.Unlock()
+	i.RecordMetrics()
+}
+
+// RecordAttempt records an attempt to obtain or renew the certificate of the domain
+func (i *Inventory) RecordAttempt(domain string) {
+	i.update(domain, func(status *CertStatus) {
+		now := time.Now()
+		status.LastAttemptTime = &now
+	})
+}
+
+// RecordObtained records the certificate of the domain is obtained or renewed
+func (i *Inventory) RecordObtained(domain string, notBeforeTime time.Time, notAfterTime time.Time) {
+	i.update(domain, func(status *CertStatus) {
+		now := time.Now()
+		status.NotBefore, status.NotAfter = &notBeforeTime, &notAfterTime
+		status.LastRenewalTime = &now
+		status.LastError = ""
+		status.Failures = 0
+		IncrementCertRenewal(status.Domain, status.Issuer)
+		RecordCertExpiry(status.Domain, status.Issuer, notAfterTime)
+	})
+}
+
+// RecordFailure records the failure to obtain or renew the certificate of the domain, or to update its secret
+func (i *Inventory) RecordFailure(domain string, err error) {
+	i.update(domain, func(status *CertStatus) {
+		status.LastError = err.Error()
+		status.Failures++
+		IncrementCertRenewalFailure(status.Domain, status.Issuer)
+	})
+}
+
+// Get returns the status of the certificate of the domain
+func (i *Inventory) Get(domain string) (CertStatus, bool) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+	status, ok := i.certs[strings.ToLower(domain)]
+	if !ok {
+		return CertStatus{}, false
+	}
+	return status.withExpiryDays(time.Now()), true
+}
+
+// List returns the status of all the managed certificates sorted by domain
+func (i *Inventory) List() []CertStatus {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+	now := time.Now()
+	list := make([]CertStatus, 0, len(i.certs))
+	for _, status := range i.certs {
+		list = append(list, status.withExpiryDays(now))
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Domain < list[b].Domain
+	})
+	return list
+}
+
+// RecordMetrics refreshes the expiry metrics, which change as time goes by
+func (i *Inventory) RecordMetrics() {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+	for _, status := range i.certs {
+		if status.NotAfter != nil {
+			RecordCertExpiry(status.Domain, status.Issuer, *status.NotAfter)
+		}
+	}
+}
+
+// ServeHTTP serves the managed certificates as JSON
+func (i *Inventory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(i.List())
+}
+
+func (i *Inventory) update(domain string, f func(status *CertStatus)) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+	status, ok := i.certs[strings.ToLower(domain)]
+	if !ok {
+		// the domain may be removed from the config while its certificate is maintained
+		return
+	}
+	f(status)
+}
+
+func (s *CertStatus) withExpiryDays(now time.Time) CertStatus {
+	status := *s
+	if status.NotAfter != nil {
+		days := status.NotAfter.Sub(now).Hours() / 24
+		status.ExpiryDays = &days
+	}
+	return status
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInventory(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newTestSecret("higress-system", "example-com-tls", map[string]string{"tls.crt": string(newTestCACert(t))}),
+	)
+	secretMgr, _ := NewSecretMgr("higress-system", client)
+	config := newDefaultConfig("admin@example.com")
+	config.CredentialConfig = []CredentialEntry{
+		{Domains: []string{"example.com"}, TLSIssuer: IssuerTypeLetsencrypt, TLSSecret: "example-com-tls"},
+		{Domains: []string{"www.example.com"}, TLSIssuer: IssuerTypeLetsencrypt, TLSSecret: "www-example-com-tls"},
+		{Domains: []string{"static.example.com"}, TLSSecret: "static-example-com-tls"},
+	}
+
+	inventory := NewInventory()
+	inventory.Sync(config, secretMgr)
+	list := inventory.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "example.com", list[0].Domain)
+	assert.Equal(t, IssuerTypeLetsencrypt, list[0].Issuer)
+	require.NotNil(t, list[0].NotAfter)
+	require.NotNil(t, list[0].ExpiryDays)
+	assert.InDelta(t, 1.0/24, *list[0].ExpiryDays, 0.01)
+	assert.Equal(t, "www.example.com", list[1].Domain)
+	assert.Nil(t, list[1].NotAfter)
+
+	// a failed renewal is kept until the certificate is obtained
+	inventory.RecordAttempt("www.example.com")
+	inventory.RecordFailure("www.example.com", errors.New("acme: error 403"))
+	inventory.RecordFailure("www.example.com", errors.New("acme: error 403"))
+	status, ok := inventory.Get("WWW.example.com")
+	require.True(t, ok)
+	assert.NotNil(t, status.LastAttemptTime)
+	assert.Equal(t, "acme: error 403", status.LastError)
+	assert.Equal(t, 2, status.Failures)
+
+	notBeforeTime, notAfterTime := time.Now(), time.Now().Add(90*24*time.Hour)
+	inventory.RecordObtained("www.example.com", notBeforeTime, notAfterTime)
+	status, _ = inventory.Get("www.example.com")
+	assert.Empty(t, status.LastError)
+	assert.Equal(t, 0, status.Failures)
+	assert.Equal(t, notAfterTime, *status.NotAfter)
+	assert.NotNil(t, status.LastRenewalTime)
+
+	// the status is kept across syncs, and removed domains are dropped along with their expiry metric
+	series := testutil.CollectAndCount(certExpiryDays)
+	config.CredentialConfig = config.CredentialConfig[1:]
+	inventory.Sync(config, secretMgr)
+	assert.Equal(t, series-1, testutil.CollectAndCount(certExpiryDays))
+	status, ok = inventory.Get("www.example.com")
+	require.True(t, ok)
+	assert.NotNil(t, status.LastRenewalTime)
+	_, ok = inventory.Get("example.com")
+	assert.False(t, ok)
+	inventory.RecordFailure("example.com", errors.New("removed"))
+
+	server := httptest.NewServer(inventory)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var served []CertStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&served))
+	require.Len(t, served, 1)
+	assert.Equal(t, "www.example.com", served[0].Domain)
+	assert.Equal(t, "www-example-com-tls", served[0].SecretName)
+}
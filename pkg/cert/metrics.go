@@ -0,0 +1,69 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"istio.io/pkg/monitoring"
+)
+
+var (
+	domainTag = monitoring.MustCreateLabel("domain")
+	issuerTag = monitoring.MustCreateLabel("issuer")
+
+	// certExpiryDays tracks the days until the certificate of a managed domain expires, it is a prometheus gauge
+	// since the series of the domains removed from the config have to be deleted
+	certExpiryDays = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "higress_cert_expiry_days",
+		Help: "Days until the certificate of the managed domain expires.",
+	}, []string{"domain", "issuer"})
+
+	certRenewals = monitoring.NewSum(
+		"higress_cert_renewals_total",
+		"Total certificates obtained or renewed for the managed domain.",
+		monitoring.WithLabels(domainTag, issuerTag),
+	)
+
+	certRenewalFailures = monitoring.NewSum(
+		"higress_cert_renewal_failures_total",
+		"Total failures of obtaining or renewing the certificate of the managed domain.",
+		monitoring.WithLabels(domainTag, issuerTag),
+	)
+)
+
+func init() {
+	prometheus.MustRegister(certExpiryDays)
+	monitoring.MustRegister(certRenewals)
+	monitoring.MustRegister(certRenewalFailures)
+}
+
+func RecordCertExpiry(domain string, issuer IssuerName, notAfter time.Time) {
+	certExpiryDays.WithLabelValues(domain, string(issuer)).Set(time.Until(notAfter).Hours() / 24)
+}
+
+// DeleteCertExpiry deletes the expiry metric of the domain which is no longer managed
+func DeleteCertExpiry(domain string, issuer IssuerName) {
+	certExpiryDays.DeleteLabelValues(domain, string(issuer))
+}
+
+func IncrementCertRenewal(domain string, issuer IssuerName) {
+	certRenewals.With(domainTag.Value(domain), issuerTag.Value(string(issuer))).Increment()
+}
+
+func IncrementCertRenewalFailure(domain string, issuer IssuerName) {
+	certRenewalFailures.With(domainTag.Value(domain), issuerTag.Value(string(issuer))).Increment()
+}
@@ -40,7 +40,16 @@ func NewSecretMgr(namespace string, client kubernetes.Interface) (*SecretMgr, er
 	return secretMgr, nil
 }
 
-func (s *SecretMgr) Update(domain string, secretName string, privateKey []byte, certificate []byte, notBefore time.Time, notAfter time.Time, isRenew bool) error {
+// Get returns the secret named like namespace/name, or name in the namespace of the secret manager
+func (s *SecretMgr) Get(secretName string) (*v1.Secret, error) {
+	namespace, name := ParseTLSSecret(secretName)
+	if namespace == "" {
+		namespace = s.namespace
+	}
+	return s.client.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
+func (s *SecretMgr) Update(issuerName IssuerName, domain string, secretName string, privateKey []byte, certificate []byte, notBefore time.Time, notAfter time.Time, isRenew bool) error {
 	CertLog.Infof("update secret, domain:%s, secretName:%s, notBefore:%v, notAfter:%v, isRenew:%t", domain, secretName, notBefore, notAfter, isRenew)
 	name := secretName
 	namespace := s.namespace
@@ -50,7 +59,7 @@ func (s *SecretMgr) Update(domain string, secretName string, privateKey []byte,
 		name = secretP
 	}
 
-	secret := s.constructSecret(domain, name, namespace, privateKey, certificate, notBefore, notAfter, isRenew)
+	secret := s.constructSecret(issuerName, domain, name, namespace, privateKey, certificate, notBefore, notAfter, isRenew)
 	_, err := s.client.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -72,13 +81,13 @@ func (s *SecretMgr) Update(domain string, secretName string, privateKey []byte,
 	return nil
 }
 
-func (s *SecretMgr) constructSecret(domain string, name string, namespace string, privateKey []byte, certificate []byte, notBefore time.Time, notAfter time.Time, isRenew bool) *v1.Secret {
+func (s *SecretMgr) constructSecret(issuerName IssuerName, domain string, name string, namespace string, privateKey []byte, certificate []byte, notBefore time.Time, notAfter time.Time, isRenew bool) *v1.Secret {
 	annotationMap := make(map[string]string, 0)
 	annotationMap["higress.io/cert-domain"] = domain
 	annotationMap["higress.io/cert-notAfter"] = notAfter.Format("2006-01-02 15:04:05")
 	annotationMap["higress.io/cert-notBefore"] = notBefore.Format("2006-01-02 15:04:05")
 	annotationMap["higress.io/cert-renew"] = strconv.FormatBool(isRenew)
-	annotationMap["higress.io/cert-source"] = string(IssuerTypeLetsencrypt)
+	annotationMap["higress.io/cert-source"] = string(issuerName)
 	if isRenew {
 		annotationMap["higress.io/cert-renew-time"] = time.Now().Format("2006-01-02 15:04:05")
 	}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/caddyserver/certmagic"
 	"istio.io/istio/pilot/pkg/model"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -105,8 +106,18 @@ func (s *Server) initHttpServer() error {
 	return nil
 }
 
+// Inventory returns the status of the managed certificates, it is nil until the server is initialized
+func (s *Server) Inventory() *Inventory {
+	if s.certMgr == nil {
+		return nil
+	}
+	return s.certMgr.inventory
+}
+
 func (s *Server) Run(stopCh <-chan struct{}) error {
 	go s.controller.Run(stopCh)
+	// refresh the expiry metrics of the managed certificates
+	go wait.Until(s.certMgr.inventory.RecordMetrics, time.Minute, stopCh)
 	CertLog.Infof("server run")
 	go func() {
 		<-stopCh
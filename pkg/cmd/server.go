@@ -115,6 +115,8 @@ func getServerCommand() *cobra.Command {
 		serverArgs.RegistryOptions.ClusterRegistriesNamespace, "Namespace for ConfigMap which stores clusters configs")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.RegistryOptions.KubeConfig, "kubeconfig", "",
 		"Use a Kubernetes configuration file instead of in-cluster configuration")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.RegistryOptions.FileDir, "configDir", "",
+		"if not empty, read Ingress, Gateway API, Higress CRDs, Secrets and ConfigMaps from the yaml files in this directory instead of the kubernetes api server")
 	// RegistryOptions Controller options
 	serveCmd.PersistentFlags().StringVar(&serverArgs.RegistryOptions.KubeOptions.DomainSuffix, "domain", constants.DefaultClusterLocalDomain,
 		"DNS domain suffix")
@@ -136,6 +138,15 @@ func getServerCommand() *cobra.Command {
 	serveCmd.PersistentFlags().StringVar(&serverArgs.AutomaticHttpsEmail, "automaticHttpsEmail", "", "email for automatic https")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.CertHttpAddress, "certHttpAddress", serverArgs.CertHttpAddress, "the cert http address")
 
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.Address, "webhookAddress", "",
+		"if not empty, serve the validating admission webhook of Ingress and WasmPlugin at this https address")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.CertFile, "webhookCertFile", "/etc/higress/webhook/tls.crt",
+		"the certificate file of the validating webhook")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.KeyFile, "webhookKeyFile", "/etc/higress/webhook/tls.key",
+		"the private key file of the validating webhook")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.PluginSchemaDir, "wasmPluginSchemaDir", "",
+		"the directory of the wasm plugin spec.yaml files, whose config schema is used to validate the WasmPlugin configs")
+
 	loggingOptions.AttachCobraFlags(serveCmd)
 	serverArgs.GrpcKeepAliveOptions.AttachCobraFlags(serveCmd)
 
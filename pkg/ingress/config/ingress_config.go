@@ -70,6 +70,7 @@ import (
 	. "github.com/alibaba/higress/v2/pkg/ingress/log"
 	"github.com/alibaba/higress/v2/pkg/kube"
 	"github.com/alibaba/higress/v2/registry"
+	"github.com/alibaba/higress/v2/registry/healthcheck"
 	"github.com/alibaba/higress/v2/registry/reconcile"
 )
 
@@ -161,6 +162,9 @@ type IngressConfig struct {
 	secretConfigMgr *SecretConfigMgr
 
 	mcpServerCache mcpserver.McpServerCache
+
+	// explainState records where the generated configs come from, see Explain
+	explainState explainState
 }
 
 // getSecretValue implements the getValue function for secret references
@@ -385,20 +389,7 @@ func (m *IngressConfig) listFromGatewayControllers(typ config.GroupVersionKind,
 func (m *IngressConfig) createWrapperConfigs(configs []config.Config) []common.WrapperConfig {
 	var wrapperConfigs []common.WrapperConfig
 
-	// Init global context
-	clusterSecretListers := map[cluster.ID]listersv1.SecretLister{}
-	clusterServiceListers := map[cluster.ID]listersv1.ServiceLister{}
-	m.mutex.RLock()
-	for clusterId, controller := range m.remoteIngressControllers {
-		clusterSecretListers[clusterId] = controller.SecretLister()
-		clusterServiceListers[clusterId] = controller.ServiceLister()
-	}
-	m.mutex.RUnlock()
-	globalContext := &annotations.GlobalContext{
-		WatchedSecrets:      sets.New[string](),
-		ClusterSecretLister: clusterSecretListers,
-		ClusterServiceList:  clusterServiceListers,
-	}
+	globalContext := m.newAnnotationGlobalContext()
 
 	for idx := range configs {
 		rawConfig := configs[idx]
@@ -428,6 +419,22 @@ func (m *IngressConfig) createWrapperConfigs(configs []config.Config) []common.W
 	return wrapperConfigs
 }
 
+func (m *IngressConfig) newAnnotationGlobalContext() *annotations.GlobalContext {
+	clusterSecretListers := map[cluster.ID]listersv1.SecretLister{}
+	clusterServiceListers := map[cluster.ID]listersv1.ServiceLister{}
+	m.mutex.RLock()
+	for clusterId, controller := range m.remoteIngressControllers {
+		clusterSecretListers[clusterId] = controller.SecretLister()
+		clusterServiceListers[clusterId] = controller.ServiceLister()
+	}
+	m.mutex.RUnlock()
+	return &annotations.GlobalContext{
+		WatchedSecrets:      sets.New[string](),
+		ClusterSecretLister: clusterSecretListers,
+		ClusterServiceList:  clusterServiceListers,
+	}
+}
+
 func (m *IngressConfig) convertGateways(configs []common.WrapperConfig) []config.Config {
 	convertOptions := common.ConvertOptions{
 		IngressDomainCache: common.NewIngressDomainCache(),
@@ -572,6 +579,7 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 
 	// Convert http route to virtual service
 	out := make([]config.Config, 0, len(convertOptions.HTTPRoutes))
+	explainRoutes := make([]explainRoute, 0, len(convertOptions.HTTPRoutes))
 	for host, routes := range convertOptions.HTTPRoutes {
 		if len(routes) == 0 {
 			continue
@@ -600,10 +608,11 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 		}
 
 		firstRoute := routes[0]
+		vsName := common.CreateConvertedName(constants.IstioIngressGatewayName, firstRoute.WrapperConfig.Config.Namespace, firstRoute.WrapperConfig.Config.Name, cleanHost)
 		out = append(out, config.Config{
 			Meta: config.Meta{
 				GroupVersionKind: gvk.VirtualService,
-				Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, firstRoute.WrapperConfig.Config.Namespace, firstRoute.WrapperConfig.Config.Name, cleanHost),
+				Name:             vsName,
 				Namespace:        m.namespace,
 				Annotations: map[string]string{
 					common.ClusterIdAnnotation: firstRoute.ClusterId.String(),
@@ -611,7 +620,13 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 			},
 			Spec: vs,
 		})
+		for _, route := range routes {
+			explainRoutes = append(explainRoutes, explainRoute{virtualService: vsName, route: route})
+		}
 	}
+	m.mutex.Lock()
+	m.explainState.routes = explainRoutes
+	m.mutex.Unlock()
 	// add vs from nacos3 for mcp server
 	if m.RegistryReconciler != nil {
 		allConfigsFromMcp := m.RegistryReconciler.GetAllConfigs(gvk.VirtualService)
@@ -627,6 +642,8 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 
 func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions) {
 	var envoyFilters []config.Config
+	var explainEnvoyFilters []explainEnvoyFilter
+	var authRoutes []*common.WrapperHTTPRoute
 	mappings := map[string]*common.Rule{}
 
 	initHttp2RpcGlobalConfig := true
@@ -646,6 +663,7 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 				} else {
 					IngressLog.Infof("Append http2rpc EnvoyFilter for name %s", http2rpc.Name)
 					envoyFilters = append(envoyFilters, *envoyFilter)
+					explainEnvoyFilters = append(explainEnvoyFilters, explainEnvoyFilter{config: envoyFilter, route: route, field: "Http2Rpc"})
 					initHttp2RpcGlobalConfig = false
 				}
 			}
@@ -659,14 +677,28 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 				} else {
 					IngressLog.Infof("Append MCP SSE stateful session EnvoyFilter for route %s", route.HTTPRoute.Name)
 					envoyFilters = append(envoyFilters, *envoyFilter)
+					explainEnvoyFilters = append(explainEnvoyFilters, explainEnvoyFilter{config: envoyFilter, route: route, field: "LoadBalance"})
 					initMcpSseGlobalFilter = false
 				}
 			}
 
+			compression := route.WrapperConfig.AnnotationsConfig.Compression
+			if compression != nil && len(compression.DisabledAlgorithms) > 0 {
+				IngressLog.Infof("Found compression %v disabled for route %s", compression.DisabledAlgorithms, route.HTTPRoute.Name)
+				envoyFilter, err := m.constructCompressionEnvoyFilter(route, m.namespace, compression)
+				if err != nil {
+					IngressLog.Errorf("Construct compression EnvoyFilter error %v", err)
+				} else {
+					envoyFilters = append(envoyFilters, *envoyFilter)
+					explainEnvoyFilters = append(explainEnvoyFilters, explainEnvoyFilter{config: envoyFilter, route: route, field: "Compression"})
+				}
+			}
+
 			auth := route.WrapperConfig.AnnotationsConfig.Auth
 			if auth == nil {
 				continue
 			}
+			authRoutes = append(authRoutes, route)
 
 			key := auth.AuthSecret.String() + "/" + auth.AuthRealm
 			if rule, exist := mappings[key]; !exist {
@@ -694,6 +726,9 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 			IngressLog.Errorf("Construct basic auth filter error %v", err)
 		} else {
 			envoyFilters = append(envoyFilters, *basicAuth)
+			for _, route := range authRoutes {
+				explainEnvoyFilters = append(explainEnvoyFilters, explainEnvoyFilter{config: basicAuth, route: route, field: "Auth"})
+			}
 		}
 	}
 
@@ -703,11 +738,19 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 		}
 	}
 
+	if m.RegistryReconciler != nil {
+		healthChecks := m.RegistryReconciler.GetRegistryHealthChecks()
+		if healthCheckEnvoyFilter := constructHealthCheckEnvoyFilter(healthChecks, convertOptions.ServiceWrappers, m.namespace); healthCheckEnvoyFilter != nil {
+			envoyFilters = append(envoyFilters, *healthCheckEnvoyFilter)
+		}
+	}
+
 	// TODO Support other envoy filters
 
 	IngressLog.Infof("Found %d number of envoyFilters", len(envoyFilters))
 	m.mutex.Lock()
 	m.cachedEnvoyFilters = envoyFilters
+	m.explainState.envoyFilters = explainEnvoyFilters
 	m.mutex.Unlock()
 }
 
@@ -793,7 +836,14 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 	IngressLog.Debugf("traffic policy number %d", len(convertOptions.Service2TrafficPolicy))
 
 	for _, wrapperTrafficPolicy := range convertOptions.Service2TrafficPolicy {
-		m.annotationHandler.ApplyTrafficPolicy(wrapperTrafficPolicy.TrafficPolicy, wrapperTrafficPolicy.PortTrafficPolicy, wrapperTrafficPolicy.WrapperConfig.AnnotationsConfig)
+		// The connection pool and outlier detection annotations of all the ingresses sharing the service are merged,
+		// the oldest ingress wins on conflict.
+		var shared []*annotations.Ingress
+		for _, sharedConfig := range wrapperTrafficPolicy.SharedConfigs {
+			shared = append(shared, sharedConfig.AnnotationsConfig)
+		}
+		annotationsConfig := annotations.MergeSharedTrafficPolicy(wrapperTrafficPolicy.WrapperConfig.AnnotationsConfig, shared)
+		m.annotationHandler.ApplyTrafficPolicy(wrapperTrafficPolicy.TrafficPolicy, wrapperTrafficPolicy.PortTrafficPolicy, annotationsConfig)
 	}
 
 	// Merge multi-port traffic policy per service into one destination rule.
@@ -882,6 +932,7 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 	}
 
 	out := make([]config.Config, 0, len(destinationRules))
+	explainDestinationRules := make([]explainDestinationRule, 0, len(destinationRules))
 	for _, dr := range destinationRules {
 		sort.SliceStable(dr.DestinationRule.TrafficPolicy.PortLevelSettings, func(i, j int) bool {
 			portI := dr.DestinationRule.TrafficPolicy.PortLevelSettings[i].Port
@@ -904,7 +955,11 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 			},
 			Spec: dr.DestinationRule,
 		})
+		explainDestinationRules = append(explainDestinationRules, explainDestinationRule{config: out[len(out)-1], wrapper: dr})
 	}
+	m.mutex.Lock()
+	m.explainState.destinationRules = explainDestinationRules
+	m.mutex.Unlock()
 
 	return out
 }
@@ -1874,6 +1929,72 @@ func validateServiceWrapperForProxy(serviceWrapper *common.ServiceWrapper) error
 	return nil
 }
 
+// constructHealthCheckEnvoyFilter adds the active health checks of the registries to the clusters of the services
+// discovered by them. The services using a proxy are skipped, since their clusters point to the local proxy listener.
+func constructHealthCheckEnvoyFilter(healthChecks map[string]*higressv1.RegistryConfig_HealthCheck, serviceWrappers map[string]*common.ServiceWrapper, namespace string) *config.Config {
+	if len(healthChecks) == 0 {
+		return nil
+	}
+
+	hosts := make([]string, 0, len(serviceWrappers))
+	for host := range serviceWrappers {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var patches []*networking.EnvoyFilter_EnvoyConfigObjectPatch
+	for _, host := range hosts {
+		serviceWrapper := serviceWrappers[host]
+		healthCheck := healthChecks[path.Join(serviceWrapper.RegistryType, serviceWrapper.RegistryName)]
+		if healthCheck == nil {
+			continue
+		}
+		if serviceWrapper.ProxyConfig != nil && serviceWrapper.ProxyConfig.ProxyName != "" {
+			IngressLog.Warnf("Service %s uses proxy %s, active health check is not supported", host, serviceWrapper.ProxyConfig.ProxyName)
+			continue
+		}
+		patchJson, _ := json.Marshal(map[string]interface{}{
+			"health_checks": healthcheck.ClusterHealthChecks(healthCheck),
+		})
+		for _, port := range serviceWrapper.ServiceEntry.Ports {
+			if port == nil || port.Number <= 0 {
+				continue
+			}
+			patches = append(patches, &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				ApplyTo: networking.EnvoyFilter_CLUSTER,
+				Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+					Context: networking.EnvoyFilter_GATEWAY,
+					ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+						// Matching by service and port covers the subset clusters of the service as well
+						Cluster: &networking.EnvoyFilter_ClusterMatch{
+							Service:    host,
+							PortNumber: port.Number,
+						},
+					},
+				},
+				Patch: &networking.EnvoyFilter_Patch{
+					Operation: networking.EnvoyFilter_Patch_MERGE,
+					Value:     util.BuildPatchStruct(string(patchJson)),
+				},
+			})
+		}
+	}
+	if len(patches) == 0 {
+		return nil
+	}
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.EnvoyFilter,
+			Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, "health-check"),
+			Namespace:        namespace,
+		},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: patches,
+		},
+	}
+}
+
 func (m *IngressConfig) Run(stop <-chan struct{}) {
 	for _, remoteIngressController := range m.remoteIngressControllers {
 		_ = remoteIngressController.SetWatchErrorHandler(m.watchErrorHandler)
@@ -2083,3 +2204,59 @@ func (m *IngressConfig) notifyXDSFullUpdate(GVK config.GroupVersionKind, reason
 		Reason:         istiomodel.NewReasonStats(reason),
 	})
 }
+
+func (m *IngressConfig) constructCompressionEnvoyFilter(route *common.WrapperHTTPRoute, namespace string, compression *annotations.CompressionConfig) (*config.Config, error) {
+	httpRoute := route.HTTPRoute
+
+	perFilterConfig := map[string]interface{}{}
+	for _, algorithm := range compression.DisabledAlgorithms {
+		filterName, ok := configmap.CompressorFilterNames[algorithm]
+		if !ok {
+			continue
+		}
+		perFilterConfig[filterName] = map[string]interface{}{
+			"@type":    "type.googleapis.com/envoy.extensions.filters.http.compressor.v3.CompressorPerRoute",
+			"disabled": true,
+		}
+	}
+	if len(perFilterConfig) == 0 {
+		return nil, errors.New("no compression algorithm to disable")
+	}
+	value, err := json.Marshal(map[string]interface{}{
+		"typed_per_filter_config": perFilterConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.EnvoyFilter,
+			Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, "compression-route", common.ConvertToDNSLabelValid(httpRoute.Name)),
+			Namespace:        namespace,
+		},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				{
+					ApplyTo: networking.EnvoyFilter_HTTP_ROUTE,
+					Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: networking.EnvoyFilter_GATEWAY,
+						ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+							RouteConfiguration: &networking.EnvoyFilter_RouteConfigurationMatch{
+								Vhost: &networking.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+									Route: &networking.EnvoyFilter_RouteConfigurationMatch_RouteMatch{
+										Name: httpRoute.Name,
+									},
+								},
+							},
+						},
+					},
+					Patch: &networking.EnvoyFilter_Patch{
+						Operation: networking.EnvoyFilter_Patch_MERGE,
+						Value:     buildPatchStruct(string(value)),
+					},
+				},
+			},
+		},
+	}, nil
+}
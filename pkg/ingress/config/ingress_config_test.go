@@ -17,6 +17,7 @@ package config
 import (
 	"testing"
 
+	clusterpb "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	httppb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
@@ -28,11 +29,13 @@ import (
 	ingress "k8s.io/api/networking/v1"
 	ingressv1beta1 "k8s.io/api/networking/v1beta1"
 
+	higressv1 "github.com/alibaba/higress/v2/api/networking/v1"
 	"github.com/alibaba/higress/v2/pkg/ingress/kube/annotations"
 	"github.com/alibaba/higress/v2/pkg/ingress/kube/common"
 	controllerv1beta1 "github.com/alibaba/higress/v2/pkg/ingress/kube/ingress"
 	controllerv1 "github.com/alibaba/higress/v2/pkg/ingress/kube/ingressv1"
 	"github.com/alibaba/higress/v2/pkg/kube"
+	"github.com/alibaba/higress/v2/registry/healthcheck"
 )
 
 func TestNormalizeWeightedCluster(t *testing.T) {
@@ -616,3 +619,86 @@ func TestConstructBasicAuthEnvoyFilter(t *testing.T) {
 	target := proto.Clone(pb).(*httppb.HttpFilter)
 	t.Log(target)
 }
+
+func TestConstructCompressionEnvoyFilter(t *testing.T) {
+	m := &IngressConfig{}
+	route := &common.WrapperHTTPRoute{
+		HTTPRoute: &networking.HTTPRoute{
+			Name: "ai-route",
+		},
+	}
+
+	config, err := m.constructCompressionEnvoyFilter(route, "higress-system", &annotations.CompressionConfig{
+		DisabledAlgorithms: []string{annotations.CompressionGzip, annotations.CompressionBrotli},
+	})
+	if err != nil {
+		t.Fatalf("construct error %v", err)
+	}
+	envoyFilter := config.Spec.(*networking.EnvoyFilter)
+	assert.Len(t, envoyFilter.ConfigPatches, 1)
+	patch := envoyFilter.ConfigPatches[0]
+	assert.Equal(t, networking.EnvoyFilter_HTTP_ROUTE, patch.ApplyTo)
+	assert.Equal(t, "ai-route", patch.Match.GetRouteConfiguration().GetVhost().GetRoute().GetName())
+
+	perFilterConfig := patch.Patch.Value.GetFields()["typed_per_filter_config"].GetStructValue().GetFields()
+	assert.Len(t, perFilterConfig, 2)
+	assert.True(t, perFilterConfig["envoy.filters.http.compressor"].GetStructValue().GetFields()["disabled"].GetBoolValue())
+	assert.True(t, perFilterConfig["envoy.filters.http.compressor.brotli"].GetStructValue().GetFields()["disabled"].GetBoolValue())
+
+	_, err = m.constructCompressionEnvoyFilter(route, "higress-system", &annotations.CompressionConfig{})
+	assert.Error(t, err)
+}
+
+func TestConstructHealthCheckEnvoyFilter(t *testing.T) {
+	serviceWrappers := map[string]*common.ServiceWrapper{
+		"legacy.static": {
+			ServiceEntry: &networking.ServiceEntry{
+				Hosts: []string{"legacy.static"},
+				Ports: []*networking.ServicePort{{Number: 80, Name: "HTTP", Protocol: "HTTP"}},
+			},
+			RegistryType: "static",
+			RegistryName: "legacy",
+		},
+		"foo.dns": {
+			ServiceEntry: &networking.ServiceEntry{
+				Hosts: []string{"foo.dns"},
+				Ports: []*networking.ServicePort{{Number: 443, Name: "HTTPS", Protocol: "HTTPS"}},
+			},
+			RegistryType: "dns",
+			RegistryName: "foo",
+		},
+	}
+
+	assert.Nil(t, constructHealthCheckEnvoyFilter(nil, serviceWrappers, "higress-system"))
+
+	healthChecks := map[string]*higressv1.RegistryConfig_HealthCheck{
+		"static/legacy": healthcheck.Normalize(&higressv1.RegistryConfig_HealthCheck{
+			Type:             healthcheck.TypeHTTP,
+			Path:             "/health",
+			ExpectedStatuses: []uint32{200, 204},
+		}),
+	}
+	config := constructHealthCheckEnvoyFilter(healthChecks, serviceWrappers, "higress-system")
+	assert.NotNil(t, config)
+	envoyFilter := config.Spec.(*networking.EnvoyFilter)
+	assert.Len(t, envoyFilter.ConfigPatches, 1)
+	patch := envoyFilter.ConfigPatches[0]
+	assert.Equal(t, networking.EnvoyFilter_CLUSTER, patch.ApplyTo)
+	assert.Equal(t, networking.EnvoyFilter_Patch_MERGE, patch.Patch.Operation)
+	assert.Equal(t, "legacy.static", patch.Match.GetCluster().GetService())
+	assert.Equal(t, uint32(80), patch.Match.GetCluster().GetPortNumber())
+	assert.Empty(t, patch.Match.GetCluster().GetName())
+	assert.Empty(t, patch.Match.GetCluster().GetSubset())
+
+	pb, err := xds.BuildXDSObjectFromStruct(networking.EnvoyFilter_CLUSTER, patch.Patch.Value, false)
+	if err != nil {
+		t.Fatalf("build object error %v", err)
+	}
+	cluster := pb.(*clusterpb.Cluster)
+	assert.Len(t, cluster.HealthChecks, 1)
+	healthCheck := cluster.HealthChecks[0]
+	assert.Equal(t, int64(5), healthCheck.Interval.GetSeconds())
+	assert.Equal(t, uint32(3), healthCheck.UnhealthyThreshold.GetValue())
+	assert.Equal(t, "/health", healthCheck.GetHttpHealthCheck().GetPath())
+	assert.Len(t, healthCheck.GetHttpHealthCheck().GetExpectedStatuses(), 2)
+}
@@ -0,0 +1,517 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pkg/config"
+	"k8s.io/apimachinery/pkg/labels"
+
+	higressext "github.com/alibaba/higress/v2/api/extensions/v1alpha1"
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/common"
+)
+
+const (
+	AnnotationApplied = "applied"
+	AnnotationIgnored = "ignored"
+)
+
+var (
+	// Fields of annotations.Ingress which are applied by the route handlers
+	routeAnnotationFields = map[string]bool{
+		"Canary":          true,
+		"Cors":            true,
+		"Destination":     true,
+		"Fallback":        true,
+		"HeaderControl":   true,
+		"IgnoreCase":      true,
+		"IPAccessControl": true,
+		"Match":           true,
+		"Mirror":          true,
+		"Redirect":        true,
+		"Retry":           true,
+		"Rewrite":         true,
+		"Timeout":         true,
+		"localRateLimit":  true,
+	}
+	// Fields of annotations.Ingress which are applied by the traffic policy handlers
+	trafficPolicyAnnotationFields = map[string]bool{
+		"LoadBalance":   true,
+		"TrafficPolicy": true,
+		"UpstreamTLS":   true,
+	}
+)
+
+// ExplainQuery selects what to explain, either an ingress by Namespace and Name or the routes matching Host and Path.
+type ExplainQuery struct {
+	Namespace string
+	Name      string
+	Host      string
+	Path      string
+}
+
+// ExplainResult is what Higress generates from the selected ingresses.
+type ExplainResult struct {
+	Ingresses []*IngressExplanation `json:"ingresses"`
+}
+
+type IngressExplanation struct {
+	Cluster          string                  `json:"cluster,omitempty"`
+	Namespace        string                  `json:"namespace"`
+	Name             string                  `json:"name"`
+	Annotations      []AnnotationExplanation `json:"annotations,omitempty"`
+	Routes           []RouteExplanation      `json:"routes,omitempty"`
+	DestinationRules []ConfigExplanation     `json:"destinationRules,omitempty"`
+	EnvoyFilters     []ConfigExplanation     `json:"envoyFilters,omitempty"`
+	WasmPlugins      []WasmPluginExplanation `json:"wasmPlugins,omitempty"`
+}
+
+// AnnotationExplanation tells whether an annotation takes effect. Affects lists the parsed annotation configs
+// changed by it, an annotation is ignored if it changes nothing, for example it is not supported, its value is
+// invalid or it depends on another annotation which is absent.
+type AnnotationExplanation struct {
+	Key     string   `json:"key"`
+	Value   string   `json:"value"`
+	Status  string   `json:"status"`
+	Affects []string `json:"affects,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// RouteExplanation is a route of a generated VirtualService. Routes are listed in the order they are matched.
+type RouteExplanation struct {
+	VirtualService string          `json:"virtualService"`
+	Host           string          `json:"host"`
+	Path           string          `json:"path"`
+	PathType       string          `json:"pathType"`
+	Annotations    []string        `json:"annotations,omitempty"`
+	Route          json.RawMessage `json:"route"`
+}
+
+// ConfigExplanation is a generated DestinationRule or EnvoyFilter and the annotations producing it.
+type ConfigExplanation struct {
+	Name        string          `json:"name"`
+	Namespace   string          `json:"namespace"`
+	Source      string          `json:"source,omitempty"`
+	Annotations []string        `json:"annotations,omitempty"`
+	Spec        json.RawMessage `json:"spec"`
+}
+
+// WasmPluginExplanation is a WasmPlugin rule matching the ingress. MatchedBy is ingress, domain, service or
+// default for the default config which applies to all the routes.
+type WasmPluginExplanation struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Phase     string          `json:"phase,omitempty"`
+	Priority  int32           `json:"priority,omitempty"`
+	MatchedBy string          `json:"matchedBy"`
+	Match     []string        `json:"match,omitempty"`
+	Config    json.RawMessage `json:"config,omitempty"`
+}
+
+type explainState struct {
+	routes           []explainRoute
+	destinationRules []explainDestinationRule
+	envoyFilters     []explainEnvoyFilter
+}
+
+type explainRoute struct {
+	virtualService string
+	route          *common.WrapperHTTPRoute
+}
+
+type explainDestinationRule struct {
+	config  config.Config
+	wrapper *common.WrapperDestinationRule
+}
+
+type explainEnvoyFilter struct {
+	config *config.Config
+	route  *common.WrapperHTTPRoute
+	// field of annotations.Ingress producing the filter
+	field string
+}
+
+// Explain returns the routes, DestinationRules, EnvoyFilters and WasmPlugin rules generated from the ingresses
+// selected by query in the last translation, together with the annotations producing them.
+func (m *IngressConfig) Explain(query ExplainQuery) *ExplainResult {
+	var ingresses []config.Config
+	m.mutex.RLock()
+	for _, ingressController := range m.remoteIngressControllers {
+		ingresses = append(ingresses, ingressController.List()...)
+	}
+	state := m.explainState
+	m.mutex.RUnlock()
+	common.SortIngressByCreationTime(ingresses)
+
+	byIngress := map[string][]explainRoute{}
+	for _, r := range state.routes {
+		if query.Host != "" && !(hostMatches(r.route.Host, query.Host) && pathMatches(r.route, query.Path)) {
+			continue
+		}
+		key := ingressKey(r.route.WrapperConfig.Config)
+		byIngress[key] = append(byIngress[key], r)
+	}
+
+	result := &ExplainResult{}
+	for i := range ingresses {
+		ing := &ingresses[i]
+		if query.Name != "" && (ing.Namespace != query.Namespace || ing.Name != query.Name) {
+			continue
+		}
+		routes := byIngress[ingressKey(ing)]
+		if query.Host != "" && len(routes) == 0 {
+			continue
+		}
+		result.Ingresses = append(result.Ingresses, m.explainIngress(ing, routes, state))
+	}
+	return result
+}
+
+func (m *IngressConfig) explainIngress(ing *config.Config, routes []explainRoute, state explainState) *IngressExplanation {
+	explanation := &IngressExplanation{
+		Cluster:   common.GetClusterId(ing.Annotations).String(),
+		Namespace: ing.Namespace,
+		Name:      ing.Name,
+	}
+	explanation.Annotations = m.explainAnnotations(ing)
+	annotationsOf := func(fields map[string]bool) []string {
+		var keys []string
+		for _, a := range explanation.Annotations {
+			for _, field := range a.Affects {
+				if fields[field] {
+					keys = append(keys, a.Key)
+					break
+				}
+			}
+		}
+		return keys
+	}
+
+	key := ingressKey(ing)
+	destinations := map[string]bool{}
+	for _, r := range routes {
+		explanation.Routes = append(explanation.Routes, RouteExplanation{
+			VirtualService: r.virtualService,
+			Host:           r.route.Host,
+			Path:           r.route.OriginPath,
+			PathType:       string(r.route.OriginPathType),
+			Annotations:    annotationsOf(routeAnnotationFields),
+			Route:          marshalExplainJSON(r.route.HTTPRoute),
+		})
+		for _, destination := range r.route.HTTPRoute.Route {
+			if destination.Destination != nil {
+				destinations[destination.Destination.Host] = true
+			}
+		}
+	}
+
+	for _, dr := range state.destinationRules {
+		fromIngress := dr.wrapper.WrapperConfig != nil && ingressKey(dr.wrapper.WrapperConfig.Config) == key
+		if !fromIngress && !destinations[dr.wrapper.DestinationRule.Host] {
+			continue
+		}
+		item := ConfigExplanation{
+			Name:      dr.config.Name,
+			Namespace: dr.config.Namespace,
+			Spec:      marshalExplainJSON(dr.wrapper.DestinationRule),
+		}
+		if fromIngress {
+			item.Source = "ingress " + ing.Namespace + "/" + ing.Name
+			item.Annotations = annotationsOf(trafficPolicyAnnotationFields)
+		} else if dr.wrapper.WrapperConfig != nil {
+			item.Source = "ingress " + dr.wrapper.WrapperConfig.Config.Namespace + "/" + dr.wrapper.WrapperConfig.Config.Name
+		} else {
+			item.Source = "McpBridge"
+		}
+		explanation.DestinationRules = append(explanation.DestinationRules, item)
+	}
+
+	routeNames := map[string]bool{}
+	for _, r := range routes {
+		routeNames[r.route.HTTPRoute.Name] = true
+	}
+	seen := map[string]bool{}
+	for _, ef := range state.envoyFilters {
+		if !routeNames[ef.route.HTTPRoute.Name] || ingressKey(ef.route.WrapperConfig.Config) != key || seen[ef.config.Name] {
+			continue
+		}
+		seen[ef.config.Name] = true
+		explanation.EnvoyFilters = append(explanation.EnvoyFilters, ConfigExplanation{
+			Name:        ef.config.Name,
+			Namespace:   ef.config.Namespace,
+			Source:      "ingress " + ing.Namespace + "/" + ing.Name,
+			Annotations: annotationsOf(map[string]bool{ef.field: true}),
+			Spec:        marshalExplainJSON(ef.config.Spec.(proto.Message)),
+		})
+	}
+
+	explanation.WasmPlugins = m.explainWasmPlugins(ing, routes)
+	return explanation
+}
+
+// explainAnnotations finds out which annotations take effect by parsing the annotations again without each of them.
+func (m *IngressConfig) explainAnnotations(ing *config.Config) []AnnotationExplanation {
+	full, fullMcpServers := m.parseAnnotations(ing, ing.Annotations)
+
+	var keys []string
+	for key := range ing.Annotations {
+		if _, ok := annotationSuffix(key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result []AnnotationExplanation
+	for _, key := range keys {
+		item := AnnotationExplanation{Key: key, Value: ing.Annotations[key], Status: AnnotationIgnored}
+		item.Affects = m.annotationEffects(ing, full, fullMcpServers, key)
+		if len(item.Affects) > 0 {
+			item.Status = AnnotationApplied
+		} else {
+			// The same annotation may be configured with both the nginx and the higress prefix
+			suffix, _ := annotationSuffix(key)
+			var twins []string
+			for _, other := range keys {
+				if otherSuffix, _ := annotationSuffix(other); other != key && otherSuffix == suffix {
+					twins = append(twins, other)
+				}
+			}
+			if len(twins) > 0 {
+				if item.Affects = m.annotationEffects(ing, full, fullMcpServers, append(twins, key)...); len(item.Affects) > 0 {
+					item.Status = AnnotationApplied
+					item.Reason = "same as " + strings.Join(twins, ", ")
+				}
+			}
+		}
+		if item.Status == AnnotationIgnored {
+			item.Reason = "not supported, invalid value or depends on another annotation"
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// annotationEffects returns the fields of annotations.Ingress which change when the keys are removed.
+func (m *IngressConfig) annotationEffects(ing *config.Config, full *annotations.Ingress, fullMcpServers int, keys ...string) []string {
+	removed := make(map[string]string, len(ing.Annotations))
+	for k, v := range ing.Annotations {
+		removed[k] = v
+	}
+	for _, key := range keys {
+		delete(removed, key)
+	}
+	parsed, mcpServers := m.parseAnnotations(ing, removed)
+	effects := changedAnnotationFields(full, parsed)
+	if mcpServers != fullMcpServers {
+		effects = append(effects, "McpServer")
+	}
+	return effects
+}
+
+func (m *IngressConfig) parseAnnotations(ing *config.Config, raw map[string]string) (*annotations.Ingress, int) {
+	parsed := &annotations.Ingress{
+		Meta: annotations.Meta{
+			Namespace:    ing.Namespace,
+			Name:         ing.Name,
+			RawClusterId: common.GetRawClusterId(ing.Annotations),
+			ClusterId:    common.GetClusterId(ing.Annotations),
+		},
+	}
+	globalContext := m.newAnnotationGlobalContext()
+	_ = m.annotationHandler.Parse(raw, parsed, globalContext)
+	return parsed, len(globalContext.McpServers)
+}
+
+// changedAnnotationFields compares the parsed annotation configs field by field.
+func changedAnnotationFields(a, b *annotations.Ingress) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	t := va.Type()
+	var fields, unexported []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			continue
+		}
+		if !field.IsExported() {
+			unexported = append(unexported, field.Name)
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			fields = append(fields, field.Name)
+		}
+	}
+	// Unexported fields can not be read by reflection, compare them after clearing all the exported fields
+	ca, cb := *a, *b
+	vca, vcb := reflect.ValueOf(&ca).Elem(), reflect.ValueOf(&cb).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			vca.Field(i).Set(reflect.Zero(t.Field(i).Type))
+			vcb.Field(i).Set(reflect.Zero(t.Field(i).Type))
+		}
+	}
+	if !reflect.DeepEqual(ca, cb) {
+		fields = append(fields, unexported...)
+	}
+	return fields
+}
+
+func (m *IngressConfig) explainWasmPlugins(ing *config.Config, routes []explainRoute) []WasmPluginExplanation {
+	plugins, err := m.wasmPluginLister.WasmPlugins(m.namespace).List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+	m.mutex.RLock()
+	enabled := map[string]bool{}
+	for name := range m.wasmPlugins {
+		enabled[name] = true
+	}
+	m.mutex.RUnlock()
+
+	hosts := map[string]bool{}
+	services := map[string]bool{}
+	for _, r := range routes {
+		hosts[r.route.Host] = true
+		for _, destination := range r.route.HTTPRoute.Route {
+			if destination.Destination != nil {
+				services[destination.Destination.Host] = true
+			}
+		}
+	}
+
+	var result []WasmPluginExplanation
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	for _, plugin := range plugins {
+		if !enabled[plugin.Name] {
+			continue
+		}
+		spec := &plugin.Spec
+		item := WasmPluginExplanation{
+			Name:      plugin.Name,
+			Namespace: plugin.Namespace,
+			Phase:     spec.Phase.String(),
+			Priority:  spec.Priority.GetValue(),
+		}
+		matched := false
+		for _, rule := range spec.MatchRules {
+			if isBoolValueTrue(rule.ConfigDisable) {
+				continue
+			}
+			if match := matchWasmPluginRule(rule, ing, hosts, services); match != nil {
+				item.MatchedBy, item.Match = match[0], match[1:]
+				item.Config = marshalExplainJSON(rule.Config)
+				matched = true
+				break
+			}
+		}
+		if !matched && !isBoolValueTrue(spec.DefaultConfigDisable) && spec.DefaultConfig != nil {
+			item.MatchedBy = "default"
+			item.Config = marshalExplainJSON(spec.DefaultConfig)
+			matched = true
+		}
+		if matched {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// matchWasmPluginRule returns the kind of the matching condition followed by the matched items, or nil if not matched.
+func matchWasmPluginRule(rule *higressext.MatchRule, ing *config.Config, hosts, services map[string]bool) []string {
+	for _, name := range rule.Ingress {
+		if name == ing.Namespace+"/"+ing.Name || name == ing.Name {
+			return []string{"ingress", name}
+		}
+	}
+	for _, domain := range rule.Domain {
+		for host := range hosts {
+			if hostMatches(domain, host) {
+				return []string{"domain", domain}
+			}
+		}
+	}
+	for _, service := range rule.Service {
+		for host := range services {
+			if service == host || strings.HasPrefix(service, host+":") {
+				return []string{"service", service}
+			}
+		}
+	}
+	return nil
+}
+
+func ingressKey(ing *config.Config) string {
+	return common.GetClusterId(ing.Annotations).String() + "/" + ing.Namespace + "/" + ing.Name
+}
+
+func annotationSuffix(key string) (string, bool) {
+	for _, prefix := range []string{annotations.DefaultAnnotationsPrefix + "/", annotations.HigressAnnotationsPrefix + "/"} {
+		if strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix), true
+		}
+	}
+	return "", false
+}
+
+// hostMatches checks whether host is matched by pattern, which may be * or a wildcard domain such as *.example.com
+func hostMatches(pattern, host string) bool {
+	if pattern == host || pattern == common.DefaultHost {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return false
+}
+
+func pathMatches(route *common.WrapperHTTPRoute, path string) bool {
+	if path == "" {
+		return true
+	}
+	switch route.OriginPathType {
+	case common.Exact:
+		return path == route.OriginPath
+	case common.PrefixRegex, common.FullPathRegex:
+		expr := "^" + route.OriginPath
+		if route.OriginPathType == common.FullPathRegex {
+			expr = "^(?:" + route.OriginPath + ")$"
+		}
+		re, err := regexp.Compile(expr)
+		return err == nil && re.MatchString(path)
+	default:
+		prefix := strings.TrimSuffix(route.OriginPath, "/")
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+}
+
+func marshalExplainJSON(msg proto.Message) json.RawMessage {
+	if msg == nil || reflect.ValueOf(msg).IsNil() {
+		return nil
+	}
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return b
+}
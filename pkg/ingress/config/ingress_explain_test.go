@@ -0,0 +1,132 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/v2/pkg/kube"
+)
+
+func TestHostMatches(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		host    string
+		expect  bool
+	}{
+		{pattern: "foo.com", host: "foo.com", expect: true},
+		{pattern: "foo.com", host: "bar.com"},
+		{pattern: "*.foo.com", host: "a.foo.com", expect: true},
+		{pattern: "*.foo.com", host: "foo.com"},
+		{pattern: "*", host: "foo.com", expect: true},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expect, hostMatches(testCase.pattern, testCase.host), "%s %s", testCase.pattern, testCase.host)
+	}
+}
+
+func TestPathMatches(t *testing.T) {
+	testCases := []struct {
+		pathType common.PathType
+		path     string
+		request  string
+		expect   bool
+	}{
+		{pathType: common.Exact, path: "/foo", request: "/foo", expect: true},
+		{pathType: common.Exact, path: "/foo", request: "/foo/bar"},
+		{pathType: common.Prefix, path: "/foo", request: "/foo/bar", expect: true},
+		{pathType: common.Prefix, path: "/foo/", request: "/foo", expect: true},
+		{pathType: common.Prefix, path: "/foo", request: "/foobar"},
+		{pathType: common.Prefix, path: "/", request: "/foo", expect: true},
+		{pathType: common.PrefixRegex, path: "/foo/[0-9]+", request: "/foo/12/bar", expect: true},
+		{pathType: common.FullPathRegex, path: "/foo/[0-9]+", request: "/foo/12/bar"},
+		{pathType: common.FullPathRegex, path: "/foo/[0-9]+", request: "/foo/12", expect: true},
+		{pathType: common.Prefix, path: "/foo", request: "", expect: true},
+	}
+	for _, testCase := range testCases {
+		route := &common.WrapperHTTPRoute{OriginPath: testCase.path, OriginPathType: testCase.pathType}
+		assert.Equal(t, testCase.expect, pathMatches(route, testCase.request), "%s %s %s", testCase.pathType, testCase.path, testCase.request)
+	}
+}
+
+func TestChangedAnnotationFields(t *testing.T) {
+	a := &annotations.Ingress{
+		Meta:    annotations.Meta{Name: "foo"},
+		Cors:    &annotations.CorsConfig{Enabled: true},
+		Timeout: &annotations.TimeoutConfig{},
+	}
+	b := &annotations.Ingress{
+		Meta:    annotations.Meta{Name: "bar"},
+		Timeout: &annotations.TimeoutConfig{},
+	}
+	assert.Equal(t, []string{"Cors"}, changedAnnotationFields(a, b))
+	assert.Empty(t, changedAnnotationFields(a, a))
+}
+
+func TestAnnotationSuffix(t *testing.T) {
+	suffix, ok := annotationSuffix("nginx.ingress.kubernetes.io/enable-cors")
+	assert.True(t, ok)
+	assert.Equal(t, "enable-cors", suffix)
+	suffix, ok = annotationSuffix("higress.io/enable-cors")
+	assert.True(t, ok)
+	assert.Equal(t, "enable-cors", suffix)
+	_, ok = annotationSuffix("kubernetes.io/ingress.class")
+	assert.False(t, ok)
+}
+
+func TestExplainTrafficPolicyAnnotations(t *testing.T) {
+	m := NewIngressConfig(kube.NewFakeClient(), nil, "wakanda", common.Options{Enable: true, ClusterId: "gw-123-istio"})
+	ing := &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.Ingress,
+			Namespace:        "default",
+			Name:             "foo",
+			Annotations: map[string]string{
+				"higress.io/max-connections": "100",
+				"higress.io/consecutive-5xx": "3",
+				"higress.io/ejection-time":   "30s",
+				"higress.io/enable-cors":     "true",
+			},
+		},
+	}
+	state := explainState{
+		destinationRules: []explainDestinationRule{{
+			config: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.DestinationRule, Namespace: "wakanda", Name: "foo-dr"},
+			},
+			wrapper: &common.WrapperDestinationRule{
+				DestinationRule: &networking.DestinationRule{Host: "foo.default.svc.cluster.local"},
+				WrapperConfig:   &common.WrapperConfig{Config: ing},
+			},
+		}},
+	}
+
+	explanation := m.explainIngress(ing, nil, state)
+	require.Len(t, explanation.DestinationRules, 1)
+	assert.Equal(t, "ingress default/foo", explanation.DestinationRules[0].Source)
+	assert.Equal(t, []string{
+		"higress.io/consecutive-5xx",
+		"higress.io/ejection-time",
+		"higress.io/max-connections",
+	}, explanation.DestinationRules[0].Annotations)
+}
@@ -94,8 +94,9 @@ func (p *TemplateProcessor) ProcessConfig(cfg *config.Config) error {
 				IngressLog.Errorf("failed to add secret dependency: %v", err)
 			}
 		}
-		// Replace placeholder with actual value
-		configStr = strings.Replace(configStr, match[0], value, 1)
+		// Replace placeholder with actual value, the value is escaped since the placeholder is always inside a JSON string,
+		// so that values containing quotes or newlines such as a JSON document stored in a secret can be substituted
+		configStr = strings.Replace(configStr, match[0], escapeJSONString(value), 1)
 	}
 
 	// Create a new instance of the same type as cfg.Spec
@@ -117,3 +118,12 @@ func (p *TemplateProcessor) ProcessConfig(cfg *config.Config) error {
 	IngressLog.Infof("end to process config %s/%s", cfg.Namespace, cfg.Name)
 	return nil
 }
+
+// escapeJSONString escapes a value to be embedded in a JSON string literal
+func escapeJSONString(value string) string {
+	escaped, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(escaped[1 : len(escaped)-1])
+}
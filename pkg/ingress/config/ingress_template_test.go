@@ -33,6 +33,7 @@ func TestTemplateProcessor_ProcessConfig(t *testing.T) {
 		"secret.default/test-secret.plugin_conf.max_retries":        "3",
 		"secret.higress-system/auth-secret.auth_config.type":        "basic",
 		"secret.higress-system/auth-secret.auth_config.credentials": "base64-encoded",
+		"secret.higress-system/consumers.consumers":                 "[{\"name\":\"consumer1\",\"credential\":\"sha256:abc\"}]\n",
 	}
 
 	// Mock value getter function
@@ -174,6 +175,26 @@ func TestTemplateProcessor_ProcessConfig(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "json value with quotes and newlines",
+			wasmPlugin: &extensions.WasmPlugin{
+				PluginName: "test-plugin",
+				PluginConfig: makeStructValue(t, map[string]interface{}{
+					"consumer_directory": map[string]interface{}{
+						"consumers": "${secret.consumers.consumers}",
+					},
+				}),
+			},
+			expected: &extensions.WasmPlugin{
+				PluginName: "test-plugin",
+				PluginConfig: makeStructValue(t, map[string]interface{}{
+					"consumer_directory": map[string]interface{}{
+						"consumers": "[{\"name\":\"consumer1\",\"credential\":\"sha256:abc\"}]\n",
+					},
+				}),
+			},
+			expectError: false,
+		},
 		{
 			name: "non-existent secret",
 			wasmPlugin: &extensions.WasmPlugin{
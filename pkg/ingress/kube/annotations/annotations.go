@@ -15,6 +15,7 @@
 package annotations
 
 import (
+	"errors"
 	"strings"
 
 	networking "istio.io/api/networking/v1alpha3"
@@ -67,6 +68,8 @@ type Ingress struct {
 
 	LoadBalance *LoadBalanceConfig
 
+	TrafficPolicy *TrafficPolicyConfig
+
 	localRateLimit *localRateLimitConfig
 
 	Fallback *FallbackConfig
@@ -84,6 +87,8 @@ type Ingress struct {
 	HeaderControl *HeaderControlConfig
 
 	Http2Rpc *Http2RpcConfig
+
+	Compression *CompressionConfig
 }
 
 func (i *Ingress) NeedRegexMatch(path string) bool {
@@ -132,11 +137,13 @@ func (i *Ingress) CanaryKind() (bool, bool) {
 
 func (i *Ingress) NeedTrafficPolicy() bool {
 	return i.UpstreamTLS != nil ||
-		i.LoadBalance != nil
+		i.LoadBalance != nil ||
+		i.TrafficPolicy != nil
 }
 
 type AnnotationHandler interface {
 	Parser
+	Validator
 	GatewayHandler
 	VirtualServiceHandler
 	RouteHandler
@@ -164,6 +171,7 @@ func NewAnnotationHandlerManager() AnnotationHandler {
 			timeout{},
 			retry{},
 			loadBalance{},
+			trafficPolicy{},
 			localRateLimit{},
 			fallback{},
 			auth{},
@@ -174,6 +182,7 @@ func NewAnnotationHandlerManager() AnnotationHandler {
 			headerControl{},
 			http2rpc{},
 			mcpServer{},
+			compression{},
 		},
 		gatewayHandlers: []GatewayHandler{
 			downstreamTLS{},
@@ -198,6 +207,7 @@ func NewAnnotationHandlerManager() AnnotationHandler {
 		trafficPolicyHandlers: []TrafficPolicyHandler{
 			upstreamTLS{},
 			loadBalance{},
+			trafficPolicy{},
 		},
 	}
 }
@@ -210,6 +220,21 @@ func (h *AnnotationHandlerManager) Parse(annotations Annotations, config *Ingres
 	return nil
 }
 
+// Validate runs all the parsers on the annotations and collects the errors of the invalid annotations they ignore.
+// Nothing is resolved from the cluster, so the annotations referring to other resources are not checked.
+func (h *AnnotationHandlerManager) Validate(annotations Annotations, config *Ingress) error {
+	globalContext := &GlobalContext{
+		WatchedSecrets:      sets.New[string](),
+		ClusterSecretLister: map[cluster.ID]listersv1.SecretLister{},
+		ClusterServiceList:  map[cluster.ID]listersv1.ServiceLister{},
+	}
+	var errs []error
+	for _, parser := range h.parsers {
+		errs = append(errs, parser.Parse(annotations, config, globalContext))
+	}
+	return errors.Join(errs...)
+}
+
 func (h *AnnotationHandlerManager) ApplyGateway(gateway *networking.Gateway, config *Ingress) {
 	for _, handler := range h.gatewayHandlers {
 		handler.ApplyGateway(gateway, config)
@@ -15,6 +15,9 @@
 package annotations
 
 import (
+	"errors"
+	"strconv"
+
 	networking "istio.io/api/networking/v1alpha3"
 )
 
@@ -57,9 +60,10 @@ func (c canary) Parse(annotations Annotations, config *Ingress, _ *GlobalContext
 		config.Canary = canaryConfig
 	}()
 
-	canaryConfig.Enabled, _ = annotations.ParseBoolASAP(enableCanary)
+	var err error
+	canaryConfig.Enabled, err = annotations.ParseBoolASAP(enableCanary)
 	if !canaryConfig.Enabled {
-		return nil
+		return invalidValue(err)
 	}
 
 	if header, err := annotations.ParseStringASAP(canaryByHeader); err == nil {
@@ -84,12 +88,16 @@ func (c canary) Parse(annotations Annotations, config *Ingress, _ *GlobalContext
 		return nil
 	}
 
-	canaryConfig.Weight, _ = annotations.ParseIntASAP(canaryWeight)
-	if weightTotal, err := annotations.ParseIntASAP(canaryWeightTotal); err == nil && weightTotal > 0 {
+	canaryConfig.Weight, err = annotations.ParseIntASAP(canaryWeight)
+	weightErr := invalidValue(err)
+	weightTotal, err := annotations.ParseIntASAP(canaryWeightTotal)
+	if err == nil && weightTotal > 0 {
 		canaryConfig.WeightTotal = weightTotal
+	} else if err == nil {
+		err = invalidAnnotation(annotations.sourceKey(canaryWeightTotal), strconv.Itoa(weightTotal), "must be positive")
 	}
 
-	return nil
+	return errors.Join(weightErr, invalidValue(err))
 }
 
 func ApplyByWeight(canary, route *networking.HTTPRoute, canaryIngress *Ingress) {
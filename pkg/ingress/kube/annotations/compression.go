@@ -0,0 +1,103 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"strings"
+
+	. "github.com/alibaba/higress/v2/pkg/ingress/log"
+)
+
+const (
+	disableCompression    = "disable-compression"
+	compressionAlgorithms = "compression-algorithms"
+
+	CompressionGzip   = "gzip"
+	CompressionBrotli = "br"
+	CompressionZstd   = "zstd"
+)
+
+var (
+	_ Parser = compression{}
+
+	allCompressionAlgorithms = []string{CompressionGzip, CompressionBrotli, CompressionZstd}
+)
+
+type CompressionConfig struct {
+	// DisabledAlgorithms are the response compression algorithms turned off on the route
+	DisabledAlgorithms []string
+}
+
+type compression struct{}
+
+func (c compression) Parse(annotations Annotations, config *Ingress, _ *GlobalContext) error {
+	if !needCompressionConfig(annotations) {
+		return nil
+	}
+
+	if disabled, err := annotations.ParseBoolForHigress(disableCompression); err == nil && disabled {
+		config.Compression = &CompressionConfig{
+			DisabledAlgorithms: allCompressionAlgorithms,
+		}
+		return nil
+	}
+
+	value, err := annotations.ParseStringForHigress(compressionAlgorithms)
+	if err != nil {
+		return nil
+	}
+
+	enabled := map[string]bool{}
+	for _, algorithm := range strings.Split(value, ",") {
+		algorithm = strings.TrimSpace(algorithm)
+		if algorithm == "" {
+			continue
+		}
+		if !isCompressionAlgorithm(algorithm) {
+			IngressLog.Errorf("unknown compression algorithm %s within ingress %s/%s", algorithm, config.Namespace, config.Name)
+			continue
+		}
+		enabled[algorithm] = true
+	}
+
+	var disabledAlgorithms []string
+	for _, algorithm := range allCompressionAlgorithms {
+		if !enabled[algorithm] {
+			disabledAlgorithms = append(disabledAlgorithms, algorithm)
+		}
+	}
+	if len(disabledAlgorithms) == 0 {
+		return nil
+	}
+
+	config.Compression = &CompressionConfig{
+		DisabledAlgorithms: disabledAlgorithms,
+	}
+	return nil
+}
+
+func isCompressionAlgorithm(algorithm string) bool {
+	for _, v := range allCompressionAlgorithms {
+		if v == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+func needCompressionConfig(annotations Annotations) bool {
+	return annotations.HasHigress(disableCompression) ||
+		annotations.HasHigress(compressionAlgorithms)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompressionParse(t *testing.T) {
+	parser := compression{}
+
+	testCases := []struct {
+		input  Annotations
+		expect *CompressionConfig
+	}{
+		{
+			input:  Annotations{},
+			expect: nil,
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(disableCompression): "true",
+			},
+			expect: &CompressionConfig{
+				DisabledAlgorithms: []string{"gzip", "br", "zstd"},
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(disableCompression): "false",
+			},
+			expect: nil,
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(compressionAlgorithms): "br, zstd",
+			},
+			expect: &CompressionConfig{
+				DisabledAlgorithms: []string{"gzip"},
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(compressionAlgorithms): "gzip,unknown",
+			},
+			expect: &CompressionConfig{
+				DisabledAlgorithms: []string{"br", "zstd"},
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(compressionAlgorithms): "gzip,br,zstd",
+			},
+			expect: nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run("", func(t *testing.T) {
+			config := &Ingress{}
+			_ = parser.Parse(tt.input, config, nil)
+			if !reflect.DeepEqual(tt.expect, config.Compression) {
+				t.Fatalf("Should be equal, expect %v, got %v", tt.expect, config.Compression)
+			}
+		})
+	}
+}
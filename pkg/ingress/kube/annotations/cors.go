@@ -15,6 +15,7 @@
 package annotations
 
 import (
+	"errors"
 	"net/url"
 	"strings"
 
@@ -65,9 +66,9 @@ func (c cors) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 	}
 
 	// cors enable
-	enable, _ := annotations.ParseBoolASAP(enableCors)
+	enable, err := annotations.ParseBoolASAP(enableCors)
 	if !enable {
-		return nil
+		return invalidValue(err)
 	}
 
 	corsConfig := &CorsConfig{
@@ -104,16 +105,18 @@ func (c cors) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 	}
 
 	// allow credentials
-	if allowCredentials, err := annotations.ParseBoolASAP(allowCredentials); err == nil {
-		corsConfig.AllowCredentials = allowCredentials
+	credentials, credentialsErr := annotations.ParseBoolASAP(allowCredentials)
+	if credentialsErr == nil {
+		corsConfig.AllowCredentials = credentials
 	}
 
 	// max age
-	if age, err := annotations.ParseIntASAP(maxAge); err == nil {
+	age, ageErr := annotations.ParseIntASAP(maxAge)
+	if ageErr == nil {
 		corsConfig.MaxAge = age
 	}
 
-	return nil
+	return errors.Join(invalidValue(credentialsErr), invalidValue(ageErr))
 }
 
 func (c cors) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -21,6 +21,13 @@ type Parser interface {
 	Parse(annotations Annotations, config *Ingress, globalContext *GlobalContext) error
 }
 
+// Validator reports the invalid annotations returned by the parsers, which are ignored when the ingress
+// is translated.
+type Validator interface {
+	// Validate parses ingress annotations into config and returns the invalid ones
+	Validate(annotations Annotations, config *Ingress) error
+}
+
 type GatewayHandler interface {
 	// ApplyGateway parsed ingress annotation config reflected on gateway
 	ApplyGateway(gateway *networking.Gateway, config *Ingress)
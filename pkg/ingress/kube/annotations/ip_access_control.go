@@ -15,6 +15,9 @@
 package annotations
 
 import (
+	"fmt"
+	"net"
+
 	networking "istio.io/api/networking/v1alpha3"
 	//"istio.io/istio/pilot/pkg/networking/core/v1alpha3/mseingress"
 )
@@ -50,18 +53,29 @@ func (i ipAccessControl) Parse(annotations Annotations, config *Ingress, _ *Glob
 	}()
 
 	var route *IPAccessControl
-	if rawWhitelist, err := annotations.ParseStringASAP(whitelist); err == nil {
+	var err error
+	if rawWhitelist, parseErr := annotations.ParseStringASAP(whitelist); parseErr == nil {
 		route = &IPAccessControl{
 			isWhite:  true,
 			remoteIp: splitStringWithSpaceTrim(rawWhitelist),
 		}
+		for _, ip := range route.remoteIp {
+			if net.ParseIP(ip) != nil {
+				continue
+			}
+			if _, _, cidrErr := net.ParseCIDR(ip); cidrErr != nil {
+				err = invalidAnnotation(annotations.sourceKey(whitelist), rawWhitelist,
+					fmt.Sprintf("%s is neither an IP nor a CIDR", ip))
+				break
+			}
+		}
 	}
 
 	if route != nil {
 		ipConfig.Route = route
 	}
 
-	return nil
+	return err
 }
 
 func (i ipAccessControl) ApplyVirtualServiceHandler(_ *networking.VirtualService, _ *Ingress) {
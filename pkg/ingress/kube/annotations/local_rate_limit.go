@@ -15,6 +15,9 @@
 package annotations
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/golang/protobuf/ptypes/duration"
 	networking "istio.io/api/networking/v1alpha3"
 	//"istio.io/istio/pilot/pkg/networking/core/v1alpha3/mseingress"
@@ -61,9 +64,11 @@ func (l localRateLimit) Parse(annotations Annotations, config *Ingress, _ *Globa
 	}()
 
 	multiplier := defaultBurstMultiplier
-	if m, err := annotations.ParseIntForHigress(limitBurstMultiplier); err == nil {
+	m, multiplierErr := annotations.ParseIntForHigress(limitBurstMultiplier)
+	if multiplierErr == nil {
 		multiplier = m
 	}
+	errs := []error{positiveValue(limitBurstMultiplier, m, multiplierErr)}
 
 	if rpm, err := annotations.ParseIntForHigress(limitRPM); err == nil {
 		local = &localRateLimitConfig{
@@ -71,15 +76,19 @@ func (l localRateLimit) Parse(annotations Annotations, config *Ingress, _ *Globa
 			TokensPerFill: uint32(rpm),
 			FillInterval:  minute,
 		}
-	} else if rps, err := annotations.ParseIntForHigress(limitRPS); err == nil {
+		errs = append(errs, positiveValue(limitRPM, rpm, err))
+	} else if rps, rpsErr := annotations.ParseIntForHigress(limitRPS); rpsErr == nil {
 		local = &localRateLimitConfig{
 			MaxTokens:     uint32(rps * multiplier),
 			TokensPerFill: uint32(rps),
 			FillInterval:  second,
 		}
+		errs = append(errs, invalidValue(err), positiveValue(limitRPS, rps, rpsErr))
+	} else {
+		errs = append(errs, invalidValue(err), invalidValue(rpsErr))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (l localRateLimit) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -104,6 +113,17 @@ func (l localRateLimit) ApplyRoute(route *networking.HTTPRoute, config *Ingress)
 	})
 }
 
+// positiveValue returns the error of the higress annotation parsed as value, which must be positive
+func positiveValue(key string, value int, err error) error {
+	if err != nil {
+		return invalidValue(err)
+	}
+	if value <= 0 {
+		return invalidAnnotation(buildHigressAnnotationKey(key), strconv.Itoa(value), "must be positive")
+	}
+	return nil
+}
+
 func needLocalRateLimitConfig(annotations Annotations) bool {
 	return annotations.HasHigress(limitRPM) ||
 		annotations.HasHigress(limitRPS)
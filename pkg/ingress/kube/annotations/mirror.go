@@ -15,6 +15,8 @@
 package annotations
 
 import (
+	"errors"
+
 	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
 	. "github.com/alibaba/higress/v2/pkg/ingress/log"
 	wrappers "google.golang.org/protobuf/types/known/wrapperspb"
@@ -53,16 +55,18 @@ func (m mirror) Parse(annotations Annotations, config *Ingress, globalContext *G
 		var port uint32
 		port = 80
 
-		if p, err := annotations.ParseInt32ASAP(mirrorTargetFQDNPort); err == nil {
+		p, portErr := annotations.ParseInt32ASAP(mirrorTargetFQDNPort)
+		if portErr == nil {
 			port = uint32(p)
 		}
 
+		percentage, percentageErr := parsePercentage(annotations)
 		config.Mirror = &MirrorConfig{
-			Percentage: parsePercentage(annotations),
+			Percentage: percentage,
 			FQDN:       fqdn,
 			FPort:      port,
 		}
-		return nil
+		return errors.Join(invalidValue(portErr), percentageErr)
 	}
 
 	target, err := annotations.ParseStringASAP(mirrorTargetService)
@@ -100,24 +104,26 @@ func (m mirror) Parse(annotations Annotations, config *Ingress, globalContext *G
 		serviceInfo.Port = uint32(service.Spec.Ports[0].Port)
 	}
 
+	percentage, err := parsePercentage(annotations)
 	config.Mirror = &MirrorConfig{
 		ServiceInfo: serviceInfo,
-		Percentage:  parsePercentage(annotations),
+		Percentage:  percentage,
 	}
-	return nil
+	return err
 }
 
-func parsePercentage(annotations Annotations) *wrappers.DoubleValue {
+func parsePercentage(annotations Annotations) (*wrappers.DoubleValue, error) {
 	var percentage *wrappers.DoubleValue
 
-	if value, err := annotations.ParseIntASAP(mirrorPercentage); err == nil {
+	value, err := annotations.ParseIntASAP(mirrorPercentage)
+	if err == nil {
 		if value < 100 {
 			percentage = &wrappers.DoubleValue{
 				Value: float64(value),
 			}
 		}
 	}
-	return percentage
+	return percentage, invalidValue(err)
 }
 
 func (m mirror) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
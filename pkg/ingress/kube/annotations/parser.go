@@ -59,7 +59,7 @@ func (a Annotations) ParseBool(key string) (bool, error) {
 	if ok {
 		b, err := strconv.ParseBool(val)
 		if err != nil {
-			return false, ErrInvalidAnnotationValue
+			return false, invalidAnnotation(buildNginxAnnotationKey(key), val, "must be true or false")
 		}
 		return b, nil
 	}
@@ -76,7 +76,7 @@ func (a Annotations) ParseBoolForHigress(key string) (bool, error) {
 	if ok {
 		b, err := strconv.ParseBool(val)
 		if err != nil {
-			return false, ErrInvalidAnnotationValue
+			return false, invalidAnnotation(buildHigressAnnotationKey(key), val, "must be true or false")
 		}
 		return b, nil
 	}
@@ -85,10 +85,12 @@ func (a Annotations) ParseBoolForHigress(key string) (bool, error) {
 }
 
 func (a Annotations) ParseBoolASAP(key string) (bool, error) {
-	if result, err := a.ParseBool(key); err == nil {
+	result, err := a.ParseBool(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseBoolForHigress(key)
+	higressResult, higressErr := a.ParseBoolForHigress(key)
+	return higressResult, asapError(err, higressErr)
 }
 
 func (a Annotations) ParseString(key string) (string, error) {
@@ -143,7 +145,7 @@ func (a Annotations) ParseInt(key string) (int, error) {
 	if ok {
 		i, err := strconv.Atoi(val)
 		if err != nil {
-			return 0, ErrInvalidAnnotationValue
+			return 0, invalidAnnotation(buildNginxAnnotationKey(key), val, "must be an integer")
 		}
 		return i, nil
 	}
@@ -159,7 +161,7 @@ func (a Annotations) ParseIntForHigress(key string) (int, error) {
 	if ok {
 		i, err := strconv.Atoi(val)
 		if err != nil {
-			return 0, ErrInvalidAnnotationValue
+			return 0, invalidAnnotation(buildHigressAnnotationKey(key), val, "must be an integer")
 		}
 		return i, nil
 	}
@@ -175,7 +177,7 @@ func (a Annotations) ParseInt32(key string) (int32, error) {
 	if ok {
 		i, err := strconv.ParseInt(val, 10, 32)
 		if err != nil {
-			return 0, ErrInvalidAnnotationValue
+			return 0, invalidAnnotation(buildNginxAnnotationKey(key), val, "must be an integer")
 		}
 		return int32(i), nil
 	}
@@ -191,7 +193,7 @@ func (a Annotations) ParseInt32ForHigress(key string) (int32, error) {
 	if ok {
 		i, err := strconv.ParseInt(val, 10, 32)
 		if err != nil {
-			return 0, ErrInvalidAnnotationValue
+			return 0, invalidAnnotation(buildHigressAnnotationKey(key), val, "must be an integer")
 		}
 		return int32(i), nil
 	}
@@ -207,7 +209,7 @@ func (a Annotations) ParseUint32ForHigress(key string) (uint32, error) {
 	if ok {
 		i, err := strconv.ParseUint(val, 10, 32)
 		if err != nil {
-			return 0, ErrInvalidAnnotationValue
+			return 0, invalidAnnotation(buildHigressAnnotationKey(key), val, "must be a non-negative integer")
 		}
 		return uint32(i), nil
 	}
@@ -215,17 +217,21 @@ func (a Annotations) ParseUint32ForHigress(key string) (uint32, error) {
 }
 
 func (a Annotations) ParseIntASAP(key string) (int, error) {
-	if result, err := a.ParseInt(key); err == nil {
+	result, err := a.ParseInt(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseIntForHigress(key)
+	higressResult, higressErr := a.ParseIntForHigress(key)
+	return higressResult, asapError(err, higressErr)
 }
 
 func (a Annotations) ParseInt32ASAP(key string) (int32, error) {
-	if result, err := a.ParseInt32(key); err == nil {
+	result, err := a.ParseInt32(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseInt32ForHigress(key)
+	higressResult, higressErr := a.ParseInt32ForHigress(key)
+	return higressResult, asapError(err, higressErr)
 }
 
 func (a Annotations) Has(key string) bool {
@@ -253,6 +259,15 @@ func (a Annotations) HasASAP(key string) bool {
 	return a.HasHigress(key)
 }
 
+// asapError returns the error of the Higress annotation, unless it is missing while the nginx one is invalid,
+// so that the invalid value is not hidden by the fallback.
+func asapError(nginxErr, higressErr error) error {
+	if IsMissingAnnotations(higressErr) && !IsMissingAnnotations(nginxErr) {
+		return nginxErr
+	}
+	return higressErr
+}
+
 func buildNginxAnnotationKey(key string) string {
 	return DefaultAnnotationsPrefix + "/" + key
 }
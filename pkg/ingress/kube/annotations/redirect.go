@@ -15,9 +15,11 @@
 package annotations
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	networking "istio.io/api/networking/v1alpha3"
@@ -64,41 +66,54 @@ func (r redirect) Parse(annotations Annotations, config *Ingress, _ *GlobalConte
 
 	redirectConfig.AppRoot, _ = annotations.ParseStringASAP(appRoot)
 
-	httpsRedirect, _ := annotations.ParseBoolASAP(sslRedirect)
-	forceHTTPSRedirect, _ := annotations.ParseBoolASAP(forceSSLRedirect)
+	httpsRedirect, sslRedirectErr := annotations.ParseBoolASAP(sslRedirect)
+	forceHTTPSRedirect, forceSSLRedirectErr := annotations.ParseBoolASAP(forceSSLRedirect)
 	if httpsRedirect || forceHTTPSRedirect {
 		redirectConfig.httpsRedirect = true
 	}
+	errs := []error{invalidValue(sslRedirectErr), invalidValue(forceSSLRedirectErr)}
 
 	// temporal redirect is firstly applied.
 	tr, err := annotations.ParseStringASAP(temporalRedirect)
 	if err != nil && !IsMissingAnnotations(err) {
-		return nil
+		return errors.Join(errs...)
 	}
-	if tr != "" && isValidURL(tr) == nil {
-		redirectConfig.URL = tr
-		redirectConfig.Code = defaultTemporalRedirectCode
-		return nil
+	if tr != "" {
+		if err := isValidURL(tr); err != nil {
+			errs = append(errs, invalidAnnotation(annotations.sourceKey(temporalRedirect), tr, err.Error()))
+		} else {
+			redirectConfig.URL = tr
+			redirectConfig.Code = defaultTemporalRedirectCode
+			return errors.Join(errs...)
+		}
 	}
 
 	// permanent redirect
 	// url
 	pr, err := annotations.ParseStringASAP(permanentRedirect)
 	if err != nil && !IsMissingAnnotations(err) {
-		return nil
+		return errors.Join(errs...)
 	}
-	if pr != "" && isValidURL(pr) == nil {
-		redirectConfig.URL = pr
+	if pr != "" {
+		if err := isValidURL(pr); err == nil {
+			redirectConfig.URL = pr
+		} else {
+			errs = append(errs, invalidAnnotation(annotations.sourceKey(permanentRedirect), pr, err.Error()))
+		}
 	}
 	// code
-	if prc, err := annotations.ParseIntASAP(permanentRedirectCode); err == nil {
+	prc, err := annotations.ParseIntASAP(permanentRedirectCode)
+	if err == nil {
 		if prc < http.StatusMultipleChoices || prc > http.StatusPermanentRedirect {
+			errs = append(errs, invalidAnnotation(annotations.sourceKey(permanentRedirectCode), strconv.Itoa(prc),
+				fmt.Sprintf("must be in the range [%d, %d]", http.StatusMultipleChoices, http.StatusPermanentRedirect)))
 			prc = defaultPermanentRedirectCode
 		}
 		redirectConfig.Code = prc
 	}
+	errs = append(errs, invalidValue(err))
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (r redirect) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
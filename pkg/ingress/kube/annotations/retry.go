@@ -15,6 +15,7 @@
 package annotations
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/golang/protobuf/ptypes/duration"
@@ -58,11 +59,13 @@ func (r retry) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 		config.Retry = retryConfig
 	}()
 
-	if count, err := annotations.ParseInt32ASAP(retryCount); err == nil {
+	count, countErr := annotations.ParseInt32ASAP(retryCount)
+	if countErr == nil {
 		retryConfig.retryCount = count
 	}
 
-	if timeout, err := annotations.ParseIntASAP(perRetryTimeout); err == nil {
+	timeout, timeoutErr := annotations.ParseIntASAP(perRetryTimeout)
+	if timeoutErr == nil {
 		retryConfig.perRetryTimeout = &duration.Duration{
 			Seconds: int64(timeout),
 		}
@@ -105,7 +108,7 @@ func (r retry) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 		}
 	}
 
-	return nil
+	return errors.Join(invalidValue(countErr), invalidValue(timeoutErr))
 }
 
 func (r retry) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -15,6 +15,7 @@
 package annotations
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -54,8 +55,9 @@ func (r rewrite) Parse(annotations Annotations, config *Ingress, _ *GlobalContex
 
 	rewriteConfig := &RewriteConfig{}
 	rewriteConfig.RewriteTarget, _ = annotations.ParseStringASAP(rewriteTarget)
-	rewriteConfig.UseRegex, _ = annotations.ParseBoolASAP(useRegex)
-	rewriteConfig.FullPathRegex, _ = annotations.ParseBoolForHigress(fullPathRegex)
+	var useRegexErr, fullPathRegexErr error
+	rewriteConfig.UseRegex, useRegexErr = annotations.ParseBoolASAP(useRegex)
+	rewriteConfig.FullPathRegex, fullPathRegexErr = annotations.ParseBoolForHigress(fullPathRegex)
 	rewriteConfig.RewriteHost, _ = annotations.ParseStringASAP(upstreamVhost)
 	rewriteConfig.RewritePath, _ = annotations.ParseStringForHigress(rewritePath)
 
@@ -65,7 +67,7 @@ func (r rewrite) Parse(annotations Annotations, config *Ingress, _ *GlobalContex
 	}
 
 	config.Rewrite = rewriteConfig
-	return nil
+	return errors.Join(invalidValue(useRegexErr), invalidValue(fullPathRegexErr))
 }
 
 func (r rewrite) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -39,14 +39,15 @@ func (t timeout) Parse(annotations Annotations, config *Ingress, _ *GlobalContex
 		return nil
 	}
 
-	if time, err := annotations.ParseIntForHigress(timeoutAnnotation); err == nil {
+	time, err := annotations.ParseIntForHigress(timeoutAnnotation)
+	if err == nil {
 		config.Timeout = &TimeoutConfig{
 			time: &types.Duration{
 				Seconds: int64(time),
 			},
 		}
 	}
-	return nil
+	return invalidValue(err)
 }
 
 func (t timeout) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -0,0 +1,272 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/duration"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	networking "istio.io/api/networking/v1alpha3"
+
+	. "github.com/alibaba/higress/v2/pkg/ingress/log"
+)
+
+const (
+	maxConnections           = "max-connections"
+	maxPendingRequests       = "max-pending-requests"
+	maxRequestsPerConnection = "max-requests-per-connection"
+	consecutive5xx           = "consecutive-5xx"
+	ejectionTime             = "ejection-time"
+	maxEjectionPercent       = "max-ejection-percent"
+)
+
+var (
+	_ Parser               = trafficPolicy{}
+	_ TrafficPolicyHandler = trafficPolicy{}
+)
+
+// TrafficPolicyConfig holds the connection pool and outlier detection settings of the backend service,
+// the nil fields are not set by the ingress.
+type TrafficPolicyConfig struct {
+	MaxConnections           *int32
+	MaxPendingRequests       *int32
+	MaxRequestsPerConnection *int32
+	Consecutive5xx           *uint32
+	EjectionTime             *duration.Duration
+	MaxEjectionPercent       *int32
+}
+
+type trafficPolicy struct{}
+
+func (t trafficPolicy) Parse(annotations Annotations, config *Ingress, _ *GlobalContext) error {
+	if !needTrafficPolicyConfig(annotations) {
+		return nil
+	}
+
+	trafficPolicyConfig := &TrafficPolicyConfig{}
+	var errs []error
+	parsePositive := func(key string) *int32 {
+		value, err := annotations.ParseInt32ForHigress(key)
+		if err == nil && value > 0 {
+			return &value
+		}
+		if err == nil {
+			err = invalidAnnotation(buildHigressAnnotationKey(key), strconv.Itoa(int(value)), "must be positive")
+		}
+		errs = append(errs, invalidValue(err))
+		return nil
+	}
+	trafficPolicyConfig.MaxConnections = parsePositive(maxConnections)
+	trafficPolicyConfig.MaxPendingRequests = parsePositive(maxPendingRequests)
+	trafficPolicyConfig.MaxRequestsPerConnection = parsePositive(maxRequestsPerConnection)
+	if value, err := annotations.ParseUint32ForHigress(consecutive5xx); err == nil {
+		trafficPolicyConfig.Consecutive5xx = &value
+	} else {
+		errs = append(errs, invalidValue(err))
+	}
+	if value, err := annotations.ParseStringForHigress(ejectionTime); err == nil {
+		if d, err := parseEjectionTime(value); err == nil {
+			trafficPolicyConfig.EjectionTime = &duration.Duration{
+				Seconds: int64(d / time.Second),
+				Nanos:   int32(d % time.Second),
+			}
+		} else {
+			errs = append(errs, invalidAnnotation(buildHigressAnnotationKey(ejectionTime), value,
+				"must be a duration such as 30s or an integer in seconds"))
+		}
+	}
+	if value, err := annotations.ParseInt32ForHigress(maxEjectionPercent); err == nil && value >= 0 && value <= 100 {
+		trafficPolicyConfig.MaxEjectionPercent = &value
+	} else if err == nil {
+		errs = append(errs, invalidAnnotation(buildHigressAnnotationKey(maxEjectionPercent), strconv.Itoa(int(value)),
+			"must be in the range [0, 100]"))
+	} else {
+		errs = append(errs, invalidValue(err))
+	}
+
+	if !trafficPolicyConfig.isEmpty() {
+		config.TrafficPolicy = trafficPolicyConfig
+	}
+	return errors.Join(errs...)
+}
+
+func (t trafficPolicy) ApplyTrafficPolicy(trafficPolicy *networking.TrafficPolicy, portTrafficPolicy *networking.TrafficPolicy_PortTrafficPolicy, config *Ingress) {
+	trafficPolicyConfig := config.TrafficPolicy
+	if trafficPolicyConfig == nil {
+		return
+	}
+
+	connectionPool := trafficPolicyConfig.connectionPool()
+	outlierDetection := trafficPolicyConfig.outlierDetection()
+
+	if trafficPolicy != nil {
+		if connectionPool != nil {
+			trafficPolicy.ConnectionPool = connectionPool
+		}
+		if outlierDetection != nil {
+			trafficPolicy.OutlierDetection = outlierDetection
+		}
+	}
+	if portTrafficPolicy != nil {
+		if connectionPool != nil {
+			portTrafficPolicy.ConnectionPool = connectionPool
+		}
+		if outlierDetection != nil {
+			portTrafficPolicy.OutlierDetection = outlierDetection
+		}
+	}
+}
+
+func (c *TrafficPolicyConfig) isEmpty() bool {
+	return c.MaxConnections == nil &&
+		c.MaxPendingRequests == nil &&
+		c.MaxRequestsPerConnection == nil &&
+		c.Consecutive5xx == nil &&
+		c.EjectionTime == nil &&
+		c.MaxEjectionPercent == nil
+}
+
+func (c *TrafficPolicyConfig) connectionPool() *networking.ConnectionPoolSettings {
+	if c.MaxConnections == nil && c.MaxPendingRequests == nil && c.MaxRequestsPerConnection == nil {
+		return nil
+	}
+
+	connectionPool := &networking.ConnectionPoolSettings{}
+	if c.MaxConnections != nil {
+		connectionPool.Tcp = &networking.ConnectionPoolSettings_TCPSettings{
+			MaxConnections: *c.MaxConnections,
+		}
+	}
+	if c.MaxPendingRequests != nil || c.MaxRequestsPerConnection != nil {
+		connectionPool.Http = &networking.ConnectionPoolSettings_HTTPSettings{}
+		if c.MaxPendingRequests != nil {
+			connectionPool.Http.Http1MaxPendingRequests = *c.MaxPendingRequests
+		}
+		if c.MaxRequestsPerConnection != nil {
+			connectionPool.Http.MaxRequestsPerConnection = *c.MaxRequestsPerConnection
+		}
+	}
+	return connectionPool
+}
+
+func (c *TrafficPolicyConfig) outlierDetection() *networking.OutlierDetection {
+	if c.Consecutive5xx == nil && c.EjectionTime == nil && c.MaxEjectionPercent == nil {
+		return nil
+	}
+
+	outlierDetection := &networking.OutlierDetection{}
+	if c.Consecutive5xx != nil {
+		outlierDetection.Consecutive_5XxErrors = wrapperspb.UInt32(*c.Consecutive5xx)
+	}
+	if c.EjectionTime != nil {
+		outlierDetection.BaseEjectionTime = c.EjectionTime
+	}
+	if c.MaxEjectionPercent != nil {
+		outlierDetection.MaxEjectionPercent = *c.MaxEjectionPercent
+	}
+	return outlierDetection
+}
+
+// MergeSharedTrafficPolicy returns the annotations config applied to the traffic policy of a backend service
+// shared by several ingresses. config is the one of the oldest ingress and shared are the others ordered by
+// creation time. Each connection pool and outlier detection setting is taken from the oldest ingress setting it,
+// the conflicting values of the newer ones are ignored. The other traffic policy annotations keep using config only.
+func MergeSharedTrafficPolicy(config *Ingress, shared []*Ingress) *Ingress {
+	if len(shared) == 0 {
+		return config
+	}
+
+	merged := &TrafficPolicyConfig{}
+	mergeTrafficPolicyConfig(merged, config)
+	for _, other := range shared {
+		mergeTrafficPolicyConfig(merged, other)
+	}
+	if merged.isEmpty() {
+		return config
+	}
+
+	result := *config
+	result.TrafficPolicy = merged
+	return &result
+}
+
+func mergeTrafficPolicyConfig(merged *TrafficPolicyConfig, config *Ingress) {
+	if config == nil || config.TrafficPolicy == nil {
+		return
+	}
+
+	from := config.TrafficPolicy
+	conflict := func(key string) {
+		IngressLog.Warnf("ingress %s/%s annotation %s conflicts with an older ingress sharing the backend service, ignored",
+			config.Namespace, config.Name, buildHigressAnnotationKey(key))
+	}
+	mergeInt32(&merged.MaxConnections, from.MaxConnections, func() { conflict(maxConnections) })
+	mergeInt32(&merged.MaxPendingRequests, from.MaxPendingRequests, func() { conflict(maxPendingRequests) })
+	mergeInt32(&merged.MaxRequestsPerConnection, from.MaxRequestsPerConnection, func() { conflict(maxRequestsPerConnection) })
+	mergeInt32(&merged.MaxEjectionPercent, from.MaxEjectionPercent, func() { conflict(maxEjectionPercent) })
+	if from.Consecutive5xx != nil {
+		if merged.Consecutive5xx == nil {
+			merged.Consecutive5xx = from.Consecutive5xx
+		} else if *merged.Consecutive5xx != *from.Consecutive5xx {
+			conflict(consecutive5xx)
+		}
+	}
+	if from.EjectionTime != nil {
+		if merged.EjectionTime == nil {
+			merged.EjectionTime = from.EjectionTime
+		} else if merged.EjectionTime.Seconds != from.EjectionTime.Seconds ||
+			merged.EjectionTime.Nanos != from.EjectionTime.Nanos {
+			conflict(ejectionTime)
+		}
+	}
+}
+
+func mergeInt32(merged **int32, from *int32, onConflict func()) {
+	if from == nil {
+		return
+	}
+	if *merged == nil {
+		*merged = from
+	} else if **merged != *from {
+		onConflict()
+	}
+}
+
+// parseEjectionTime accepts a duration such as 30s or 1m, or an integer in seconds.
+func parseEjectionTime(value string) (time.Duration, error) {
+	var d time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else if d, err = time.ParseDuration(value); err != nil {
+		return 0, err
+	}
+	// Envoy requires the base ejection time to be at least 1ms
+	if d < time.Millisecond {
+		return 0, errors.New("must be at least 1ms")
+	}
+	return d, nil
+}
+
+func needTrafficPolicyConfig(annotations Annotations) bool {
+	return annotations.HasHigress(maxConnections) ||
+		annotations.HasHigress(maxPendingRequests) ||
+		annotations.HasHigress(maxRequestsPerConnection) ||
+		annotations.HasHigress(consecutive5xx) ||
+		annotations.HasHigress(ejectionTime) ||
+		annotations.HasHigress(maxEjectionPercent)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/duration"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func uint32Ptr(i uint32) *uint32 {
+	return &i
+}
+
+func TestTrafficPolicyParse(t *testing.T) {
+	trafficPolicy := trafficPolicy{}
+	inputCases := []struct {
+		input  map[string]string
+		expect *TrafficPolicyConfig
+	}{
+		{},
+		{
+			input: map[string]string{
+				buildNginxAnnotationKey(maxConnections): "10",
+			},
+		},
+		{
+			input: map[string]string{
+				buildHigressAnnotationKey(maxConnections):           "10",
+				buildHigressAnnotationKey(maxPendingRequests):       "20",
+				buildHigressAnnotationKey(maxRequestsPerConnection): "1",
+			},
+			expect: &TrafficPolicyConfig{
+				MaxConnections:           int32Ptr(10),
+				MaxPendingRequests:       int32Ptr(20),
+				MaxRequestsPerConnection: int32Ptr(1),
+			},
+		},
+		{
+			input: map[string]string{
+				buildHigressAnnotationKey(consecutive5xx):     "5",
+				buildHigressAnnotationKey(ejectionTime):       "1m",
+				buildHigressAnnotationKey(maxEjectionPercent): "50",
+			},
+			expect: &TrafficPolicyConfig{
+				Consecutive5xx:     uint32Ptr(5),
+				EjectionTime:       &duration.Duration{Seconds: 60},
+				MaxEjectionPercent: int32Ptr(50),
+			},
+		},
+		{
+			input: map[string]string{
+				buildHigressAnnotationKey(ejectionTime): "30",
+			},
+			expect: &TrafficPolicyConfig{
+				EjectionTime: &duration.Duration{Seconds: 30},
+			},
+		},
+		{
+			input: map[string]string{
+				buildHigressAnnotationKey(maxConnections):     "0",
+				buildHigressAnnotationKey(ejectionTime):       "abc",
+				buildHigressAnnotationKey(maxEjectionPercent): "120",
+			},
+		},
+	}
+
+	for _, inputCase := range inputCases {
+		t.Run("", func(t *testing.T) {
+			config := &Ingress{}
+			_ = trafficPolicy.Parse(inputCase.input, config, nil)
+			if !reflect.DeepEqual(inputCase.expect, config.TrafficPolicy) {
+				t.Fatalf("Should be equal, expect %v, got %v", inputCase.expect, config.TrafficPolicy)
+			}
+		})
+	}
+}
+
+func TestTrafficPolicyApplyTrafficPolicy(t *testing.T) {
+	trafficPolicy := trafficPolicy{}
+	inputCases := []struct {
+		config *Ingress
+		input  *networking.TrafficPolicy_PortTrafficPolicy
+		expect *networking.TrafficPolicy_PortTrafficPolicy
+	}{
+		{
+			config: &Ingress{},
+			input:  &networking.TrafficPolicy_PortTrafficPolicy{},
+			expect: &networking.TrafficPolicy_PortTrafficPolicy{},
+		},
+		{
+			config: &Ingress{
+				TrafficPolicy: &TrafficPolicyConfig{
+					MaxConnections:     int32Ptr(10),
+					MaxPendingRequests: int32Ptr(20),
+				},
+			},
+			input: &networking.TrafficPolicy_PortTrafficPolicy{},
+			expect: &networking.TrafficPolicy_PortTrafficPolicy{
+				ConnectionPool: &networking.ConnectionPoolSettings{
+					Tcp: &networking.ConnectionPoolSettings_TCPSettings{
+						MaxConnections: 10,
+					},
+					Http: &networking.ConnectionPoolSettings_HTTPSettings{
+						Http1MaxPendingRequests: 20,
+					},
+				},
+			},
+		},
+		{
+			config: &Ingress{
+				TrafficPolicy: &TrafficPolicyConfig{
+					Consecutive5xx:     uint32Ptr(5),
+					EjectionTime:       &duration.Duration{Seconds: 30},
+					MaxEjectionPercent: int32Ptr(50),
+				},
+			},
+			input: &networking.TrafficPolicy_PortTrafficPolicy{},
+			expect: &networking.TrafficPolicy_PortTrafficPolicy{
+				OutlierDetection: &networking.OutlierDetection{
+					Consecutive_5XxErrors: wrapperspb.UInt32(5),
+					BaseEjectionTime:      &duration.Duration{Seconds: 30},
+					MaxEjectionPercent:    50,
+				},
+			},
+		},
+	}
+
+	for _, inputCase := range inputCases {
+		t.Run("", func(t *testing.T) {
+			trafficPolicy.ApplyTrafficPolicy(nil, inputCase.input, inputCase.config)
+			if !reflect.DeepEqual(inputCase.input, inputCase.expect) {
+				t.Fatal("Should be equal")
+			}
+		})
+	}
+}
+
+func TestMergeSharedTrafficPolicy(t *testing.T) {
+	oldest := &Ingress{
+		Meta: Meta{Namespace: "default", Name: "oldest"},
+		TrafficPolicy: &TrafficPolicyConfig{
+			MaxConnections: int32Ptr(10),
+		},
+		LoadBalance: &LoadBalanceConfig{
+			simple: networking.LoadBalancerSettings_LEAST_REQUEST,
+		},
+	}
+	newer := &Ingress{
+		Meta: Meta{Namespace: "default", Name: "newer"},
+		TrafficPolicy: &TrafficPolicyConfig{
+			MaxConnections: int32Ptr(100),
+			Consecutive5xx: uint32Ptr(3),
+		},
+		LoadBalance: &LoadBalanceConfig{
+			simple: networking.LoadBalancerSettings_RANDOM,
+		},
+	}
+
+	if got := MergeSharedTrafficPolicy(oldest, nil); got != oldest {
+		t.Fatal("Should return the config itself without shared configs")
+	}
+
+	merged := MergeSharedTrafficPolicy(oldest, []*Ingress{{}, newer})
+	expect := &TrafficPolicyConfig{
+		MaxConnections: int32Ptr(10),
+		Consecutive5xx: uint32Ptr(3),
+	}
+	if !reflect.DeepEqual(expect, merged.TrafficPolicy) {
+		t.Fatalf("Should be equal, expect %v, got %v", expect, merged.TrafficPolicy)
+	}
+	if merged.LoadBalance != oldest.LoadBalance {
+		t.Fatal("Load balance should be the one of the oldest ingress")
+	}
+	if *oldest.TrafficPolicy.MaxConnections != 10 || oldest.TrafficPolicy.Consecutive5xx != nil {
+		t.Fatal("The oldest config should not be modified")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"fmt"
+)
+
+// invalidAnnotationError tells which annotation value is invalid and why, it matches ErrInvalidAnnotationValue.
+type invalidAnnotationError struct {
+	name   string
+	value  string
+	reason string
+}
+
+func (e *invalidAnnotationError) Error() string {
+	return fmt.Sprintf("annotation %s has invalid value %q: %s", e.name, e.value, e.reason)
+}
+
+func (e *invalidAnnotationError) Is(target error) bool {
+	return target == ErrInvalidAnnotationValue
+}
+
+func invalidAnnotation(name, value, reason string) error {
+	return &invalidAnnotationError{name: name, value: value, reason: reason}
+}
+
+// invalidValue drops the error of a missing annotation, the parsers return the remaining ones to report the
+// invalid annotations they ignore.
+func invalidValue(err error) error {
+	if err == nil || IsMissingAnnotations(err) {
+		return nil
+	}
+	return err
+}
+
+// sourceKey returns the full name of the annotation key is read from, the nginx one takes precedence.
+func (a Annotations) sourceKey(key string) string {
+	if a.Has(key) {
+		return buildNginxAnnotationKey(key)
+	}
+	return buildHigressAnnotationKey(key)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	handler := NewAnnotationHandlerManager()
+	testCases := []struct {
+		name   string
+		input  Annotations
+		expect []string
+	}{
+		{
+			name: "valid",
+			input: Annotations{
+				buildNginxAnnotationKey(enableCanary):        "true",
+				buildNginxAnnotationKey(canaryWeight):        "20",
+				buildHigressAnnotationKey(timeoutAnnotation): "10",
+				buildHigressAnnotationKey(limitRPS):          "100",
+				buildNginxAnnotationKey(whitelist):           "1.1.1.1, 10.0.0.0/8",
+				buildNginxAnnotationKey(enableCors):          "true",
+			},
+		},
+		{
+			name: "canary disabled by invalid value",
+			input: Annotations{
+				buildNginxAnnotationKey(enableCanary): "yes",
+				buildNginxAnnotationKey(canaryWeight): "abc",
+			},
+			expect: []string{
+				`annotation nginx.ingress.kubernetes.io/canary has invalid value "yes": must be true or false`,
+			},
+		},
+		{
+			name: "canary weight",
+			input: Annotations{
+				buildNginxAnnotationKey(enableCanary):      "true",
+				buildNginxAnnotationKey(canaryWeight):      "abc",
+				buildNginxAnnotationKey(canaryWeightTotal): "0",
+			},
+			expect: []string{
+				`annotation nginx.ingress.kubernetes.io/canary-weight has invalid value "abc": must be an integer`,
+				`annotation nginx.ingress.kubernetes.io/canary-weight-total has invalid value "0": must be positive`,
+			},
+		},
+		{
+			name: "regex is passed through as the parser does",
+			input: Annotations{
+				buildNginxAnnotationKey(enableCanary):          "true",
+				buildNginxAnnotationKey(canaryByHeaderPattern): "^(?!internal).*$",
+			},
+		},
+		{
+			name: "local rate limit",
+			input: Annotations{
+				buildHigressAnnotationKey(limitRPM): "0",
+				buildHigressAnnotationKey(limitRPS): "abc",
+			},
+			expect: []string{
+				`annotation higress.io/route-limit-rpm has invalid value "0": must be positive`,
+			},
+		},
+		{
+			name: "local rate limit falls back to rps",
+			input: Annotations{
+				buildHigressAnnotationKey(limitRPM): "abc",
+				buildHigressAnnotationKey(limitRPS): "10",
+			},
+			expect: []string{
+				`annotation higress.io/route-limit-rpm has invalid value "abc": must be an integer`,
+			},
+		},
+		{
+			name: "rewrite",
+			input: Annotations{
+				buildNginxAnnotationKey(rewriteTarget):   "/$1",
+				buildHigressAnnotationKey(rewritePath):   "/foo",
+				buildHigressAnnotationKey(fullPathRegex): "on",
+			},
+			expect: []string{
+				`annotation higress.io/full-path-regex has invalid value "on": must be true or false`,
+			},
+		},
+		{
+			name: "rewrite target ignored by rewrite path is allowed",
+			input: Annotations{
+				buildNginxAnnotationKey(rewriteTarget): "/$1",
+				buildHigressAnnotationKey(rewritePath): "/foo",
+			},
+		},
+		{
+			name: "higress only annotation with nginx prefix is not validated",
+			input: Annotations{
+				buildNginxAnnotationKey(timeoutAnnotation): "abc",
+			},
+		},
+		{
+			name: "whitelist",
+			input: Annotations{
+				buildHigressAnnotationKey(whitelist): "1.1.1.1,1.1.1.300",
+			},
+			expect: []string{
+				`annotation higress.io/whitelist-source-range has invalid value "1.1.1.1,1.1.1.300": 1.1.1.300 is neither an IP nor a CIDR`,
+			},
+		},
+		{
+			name: "traffic policy",
+			input: Annotations{
+				buildHigressAnnotationKey(maxConnections):     "0",
+				buildHigressAnnotationKey(ejectionTime):       "abc",
+				buildHigressAnnotationKey(maxEjectionPercent): "120",
+			},
+			expect: []string{
+				`annotation higress.io/max-connections has invalid value "0": must be positive`,
+				`annotation higress.io/ejection-time has invalid value "abc": must be a duration such as 30s or an integer in seconds`,
+				`annotation higress.io/max-ejection-percent has invalid value "120": must be in the range [0, 100]`,
+			},
+		},
+		{
+			name: "redirect",
+			input: Annotations{
+				buildNginxAnnotationKey(permanentRedirect):     "ftp://foo.com",
+				buildNginxAnnotationKey(permanentRedirectCode): "200",
+			},
+			expect: []string{
+				`annotation nginx.ingress.kubernetes.io/permanent-redirect-code has invalid value "200": must be in the range [300, 308]`,
+				`annotation nginx.ingress.kubernetes.io/permanent-redirect has invalid value "ftp://foo.com": only http and https are valid protocols (ftp)`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := handler.Validate(testCase.input, &Ingress{Meta: Meta{Namespace: "default", Name: "foo"}})
+			if len(testCase.expect) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidAnnotationValue))
+			for _, expect := range testCase.expect {
+				assert.Contains(t, err.Error(), expect)
+			}
+			assert.Len(t, strings.Split(err.Error(), "\n"), len(testCase.expect))
+		})
+	}
+}
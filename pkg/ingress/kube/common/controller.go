@@ -115,6 +115,21 @@ type WrapperTrafficPolicy struct {
 	TrafficPolicy     *networking.TrafficPolicy
 	PortTrafficPolicy *networking.TrafficPolicy_PortTrafficPolicy
 	WrapperConfig     *WrapperConfig
+	// SharedConfigs are the newer ingresses using the same backend service, ordered by creation time.
+	SharedConfigs []*WrapperConfig
+}
+
+// AddSharedConfig records a newer ingress using the same backend service.
+func (w *WrapperTrafficPolicy) AddSharedConfig(wrapper *WrapperConfig) {
+	if wrapper == w.WrapperConfig {
+		return
+	}
+	for _, shared := range w.SharedConfigs {
+		if shared == wrapper {
+			return
+		}
+	}
+	w.SharedConfigs = append(w.SharedConfigs, wrapper)
 }
 
 type WrapperDestinationRule struct {
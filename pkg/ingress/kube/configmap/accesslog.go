@@ -0,0 +1,607 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
+	. "github.com/alibaba/higress/v2/pkg/ingress/log"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+const (
+	higressAccessLogEnvoyFilterName = "higress-config-access-log"
+
+	AccessLogFormatJSON = "json"
+	AccessLogFormatText = "text"
+
+	AccessLogSinkFile          = "file"
+	AccessLogSinkStdout        = "stdout"
+	AccessLogSinkOpenTelemetry = "opentelemetry"
+	AccessLogSinkGrpc          = "grpc"
+
+	defaultAccessLogName = "higress-gateway"
+)
+
+type accessLogField struct {
+	name     string
+	operator string
+}
+
+// accessLogBuiltinFields are the fields of the default access log format of the gateway
+var accessLogBuiltinFields = []accessLogField{
+	{"start_time", "%START_TIME%"},
+	{"authority", "%REQ(X-ENVOY-ORIGINAL-HOST?:AUTHORITY)%"},
+	{"method", "%REQ(:METHOD)%"},
+	{"path", "%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%"},
+	{"protocol", "%PROTOCOL%"},
+	{"response_code", "%RESPONSE_CODE%"},
+	{"response_flags", "%RESPONSE_FLAGS%"},
+	{"response_code_details", "%RESPONSE_CODE_DETAILS%"},
+	{"bytes_received", "%BYTES_RECEIVED%"},
+	{"bytes_sent", "%BYTES_SENT%"},
+	{"duration", "%DURATION%"},
+	{"upstream_service_time", "%RESP(X-ENVOY-UPSTREAM-SERVICE-TIME)%"},
+	{"x_forwarded_for", "%REQ(X-FORWARDED-FOR)%"},
+	{"user_agent", "%REQ(USER-AGENT)%"},
+	{"request_id", "%REQ(X-REQUEST-ID)%"},
+	{"trace_id", "%REQ(X-B3-TRACEID)%"},
+	{"route_name", "%ROUTE_NAME%"},
+	{"requested_server_name", "%REQUESTED_SERVER_NAME%"},
+	{"downstream_local_address", "%DOWNSTREAM_LOCAL_ADDRESS%"},
+	{"downstream_remote_address", "%DOWNSTREAM_REMOTE_ADDRESS%"},
+	{"upstream_cluster", "%UPSTREAM_CLUSTER%"},
+	{"upstream_host", "%UPSTREAM_HOST%"},
+	{"upstream_local_address", "%UPSTREAM_LOCAL_ADDRESS%"},
+	{"upstream_transport_failure_reason", "%UPSTREAM_TRANSPORT_FAILURE_REASON%"},
+	// ai_log holds the attributes of the AI plugins such as ai-statistics
+	{"ai_log", "%FILTER_STATE(wasm.ai_log:PLAIN)%"},
+}
+
+type AccessLog struct {
+	// Flag to control access log
+	Enable bool `json:"enable,omitempty"`
+	// Format is json or text. Default is json.
+	Format string `json:"format,omitempty"`
+	// Fields are the built-in fields to log, such as method, path, response_code and ai_log. Default is all of them.
+	Fields []string `json:"fields,omitempty"`
+	// CustomFields maps the extra field names to envoy command operators, e.g. user_id: "%REQ(X-USER-ID)%"
+	CustomFields map[string]string `json:"customFields,omitempty"`
+	// FilterStateFields are the filter state keys set by plugins, e.g. wasm.mcp_tool_name,
+	// each is logged as a field named by the key without the "wasm." prefix
+	FilterStateFields []string `json:"filterStateFields,omitempty"`
+	// TextFormat overrides the line of the text format, e.g. "[%START_TIME%] %REQ(:METHOD)% %RESPONSE_CODE%\n".
+	// Default is the selected fields as key=value pairs.
+	TextFormat string `json:"textFormat,omitempty"`
+	// The percentage of requests (0.0 - 100.0) that will be randomly selected to log. Default is 100.0.
+	Sampling float64 `json:"sampling,omitempty"`
+	// Filter selects the requests to log by status code and route
+	Filter *AccessLogFilter `json:"filter,omitempty"`
+	// Sinks are where the access logs are written
+	Sinks []*AccessLogSink `json:"sinks,omitempty"`
+}
+
+type AccessLogFilter struct {
+	// Only log the responses with a status code no less than MinStatusCode, e.g. 400
+	MinStatusCode int32 `json:"minStatusCode,omitempty"`
+	// Only log the responses with a status code no greater than MaxStatusCode, e.g. 599
+	MaxStatusCode int32 `json:"maxStatusCode,omitempty"`
+	// Only log the requests matching one of the route names
+	Routes []string `json:"routes,omitempty"`
+}
+
+type AccessLogSink struct {
+	// Type is one of file, stdout, opentelemetry and grpc
+	Type string `json:"type,omitempty"`
+	// Path of the log file, only for the file sink
+	Path string `json:"path,omitempty"`
+	// Address of the OpenTelemetry collector or gRPC access log service
+	Service string `json:"service,omitempty"`
+	Port    string `json:"port,omitempty"`
+	// LogName identifies the logs sent to the collector or service. Default is higress-gateway.
+	LogName string `json:"logName,omitempty"`
+}
+
+func validAccessLog(a *AccessLog) error {
+	if a == nil {
+		return nil
+	}
+
+	if a.Format != AccessLogFormatJSON && a.Format != AccessLogFormatText {
+		return fmt.Errorf("format need be one of %s and %s", AccessLogFormatJSON, AccessLogFormatText)
+	}
+
+	for _, field := range a.Fields {
+		if builtinAccessLogOperator(field) == "" {
+			return fmt.Errorf("field %s is not a built-in field", field)
+		}
+	}
+
+	for name, operator := range a.CustomFields {
+		if len(name) == 0 || len(operator) == 0 {
+			return errors.New("custom field name and operator can not be empty")
+		}
+	}
+
+	for _, key := range a.FilterStateFields {
+		if len(key) == 0 {
+			return errors.New("filter state field can not be empty")
+		}
+	}
+
+	if a.Sampling < 0 || a.Sampling > 100 {
+		return errors.New("sampling must be in (0.0 - 100.0)")
+	}
+
+	if f := a.Filter; f != nil {
+		if f.MinStatusCode != 0 && (f.MinStatusCode < 100 || f.MinStatusCode > 599) {
+			return errors.New("minStatusCode need be between 100 and 599")
+		}
+		if f.MaxStatusCode != 0 && (f.MaxStatusCode < 100 || f.MaxStatusCode > 599) {
+			return errors.New("maxStatusCode need be between 100 and 599")
+		}
+		if f.MinStatusCode != 0 && f.MaxStatusCode != 0 && f.MinStatusCode > f.MaxStatusCode {
+			return errors.New("minStatusCode can not be greater than maxStatusCode")
+		}
+		for _, route := range f.Routes {
+			if len(route) == 0 || strings.ContainsAny(route, `'\`) {
+				return fmt.Errorf("route %q is invalid", route)
+			}
+		}
+	}
+
+	if a.Enable && len(a.Sinks) == 0 {
+		return errors.New("sinks can not be empty when access log is enabled")
+	}
+
+	for _, sink := range a.Sinks {
+		if sink == nil {
+			return errors.New("sink can not be empty")
+		}
+		switch sink.Type {
+		case AccessLogSinkFile:
+			if len(sink.Path) == 0 {
+				return errors.New("file sink path can not be empty")
+			}
+		case AccessLogSinkStdout:
+		case AccessLogSinkOpenTelemetry, AccessLogSinkGrpc:
+			if !validServiceAndPort(sink.Service, sink.Port) {
+				return fmt.Errorf("%s sink service and port can not be empty", sink.Type)
+			}
+		default:
+			return fmt.Errorf("sink type need be one of %s, %s, %s and %s", AccessLogSinkFile, AccessLogSinkStdout, AccessLogSinkOpenTelemetry, AccessLogSinkGrpc)
+		}
+	}
+
+	return nil
+}
+
+func builtinAccessLogOperator(name string) string {
+	for _, field := range accessLogBuiltinFields {
+		if field.name == name {
+			return field.operator
+		}
+	}
+	return ""
+}
+
+func compareAccessLog(old *AccessLog, new *AccessLog) (Result, error) {
+	if old == nil && new == nil {
+		return ResultNothing, nil
+	}
+
+	if new == nil {
+		return ResultDelete, nil
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		return ResultReplace, nil
+	}
+
+	return ResultNothing, nil
+}
+
+func deepCopyAccessLog(accessLog *AccessLog) (*AccessLog, error) {
+	newAccessLog := NewDefaultAccessLog()
+	bytes, err := json.Marshal(accessLog)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bytes, newAccessLog)
+	return newAccessLog, err
+}
+
+func NewDefaultAccessLog() *AccessLog {
+	accessLog := &AccessLog{
+		Enable:   false,
+		Format:   AccessLogFormatJSON,
+		Sampling: defaultSampling,
+	}
+	return accessLog
+}
+
+type AccessLogController struct {
+	Namespace    string
+	accessLog    atomic.Value
+	Name         string
+	eventHandler ItemEventHandler
+}
+
+func NewAccessLogController(namespace string) *AccessLogController {
+	accessLogController := &AccessLogController{
+		Namespace: namespace,
+		accessLog: atomic.Value{},
+		Name:      "accessLog",
+	}
+	accessLogController.SetAccessLog(NewDefaultAccessLog())
+	return accessLogController
+}
+
+func (a *AccessLogController) SetAccessLog(accessLog *AccessLog) {
+	a.accessLog.Store(accessLog)
+}
+
+func (a *AccessLogController) GetAccessLog() *AccessLog {
+	value := a.accessLog.Load()
+	if value != nil {
+		if accessLog, ok := value.(*AccessLog); ok {
+			return accessLog
+		}
+	}
+	return nil
+}
+
+func (a *AccessLogController) GetName() string {
+	return a.Name
+}
+
+func (a *AccessLogController) AddOrUpdateHigressConfig(name util.ClusterNamespacedName, old *HigressConfig, new *HigressConfig) error {
+	if err := validAccessLog(new.AccessLog); err != nil {
+		IngressLog.Errorf("data:%+v convert to access log, error: %+v", new.AccessLog, err)
+		return nil
+	}
+
+	result, _ := compareAccessLog(old.AccessLog, new.AccessLog)
+
+	switch result {
+	case ResultReplace:
+		if newAccessLog, err := deepCopyAccessLog(new.AccessLog); err != nil {
+			IngressLog.Infof("access log deepcopy error:%v", err)
+		} else {
+			a.SetAccessLog(newAccessLog)
+			IngressLog.Infof("AddOrUpdate Higress config access log")
+			a.eventHandler(higressAccessLogEnvoyFilterName)
+			IngressLog.Infof("send event with filter name:%s", higressAccessLogEnvoyFilterName)
+		}
+	case ResultDelete:
+		a.SetAccessLog(NewDefaultAccessLog())
+		IngressLog.Infof("Delete Higress config access log")
+		a.eventHandler(higressAccessLogEnvoyFilterName)
+		IngressLog.Infof("send event with filter name:%s", higressAccessLogEnvoyFilterName)
+	}
+
+	return nil
+}
+
+func (a *AccessLogController) ValidHigressConfig(higressConfig *HigressConfig) error {
+	if higressConfig == nil {
+		return nil
+	}
+	if higressConfig.AccessLog == nil {
+		return nil
+	}
+
+	return validAccessLog(higressConfig.AccessLog)
+}
+
+func (a *AccessLogController) RegisterItemEventHandler(eventHandler ItemEventHandler) {
+	a.eventHandler = eventHandler
+}
+
+// ConstructEnvoyFilters appends the access loggers to the http connection managers of the gateway,
+// they work alongside the access log of the mesh config
+func (a *AccessLogController) ConstructEnvoyFilters() ([]*config.Config, error) {
+	configs := make([]*config.Config, 0)
+	accessLog := a.GetAccessLog()
+	namespace := a.Namespace
+
+	if accessLog == nil {
+		return configs, nil
+	}
+
+	if accessLog.Enable == false {
+		return configs, nil
+	}
+
+	accessLogConfig, err := a.constructAccessLogStruct(accessLog)
+	if err != nil {
+		return configs, err
+	}
+
+	configPatches := []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: networking.EnvoyFilter_NETWORK_FILTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+					Listener: &networking.EnvoyFilter_ListenerMatch{
+						FilterChain: &networking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+							Filter: &networking.EnvoyFilter_ListenerMatch_FilterMatch{
+								Name: "envoy.filters.network.http_connection_manager",
+							},
+						},
+					},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_MERGE,
+				Value:     util.BuildPatchStruct(accessLogConfig),
+			},
+		},
+	}
+
+	clusters := make(map[string]struct{})
+	for _, sink := range accessLog.Sinks {
+		if sink.Type != AccessLogSinkOpenTelemetry && sink.Type != AccessLogSinkGrpc {
+			continue
+		}
+		clusterName := tracingClusterName(sink.Port, sink.Service)
+		if _, ok := clusters[clusterName]; ok {
+			continue
+		}
+		clusters[clusterName] = struct{}{}
+		configPatches = append(configPatches, constructHTTP2ProtocolOptionsPatch(sink.Port, sink.Service))
+	}
+
+	config := &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.EnvoyFilter,
+			Name:             higressAccessLogEnvoyFilterName,
+			Namespace:        namespace,
+		},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: configPatches,
+		},
+	}
+
+	configs = append(configs, config)
+	return configs, nil
+}
+
+// accessLogFields returns the fields to log in order, the built-in fields come first
+func accessLogFields(accessLog *AccessLog) []accessLogField {
+	fields := make([]accessLogField, 0)
+	if len(accessLog.Fields) == 0 {
+		fields = append(fields, accessLogBuiltinFields...)
+	} else {
+		for _, name := range accessLog.Fields {
+			fields = append(fields, accessLogField{name, builtinAccessLogOperator(name)})
+		}
+	}
+	for _, key := range accessLog.FilterStateFields {
+		fields = append(fields, accessLogField{strings.TrimPrefix(key, "wasm."), fmt.Sprintf("%%FILTER_STATE(%s:PLAIN)%%", key)})
+	}
+	customNames := make([]string, 0, len(accessLog.CustomFields))
+	for name := range accessLog.CustomFields {
+		customNames = append(customNames, name)
+	}
+	sort.Strings(customNames)
+	for _, name := range customNames {
+		fields = append(fields, accessLogField{name, accessLog.CustomFields[name]})
+	}
+	return fields
+}
+
+func (a *AccessLogController) constructLogFormat(accessLog *AccessLog) map[string]interface{} {
+	fields := accessLogFields(accessLog)
+	if accessLog.Format == AccessLogFormatText {
+		return map[string]interface{}{
+			"text_format_source": map[string]interface{}{
+				"inline_string": a.constructTextFormat(accessLog, fields),
+			},
+		}
+	}
+	jsonFormat := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		jsonFormat[field.name] = field.operator
+	}
+	return map[string]interface{}{
+		"json_format": jsonFormat,
+	}
+}
+
+func (a *AccessLogController) constructTextFormat(accessLog *AccessLog, fields []accessLogField) string {
+	if len(accessLog.TextFormat) > 0 {
+		return accessLog.TextFormat
+	}
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, field.name, field.operator))
+	}
+	return strings.Join(pairs, " ") + "\n"
+}
+
+func (a *AccessLogController) constructAccessLogFilter(accessLog *AccessLog) map[string]interface{} {
+	filters := make([]interface{}, 0)
+	if accessLog.Sampling < 100 {
+		filters = append(filters, map[string]interface{}{
+			"runtime_filter": map[string]interface{}{
+				"runtime_key": "higress.access_log.sampling",
+				"percent_sampled": map[string]interface{}{
+					"numerator":   int(accessLog.Sampling * 100),
+					"denominator": "TEN_THOUSAND",
+				},
+				"use_independent_randomness": true,
+			},
+		})
+	}
+	if f := accessLog.Filter; f != nil {
+		if f.MinStatusCode > 0 {
+			filters = append(filters, statusCodeFilter("GE", f.MinStatusCode, "higress.access_log.min_status_code"))
+		}
+		if f.MaxStatusCode > 0 {
+			filters = append(filters, statusCodeFilter("LE", f.MaxStatusCode, "higress.access_log.max_status_code"))
+		}
+		if len(f.Routes) > 0 {
+			conditions := make([]string, 0, len(f.Routes))
+			for _, route := range f.Routes {
+				conditions = append(conditions, fmt.Sprintf("xds.route_name == '%s'", route))
+			}
+			filters = append(filters, map[string]interface{}{
+				"extension_filter": map[string]interface{}{
+					"name": "envoy.access_loggers.extension_filters.cel",
+					"typed_config": map[string]interface{}{
+						"@type":      "type.googleapis.com/envoy.extensions.access_loggers.filters.cel.v3.ExpressionFilter",
+						"expression": strings.Join(conditions, " || "),
+					},
+				},
+			})
+		}
+	}
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0].(map[string]interface{})
+	}
+	return map[string]interface{}{
+		"and_filter": map[string]interface{}{
+			"filters": filters,
+		},
+	}
+}
+
+func statusCodeFilter(op string, value int32, runtimeKey string) map[string]interface{} {
+	return map[string]interface{}{
+		"status_code_filter": map[string]interface{}{
+			"comparison": map[string]interface{}{
+				"op": op,
+				"value": map[string]interface{}{
+					"default_value": value,
+					"runtime_key":   runtimeKey,
+				},
+			},
+		},
+	}
+}
+
+func (a *AccessLogController) constructAccessLogger(accessLog *AccessLog, sink *AccessLogSink) map[string]interface{} {
+	logName := sink.LogName
+	if len(logName) == 0 {
+		logName = defaultAccessLogName
+	}
+	commonConfig := map[string]interface{}{
+		"log_name":              logName,
+		"transport_api_version": "V3",
+		"grpc_service": map[string]interface{}{
+			"envoy_grpc": map[string]interface{}{
+				"cluster_name": tracingClusterName(sink.Port, sink.Service),
+			},
+		},
+	}
+	switch sink.Type {
+	case AccessLogSinkFile:
+		return map[string]interface{}{
+			"name": "envoy.access_loggers.file",
+			"typed_config": map[string]interface{}{
+				"@type":      "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+				"path":       sink.Path,
+				"log_format": a.constructLogFormat(accessLog),
+			},
+		}
+	case AccessLogSinkStdout:
+		return map[string]interface{}{
+			"name": "envoy.access_loggers.stdout",
+			"typed_config": map[string]interface{}{
+				"@type":      "type.googleapis.com/envoy.extensions.access_loggers.stream.v3.StdoutAccessLog",
+				"log_format": a.constructLogFormat(accessLog),
+			},
+		}
+	case AccessLogSinkOpenTelemetry:
+		otelConfig := map[string]interface{}{
+			"@type":         "type.googleapis.com/envoy.extensions.access_loggers.open_telemetry.v3.OpenTelemetryAccessLogConfig",
+			"common_config": commonConfig,
+		}
+		fields := accessLogFields(accessLog)
+		if accessLog.Format == AccessLogFormatText {
+			otelConfig["body"] = map[string]interface{}{
+				"string_value": a.constructTextFormat(accessLog, fields),
+			}
+		} else {
+			values := make([]interface{}, 0, len(fields))
+			for _, field := range fields {
+				values = append(values, map[string]interface{}{
+					"key":   field.name,
+					"value": map[string]interface{}{"string_value": field.operator},
+				})
+			}
+			otelConfig["attributes"] = map[string]interface{}{
+				"values": values,
+			}
+		}
+		return map[string]interface{}{
+			"name":         "envoy.access_loggers.open_telemetry",
+			"typed_config": otelConfig,
+		}
+	case AccessLogSinkGrpc:
+		// the gRPC access log service receives structured entries, so only the filter states are configured
+		filterStateKeys := append([]string{"wasm.ai_log"}, accessLog.FilterStateFields...)
+		commonConfig["filter_state_objects_to_log"] = filterStateKeys
+		return map[string]interface{}{
+			"name": "envoy.access_loggers.http_grpc",
+			"typed_config": map[string]interface{}{
+				"@type":         "type.googleapis.com/envoy.extensions.access_loggers.grpc.v3.HttpGrpcAccessLogConfig",
+				"common_config": commonConfig,
+			},
+		}
+	}
+	return nil
+}
+
+func (a *AccessLogController) constructAccessLogStruct(accessLog *AccessLog) (string, error) {
+	filter := a.constructAccessLogFilter(accessLog)
+	accessLoggers := make([]interface{}, 0, len(accessLog.Sinks))
+	for _, sink := range accessLog.Sinks {
+		accessLogger := a.constructAccessLogger(accessLog, sink)
+		if accessLogger == nil {
+			continue
+		}
+		if filter != nil {
+			accessLogger["filter"] = filter
+		}
+		accessLoggers = append(accessLoggers, accessLogger)
+	}
+	accessLogConfig := map[string]interface{}{
+		"name": "envoy.filters.network.http_connection_manager",
+		"typed_config": map[string]interface{}{
+			"@type":      "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+			"access_log": accessLoggers,
+		},
+	}
+	bytes, err := json.Marshal(accessLogConfig)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
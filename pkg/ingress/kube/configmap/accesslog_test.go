@@ -0,0 +1,440 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validAccessLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		accessLog *AccessLog
+		wantErr   error
+	}{
+		{
+			name:      "nil",
+			accessLog: nil,
+			wantErr:   nil,
+		},
+		{
+			name:      "default",
+			accessLog: NewDefaultAccessLog(),
+			wantErr:   nil,
+		},
+		{
+			name: "enable without sinks",
+			accessLog: &AccessLog{
+				Enable:   true,
+				Format:   AccessLogFormatJSON,
+				Sampling: 100,
+			},
+			wantErr: errors.New("sinks can not be empty when access log is enabled"),
+		},
+		{
+			name: "unknown format",
+			accessLog: &AccessLog{
+				Format: "xml",
+			},
+			wantErr: errors.New("format need be one of json and text"),
+		},
+		{
+			name: "unknown field",
+			accessLog: &AccessLog{
+				Format: AccessLogFormatJSON,
+				Fields: []string{"method", "foo"},
+			},
+			wantErr: errors.New("field foo is not a built-in field"),
+		},
+		{
+			name: "sampling out of range",
+			accessLog: &AccessLog{
+				Format:   AccessLogFormatJSON,
+				Sampling: 101,
+			},
+			wantErr: errors.New("sampling must be in (0.0 - 100.0)"),
+		},
+		{
+			name: "invalid status code range",
+			accessLog: &AccessLog{
+				Format: AccessLogFormatJSON,
+				Filter: &AccessLogFilter{
+					MinStatusCode: 500,
+					MaxStatusCode: 400,
+				},
+			},
+			wantErr: errors.New("minStatusCode can not be greater than maxStatusCode"),
+		},
+		{
+			name: "file sink without path",
+			accessLog: &AccessLog{
+				Enable: true,
+				Format: AccessLogFormatJSON,
+				Sinks: []*AccessLogSink{
+					{Type: AccessLogSinkFile},
+				},
+			},
+			wantErr: errors.New("file sink path can not be empty"),
+		},
+		{
+			name: "opentelemetry sink without service",
+			accessLog: &AccessLog{
+				Enable: true,
+				Format: AccessLogFormatJSON,
+				Sinks: []*AccessLogSink{
+					{Type: AccessLogSinkOpenTelemetry, Port: "4317"},
+				},
+			},
+			wantErr: errors.New("opentelemetry sink service and port can not be empty"),
+		},
+		{
+			name: "unknown sink",
+			accessLog: &AccessLog{
+				Enable: true,
+				Format: AccessLogFormatJSON,
+				Sinks: []*AccessLogSink{
+					{Type: "kafka"},
+				},
+			},
+			wantErr: errors.New("sink type need be one of file, stdout, opentelemetry and grpc"),
+		},
+		{
+			name: "valid",
+			accessLog: &AccessLog{
+				Enable:            true,
+				Format:            AccessLogFormatText,
+				Fields:            []string{"method", "path", "response_code", "ai_log"},
+				FilterStateFields: []string{"wasm.mcp_tool_name"},
+				Sampling:          10,
+				Filter: &AccessLogFilter{
+					MinStatusCode: 400,
+					Routes:        []string{"ai-route"},
+				},
+				Sinks: []*AccessLogSink{
+					{Type: AccessLogSinkStdout},
+					{Type: AccessLogSinkFile, Path: "/var/log/higress/access.log"},
+					{Type: AccessLogSinkGrpc, Service: "als.higress-system.svc.cluster.local", Port: "9001"},
+				},
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validAccessLog(tt.accessLog)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func Test_compareAccessLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		old        *AccessLog
+		new        *AccessLog
+		wantResult Result
+	}{
+		{
+			name:       "compare both nil",
+			old:        nil,
+			new:        nil,
+			wantResult: ResultNothing,
+		},
+		{
+			name:       "compare result delete",
+			old:        NewDefaultAccessLog(),
+			new:        nil,
+			wantResult: ResultDelete,
+		},
+		{
+			name:       "compare result equal",
+			old:        NewDefaultAccessLog(),
+			new:        NewDefaultAccessLog(),
+			wantResult: ResultNothing,
+		},
+		{
+			name: "compare result replace",
+			old:  NewDefaultAccessLog(),
+			new: &AccessLog{
+				Enable:   true,
+				Format:   AccessLogFormatJSON,
+				Sampling: 100,
+				Sinks: []*AccessLogSink{
+					{Type: AccessLogSinkStdout},
+				},
+			},
+			wantResult: ResultReplace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := compareAccessLog(tt.old, tt.new)
+			assert.Equal(t, tt.wantResult, result)
+		})
+	}
+}
+
+func Test_deepCopyAccessLog(t *testing.T) {
+	accessLog := &AccessLog{
+		Enable:       true,
+		Format:       AccessLogFormatJSON,
+		Fields:       []string{"method"},
+		CustomFields: map[string]string{"user_id": "%REQ(X-USER-ID)%"},
+		Sampling:     50,
+		Filter: &AccessLogFilter{
+			MinStatusCode: 500,
+		},
+		Sinks: []*AccessLogSink{
+			{Type: AccessLogSinkStdout},
+		},
+	}
+
+	newAccessLog, err := deepCopyAccessLog(accessLog)
+	require.NoError(t, err)
+	assert.Equal(t, accessLog, newAccessLog)
+
+	newAccessLog.Sinks[0].Type = AccessLogSinkFile
+	newAccessLog.CustomFields["user_id"] = "%REQ(X-USER)%"
+	assert.Equal(t, AccessLogSinkStdout, accessLog.Sinks[0].Type)
+	assert.Equal(t, "%REQ(X-USER-ID)%", accessLog.CustomFields["user_id"])
+}
+
+func TestAccessLogController_AddOrUpdateHigressConfig(t *testing.T) {
+	eventPush := "default"
+	defaultHandler := func(name string) {
+		eventPush = "push"
+	}
+
+	defaultName := util.ClusterNamespacedName{}
+	enabledAccessLog := &AccessLog{
+		Enable:   true,
+		Format:   AccessLogFormatJSON,
+		Sampling: 100,
+		Sinks: []*AccessLogSink{
+			{Type: AccessLogSinkStdout},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		old           *HigressConfig
+		new           *HigressConfig
+		wantEventPush string
+		wantAccessLog *AccessLog
+	}{
+		{
+			name: "default",
+			old: &HigressConfig{
+				AccessLog: NewDefaultAccessLog(),
+			},
+			new: &HigressConfig{
+				AccessLog: NewDefaultAccessLog(),
+			},
+			wantEventPush: "default",
+			wantAccessLog: NewDefaultAccessLog(),
+		},
+		{
+			name: "replace and push",
+			old: &HigressConfig{
+				AccessLog: NewDefaultAccessLog(),
+			},
+			new: &HigressConfig{
+				AccessLog: enabledAccessLog,
+			},
+			wantEventPush: "push",
+			wantAccessLog: enabledAccessLog,
+		},
+		{
+			name: "invalid and ignored",
+			old: &HigressConfig{
+				AccessLog: NewDefaultAccessLog(),
+			},
+			new: &HigressConfig{
+				AccessLog: &AccessLog{
+					Enable: true,
+					Format: AccessLogFormatJSON,
+				},
+			},
+			wantEventPush: "default",
+			wantAccessLog: NewDefaultAccessLog(),
+		},
+		{
+			name: "delete and push",
+			old: &HigressConfig{
+				AccessLog: enabledAccessLog,
+			},
+			new: &HigressConfig{
+				AccessLog: nil,
+			},
+			wantEventPush: "push",
+			wantAccessLog: NewDefaultAccessLog(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAccessLogController("higress-system")
+			a.eventHandler = defaultHandler
+			eventPush = "default"
+			err := a.AddOrUpdateHigressConfig(defaultName, tt.old, tt.new)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEventPush, eventPush)
+			assert.Equal(t, tt.wantAccessLog, a.GetAccessLog())
+		})
+	}
+}
+
+func TestAccessLogController_ConstructEnvoyFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessLog   *AccessLog
+		wantConfigs int
+		wantPatches int
+	}{
+		{
+			name:        "disabled",
+			accessLog:   NewDefaultAccessLog(),
+			wantConfigs: 0,
+		},
+		{
+			name: "stdout",
+			accessLog: &AccessLog{
+				Enable:   true,
+				Format:   AccessLogFormatJSON,
+				Sampling: 100,
+				Sinks: []*AccessLogSink{
+					{Type: AccessLogSinkStdout},
+				},
+			},
+			wantConfigs: 1,
+			wantPatches: 1,
+		},
+		{
+			name: "grpc sinks share the cluster patch",
+			accessLog: &AccessLog{
+				Enable:   true,
+				Format:   AccessLogFormatJSON,
+				Sampling: 100,
+				Sinks: []*AccessLogSink{
+					{Type: AccessLogSinkOpenTelemetry, Service: "otel-collector.higress-system.svc.cluster.local", Port: "4317"},
+					{Type: AccessLogSinkGrpc, Service: "otel-collector.higress-system.svc.cluster.local", Port: "4317"},
+					{Type: AccessLogSinkGrpc, Service: "als.higress-system.svc.cluster.local", Port: "9001"},
+				},
+			},
+			wantConfigs: 1,
+			wantPatches: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAccessLogController("higress-system")
+			a.SetAccessLog(tt.accessLog)
+			configs, err := a.ConstructEnvoyFilters()
+			require.NoError(t, err)
+			require.Len(t, configs, tt.wantConfigs)
+			if tt.wantConfigs == 0 {
+				return
+			}
+			assert.Equal(t, higressAccessLogEnvoyFilterName, configs[0].Name)
+			envoyFilter := configs[0].Spec.(*networking.EnvoyFilter)
+			assert.Len(t, envoyFilter.ConfigPatches, tt.wantPatches)
+		})
+	}
+}
+
+func TestAccessLogController_constructAccessLogStruct(t *testing.T) {
+	a := NewAccessLogController("higress-system")
+	accessLog := &AccessLog{
+		Enable:            true,
+		Format:            AccessLogFormatJSON,
+		Fields:            []string{"method", "response_code", "ai_log"},
+		FilterStateFields: []string{"wasm.mcp_tool_name"},
+		CustomFields:      map[string]string{"user_id": "%REQ(X-USER-ID)%"},
+		Sampling:          10,
+		Filter: &AccessLogFilter{
+			MinStatusCode: 400,
+			Routes:        []string{"ai-route", "mcp-route"},
+		},
+		Sinks: []*AccessLogSink{
+			{Type: AccessLogSinkFile, Path: "/var/log/higress/access.log"},
+		},
+	}
+
+	accessLogStruct, err := a.constructAccessLogStruct(accessLog)
+	require.NoError(t, err)
+
+	var hcm struct {
+		TypedConfig struct {
+			AccessLog []struct {
+				Name        string `json:"name"`
+				TypedConfig struct {
+					Path      string `json:"path"`
+					LogFormat struct {
+						JsonFormat map[string]string `json:"json_format"`
+					} `json:"log_format"`
+				} `json:"typed_config"`
+				Filter struct {
+					AndFilter struct {
+						Filters []map[string]interface{} `json:"filters"`
+					} `json:"and_filter"`
+				} `json:"filter"`
+			} `json:"access_log"`
+		} `json:"typed_config"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(accessLogStruct), &hcm))
+	require.Len(t, hcm.TypedConfig.AccessLog, 1)
+
+	fileLog := hcm.TypedConfig.AccessLog[0]
+	assert.Equal(t, "envoy.access_loggers.file", fileLog.Name)
+	assert.Equal(t, "/var/log/higress/access.log", fileLog.TypedConfig.Path)
+	assert.Equal(t, map[string]string{
+		"method":        "%REQ(:METHOD)%",
+		"response_code": "%RESPONSE_CODE%",
+		"ai_log":        "%FILTER_STATE(wasm.ai_log:PLAIN)%",
+		"mcp_tool_name": "%FILTER_STATE(wasm.mcp_tool_name:PLAIN)%",
+		"user_id":       "%REQ(X-USER-ID)%",
+	}, fileLog.TypedConfig.LogFormat.JsonFormat)
+
+	filters := fileLog.Filter.AndFilter.Filters
+	require.Len(t, filters, 3)
+	assert.Contains(t, filters[0], "runtime_filter")
+	assert.Contains(t, filters[1], "status_code_filter")
+	assert.Equal(t, "xds.route_name == 'ai-route' || xds.route_name == 'mcp-route'",
+		filters[2]["extension_filter"].(map[string]interface{})["typed_config"].(map[string]interface{})["expression"])
+}
+
+func TestAccessLogController_constructTextFormat(t *testing.T) {
+	a := NewAccessLogController("higress-system")
+	accessLog := &AccessLog{
+		Format: AccessLogFormatText,
+		Fields: []string{"method", "response_code"},
+	}
+	assert.Equal(t, "method=\"%REQ(:METHOD)%\" response_code=\"%RESPONSE_CODE%\"\n",
+		a.constructTextFormat(accessLog, accessLogFields(accessLog)))
+
+	accessLog.TextFormat = "%REQ(:METHOD)% %RESPONSE_CODE%\n"
+	assert.Equal(t, accessLog.TextFormat, a.constructTextFormat(accessLog, accessLogFields(accessLog)))
+}
@@ -0,0 +1,418 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
+	. "github.com/alibaba/higress/v2/pkg/ingress/log"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+const (
+	higressCompressionEnvoyFilterName = "higress-config-compression"
+
+	CompressionAlgorithmGzip   = "gzip"
+	CompressionAlgorithmBrotli = "br"
+	CompressionAlgorithmZstd   = "zstd"
+
+	// Names of the compressor filters, routes refer to them in typed_per_filter_config
+	GzipCompressorFilterName   = "envoy.filters.http.compressor"
+	BrotliCompressorFilterName = "envoy.filters.http.compressor.brotli"
+	ZstdCompressorFilterName   = "envoy.filters.http.compressor.zstd"
+
+	brotliEncoderModeValues = "DEFAULT,GENERIC,TEXT,FONT"
+	zstdStrategyValues      = "DEFAULT,FAST,DFAST,GREEDY,LAZY,LAZY2,BTLAZY2,BTOPT,BTULTRA,BTULTRA2"
+)
+
+// CompressorFilterNames maps the compression algorithms to their compressor filters,
+// gzip is configured by the gzip item and the others by the compression item.
+var CompressorFilterNames = map[string]string{
+	CompressionAlgorithmGzip:   GzipCompressorFilterName,
+	CompressionAlgorithmBrotli: BrotliCompressorFilterName,
+	CompressionAlgorithmZstd:   ZstdCompressorFilterName,
+}
+
+type Compression struct {
+	// Flag to control brotli and zstd compression, gzip is still controlled by the gzip item
+	Enable              bool     `json:"enable,omitempty"`
+	MinContentLength    int32    `json:"minContentLength,omitempty"`
+	ContentType         []string `json:"contentType,omitempty"`
+	DisableOnEtagHeader bool     `json:"disableOnEtagHeader,omitempty"`
+	// The algorithm chosen when the q-values of Accept-Encoding are the same, br or zstd. The default is br.
+	Preferred string  `json:"preferred,omitempty"`
+	Brotli    *Brotli `json:"brotli,omitempty"`
+	Zstd      *Zstd   `json:"zstd,omitempty"`
+}
+
+type Brotli struct {
+	Enable bool `json:"enable,omitempty"`
+	// Value from 0 to 11 that controls the compression level. The default value is 4.
+	Quality int32 `json:"quality,omitempty"`
+	// Value from 10 to 24 that represents the base two logarithmic of the compressor’s window size.
+	// The default is 18.
+	WindowBits int32 `json:"windowBits,omitempty"`
+	// Value is one of DEFAULT, GENERIC, TEXT, FONT
+	EncoderMode string `json:"encoderMode,omitempty"`
+	// Value for compressor’s next output buffer. If not set, defaults to 4096.
+	ChunkSize int32 `json:"chunkSize,omitempty"`
+}
+
+type Zstd struct {
+	Enable bool `json:"enable,omitempty"`
+	// Value from 1 to 22 that controls the compression level. The default value is 3.
+	CompressionLevel int32 `json:"compressionLevel,omitempty"`
+	// Value is one of DEFAULT, FAST, DFAST, GREEDY, LAZY, LAZY2, BTLAZY2, BTOPT, BTULTRA, BTULTRA2
+	Strategy       string `json:"strategy,omitempty"`
+	EnableChecksum bool   `json:"enableChecksum,omitempty"`
+	// Value for compressor’s next output buffer. If not set, defaults to 4096.
+	ChunkSize int32 `json:"chunkSize,omitempty"`
+}
+
+func validCompression(c *Compression) error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MinContentLength <= 0 {
+		return errors.New("minContentLength can not be less than zero")
+	}
+
+	if len(c.ContentType) == 0 {
+		return errors.New("content type can not be empty")
+	}
+
+	if c.Preferred != CompressionAlgorithmBrotli && c.Preferred != CompressionAlgorithmZstd {
+		return fmt.Errorf("preferred need be one of %s and %s", CompressionAlgorithmBrotli, CompressionAlgorithmZstd)
+	}
+
+	if b := c.Brotli; b != nil {
+		if !(b.Quality >= 0 && b.Quality <= 11) {
+			return errors.New("brotli quality need be between 0 and 11")
+		}
+		if !(b.WindowBits >= 10 && b.WindowBits <= 24) {
+			return errors.New("brotli window bits need be between 10 and 24")
+		}
+		if b.ChunkSize <= 0 {
+			return errors.New("brotli chunk size need be large than zero")
+		}
+		if !containsValue(brotliEncoderModeValues, b.EncoderMode) {
+			return fmt.Errorf("brotli encoderMode need be one of %s", brotliEncoderModeValues)
+		}
+	}
+
+	if z := c.Zstd; z != nil {
+		if !(z.CompressionLevel >= 1 && z.CompressionLevel <= 22) {
+			return errors.New("zstd compression level need be between 1 and 22")
+		}
+		if z.ChunkSize <= 0 {
+			return errors.New("zstd chunk size need be large than zero")
+		}
+		if !containsValue(zstdStrategyValues, z.Strategy) {
+			return fmt.Errorf("zstd strategy need be one of %s", zstdStrategyValues)
+		}
+	}
+
+	return nil
+}
+
+func containsValue(values string, value string) bool {
+	for _, v := range strings.Split(values, ",") {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func compareCompression(old *Compression, new *Compression) (Result, error) {
+	if old == nil && new == nil {
+		return ResultNothing, nil
+	}
+
+	if new == nil {
+		return ResultDelete, nil
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		return ResultReplace, nil
+	}
+
+	return ResultNothing, nil
+}
+
+func deepCopyCompression(compression *Compression) (*Compression, error) {
+	newCompression := NewDefaultCompression()
+	newCompression.Enable = compression.Enable
+	newCompression.MinContentLength = compression.MinContentLength
+	newCompression.ContentType = make([]string, 0, len(compression.ContentType))
+	newCompression.ContentType = append(newCompression.ContentType, compression.ContentType...)
+	newCompression.DisableOnEtagHeader = compression.DisableOnEtagHeader
+	newCompression.Preferred = compression.Preferred
+	newCompression.Brotli = nil
+	if compression.Brotli != nil {
+		brotli := *compression.Brotli
+		newCompression.Brotli = &brotli
+	}
+	newCompression.Zstd = nil
+	if compression.Zstd != nil {
+		zstd := *compression.Zstd
+		newCompression.Zstd = &zstd
+	}
+	return newCompression, nil
+}
+
+func NewDefaultCompression() *Compression {
+	compression := &Compression{
+		Enable:              false,
+		MinContentLength:    1024,
+		ContentType:         []string{"text/html", "text/css", "text/plain", "text/xml", "application/json", "application/javascript", "application/xhtml+xml", "image/svg+xml"},
+		DisableOnEtagHeader: true,
+		Preferred:           CompressionAlgorithmBrotli,
+		Brotli: &Brotli{
+			Enable:      true,
+			Quality:     4,
+			WindowBits:  18,
+			EncoderMode: "DEFAULT",
+			ChunkSize:   4096,
+		},
+		Zstd: &Zstd{
+			Enable:           true,
+			CompressionLevel: 3,
+			Strategy:         "DEFAULT",
+			ChunkSize:        4096,
+		},
+	}
+	return compression
+}
+
+type CompressionController struct {
+	Namespace    string
+	compression  atomic.Value
+	Name         string
+	eventHandler ItemEventHandler
+}
+
+func NewCompressionController(namespace string) *CompressionController {
+	compressionController := &CompressionController{
+		Namespace:   namespace,
+		compression: atomic.Value{},
+		Name:        "compression",
+	}
+	compressionController.SetCompression(NewDefaultCompression())
+	return compressionController
+}
+
+func (c *CompressionController) GetName() string {
+	return c.Name
+}
+
+func (c *CompressionController) SetCompression(compression *Compression) {
+	c.compression.Store(compression)
+}
+
+func (c *CompressionController) GetCompression() *Compression {
+	value := c.compression.Load()
+	if value != nil {
+		if compression, ok := value.(*Compression); ok {
+			return compression
+		}
+	}
+	return nil
+}
+
+func (c *CompressionController) AddOrUpdateHigressConfig(name util.ClusterNamespacedName, old *HigressConfig, new *HigressConfig) error {
+	if err := validCompression(new.Compression); err != nil {
+		IngressLog.Errorf("data:%+v convert to compression, error: %+v", new.Compression, err)
+		return nil
+	}
+
+	result, _ := compareCompression(old.Compression, new.Compression)
+
+	switch result {
+	case ResultReplace:
+		if newCompression, err := deepCopyCompression(new.Compression); err != nil {
+			IngressLog.Infof("compression deepcopy error:%v", err)
+		} else {
+			c.SetCompression(newCompression)
+			IngressLog.Infof("AddOrUpdate Higress config compression")
+			c.eventHandler(higressCompressionEnvoyFilterName)
+			IngressLog.Infof("send event with filter name:%s", higressCompressionEnvoyFilterName)
+		}
+	case ResultDelete:
+		c.SetCompression(NewDefaultCompression())
+		IngressLog.Infof("Delete Higress config compression")
+		c.eventHandler(higressCompressionEnvoyFilterName)
+		IngressLog.Infof("send event with filter name:%s", higressCompressionEnvoyFilterName)
+	}
+
+	return nil
+}
+
+func (c *CompressionController) ValidHigressConfig(higressConfig *HigressConfig) error {
+	if higressConfig == nil {
+		return nil
+	}
+	if higressConfig.Compression == nil {
+		return nil
+	}
+
+	return validCompression(higressConfig.Compression)
+}
+
+func (c *CompressionController) ConstructEnvoyFilters() ([]*config.Config, error) {
+	configs := make([]*config.Config, 0)
+	compression := c.GetCompression()
+	namespace := c.Namespace
+
+	if compression == nil {
+		return configs, nil
+	}
+
+	if compression.Enable == false {
+		return configs, nil
+	}
+
+	configPatches := make([]*networking.EnvoyFilter_EnvoyConfigObjectPatch, 0)
+	if compression.Brotli != nil && compression.Brotli.Enable {
+		configPatches = append(configPatches, c.constructCompressorPatch(c.constructBrotliStruct(compression)))
+	}
+	if compression.Zstd != nil && compression.Zstd.Enable {
+		configPatches = append(configPatches, c.constructCompressorPatch(c.constructZstdStruct(compression)))
+	}
+	if len(configPatches) == 0 {
+		return configs, nil
+	}
+
+	config := &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.EnvoyFilter,
+			Name:             higressCompressionEnvoyFilterName,
+			Namespace:        namespace,
+		},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: configPatches,
+		},
+	}
+
+	configs = append(configs, config)
+	return configs, nil
+}
+
+func (c *CompressionController) RegisterItemEventHandler(eventHandler ItemEventHandler) {
+	c.eventHandler = eventHandler
+}
+
+func (c *CompressionController) constructCompressorPatch(compressorStruct string) *networking.EnvoyFilter_EnvoyConfigObjectPatch {
+	return &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+		Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+			Context: networking.EnvoyFilter_GATEWAY,
+			ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+				Listener: &networking.EnvoyFilter_ListenerMatch{
+					FilterChain: &networking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+						Filter: &networking.EnvoyFilter_ListenerMatch_FilterMatch{
+							Name: "envoy.filters.network.http_connection_manager",
+							SubFilter: &networking.EnvoyFilter_ListenerMatch_SubFilterMatch{
+								Name: "envoy.filters.http.cors",
+							},
+						},
+					},
+				},
+			},
+		},
+		Patch: &networking.EnvoyFilter_Patch{
+			Operation: networking.EnvoyFilter_Patch_INSERT_BEFORE,
+			Value:     util.BuildPatchStruct(compressorStruct),
+		},
+	}
+}
+
+// constructCompressorStruct builds a compressor filter, envoy negotiates among the compressor
+// filters with the q-values of Accept-Encoding and picks the one with choose_first on a tie.
+func (c *CompressionController) constructCompressorStruct(compression *Compression, filterName string, chooseFirst bool, library string) string {
+	contentType := ""
+	index := 0
+	for _, v := range compression.ContentType {
+		contentType = contentType + fmt.Sprintf("\"%s\"", v)
+		if index < len(compression.ContentType)-1 {
+			contentType = contentType + ","
+		}
+		index++
+	}
+	structFmt := `{
+   "name": "%s",
+   "typed_config": {
+      "@type": "type.googleapis.com/envoy.extensions.filters.http.compressor.v3.Compressor",
+      "response_direction_config": {
+         "common_config": {
+            "min_content_length": %d,
+            "content_type": [%s]
+         },
+        "disable_on_etag_header": %t
+      },
+      "request_direction_config": {
+         "common_config": {
+            "enabled": {
+               "default_value": false,
+               "runtime_key": "request_compressor_enabled"
+            }
+         }
+      },
+      "choose_first": %t,
+      "compressor_library": %s
+   }
+}`
+	return fmt.Sprintf(structFmt, filterName, compression.MinContentLength, contentType, compression.DisableOnEtagHeader, chooseFirst, library)
+}
+
+func (c *CompressionController) constructBrotliStruct(compression *Compression) string {
+	brotli := compression.Brotli
+	libraryFmt := `{
+         "name": "brotli",
+         "typed_config": {
+            "@type": "type.googleapis.com/envoy.extensions.compression.brotli.compressor.v3.Brotli",
+            "quality": %d,
+            "window_bits": %d,
+            "encoder_mode": "%s",
+            "chunk_size": %d
+         }
+      }`
+	library := fmt.Sprintf(libraryFmt, brotli.Quality, brotli.WindowBits, brotli.EncoderMode, brotli.ChunkSize)
+	return c.constructCompressorStruct(compression, BrotliCompressorFilterName, compression.Preferred == CompressionAlgorithmBrotli, library)
+}
+
+func (c *CompressionController) constructZstdStruct(compression *Compression) string {
+	zstd := compression.Zstd
+	libraryFmt := `{
+         "name": "zstd",
+         "typed_config": {
+            "@type": "type.googleapis.com/envoy.extensions.compression.zstd.compressor.v3.Zstd",
+            "compression_level": %d,
+            "strategy": "%s",
+            "enable_checksum": %t,
+            "chunk_size": %d
+         }
+      }`
+	library := fmt.Sprintf(libraryFmt, zstd.CompressionLevel, zstd.Strategy, zstd.EnableChecksum, zstd.ChunkSize)
+	return c.constructCompressorStruct(compression, ZstdCompressorFilterName, compression.Preferred == CompressionAlgorithmZstd, library)
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validCompression(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression func(c *Compression)
+		wantErr     error
+	}{
+		{
+			name:        "default",
+			compression: func(c *Compression) {},
+			wantErr:     nil,
+		},
+		{
+			name: "no content type",
+			compression: func(c *Compression) {
+				c.ContentType = []string{}
+			},
+			wantErr: errors.New("content type can not be empty"),
+		},
+		{
+			name: "unknown preferred",
+			compression: func(c *Compression) {
+				c.Preferred = "gzip"
+			},
+			wantErr: errors.New("preferred need be one of br and zstd"),
+		},
+		{
+			name: "brotli quality out of range",
+			compression: func(c *Compression) {
+				c.Brotli.Quality = 12
+			},
+			wantErr: errors.New("brotli quality need be between 0 and 11"),
+		},
+		{
+			name: "brotli unknown encoder mode",
+			compression: func(c *Compression) {
+				c.Brotli.EncoderMode = "IMAGE"
+			},
+			wantErr: errors.New("brotli encoderMode need be one of DEFAULT,GENERIC,TEXT,FONT"),
+		},
+		{
+			name: "zstd level out of range",
+			compression: func(c *Compression) {
+				c.Zstd.CompressionLevel = 23
+			},
+			wantErr: errors.New("zstd compression level need be between 1 and 22"),
+		},
+		{
+			name: "zstd unknown strategy",
+			compression: func(c *Compression) {
+				c.Zstd.Strategy = "SLOW"
+			},
+			wantErr: errors.New("zstd strategy need be one of DEFAULT,FAST,DFAST,GREEDY,LAZY,LAZY2,BTLAZY2,BTOPT,BTULTRA,BTULTRA2"),
+		},
+		{
+			name: "brotli only",
+			compression: func(c *Compression) {
+				c.Zstd = nil
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compression := NewDefaultCompression()
+			tt.compression(compression)
+			assert.Equal(t, tt.wantErr, validCompression(compression))
+		})
+	}
+
+	assert.NoError(t, validCompression(nil))
+}
+
+func Test_compareCompression(t *testing.T) {
+	changed := NewDefaultCompression()
+	changed.Brotli.Quality = 6
+
+	tests := []struct {
+		name       string
+		old        *Compression
+		new        *Compression
+		wantResult Result
+	}{
+		{
+			name:       "compare both nil",
+			old:        nil,
+			new:        nil,
+			wantResult: ResultNothing,
+		},
+		{
+			name:       "compare result delete",
+			old:        NewDefaultCompression(),
+			new:        nil,
+			wantResult: ResultDelete,
+		},
+		{
+			name:       "compare result equal",
+			old:        NewDefaultCompression(),
+			new:        NewDefaultCompression(),
+			wantResult: ResultNothing,
+		},
+		{
+			name:       "compare result replace",
+			old:        NewDefaultCompression(),
+			new:        changed,
+			wantResult: ResultReplace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := compareCompression(tt.old, tt.new)
+			assert.Equal(t, tt.wantResult, result)
+		})
+	}
+}
+
+func Test_deepCopyCompression(t *testing.T) {
+	compression := NewDefaultCompression()
+	compression.Enable = true
+	compression.Zstd = nil
+
+	newCompression, err := deepCopyCompression(compression)
+	require.NoError(t, err)
+	assert.Equal(t, compression, newCompression)
+
+	newCompression.Brotli.Quality = 11
+	newCompression.ContentType[0] = "text/event-stream"
+	assert.Equal(t, int32(4), compression.Brotli.Quality)
+	assert.Equal(t, "text/html", compression.ContentType[0])
+}
+
+func TestCompressionController_AddOrUpdateHigressConfig(t *testing.T) {
+	eventPush := "default"
+	defaultHandler := func(name string) {
+		eventPush = "push"
+	}
+
+	defaultName := util.ClusterNamespacedName{}
+	enabled := NewDefaultCompression()
+	enabled.Enable = true
+
+	tests := []struct {
+		name            string
+		old             *HigressConfig
+		new             *HigressConfig
+		wantEventPush   string
+		wantCompression *Compression
+	}{
+		{
+			name: "default",
+			old: &HigressConfig{
+				Compression: NewDefaultCompression(),
+			},
+			new: &HigressConfig{
+				Compression: NewDefaultCompression(),
+			},
+			wantEventPush:   "default",
+			wantCompression: NewDefaultCompression(),
+		},
+		{
+			name: "replace and push",
+			old: &HigressConfig{
+				Compression: NewDefaultCompression(),
+			},
+			new: &HigressConfig{
+				Compression: enabled,
+			},
+			wantEventPush:   "push",
+			wantCompression: enabled,
+		},
+		{
+			name: "delete and push",
+			old: &HigressConfig{
+				Compression: enabled,
+			},
+			new: &HigressConfig{
+				Compression: nil,
+			},
+			wantEventPush:   "push",
+			wantCompression: NewDefaultCompression(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCompressionController("higress-system")
+			c.eventHandler = defaultHandler
+			eventPush = "default"
+			err := c.AddOrUpdateHigressConfig(defaultName, tt.old, tt.new)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEventPush, eventPush)
+			assert.Equal(t, tt.wantCompression, c.GetCompression())
+		})
+	}
+}
+
+func TestCompressionController_ConstructEnvoyFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression func(c *Compression)
+		wantConfigs int
+		wantPatches int
+	}{
+		{
+			name:        "disabled",
+			compression: func(c *Compression) {},
+			wantConfigs: 0,
+		},
+		{
+			name: "brotli and zstd",
+			compression: func(c *Compression) {
+				c.Enable = true
+			},
+			wantConfigs: 1,
+			wantPatches: 2,
+		},
+		{
+			name: "zstd only",
+			compression: func(c *Compression) {
+				c.Enable = true
+				c.Brotli.Enable = false
+			},
+			wantConfigs: 1,
+			wantPatches: 1,
+		},
+		{
+			name: "no algorithm",
+			compression: func(c *Compression) {
+				c.Enable = true
+				c.Brotli = nil
+				c.Zstd.Enable = false
+			},
+			wantConfigs: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCompressionController("higress-system")
+			compression := NewDefaultCompression()
+			tt.compression(compression)
+			c.SetCompression(compression)
+			configs, err := c.ConstructEnvoyFilters()
+			require.NoError(t, err)
+			require.Len(t, configs, tt.wantConfigs)
+			if tt.wantConfigs == 0 {
+				return
+			}
+			envoyFilter := configs[0].Spec.(*networking.EnvoyFilter)
+			assert.Len(t, envoyFilter.ConfigPatches, tt.wantPatches)
+		})
+	}
+}
+
+func TestCompressionController_constructCompressorStruct(t *testing.T) {
+	c := NewCompressionController("higress-system")
+	compression := NewDefaultCompression()
+	compression.Preferred = CompressionAlgorithmZstd
+
+	var brotli, zstd struct {
+		Name        string `json:"name"`
+		TypedConfig struct {
+			ChooseFirst       bool `json:"choose_first"`
+			CompressorLibrary struct {
+				TypedConfig map[string]interface{} `json:"typed_config"`
+			} `json:"compressor_library"`
+		} `json:"typed_config"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.constructBrotliStruct(compression)), &brotli))
+	require.NoError(t, json.Unmarshal([]byte(c.constructZstdStruct(compression)), &zstd))
+
+	assert.Equal(t, BrotliCompressorFilterName, brotli.Name)
+	assert.False(t, brotli.TypedConfig.ChooseFirst)
+	assert.Equal(t, float64(4), brotli.TypedConfig.CompressorLibrary.TypedConfig["quality"])
+
+	assert.Equal(t, ZstdCompressorFilterName, zstd.Name)
+	assert.True(t, zstd.TypedConfig.ChooseFirst)
+	assert.Equal(t, float64(3), zstd.TypedConfig.CompressorLibrary.TypedConfig["compression_level"])
+}
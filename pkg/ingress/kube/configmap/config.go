@@ -34,13 +34,16 @@ const (
 type ItemEventHandler = func(name string)
 
 type HigressConfig struct {
-	Tracing              *Tracing    `json:"tracing,omitempty"`
-	Gzip                 *Gzip       `json:"gzip,omitempty"`
-	Downstream           *Downstream `json:"downstream,omitempty"`
-	Upstream             *Upstream   `json:"upstream,omitempty"`
-	DisableXEnvoyHeaders bool        `json:"disableXEnvoyHeaders,omitempty"`
-	AddXRealIpHeader     bool        `json:"addXRealIpHeader,omitempty"`
-	McpServer            *McpServer  `json:"mcpServer,omitempty"`
+	Tracing              *Tracing     `json:"tracing,omitempty"`
+	Gzip                 *Gzip        `json:"gzip,omitempty"`
+	Downstream           *Downstream  `json:"downstream,omitempty"`
+	Upstream             *Upstream    `json:"upstream,omitempty"`
+	DisableXEnvoyHeaders bool         `json:"disableXEnvoyHeaders,omitempty"`
+	AddXRealIpHeader     bool         `json:"addXRealIpHeader,omitempty"`
+	ClientIp             *ClientIp    `json:"clientIp,omitempty"`
+	McpServer            *McpServer   `json:"mcpServer,omitempty"`
+	AccessLog            *AccessLog   `json:"accessLog,omitempty"`
+	Compression          *Compression `json:"compression,omitempty"`
 }
 
 func NewDefaultHigressConfig() *HigressConfig {
@@ -52,7 +55,10 @@ func NewDefaultHigressConfig() *HigressConfig {
 		Upstream:             globalOption.Upstream,
 		DisableXEnvoyHeaders: globalOption.DisableXEnvoyHeaders,
 		AddXRealIpHeader:     globalOption.AddXRealIpHeader,
+		ClientIp:             globalOption.ClientIp,
 		McpServer:            NewDefaultMcpServer(),
+		AccessLog:            NewDefaultAccessLog(),
+		Compression:          NewDefaultCompression(),
 	}
 	return higressConfig
 }
@@ -87,12 +87,18 @@ func NewConfigmapMgr(XDSUpdater model.XDSUpdater, namespace string, higressConfi
 	gzipController := NewGzipController(namespace)
 	configmapMgr.AddItemControllers(gzipController)
 
+	compressionController := NewCompressionController(namespace)
+	configmapMgr.AddItemControllers(compressionController)
+
 	globalOptionController := NewGlobalOptionController(namespace)
 	configmapMgr.AddItemControllers(globalOptionController)
 
 	mcpServerController := NewMcpServerController(namespace)
 	configmapMgr.AddItemControllers(mcpServerController)
 
+	accessLogController := NewAccessLogController(namespace)
+	configmapMgr.AddItemControllers(accessLogController)
+
 	configmapMgr.initEventHandlers()
 
 	return configmapMgr
@@ -16,6 +16,7 @@ package configmap
 
 import (
 	"fmt"
+	"net"
 	"reflect"
 	"sync/atomic"
 
@@ -36,6 +37,15 @@ const (
 	maxInitialStreamWindowSize     = 2147483647
 	minInitialConnectionWindowSize = 65535
 	maxInitialConnectionWindowSize = 2147483647
+	minHttp3Port                   = 1
+	maxHttp3Port                   = 65535
+	minQuicWindowSize              = 1
+	maxQuicStreamWindowSize        = 16777216
+	maxQuicConnectionWindowSize    = 25165824
+	minQuicIdleTimeout             = 1
+	maxQuicIdleTimeout             = 600
+	minProxyProtocolPort           = 1
+	maxProxyProtocolPort           = 65535
 
 	defaultIdleTimeout                    = 180
 	defaultRouteTimeout                   = 0
@@ -46,16 +56,43 @@ const (
 	defaultMaxConcurrentStreams           = 100
 	defaultInitialStreamWindowSize        = 65535
 	defaultInitialConnectionWindowSize    = 1048576
+	defaultHttp3Port                      = 443
+	defaultHttpPort                       = 80
+	defaultHttpsPort                      = 443
+	defaultAltSvcMaxAge                   = 86400
 	defaultAddXRealIpHeader               = false
 	defaultDisableXEnvoyHeaders           = false
+
+	// tcpListenerNameFormat is the name of the TCP listener generated for a gateway port.
+	tcpListenerNameFormat = "0.0.0.0_%d"
+	// quicListenerNameFormat is the name of the QUIC listener generated for a gateway port, it is the name of
+	// the TCP listener of the port prefixed with udp_, so patches matching it never apply to the TCP listener.
+	quicListenerNameFormat = "udp_0.0.0.0_%d"
 )
 
-// Global configures the behavior of the downstream connection, x-real-ip header and x-envoy headers.
+// Global configures the behavior of the downstream connection, client ip, x-real-ip header and x-envoy headers.
 type Global struct {
 	Downstream           *Downstream `json:"downstream,omitempty"`
 	Upstream             *Upstream   `json:"upstream,omitempty"`
 	AddXRealIpHeader     bool        `json:"addXRealIpHeader,omitempty"`
 	DisableXEnvoyHeaders bool        `json:"disableXEnvoyHeaders,omitempty"`
+	ClientIp             *ClientIp   `json:"clientIp,omitempty"`
+}
+
+// ClientIp configures how the client ip is determined. The result becomes the downstream remote address,
+// which is used by the x-real-ip header, the whitelist-source-range annotation and the plugins reading
+// the client ip from origin-source.
+type ClientIp struct {
+	// ProxyProtocol accepts PROXY protocol v1 and v2 on the listeners.
+	ProxyProtocol bool `json:"proxyProtocol,omitempty"`
+	// ProxyProtocolPorts are the gateway ports whose TCP listeners accept PROXY protocol, defaults to 80 and 443.
+	ProxyProtocolPorts []uint32 `json:"proxyProtocolPorts,omitempty"`
+	// AllowRequestsWithoutProxyProtocol also accepts the connections without PROXY protocol.
+	AllowRequestsWithoutProxyProtocol bool `json:"allowRequestsWithoutProxyProtocol,omitempty"`
+	// TrustedCidrs are the CIDRs of the trusted proxies, the client ip is the rightmost x-forwarded-for address out of them.
+	TrustedCidrs []string `json:"trustedCidrs,omitempty"`
+	// XffNumTrustedHops is the number of trusted proxies in front of the gateway.
+	XffNumTrustedHops uint32 `json:"xffNumTrustedHops,omitempty"`
 }
 
 // Downstream configures the behavior of the downstream connection.
@@ -68,6 +105,8 @@ type Downstream struct {
 	ConnectionBufferLimits uint32 `json:"connectionBufferLimits,omitempty"`
 	// Http2 configures HTTP/2 specific options.
 	Http2 *Http2 `json:"http2,omitempty"`
+	// Http3 configures HTTP/3 specific options.
+	Http3 *Http3 `json:"http3,omitempty"`
 	// RouteTimeout limits the time that timeout for the route.
 	RouteTimeout uint32 `json:"routeTimeout"`
 }
@@ -90,12 +129,36 @@ type Http2 struct {
 	InitialConnectionWindowSize uint32 `json:"initialConnectionWindowSize,omitempty"`
 }
 
+// Http3 configures HTTP/3 specific options.
+// The QUIC listener is generated next to the TLS listener of the https port and shares its certificates,
+// the gateway service needs to expose the port over UDP as well.
+type Http3 struct {
+	// Enable advertises HTTP/3 with the Alt-Svc header and applies the QUIC options.
+	Enable bool `json:"enable,omitempty"`
+	// Port is the https port serving HTTP/3 over UDP.
+	Port uint32 `json:"port,omitempty"`
+	// AltSvcMaxAge is the time in seconds that clients may remember HTTP/3 is available.
+	AltSvcMaxAge uint32 `json:"altSvcMaxAge,omitempty"`
+	// MaxConcurrentStreams limits the number of concurrent streams allowed.
+	MaxConcurrentStreams uint32 `json:"maxConcurrentStreams,omitempty"`
+	// InitialStreamWindowSize limits the initial window size of stream.
+	InitialStreamWindowSize uint32 `json:"initialStreamWindowSize,omitempty"`
+	// InitialConnectionWindowSize limits the initial window size of connection.
+	InitialConnectionWindowSize uint32 `json:"initialConnectionWindowSize,omitempty"`
+	// IdleTimeout limits the time that a QUIC connection may be idle.
+	IdleTimeout uint32 `json:"idleTimeout,omitempty"`
+}
+
 // validGlobal validates the global config.
 func validGlobal(global *Global) error {
 	if global == nil {
 		return nil
 	}
 
+	if err := validClientIp(global.ClientIp); err != nil {
+		return err
+	}
+
 	if global.Downstream == nil {
 		return nil
 	}
@@ -124,6 +187,50 @@ func validGlobal(global *Global) error {
 			return fmt.Errorf("http2.initialConnectionWindowSize must be between 65535 and 2147483647")
 		}
 	}
+	// check http3
+	if downStream.Http3 != nil {
+		if downStream.Http3.Port < minHttp3Port || downStream.Http3.Port > maxHttp3Port {
+			return fmt.Errorf("http3.port must be between 1 and 65535")
+		}
+		if downStream.Http3.MaxConcurrentStreams < minMaxConcurrentStreams ||
+			downStream.Http3.MaxConcurrentStreams > maxMaxConcurrentStreams {
+			return fmt.Errorf("http3.maxConcurrentStreams must be between 1 and 2147483647")
+		}
+		if downStream.Http3.InitialStreamWindowSize < minQuicWindowSize ||
+			downStream.Http3.InitialStreamWindowSize > maxQuicStreamWindowSize {
+			return fmt.Errorf("http3.initialStreamWindowSize must be between 1 and 16777216")
+		}
+		if downStream.Http3.InitialConnectionWindowSize < minQuicWindowSize ||
+			downStream.Http3.InitialConnectionWindowSize > maxQuicConnectionWindowSize {
+			return fmt.Errorf("http3.initialConnectionWindowSize must be between 1 and 25165824")
+		}
+		if downStream.Http3.IdleTimeout < minQuicIdleTimeout || downStream.Http3.IdleTimeout > maxQuicIdleTimeout {
+			return fmt.Errorf("http3.idleTimeout must be between 1 and 600")
+		}
+	}
+
+	return nil
+}
+
+// validClientIp validates the client ip config.
+func validClientIp(clientIp *ClientIp) error {
+	if clientIp == nil {
+		return nil
+	}
+
+	for _, port := range clientIp.ProxyProtocolPorts {
+		if port < minProxyProtocolPort || port > maxProxyProtocolPort {
+			return fmt.Errorf("clientIp.proxyProtocolPorts must be between 1 and 65535")
+		}
+	}
+	if len(clientIp.TrustedCidrs) > 0 && clientIp.XffNumTrustedHops > 0 {
+		return fmt.Errorf("clientIp.trustedCidrs and clientIp.xffNumTrustedHops can not be set at the same time")
+	}
+	for _, cidr := range clientIp.TrustedCidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("clientIp.trustedCidrs has invalid cidr %s", cidr)
+		}
+	}
 
 	return nil
 }
@@ -138,7 +245,7 @@ func compareGlobal(old *Global, new *Global) (Result, error) {
 		return ResultDelete, nil
 	}
 
-	if new.Downstream == nil && new.Upstream == nil && !new.AddXRealIpHeader && !new.DisableXEnvoyHeaders {
+	if new.Downstream == nil && new.Upstream == nil && !new.AddXRealIpHeader && !new.DisableXEnvoyHeaders && new.ClientIp == nil {
 		return ResultDelete, nil
 	}
 
@@ -161,6 +268,11 @@ func deepCopyGlobal(global *Global) (*Global, error) {
 			newGlobal.Downstream.Http2.InitialStreamWindowSize = global.Downstream.Http2.InitialStreamWindowSize
 			newGlobal.Downstream.Http2.InitialConnectionWindowSize = global.Downstream.Http2.InitialConnectionWindowSize
 		}
+		newGlobal.Downstream.Http3 = nil
+		if global.Downstream.Http3 != nil {
+			http3 := *global.Downstream.Http3
+			newGlobal.Downstream.Http3 = &http3
+		}
 		newGlobal.Downstream.RouteTimeout = global.Downstream.RouteTimeout
 	}
 	if global.Upstream != nil {
@@ -169,6 +281,15 @@ func deepCopyGlobal(global *Global) (*Global, error) {
 	}
 	newGlobal.AddXRealIpHeader = global.AddXRealIpHeader
 	newGlobal.DisableXEnvoyHeaders = global.DisableXEnvoyHeaders
+	if global.ClientIp != nil {
+		newGlobal.ClientIp = &ClientIp{
+			ProxyProtocol:                     global.ClientIp.ProxyProtocol,
+			ProxyProtocolPorts:                append([]uint32(nil), global.ClientIp.ProxyProtocolPorts...),
+			AllowRequestsWithoutProxyProtocol: global.ClientIp.AllowRequestsWithoutProxyProtocol,
+			TrustedCidrs:                      append([]string(nil), global.ClientIp.TrustedCidrs...),
+			XffNumTrustedHops:                 global.ClientIp.XffNumTrustedHops,
+		}
+	}
 	return newGlobal, nil
 }
 
@@ -189,6 +310,7 @@ func NewDefaultDownstream() *Downstream {
 		MaxRequestHeadersKb:    defaultMaxRequestHeadersKb,
 		ConnectionBufferLimits: defaultConnectionBufferLimits,
 		Http2:                  NewDefaultHttp2(),
+		Http3:                  NewDefaultHttp3(),
 		RouteTimeout:           defaultRouteTimeout,
 	}
 }
@@ -210,6 +332,19 @@ func NewDefaultHttp2() *Http2 {
 	}
 }
 
+// NewDefaultHttp3 returns a default http3 config.
+func NewDefaultHttp3() *Http3 {
+	return &Http3{
+		Enable:                      false,
+		Port:                        defaultHttp3Port,
+		AltSvcMaxAge:                defaultAltSvcMaxAge,
+		MaxConcurrentStreams:        defaultMaxConcurrentStreams,
+		InitialStreamWindowSize:     defaultInitialStreamWindowSize,
+		InitialConnectionWindowSize: defaultInitialConnectionWindowSize,
+		IdleTimeout:                 defaultIdleTimeout,
+	}
+}
+
 // GlobalOptionController is the controller of downstream config.
 type GlobalOptionController struct {
 	Namespace    string
@@ -253,6 +388,7 @@ func (g *GlobalOptionController) AddOrUpdateHigressConfig(name util.ClusterNames
 		Upstream:             new.Upstream,
 		AddXRealIpHeader:     new.AddXRealIpHeader,
 		DisableXEnvoyHeaders: new.DisableXEnvoyHeaders,
+		ClientIp:             new.ClientIp,
 	}
 
 	oldGlobal := &Global{
@@ -260,6 +396,7 @@ func (g *GlobalOptionController) AddOrUpdateHigressConfig(name util.ClusterNames
 		Upstream:             old.Upstream,
 		AddXRealIpHeader:     old.AddXRealIpHeader,
 		DisableXEnvoyHeaders: old.DisableXEnvoyHeaders,
+		ClientIp:             old.ClientIp,
 	}
 
 	err := validGlobal(newGlobal)
@@ -295,7 +432,7 @@ func (g *GlobalOptionController) ValidHigressConfig(higressConfig *HigressConfig
 		return nil
 	}
 
-	if higressConfig.Downstream == nil {
+	if higressConfig.Downstream == nil && higressConfig.ClientIp == nil {
 		return nil
 	}
 
@@ -304,6 +441,7 @@ func (g *GlobalOptionController) ValidHigressConfig(higressConfig *HigressConfig
 		Upstream:             higressConfig.Upstream,
 		AddXRealIpHeader:     higressConfig.AddXRealIpHeader,
 		DisableXEnvoyHeaders: higressConfig.DisableXEnvoyHeaders,
+		ClientIp:             higressConfig.ClientIp,
 	}
 
 	return validGlobal(global)
@@ -318,8 +456,13 @@ func (g *GlobalOptionController) ConstructEnvoyFilters() ([]*config.Config, erro
 
 	namespace := g.Namespace
 
+	if global.ClientIp != nil {
+		clientIpConfig := g.generateClientIpEnvoyFilter(global.ClientIp, namespace)
+		configPatch = append(configPatch, clientIpConfig...)
+	}
+
 	if global.AddXRealIpHeader {
-		addXRealIpStruct := g.constructAddXRealIpHeader()
+		addXRealIpStruct := g.constructAddXRealIpHeader(global.ClientIp)
 		addXRealIpHeaderConfig := g.generateAddXRealIpHeaderEnvoyFilter(addXRealIpStruct, namespace)
 		configPatch = append(configPatch, addXRealIpHeaderConfig...)
 	}
@@ -338,6 +481,11 @@ func (g *GlobalOptionController) ConstructEnvoyFilters() ([]*config.Config, erro
 		if downstreamConfig != nil {
 			configPatch = append(configPatch, downstreamConfig...)
 		}
+		if http3 := global.Downstream.Http3; http3 != nil && http3.Enable {
+			altSvcStruct := g.constructAltSvcHeader(http3)
+			quicOptionsStruct := g.constructQuicOptions(http3)
+			configPatch = append(configPatch, g.generateHttp3EnvoyFilter(http3, altSvcStruct, quicOptionsStruct, namespace)...)
+		}
 	}
 
 	if global.Upstream != nil {
@@ -440,6 +588,45 @@ func (g *GlobalOptionController) generateDownstreamEnvoyFilter(downstreamValueSt
 	return downstreamConfig
 }
 
+// generateHttp3EnvoyFilter generates the http3 envoy filter, which advertises HTTP/3 on the routes of the https port
+// and tunes the QUIC listener generated for the port. The listener patch matches the QUIC listener by name,
+// matching by port only would also merge the udp listener config into the TCP listener of the same port.
+func (g *GlobalOptionController) generateHttp3EnvoyFilter(http3 *Http3, altSvcStruct string, quicOptionsStruct string, namespace string) []*networking.EnvoyFilter_EnvoyConfigObjectPatch {
+	return []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: networking.EnvoyFilter_ROUTE_CONFIGURATION,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+					RouteConfiguration: &networking.EnvoyFilter_RouteConfigurationMatch{
+						PortNumber: http3.Port,
+					},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_MERGE,
+				Value:     util.BuildPatchStruct(altSvcStruct),
+			},
+		},
+		{
+			ApplyTo: networking.EnvoyFilter_LISTENER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+					Listener: &networking.EnvoyFilter_ListenerMatch{
+						Name:       fmt.Sprintf(quicListenerNameFormat, http3.Port),
+						PortNumber: http3.Port,
+					},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_MERGE,
+				Value:     util.BuildPatchStruct(quicOptionsStruct),
+			},
+		},
+	}
+}
+
 func (g *GlobalOptionController) generateUpstreamEnvoyFilter(upstreamValueStruct string, bufferLimit string, namespace string) []*networking.EnvoyFilter_EnvoyConfigObjectPatch {
 	var upstreamConfig []*networking.EnvoyFilter_EnvoyConfigObjectPatch
 
@@ -472,6 +659,63 @@ func (g *GlobalOptionController) generateUpstreamEnvoyFilter(upstreamValueStruct
 	return upstreamConfig
 }
 
+// generateClientIpEnvoyFilter generates the client ip envoy filter, the PROXY protocol listener filter goes
+// first so that the tls inspector reads the TLS handshake after the PROXY header. It is only added to the TCP
+// listeners of the configured ports, the QUIC listener of the same port does not support listener filters.
+func (g *GlobalOptionController) generateClientIpEnvoyFilter(clientIp *ClientIp, namespace string) []*networking.EnvoyFilter_EnvoyConfigObjectPatch {
+	var clientIpConfig []*networking.EnvoyFilter_EnvoyConfigObjectPatch
+
+	if clientIp.ProxyProtocol {
+		ports := clientIp.ProxyProtocolPorts
+		if len(ports) == 0 {
+			ports = []uint32{defaultHttpPort, defaultHttpsPort}
+		}
+		proxyProtocolStruct := g.constructProxyProtocol(clientIp)
+		for _, port := range ports {
+			clientIpConfig = append(clientIpConfig, &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				ApplyTo: networking.EnvoyFilter_LISTENER_FILTER,
+				Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+					Context: networking.EnvoyFilter_GATEWAY,
+					ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+						Listener: &networking.EnvoyFilter_ListenerMatch{
+							Name:       fmt.Sprintf(tcpListenerNameFormat, port),
+							PortNumber: port,
+						},
+					},
+				},
+				Patch: &networking.EnvoyFilter_Patch{
+					Operation: networking.EnvoyFilter_Patch_INSERT_FIRST,
+					Value:     util.BuildPatchStruct(proxyProtocolStruct),
+				},
+			})
+		}
+	}
+
+	if originalIpDetectionStruct := g.constructOriginalIpDetection(clientIp); len(originalIpDetectionStruct) != 0 {
+		clientIpConfig = append(clientIpConfig, &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+			ApplyTo: networking.EnvoyFilter_NETWORK_FILTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+					Listener: &networking.EnvoyFilter_ListenerMatch{
+						FilterChain: &networking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+							Filter: &networking.EnvoyFilter_ListenerMatch_FilterMatch{
+								Name: "envoy.filters.network.http_connection_manager",
+							},
+						},
+					},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_MERGE,
+				Value:     util.BuildPatchStruct(originalIpDetectionStruct),
+			},
+		})
+	}
+
+	return clientIpConfig
+}
+
 // generateAddXRealIpHeaderEnvoyFilter generates the add x-real-ip header envoy filter.
 func (g *GlobalOptionController) generateAddXRealIpHeaderEnvoyFilter(addXRealIpHeaderStruct string, namespace string) []*networking.EnvoyFilter_EnvoyConfigObjectPatch {
 	addXRealIpHeaderConfig := []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
@@ -594,7 +838,23 @@ func (g *GlobalOptionController) constructUpstreamBufferLimit(upstream *Upstream
 }
 
 // constructAddXRealIpHeader constructs the add x-real-ip header config.
-func (g *GlobalOptionController) constructAddXRealIpHeader() string {
+func (g *GlobalOptionController) constructAddXRealIpHeader(clientIp *ClientIp) string {
+	if clientIp != nil {
+		// x-envoy-external-address is only set by the remote address, so take the detected client ip instead
+		return `
+		{
+			"request_headers_to_add": [
+				{
+					"append": false,
+					"header": {
+						"key": "x-real-ip",
+						"value": "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%"
+					}
+				}
+			]
+		}
+`
+	}
 	addXRealIpHeaderStruct := fmt.Sprintf(`
 		{
 			"request_headers_to_add": [
@@ -647,3 +907,92 @@ func (g *GlobalOptionController) constructRouteTimeout(downstream *Downstream) s
 	}
 	`, downstream.RouteTimeout)
 }
+
+// constructAltSvcHeader constructs the alt-svc header config.
+func (g *GlobalOptionController) constructAltSvcHeader(http3 *Http3) string {
+	return fmt.Sprintf(`
+		{
+			"response_headers_to_add": [
+				{
+					"append_action": "ADD_IF_ABSENT",
+					"header": {
+						"key": "alt-svc",
+						"value": "h3=\":%d\"; ma=%d"
+					}
+				}
+			]
+		}
+`, http3.Port, http3.AltSvcMaxAge)
+}
+
+// constructQuicOptions constructs the quic options config, which only takes effect on the udp listener.
+func (g *GlobalOptionController) constructQuicOptions(http3 *Http3) string {
+	return fmt.Sprintf(`
+		{
+			"udp_listener_config": {
+				"quic_options": {
+					"idle_timeout": "%ds",
+					"quic_protocol_options": {
+						"max_concurrent_streams": %d,
+						"initial_stream_window_size": %d,
+						"initial_connection_window_size": %d
+					}
+				}
+			}
+		}
+`, http3.IdleTimeout, http3.MaxConcurrentStreams, http3.InitialStreamWindowSize, http3.InitialConnectionWindowSize)
+}
+
+// constructProxyProtocol constructs the PROXY protocol listener filter config.
+func (g *GlobalOptionController) constructProxyProtocol(clientIp *ClientIp) string {
+	return fmt.Sprintf(`
+		{
+			"name": "envoy.filters.listener.proxy_protocol",
+			"typed_config": {
+				"@type": "type.googleapis.com/envoy.extensions.filters.listener.proxy_protocol.v3.ProxyProtocol",
+				"allow_requests_without_proxy_protocol": %t
+			}
+		}
+`, clientIp.AllowRequestsWithoutProxyProtocol)
+}
+
+// constructOriginalIpDetection constructs the original ip detection config of the trusted proxies.
+func (g *GlobalOptionController) constructOriginalIpDetection(clientIp *ClientIp) string {
+	xffConfig := ""
+	if len(clientIp.TrustedCidrs) > 0 {
+		cidrs := ""
+		for i, cidr := range clientIp.TrustedCidrs {
+			_, ipNet, _ := net.ParseCIDR(cidr)
+			prefixLen, _ := ipNet.Mask.Size()
+			if i > 0 {
+				cidrs += ","
+			}
+			cidrs += fmt.Sprintf(`{"address_prefix": "%s", "prefix_len": %d}`, ipNet.IP.String(), prefixLen)
+		}
+		xffConfig = fmt.Sprintf(`"xff_trusted_cidrs": {"cidrs": [%s]}`, cidrs)
+	} else if clientIp.XffNumTrustedHops > 0 {
+		xffConfig = fmt.Sprintf(`"xff_num_trusted_hops": %d`, clientIp.XffNumTrustedHops)
+	} else {
+		return ""
+	}
+
+	// the original ip detection extensions can not be mixed with use_remote_address
+	return fmt.Sprintf(`
+		{
+			"name": "envoy.filters.network.http_connection_manager",
+			"typed_config": {
+				"@type": "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+				"use_remote_address": false,
+				"original_ip_detection_extensions": [
+					{
+						"name": "envoy.extensions.http.original_ip_detection.xff",
+						"typed_config": {
+							"@type": "type.googleapis.com/envoy.extensions.http.original_ip_detection.xff.v3.XffConfig",
+							%s
+						}
+					}
+				]
+			}
+		}
+`, xffConfig)
+}
@@ -15,10 +15,12 @@
 package configmap
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
 	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
 )
 
 func Test_validGlobal(t *testing.T) {
@@ -47,6 +49,51 @@ func Test_validGlobal(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "http3 invalid stream window size",
+			global: &Global{
+				Downstream: &Downstream{
+					Http3: &Http3{
+						Enable:                      true,
+						Port:                        443,
+						MaxConcurrentStreams:        100,
+						InitialStreamWindowSize:     maxQuicStreamWindowSize + 1,
+						InitialConnectionWindowSize: 1048576,
+						IdleTimeout:                 180,
+					},
+				},
+			},
+			wantErr: fmt.Errorf("http3.initialStreamWindowSize must be between 1 and 16777216"),
+		},
+		{
+			name: "client ip invalid cidr",
+			global: &Global{
+				ClientIp: &ClientIp{
+					TrustedCidrs: []string{"10.0.0.0/8", "10.0.0.1"},
+				},
+			},
+			wantErr: fmt.Errorf("clientIp.trustedCidrs has invalid cidr 10.0.0.1"),
+		},
+		{
+			name: "client ip invalid proxy protocol port",
+			global: &Global{
+				ClientIp: &ClientIp{
+					ProxyProtocol:      true,
+					ProxyProtocolPorts: []uint32{443, 70000},
+				},
+			},
+			wantErr: fmt.Errorf("clientIp.proxyProtocolPorts must be between 1 and 65535"),
+		},
+		{
+			name: "client ip both trusted cidrs and hops",
+			global: &Global{
+				ClientIp: &ClientIp{
+					TrustedCidrs:      []string{"10.0.0.0/8"},
+					XffNumTrustedHops: 1,
+				},
+			},
+			wantErr: fmt.Errorf("clientIp.trustedCidrs and clientIp.xffNumTrustedHops can not be set at the same time"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -158,6 +205,26 @@ func Test_deepCopyGlobal(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "deep copy client ip",
+			global: &Global{
+				ClientIp: &ClientIp{
+					ProxyProtocol:      true,
+					ProxyProtocolPorts: []uint32{8080},
+					TrustedCidrs:       []string{"10.0.0.0/8"},
+				},
+			},
+			want: &Global{
+				Downstream: NewDefaultDownstream(),
+				Upstream:   NewDefaultUpStream(),
+				ClientIp: &ClientIp{
+					ProxyProtocol:      true,
+					ProxyProtocolPorts: []uint32{8080},
+					TrustedCidrs:       []string{"10.0.0.0/8"},
+				},
+			},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -254,3 +321,66 @@ func Test_AddOrUpdateHigressConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobalOptionController_ConstructEnvoyFiltersWithHttp3(t *testing.T) {
+	g := NewGlobalOptionController("higress-system")
+	global := NewDefaultGlobalOption()
+	configs, err := g.ConstructEnvoyFilters()
+	assert.NoError(t, err)
+	assert.Len(t, configs[0].Spec.(*networking.EnvoyFilter).ConfigPatches, 4)
+
+	global.Downstream.Http3.Enable = true
+	global.Downstream.Http3.Port = 8443
+	g.SetGlobal(global)
+	configs, err = g.ConstructEnvoyFilters()
+	assert.NoError(t, err)
+	patches := configs[0].Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 6)
+
+	altSvc := patches[2]
+	assert.Equal(t, networking.EnvoyFilter_ROUTE_CONFIGURATION, altSvc.ApplyTo)
+	assert.Equal(t, uint32(8443), altSvc.Match.GetRouteConfiguration().GetPortNumber())
+	header := altSvc.Patch.Value.GetFields()["response_headers_to_add"].GetListValue().GetValues()[0].GetStructValue().GetFields()["header"].GetStructValue()
+	assert.Equal(t, `h3=":8443"; ma=86400`, header.GetFields()["value"].GetStringValue())
+
+	quicOptions := patches[3]
+	assert.Equal(t, networking.EnvoyFilter_LISTENER, quicOptions.ApplyTo)
+	assert.Equal(t, uint32(8443), quicOptions.Match.GetListener().GetPortNumber())
+	assert.Equal(t, "udp_0.0.0.0_8443", quicOptions.Match.GetListener().GetName())
+}
+
+func TestGlobalOptionController_ConstructEnvoyFiltersWithClientIp(t *testing.T) {
+	g := NewGlobalOptionController("higress-system")
+	global := &Global{
+		AddXRealIpHeader: true,
+		ClientIp: &ClientIp{
+			ProxyProtocol: true,
+			TrustedCidrs:  []string{"10.0.0.0/8", "192.168.1.1/32"},
+		},
+	}
+	g.SetGlobal(global)
+	configs, err := g.ConstructEnvoyFilters()
+	assert.NoError(t, err)
+	patches := configs[0].Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 4)
+
+	for i, port := range []uint32{80, 443} {
+		proxyProtocol := patches[i]
+		assert.Equal(t, networking.EnvoyFilter_LISTENER_FILTER, proxyProtocol.ApplyTo)
+		assert.Equal(t, networking.EnvoyFilter_Patch_INSERT_FIRST, proxyProtocol.Patch.Operation)
+		assert.Equal(t, port, proxyProtocol.Match.GetListener().GetPortNumber())
+		assert.Equal(t, fmt.Sprintf("0.0.0.0_%d", port), proxyProtocol.Match.GetListener().GetName())
+		assert.Equal(t, "envoy.filters.listener.proxy_protocol", proxyProtocol.Patch.Value.GetFields()["name"].GetStringValue())
+	}
+
+	hcm := patches[2].Patch.Value.GetFields()["typed_config"].GetStructValue().GetFields()
+	assert.False(t, hcm["use_remote_address"].GetBoolValue())
+	xffConfig := hcm["original_ip_detection_extensions"].GetListValue().GetValues()[0].GetStructValue().GetFields()["typed_config"].GetStructValue().GetFields()
+	cidrs := xffConfig["xff_trusted_cidrs"].GetStructValue().GetFields()["cidrs"].GetListValue().GetValues()
+	assert.Len(t, cidrs, 2)
+	assert.Equal(t, "10.0.0.0", cidrs[0].GetStructValue().GetFields()["address_prefix"].GetStringValue())
+	assert.Equal(t, float64(32), cidrs[1].GetStructValue().GetFields()["prefix_len"].GetNumberValue())
+
+	xRealIp := patches[3].Patch.Value.GetFields()["request_headers_to_add"].GetListValue().GetValues()[0].GetStructValue().GetFields()["header"].GetStructValue()
+	assert.Equal(t, "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%", xRealIp.GetFields()["value"].GetStringValue())
+}
@@ -327,7 +327,7 @@ func tracingClusterName(port, service string) string {
 	return fmt.Sprintf("outbound|%s||%s", port, service)
 }
 
-func (t *TracingController) constructHTTP2ProtocolOptionsPatch(port, service string) *networking.EnvoyFilter_EnvoyConfigObjectPatch {
+func constructHTTP2ProtocolOptionsPatch(port, service string) *networking.EnvoyFilter_EnvoyConfigObjectPatch {
 	http2ProtocolOptions := `{"typed_extension_protocol_options": {
   "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
       "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
@@ -360,10 +360,10 @@ func (t *TracingController) constructTracingExtendPatches(tracing *Tracing) []*n
 	}
 	var patches []*networking.EnvoyFilter_EnvoyConfigObjectPatch
 	if skywalking := tracing.Skywalking; skywalking != nil {
-		patches = append(patches, t.constructHTTP2ProtocolOptionsPatch(skywalking.Port, skywalking.Service))
+		patches = append(patches, constructHTTP2ProtocolOptionsPatch(skywalking.Port, skywalking.Service))
 	}
 	if otel := tracing.OpenTelemetry; otel != nil {
-		patches = append(patches, t.constructHTTP2ProtocolOptionsPatch(otel.Port, otel.Service))
+		patches = append(patches, constructHTTP2ProtocolOptionsPatch(otel.Port, otel.Service))
 	}
 
 	return patches
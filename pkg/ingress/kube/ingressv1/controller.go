@@ -906,21 +906,21 @@ func (c *controller) storeBackendTrafficPolicy(wrapper *common.WrapperConfig, ba
 		for _, dest := range wrapper.AnnotationsConfig.Destination.McpDestination {
 			portNumber := dest.Destination.GetPort().GetNumber()
 			serviceKey := common.CreateMcpServiceKey(dest.Destination.Host, int32(portNumber))
-			if _, exist := store[serviceKey]; !exist {
-				if serviceKey.Port != 0 {
-					store[serviceKey] = &common.WrapperTrafficPolicy{
-						PortTrafficPolicy: &networking.TrafficPolicy_PortTrafficPolicy{
-							Port: &networking.PortSelector{
-								Number: uint32(serviceKey.Port),
-							},
+			if existing, exist := store[serviceKey]; exist {
+				existing.AddSharedConfig(wrapper)
+			} else if serviceKey.Port != 0 {
+				store[serviceKey] = &common.WrapperTrafficPolicy{
+					PortTrafficPolicy: &networking.TrafficPolicy_PortTrafficPolicy{
+						Port: &networking.PortSelector{
+							Number: uint32(serviceKey.Port),
 						},
-						WrapperConfig: wrapper,
-					}
-				} else {
-					store[serviceKey] = &common.WrapperTrafficPolicy{
-						TrafficPolicy: &networking.TrafficPolicy{},
-						WrapperConfig: wrapper,
-					}
+					},
+					WrapperConfig: wrapper,
+				}
+			} else {
+				store[serviceKey] = &common.WrapperTrafficPolicy{
+					TrafficPolicy: &networking.TrafficPolicy{},
+					WrapperConfig: wrapper,
 				}
 			}
 		}
@@ -933,7 +933,9 @@ func (c *controller) storeBackendTrafficPolicy(wrapper *common.WrapperConfig, ba
 			return fmt.Errorf("ignore service %s within ingress %s/%s", serviceKey.Name, wrapper.Config.Namespace, wrapper.Config.Name)
 		}
 
-		if _, exist := store[serviceKey]; !exist {
+		if existing, exist := store[serviceKey]; exist {
+			existing.AddSharedConfig(wrapper)
+		} else {
 			store[serviceKey] = &common.WrapperTrafficPolicy{
 				PortTrafficPolicy: &networking.TrafficPolicy_PortTrafficPolicy{
 					Port: &networking.PortSelector{
@@ -0,0 +1,66 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+
+	"istio.io/istio/pkg/config/schema/gvr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	metadatafake "k8s.io/client-go/metadata/fake"
+
+	higresskube "github.com/alibaba/higress/v2/pkg/kube"
+)
+
+// registerCRDs registers the CRDs to a fake client, so the controllers watching them start. The CRD is added to
+// the fake metadata client as well, since it is not kept in sync with the fake apiextensions client.
+func registerCRDs(client higresskube.Client, crds []schema.GroupVersionResource, annotations map[string]string) error {
+	for _, g := range crds {
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("%s.%s", g.Resource, g.Group),
+				Annotations: annotations,
+			},
+		}
+		_, err := client.Ext().ApiextensionsV1().CustomResourceDefinitions().Create(context.TODO(), crd, metav1.CreateOptions{})
+		if err != nil && !kerrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create crd %s: %v", crd.Name, err)
+		}
+
+		fakeMetadata, ok := client.Metadata().(*metadatafake.FakeMetadataClient)
+		if !ok {
+			continue
+		}
+		fakeCRDs, ok := fakeMetadata.Resource(gvr.CustomResourceDefinition).(metadatafake.MetadataClient)
+		if !ok {
+			continue
+		}
+		_, err = fakeCRDs.CreateFake(&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "CustomResourceDefinition",
+				APIVersion: "apiextensions.k8s.io/v1",
+			},
+			ObjectMeta: crd.ObjectMeta,
+		}, metav1.CreateOptions{})
+		if err != nil && !kerrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create crd metadata %s: %v", crd.Name, err)
+		}
+	}
+	return nil
+}
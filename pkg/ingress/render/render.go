@@ -0,0 +1,261 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render runs the translation of Higress offline: the Ingress, Gateway API and Higress resources are
+// translated into Istio configs like the controller does, and the configs are built into the xDS resources of
+// the gateway like Istio does, without a running controller or gateway.
+package render
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	admin "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	istiomodel "istio.io/istio/pilot/pkg/model"
+	serviceRegistryKube "istio.io/istio/pilot/pkg/serviceregistry/kube"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/config/schema/gvr"
+	istiokube "istio.io/istio/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/gateway-api/pkg/consts"
+
+	innerconstants "github.com/alibaba/higress/v2/pkg/config/constants"
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/v2/pkg/ingress/translation"
+	higresskube "github.com/alibaba/higress/v2/pkg/kube"
+	"github.com/alibaba/higress/v2/pkg/kube/filesource"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// translatedKinds are the kinds of Istio configs the controller of Higress generates, Gateway goes first since
+// the other configs are converted along with it.
+var translatedKinds = []config.GroupVersionKind{
+	gvk.Gateway,
+	gvk.VirtualService,
+	gvk.DestinationRule,
+	gvk.EnvoyFilter,
+	gvk.ServiceEntry,
+	gvk.WasmPlugin,
+}
+
+// gatewayAPICRDs are registered to the fake client, otherwise the Gateway API resources are not watched.
+var gatewayAPICRDs = []schema.GroupVersionResource{
+	gvr.KubernetesGateway,
+	gvr.ReferenceGrant,
+	gvr.GatewayClass,
+	gvr.HTTPRoute,
+	gvr.GRPCRoute,
+	gvr.TCPRoute,
+	gvr.TLSRoute,
+	gvr.BackendTLSPolicy,
+}
+
+// Options are the same as the flags of the controller which affect the translation.
+type Options struct {
+	IngressClass         string
+	WatchNamespace       string
+	SystemNamespace      string
+	GatewaySelectorKey   string
+	GatewaySelectorValue string
+	GatewayHttpPort      uint32
+	GatewayHttpsPort     uint32
+	// Timeout of waiting for the translation to be done
+	Timeout time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		IngressClass:         innerconstants.DefaultIngressClass,
+		SystemNamespace:      "higress-system",
+		GatewaySelectorKey:   "higress",
+		GatewaySelectorValue: "higress-system-higress-gateway",
+		GatewayHttpPort:      80,
+		GatewayHttpsPort:     443,
+		Timeout:              defaultTimeout,
+	}
+}
+
+// Result is what the gateway would receive from the controller and Istio.
+type Result struct {
+	// Configs are the Istio configs translated from the resources
+	Configs   []config.Config
+	Listeners []*listener.Listener
+	Routes    []*route.RouteConfiguration
+	Clusters  []*cluster.Cluster
+	// Files is the load result of the manifests, only set by RenderDir
+	Files []filesource.FileStatus
+}
+
+// RenderDir renders the manifests in the directory.
+func RenderDir(dir string, opts Options) (*Result, error) {
+	client := higresskube.NewFakeClient()
+	err := registerCRDs(client, gatewayAPICRDs, map[string]string{
+		consts.BundleVersionAnnotation: consts.BundleVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register gateway api crds: %v", err)
+	}
+	source, err := filesource.NewSource(dir, client)
+	if err != nil {
+		return nil, err
+	}
+	if err = source.Load(); err != nil {
+		return nil, err
+	}
+	result, err := Render(client, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Files = source.Status()
+	return result, nil
+}
+
+// Render renders the resources of the client, which is either a fake client holding the manifests or a client of
+// a live cluster. The client must not be started.
+func Render(client higresskube.Client, opts Options) (*Result, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	options := common.Options{
+		Enable:               true,
+		IngressClass:         opts.IngressClass,
+		WatchNamespace:       opts.WatchNamespace,
+		SystemNamespace:      opts.SystemNamespace,
+		GatewaySelectorKey:   opts.GatewaySelectorKey,
+		GatewaySelectorValue: opts.GatewaySelectorValue,
+		GatewayHttpPort:      opts.GatewayHttpPort,
+		GatewayHttpsPort:     opts.GatewayHttpsPort,
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ingressTranslation := translation.NewIngressTranslation(client, noopXDSUpdater{}, opts.SystemNamespace, options)
+	ingressTranslation.AddLocalCluster(options)
+	ingressTranslation.Run(stop)
+	client.RunAndWait(stop)
+
+	syncStop := make(chan struct{})
+	timer := time.AfterFunc(opts.Timeout, func() {
+		close(syncStop)
+	})
+	synced := istiokube.WaitForCacheSync("render", syncStop, ingressTranslation.HasSynced)
+	timer.Stop()
+	if !synced {
+		return nil, fmt.Errorf("timed out waiting for the translation after %v", opts.Timeout)
+	}
+
+	result := &Result{}
+	for _, kind := range translatedKinds {
+		result.Configs = append(result.Configs, ingressTranslation.List(kind, "")...)
+	}
+
+	services, err := listServices(client, opts.WatchNamespace)
+	if err != nil {
+		return nil, err
+	}
+	builder, err := newXDSBuilder(result.Configs, services)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build xds resources: %v", err)
+	}
+	if err = builder.build(builder.gatewayProxy(opts), result); err != nil {
+		return nil, fmt.Errorf("failed to build xds resources: %v", err)
+	}
+	sort.Slice(result.Listeners, func(i, j int) bool {
+		return result.Listeners[i].Name < result.Listeners[j].Name
+	})
+	sort.Slice(result.Routes, func(i, j int) bool {
+		return result.Routes[i].Name < result.Routes[j].Name
+	})
+	sort.Slice(result.Clusters, func(i, j int) bool {
+		return result.Clusters[i].Name < result.Clusters[j].Name
+	})
+	return result, nil
+}
+
+func listServices(client higresskube.Client, namespace string) ([]*istiomodel.Service, error) {
+	list, err := client.Kube().CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+	services := make([]*istiomodel.Service, 0, len(list.Items))
+	for _, svc := range list.Items {
+		services = append(services, serviceRegistryKube.ConvertService(svc, constants.DefaultClusterLocalDomain,
+			constants.DefaultClusterName, nil))
+	}
+	return services, nil
+}
+
+// ConfigDump returns the xDS resources in the format of the config dump of Envoy, so that they can be printed
+// the same as the config of a running gateway.
+func (r *Result) ConfigDump() (*admin.ConfigDump, error) {
+	clusters := &admin.ClustersConfigDump{}
+	for _, c := range r.Clusters {
+		a, err := anypb.New(c)
+		if err != nil {
+			return nil, err
+		}
+		clusters.DynamicActiveClusters = append(clusters.DynamicActiveClusters, &admin.ClustersConfigDump_DynamicCluster{
+			Cluster: a,
+		})
+	}
+	listeners := &admin.ListenersConfigDump{}
+	for _, l := range r.Listeners {
+		a, err := anypb.New(l)
+		if err != nil {
+			return nil, err
+		}
+		listeners.DynamicListeners = append(listeners.DynamicListeners, &admin.ListenersConfigDump_DynamicListener{
+			Name:        l.Name,
+			ActiveState: &admin.ListenersConfigDump_DynamicListenerState{Listener: a},
+		})
+	}
+	routes := &admin.RoutesConfigDump{}
+	for _, rc := range r.Routes {
+		a, err := anypb.New(rc)
+		if err != nil {
+			return nil, err
+		}
+		routes.DynamicRouteConfigs = append(routes.DynamicRouteConfigs, &admin.RoutesConfigDump_DynamicRouteConfig{
+			RouteConfig: a,
+		})
+	}
+
+	dump := &admin.ConfigDump{}
+	for _, m := range []proto.Message{clusters, listeners, routes} {
+		a, err := anypb.New(m)
+		if err != nil {
+			return nil, err
+		}
+		dump.Configs = append(dump.Configs, a)
+	}
+	return dump, nil
+}
+
+// noopXDSUpdater drops the push requests of the translation, the configs are listed once it is done.
+type noopXDSUpdater struct {
+	istiomodel.XDSUpdater
+}
+
+func (noopXDSUpdater) ConfigUpdate(*istiomodel.PushRequest) {}
@@ -0,0 +1,129 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+const manifests = `apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+  namespace: default
+spec:
+  ports:
+  - name: http
+    port: 8080
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: foo
+  namespace: default
+spec:
+  ingressClassName: higress
+  rules:
+  - host: foo.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: foo
+            port:
+              number: 8080
+`
+
+func TestRenderDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.yaml"), []byte(manifests), 0o644))
+
+	result, err := RenderDir(dir, DefaultOptions())
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, 2, result.Files[0].Objects)
+
+	kinds := map[string]int{}
+	for _, c := range result.Configs {
+		kinds[c.GroupVersionKind.Kind]++
+	}
+	assert.Equal(t, 1, kinds[gvk.Gateway.Kind])
+	assert.Equal(t, 1, kinds[gvk.VirtualService.Kind])
+
+	var listeners []string
+	for _, l := range result.Listeners {
+		listeners = append(listeners, l.Name)
+	}
+	assert.Contains(t, listeners, "0.0.0.0_80")
+
+	var domains []string
+	for _, rc := range result.Routes {
+		for _, vh := range rc.VirtualHosts {
+			domains = append(domains, vh.Domains...)
+		}
+	}
+	assert.Contains(t, domains, "foo.example.com")
+
+	var clusters []string
+	for _, c := range result.Clusters {
+		clusters = append(clusters, c.Name)
+	}
+	assert.Contains(t, clusters, "outbound|8080||foo.default.svc.cluster.local")
+
+	dump, err := result.ConfigDump()
+	require.NoError(t, err)
+	assert.Len(t, dump.Configs, 3)
+}
+
+func TestRouteNames(t *testing.T) {
+	newFilter := func(t *testing.T, routeName string) *listener.Filter {
+		manager, err := anypb.New(&hcm.HttpConnectionManager{
+			RouteSpecifier: &hcm.HttpConnectionManager_Rds{Rds: &hcm.Rds{RouteConfigName: routeName}},
+		})
+		require.NoError(t, err)
+		return &listener.Filter{Name: "envoy.filters.network.http_connection_manager",
+			ConfigType: &listener.Filter_TypedConfig{TypedConfig: manager}}
+	}
+	listeners := []*listener.Listener{
+		{
+			Name: "0.0.0.0_80",
+			FilterChains: []*listener.FilterChain{
+				{Filters: []*listener.Filter{newFilter(t, "http.80")}},
+			},
+		},
+		{
+			Name: "0.0.0.0_443",
+			FilterChains: []*listener.FilterChain{
+				{Filters: []*listener.Filter{newFilter(t, "https.443.foo")}},
+				{Filters: []*listener.Filter{newFilter(t, "https.443.foo")}},
+				{Filters: []*listener.Filter{{Name: "envoy.filters.network.tcp_proxy"}}},
+			},
+			DefaultFilterChain: &listener.FilterChain{Filters: []*listener.Filter{newFilter(t, "https.443.default")}},
+		},
+	}
+	assert.Equal(t, []string{"http.80", "https.443.foo", "https.443.default"}, routeNames(listeners))
+	assert.Len(t, listeners[1].FilterChains, 3)
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pilot/pkg/config/memory"
+	istiomodel "istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core"
+	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	memregistry "istio.io/istio/pilot/pkg/serviceregistry/memory"
+	"istio.io/istio/pilot/pkg/serviceregistry/provider"
+	"istio.io/istio/pilot/pkg/serviceregistry/serviceentry"
+	istiocluster "istio.io/istio/pkg/cluster"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/mesh/meshwatcher"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+// xdsBuilder builds the xDS resources of the gateway from the Istio configs, with the same environment and config
+// generator as the discovery server of Istio, but without watching anything: the configs and the services are
+// loaded once and the push context is initialized from them.
+type xdsBuilder struct {
+	env  *istiomodel.Environment
+	push *istiomodel.PushContext
+	gen  *core.ConfigGeneratorImpl
+}
+
+func newXDSBuilder(configs []config.Config, services []*istiomodel.Service) (*xdsBuilder, error) {
+	env := istiomodel.NewEnvironment()
+	env.DomainSuffix = constants.DefaultClusterLocalDomain
+	env.Watcher = meshwatcher.NewTestWatcher(mesh.DefaultMeshConfig())
+
+	// the configs are translated by Higress already, so they are not validated again like the controller does
+	store := memory.NewSyncController(memory.MakeSkipValidation(collections.PilotGatewayAPI()))
+	env.ConfigStore = store
+
+	// the endpoints of the service entries are written into the endpoint index like the discovery server does
+	xdsUpdater := istiomodel.NewEndpointIndexUpdater(env.EndpointIndex)
+	serviceEntries := serviceentry.NewController(store, xdsUpdater, env.Watcher,
+		serviceentry.WithClusterID(istiocluster.ID(constants.DefaultClusterName)))
+	registries := aggregate.NewController(aggregate.Options{
+		MeshHolder: env,
+	})
+	registries.AddRegistry(serviceregistry.Simple{
+		ClusterID:        istiocluster.ID(constants.DefaultClusterName),
+		ProviderID:       provider.Kubernetes,
+		ServiceDiscovery: memregistry.NewServiceDiscovery(services...),
+	})
+	registries.AddRegistry(serviceEntries)
+	env.ServiceDiscovery = registries
+	env.Init()
+
+	for _, c := range configs {
+		if _, err := store.Create(c); err != nil {
+			return nil, fmt.Errorf("failed to add %s %s/%s: %v", c.GroupVersionKind.Kind, c.Namespace, c.Name, err)
+		}
+	}
+	serviceEntries.ResyncEDS()
+
+	push := istiomodel.NewPushContext()
+	if err := push.InitContext(env, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to init push context: %v", err)
+	}
+	env.SetPushContext(push)
+	return &xdsBuilder{
+		env:  env,
+		push: push,
+		gen:  core.NewConfigGenerator(&istiomodel.DisabledCache{}),
+	}, nil
+}
+
+// gatewayProxy returns the gateway selected by the options, set up for the push like a connected proxy.
+func (b *xdsBuilder) gatewayProxy(opts Options) *istiomodel.Proxy {
+	labels := map[string]string{opts.GatewaySelectorKey: opts.GatewaySelectorValue}
+	proxy := &istiomodel.Proxy{
+		Type:            istiomodel.Router,
+		ID:              "render." + opts.SystemNamespace,
+		IPAddresses:     []string{"127.0.0.1"},
+		ConfigNamespace: opts.SystemNamespace,
+		DNSDomain:       opts.SystemNamespace + ".svc." + constants.DefaultClusterLocalDomain,
+		Labels:          labels,
+		Metadata: &istiomodel.NodeMetadata{
+			Namespace: opts.SystemNamespace,
+			Labels:    labels,
+		},
+		IstioVersion: istiomodel.ParseIstioVersion(""),
+	}
+	proxy.DiscoverIPMode()
+	proxy.SetWorkloadLabels(b.env)
+	proxy.SetServiceTargets(b.env.ServiceDiscovery)
+	proxy.SetSidecarScope(b.push)
+	proxy.SetGatewaysForProxy(b.push)
+	return proxy
+}
+
+func (b *xdsBuilder) build(proxy *istiomodel.Proxy, result *Result) error {
+	result.Listeners = b.gen.BuildListeners(proxy, b.push)
+
+	clusters, _ := b.gen.BuildClusters(proxy, &istiomodel.PushRequest{Push: b.push, Full: true, Forced: true})
+	for _, r := range clusters {
+		c := &cluster.Cluster{}
+		if err := unmarshalResource(r, c); err != nil {
+			return err
+		}
+		result.Clusters = append(result.Clusters, c)
+	}
+
+	routes, _ := b.gen.BuildHTTPRoutes(proxy, &istiomodel.PushRequest{Push: b.push, Full: true, Forced: true},
+		routeNames(result.Listeners))
+	for _, r := range routes {
+		rc := &route.RouteConfiguration{}
+		if err := unmarshalResource(r, rc); err != nil {
+			return err
+		}
+		result.Routes = append(result.Routes, rc)
+	}
+	return nil
+}
+
+func unmarshalResource(r *discovery.Resource, m proto.Message) error {
+	if err := r.GetResource().UnmarshalTo(m); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %v", r.GetName(), err)
+	}
+	return nil
+}
+
+// routeNames returns the route configs the http connection managers of the listeners refer to by RDS, which are
+// requested by the gateway after the listeners.
+func routeNames(listeners []*listener.Listener) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, l := range listeners {
+		chains := append([]*listener.FilterChain{}, l.GetFilterChains()...)
+		if l.GetDefaultFilterChain() != nil {
+			chains = append(chains, l.GetDefaultFilterChain())
+		}
+		for _, chain := range chains {
+			for _, filter := range chain.GetFilters() {
+				manager := &hcm.HttpConnectionManager{}
+				if !filter.GetTypedConfig().MessageIs(manager) || filter.GetTypedConfig().UnmarshalTo(manager) != nil {
+					continue
+				}
+				name := manager.GetRds().GetRouteConfigName()
+				if name != "" && !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,436 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filesource loads Kubernetes manifests from a directory into a fake kube client, so that the
+// controllers of Higress can run without a Kubernetes API server. The directory is watched and changes
+// are applied to the client as create, update and delete events.
+package filesource
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"istio.io/istio/pkg/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	gatewayscheme "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
+
+	higressscheme "github.com/alibaba/higress/v2/client/pkg/clientset/versioned/scheme"
+	higresskube "github.com/alibaba/higress/v2/pkg/kube"
+)
+
+var fileLog = log.RegisterScope("file-source", "Higress file config source.")
+
+const (
+	// DefaultNamespace is used for namespaced objects that do not specify a namespace.
+	DefaultNamespace = "default"
+
+	reloadDebounce = 100 * time.Millisecond
+)
+
+var (
+	scheme = runtime.NewScheme()
+	codecs serializer.CodecFactory
+
+	// clusterScopedKinds are the supported kinds which have no namespace.
+	clusterScopedKinds = map[string]bool{
+		"Namespace":    true,
+		"IngressClass": true,
+		"GatewayClass": true,
+	}
+)
+
+func init() {
+	utilruntime.Must(kubescheme.AddToScheme(scheme))
+	utilruntime.Must(gatewayscheme.AddToScheme(scheme))
+	utilruntime.Must(higressscheme.AddToScheme(scheme))
+	codecs = serializer.NewCodecFactory(scheme)
+}
+
+// FileStatus is the result of loading a file.
+type FileStatus struct {
+	Path    string `json:"path"`
+	Objects int    `json:"objects"`
+	Error   string `json:"error,omitempty"`
+}
+
+type objectKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+func (k objectKey) String() string {
+	if k.namespace == "" {
+		return fmt.Sprintf("%s %s", k.gvk.Kind, k.name)
+	}
+	return fmt.Sprintf("%s %s/%s", k.gvk.Kind, k.namespace, k.name)
+}
+
+type entry struct {
+	file   string
+	raw    []byte
+	object runtime.Object
+}
+
+// Source watches a directory of YAML or JSON manifests and keeps the objects of a fake kube client in
+// sync with it. Ingress, Gateway API, Higress CRDs, Secrets, ConfigMaps and other core resources are
+// supported. Files are loaded independently: a file that fails to load is reported in the status and
+// the objects previously loaded from it are kept, so a bad edit does not remove working config.
+type Source struct {
+	dir    string
+	client higresskube.Client
+
+	mu      sync.RWMutex
+	objects map[objectKey]*entry
+	status  map[string]*FileStatus
+}
+
+// NewSource creates a source for dir. The client must be a fake client created by higresskube.NewFakeClient.
+func NewSource(dir string, client higresskube.Client) (*Source, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &Source{
+		dir:     dir,
+		client:  client,
+		objects: make(map[objectKey]*entry),
+		status:  make(map[string]*FileStatus),
+	}, nil
+}
+
+// Status returns the load result of every file, sorted by path.
+func (s *Source) Status() []FileStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]FileStatus, 0, len(s.status))
+	for _, status := range s.status {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// Load reads all the files in the directory and applies the difference with the last load to the client.
+// Errors of single files are reported by Status, only a failure to read the directory is returned.
+func (s *Source) Load() error {
+	files, err := s.listFiles()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	desired := make(map[objectKey]*entry)
+	status := make(map[string]*FileStatus, len(files))
+	for _, file := range files {
+		fileStatus := &FileStatus{Path: file}
+		status[file] = fileStatus
+		entries, err := s.loadFile(file, desired)
+		if err != nil {
+			fileLog.Errorf("failed to load %s: %v", file, err)
+			fileStatus.Error = err.Error()
+			// Keep the objects loaded from the file before
+			entries = make(map[objectKey]*entry)
+			for key, e := range s.objects {
+				if _, exist := desired[key]; !exist && e.file == file {
+					entries[key] = e
+				}
+			}
+		}
+		for key, e := range entries {
+			desired[key] = e
+		}
+		fileStatus.Objects = len(entries)
+	}
+
+	applied := make(map[objectKey]*entry, len(desired))
+	for key, e := range desired {
+		prev, exist := s.objects[key]
+		if exist && bytes.Equal(prev.raw, e.raw) {
+			applied[key] = prev
+			continue
+		}
+		if err := s.apply(key, e, exist); err != nil {
+			fileLog.Errorf("failed to apply %s from %s: %v", key, e.file, err)
+			status[e.file].Error = err.Error()
+			status[e.file].Objects--
+			if exist {
+				applied[key] = prev
+			}
+			continue
+		}
+		applied[key] = e
+	}
+	for key, prev := range s.objects {
+		if _, exist := desired[key]; exist {
+			continue
+		}
+		if err := s.delete(key); err != nil {
+			fileLog.Errorf("failed to delete %s: %v", key, err)
+			continue
+		}
+		fileLog.Infof("deleted %s, it was loaded from %s", key, prev.file)
+	}
+	s.objects = applied
+	s.status = status
+	return nil
+}
+
+// Run watches the directory and reloads it on change until stop is closed.
+func (s *Source) Run(stop <-chan struct{}) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fileLog.Errorf("failed to create file watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+	if err := s.watchDirs(watcher); err != nil {
+		fileLog.Errorf("failed to watch %s: %v", s.dir, err)
+		return
+	}
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = s.watchDirs(watcher)
+				}
+			}
+			// Editors usually write a file with several events, wait for them to settle
+			reload = time.After(reloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fileLog.Warnf("file watcher error: %v", err)
+		case <-reload:
+			reload = nil
+			if err := s.Load(); err != nil {
+				fileLog.Errorf("failed to reload %s: %v", s.dir, err)
+			}
+		}
+	}
+}
+
+func (s *Source) watchDirs(watcher *fsnotify.Watcher) error {
+	return filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != s.dir && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+func (s *Source) listFiles() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == s.dir {
+			return nil
+		}
+		// Skip hidden files such as editor swap files and the ..data links of mounted ConfigMaps
+		if isHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// loadFile parses the objects in a file. The whole file is rejected if any of its objects is invalid or
+// has already been defined by another file.
+func (s *Source) loadFile(file string, loaded map[objectKey]*entry) (map[objectKey]*entry, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[objectKey]*entry)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for i := 0; ; i++ {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		key, obj, err := decode(doc)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		if _, exist := entries[key]; exist {
+			return nil, fmt.Errorf("document %d: %s is defined more than once", i, key)
+		}
+		if prev, exist := loaded[key]; exist {
+			return nil, fmt.Errorf("document %d: %s is already defined in %s", i, key, prev.file)
+		}
+		entries[key] = &entry{file: file, raw: doc, object: obj}
+	}
+	return entries, nil
+}
+
+func decode(doc []byte) (objectKey, runtime.Object, error) {
+	obj, gvk, err := codecs.UniversalDeserializer().Decode(doc, nil, nil)
+	if err != nil {
+		return objectKey{}, nil, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return objectKey{}, nil, err
+	}
+	if accessor.GetName() == "" {
+		return objectKey{}, nil, fmt.Errorf("%s has no name", gvk.Kind)
+	}
+	if clusterScopedKinds[gvk.Kind] {
+		accessor.SetNamespace("")
+	} else if accessor.GetNamespace() == "" {
+		accessor.SetNamespace(DefaultNamespace)
+	}
+	if secret, ok := obj.(*corev1.Secret); ok && len(secret.StringData) > 0 {
+		// The API server merges stringData into data, do the same here
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte, len(secret.StringData))
+		}
+		for k, v := range secret.StringData {
+			secret.Data[k] = []byte(v)
+		}
+		secret.StringData = nil
+	}
+	return objectKey{gvk: *gvk, namespace: accessor.GetNamespace(), name: accessor.GetName()}, obj, nil
+}
+
+type trackerProvider interface {
+	Tracker() clienttesting.ObjectTracker
+}
+
+func (s *Source) tracker(gvk schema.GroupVersionKind) (clienttesting.ObjectTracker, error) {
+	var clientset interface{}
+	switch {
+	case higressscheme.Scheme.Recognizes(gvk):
+		clientset = s.client.Higress()
+	case gatewayscheme.Scheme.Recognizes(gvk):
+		clientset = s.client.GatewayAPI()
+	default:
+		clientset = s.client.Kube()
+	}
+	provider, ok := clientset.(trackerProvider)
+	if !ok {
+		return nil, errors.New("the kube client is not a fake client")
+	}
+	return provider.Tracker(), nil
+}
+
+func (s *Source) apply(key objectKey, e *entry, update bool) error {
+	tracker, err := s.tracker(key.gvk)
+	if err != nil {
+		return err
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(key.gvk)
+	accessor, err := meta.Accessor(e.object)
+	if err != nil {
+		return err
+	}
+	if !update {
+		accessor.SetUID(uuid.NewUUID())
+		accessor.SetCreationTimestamp(metav1.Now())
+		if err := tracker.Create(gvr, e.object, key.namespace); err != nil {
+			return err
+		}
+		fileLog.Infof("created %s from %s", key, e.file)
+		return nil
+	}
+	existing, err := tracker.Get(gvr, key.namespace, key.name)
+	if err != nil {
+		return err
+	}
+	existingAccessor, err := meta.Accessor(existing)
+	if err != nil {
+		return err
+	}
+	accessor.SetUID(existingAccessor.GetUID())
+	accessor.SetCreationTimestamp(existingAccessor.GetCreationTimestamp())
+	accessor.SetResourceVersion(existingAccessor.GetResourceVersion())
+	if err := tracker.Update(gvr, e.object, key.namespace); err != nil {
+		return err
+	}
+	fileLog.Infof("updated %s from %s", key, e.file)
+	return nil
+}
+
+func (s *Source) delete(key objectKey) error {
+	tracker, err := s.tracker(key.gvk)
+	if err != nil {
+		return err
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(key.gvk)
+	return tracker.Delete(gvr, key.namespace, key.name)
+}
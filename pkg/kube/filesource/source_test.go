@@ -0,0 +1,228 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	higresskube "github.com/alibaba/higress/v2/pkg/kube"
+)
+
+const ingressYaml = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: foo
+  namespace: higress-system
+spec:
+  ingressClassName: higress
+  rules:
+  - host: foo.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: foo
+            port:
+              number: 80
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: foo-tls
+stringData:
+  tls.crt: cert
+`
+
+const mcpBridgeYaml = `
+apiVersion: networking.higress.io/v1
+kind: McpBridge
+metadata:
+  name: default
+  namespace: higress-system
+spec:
+  registries:
+  - name: foo
+    type: dns
+    domain: foo.com
+    port: 80
+`
+
+const gatewayYaml = `
+apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: higress-gateway
+spec:
+  controllerName: higress.io/gateway-controller
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gateway
+  namespace: higress-system
+spec:
+  gatewayClassName: higress-gateway
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "ingress.yaml", ingressYaml)
+	writeFile(t, dir, "mcpbridge.yml", mcpBridgeYaml)
+	writeFile(t, dir, "gateway.yaml", gatewayYaml)
+	writeFile(t, dir, "README.md", "not a manifest")
+	writeFile(t, dir, ".ingress.yaml.swp", "not a manifest")
+
+	client := higresskube.NewFakeClient()
+	source, err := NewSource(dir, client)
+	require.NoError(t, err)
+	require.NoError(t, source.Load())
+
+	ctx := context.Background()
+	ingress, err := client.Kube().NetworkingV1().Ingresses("higress-system").Get(ctx, "foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "foo.com", ingress.Spec.Rules[0].Host)
+	assert.NotEmpty(t, ingress.UID)
+
+	secret, err := client.Kube().CoreV1().Secrets(DefaultNamespace).Get(ctx, "foo-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), secret.Data["tls.crt"])
+
+	mcpBridge, err := client.Higress().NetworkingV1().McpBridges("higress-system").Get(ctx, "default", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "foo.com", mcpBridge.Spec.Registries[0].Domain)
+
+	_, err = client.GatewayAPI().GatewayV1().GatewayClasses().Get(ctx, "higress-gateway", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = client.GatewayAPI().GatewayV1().Gateways("higress-system").Get(ctx, "gateway", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []FileStatus{
+		{Path: filepath.Join(dir, "gateway.yaml"), Objects: 2},
+		{Path: filepath.Join(dir, "ingress.yaml"), Objects: 2},
+		{Path: filepath.Join(dir, "mcpbridge.yml"), Objects: 1},
+	}, source.Status())
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "ingress.yaml", ingressYaml)
+	writeFile(t, dir, "mcpbridge.yaml", mcpBridgeYaml)
+
+	client := higresskube.NewFakeClient()
+	source, err := NewSource(dir, client)
+	require.NoError(t, err)
+	require.NoError(t, source.Load())
+
+	ctx := context.Background()
+	ingresses := client.Kube().NetworkingV1().Ingresses("higress-system")
+	ingress, err := ingresses.Get(ctx, "foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	uid := ingress.UID
+
+	t.Run("update", func(t *testing.T) {
+		writeFile(t, dir, "ingress.yaml", ingressYaml[:len(ingressYaml)-len("cert\n")]+"new-cert\n")
+		require.NoError(t, source.Load())
+		secret, err := client.Kube().CoreV1().Secrets(DefaultNamespace).Get(ctx, "foo-tls", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("new-cert"), secret.Data["tls.crt"])
+		ingress, err := ingresses.Get(ctx, "foo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, uid, ingress.UID)
+	})
+
+	t.Run("invalid file keeps loaded objects", func(t *testing.T) {
+		writeFile(t, dir, "ingress.yaml", ingressYaml+"---\nkind: Unknown\napiVersion: foo.io/v1\nmetadata:\n  name: bar\n")
+		require.NoError(t, source.Load())
+		_, err := ingresses.Get(ctx, "foo", metav1.GetOptions{})
+		require.NoError(t, err)
+		status := source.Status()
+		require.Len(t, status, 2)
+		assert.Equal(t, 2, status[0].Objects)
+		assert.Contains(t, status[0].Error, "document 2")
+	})
+
+	t.Run("duplicate object", func(t *testing.T) {
+		writeFile(t, dir, "ingress.yaml", ingressYaml)
+		writeFile(t, dir, "mcpbridge2.yaml", mcpBridgeYaml)
+		require.NoError(t, source.Load())
+		status := source.Status()
+		require.Len(t, status, 3)
+		assert.Empty(t, status[1].Error)
+		assert.Contains(t, status[2].Error, "already defined in "+filepath.Join(dir, "mcpbridge.yaml"))
+		require.NoError(t, os.Remove(filepath.Join(dir, "mcpbridge2.yaml")))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "ingress.yaml")))
+		require.NoError(t, source.Load())
+		_, err := ingresses.Get(ctx, "foo", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+		_, err = client.Higress().NetworkingV1().McpBridges("higress-system").Get(ctx, "default", metav1.GetOptions{})
+		require.NoError(t, err)
+	})
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	client := higresskube.NewFakeClient()
+	source, err := NewSource(dir, client)
+	require.NoError(t, err)
+	require.NoError(t, source.Load())
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go source.Run(stop)
+	// Wait for the watcher to be added
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "higress"), 0o755))
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, filepath.Join(dir, "higress"), "mcpbridge.yaml", mcpBridgeYaml)
+	assert.Eventually(t, func() bool {
+		_, err := client.Higress().NetworkingV1().McpBridges("higress-system").Get(context.Background(), "default", metav1.GetOptions{})
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestNewSource(t *testing.T) {
+	_, err := NewSource(filepath.Join(t.TempDir(), "not-exist"), higresskube.NewFakeClient())
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "ingress.yaml", ingressYaml)
+	_, err = NewSource(filepath.Join(dir, "ingress.yaml"), higresskube.NewFakeClient())
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+)
+
+// pluginMeta is the part of the spec.yaml generated by `hgctl plugin build` used for validation
+type pluginMeta struct {
+	Info struct {
+		Name string `json:"name"`
+	} `json:"info"`
+	Spec struct {
+		ConfigSchema struct {
+			OpenAPIV3Schema *spec.Schema `json:"openAPIV3Schema"`
+		} `json:"configSchema"`
+	} `json:"spec"`
+}
+
+// PluginSchemas holds the config schemas of the wasm plugins keyed by plugin name.
+type PluginSchemas struct {
+	schemas map[string]*pluginSchema
+}
+
+type pluginSchema struct {
+	path string
+	// schema of the default config
+	global *spec.Schema
+	// schema of the config of match rules, which may omit the required fields provided by the default config
+	rule *spec.Schema
+}
+
+// LoadPluginSchemas loads the spec.configSchema of every yaml file under dir, the files without a config schema
+// are skipped.
+func LoadPluginSchemas(dir string) (*PluginSchemas, error) {
+	schemas := &PluginSchemas{schemas: map[string]*pluginSchema{}}
+	if dir == "" {
+		return schemas, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		meta := &pluginMeta{}
+		if err := yaml.Unmarshal(content, meta); err != nil {
+			WebhookLog.Debugf("skip %s which is not a plugin spec: %v", path, err)
+			return nil
+		}
+		schema := meta.Spec.ConfigSchema.OpenAPIV3Schema
+		if meta.Info.Name == "" || schema == nil {
+			return nil
+		}
+		if existing, ok := schemas.schemas[meta.Info.Name]; ok {
+			return fmt.Errorf("schema of plugin %s is defined in both %s and %s", meta.Info.Name, existing.path, path)
+		}
+		ruleSchema := *schema
+		ruleSchema.Required = nil
+		schemas.schemas[meta.Info.Name] = &pluginSchema{
+			path:   path,
+			global: schema,
+			rule:   &ruleSchema,
+		}
+		WebhookLog.Infof("loaded config schema of plugin %s from %s", meta.Info.Name, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// Has tells whether the schema of the plugin is loaded.
+func (p *PluginSchemas) Has(plugin string) bool {
+	_, ok := p.schemas[plugin]
+	return ok
+}
+
+// Validate validates config against the schema of plugin, field is the path of config in the WasmPlugin.
+// Configs of plugins without a schema are not validated.
+func (p *PluginSchemas) Validate(plugin, field string, config map[string]interface{}, isRule bool) []string {
+	schema, ok := p.schemas[plugin]
+	if !ok || config == nil {
+		return nil
+	}
+	configSchema := schema.global
+	if isRule {
+		configSchema = schema.rule
+	}
+	// Normalize the numbers to float64 like the json decoded by the validator
+	b, err := json.Marshal(config)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", field, err)}
+	}
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return []string{fmt.Sprintf("%s: %v", field, err)}
+	}
+	result := validate.NewSchemaValidator(configSchema, nil, field, strfmt.Default).Validate(data)
+	if result.IsValid() {
+		return nil
+	}
+	var errs []string
+	for _, err := range result.Errors {
+		errs = append(errs, err.Error())
+	}
+	sort.Strings(errs)
+	return errs
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const maxRequestBodySize = 3 * 1024 * 1024
+
+type Options struct {
+	// Address is the https address serving the validating webhook
+	Address string
+	// CertFile and KeyFile are the serving certificate, they are reloaded on every TLS handshake so
+	// that rotated certificates take effect without restart.
+	CertFile string
+	KeyFile  string
+	// PluginSchemaDir is the directory of the spec.yaml files of wasm plugins
+	PluginSchemaDir string
+	// IngressClass and WatchNamespace select the ingresses to validate, the same as the ingress controller
+	IngressClass   string
+	WatchNamespace string
+}
+
+// Server serves the validating admission webhook of Ingress and WasmPlugin at /validate.
+type Server struct {
+	opts       Options
+	validator  *Validator
+	httpServer *http.Server
+}
+
+func NewServer(opts Options) (*Server, error) {
+	schemas, err := LoadPluginSchemas(opts.PluginSchemaDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wasm plugin schemas: %v", err)
+	}
+	s := &Server{
+		opts:      opts,
+		validator: NewValidator(schemas, opts.IngressClass, opts.WatchNamespace),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", s.serveValidate)
+	s.httpServer = &http.Server{
+		Addr:              opts.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: s.getCertificate,
+		},
+	}
+	return s, nil
+}
+
+func (s *Server) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(s.opts.CertFile, s.opts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (s *Server) Run(stop <-chan struct{}) error {
+	go func() {
+		<-stop
+		_ = s.httpServer.Close()
+	}()
+	WebhookLog.Infof("starting validating webhook at %s", s.opts.Address)
+	if err := s.httpServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serveValidate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	request := review.Request
+	response := &admissionv1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+	if request.Operation == admissionv1.Create || request.Operation == admissionv1.Update {
+		if err := s.validator.Validate(request.Kind, request.Object.Raw); err != nil {
+			WebhookLog.Infof("reject %s %s/%s: %v", request.Kind.Kind, request.Namespace, request.Name, err)
+			response.Allowed = false
+			response.Result = &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonInvalid,
+				Code:    http.StatusUnprocessableEntity,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	review.Request = nil
+	review.Response = response
+	b, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
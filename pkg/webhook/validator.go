@@ -0,0 +1,173 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/v2/pkg/ingress/kube/util"
+)
+
+// PluginNameLabel is set by hgctl and the console on the WasmPlugins to tell which plugin is used
+const PluginNameLabel = "higress.io/wasm-plugin-name"
+
+type ingressObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		IngressClassName *string `json:"ingressClassName"`
+	} `json:"spec"`
+}
+
+type wasmPlugin struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Url                  string                 `json:"url"`
+		DefaultConfig        map[string]interface{} `json:"defaultConfig"`
+		DefaultConfigDisable bool                   `json:"defaultConfigDisable"`
+		MatchRules           []struct {
+			Ingress       []string               `json:"ingress"`
+			Domain        []string               `json:"domain"`
+			Service       []string               `json:"service"`
+			Config        map[string]interface{} `json:"config"`
+			ConfigDisable bool                   `json:"configDisable"`
+		} `json:"matchRules"`
+	} `json:"spec"`
+}
+
+// Validator checks the objects with the same logic applied when they are translated.
+type Validator struct {
+	annotationHandler annotations.AnnotationHandler
+	schemas           *PluginSchemas
+	ingressClass      string
+	watchNamespace    string
+}
+
+func NewValidator(schemas *PluginSchemas, ingressClass, watchNamespace string) *Validator {
+	return &Validator{
+		annotationHandler: annotations.NewAnnotationHandlerManager(),
+		schemas:           schemas,
+		ingressClass:      ingressClass,
+		watchNamespace:    watchNamespace,
+	}
+}
+
+// Validate returns why the object of kind should be rejected, objects of unknown kinds are allowed.
+func (v *Validator) Validate(kind metav1.GroupVersionKind, raw []byte) error {
+	switch {
+	case kind.Group == "networking.k8s.io" && kind.Kind == "Ingress":
+		return v.validateIngress(raw)
+	case kind.Group == "extensions.higress.io" && kind.Kind == "WasmPlugin":
+		return v.validateWasmPlugin(raw)
+	}
+	return nil
+}
+
+func (v *Validator) validateIngress(raw []byte) error {
+	ingress := &ingressObject{}
+	if err := json.Unmarshal(raw, ingress); err != nil {
+		return fmt.Errorf("failed to decode ingress: %v", err)
+	}
+	if !v.shouldProcessIngress(ingress) {
+		return nil
+	}
+	config := &annotations.Ingress{
+		Meta: annotations.Meta{
+			Namespace:    ingress.Namespace,
+			Name:         ingress.Name,
+			RawClusterId: common.GetRawClusterId(ingress.Annotations),
+			ClusterId:    common.GetClusterId(ingress.Annotations),
+		},
+	}
+	return v.annotationHandler.Validate(ingress.Annotations, config)
+}
+
+// shouldProcessIngress filters the ingresses by class and namespace as the ingress controller does, the ones
+// translated by other ingress controllers are not validated.
+func (v *Validator) shouldProcessIngress(ingress *ingressObject) bool {
+	if v.watchNamespace != "" && v.watchNamespace != ingress.Namespace {
+		return false
+	}
+	if v.ingressClass == "" {
+		return true
+	}
+	if class, exists := ingress.Annotations[util.IngressClassAnnotation]; exists {
+		if v.ingressClass == common.DefaultIngressClass {
+			return class == "" || class == common.DefaultIngressClass
+		}
+		return class == v.ingressClass
+	}
+	className := ingress.Spec.IngressClassName
+	if v.ingressClass == common.DefaultIngressClass {
+		return className == nil || *className == "" || *className == common.DefaultIngressClass
+	}
+	return className != nil && *className == v.ingressClass
+}
+
+func (v *Validator) validateWasmPlugin(raw []byte) error {
+	plugin := &wasmPlugin{}
+	if err := json.Unmarshal(raw, plugin); err != nil {
+		return fmt.Errorf("failed to decode wasm plugin: %v", err)
+	}
+
+	var errs []string
+	name := pluginName(plugin)
+	if !plugin.Spec.DefaultConfigDisable {
+		errs = append(errs, v.schemas.Validate(name, "spec.defaultConfig", plugin.Spec.DefaultConfig, false)...)
+	}
+	for i, rule := range plugin.Spec.MatchRules {
+		field := fmt.Sprintf("spec.matchRules[%d]", i)
+		if len(rule.Ingress) == 0 && len(rule.Domain) == 0 && len(rule.Service) == 0 {
+			errs = append(errs, field+" should match at least one of ingress, domain and service")
+		}
+		if !rule.ConfigDisable {
+			errs = append(errs, v.schemas.Validate(name, field+".config", rule.Config, true)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, "\n"))
+}
+
+// pluginName finds out the plugin from the label set by hgctl and the console, or the image name of the url,
+// such as key-auth of oci://higress-registry.cn-hangzhou.cr.aliyuncs.com/plugins/key-auth:1.0.0
+func pluginName(plugin *wasmPlugin) string {
+	if name := plugin.Labels[PluginNameLabel]; name != "" {
+		return name
+	}
+	url := plugin.Spec.Url
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	if i := strings.Index(url, "@"); i >= 0 {
+		url = url[:i]
+	}
+	url = url[strings.LastIndex(url, "/")+1:]
+	if i := strings.Index(url, ":"); i >= 0 {
+		url = url[:i]
+	}
+	url = strings.TrimSuffix(url, ".wasm")
+	if url != "" {
+		return url
+	}
+	return plugin.Name
+}
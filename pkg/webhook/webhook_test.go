@@ -0,0 +1,230 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const keyAuthSpec = `
+apiVersion: 1.0.0
+info:
+  category: auth
+  name: key-auth
+  title: Key Auth
+spec:
+  phase: AUTHN
+  priority: 310
+  configSchema:
+    openAPIV3Schema:
+      type: object
+      properties:
+        keys:
+          type: array
+          items:
+            type: string
+        in_query:
+          type: boolean
+        allow:
+          type: array
+          items:
+            type: string
+      required:
+      - keys
+`
+
+var (
+	ingressKind    = metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	wasmPluginKind = metav1.GroupVersionKind{Group: "extensions.higress.io", Version: "v1alpha1", Kind: "WasmPlugin"}
+)
+
+func newTestServer(t *testing.T) *Server {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "key-auth"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key-auth", "spec.yaml"), []byte(keyAuthSpec), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key-auth", "option.yaml"), []byte("version: 1.0.0\n"), 0o644))
+	s, err := NewServer(Options{PluginSchemaDir: dir})
+	require.NoError(t, err)
+	return s
+}
+
+func TestLoadPluginSchemas(t *testing.T) {
+	s := newTestServer(t)
+	assert.True(t, s.validator.schemas.Has("key-auth"))
+	assert.False(t, s.validator.schemas.Has("basic-auth"))
+
+	schemas, err := LoadPluginSchemas("")
+	require.NoError(t, err)
+	assert.False(t, schemas.Has("key-auth"))
+}
+
+func TestValidateIngress(t *testing.T) {
+	v := NewValidator(&PluginSchemas{}, "higress", "")
+	ingress := func(namespace string, class *string, annotations map[string]string) []byte {
+		b, _ := json.Marshal(map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata":   map[string]interface{}{"name": "foo", "namespace": namespace, "annotations": annotations},
+			"spec":       map[string]interface{}{"ingressClassName": class},
+		})
+		return b
+	}
+	higress, nginx := "higress", "nginx"
+
+	assert.NoError(t, v.Validate(ingressKind, ingress("default", &higress, map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "10",
+	})))
+	invalid := map[string]string{
+		"higress.io/route-limit-rpm": "-1",
+	}
+	err := v.Validate(ingressKind, ingress("default", &higress, invalid))
+	assert.EqualError(t, err, `annotation higress.io/route-limit-rpm has invalid value "-1": must be positive`)
+
+	// Ingresses translated by other ingress controllers are not validated
+	assert.NoError(t, v.Validate(ingressKind, ingress("default", &nginx, invalid)))
+	assert.NoError(t, v.Validate(ingressKind, ingress("default", nil, invalid)))
+
+	v = NewValidator(&PluginSchemas{}, "higress", "higress-system")
+	assert.NoError(t, v.Validate(ingressKind, ingress("default", &higress, invalid)))
+	assert.Error(t, v.Validate(ingressKind, ingress("higress-system", &higress, invalid)))
+
+	// The default class also owns the ingresses without class
+	v = NewValidator(&PluginSchemas{}, "nginx", "")
+	assert.Error(t, v.Validate(ingressKind, ingress("default", nil, invalid)))
+	assert.NoError(t, v.Validate(ingressKind, ingress("default", &higress, invalid)))
+}
+
+func TestValidateWasmPlugin(t *testing.T) {
+	v := newTestServer(t).validator
+	testCases := []struct {
+		name   string
+		plugin string
+		expect []string
+	}{
+		{
+			name: "valid",
+			plugin: `{"metadata":{"name":"key-auth"},"spec":{"url":"oci://higress-registry.cn-hangzhou.cr.aliyuncs.com/plugins/key-auth:1.0.0",
+"defaultConfig":{"keys":["x-api-key"]},"matchRules":[{"ingress":["default/foo"],"config":{"allow":["consumer1"]}}]}}`,
+		},
+		{
+			name: "invalid default config",
+			plugin: `{"metadata":{"name":"auth"},"spec":{"url":"oci://higress-registry.cn-hangzhou.cr.aliyuncs.com/plugins/key-auth:1.0.0",
+"defaultConfig":{"in_query":"yes"}}}`,
+			expect: []string{
+				"spec.defaultConfig.in_query in body must be of type boolean",
+				"spec.defaultConfig.keys in body is required",
+			},
+		},
+		{
+			name: "disabled default config is not validated",
+			plugin: `{"metadata":{"name":"auth","labels":{"higress.io/wasm-plugin-name":"key-auth"}},"spec":{"url":"oci://foo.com/bar:1.0.0",
+"defaultConfig":{},"defaultConfigDisable":true}}`,
+		},
+		{
+			name: "invalid match rule",
+			plugin: `{"metadata":{"name":"key-auth"},"spec":{"url":"file:///opt/plugins/key-auth.wasm",
+"matchRules":[{"config":{"allow":"consumer1"}}]}}`,
+			expect: []string{
+				"spec.matchRules[0] should match at least one of ingress, domain and service",
+				"spec.matchRules[0].config.allow in body must be of type array",
+			},
+		},
+		{
+			name: "plugin without schema",
+			plugin: `{"metadata":{"name":"custom"},"spec":{"url":"oci://foo.com/custom:1.0.0",
+"defaultConfig":{"foo":"bar"}}}`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := v.Validate(wasmPluginKind, []byte(testCase.plugin))
+			if len(testCase.expect) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, expect := range testCase.expect {
+				assert.Contains(t, err.Error(), expect)
+			}
+		})
+	}
+}
+
+func TestServeValidate(t *testing.T) {
+	s := newTestServer(t)
+	review := func(operation admissionv1.Operation, object []byte) *admissionv1.AdmissionReview {
+		request := &admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Kind:      wasmPluginKind,
+				Operation: operation,
+				Object:    runtime.RawExtension{Raw: object},
+			},
+		}
+		body, _ := json.Marshal(request)
+		recorder := httptest.NewRecorder()
+		s.serveValidate(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		response := &admissionv1.AdmissionReview{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		return response
+	}
+
+	invalid := `{"metadata":{"name":"key-auth"},"spec":{"url":"oci://foo.com/key-auth:1.0.0","defaultConfig":{}}}`
+	response := review(admissionv1.Create, []byte(invalid))
+	assert.Equal(t, "AdmissionReview", response.Kind)
+	assert.Nil(t, response.Request)
+	assert.Equal(t, "uid", string(response.Response.UID))
+	assert.False(t, response.Response.Allowed)
+	assert.Equal(t, "spec.defaultConfig.keys in body is required", response.Response.Result.Message)
+
+	assert.True(t, review(admissionv1.Delete, nil).Response.Allowed)
+
+	recorder := httptest.NewRecorder()
+	s.serveValidate(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestPluginName(t *testing.T) {
+	testCases := []struct {
+		url    string
+		expect string
+	}{
+		{url: "oci://higress-registry.cn-hangzhou.cr.aliyuncs.com/plugins/key-auth:1.0.0", expect: "key-auth"},
+		{url: "oci://foo.com/plugins/basic-auth@sha256:abc", expect: "basic-auth"},
+		{url: "file:///opt/plugins/jwt-auth.wasm", expect: "jwt-auth"},
+		{url: "", expect: "foo"},
+	}
+	for _, testCase := range testCases {
+		plugin := &wasmPlugin{}
+		plugin.Name = "foo"
+		plugin.Spec.Url = testCase.url
+		assert.Equal(t, testCase.expect, pluginName(plugin), testCase.url)
+	}
+}
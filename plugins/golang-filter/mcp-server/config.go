@@ -48,6 +48,7 @@ func (p *Parser) Parse(any *anypb.Any, callbacks api.ConfigCallbackHandler) (int
 	conf := &config{
 		servers: make([]*SSEServerWrapper, 0),
 	}
+	instances := make([]common.ServerInstance, 0)
 
 	serverConfigs, ok := v.AsMap()["servers"].([]interface{})
 	if !ok {
@@ -110,6 +111,12 @@ func (p *Parser) Parse(any *anypb.Any, callbacks api.ConfigCallbackHandler) (int
 			return nil, fmt.Errorf("failed to initialize MCP Server: %w", err)
 		}
 
+		instances = append(instances, common.ServerInstance{
+			Name:   serverName,
+			Type:   serverType,
+			Path:   serverPath,
+			Server: serverInstance,
+		})
 		conf.servers = append(conf.servers, &SSEServerWrapper{
 			BaseServer: common.NewSSEServer(serverInstance,
 				common.WithSSEEndpoint(fmt.Sprintf("%s%s", serverPath, mcp_session.GlobalSSEPathSuffix)),
@@ -118,6 +125,7 @@ func (p *Parser) Parse(any *anypb.Any, callbacks api.ConfigCallbackHandler) (int
 		})
 		api.LogDebug(fmt.Sprintf("Registered MCP Server: %s", serverType))
 	}
+	common.GlobalRegistry.SetInstances(instances)
 
 	return conf, nil
 }
@@ -8,6 +8,7 @@ type Config struct {
 	LLM       LLMConfig       `json:"llm" yaml:"llm"`
 	Embedding EmbeddingConfig `json:"embedding" yaml:"embedding"`
 	VectorDB  VectorDBConfig  `json:"vectordb" yaml:"vectordb"`
+	Rerank    RerankConfig    `json:"rerank,omitempty" yaml:"rerank,omitempty"`
 }
 
 // RAGConfig contains basic configuration for the RAG system
@@ -15,11 +16,40 @@ type RAGConfig struct {
 	Splitter  SplitterConfig `json:"splitter" yaml:"splitter"`
 	Threshold float64        `json:"threshold,omitempty" yaml:"threshold,omitempty"`
 	TopK      int            `json:"top_k,omitempty" yaml:"top_k,omitempty"`
+	Hybrid    HybridConfig   `json:"hybrid,omitempty" yaml:"hybrid,omitempty"`
+}
+
+// HybridConfig defines configuration for hybrid keyword (BM25) and vector retrieval
+type HybridConfig struct {
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Number of candidates fetched from each retriever before fusion, defaults to 3 * top_k
+	CandidateTopK int `json:"candidate_top_k,omitempty" yaml:"candidate_top_k,omitempty"`
+	// Constant k of reciprocal rank fusion, defaults to 60
+	RRFK int `json:"rrf_k,omitempty" yaml:"rrf_k,omitempty"`
+	// Weights of each ranked list in the fusion, both default to 1
+	VectorWeight  float64 `json:"vector_weight,omitempty" yaml:"vector_weight,omitempty"`
+	KeywordWeight float64 `json:"keyword_weight,omitempty" yaml:"keyword_weight,omitempty"`
+	// BM25 parameters, default to k1 = 1.2 and b = 0.75
+	BM25K1 float64 `json:"bm25_k1,omitempty" yaml:"bm25_k1,omitempty"`
+	BM25B  float64 `json:"bm25_b,omitempty" yaml:"bm25_b,omitempty"`
+	// Interval in seconds to reload the in-memory keyword index from the vector store, defaults to 60.
+	// Not used by vector stores with native full text search (pgvector)
+	IndexRefreshInterval int `json:"index_refresh_interval,omitempty" yaml:"index_refresh_interval,omitempty"`
+}
+
+// RerankConfig defines configuration for rerank models
+type RerankConfig struct {
+	Provider  string  `json:"provider,omitempty" yaml:"provider,omitempty"` // Available options: cohere, dashscope
+	APIKey    string  `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	BaseURL   string  `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	Model     string  `json:"model,omitempty" yaml:"model,omitempty"`
+	TopN      int     `json:"top_n,omitempty" yaml:"top_n,omitempty"`
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
 }
 
 // SplitterConfig defines document splitter configuration
 type SplitterConfig struct {
-	Provider     string `json:"provider" yaml:"provider"` // Available options: recursive, character, token
+	Provider     string `json:"provider" yaml:"provider"` // Available options: recursive, markdown, nosplitter
 	ChunkSize    int    `json:"chunk_size,omitempty" yaml:"chunk_size,omitempty"`
 	ChunkOverlap int    `json:"chunk_overlap,omitempty" yaml:"chunk_overlap,omitempty"`
 }
@@ -45,13 +75,15 @@ type EmbeddingConfig struct {
 
 // VectorDBConfig defines configuration for vector databases
 type VectorDBConfig struct {
-	Provider   string        `json:"provider" yaml:"provider"` // Available options: milvus, qdrant, chroma
+	Provider   string        `json:"provider" yaml:"provider"` // Available options: milvus, pgvector, embedded
 	Host       string        `json:"host,omitempty" yaml:"host,omitempty"`
 	Port       int           `json:"port,omitempty" yaml:"port,omitempty"`
 	Database   string        `json:"database,omitempty" yaml:"database,omitempty"`
 	Collection string        `json:"collection,omitempty" yaml:"collection,omitempty"`
 	Username   string        `json:"username,omitempty" yaml:"username,omitempty"`
 	Password   string        `json:"password,omitempty" yaml:"password,omitempty"`
+	SSLMode    string        `json:"sslmode,omitempty" yaml:"sslmode,omitempty"` // Only used by pgvector
+	Path       string        `json:"path,omitempty" yaml:"path,omitempty"`       // Only used by embedded, empty means in-memory only
 	Mapping    MappingConfig `json:"mapping,omitempty" yaml:"mapping,omitempty"`
 }
 
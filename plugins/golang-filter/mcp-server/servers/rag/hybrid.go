@@ -0,0 +1,119 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+)
+
+const (
+	DEFAULT_RRF_K                          = 60
+	DEFAULT_CANDIDATE_MULTIPLIER           = 3
+	DEFAULT_KEYWORD_INDEX_REFRESH_INTERVAL = time.Minute
+)
+
+// rankedList is one ranked retrieval result list taking part in the fusion
+type rankedList struct {
+	results []schema.SearchResult
+	weight  float64
+}
+
+// fuseResults merges ranked lists with weighted reciprocal rank fusion: score(d) = sum(weight / (k + rank(d))).
+// The returned results are ordered by the fused score, which replaces the original retriever scores.
+func fuseResults(k int, lists ...rankedList) []schema.SearchResult {
+	if k <= 0 {
+		k = DEFAULT_RRF_K
+	}
+	scores := make(map[string]float64)
+	docs := make(map[string]schema.Document)
+	for _, list := range lists {
+		weight := list.weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for rank, result := range list.results {
+			id := result.Document.ID
+			scores[id] += weight / float64(k+rank+1)
+			if _, ok := docs[id]; !ok {
+				docs[id] = result.Document
+			}
+		}
+	}
+	fused := make([]schema.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		fused = append(fused, schema.SearchResult{
+			Document: docs[id],
+			Score:    score,
+		})
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Score == fused[j].Score {
+			return fused[i].Document.ID < fused[j].Document.ID
+		}
+		return fused[i].Score > fused[j].Score
+	})
+	return fused
+}
+
+// filterByThreshold drops results scoring below the threshold
+func filterByThreshold(results []schema.SearchResult, threshold float64) []schema.SearchResult {
+	if threshold <= 0 {
+		return results
+	}
+	filtered := results[:0]
+	for _, result := range results {
+		if result.Score >= threshold {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// rerankResults reorders candidates with the rerank provider, the relevance score replaces the candidate score
+func (r *RAGClient) rerankResults(ctx context.Context, query string, candidates []schema.SearchResult, topN int, threshold float64) ([]schema.SearchResult, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+	documents := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		documents = append(documents, candidate.Document.Content)
+	}
+	ranked, err := r.rerankProvider.Rerank(ctx, query, documents, topN)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]schema.SearchResult, 0, len(ranked))
+	for _, item := range ranked {
+		if item.Index < 0 || item.Index >= len(candidates) {
+			return nil, fmt.Errorf("rerank result index %d out of range", item.Index)
+		}
+		results = append(results, schema.SearchResult{
+			Document: candidates[item.Index].Document,
+			Score:    item.Score,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return filterByThreshold(results, threshold), nil
+}
+
+// isDistanceMetric reports whether smaller vector scores mean more similar, in which case the threshold does not apply
+func (r *RAGClient) isDistanceMetric() bool {
+	return strings.ToUpper(r.config.VectorDB.Mapping.Search.MetricType) == "L2"
+}
+
+// candidateTopK returns how many candidates each retriever fetches before fusion and rerank
+func (r *RAGClient) candidateTopK(topK int) int {
+	if !r.hybridEnabled() && r.rerankProvider == nil {
+		return topK
+	}
+	if r.config.RAG.Hybrid.CandidateTopK > topK {
+		return r.config.RAG.Hybrid.CandidateTopK
+	}
+	return topK * DEFAULT_CANDIDATE_MULTIPLIER
+}
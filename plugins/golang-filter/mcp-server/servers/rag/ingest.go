@@ -0,0 +1,215 @@
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/textsplitter"
+	"github.com/distribution/distribution/v3/uuid"
+	"github.com/envoyproxy/envoy/contrib/golang/common/go/api"
+)
+
+const (
+	METADATA_DOCUMENT_ID  = "document_id"
+	METADATA_SECTION_PATH = "section_path"
+	METADATA_CONTENT_HASH = "content_hash"
+	METADATA_FORMAT       = "format"
+)
+
+// IngestDocument splits a document with a splitter aware of its format and stores the chunks.
+// Chunks are identified by the hash of their content: re-ingesting a document with the same ID keeps
+// the chunks whose content is unchanged, embeds only the new ones and deletes the ones no longer present.
+// Unchanged chunks whose position or metadata moved are updated in place without being embedded again.
+// Repeated content within the document is stored once.
+func (r *RAGClient) IngestDocument(req *schema.IngestRequest) (*schema.IngestResult, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, fmt.Errorf("document content is empty")
+	}
+	if req.DocumentID == "" {
+		req.DocumentID = uuid.Generate().String()
+	}
+	if req.Title == "" {
+		req.Title = req.DocumentID
+	}
+	if req.Format == "" {
+		req.Format = textsplitter.FORMAT_TEXT
+	}
+	ctx := context.Background()
+
+	splitter, err := textsplitter.NewSectionSplitter(req.Format, req.Language, &r.config.RAG.Splitter)
+	if err != nil {
+		return nil, fmt.Errorf("create section splitter failed, err: %w", err)
+	}
+	sections, err := splitter.SplitSections(req.Content)
+	if err != nil {
+		return nil, fmt.Errorf("split document failed, err: %w", err)
+	}
+
+	existing, err := r.listDocumentChunks(ctx, req.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	existingByHash := make(map[string]schema.Document, len(existing))
+	for _, doc := range existing {
+		if hash, ok := doc.Metadata[METADATA_CONTENT_HASH].(string); ok {
+			existingByHash[hash] = doc
+		}
+	}
+
+	result := &schema.IngestResult{
+		DocumentID: req.DocumentID,
+		Chunks:     make([]schema.Document, 0),
+	}
+	seen := make(map[string]struct{}, len(sections))
+	updated := make([]schema.Document, 0)
+	chunkIndex := 0
+	for _, section := range sections {
+		content := strings.TrimSpace(section.Content)
+		if content == "" {
+			continue
+		}
+		hash := contentHash(content)
+		if _, ok := seen[hash]; ok {
+			result.Duplicates++
+			continue
+		}
+		seen[hash] = struct{}{}
+		index := chunkIndex
+		chunkIndex++
+
+		metadata := make(map[string]interface{}, len(req.Metadata)+8)
+		for key, value := range req.Metadata {
+			metadata[key] = value
+		}
+		metadata[METADATA_DOCUMENT_ID] = req.DocumentID
+		metadata[METADATA_SECTION_PATH] = section.PathString()
+		metadata[METADATA_CONTENT_HASH] = hash
+		metadata[METADATA_FORMAT] = strings.ToLower(req.Format)
+		metadata["chunk_index"] = index
+		metadata["chunk_title"] = req.Title
+		metadata["chunk_size"] = len(content)
+
+		if doc, ok := existingByHash[hash]; ok {
+			result.Unchanged++
+			if !sameMetadata(doc.Metadata, metadata) {
+				doc.Metadata = metadata
+				updated = append(updated, doc)
+			}
+			continue
+		}
+
+		embedding, err := r.embeddingProvider.GetEmbedding(ctx, content)
+		if err != nil {
+			return nil, fmt.Errorf("create embedding failed, err: %w", err)
+		}
+		result.Chunks = append(result.Chunks, schema.Document{
+			ID:        uuid.Generate().String(),
+			Content:   content,
+			Vector:    embedding,
+			Metadata:  metadata,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	// New chunks are stored before stale ones are removed, so a failed ingestion never leaves the document empty
+	if len(result.Chunks) > 0 {
+		if err := r.vectordbProvider.AddDoc(ctx, result.Chunks); err != nil {
+			return nil, fmt.Errorf("add documents failed, err: %w", err)
+		}
+		if r.keywordIndex != nil {
+			r.keywordIndex.Add(result.Chunks...)
+		}
+	}
+	result.Added = len(result.Chunks)
+
+	if len(updated) > 0 {
+		if err := r.vectordbProvider.UpdateDoc(ctx, updated); err != nil {
+			return nil, fmt.Errorf("update chunk metadata failed, err: %w", err)
+		}
+		if r.keywordIndex != nil {
+			r.keywordIndex.Add(updated...)
+		}
+	}
+
+	staleIDs := make([]string, 0)
+	for _, doc := range existing {
+		hash, _ := doc.Metadata[METADATA_CONTENT_HASH].(string)
+		if _, ok := seen[hash]; !ok {
+			staleIDs = append(staleIDs, doc.ID)
+		}
+	}
+	if err := r.deleteChunks(ctx, staleIDs); err != nil {
+		return nil, err
+	}
+	result.Deleted = len(staleIDs)
+
+	api.LogDebugf("RAG ingest document %s: added %d, unchanged %d (metadata updated %d), deleted %d, duplicates %d",
+		req.DocumentID, result.Added, result.Unchanged, len(updated), result.Deleted, result.Duplicates)
+	return result, nil
+}
+
+// DeleteDocument deletes all chunks of a document and returns the number of deleted chunks
+func (r *RAGClient) DeleteDocument(documentID string) (int, error) {
+	ctx := context.Background()
+	existing, err := r.listDocumentChunks(ctx, documentID)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]string, 0, len(existing))
+	for _, doc := range existing {
+		ids = append(ids, doc.ID)
+	}
+	if err := r.deleteChunks(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// listDocumentChunks lists all stored chunks belonging to a document, filtered by the vector store
+func (r *RAGClient) listDocumentChunks(ctx context.Context, documentID string) ([]schema.Document, error) {
+	chunks, err := r.vectordbProvider.QueryDocs(ctx, map[string]interface{}{METADATA_DOCUMENT_ID: documentID})
+	if err != nil {
+		return nil, fmt.Errorf("list chunks failed, err: %w", err)
+	}
+	return chunks, nil
+}
+
+// deleteChunks deletes chunks from the vector store and the keyword index
+func (r *RAGClient) deleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.vectordbProvider.DeleteDocs(ctx, ids); err != nil {
+		return fmt.Errorf("delete chunks failed, err: %w", err)
+	}
+	if r.keywordIndex != nil {
+		r.keywordIndex.Delete(ids...)
+	}
+	return nil
+}
+
+// sameMetadata reports whether two metadata maps hold the same values.
+// Values are compared by their string form since numbers read back from a vector store may change type
+func sameMetadata(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range b {
+		other, ok := a[key]
+		if !ok || fmt.Sprint(other) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// contentHash returns the hex encoded SHA-256 of the chunk content
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
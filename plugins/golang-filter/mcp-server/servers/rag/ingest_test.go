@@ -0,0 +1,25 @@
+package rag
+
+import "testing"
+
+func TestSameMetadata(t *testing.T) {
+	current := map[string]interface{}{"document_id": "doc1", "chunk_index": 1, "section_path": "A > B"}
+	tests := []struct {
+		name   string
+		stored map[string]interface{}
+		want   bool
+	}{
+		{"same values", map[string]interface{}{"document_id": "doc1", "chunk_index": 1, "section_path": "A > B"}, true},
+		{"number read back as float", map[string]interface{}{"document_id": "doc1", "chunk_index": float64(1), "section_path": "A > B"}, true},
+		{"chunk moved", map[string]interface{}{"document_id": "doc1", "chunk_index": float64(0), "section_path": "A > B"}, false},
+		{"section renamed", map[string]interface{}{"document_id": "doc1", "chunk_index": 1, "section_path": "A > C"}, false},
+		{"missing key", map[string]interface{}{"document_id": "doc1", "chunk_index": 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameMetadata(tt.stored, current); got != tt.want {
+				t.Errorf("sameMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
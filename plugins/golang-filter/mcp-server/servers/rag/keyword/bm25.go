@@ -0,0 +1,172 @@
+package keyword
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+)
+
+const (
+	DEFAULT_BM25_K1 = 1.2
+	DEFAULT_BM25_B  = 0.75
+)
+
+// bm25Entry holds an indexed document and its term frequencies
+type bm25Entry struct {
+	doc    schema.Document
+	terms  map[string]int
+	length int
+}
+
+// BM25Index is an in-memory Okapi BM25 keyword index over document chunks.
+// It is loaded from the vector store, updated whenever chunks are added or removed and periodically
+// reloaded so that chunks written by other replicas are indexed as well.
+type BM25Index struct {
+	mu          sync.RWMutex
+	k1          float64
+	b           float64
+	entries     map[string]*bm25Entry
+	docFreq     map[string]int
+	totalLength int
+}
+
+// NewBM25Index creates an empty BM25 index, non-positive parameters fall back to defaults
+func NewBM25Index(k1, b float64) *BM25Index {
+	if k1 <= 0 {
+		k1 = DEFAULT_BM25_K1
+	}
+	if b <= 0 {
+		b = DEFAULT_BM25_B
+	}
+	return &BM25Index{
+		k1:      k1,
+		b:       b,
+		entries: make(map[string]*bm25Entry),
+		docFreq: make(map[string]int),
+	}
+}
+
+// Add indexes documents, replacing any previously indexed document with the same ID
+func (idx *BM25Index) Add(docs ...schema.Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, doc := range docs {
+		idx.remove(doc.ID)
+		tokens := Tokenize(doc.Content)
+		terms := make(map[string]int, len(tokens))
+		for _, token := range tokens {
+			terms[token]++
+		}
+		for term := range terms {
+			idx.docFreq[term]++
+		}
+		// The vector is not needed for keyword search
+		doc.Vector = nil
+		idx.entries[doc.ID] = &bm25Entry{
+			doc:    doc,
+			terms:  terms,
+			length: len(tokens),
+		}
+		idx.totalLength += len(tokens)
+	}
+}
+
+// Replace replaces all indexed documents with docs, the new index is built before the lock is taken
+func (idx *BM25Index) Replace(docs ...schema.Document) {
+	fresh := NewBM25Index(idx.k1, idx.b)
+	fresh.Add(docs...)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries = fresh.entries
+	idx.docFreq = fresh.docFreq
+	idx.totalLength = fresh.totalLength
+}
+
+// Delete removes documents from the index by their IDs
+func (idx *BM25Index) Delete(ids ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, id := range ids {
+		idx.remove(id)
+	}
+}
+
+// remove removes a document from the index, must be called with the lock held
+func (idx *BM25Index) remove(id string) {
+	entry, ok := idx.entries[id]
+	if !ok {
+		return
+	}
+	for term := range entry.terms {
+		idx.docFreq[term]--
+		if idx.docFreq[term] <= 0 {
+			delete(idx.docFreq, term)
+		}
+	}
+	idx.totalLength -= entry.length
+	delete(idx.entries, id)
+}
+
+// Len returns the number of indexed documents
+func (idx *BM25Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.entries)
+}
+
+// Search returns up to topK documents ranked by BM25 score, restricted to documents matching filters
+func (idx *BM25Index) Search(query string, topK int, filters map[string]interface{}) []schema.SearchResult {
+	queryTerms := make(map[string]struct{})
+	for _, token := range Tokenize(query) {
+		queryTerms[token] = struct{}{}
+	}
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	total := float64(len(idx.entries))
+	if total == 0 {
+		return nil
+	}
+	avgLength := float64(idx.totalLength) / total
+
+	results := make([]schema.SearchResult, 0)
+	for _, entry := range idx.entries {
+		score := 0.0
+		for term := range queryTerms {
+			tf := float64(entry.terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(idx.docFreq[term])
+			idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+			norm := idx.k1 * (1 - idx.b + idx.b*float64(entry.length)/avgLength)
+			score += idf * tf * (idx.k1 + 1) / (tf + norm)
+		}
+		if score <= 0 {
+			continue
+		}
+		if !schema.MatchFilters(entry.doc.Metadata, filters) {
+			continue
+		}
+		results = append(results, schema.SearchResult{
+			Document: entry.doc,
+			Score:    score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Document.ID < results[j].Document.ID
+		}
+		return results[i].Score > results[j].Score
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
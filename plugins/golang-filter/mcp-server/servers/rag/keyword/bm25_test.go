@@ -0,0 +1,94 @@
+package keyword
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "plain words",
+			text: "Connection reset, retry later.",
+			want: []string{"connection", "reset", "retry", "later"},
+		},
+		{
+			name: "error code and config key",
+			text: "got ERR_CONN_RESET from server.max-connections",
+			want: []string{"got", "err_conn_reset", "err", "conn", "reset", "from", "server.max-connections", "server", "max", "connections"},
+		},
+		{
+			name: "han characters",
+			text: "限流配置",
+			want: []string{"限", "流", "限流", "配", "流配", "置", "配置"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBM25Index_Search(t *testing.T) {
+	idx := NewBM25Index(0, 0)
+	idx.Add(
+		schema.Document{ID: "1", Content: "How to configure rate limiting for the gateway", Metadata: map[string]interface{}{"source": "guide"}},
+		schema.Document{ID: "2", Content: "Error E1001 means the upstream connection was reset", Metadata: map[string]interface{}{"source": "runbook"}},
+		schema.Document{ID: "3", Content: "The gateway supports many plugins", Metadata: map[string]interface{}{"source": "guide"}},
+	)
+
+	results := idx.Search("what does E1001 mean", 10, nil)
+	if len(results) == 0 || results[0].Document.ID != "2" {
+		t.Fatalf("Search() = %+v, want doc 2 first", results)
+	}
+
+	results = idx.Search("gateway", 10, map[string]interface{}{"source": "guide"})
+	if len(results) != 2 {
+		t.Fatalf("Search() with filters len = %d, want 2", len(results))
+	}
+
+	results = idx.Search("gateway", 10, map[string]interface{}{"source": []interface{}{"runbook"}})
+	if len(results) != 0 {
+		t.Fatalf("Search() with unmatched filters len = %d, want 0", len(results))
+	}
+
+	idx.Delete("2")
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+	if results := idx.Search("E1001", 10, nil); len(results) != 0 {
+		t.Fatalf("Search() after delete = %+v, want empty", results)
+	}
+}
+
+func TestBM25Index_Replace(t *testing.T) {
+	idx := NewBM25Index(0, 0)
+	idx.Add(
+		schema.Document{ID: "1", Content: "Error E1001 means the upstream connection was reset"},
+		schema.Document{ID: "2", Content: "The gateway supports many plugins"},
+	)
+
+	idx.Replace(
+		schema.Document{ID: "2", Content: "The gateway supports many plugins"},
+		schema.Document{ID: "3", Content: "Error E2002 means the request timed out"},
+	)
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+	if results := idx.Search("E1001", 10, nil); len(results) != 0 {
+		t.Fatalf("Search() of replaced doc = %+v, want empty", results)
+	}
+	results := idx.Search("E2002", 10, nil)
+	if len(results) != 1 || results[0].Document.ID != "3" {
+		t.Fatalf("Search() = %+v, want doc 3", results)
+	}
+}
@@ -0,0 +1,61 @@
+package keyword
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits text into lowercase terms for keyword indexing.
+//
+// Besides plain words, identifier-like runs such as error codes (ERR_CONN_RESET, E1001) and
+// config keys (server.max-connections) are kept as whole terms so that they can be matched
+// exactly, and their parts are emitted as well. Han characters are indexed as unigrams and
+// bigrams because they are not separated by whitespace.
+func Tokenize(text string) []string {
+	var tokens []string
+	var run []rune
+
+	flushRun := func() {
+		if len(run) == 0 {
+			return
+		}
+		token := strings.Trim(string(run), identifierPunctuation)
+		run = run[:0]
+		if token == "" {
+			return
+		}
+		parts := strings.FieldsFunc(token, func(r rune) bool {
+			return strings.ContainsRune(identifierPunctuation, r)
+		})
+		if len(parts) != 1 || parts[0] != token {
+			tokens = append(tokens, token)
+		}
+		tokens = append(tokens, parts...)
+	}
+
+	var prevHan rune
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushRun()
+			tokens = append(tokens, string(r))
+			if prevHan != 0 {
+				tokens = append(tokens, string([]rune{prevHan, r}))
+			}
+			prevHan = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			run = append(run, r)
+		case strings.ContainsRune(identifierPunctuation, r) && len(run) > 0:
+			run = append(run, r)
+		default:
+			flushRun()
+		}
+		prevHan = 0
+	}
+	flushRun()
+	return tokens
+}
+
+// identifierPunctuation are the characters allowed inside identifier-like terms
+const identifierPunctuation = "_-.:/"
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/embedding"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/keyword"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/llm"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/rerank"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/textsplitter"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/vectordb"
@@ -28,6 +31,15 @@ type RAGClient struct {
 	embeddingProvider embedding.Provider
 	textSplitter      textsplitter.TextSplitter
 	llmProvider       llm.Provider
+	rerankProvider    rerank.Provider
+	// keywordSearcher is set when the vector store supports full text search natively,
+	// otherwise keywordIndex holds an in-memory BM25 index loaded from the vector store
+	keywordSearcher vectordb.KeywordSearcher
+	keywordIndex    *keyword.BM25Index
+	// keywordIndexLoadedAt is the unix nano time of the last keyword index load
+	keywordIndexLoadedAt int64
+	// keywordIndexLoading is 1 while the keyword index is being reloaded in the background
+	keywordIndexLoading int32
 }
 
 // NewRAGClient creates a new RAG client instance
@@ -67,9 +79,81 @@ func NewRAGClient(config *config.Config) (*RAGClient, error) {
 		return nil, fmt.Errorf("create vector store provider failed, err: %w", err)
 	}
 	ragclient.vectordbProvider = provider
+
+	if ragclient.config.Rerank.Provider != "" {
+		api.LogDebugf("RAG New Rerank Provider: %+v", ragclient.config.Rerank)
+		rerankProvider, err := rerank.NewRerankProvider(ragclient.config.Rerank)
+		if err != nil {
+			return nil, fmt.Errorf("create rerank provider failed, err: %w", err)
+		}
+		ragclient.rerankProvider = rerankProvider
+	}
+
+	if ragclient.config.RAG.Hybrid.Enable {
+		if searcher, ok := provider.(vectordb.KeywordSearcher); ok {
+			ragclient.keywordSearcher = searcher
+		} else {
+			hybrid := ragclient.config.RAG.Hybrid
+			ragclient.keywordIndex = keyword.NewBM25Index(hybrid.BM25K1, hybrid.BM25B)
+			if err := ragclient.loadKeywordIndex(); err != nil {
+				return nil, fmt.Errorf("build keyword index failed, err: %w", err)
+			}
+		}
+	}
 	return ragclient, nil
 }
 
+// loadKeywordIndex (re)loads the BM25 keyword index from all the chunks stored in the vector store
+func (r *RAGClient) loadKeywordIndex() error {
+	loadedAt := time.Now()
+	docs, err := r.vectordbProvider.QueryDocs(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	r.keywordIndex.Replace(docs...)
+	atomic.StoreInt64(&r.keywordIndexLoadedAt, loadedAt.UnixNano())
+	api.LogDebugf("RAG keyword index loaded with %d chunks", len(docs))
+	return nil
+}
+
+// refreshKeywordIndex reloads the in-memory keyword index in the background once it is older than the
+// refresh interval, so that the chunks written or deleted through other replicas are eventually indexed
+func (r *RAGClient) refreshKeywordIndex() {
+	interval := time.Duration(r.config.RAG.Hybrid.IndexRefreshInterval) * time.Second
+	if interval <= 0 {
+		interval = DEFAULT_KEYWORD_INDEX_REFRESH_INTERVAL
+	}
+	if time.Since(time.Unix(0, atomic.LoadInt64(&r.keywordIndexLoadedAt))) < interval {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&r.keywordIndexLoading, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&r.keywordIndexLoading, 0)
+		if err := r.loadKeywordIndex(); err != nil {
+			api.LogWarnf("RAG reload keyword index failed, err: %v", err)
+		}
+	}()
+}
+
+// keywordSearch searches chunks by keyword, using the vector store full text search when supported
+func (r *RAGClient) keywordSearch(ctx context.Context, query string, topK int, filters map[string]interface{}) ([]schema.SearchResult, error) {
+	if r.keywordSearcher != nil {
+		return r.keywordSearcher.KeywordSearch(ctx, query, &schema.SearchOptions{
+			TopK:    topK,
+			Filters: filters,
+		})
+	}
+	r.refreshKeywordIndex()
+	return r.keywordIndex.Search(query, topK, filters), nil
+}
+
+// hybridEnabled reports whether keyword retrieval takes part in the search
+func (r *RAGClient) hybridEnabled() bool {
+	return r.keywordSearcher != nil || r.keywordIndex != nil
+}
+
 // ListChunks lists document chunks by knowledge ID, returns in ascending order of DocumentIndex
 func (r *RAGClient) ListChunks() ([]schema.Document, error) {
 	docs, err := r.vectordbProvider.ListDocs(context.Background(), MAX_LIST_DOCUMENT_ROW_COUNT)
@@ -84,6 +168,9 @@ func (r *RAGClient) DeleteChunk(id string) error {
 	if err := r.vectordbProvider.DeleteDocs(context.Background(), []string{id}); err != nil {
 		return fmt.Errorf("delete chunk failed, err: %w", err)
 	}
+	if r.keywordIndex != nil {
+		r.keywordIndex.Delete(id)
+	}
 	return nil
 }
 
@@ -114,35 +201,87 @@ func (r *RAGClient) CreateChunkFromText(text string, title string) ([]schema.Doc
 	if err := r.vectordbProvider.AddDoc(context.Background(), results); err != nil {
 		return nil, fmt.Errorf("add documents failed, err: %w", err)
 	}
+	if r.keywordIndex != nil {
+		r.keywordIndex.Add(results...)
+	}
 
 	return results, nil
 }
 
-// SearchChunks searches for document chunks
-func (r *RAGClient) SearchChunks(query string, topK int, threshold float64) ([]schema.SearchResult, error) {
+// SearchChunks searches for document chunks, combining vector and keyword retrieval when hybrid search
+// is enabled and reranking the candidates when a rerank provider is configured.
+// Zero valued options fall back to the configured defaults.
+func (r *RAGClient) SearchChunks(query string, options *schema.SearchOptions) ([]schema.SearchResult, error) {
+	opts := schema.SearchOptions{
+		TopK:            r.config.RAG.TopK,
+		Threshold:       r.config.RAG.Threshold,
+		RerankThreshold: r.config.Rerank.Threshold,
+	}
+	if options != nil {
+		if options.TopK > 0 {
+			opts.TopK = options.TopK
+		}
+		if options.Threshold > 0 {
+			opts.Threshold = options.Threshold
+		}
+		if options.RerankThreshold > 0 {
+			opts.RerankThreshold = options.RerankThreshold
+		}
+		opts.Filters = options.Filters
+	}
+	ctx := context.Background()
+	candidateTopK := r.candidateTopK(opts.TopK)
 
-	vector, err := r.embeddingProvider.GetEmbedding(context.Background(), query)
+	vector, err := r.embeddingProvider.GetEmbedding(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("create embedding failed, err: %w", err)
 	}
-	options := &schema.SearchOptions{
-		TopK:      topK,
-		Threshold: threshold,
-	}
-	docs, err := r.vectordbProvider.SearchDocs(context.Background(), vector, options)
+	docs, err := r.vectordbProvider.SearchDocs(ctx, vector, &schema.SearchOptions{
+		TopK:    candidateTopK,
+		Filters: opts.Filters,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("search chunks failed, err: %w", err)
 	}
+	if !r.isDistanceMetric() {
+		docs = filterByThreshold(docs, opts.Threshold)
+	}
+
+	if r.hybridEnabled() {
+		keywordDocs, err := r.keywordSearch(ctx, query, candidateTopK, opts.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("search chunks by keyword failed, err: %w", err)
+		}
+		docs = fuseResults(r.config.RAG.Hybrid.RRFK,
+			rankedList{results: docs, weight: r.config.RAG.Hybrid.VectorWeight},
+			rankedList{results: keywordDocs, weight: r.config.RAG.Hybrid.KeywordWeight},
+		)
+	}
+
+	if r.rerankProvider != nil {
+		topN := opts.TopK
+		if r.config.Rerank.TopN > 0 {
+			topN = r.config.Rerank.TopN
+		}
+		docs, err = r.rerankResults(ctx, query, docs, topN, opts.RerankThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("rerank chunks failed, err: %w", err)
+		}
+	}
+
+	if len(docs) > opts.TopK {
+		docs = docs[:opts.TopK]
+	}
 	return docs, nil
 }
 
-// Chat generates a response using LLM
-func (r *RAGClient) Chat(query string) (string, error) {
+// Chat generates a response using LLM, options control the retrieval of the context chunks
+func (r *RAGClient) Chat(query string, options *schema.SearchOptions) (string, error) {
 	if r.llmProvider == nil {
 		return "", fmt.Errorf("llm provider not initialized")
 	}
 
-	docs, err := r.SearchChunks(query, r.config.RAG.TopK, r.config.RAG.Threshold)
+	docs, err := r.SearchChunks(query, options)
 	if err != nil {
 		return "", fmt.Errorf("search chunks failed, err: %w", err)
 	}
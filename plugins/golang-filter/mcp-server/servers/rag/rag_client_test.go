@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
 )
 
 func getRAGClient() (*RAGClient, error) {
@@ -164,7 +165,7 @@ func TestRAGClient_SearchChunks(t *testing.T) {
 	topk := 2
 	threshold := 0.5
 	query := "multi-agent"
-	docs, err := ragClient.SearchChunks(query, topk, threshold)
+	docs, err := ragClient.SearchChunks(query, &schema.SearchOptions{TopK: topk, Threshold: threshold})
 	if err != nil {
 		t.Errorf("SearchChunks() error = %v", err)
 		return
@@ -187,7 +188,7 @@ func TestRAGClient_Chat(t *testing.T) {
 	// query := "Who is the figure associated with generative AI technology whose departure from OpenAI was considered shocking according to Fortune, and is also the subject of a prevailing theory suggesting a lack of full truthfulness with the board as reported by TechCrunch?"
 	// query := "Do the TechCrunch article on software companies and the Hacker News article on The Epoch Times both report an increase in revenue related to payment and subscription models, respectively?"
 	query := "Which online betting platform provides a welcome bonus of up to $1000 in bonus bets for new customers' first losses, runs NBA betting promotions, and is anticipated to extend the same sign-up offer to new users in Vermont, as reported by both CBSSports.com and Sporting News?"
-	resp, err := ragClient.Chat(query)
+	resp, err := ragClient.Chat(query, nil)
 	if err != nil {
 		t.Errorf("Chat() error = %v", err)
 		return
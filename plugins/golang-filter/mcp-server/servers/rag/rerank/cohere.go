@@ -0,0 +1,87 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/common"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+)
+
+const (
+	COHERE_DEFAULT_BASE_URL = "https://api.cohere.com/v1"
+	COHERE_DEFAULT_MODEL    = "rerank-v3.5"
+)
+
+type cohereProviderInitializer struct{}
+
+func (c *cohereProviderInitializer) validateConfig(cfg *config.RerankConfig) error {
+	if cfg.APIKey == "" {
+		return errors.New("[cohere rerank] apiKey is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = COHERE_DEFAULT_BASE_URL
+	}
+	if cfg.Model == "" {
+		cfg.Model = COHERE_DEFAULT_MODEL
+	}
+	return nil
+}
+
+func (c *cohereProviderInitializer) CreateProvider(cfg config.RerankConfig) (Provider, error) {
+	if err := c.validateConfig(&cfg); err != nil {
+		return nil, err
+	}
+	client := common.NewHTTPClient(strings.TrimSuffix(cfg.BaseURL, "/"), map[string]string{
+		"Authorization": "Bearer " + cfg.APIKey,
+	})
+	return &CohereProvider{
+		client: client,
+		model:  cfg.Model,
+	}, nil
+}
+
+// CohereProvider calls a Cohere compatible POST {base_url}/rerank endpoint
+type CohereProvider struct {
+	client *common.HTTPClient
+	model  string
+}
+
+type cohereRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type cohereRerankResponse struct {
+	Results []Result `json:"results"`
+}
+
+func (c *CohereProvider) GetProviderType() string {
+	return PROVIDER_TYPE_COHERE
+}
+
+// Rerank scores documents against the query
+func (c *CohereProvider) Rerank(ctx context.Context, query string, documents []string, topN int) ([]Result, error) {
+	if len(documents) == 0 {
+		return []Result{}, nil
+	}
+	body, err := c.client.Post("/rerank", cohereRerankRequest{
+		Model:     c.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      topN,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank documents: %w", err)
+	}
+	var resp cohereRerankResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+	return resp.Results, nil
+}
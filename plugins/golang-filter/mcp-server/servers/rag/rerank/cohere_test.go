@@ -0,0 +1,47 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+)
+
+func TestCohereProvider_Rerank(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected authorization header %s", r.Header.Get("Authorization"))
+		}
+		var req cohereRerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request failed: %v", err)
+		}
+		if req.Query != "E1001" || len(req.Documents) != 2 || req.TopN != 1 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.98}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewRerankProvider(config.RerankConfig{
+		Provider: PROVIDER_TYPE_COHERE,
+		APIKey:   "sk-test",
+		BaseURL:  server.URL + "/",
+	})
+	if err != nil {
+		t.Fatalf("NewRerankProvider() error = %v", err)
+	}
+	results, err := provider.Rerank(context.Background(), "E1001", []string{"gateway plugins", "error E1001"}, 1)
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Index != 1 || results[0].Score != 0.98 {
+		t.Errorf("Rerank() = %+v, want index 1 with score 0.98", results)
+	}
+}
@@ -0,0 +1,94 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/common"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+)
+
+const (
+	DASHSCOPE_DEFAULT_BASE_URL = "https://dashscope.aliyuncs.com/api/v1"
+	DASHSCOPE_DEFAULT_MODEL    = "gte-rerank-v2"
+	DASHSCOPE_RERANK_PATH      = "/services/rerank/text-rerank/text-rerank"
+)
+
+type dashScopeProviderInitializer struct{}
+
+func (d *dashScopeProviderInitializer) validateConfig(cfg *config.RerankConfig) error {
+	if cfg.APIKey == "" {
+		return errors.New("[dashscope rerank] apiKey is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DASHSCOPE_DEFAULT_BASE_URL
+	}
+	if cfg.Model == "" {
+		cfg.Model = DASHSCOPE_DEFAULT_MODEL
+	}
+	return nil
+}
+
+func (d *dashScopeProviderInitializer) CreateProvider(cfg config.RerankConfig) (Provider, error) {
+	if err := d.validateConfig(&cfg); err != nil {
+		return nil, err
+	}
+	client := common.NewHTTPClient(strings.TrimSuffix(cfg.BaseURL, "/"), map[string]string{
+		"Authorization": "Bearer " + cfg.APIKey,
+	})
+	return &DashScopeProvider{
+		client: client,
+		model:  cfg.Model,
+	}, nil
+}
+
+// DashScopeProvider calls the DashScope text rerank API used by Qwen gte-rerank models
+type DashScopeProvider struct {
+	client *common.HTTPClient
+	model  string
+}
+
+type dashScopeRerankRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Query     string   `json:"query"`
+		Documents []string `json:"documents"`
+	} `json:"input"`
+	Parameters struct {
+		TopN            int  `json:"top_n,omitempty"`
+		ReturnDocuments bool `json:"return_documents"`
+	} `json:"parameters"`
+}
+
+type dashScopeRerankResponse struct {
+	Output struct {
+		Results []Result `json:"results"`
+	} `json:"output"`
+}
+
+func (d *DashScopeProvider) GetProviderType() string {
+	return PROVIDER_TYPE_DASHSCOPE
+}
+
+// Rerank scores documents against the query
+func (d *DashScopeProvider) Rerank(ctx context.Context, query string, documents []string, topN int) ([]Result, error) {
+	if len(documents) == 0 {
+		return []Result{}, nil
+	}
+	req := dashScopeRerankRequest{Model: d.model}
+	req.Input.Query = query
+	req.Input.Documents = documents
+	req.Parameters.TopN = topN
+	body, err := d.client.Post(DASHSCOPE_RERANK_PATH, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank documents: %w", err)
+	}
+	var resp dashScopeRerankResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+	return resp.Output.Results, nil
+}
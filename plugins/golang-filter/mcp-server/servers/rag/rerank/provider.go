@@ -0,0 +1,53 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+)
+
+const (
+	// Cohere compatible /rerank API, also served by Jina, Xinference, vLLM and others
+	PROVIDER_TYPE_COHERE = "cohere"
+	// DashScope text rerank API for Qwen gte-rerank models
+	PROVIDER_TYPE_DASHSCOPE = "dashscope"
+)
+
+// Result is the relevance of one input document, Index refers to the position in the input documents
+type Result struct {
+	Index int     `json:"index"`
+	Score float64 `json:"relevance_score"`
+}
+
+// Provider defines the interface for rerank services
+type Provider interface {
+	// Returns the provider type identifier
+	GetProviderType() string
+	// Scores documents against the query and returns at most topN results ordered by descending relevance
+	Rerank(ctx context.Context, query string, documents []string, topN int) ([]Result, error)
+}
+
+// Factory interface for creating Provider instances
+type providerInitializer interface {
+	// Creates a new Provider with the given configuration
+	CreateProvider(config.RerankConfig) (Provider, error)
+}
+
+// Maps provider types to their initializers
+var (
+	providerInitializers = map[string]providerInitializer{
+		PROVIDER_TYPE_COHERE:    &cohereProviderInitializer{},
+		PROVIDER_TYPE_DASHSCOPE: &dashScopeProviderInitializer{},
+	}
+)
+
+// Creates a new rerank Provider based on the configuration
+// Returns error if provider type is not supported
+func NewRerankProvider(cfg config.RerankConfig) (Provider, error) {
+	initializer, ok := providerInitializers[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("no initializer found for rerank provider type: %s", cfg.Provider)
+	}
+	return initializer.CreateProvider(cfg)
+}
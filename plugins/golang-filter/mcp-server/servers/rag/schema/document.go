@@ -40,7 +40,37 @@ type Knowledge struct {
 
 // SearchOptions contains options for vector search
 type SearchOptions struct {
-	TopK      int                    `json:"top_k"`
-	Threshold float64                `json:"threshold"`
-	Filters   map[string]interface{} `json:"filters,omitempty"`
+	TopK      int     `json:"top_k"`
+	Threshold float64 `json:"threshold"`
+	// RerankThreshold is the minimum relevance score of reranked results, only used when a rerank provider is configured
+	RerankThreshold float64 `json:"rerank_threshold,omitempty"`
+	// Filters restricts results by metadata, a scalar value matches by equality and a list value matches any of its elements
+	Filters map[string]interface{} `json:"filters,omitempty"`
+}
+
+// IngestRequest describes a document to be split with a structure-aware splitter and stored as chunks
+type IngestRequest struct {
+	// DocumentID identifies the document, ingesting again with the same ID replaces its chunks
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	// Format is one of text, markdown, html, json, yaml and code
+	Format string `json:"format"`
+	// Language is the programming language of code documents
+	Language string                 `json:"language,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// IngestResult summarizes the chunk changes made by ingesting a document
+type IngestResult struct {
+	DocumentID string `json:"document_id"`
+	// Added is the number of new chunks embedded and stored
+	Added int `json:"added"`
+	// Unchanged is the number of existing chunks kept because their content did not change
+	Unchanged int `json:"unchanged"`
+	// Deleted is the number of existing chunks removed because their content is no longer in the document
+	Deleted int `json:"deleted"`
+	// Duplicates is the number of chunks skipped because the same content appears earlier in the document
+	Duplicates int        `json:"duplicates"`
+	Chunks     []Document `json:"chunks"`
 }
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// MatchFilters reports whether metadata satisfies all filters.
+// A scalar filter value matches by equality, a list filter value matches any of its elements.
+func MatchFilters(metadata map[string]interface{}, filters map[string]interface{}) bool {
+	for key, expected := range filters {
+		actual, ok := metadata[key]
+		if !ok {
+			return false
+		}
+		if !matchFilterValue(actual, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFilterValue(actual, expected interface{}) bool {
+	if candidates, ok := expected.([]interface{}); ok {
+		for _, candidate := range candidates {
+			if filterValueEqual(actual, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+	return filterValueEqual(actual, expected)
+}
+
+func filterValueEqual(a, b interface{}) bool {
+	if x, ok := toFloat64(a); ok {
+		if y, ok := toFloat64(b); ok {
+			return x == y
+		}
+		return false
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -121,6 +121,32 @@ func (c *RAGConfig) ParseConfig(cfg map[string]any) error {
 		if topK, exists := ragConfig["top_k"].(float64); exists {
 			c.config.RAG.TopK = int(topK)
 		}
+		if hybrid, exists := ragConfig["hybrid"].(map[string]any); exists {
+			if enable, exists := hybrid["enable"].(bool); exists {
+				c.config.RAG.Hybrid.Enable = enable
+			}
+			if candidateTopK, exists := hybrid["candidate_top_k"].(float64); exists {
+				c.config.RAG.Hybrid.CandidateTopK = int(candidateTopK)
+			}
+			if rrfK, exists := hybrid["rrf_k"].(float64); exists {
+				c.config.RAG.Hybrid.RRFK = int(rrfK)
+			}
+			if vectorWeight, exists := hybrid["vector_weight"].(float64); exists {
+				c.config.RAG.Hybrid.VectorWeight = vectorWeight
+			}
+			if keywordWeight, exists := hybrid["keyword_weight"].(float64); exists {
+				c.config.RAG.Hybrid.KeywordWeight = keywordWeight
+			}
+			if k1, exists := hybrid["bm25_k1"].(float64); exists {
+				c.config.RAG.Hybrid.BM25K1 = k1
+			}
+			if b, exists := hybrid["bm25_b"].(float64); exists {
+				c.config.RAG.Hybrid.BM25B = b
+			}
+			if refreshInterval, exists := hybrid["index_refresh_interval"].(float64); exists {
+				c.config.RAG.Hybrid.IndexRefreshInterval = int(refreshInterval)
+			}
+		}
 	}
 
 	// Parse Embedding configuration
@@ -169,6 +195,29 @@ func (c *RAGConfig) ParseConfig(cfg map[string]any) error {
 		}
 	}
 
+	// Parse rerank configuration
+	api.LogDebugf("RAG parse rerank config")
+	if rerankConfig, ok := cfg["rerank"].(map[string]any); ok {
+		if provider, exists := rerankConfig["provider"].(string); exists {
+			c.config.Rerank.Provider = provider
+		}
+		if apiKey, exists := rerankConfig["api_key"].(string); exists {
+			c.config.Rerank.APIKey = apiKey
+		}
+		if baseURL, exists := rerankConfig["base_url"].(string); exists {
+			c.config.Rerank.BaseURL = baseURL
+		}
+		if model, exists := rerankConfig["model"].(string); exists {
+			c.config.Rerank.Model = model
+		}
+		if topN, exists := rerankConfig["top_n"].(float64); exists {
+			c.config.Rerank.TopN = int(topN)
+		}
+		if threshold, exists := rerankConfig["threshold"].(float64); exists {
+			c.config.Rerank.Threshold = threshold
+		}
+	}
+
 	// Parse VectorDB configuration
 	api.LogDebugf("RAG parse vectordb config")
 	if vectordbConfig, ok := cfg["vectordb"].(map[string]any); ok {
@@ -195,6 +244,12 @@ func (c *RAGConfig) ParseConfig(cfg map[string]any) error {
 		if password, exists := vectordbConfig["password"].(string); exists {
 			c.config.VectorDB.Password = password
 		}
+		if sslMode, exists := vectordbConfig["sslmode"].(string); exists {
+			c.config.VectorDB.SSLMode = sslMode
+		}
+		if path, exists := vectordbConfig["path"].(string); exists {
+			c.config.VectorDB.Path = path
+		}
 
 		// Parse mapping here
 		if mapping, exists := vectordbConfig["mapping"].(map[string]any); exists {
@@ -274,6 +329,14 @@ func (c *RAGConfig) NewServer(serverName string) (*common.MCPServer, error) {
 		mcp.NewToolWithRawSchema("create-chunks-from-text", "Process and segment input text into semantic chunks for knowledge base ingestion", GetCreateChunkFromTextSchema()),
 		HandleCreateChunkFromText(ragClient),
 	)
+	mcpServer.AddTool(
+		mcp.NewToolWithRawSchema("ingest-document", "Ingest a Markdown, HTML, JSON, YAML, source code or text document with structure-aware chunking, replacing the chunks of a previous version with the same document ID", GetIngestDocumentSchema()),
+		HandleIngestDocument(ragClient),
+	)
+	mcpServer.AddTool(
+		mcp.NewToolWithRawSchema("delete-document", "Remove all knowledge chunks of a document using its document ID", GetDeleteDocumentSchema()),
+		HandleDeleteDocument(ragClient),
+	)
 
 	// Chunk Management Tools
 	mcpServer.AddTool(
@@ -0,0 +1,185 @@
+package textsplitter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// codeDeclarationPatterns match the lines starting a top-level declaration, the non-empty
+// submatches joined by "." form the symbol name used as the section path.
+var codeDeclarationPatterns = map[string][]*regexp.Regexp{
+	"go": {
+		regexp.MustCompile(`^func\s*\(\s*\w*\s*\*?\s*(\w+)[^)]*\)\s*(\w+)`),
+		regexp.MustCompile(`^func\s+(\w+)`),
+		regexp.MustCompile(`^(?:type|var|const)\s+(\w+)`),
+		regexp.MustCompile(`^(type|var|const)\s*\(`),
+	},
+	"python": {
+		regexp.MustCompile(`^(?:async\s+)?(?:def|class)\s+(\w+)`),
+	},
+	"javascript": {
+		regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?(?:function\*?|class|const|let|var)\s+([\w$]+)`),
+	},
+	"typescript": {
+		regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:function\*?|class|interface|type|enum|const|let|var|namespace)\s+([\w$]+)`),
+	},
+	"java": {
+		regexp.MustCompile(`^(?:(?:public|protected|private|abstract|final|static|sealed)\s+)*(?:class|interface|enum|record|@interface)\s+(\w+)`),
+	},
+	"rust": {
+		regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?impl(?:<[^>]*>)?\s+(?:[\w:<>]+\s+for\s+)?([\w:]+)`),
+		regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?(?:fn|struct|enum|trait|mod|type|const|static|macro_rules!)\s*(\w+)`),
+	},
+	"shell": {
+		regexp.MustCompile(`^(?:function\s+)?([\w-]+)\s*\(\)\s*\{?`),
+	},
+}
+
+// codeLanguageAliases maps common language names and file extensions to the supported languages.
+var codeLanguageAliases = map[string]string{
+	"golang": "go",
+	"py":     "python",
+	"js":     "javascript",
+	"jsx":    "javascript",
+	"ts":     "typescript",
+	"tsx":    "typescript",
+	"rs":     "rust",
+	"sh":     "shell",
+	"bash":   "shell",
+}
+
+// codeCommentPrefixes are the line prefixes of comments and decorators attached to the following declaration.
+var codeCommentPrefixes = []string{"//", "#", "/*", "*", "@"}
+
+// CodeSplitter is a text splitter for source code that splits by top-level declarations, keeping the
+// comments directly above a declaration with it. The section path is the declaration symbol, code before
+// the first declaration (package clause, imports) has an empty path. Declarations larger than the chunk
+// size are split by blank lines and then lines. Unsupported languages fall back to splitting by blank lines.
+type CodeSplitter struct {
+	Language     string
+	ChunkSize    int
+	ChunkOverlap int
+	LenFunc      func(string) int
+}
+
+// NewCodeSplitter creates a new source code splitter with default values.
+func NewCodeSplitter(language string, opts ...Option) CodeSplitter {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	language = strings.ToLower(language)
+	if alias, ok := codeLanguageAliases[language]; ok {
+		language = alias
+	}
+	return CodeSplitter{
+		Language:     language,
+		ChunkSize:    options.ChunkSize,
+		ChunkOverlap: options.ChunkOverlap,
+		LenFunc:      options.LenFunc,
+	}
+}
+
+// SplitText splits source code into multiple text.
+func (s CodeSplitter) SplitText(text string) ([]string, error) {
+	sections, err := s.SplitSections(text)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]string, 0, len(sections))
+	for _, section := range sections {
+		chunks = append(chunks, section.Content)
+	}
+	return chunks, nil
+}
+
+// SplitSections splits source code into sections, one or more per top-level declaration.
+func (s CodeSplitter) SplitSections(text string) ([]Section, error) {
+	type declaration struct {
+		symbol string
+		lines  []string
+	}
+	patterns := codeDeclarationPatterns[s.Language]
+	declarations := []declaration{{}}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		symbol, ok := matchDeclaration(patterns, line)
+		if !ok {
+			last := &declarations[len(declarations)-1]
+			last.lines = append(last.lines, line)
+			continue
+		}
+		// Move the comments right above the declaration from the previous block into the new one
+		last := &declarations[len(declarations)-1]
+		start := len(last.lines)
+		for start > 0 && isCodeComment(last.lines[start-1]) {
+			start--
+		}
+		lines := append(append([]string{}, last.lines[start:]...), line)
+		last.lines = last.lines[:start]
+		declarations = append(declarations, declaration{symbol: symbol, lines: lines})
+	}
+
+	sections := make([]Section, 0, len(declarations))
+	for _, decl := range declarations {
+		content := strings.Trim(strings.Join(decl.lines, "\n"), "\n")
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		var path []string
+		if decl.symbol != "" {
+			path = []string{decl.symbol}
+		}
+		chunks, err := s.splitDeclaration(content)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range chunks {
+			sections = append(sections, Section{Path: path, Content: chunk})
+		}
+	}
+	return sections, nil
+}
+
+func (s CodeSplitter) splitDeclaration(content string) ([]string, error) {
+	if s.LenFunc(content) <= s.ChunkSize {
+		return []string{content}, nil
+	}
+	splitter := NewRecursiveCharacter(
+		WithChunkSize(s.ChunkSize),
+		WithChunkOverlap(s.ChunkOverlap),
+		WithLenFunc(s.LenFunc),
+		WithSeparators([]string{"\n\n", "\n", " ", ""}),
+	)
+	return splitter.SplitText(content)
+}
+
+func matchDeclaration(patterns []*regexp.Regexp, line string) (string, bool) {
+	for _, pattern := range patterns {
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		names := make([]string, 0, len(m)-1)
+		for _, name := range m[1:] {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, "."), true
+	}
+	return "", false
+}
+
+func isCodeComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	for _, prefix := range codeCommentPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package textsplitter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	htmlSpaceRegex     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	htmlBlankLineRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLSplitter is a text splitter for HTML documents. The document is converted to markdown,
+// keeping headings, preformatted code and tables, and then split with a MarkdownSplitter.
+// Scripts, styles and navigation elements are dropped.
+type HTMLSplitter struct {
+	markdown MarkdownSplitter
+}
+
+// NewHTMLSplitter creates a new HTML splitter with default values.
+func NewHTMLSplitter(opts ...Option) HTMLSplitter {
+	return HTMLSplitter{markdown: NewMarkdownSplitter(opts...)}
+}
+
+// SplitText splits a HTML text into multiple text.
+func (s HTMLSplitter) SplitText(text string) ([]string, error) {
+	markdown, err := HTMLToMarkdown(text)
+	if err != nil {
+		return nil, err
+	}
+	return s.markdown.SplitText(markdown)
+}
+
+// SplitSections splits a HTML text into sections, the section path is the heading hierarchy.
+func (s HTMLSplitter) SplitSections(text string) ([]Section, error) {
+	markdown, err := HTMLToMarkdown(text)
+	if err != nil {
+		return nil, err
+	}
+	return s.markdown.SplitSections(markdown)
+}
+
+// HTMLToMarkdown converts the structural elements of a HTML document to markdown.
+func HTMLToMarkdown(text string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse html: %w", err)
+	}
+	var sb strings.Builder
+	renderHTMLNode(&sb, doc)
+	result := htmlBlankLineRegex.ReplaceAllString(sb.String(), "\n\n")
+	return strings.TrimSpace(result), nil
+}
+
+func renderHTMLNode(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(htmlSpaceRegex.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+	default:
+		renderHTMLChildren(sb, n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Head, atom.Nav, atom.Svg:
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		sb.WriteString("\n\n" + strings.Repeat("#", level) + " " + htmlInlineText(n) + "\n\n")
+	case atom.Pre:
+		sb.WriteString("\n\n```" + htmlCodeLanguage(n) + "\n")
+		sb.WriteString(strings.TrimRight(htmlRawText(n), "\n"))
+		sb.WriteString("\n```\n\n")
+	case atom.Table:
+		sb.WriteString("\n\n")
+		renderHTMLTable(sb, n)
+		sb.WriteString("\n\n")
+	case atom.Br:
+		sb.WriteString("\n")
+	case atom.Li:
+		sb.WriteString("\n- ")
+		renderHTMLChildren(sb, n)
+		sb.WriteString("\n")
+	case atom.Code:
+		sb.WriteString("`" + htmlRawText(n) + "`")
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer,
+		atom.Blockquote, atom.Ul, atom.Ol, atom.Dl, atom.Dt, atom.Dd, atom.Figure, atom.Aside:
+		sb.WriteString("\n\n")
+		renderHTMLChildren(sb, n)
+		sb.WriteString("\n\n")
+	default:
+		renderHTMLChildren(sb, n)
+	}
+}
+
+func renderHTMLChildren(sb *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderHTMLNode(sb, c)
+	}
+}
+
+// renderHTMLTable writes a table as a markdown table, the first row becomes the header.
+func renderHTMLTable(sb *strings.Builder, table *html.Node) {
+	rows := make([][]string, 0)
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Tr:
+				cells := make([]string, 0)
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						cells = append(cells, strings.ReplaceAll(htmlInlineText(cell), "|", "\\|"))
+					}
+				}
+				rows = append(rows, cells)
+			default:
+				// thead, tbody, tfoot and nested tables are flattened into the outer table
+				collect(c)
+			}
+		}
+	}
+	collect(table)
+	for i, row := range rows {
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", max(len(row), 1)) + "\n")
+		}
+	}
+}
+
+// htmlInlineText returns the whitespace collapsed text content of a node.
+func htmlInlineText(n *html.Node) string {
+	return strings.TrimSpace(htmlSpaceRegex.ReplaceAllString(htmlRawText(n), " "))
+}
+
+// htmlRawText returns the text content of a node as is.
+func htmlRawText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			sb.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// htmlCodeLanguage returns the language of a pre element from a language-xxx class on it or its code child.
+func htmlCodeLanguage(pre *html.Node) string {
+	nodes := []*html.Node{pre}
+	if pre.FirstChild != nil && pre.FirstChild.DataAtom == atom.Code {
+		nodes = append(nodes, pre.FirstChild)
+	}
+	for _, n := range nodes {
+		for _, attr := range n.Attr {
+			if attr.Key != "class" {
+				continue
+			}
+			for _, class := range strings.Fields(attr.Val) {
+				if lang, ok := strings.CutPrefix(class, "language-"); ok {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
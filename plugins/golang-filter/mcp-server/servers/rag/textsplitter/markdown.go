@@ -0,0 +1,311 @@
+package textsplitter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeadingRegex   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownTableSepRegex  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	markdownFenceOpenRegex = regexp.MustCompile("^(```+|~~~+)")
+)
+
+type markdownBlockKind int
+
+const (
+	markdownParagraph markdownBlockKind = iota
+	markdownCode
+	markdownTable
+)
+
+// markdownBlock is the smallest unit the markdown splitter keeps together when possible.
+type markdownBlock struct {
+	kind  markdownBlockKind
+	lines []string
+}
+
+func (b markdownBlock) text() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// markdownSection collects the blocks under one heading.
+type markdownSection struct {
+	path   []string
+	blocks []markdownBlock
+}
+
+// MarkdownSplitter is a text splitter that splits markdown documents by headings. Fenced code
+// blocks and tables are kept whole unless they exceed the chunk size, in which case code is
+// split by lines and tables by rows with the table header repeated in every chunk.
+type MarkdownSplitter struct {
+	ChunkSize            int
+	ChunkOverlap         int
+	LenFunc              func(string) int
+	KeepHeadingHierarchy bool
+	JoinTableRows        bool
+}
+
+// NewMarkdownSplitter creates a new markdown splitter with default values.
+func NewMarkdownSplitter(opts ...Option) MarkdownSplitter {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return MarkdownSplitter{
+		ChunkSize:            options.ChunkSize,
+		ChunkOverlap:         options.ChunkOverlap,
+		LenFunc:              options.LenFunc,
+		KeepHeadingHierarchy: options.KeepHeadingHierarchy,
+		JoinTableRows:        options.JoinTableRows,
+	}
+}
+
+// SplitText splits a markdown text into multiple text.
+func (s MarkdownSplitter) SplitText(text string) ([]string, error) {
+	sections, err := s.SplitSections(text)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]string, 0, len(sections))
+	for _, section := range sections {
+		chunks = append(chunks, section.Content)
+	}
+	return chunks, nil
+}
+
+// SplitSections splits a markdown text into sections, the section path is the heading hierarchy.
+// When KeepHeadingHierarchy is set, every chunk is prefixed with its heading hierarchy instead of
+// containing the heading line itself.
+func (s MarkdownSplitter) SplitSections(text string) ([]Section, error) {
+	result := make([]Section, 0)
+	for _, section := range s.parse(text) {
+		prefix := ""
+		if s.KeepHeadingHierarchy && len(section.path) > 0 {
+			prefix = strings.Join(section.path, SectionPathSeparator) + "\n\n"
+		}
+		chunks, err := s.packBlocks(section.blocks)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range chunks {
+			result = append(result, Section{
+				Path:    section.path,
+				Content: prefix + chunk,
+			})
+		}
+	}
+	return result, nil
+}
+
+// parse groups the markdown lines into blocks under their headings.
+func (s MarkdownSplitter) parse(text string) []markdownSection {
+	type heading struct {
+		level int
+		title string
+	}
+	var (
+		sections = make([]markdownSection, 0)
+		headings = make([]heading, 0)
+		current  = markdownSection{}
+		block    *markdownBlock
+		fence    string
+	)
+
+	flushBlock := func() {
+		if block != nil && len(block.lines) > 0 {
+			current.blocks = append(current.blocks, *block)
+		}
+		block = nil
+	}
+	flushSection := func() {
+		flushBlock()
+		if len(current.blocks) > 0 {
+			sections = append(sections, current)
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			block.lines = append(block.lines, line)
+			if strings.HasPrefix(trimmed, fence) && strings.TrimLeft(trimmed, fence[:1]) == "" {
+				flushBlock()
+				fence = ""
+			}
+			continue
+		}
+
+		if m := markdownFenceOpenRegex.FindString(trimmed); m != "" {
+			flushBlock()
+			fence = m
+			block = &markdownBlock{kind: markdownCode, lines: []string{line}}
+			continue
+		}
+
+		if m := markdownHeadingRegex.FindStringSubmatch(trimmed); m != nil {
+			flushSection()
+			level := len(m[1])
+			for len(headings) > 0 && headings[len(headings)-1].level >= level {
+				headings = headings[:len(headings)-1]
+			}
+			headings = append(headings, heading{level: level, title: m[2]})
+			path := make([]string, 0, len(headings))
+			for _, h := range headings {
+				path = append(path, h.title)
+			}
+			current = markdownSection{path: path}
+			if !s.KeepHeadingHierarchy {
+				current.blocks = append(current.blocks, markdownBlock{kind: markdownParagraph, lines: []string{trimmed}})
+			}
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushBlock()
+		case strings.HasPrefix(trimmed, "|"):
+			if block != nil && block.kind != markdownTable {
+				flushBlock()
+			}
+			if block == nil {
+				block = &markdownBlock{kind: markdownTable}
+			}
+			block.lines = append(block.lines, trimmed)
+		default:
+			if block != nil && block.kind != markdownParagraph {
+				flushBlock()
+			}
+			if block == nil {
+				block = &markdownBlock{kind: markdownParagraph}
+			}
+			block.lines = append(block.lines, line)
+		}
+	}
+	// An unterminated fence still keeps its content
+	flushSection()
+	return sections
+}
+
+// packBlocks merges consecutive blocks into chunks close to the chunk size.
+func (s MarkdownSplitter) packBlocks(blocks []markdownBlock) ([]string, error) {
+	pieces := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		split, err := s.splitBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		pieces = append(pieces, split...)
+	}
+
+	chunks := make([]string, 0)
+	current := make([]string, 0)
+	total := 0
+	for _, piece := range pieces {
+		size := s.LenFunc(piece)
+		if len(current) > 0 && total+size+2 > s.ChunkSize {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current = current[:0]
+			total = 0
+		}
+		if len(current) > 0 {
+			total += 2
+		}
+		current = append(current, piece)
+		total += size
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n\n"))
+	}
+	return chunks, nil
+}
+
+// splitBlock splits a block into pieces, each no larger than the chunk size when possible.
+func (s MarkdownSplitter) splitBlock(block markdownBlock) ([]string, error) {
+	text := block.text()
+	switch block.kind {
+	case markdownTable:
+		if !s.JoinTableRows || s.LenFunc(text) > s.ChunkSize {
+			return s.splitTable(block.lines), nil
+		}
+		return []string{text}, nil
+	case markdownCode:
+		if s.LenFunc(text) > s.ChunkSize {
+			return s.splitCode(block.lines), nil
+		}
+		return []string{text}, nil
+	default:
+		return splitOversized(strings.TrimSpace(text), s.ChunkSize, s.ChunkOverlap, s.LenFunc)
+	}
+}
+
+// splitTable splits table rows into pieces, repeating the header in every piece.
+// Rows are joined up to the chunk size when JoinTableRows is set, otherwise every row is its own piece.
+func (s MarkdownSplitter) splitTable(lines []string) []string {
+	header := make([]string, 0, 2)
+	rows := lines
+	if len(lines) >= 2 && markdownTableSepRegex.MatchString(lines[1]) {
+		header = lines[:2]
+		rows = lines[2:]
+	}
+	if len(rows) == 0 {
+		return []string{strings.Join(lines, "\n")}
+	}
+	maxSize := s.ChunkSize
+	if !s.JoinTableRows {
+		maxSize = 0
+	}
+	return packLines(header, rows, nil, maxSize, s.LenFunc)
+}
+
+// splitCode splits a fenced code block by lines, every piece is wrapped in the original fence.
+func (s MarkdownSplitter) splitCode(lines []string) []string {
+	if len(lines) < 2 {
+		return []string{strings.Join(lines, "\n")}
+	}
+	open := lines[:1]
+	body := lines[1:]
+	closing := []string(nil)
+	if fence := markdownFenceOpenRegex.FindString(strings.TrimSpace(lines[0])); fence != "" {
+		last := strings.TrimSpace(body[len(body)-1])
+		if strings.HasPrefix(last, fence) {
+			closing = body[len(body)-1:]
+			body = body[:len(body)-1]
+		} else {
+			closing = []string{fence}
+		}
+	}
+	return packLines(open, body, closing, s.ChunkSize, s.LenFunc)
+}
+
+// packLines packs lines into pieces no larger than maxSize, every piece is wrapped with head and tail.
+// A piece always holds at least one line, so a single long line may exceed maxSize.
+func packLines(head, lines, tail []string, maxSize int, lenFunc func(string) int) []string {
+	fixed := lenFunc(strings.Join(head, "\n")) + lenFunc(strings.Join(tail, "\n")) + 2
+	pieces := make([]string, 0)
+	current := make([]string, 0)
+	total := fixed
+	flush := func() {
+		piece := make([]string, 0, len(head)+len(current)+len(tail))
+		piece = append(piece, head...)
+		piece = append(piece, current...)
+		piece = append(piece, tail...)
+		pieces = append(pieces, strings.Join(piece, "\n"))
+		current = current[:0]
+		total = fixed
+	}
+	for _, line := range lines {
+		size := lenFunc(line) + 1
+		if len(current) > 0 && total+size > maxSize {
+			flush()
+		}
+		current = append(current, line)
+		total += size
+	}
+	if len(current) > 0 {
+		flush()
+	}
+	return pieces
+}
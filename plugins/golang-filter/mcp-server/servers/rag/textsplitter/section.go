@@ -0,0 +1,102 @@
+package textsplitter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+)
+
+// Document formats supported by structure-aware splitting.
+const (
+	FORMAT_TEXT     = "text"
+	FORMAT_MARKDOWN = "markdown"
+	FORMAT_HTML     = "html"
+	FORMAT_JSON     = "json"
+	FORMAT_YAML     = "yaml"
+	FORMAT_CODE     = "code"
+)
+
+// SectionPathSeparator joins the elements of a section path when it is stored as chunk metadata.
+const SectionPathSeparator = " > "
+
+// Section is a chunk of a document together with its location in the document structure.
+type Section struct {
+	// Path lists the enclosing headings, keys or symbols from the outermost to the innermost.
+	Path    []string
+	Content string
+}
+
+// PathString returns the section path joined with SectionPathSeparator.
+func (s Section) PathString() string {
+	return strings.Join(s.Path, SectionPathSeparator)
+}
+
+// SectionSplitter is the interface for splitters that are aware of the document structure.
+type SectionSplitter interface {
+	SplitSections(text string) ([]Section, error)
+}
+
+// plainSectionSplitter adapts a TextSplitter to a SectionSplitter, the sections have no path.
+type plainSectionSplitter struct {
+	splitter TextSplitter
+}
+
+func (s plainSectionSplitter) SplitSections(text string) ([]Section, error) {
+	chunks, err := s.splitter.SplitText(text)
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]Section, 0, len(chunks))
+	for _, chunk := range chunks {
+		sections = append(sections, Section{Content: chunk})
+	}
+	return sections, nil
+}
+
+// NewSectionSplitter creates a structure-aware splitter for the document format.
+// The language is only used by the code format. Plain text uses the configured text splitter.
+func NewSectionSplitter(format string, language string, cfg *config.SplitterConfig) (SectionSplitter, error) {
+	opts := make([]Option, 0, 2)
+	if cfg.ChunkSize > 0 {
+		opts = append(opts, WithChunkSize(cfg.ChunkSize))
+	}
+	if cfg.ChunkOverlap > 0 {
+		opts = append(opts, WithChunkOverlap(cfg.ChunkOverlap))
+	}
+
+	switch strings.ToLower(format) {
+	case "", FORMAT_TEXT:
+		splitter, err := NewTextSplitter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return plainSectionSplitter{splitter: splitter}, nil
+	case FORMAT_MARKDOWN, "md":
+		return NewMarkdownSplitter(append(opts, WithHeadingHierarchy(true), WithJoinTableRows(true))...), nil
+	case FORMAT_HTML:
+		return NewHTMLSplitter(append(opts, WithHeadingHierarchy(true), WithJoinTableRows(true))...), nil
+	case FORMAT_JSON:
+		return NewStructuredDataSplitter(FORMAT_JSON, opts...), nil
+	case FORMAT_YAML, "yml":
+		return NewStructuredDataSplitter(FORMAT_YAML, opts...), nil
+	case FORMAT_CODE:
+		return NewCodeSplitter(language, opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown document format: %s", format)
+	}
+}
+
+// splitOversized splits content longer than the chunk size with a recursive character splitter.
+func splitOversized(content string, chunkSize, chunkOverlap int, lenFunc func(string) int) ([]string, error) {
+	if lenFunc(content) <= chunkSize {
+		return []string{content}, nil
+	}
+	splitter := NewRecursiveCharacter(
+		WithChunkSize(chunkSize),
+		WithChunkOverlap(chunkOverlap),
+		WithLenFunc(lenFunc),
+		WithSeparators([]string{"\n\n", "\n", ".", "。", "?", "!", "；", " ", ""}),
+	)
+	return splitter.SplitText(content)
+}
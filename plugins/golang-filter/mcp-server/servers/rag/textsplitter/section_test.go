@@ -0,0 +1,160 @@
+package textsplitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownSplitter(t *testing.T) {
+	text := "# Runbook\n\nIntro paragraph.\n\n## Restart\n\nRun the following:\n\n```bash\nkubectl rollout restart deploy/higress-gateway\n\n# wait\nkubectl rollout status deploy/higress-gateway\n```\n\n## Errors\n\n| code | meaning |\n| --- | --- |\n| ERR_CONN_RESET | upstream reset |\n| ERR_TIMEOUT | upstream timeout |\n"
+
+	splitter := NewMarkdownSplitter(WithChunkSize(200), WithHeadingHierarchy(true), WithJoinTableRows(true))
+	sections, err := splitter.SplitSections(text)
+	require.NoError(t, err)
+	require.Len(t, sections, 3)
+
+	assert.Equal(t, []string{"Runbook"}, sections[0].Path)
+	assert.Equal(t, "Runbook\n\nIntro paragraph.", sections[0].Content)
+
+	// The code block with a blank line inside stays whole
+	assert.Equal(t, "Runbook > Restart", sections[1].PathString())
+	assert.Contains(t, sections[1].Content, "```bash\nkubectl rollout restart deploy/higress-gateway\n\n# wait\nkubectl rollout status deploy/higress-gateway\n```")
+
+	assert.Equal(t, []string{"Runbook", "Errors"}, sections[2].Path)
+	assert.Contains(t, sections[2].Content, "| ERR_CONN_RESET | upstream reset |\n| ERR_TIMEOUT | upstream timeout |")
+}
+
+func TestMarkdownSplitterLargeBlocks(t *testing.T) {
+	rows := make([]string, 0)
+	code := make([]string, 0)
+	for i := 0; i < 20; i++ {
+		rows = append(rows, "| key"+strings.Repeat("x", 10)+" | value |")
+		code = append(code, "echo line"+strings.Repeat("y", 10))
+	}
+	text := "# Table\n\n| name | value |\n| --- | --- |\n" + strings.Join(rows, "\n") + "\n\n# Code\n\n```sh\n" + strings.Join(code, "\n") + "\n```\n"
+
+	splitter := NewMarkdownSplitter(WithChunkSize(120), WithJoinTableRows(true))
+	sections, err := splitter.SplitSections(text)
+	require.NoError(t, err)
+	require.Greater(t, len(sections), 4)
+
+	for _, section := range sections {
+		switch section.Path[0] {
+		case "Table":
+			if strings.HasPrefix(section.Content, "# Table") {
+				continue
+			}
+			assert.True(t, strings.HasPrefix(section.Content, "| name | value |\n| --- | --- |\n"), section.Content)
+		case "Code":
+			if strings.HasPrefix(section.Content, "# Code") {
+				continue
+			}
+			assert.True(t, strings.HasPrefix(section.Content, "```sh\n"), section.Content)
+			assert.True(t, strings.HasSuffix(section.Content, "\n```"), section.Content)
+		}
+		assert.LessOrEqual(t, len(section.Content), 120)
+	}
+}
+
+func TestHTMLSplitter(t *testing.T) {
+	text := `<html><head><title>t</title><script>var a = 1;</script></head><body>
+<nav>Home | Docs</nav>
+<h1>Gateway</h1><p>Higress   is a <b>cloud native</b> gateway.</p>
+<h2>Install</h2><pre><code class="language-bash">helm install higress
+  higress.io/higress</code></pre>
+<table><thead><tr><th>flag</th><th>desc</th></tr></thead><tbody><tr><td>--debug</td><td>a|b</td></tr></tbody></table>
+</body></html>`
+
+	markdown, err := HTMLToMarkdown(text)
+	require.NoError(t, err)
+	assert.NotContains(t, markdown, "var a")
+	assert.NotContains(t, markdown, "Home | Docs")
+	assert.Contains(t, markdown, "# Gateway\n\nHigress is a cloud native gateway.")
+	assert.Contains(t, markdown, "```bash\nhelm install higress\n  higress.io/higress\n```")
+	assert.Contains(t, markdown, "| flag | desc |\n| --- | --- |\n| --debug | a\\|b |")
+
+	sections, err := NewHTMLSplitter(WithChunkSize(500), WithHeadingHierarchy(true)).SplitSections(text)
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	assert.Equal(t, []string{"Gateway"}, sections[0].Path)
+	assert.Equal(t, []string{"Gateway", "Install"}, sections[1].Path)
+}
+
+func TestStructuredDataSplitter(t *testing.T) {
+	yamlText := `server:
+  port: 8080
+  max-connections: 1024
+routes:
+  - name: api
+    upstream: ` + strings.Repeat("a", 60) + `
+  - name: web
+    upstream: ` + strings.Repeat("b", 60) + `
+`
+	sections, err := NewStructuredDataSplitter(FORMAT_YAML, WithChunkSize(100)).SplitSections(yamlText)
+	require.NoError(t, err)
+	require.Len(t, sections, 3)
+	assert.Equal(t, []string{"server"}, sections[0].Path)
+	assert.Equal(t, "server:\n    port: 8080\n    max-connections: 1024", sections[0].Content)
+	assert.Equal(t, "routes > [0]", sections[1].PathString())
+	assert.True(t, strings.HasPrefix(sections[1].Content, "name: api\n"))
+	assert.Equal(t, "routes > [1]", sections[2].PathString())
+
+	jsonText := `{"server": {"port": 8080}, "plugins": {"key-auth": {"keys": ["` + strings.Repeat("k", 80) + `"]}, "cors": {"allow_origins": ["*"]}}}`
+	sections, err = NewStructuredDataSplitter(FORMAT_JSON, WithChunkSize(100)).SplitSections(jsonText)
+	require.NoError(t, err)
+	require.Len(t, sections, 3)
+	assert.Equal(t, []string{"server"}, sections[0].Path)
+	assert.JSONEq(t, `{"server": {"port": 8080}}`, sections[0].Content)
+	assert.Equal(t, "plugins > key-auth > keys > [0]", sections[1].PathString())
+	assert.JSONEq(t, `{"cors": {"allow_origins": ["*"]}}`, sections[2].Content)
+
+	_, err = NewStructuredDataSplitter(FORMAT_JSON).SplitSections(`{"a": `)
+	assert.Error(t, err)
+}
+
+func TestCodeSplitter(t *testing.T) {
+	text := `package main
+
+import "fmt"
+
+// Server serves requests
+type Server struct {
+	port int
+}
+
+// Start starts the server
+func (s *Server) Start() error {
+	fmt.Println(s.port)
+	return nil
+}
+
+func main() {
+	_ = (&Server{}).Start()
+}
+`
+	sections, err := NewCodeSplitter("golang", WithChunkSize(200)).SplitSections(text)
+	require.NoError(t, err)
+	require.Len(t, sections, 4)
+	assert.Nil(t, sections[0].Path)
+	assert.Equal(t, "package main\n\nimport \"fmt\"", sections[0].Content)
+	assert.Equal(t, []string{"Server"}, sections[1].Path)
+	assert.True(t, strings.HasPrefix(sections[1].Content, "// Server serves requests\ntype Server struct"))
+	assert.Equal(t, []string{"Server.Start"}, sections[2].Path)
+	assert.True(t, strings.HasPrefix(sections[2].Content, "// Start starts the server\n"))
+	assert.Equal(t, []string{"main"}, sections[3].Path)
+}
+
+func TestNewSectionSplitter(t *testing.T) {
+	cfg := &config.SplitterConfig{Provider: "recursive", ChunkSize: 100, ChunkOverlap: 10}
+	for _, format := range []string{"", FORMAT_TEXT, FORMAT_MARKDOWN, FORMAT_HTML, FORMAT_JSON, FORMAT_YAML, FORMAT_CODE} {
+		splitter, err := NewSectionSplitter(format, "go", cfg)
+		require.NoError(t, err, format)
+		require.NotNil(t, splitter, format)
+	}
+	_, err := NewSectionSplitter("pdf", "", cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package textsplitter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StructuredDataSplitter is a text splitter for JSON and YAML documents. A node that fits in the
+// chunk size is kept whole, larger mappings and sequences are split into their children, so the
+// section path is the key path from the document root. Every chunk is rendered under its own key,
+// in the format of the input document.
+type StructuredDataSplitter struct {
+	Format       string
+	ChunkSize    int
+	ChunkOverlap int
+	LenFunc      func(string) int
+}
+
+// NewStructuredDataSplitter creates a new JSON or YAML splitter with default values.
+func NewStructuredDataSplitter(format string, opts ...Option) StructuredDataSplitter {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return StructuredDataSplitter{
+		Format:       format,
+		ChunkSize:    options.ChunkSize,
+		ChunkOverlap: options.ChunkOverlap,
+		LenFunc:      options.LenFunc,
+	}
+}
+
+// SplitText splits a JSON or YAML text into multiple text.
+func (s StructuredDataSplitter) SplitText(text string) ([]string, error) {
+	sections, err := s.SplitSections(text)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]string, 0, len(sections))
+	for _, section := range sections {
+		chunks = append(chunks, section.Content)
+	}
+	return chunks, nil
+}
+
+// SplitSections splits a JSON or YAML text into sections, the section path is the key path.
+// Multi-document YAML streams are supported, every document is split on its own.
+func (s StructuredDataSplitter) SplitSections(text string) ([]Section, error) {
+	// YAML is a superset of JSON, so the YAML decoder handles both while preserving key order
+	decoder := yaml.NewDecoder(strings.NewReader(text))
+	sections := make([]Section, 0)
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse %s document: %w", s.Format, err)
+		}
+		root := &node
+		if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+			root = root.Content[0]
+		}
+		if err := s.walk(root, nil, "", &sections); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+// walk emits the node as one section when it fits in the chunk size, otherwise it descends into the children.
+func (s StructuredDataSplitter) walk(node *yaml.Node, path []string, key string, sections *[]Section) error {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	content, err := s.render(node, key)
+	if err != nil {
+		return err
+	}
+	if s.LenFunc(content) <= s.ChunkSize || (node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode) || len(node.Content) == 0 {
+		chunks, err := splitOversized(content, s.ChunkSize, s.ChunkOverlap, s.LenFunc)
+		if err != nil {
+			return err
+		}
+		for _, chunk := range chunks {
+			*sections = append(*sections, Section{Path: path, Content: chunk})
+		}
+		return nil
+	}
+
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			childKey := node.Content[i].Value
+			if err := s.walk(node.Content[i+1], appendPath(path, childKey), childKey, sections); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i, item := range node.Content {
+		if err := s.walk(item, appendPath(path, fmt.Sprintf("[%d]", i)), "", sections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render renders a node in the document format, wrapped in a single-key mapping when a key is given.
+func (s StructuredDataSplitter) render(node *yaml.Node, key string) (string, error) {
+	if s.Format == FORMAT_JSON {
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return "", fmt.Errorf("failed to decode json node: %w", err)
+		}
+		if key != "" {
+			value = map[string]interface{}{key: value}
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to render json node: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if key != "" {
+		node = &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node},
+		}
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return "", fmt.Errorf("failed to render yaml node: %w", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// appendPath returns a copy of path with elem appended, so sibling sections never share a backing array.
+func appendPath(path []string, elem string) []string {
+	result := make([]string, 0, len(path)+1)
+	result = append(result, path...)
+	return append(result, elem)
+}
@@ -22,6 +22,8 @@ func NewTextSplitter(cfg *config.SplitterConfig) (TextSplitter, error) {
 	switch cfg.Provider {
 	case "recursive":
 		return NewRecursiveCharacter(WithChunkSize(cfg.ChunkSize), WithChunkOverlap(cfg.ChunkOverlap), WithSeparators([]string{"\n\n", "\n", ".", "。", "?", "!", "；"})), nil
+	case "markdown":
+		return NewMarkdownSplitter(WithChunkSize(cfg.ChunkSize), WithChunkOverlap(cfg.ChunkOverlap), WithHeadingHierarchy(true), WithJoinTableRows(true)), nil
 	case "nosplitter":
 		return NoSplitterCharacter{}, nil
 	default:
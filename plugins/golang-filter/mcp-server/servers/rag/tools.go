@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-session/common"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -37,6 +38,73 @@ func HandleCreateChunkFromText(ragClient *RAGClient) common.ToolHandlerFunc {
 	}
 }
 
+// HandleIngestDocument handles structure-aware ingestion of a document, replacing the chunks of a previous version
+func HandleIngestDocument(ragClient *RAGClient) common.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.Params.Arguments
+		content, ok := arguments["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid content argument")
+		}
+		req := &schema.IngestRequest{Content: content}
+		if documentID, ok := arguments["document_id"].(string); ok {
+			req.DocumentID = documentID
+		}
+		if title, ok := arguments["title"].(string); ok {
+			req.Title = title
+		}
+		if format, ok := arguments["format"].(string); ok {
+			req.Format = format
+		}
+		if language, ok := arguments["language"].(string); ok {
+			req.Language = language
+		}
+		if metadata, exists := arguments["metadata"]; exists {
+			m, ok := metadata.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid metadata argument")
+			}
+			req.Metadata = m
+		}
+
+		ingestResult, err := ragClient.IngestDocument(req)
+		if err != nil {
+			return nil, fmt.Errorf("ingest document failed, err: %w", err)
+		}
+
+		result := map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("document ingested, document_id: %s", ingestResult.DocumentID),
+			"data":    ingestResult,
+		}
+
+		return buildCallToolResult(result)
+	}
+}
+
+// HandleDeleteDocument handles the deletion of all chunks of a document
+func HandleDeleteDocument(ragClient *RAGClient) common.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.Params.Arguments
+		documentID, ok := arguments["document_id"].(string)
+		if !ok || documentID == "" {
+			return nil, fmt.Errorf("invalid document_id argument")
+		}
+
+		deleted, err := ragClient.DeleteDocument(documentID)
+		if err != nil {
+			return nil, fmt.Errorf("delete document failed, err: %w", err)
+		}
+
+		result := map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("document deleted, document_id: %s, chunks: %d", documentID, deleted),
+		}
+
+		return buildCallToolResult(result)
+	}
+}
+
 // HandleListChunks handles the listing of knowledge chunks
 func HandleListChunks(ragClient *RAGClient) common.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -143,17 +211,12 @@ func HandleSearch(ragClient *RAGClient) common.ToolHandlerFunc {
 		if !ok {
 			return nil, fmt.Errorf("invalid query argument")
 		}
-		topK, ok := arguments["topk"].(int)
-		if !ok {
-			topK = ragClient.config.RAG.TopK
-		}
-
-		threshold, ok := arguments["threshold"].(float64)
-		if !ok {
-			threshold = ragClient.config.RAG.Threshold
+		options, err := parseSearchOptions(arguments)
+		if err != nil {
+			return nil, err
 		}
 
-		searchResult, err := ragClient.SearchChunks(query, int(topK), threshold)
+		searchResult, err := ragClient.SearchChunks(query, options)
 		if err != nil {
 			return nil, fmt.Errorf("search chunks failed, err: %w", err)
 		}
@@ -173,8 +236,12 @@ func HandleChat(ragClient *RAGClient) common.ToolHandlerFunc {
 		if ragClient.llmProvider == nil {
 			return nil, fmt.Errorf("llm provider is empty, please check the llm configuration")
 		}
+		options, err := parseSearchOptions(arguments)
+		if err != nil {
+			return nil, err
+		}
 		// Generate response using RAGClient's LLM
-		reply, err := ragClient.Chat(query)
+		reply, err := ragClient.Chat(query, options)
 		if err != nil {
 			return nil, fmt.Errorf("chat failed, err: %w", err)
 		}
@@ -183,6 +250,37 @@ func HandleChat(ragClient *RAGClient) common.ToolHandlerFunc {
 	}
 }
 
+// parseSearchOptions parses the retrieval arguments shared by the search and chat tools,
+// arguments that are not set are left zero so that the configured defaults apply
+func parseSearchOptions(arguments map[string]interface{}) (*schema.SearchOptions, error) {
+	options := &schema.SearchOptions{}
+	// topk is kept for compatibility with earlier clients
+	for _, key := range []string{"topK", "topk"} {
+		if topK, ok := arguments[key].(float64); ok {
+			options.TopK = int(topK)
+			break
+		}
+		if topK, ok := arguments[key].(int); ok {
+			options.TopK = topK
+			break
+		}
+	}
+	if threshold, ok := arguments["threshold"].(float64); ok {
+		options.Threshold = threshold
+	}
+	if rerankThreshold, ok := arguments["rerankThreshold"].(float64); ok {
+		options.RerankThreshold = rerankThreshold
+	}
+	if filters, exists := arguments["filters"]; exists && filters != nil {
+		filterMap, ok := filters.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid filters argument")
+		}
+		options.Filters = filterMap
+	}
+	return options, nil
+}
+
 // buildCallToolResult builds the call tool result
 func buildCallToolResult(results any) (*mcp.CallToolResult, error) {
 	jsonData, err := json.Marshal(results)
@@ -220,6 +318,56 @@ func GetCreateChunkFromTextSchema() json.RawMessage {
 	}`)
 }
 
+// GetIngestDocumentSchema returns the schema for ingest document tool
+func GetIngestDocumentSchema() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"document_id": {
+				"type": "string",
+				"description": "The document ID, ingesting again with the same ID replaces the chunks of the previous version. Generated when omitted"
+			},
+			"title": {
+				"type": "string",
+				"description": "The title of the document"
+			},
+			"content": {
+				"type": "string",
+				"description": "The document content"
+			},
+			"format": {
+				"type": "string",
+				"enum": ["text", "markdown", "html", "json", "yaml", "code"],
+				"description": "The document format, chunks follow headings, code blocks and tables for markdown and html, key paths for json and yaml, and top-level declarations for code",
+				"default": "text"
+			},
+			"language": {
+				"type": "string",
+				"description": "The programming language of code documents, e.g. go, python, javascript, typescript, java, rust, shell"
+			},
+			"metadata": {
+				"type": "object",
+				"description": "Metadata attached to every chunk of the document, usable as search filters"
+			}
+		},
+		"required": ["content"]
+	}`)
+}
+
+// GetDeleteDocumentSchema returns the schema for delete document tool
+func GetDeleteDocumentSchema() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"document_id": {
+				"type": "string",
+				"description": "The ID of the document whose chunks are deleted"
+			}
+		},
+		"required": ["document_id"]
+	}`)
+}
+
 // GetListKnowledgeSchema returns the schema for list knowledge tool
 func GetListKnowledgeSchema() json.RawMessage {
 	return json.RawMessage(`{
@@ -331,14 +479,22 @@ func GetSearchSchema() json.RawMessage {
 				"type": "string",
 				"description": "The search query"
 			},
-			"topk": {
-                "type": "integer",
-                "description": "The number of top results to return (optional, default 10)"
-            },
-            "threshold": {
-                "type": "number",
-                "description": "The relevance score threshold for filtering results (optional, default 0.5)"
-            }
+			"topK": {
+				"type": "integer",
+				"description": "The number of top results to return (optional, default 10)"
+			},
+			"threshold": {
+				"type": "number",
+				"description": "The minimum vector similarity score of results (optional, default 0.5)"
+			},
+			"rerankThreshold": {
+				"type": "number",
+				"description": "The minimum rerank relevance score of results, only used when a rerank model is configured (optional)"
+			},
+			"filters": {
+				"type": "object",
+				"description": "Metadata filters, e.g. {\"chunk_title\": \"runbook\"}. A list value matches any of its elements (optional)"
+			}
 		},
 		"required": ["query"]
 	}`)
@@ -352,6 +508,22 @@ func GetChatSchema() json.RawMessage {
 			"query": {
 				"type": "string",
 				"description": "User query"
+			},
+			"topK": {
+				"type": "integer",
+				"description": "The number of knowledge chunks used as context (optional, default 10)"
+			},
+			"threshold": {
+				"type": "number",
+				"description": "The minimum vector similarity score of context chunks (optional, default 0.5)"
+			},
+			"rerankThreshold": {
+				"type": "number",
+				"description": "The minimum rerank relevance score of context chunks, only used when a rerank model is configured (optional)"
+			},
+			"filters": {
+				"type": "object",
+				"description": "Metadata filters applied to context chunks, a list value matches any of its elements (optional)"
 			}
 		},
 		"required": ["query"]
@@ -0,0 +1,377 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+	"github.com/distribution/distribution/v3/uuid"
+)
+
+const (
+	EMBEDDED_PROVIDER_TYPE = "embedded"
+)
+
+// embeddedProviderInitializer initializes the embedded vector store provider
+type embeddedProviderInitializer struct{}
+
+// InitConfig initializes the configuration with default values if not set
+func (e *embeddedProviderInitializer) InitConfig(cfg *config.VectorDBConfig) error {
+	if cfg.Provider != EMBEDDED_PROVIDER_TYPE {
+		return fmt.Errorf("provider type mismatch: expected %s, got %s", EMBEDDED_PROVIDER_TYPE, cfg.Provider)
+	}
+	if cfg.Collection == "" {
+		cfg.Collection = schema.DEFAULT_DOCUMENT_COLLECTION
+	}
+	return nil
+}
+
+// ValidateConfig validates the configuration parameters
+func (e *embeddedProviderInitializer) ValidateConfig(cfg *config.VectorDBConfig) error {
+	if cfg.Collection == "" {
+		return fmt.Errorf("embedded document collection is required")
+	}
+	if strings.ContainsAny(cfg.Collection, `/\`) {
+		return fmt.Errorf("embedded collection name must not contain path separators: %s", cfg.Collection)
+	}
+	return nil
+}
+
+// CreateProvider creates a new embedded vector store provider instance
+func (e *embeddedProviderInitializer) CreateProvider(cfg *config.VectorDBConfig, dim int) (VectorStoreProvider, error) {
+	if err := e.InitConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := e.ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	return NewEmbeddedProvider(cfg, dim)
+}
+
+// embeddedRecord is the persisted form of a document, Document.Vector is not serialized by default
+type embeddedRecord struct {
+	ID        string                 `json:"id"`
+	Content   string                 `json:"content"`
+	Vector    []float32              `json:"vector"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt int64                  `json:"created_at"`
+}
+
+// embeddedSnapshot is the on-disk layout of a collection
+type embeddedSnapshot struct {
+	Dimensions int              `json:"dimensions"`
+	Records    []embeddedRecord `json:"records"`
+}
+
+// EmbeddedProvider implements an in-process vector store that keeps all documents in memory
+// and optionally persists them to a JSON file. Search is an exact scan, suitable for small collections.
+type EmbeddedProvider struct {
+	mu         sync.RWMutex
+	config     *config.VectorDBConfig
+	collection string
+	filePath   string
+	mapper     VectorDBMapper
+	dimensions int
+	records    []embeddedRecord
+	index      map[string]int
+}
+
+// NewEmbeddedProvider creates a new instance of EmbeddedProvider
+func NewEmbeddedProvider(cfg *config.VectorDBConfig, dimensions int) (VectorStoreProvider, error) {
+	mapper, err := NewDefaultVectorDBMapper(EMBEDDED_PROVIDER_TYPE, cfg.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default vector db mapper: %w", err)
+	}
+	provider := &EmbeddedProvider{
+		config:     cfg,
+		collection: cfg.Collection,
+		mapper:     mapper,
+		dimensions: dimensions,
+		index:      make(map[string]int),
+	}
+	if cfg.Path != "" {
+		provider.filePath = filepath.Join(cfg.Path, cfg.Collection+".json")
+	}
+	if err := provider.CreateCollection(context.Background(), dimensions); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// CreateCollection loads the collection from disk if it has been persisted before
+func (e *EmbeddedProvider) CreateCollection(ctx context.Context, dim int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dimensions = dim
+	if e.filePath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(e.filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	data, err := os.ReadFile(e.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return e.persist()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read collection %s: %w", e.collection, err)
+	}
+	var snapshot embeddedSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse collection %s: %w", e.collection, err)
+	}
+	if snapshot.Dimensions != 0 && dim != 0 && snapshot.Dimensions != dim {
+		return fmt.Errorf("collection %s has dimension %d, expected %d", e.collection, snapshot.Dimensions, dim)
+	}
+	e.records = snapshot.Records
+	e.rebuildIndex()
+	return nil
+}
+
+// DropCollection removes all documents and the persisted file
+func (e *EmbeddedProvider) DropCollection(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = nil
+	e.rebuildIndex()
+	if e.filePath == "" {
+		return nil
+	}
+	if err := os.Remove(e.filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to drop collection: %w", err)
+	}
+	return nil
+}
+
+// rebuildIndex rebuilds the id to position index, must be called with the lock held
+func (e *EmbeddedProvider) rebuildIndex() {
+	e.index = make(map[string]int, len(e.records))
+	for i, record := range e.records {
+		e.index[record.ID] = i
+	}
+}
+
+// persist writes the collection to disk atomically, must be called with the lock held
+func (e *EmbeddedProvider) persist() error {
+	if e.filePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(embeddedSnapshot{
+		Dimensions: e.dimensions,
+		Records:    e.records,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection %s: %w", e.collection, err)
+	}
+	tmpFile := e.filePath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write collection %s: %w", e.collection, err)
+	}
+	if err := os.Rename(tmpFile, e.filePath); err != nil {
+		return fmt.Errorf("failed to write collection %s: %w", e.collection, err)
+	}
+	return nil
+}
+
+func (e *EmbeddedProvider) toRecord(doc schema.Document) (embeddedRecord, error) {
+	if e.dimensions > 0 && len(doc.Vector) != e.dimensions {
+		return embeddedRecord{}, fmt.Errorf("vector dimension of doc %s is %d, expected %d", doc.ID, len(doc.Vector), e.dimensions)
+	}
+	id := doc.ID
+	if id == "" {
+		id = uuid.Generate().String()
+	}
+	return embeddedRecord{
+		ID:        id,
+		Content:   doc.Content,
+		Vector:    doc.Vector,
+		Metadata:  doc.Metadata,
+		CreatedAt: doc.CreatedAt.UnixMilli(),
+	}, nil
+}
+
+func (r embeddedRecord) toDocument() schema.Document {
+	return schema.Document{
+		ID:        r.ID,
+		Content:   r.Content,
+		Metadata:  r.Metadata,
+		CreatedAt: time.UnixMilli(r.CreatedAt),
+	}
+}
+
+// AddDoc adds documents to the vector store
+func (e *EmbeddedProvider) AddDoc(ctx context.Context, docs []schema.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	records := make([]embeddedRecord, 0, len(docs))
+	for _, doc := range docs {
+		record, err := e.toRecord(doc)
+		if err != nil {
+			return fmt.Errorf("failed to insert documents: %w", err)
+		}
+		if _, exists := e.index[record.ID]; exists {
+			return fmt.Errorf("failed to insert documents: duplicate id %s", record.ID)
+		}
+		records = append(records, record)
+	}
+	for _, record := range records {
+		e.index[record.ID] = len(e.records)
+		e.records = append(e.records, record)
+	}
+	return e.persist()
+}
+
+// DeleteDoc deletes a document by its ID
+func (e *EmbeddedProvider) DeleteDoc(ctx context.Context, id string) error {
+	return e.DeleteDocs(ctx, []string{id})
+}
+
+// UpdateDoc replaces existing documents and adds the ones that do not exist yet
+func (e *EmbeddedProvider) UpdateDoc(ctx context.Context, docs []schema.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, doc := range docs {
+		record, err := e.toRecord(doc)
+		if err != nil {
+			return fmt.Errorf("failed to update documents: %w", err)
+		}
+		if i, exists := e.index[record.ID]; exists {
+			e.records[i] = record
+		} else {
+			e.index[record.ID] = len(e.records)
+			e.records = append(e.records, record)
+		}
+	}
+	return e.persist()
+}
+
+// similarity computes the score between two vectors using the configured metric type
+func (e *EmbeddedProvider) similarity(metricType string, a, b []float32) float64 {
+	var dot, normA, normB, l2 float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+		l2 += (x - y) * (x - y)
+	}
+	switch metricType {
+	case "L2":
+		return l2
+	case "COSINE":
+		if normA == 0 || normB == 0 {
+			return 0
+		}
+		return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+	default:
+		return dot
+	}
+}
+
+// SearchDocs performs an exact similarity search over all documents
+func (e *EmbeddedProvider) SearchDocs(ctx context.Context, vector []float32, options *schema.SearchOptions) ([]schema.SearchResult, error) {
+	if options == nil {
+		options = &schema.SearchOptions{TopK: 10}
+	}
+	searchConfig, _ := e.mapper.GetSearchConfig()
+	metricType := strings.ToUpper(searchConfig.MetricType)
+
+	e.mu.RLock()
+	results := make([]schema.SearchResult, 0, len(e.records))
+	for _, record := range e.records {
+		if !schema.MatchFilters(record.Metadata, options.Filters) {
+			continue
+		}
+		results = append(results, schema.SearchResult{
+			Document: record.toDocument(),
+			Score:    e.similarity(metricType, vector, record.Vector),
+		})
+	}
+	e.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		// L2 is a distance, smaller is better
+		if metricType == "L2" {
+			return results[i].Score < results[j].Score
+		}
+		return results[i].Score > results[j].Score
+	})
+	if options.TopK > 0 && len(results) > options.TopK {
+		results = results[:options.TopK]
+	}
+	return results, nil
+}
+
+// DeleteDocs deletes multiple documents by their IDs
+func (e *EmbeddedProvider) DeleteDocs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	toDelete := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		toDelete[id] = struct{}{}
+	}
+	records := e.records[:0]
+	for _, record := range e.records {
+		if _, ok := toDelete[record.ID]; !ok {
+			records = append(records, record)
+		}
+	}
+	e.records = records
+	e.rebuildIndex()
+	return e.persist()
+}
+
+// ListDocs retrieves all documents with optional limit
+func (e *EmbeddedProvider) ListDocs(ctx context.Context, limit int) ([]schema.Document, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	count := len(e.records)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+	documents := make([]schema.Document, 0, count)
+	for _, record := range e.records[:count] {
+		documents = append(documents, record.toDocument())
+	}
+	return documents, nil
+}
+
+// QueryDocs retrieves all documents matching the metadata filters, including their vectors
+func (e *EmbeddedProvider) QueryDocs(ctx context.Context, filters map[string]interface{}) ([]schema.Document, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	documents := make([]schema.Document, 0)
+	for _, record := range e.records {
+		if !schema.MatchFilters(record.Metadata, filters) {
+			continue
+		}
+		doc := record.toDocument()
+		doc.Vector = record.Vector
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+// GetProviderType returns the provider type identifier
+func (e *EmbeddedProvider) GetProviderType() string {
+	return EMBEDDED_PROVIDER_TYPE
+}
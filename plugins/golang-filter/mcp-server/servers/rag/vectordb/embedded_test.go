@@ -0,0 +1,144 @@
+package vectordb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+)
+
+func getEmbeddedProvider(t *testing.T, path string) VectorStoreProvider {
+	cfg := &config.VectorDBConfig{
+		Provider:   PROVIDER_TYPE_EMBEDDED,
+		Collection: "knowledge_test",
+		Path:       path,
+	}
+	provider, err := NewVectorDBProvider(cfg, 3)
+	if err != nil {
+		t.Fatalf("create embedded provider failed: %v", err)
+	}
+	return provider
+}
+
+func TestEmbeddedProvider_SearchDocs(t *testing.T) {
+	ctx := context.Background()
+	provider := getEmbeddedProvider(t, "")
+	docs := []schema.Document{
+		{ID: "a", Content: "alpha", Vector: []float32{1, 0, 0}, Metadata: map[string]interface{}{"title": "a"}, CreatedAt: time.Now()},
+		{ID: "b", Content: "beta", Vector: []float32{0, 1, 0}, Metadata: map[string]interface{}{"title": "b"}, CreatedAt: time.Now()},
+		{ID: "c", Content: "gamma", Vector: []float32{0.8, 0.2, 0}, Metadata: map[string]interface{}{"title": "c"}, CreatedAt: time.Now()},
+	}
+	if err := provider.AddDoc(ctx, docs); err != nil {
+		t.Fatalf("AddDoc() error = %v", err)
+	}
+	if err := provider.AddDoc(ctx, docs[:1]); err == nil {
+		t.Fatalf("AddDoc() with duplicate id should fail")
+	}
+
+	results, err := provider.SearchDocs(ctx, []float32{1, 0, 0}, &schema.SearchOptions{TopK: 2})
+	if err != nil {
+		t.Fatalf("SearchDocs() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("SearchDocs() results len = %d, want 2", len(results))
+	}
+	if results[0].Document.ID != "a" || results[1].Document.ID != "c" {
+		t.Errorf("SearchDocs() order = [%s %s], want [a c]", results[0].Document.ID, results[1].Document.ID)
+	}
+
+	if err := provider.DeleteDocs(ctx, []string{"a"}); err != nil {
+		t.Fatalf("DeleteDocs() error = %v", err)
+	}
+	listed, err := provider.ListDocs(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListDocs() error = %v", err)
+	}
+	if len(listed) != 2 {
+		t.Errorf("ListDocs() len = %d, want 2", len(listed))
+	}
+}
+
+func TestEmbeddedProvider_Persistence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	provider := getEmbeddedProvider(t, dir)
+	doc := schema.Document{ID: "a", Content: "alpha", Vector: []float32{1, 0, 0}, CreatedAt: time.Now()}
+	if err := provider.AddDoc(ctx, []schema.Document{doc}); err != nil {
+		t.Fatalf("AddDoc() error = %v", err)
+	}
+	doc.Content = "alpha v2"
+	if err := provider.UpdateDoc(ctx, []schema.Document{doc}); err != nil {
+		t.Fatalf("UpdateDoc() error = %v", err)
+	}
+
+	reopened := getEmbeddedProvider(t, dir)
+	docs, err := reopened.ListDocs(ctx, 0)
+	if err != nil {
+		t.Fatalf("ListDocs() error = %v", err)
+	}
+	if len(docs) != 1 || docs[0].Content != "alpha v2" {
+		t.Fatalf("ListDocs() after reopen = %+v, want one updated doc", docs)
+	}
+	results, err := reopened.SearchDocs(ctx, []float32{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("SearchDocs() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Score != 1 {
+		t.Errorf("SearchDocs() after reopen = %+v, want vector to be restored", results)
+	}
+
+	if err := reopened.DropCollection(ctx); err != nil {
+		t.Fatalf("DropCollection() error = %v", err)
+	}
+	dropped := getEmbeddedProvider(t, dir)
+	if docs, _ := dropped.ListDocs(ctx, 0); len(docs) != 0 {
+		t.Errorf("ListDocs() after drop len = %d, want 0", len(docs))
+	}
+}
+
+func TestEmbeddedProvider_DimensionMismatch(t *testing.T) {
+	provider := getEmbeddedProvider(t, "")
+	err := provider.AddDoc(context.Background(), []schema.Document{{ID: "a", Vector: []float32{1, 0}}})
+	if err == nil {
+		t.Fatalf("AddDoc() with wrong dimension should fail")
+	}
+}
+
+func TestEmbeddedProvider_QueryDocs(t *testing.T) {
+	ctx := context.Background()
+	provider := getEmbeddedProvider(t, "")
+	docs := []schema.Document{
+		{ID: "a", Content: "alpha", Vector: []float32{1, 0, 0}, Metadata: map[string]interface{}{"document_id": "doc1"}, CreatedAt: time.Now()},
+		{ID: "b", Content: "beta", Vector: []float32{0, 1, 0}, Metadata: map[string]interface{}{"document_id": "doc2"}, CreatedAt: time.Now()},
+		{ID: "c", Content: "gamma", Vector: []float32{0, 0, 1}, Metadata: map[string]interface{}{"document_id": "doc1"}, CreatedAt: time.Now()},
+	}
+	if err := provider.AddDoc(ctx, docs); err != nil {
+		t.Fatalf("AddDoc() error = %v", err)
+	}
+
+	queried, err := provider.QueryDocs(ctx, map[string]interface{}{"document_id": "doc1"})
+	if err != nil {
+		t.Fatalf("QueryDocs() error = %v", err)
+	}
+	if len(queried) != 2 {
+		t.Fatalf("QueryDocs() len = %d, want 2", len(queried))
+	}
+	for _, doc := range queried {
+		if doc.ID != "a" && doc.ID != "c" {
+			t.Errorf("QueryDocs() returned unexpected doc %s", doc.ID)
+		}
+		if len(doc.Vector) != 3 {
+			t.Errorf("QueryDocs() doc %s vector = %v, want the stored vector", doc.ID, doc.Vector)
+		}
+	}
+
+	all, err := provider.QueryDocs(ctx, nil)
+	if err != nil {
+		t.Fatalf("QueryDocs() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("QueryDocs() without filters len = %d, want 3", len(all))
+	}
+}
@@ -108,6 +108,7 @@ func (m *DefaultVectorDBMapper) ParseMapping(provider string, cfg config.Mapping
 			},
 		}
 		cfg.Fields = defaultFields
+		m.mappingConfig = cfg
 	}
 
 	// Parse field mappings
@@ -3,7 +3,10 @@ package vectordb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,8 +17,9 @@ import (
 )
 
 const (
-	MILVUS_DUMMY_DIM     = 8
-	MILVUS_PROVIDER_TYPE = "milvus"
+	MILVUS_DUMMY_DIM        = 8
+	MILVUS_PROVIDER_TYPE    = "milvus"
+	MILVUS_QUERY_BATCH_SIZE = 1000
 )
 
 // MilvusProviderInitializer initializes the Milvus vector store provider
@@ -632,6 +636,40 @@ func (m *MilvusProvider) buildSearchParam() (entity.SearchParam, error) {
 	}
 }
 
+// buildFilterExpr builds a boolean expression on the metadata JSON field from metadata filters
+func (m *MilvusProvider) buildFilterExpr(filters map[string]interface{}) (string, error) {
+	if len(filters) == 0 {
+		return "", nil
+	}
+	metadataField, err := m.mapper.GetRawField("metadata")
+	if err != nil {
+		return "", fmt.Errorf("%w: metadata filters require a metadata field", ErrUnsupportedOperation)
+	}
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return "", err
+		}
+		valueBytes, err := json.Marshal(filters[key])
+		if err != nil {
+			return "", fmt.Errorf("invalid filter value for %s: %w", key, err)
+		}
+		operator := "=="
+		if _, ok := filters[key].([]interface{}); ok {
+			operator = "in"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s[%s] %s %s", metadataField.RawName, keyBytes, operator, valueBytes))
+	}
+	return strings.Join(conditions, " && "), nil
+}
+
 // SearchDocs performs similarity search for documents
 func (m *MilvusProvider) SearchDocs(ctx context.Context, vector []float32, options *schema.SearchOptions) ([]schema.SearchResult, error) {
 	if options == nil {
@@ -650,7 +688,10 @@ func (m *MilvusProvider) SearchDocs(ctx context.Context, vector []float32, optio
 	metricType := m.GetMetricType(searchConfig.MetricType)
 
 	// Build filter expression
-	expr := ""
+	expr, err := m.buildFilterExpr(options.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build filter expression: %w", err)
+	}
 	searchResults, err := m.client.Search(
 		ctx,
 		m.collection,
@@ -768,8 +809,43 @@ func (m *MilvusProvider) ListDocs(ctx context.Context, limit int) ([]schema.Docu
 		return nil, fmt.Errorf("failed to query documents: %w", err)
 	}
 
+	return m.parseQueryResult(queryResult), nil
+}
+
+// QueryDocs retrieves all documents matching the metadata filters, including their vectors.
+// The filter is evaluated by Milvus and the results are paged with a query iterator
+func (m *MilvusProvider) QueryDocs(ctx context.Context, filters map[string]interface{}) ([]schema.Document, error) {
+	expr, err := m.buildFilterExpr(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build filter expression: %w", err)
+	}
+	outputFields, _ := m.mapper.GetRawAllFieldNames()
+	iterator, err := m.client.QueryIterator(ctx, client.NewQueryIteratorOption(m.collection).
+		WithExpr(expr).
+		WithOutputFields(outputFields...).
+		WithBatchSize(MILVUS_QUERY_BATCH_SIZE))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query iterator: %w", err)
+	}
+
+	documents := []schema.Document{}
+	for {
+		queryResult, err := iterator.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query documents: %w", err)
+		}
+		documents = append(documents, m.parseQueryResult(queryResult)...)
+	}
+	return documents, nil
+}
+
+// parseQueryResult converts the columns returned by a Milvus query into documents
+func (m *MilvusProvider) parseQueryResult(queryResult []entity.Column) []schema.Document {
 	if len(queryResult) == 0 {
-		return []schema.Document{}, nil
+		return []schema.Document{}
 	}
 
 	rowCount := queryResult[0].Len()
@@ -780,6 +856,7 @@ func (m *MilvusProvider) ListDocs(ctx context.Context, limit int) ([]schema.Docu
 		var (
 			id        string
 			content   string
+			vector    []float32
 			metadata  map[string]interface{}
 			createdAt int64
 		)
@@ -799,6 +876,10 @@ func (m *MilvusProvider) ListDocs(ctx context.Context, limit int) ([]schema.Docu
 				if v, err := col.(*entity.ColumnVarChar).Get(i); err == nil {
 					content = v.(string)
 				}
+			case "vector":
+				if c, ok := col.(*entity.ColumnFloatVector); ok && i < len(c.Data()) {
+					vector = c.Data()[i]
+				}
 			case "metadata":
 				if v, err := col.(*entity.ColumnJSONBytes).Get(i); err == nil {
 					if bytes, ok := v.([]byte); ok {
@@ -815,12 +896,13 @@ func (m *MilvusProvider) ListDocs(ctx context.Context, limit int) ([]schema.Docu
 		doc := schema.Document{
 			ID:        id,
 			Content:   content,
+			Vector:    vector,
 			Metadata:  metadata,
 			CreatedAt: time.UnixMilli(createdAt),
 		}
 		documents = append(documents, doc)
 	}
-	return documents, nil
+	return documents
 }
 
 // GetProviderType returns the provider type identifier
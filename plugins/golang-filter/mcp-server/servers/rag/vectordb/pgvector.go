@@ -0,0 +1,788 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/keyword"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	PGVECTOR_PROVIDER_TYPE = "pgvector"
+	// Text search configuration of the keyword search, simple does not stem so that identifiers match exactly
+	PGVECTOR_TEXT_SEARCH_CONFIG = "simple"
+)
+
+// pgvectorProviderInitializer initializes the pgvector vector store provider
+type pgvectorProviderInitializer struct{}
+
+// InitConfig initializes the configuration with default values if not set
+func (p *pgvectorProviderInitializer) InitConfig(cfg *config.VectorDBConfig) error {
+	if cfg.Provider != PGVECTOR_PROVIDER_TYPE {
+		return fmt.Errorf("provider type mismatch: expected %s, got %s", PGVECTOR_PROVIDER_TYPE, cfg.Provider)
+	}
+
+	// Set default values
+	if cfg.Host == "" {
+		cfg.Host = "localhost"
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 5432
+	}
+	if cfg.Database == "" {
+		cfg.Database = "postgres"
+	}
+	if cfg.Collection == "" {
+		cfg.Collection = schema.DEFAULT_DOCUMENT_COLLECTION
+	}
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = "disable"
+	}
+	return nil
+}
+
+// ValidateConfig validates the configuration parameters
+func (p *pgvectorProviderInitializer) ValidateConfig(cfg *config.VectorDBConfig) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("pgvector host is required")
+	}
+	if cfg.Port <= 0 {
+		return fmt.Errorf("pgvector port must be positive")
+	}
+	if cfg.Database == "" {
+		return fmt.Errorf("pgvector database is required")
+	}
+	if cfg.Collection == "" {
+		return fmt.Errorf("pgvector document table is required")
+	}
+	return nil
+}
+
+// CreateProvider creates a new pgvector vector store provider instance
+func (p *pgvectorProviderInitializer) CreateProvider(cfg *config.VectorDBConfig, dim int) (VectorStoreProvider, error) {
+	if err := p.InitConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := p.ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	return NewPgVectorProvider(cfg, dim)
+}
+
+// PgVectorProvider implements the vector store provider interface for PostgreSQL with the pgvector extension
+type PgVectorProvider struct {
+	pool       *pgxpool.Pool
+	config     *config.VectorDBConfig
+	table      string
+	mapper     VectorDBMapper
+	dimensions int
+}
+
+// NewPgVectorProvider creates a new instance of PgVectorProvider
+func NewPgVectorProvider(cfg *config.VectorDBConfig, dimensions int) (VectorStoreProvider, error) {
+	mapper, err := NewDefaultVectorDBMapper(PGVECTOR_PROVIDER_TYPE, cfg.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default vector db mapper: %w", err)
+	}
+
+	pool, err := pgxpool.New(context.Background(), buildPgVectorDSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pgvector client: %w", err)
+	}
+
+	provider := &PgVectorProvider{
+		pool:       pool,
+		config:     cfg,
+		table:      cfg.Collection,
+		mapper:     mapper,
+		dimensions: dimensions,
+	}
+	ctx := context.Background()
+	if err := provider.CreateCollection(ctx, dimensions); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return provider, nil
+}
+
+// buildPgVectorDSN builds a postgres connection url from the vector db configuration
+func buildPgVectorDSN(cfg *config.VectorDBConfig) string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		Host:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:   "/" + cfg.Database,
+	}
+	if cfg.Username != "" {
+		if cfg.Password != "" {
+			dsn.User = url.UserPassword(cfg.Username, cfg.Password)
+		} else {
+			dsn.User = url.User(cfg.Username)
+		}
+	}
+	query := url.Values{}
+	if cfg.SSLMode != "" {
+		query.Set("sslmode", cfg.SSLMode)
+	}
+	dsn.RawQuery = query.Encode()
+	return dsn.String()
+}
+
+// parseVector parses the text representation of a pgvector value, e.g. [1,2,3]
+func parseVector(text string) ([]float32, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "["), "]")
+	if text == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(text, ",")
+	vector := make([]float32, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, err
+		}
+		vector[i] = float32(value)
+	}
+	return vector, nil
+}
+
+// quoteIdentifier quotes a table or column name for use in SQL statements
+func quoteIdentifier(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+// formatVector formats a vector as a pgvector text literal, e.g. [0.1,0.2,0.3]
+func formatVector(vector []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// getDistanceOperator returns the pgvector operator class and distance operator for the configured metric type
+func (p *PgVectorProvider) getDistanceOperator() (string, string) {
+	searchConfig, _ := p.mapper.GetSearchConfig()
+	switch strings.ToUpper(searchConfig.MetricType) {
+	case "L2":
+		return "vector_l2_ops", "<->"
+	case "COSINE":
+		return "vector_cosine_ops", "<=>"
+	default:
+		return "vector_ip_ops", "<#>"
+	}
+}
+
+// buildScoreExpr converts the pgvector distance into a score that follows the same convention as Milvus
+func (p *PgVectorProvider) buildScoreExpr(distanceExpr string) string {
+	searchConfig, _ := p.mapper.GetSearchConfig()
+	switch strings.ToUpper(searchConfig.MetricType) {
+	case "L2":
+		return distanceExpr
+	case "COSINE":
+		return fmt.Sprintf("1 - (%s)", distanceExpr)
+	default:
+		// <#> returns the negative inner product
+		return fmt.Sprintf("-(%s)", distanceExpr)
+	}
+}
+
+func (p *PgVectorProvider) buildCreateTableSQL() (string, error) {
+	fieldMappings, err := p.mapper.GetFieldMappings()
+	if err != nil {
+		return "", fmt.Errorf("failed to get field mappings: %w", err)
+	}
+	columns := make([]string, 0, len(fieldMappings))
+	for _, field := range fieldMappings {
+		name := quoteIdentifier(field.RawName)
+		switch field.StandardName {
+		case "id":
+			column := fmt.Sprintf("%s VARCHAR(%d) PRIMARY KEY", name, field.MaxLength())
+			if field.IsAutoID() {
+				column = fmt.Sprintf("%s VARCHAR(%d) PRIMARY KEY DEFAULT gen_random_uuid()::text", name, field.MaxLength())
+			}
+			columns = append(columns, column)
+		case "content":
+			columns = append(columns, fmt.Sprintf("%s TEXT", name))
+		case "vector":
+			columns = append(columns, fmt.Sprintf("%s vector(%d)", name, p.dimensions))
+		case "metadata":
+			columns = append(columns, fmt.Sprintf("%s JSONB", name))
+		case "created_at":
+			columns = append(columns, fmt.Sprintf("%s BIGINT", name))
+		}
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdentifier(p.table), strings.Join(columns, ", ")), nil
+}
+
+func (p *PgVectorProvider) buildCreateIndexSQL() (string, error) {
+	indexConfig, _ := p.mapper.GetIndexConfig()
+	vectorField, _ := p.mapper.GetVectorField()
+	opsClass, _ := p.getDistanceOperator()
+	indexName := quoteIdentifier(p.table + "_vector_index")
+	table := quoteIdentifier(p.table)
+	column := quoteIdentifier(vectorField.RawName)
+
+	indexType := strings.ToUpper(indexConfig.IndexType)
+	if indexType == "" {
+		indexType = "HNSW"
+	}
+	switch indexType {
+	case "FLAT":
+		// Exact search, no index required
+		return "", nil
+	case "HNSW":
+		m := 8
+		efConstruction := 64
+		if mVal, err := indexConfig.ParamsInt64("M"); err == nil {
+			m = int(mVal)
+		}
+		if efConstructionVal, err := indexConfig.ParamsInt64("efConstruction"); err == nil {
+			efConstruction = int(efConstructionVal)
+		}
+		return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (%s %s) WITH (m = %d, ef_construction = %d)",
+			indexName, table, column, opsClass, m, efConstruction), nil
+	case "IVF_FLAT", "IVFFLAT":
+		nlist := 128
+		if nlistVal, err := indexConfig.ParamsInt64("nlist"); err == nil {
+			nlist = int(nlistVal)
+		}
+		return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING ivfflat (%s %s) WITH (lists = %d)",
+			indexName, table, column, opsClass, nlist), nil
+	default:
+		return "", fmt.Errorf("unsupported index type: %s", indexType)
+	}
+}
+
+// buildCreateKeywordIndexSQL builds the GIN index used by the full text keyword search,
+// it is empty when the content field is not mapped
+func (p *PgVectorProvider) buildCreateKeywordIndexSQL() string {
+	contentField, err := p.mapper.GetRawField("content")
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING gin (%s)",
+		quoteIdentifier(p.table+"_content_fts_index"), quoteIdentifier(p.table), buildTSVectorExpr(contentField.RawName))
+}
+
+// buildTSVectorExpr returns the tsvector expression of the content column, the keyword index and
+// the keyword search must use the same expression for the index to be used
+func buildTSVectorExpr(column string) string {
+	return fmt.Sprintf("to_tsvector('%s', %s)", PGVECTOR_TEXT_SEARCH_CONFIG, quoteIdentifier(column))
+}
+
+// buildSearchSettings returns the session settings to apply before a search, based on the index type
+func (p *PgVectorProvider) buildSearchSettings() []string {
+	indexConfig, _ := p.mapper.GetIndexConfig()
+	searchConfig, _ := p.mapper.GetSearchConfig()
+	indexType := strings.ToUpper(indexConfig.IndexType)
+	if indexType == "" {
+		indexType = "HNSW"
+	}
+	switch indexType {
+	case "HNSW":
+		if efSearchVal, err := searchConfig.ParamsFloat64("ef"); err == nil {
+			return []string{fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", int(efSearchVal))}
+		}
+	case "IVF_FLAT", "IVFFLAT":
+		if nprobeVal, err := searchConfig.ParamsFloat64("nprobe"); err == nil {
+			return []string{fmt.Sprintf("SET LOCAL ivfflat.probes = %d", int(nprobeVal))}
+		}
+	}
+	return nil
+}
+
+// CreateCollection creates the document table and vector index if they do not exist
+func (p *PgVectorProvider) CreateCollection(ctx context.Context, dim int) error {
+	if _, err := p.pool.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS vector"); err != nil {
+		return fmt.Errorf("failed to create vector extension: %w", err)
+	}
+	createTableSQL, err := p.buildCreateTableSQL()
+	if err != nil {
+		return fmt.Errorf("failed to build table schema: %w", err)
+	}
+	if _, err := p.pool.Exec(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", p.table, err)
+	}
+	createIndexSQL, err := p.buildCreateIndexSQL()
+	if err != nil {
+		return fmt.Errorf("failed to create vector index: %w", err)
+	}
+	if createIndexSQL != "" {
+		if _, err := p.pool.Exec(ctx, createIndexSQL); err != nil {
+			return fmt.Errorf("failed to create vector index: %w", err)
+		}
+	}
+	if createKeywordIndexSQL := p.buildCreateKeywordIndexSQL(); createKeywordIndexSQL != "" {
+		if _, err := p.pool.Exec(ctx, createKeywordIndexSQL); err != nil {
+			return fmt.Errorf("failed to create keyword index: %w", err)
+		}
+	}
+	return nil
+}
+
+// DropCollection removes the document table from the database
+func (p *PgVectorProvider) DropCollection(ctx context.Context) error {
+	if _, err := p.pool.Exec(ctx, fmt.Sprintf("DROP TABLE %s", quoteIdentifier(p.table))); err != nil {
+		return fmt.Errorf("failed to drop table %s: %w", p.table, err)
+	}
+	return nil
+}
+
+// buildRowValues returns the column names and values of a document according to the field mappings
+func (p *PgVectorProvider) buildRowValues(doc schema.Document) ([]string, []string, []any, error) {
+	fieldMappings, err := p.mapper.GetFieldMappings()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get field mappings: %w", err)
+	}
+	columns := make([]string, 0, len(fieldMappings))
+	placeholders := make([]string, 0, len(fieldMappings))
+	values := make([]any, 0, len(fieldMappings))
+	for _, field := range fieldMappings {
+		// Skip ID field if configured as auto ID
+		if field.IsPrimaryKey() && field.IsAutoID() {
+			continue
+		}
+		placeholder := fmt.Sprintf("$%d", len(values)+1)
+		switch field.StandardName {
+		case "id":
+			values = append(values, doc.ID)
+		case "content":
+			values = append(values, doc.Content)
+		case "vector":
+			values = append(values, formatVector(doc.Vector))
+			placeholder += "::vector"
+		case "metadata":
+			metadataBytes, err := json.Marshal(doc.Metadata)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to marshal metadata for doc %s: %w", doc.ID, err)
+			}
+			values = append(values, string(metadataBytes))
+			placeholder += "::jsonb"
+		case "created_at":
+			values = append(values, doc.CreatedAt.UnixMilli())
+		default:
+			continue
+		}
+		columns = append(columns, quoteIdentifier(field.RawName))
+		placeholders = append(placeholders, placeholder)
+	}
+	return columns, placeholders, values, nil
+}
+
+func (p *PgVectorProvider) insertDocs(ctx context.Context, docs []schema.Document, upsert bool) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	idField, _ := p.mapper.GetIDField()
+	batch := &pgx.Batch{}
+	for _, doc := range docs {
+		columns, placeholders, values, err := p.buildRowValues(doc)
+		if err != nil {
+			return err
+		}
+		sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(p.table),
+			strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+		if upsert {
+			updates := make([]string, 0, len(columns))
+			for _, column := range columns {
+				updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+			}
+			sql += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", quoteIdentifier(idField.RawName), strings.Join(updates, ", "))
+		}
+		batch.Queue(sql, values...)
+	}
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert documents: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit documents: %w", err)
+	}
+	return nil
+}
+
+// AddDoc adds documents to the vector database
+func (p *PgVectorProvider) AddDoc(ctx context.Context, docs []schema.Document) error {
+	return p.insertDocs(ctx, docs, false)
+}
+
+// DeleteDoc deletes a document by its ID
+func (p *PgVectorProvider) DeleteDoc(ctx context.Context, id string) error {
+	idField, _ := p.mapper.GetIDField()
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", quoteIdentifier(p.table), quoteIdentifier(idField.RawName))
+	if _, err := p.pool.Exec(ctx, sql, id); err != nil {
+		return fmt.Errorf("failed to delete documents for id %s: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateDoc updates documents in place, inserting the ones that do not exist yet
+func (p *PgVectorProvider) UpdateDoc(ctx context.Context, docs []schema.Document) error {
+	if err := p.insertDocs(ctx, docs, true); err != nil {
+		return fmt.Errorf("failed to update documents: %w", err)
+	}
+	return nil
+}
+
+// buildSelectColumns returns the columns to select for a document, in field mapping order, without the vector
+func (p *PgVectorProvider) buildSelectColumns() ([]string, []config.FieldMapping) {
+	fieldMappings, _ := p.mapper.GetFieldMappings()
+	columns := make([]string, 0, len(fieldMappings))
+	fields := make([]config.FieldMapping, 0, len(fieldMappings))
+	for _, field := range fieldMappings {
+		switch field.StandardName {
+		case "id", "content", "metadata", "created_at":
+			columns = append(columns, quoteIdentifier(field.RawName))
+			fields = append(fields, field)
+		}
+	}
+	return columns, fields
+}
+
+// scanDocument scans a row into a document, extra holds destinations for additional trailing columns
+func scanDocument(rows pgx.Rows, fields []config.FieldMapping, extra ...any) (schema.Document, error) {
+	var (
+		id        *string
+		content   *string
+		metadata  []byte
+		createdAt *int64
+	)
+	dest := make([]any, 0, len(fields)+len(extra))
+	for _, field := range fields {
+		switch field.StandardName {
+		case "id":
+			dest = append(dest, &id)
+		case "content":
+			dest = append(dest, &content)
+		case "metadata":
+			dest = append(dest, &metadata)
+		case "created_at":
+			dest = append(dest, &createdAt)
+		}
+	}
+	dest = append(dest, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return schema.Document{}, err
+	}
+	doc := schema.Document{}
+	if id != nil {
+		doc.ID = *id
+	}
+	if content != nil {
+		doc.Content = *content
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &doc.Metadata); err != nil {
+			doc.Metadata = make(map[string]interface{})
+		}
+	}
+	if createdAt != nil {
+		doc.CreatedAt = time.UnixMilli(*createdAt)
+	}
+	return doc, nil
+}
+
+// buildFilterClause builds a WHERE clause on the metadata JSONB column from metadata filters,
+// filter values are appended to args as JSON containment parameters
+func (p *PgVectorProvider) buildFilterClause(filters map[string]interface{}, args []any) (string, []any, error) {
+	if len(filters) == 0 {
+		return "", args, nil
+	}
+	metadataField, err := p.mapper.GetRawField("metadata")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: metadata filters require a metadata field", ErrUnsupportedOperation)
+	}
+	column := quoteIdentifier(metadataField.RawName)
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values, ok := filters[key].([]interface{})
+		if !ok {
+			values = []interface{}{filters[key]}
+		}
+		alternatives := make([]string, 0, len(values))
+		for _, value := range values {
+			containment, err := json.Marshal(map[string]interface{}{key: value})
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid filter value for %s: %w", key, err)
+			}
+			args = append(args, string(containment))
+			alternatives = append(alternatives, fmt.Sprintf("%s @> $%d::jsonb", column, len(args)))
+		}
+		if len(alternatives) == 0 {
+			conditions = append(conditions, "FALSE")
+			continue
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// SearchDocs performs similarity search for documents
+func (p *PgVectorProvider) SearchDocs(ctx context.Context, vector []float32, options *schema.SearchOptions) ([]schema.SearchResult, error) {
+	if options == nil {
+		options = &schema.SearchOptions{TopK: 10}
+	}
+
+	vectorField, _ := p.mapper.GetVectorField()
+	_, operator := p.getDistanceOperator()
+	distanceExpr := fmt.Sprintf("%s %s $1::vector", quoteIdentifier(vectorField.RawName), operator)
+	columns, fields := p.buildSelectColumns()
+	args := []any{formatVector(vector), options.TopK}
+	where, args, err := p.buildFilterClause(options.Filters, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build filter clause: %w", err)
+	}
+	sql := fmt.Sprintf("SELECT %s, %s AS score FROM %s%s ORDER BY %s LIMIT $2",
+		strings.Join(columns, ", "), p.buildScoreExpr(distanceExpr), quoteIdentifier(p.table), where, distanceExpr)
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	for _, setting := range p.buildSearchSettings() {
+		if _, err := tx.Exec(ctx, setting); err != nil {
+			return nil, fmt.Errorf("failed to apply search param: %w", err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	var results []schema.SearchResult
+	for rows.Next() {
+		var score float64
+		doc, err := scanDocument(rows, fields, &score)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, schema.SearchResult{
+			Document: doc,
+			Score:    score,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	return results, nil
+}
+
+// buildKeywordSearchSQL builds a full text search over the content column ranked by ts_rank_cd.
+// The query terms are combined with OR so that a chunk matching any of them is a candidate,
+// identifier-like terms such as ERR_CONN_RESET are matched as phrases of their parts
+func (p *PgVectorProvider) buildKeywordSearchSQL(query string, options *schema.SearchOptions) (string, []any, error) {
+	contentField, err := p.mapper.GetRawField("content")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: keyword search requires a content field", ErrUnsupportedOperation)
+	}
+	terms := keyword.Tokenize(query)
+	if len(terms) == 0 {
+		return "", nil, nil
+	}
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, `"`+term+`"`)
+	}
+	columns, _ := p.buildSelectColumns()
+	tsvector := buildTSVectorExpr(contentField.RawName)
+	args := []any{strings.Join(quoted, " or "), options.TopK}
+	where, args, err := p.buildFilterClause(options.Filters, args)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build filter clause: %w", err)
+	}
+	if where != "" {
+		where = " AND " + strings.TrimPrefix(where, " WHERE ")
+	}
+	sql := fmt.Sprintf("SELECT %s, ts_rank_cd(%s, query) AS score FROM %s, websearch_to_tsquery('%s', $1) query WHERE %s @@ query%s ORDER BY score DESC LIMIT $2",
+		strings.Join(columns, ", "), tsvector, quoteIdentifier(p.table), PGVECTOR_TEXT_SEARCH_CONFIG, tsvector, where)
+	return sql, args, nil
+}
+
+// KeywordSearch performs full text search for documents using the PostgreSQL text search index
+func (p *PgVectorProvider) KeywordSearch(ctx context.Context, query string, options *schema.SearchOptions) ([]schema.SearchResult, error) {
+	if options == nil {
+		options = &schema.SearchOptions{TopK: 10}
+	}
+	sql, args, err := p.buildKeywordSearchSQL(query, options)
+	if err != nil {
+		return nil, err
+	}
+	if sql == "" {
+		return nil, nil
+	}
+	_, fields := p.buildSelectColumns()
+	rows, err := p.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents by keyword: %w", err)
+	}
+	defer rows.Close()
+
+	var results []schema.SearchResult
+	for rows.Next() {
+		var score float64
+		doc, err := scanDocument(rows, fields, &score)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, schema.SearchResult{
+			Document: doc,
+			Score:    score,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search documents by keyword: %w", err)
+	}
+	return results, nil
+}
+
+// advisoryLockKey returns the advisory lock key text of a named lock on the table
+func (p *PgVectorProvider) advisoryLockKey(name string) string {
+	return p.table + "/" + name
+}
+
+// TryLock acquires a session level advisory lock, the connection holding it is kept out of the pool until unlock
+func (p *PgVectorProvider) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire pgvector connection: %w", err)
+	}
+	key := p.advisoryLockKey(name)
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to acquire pgvector lock %s: %w", key, err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+	unlock := func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", key); err != nil {
+			// Drop the connection so that the lock held by its session is released
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+// DeleteDocs deletes multiple documents by their IDs
+func (p *PgVectorProvider) DeleteDocs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	idField, _ := p.mapper.GetIDField()
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = ANY($1)", quoteIdentifier(p.table), quoteIdentifier(idField.RawName))
+	if _, err := p.pool.Exec(ctx, sql, ids); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// ListDocs retrieves all documents with optional limit
+func (p *PgVectorProvider) ListDocs(ctx context.Context, limit int) ([]schema.Document, error) {
+	columns, fields := p.buildSelectColumns()
+	sql := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), quoteIdentifier(p.table))
+	args := []any{}
+	if limit > 0 {
+		sql += " LIMIT $1"
+		args = append(args, limit)
+	}
+	rows, err := p.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []schema.Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows, fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	return documents, nil
+}
+
+// QueryDocs retrieves all documents matching the metadata filters, including their vectors.
+// Rows are streamed from the server so the whole result set is never capped
+func (p *PgVectorProvider) QueryDocs(ctx context.Context, filters map[string]interface{}) ([]schema.Document, error) {
+	columns, fields := p.buildSelectColumns()
+	vectorField, _ := p.mapper.GetVectorField()
+	where, args, err := p.buildFilterClause(filters, []any{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build filter clause: %w", err)
+	}
+	sql := fmt.Sprintf("SELECT %s, %s::text FROM %s%s", strings.Join(columns, ", "),
+		quoteIdentifier(vectorField.RawName), quoteIdentifier(p.table), where)
+	rows, err := p.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []schema.Document{}
+	for rows.Next() {
+		var vector *string
+		doc, err := scanDocument(rows, fields, &vector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		if vector != nil {
+			if doc.Vector, err = parseVector(*vector); err != nil {
+				return nil, fmt.Errorf("failed to parse vector of doc %s: %w", doc.ID, err)
+			}
+		}
+		documents = append(documents, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	return documents, nil
+}
+
+// GetProviderType returns the provider type identifier
+func (p *PgVectorProvider) GetProviderType() string {
+	return PGVECTOR_PROVIDER_TYPE
+}
+
+// Close closes the connection pool
+func (p *PgVectorProvider) Close() error {
+	if p.pool != nil {
+		p.pool.Close()
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ const (
 	PROVIDER_TYPE_MILVUS        = "milvus"
 	PROVIDER_TYPE_FAISS         = "faiss"
 	PROVIDER_TYPE_ELASTICSEARCH = "elasticsearch"
+	PROVIDER_TYPE_PGVECTOR      = "pgvector"
+	PROVIDER_TYPE_EMBEDDED      = "embedded"
 )
 
 // VectorStoreBase defines the base interface for vector store implementations
@@ -45,10 +47,29 @@ type VectorStoreProvider interface {
 	// ListDocs lists documents in the vector store
 	ListDocs(ctx context.Context, limit int) ([]schema.Document, error)
 
+	// QueryDocs lists all documents whose metadata match the filters, including their vectors.
+	// Filters are evaluated by the vector store and the results are not capped by a limit
+	QueryDocs(ctx context.Context, filters map[string]interface{}) ([]schema.Document, error)
+
 	// GetProviderType returns the type of the vector store provider
 	GetProviderType() string
 }
 
+// KeywordSearcher is implemented by vector stores that support full text search natively, the keyword index
+// is then kept by the store itself instead of in memory, shared by all replicas and persisted with the documents
+type KeywordSearcher interface {
+	// KeywordSearch searches for documents whose content match the query terms, ordered by relevance
+	KeywordSearch(ctx context.Context, query string, options *schema.SearchOptions) ([]schema.SearchResult, error)
+}
+
+// Locker is implemented by vector stores that can hold a lock shared by all clients of the collection, so that
+// jobs maintaining the collection run on a single replica at a time
+type Locker interface {
+	// TryLock acquires the named lock without waiting, acquired is false when another client holds it.
+	// The lock is released by calling unlock, or when the connection holding it is lost
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
 // VectorDBProviderInitializer defines the interface for vector database provider initializers
 type VectorDBProviderInitializer interface {
 	// CreateProvider creates a new vector database provider instance
@@ -57,7 +78,9 @@ type VectorDBProviderInitializer interface {
 
 var (
 	vectorDBProviderInitializers = map[string]VectorDBProviderInitializer{
-		PROVIDER_TYPE_MILVUS: &milvusProviderInitializer{},
+		PROVIDER_TYPE_MILVUS:   &milvusProviderInitializer{},
+		PROVIDER_TYPE_PGVECTOR: &pgvectorProviderInitializer{},
+		PROVIDER_TYPE_EMBEDDED: &embeddedProviderInitializer{},
 	}
 )
 
@@ -0,0 +1,399 @@
+package tool_search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/vectordb"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-session/common"
+	"github.com/envoyproxy/envoy/contrib/golang/common/go/api"
+)
+
+const (
+	// Tools discovered from MCP servers running in the same mcp-server filter
+	toolSourceGateway = "gateway"
+	// Tools discovered from remote MCP servers configured in indexer.servers
+	toolSourceRemote = "remote"
+
+	toolSearchServerType = "tool-search"
+	// Delay of the first sync, so the servers configured after tool-search in the same filter config exist
+	initialSyncDelay = 5 * time.Second
+	syncTimeout      = 5 * time.Minute
+	// Name of the vector store lock held while syncing, so a single replica indexes the collection at a time
+	syncLockName = "tool-indexer"
+)
+
+// syncLocks serializes the indexers of this process writing the same collection, e.g. after a config update
+// leaves the previous server running until it is destroyed
+var syncLocks sync.Map
+
+// ServerInfo identifies the MCP server owning an indexed tool
+type ServerInfo struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	// Type is the server type of gateway servers
+	Type string `json:"type,omitempty"`
+	// Path is the request path of gateway servers
+	Path string `json:"path,omitempty"`
+	// URL is the endpoint of remote servers
+	URL string `json:"url,omitempty"`
+}
+
+// SyncResult summarizes an index synchronization
+type SyncResult struct {
+	Added     int      `json:"added"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Deleted   int      `json:"deleted"`
+	Failed    []string `json:"failed,omitempty"`
+	// Skipped is set when another indexer was syncing the same collection
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// discoveredServer holds the tools listed from one MCP server
+type discoveredServer struct {
+	info  ServerInfo
+	tools []listedTool
+	err   error
+}
+
+// ToolIndexer keeps the tool index in sync with the tools listed by MCP servers.
+// Tools are upserted when their definition changes, with an increasing version, and deleted when they
+// disappear from a server that was listed successfully or when their server is no longer configured.
+// Records that were not written by the indexer are never modified.
+type ToolIndexer struct {
+	mu      sync.Mutex
+	config  IndexerConfig
+	service *SearchService
+}
+
+// NewToolIndexer creates a tool indexer writing to the search service vector store
+func NewToolIndexer(cfg IndexerConfig, service *SearchService) *ToolIndexer {
+	return &ToolIndexer{
+		config:  cfg,
+		service: service,
+	}
+}
+
+// Start synchronizes the index periodically until stop is closed
+func (x *ToolIndexer) Start(stop <-chan struct{}) {
+	go func() {
+		timer := time.NewTimer(initialSyncDelay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-stop:
+				api.LogInfo("Tool indexer stopped")
+				return
+			case <-timer.C:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+			result, err := x.Sync(ctx)
+			cancel()
+			if err != nil {
+				api.LogErrorf("Tool index sync failed: %v", err)
+			} else if result.Skipped {
+				api.LogInfo("Tool index sync skipped, another indexer is syncing the collection")
+			} else {
+				api.LogInfof("Tool index sync completed: %+v", *result)
+			}
+			timer.Reset(x.config.SyncInterval)
+		}
+	}()
+}
+
+// Sync lists the tools of all configured servers and applies the changes to the index.
+// The sync is skipped when another indexer of this process or, if the vector store supports locking, of another
+// replica is syncing the same collection
+func (x *ToolIndexer) Sync(ctx context.Context) (*SyncResult, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	lock, _ := syncLocks.LoadOrStore(x.collectionKey(), &sync.Mutex{})
+	if !lock.(*sync.Mutex).TryLock() {
+		return &SyncResult{Skipped: true}, nil
+	}
+	defer lock.(*sync.Mutex).Unlock()
+	if locker, ok := x.service.vectorProvider.(vectordb.Locker); ok {
+		unlock, acquired, err := locker.TryLock(ctx, syncLockName)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return &SyncResult{Skipped: true}, nil
+		}
+		defer unlock()
+	}
+
+	servers := x.discover(ctx)
+	// Records are matched against the whole collection, a capped listing would re-add or never delete the rest
+	existing, err := x.service.vectorProvider.QueryDocs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed tools: %w", err)
+	}
+	indexed := make(map[string]schema.Document)
+	for _, doc := range existing {
+		if _, ok := indexedServer(doc.Metadata); ok {
+			indexed[doc.ID] = doc
+		}
+	}
+
+	result := &SyncResult{}
+	failed := make(map[string]bool)
+	seen := make(map[string]bool)
+	upserts := make([]schema.Document, 0)
+	for _, server := range servers {
+		if server.err != nil {
+			// Keep the tools of an unreachable server until it can be listed again
+			api.LogWarnf("Failed to list tools of MCP server %s: %v", server.info.Name, server.err)
+			result.Failed = append(result.Failed, server.info.Name)
+			failed[serverKey(server.info)] = true
+			continue
+		}
+		for _, tool := range server.tools {
+			id := toolRecordID(server.info, tool.Name)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			hash := toolHash(server.info, tool)
+			version := 1
+			if doc, ok := indexed[id]; ok {
+				if doc.Metadata["content_hash"] == hash {
+					result.Unchanged++
+					continue
+				}
+				version = metadataInt(doc.Metadata, "version") + 1
+				result.Updated++
+			} else {
+				result.Added++
+			}
+			doc, err := x.buildRecord(ctx, server.info, tool, id, hash, version)
+			if err != nil {
+				return nil, err
+			}
+			upserts = append(upserts, doc)
+		}
+	}
+
+	if len(upserts) > 0 {
+		if err := x.service.vectorProvider.UpdateDoc(ctx, upserts); err != nil {
+			return nil, fmt.Errorf("failed to upsert tools: %w", err)
+		}
+	}
+
+	stale := make([]string, 0)
+	for id, doc := range indexed {
+		info, _ := indexedServer(doc.Metadata)
+		if seen[id] {
+			continue
+		}
+		// Tools of failed servers are kept, tools of servers no longer configured are removed
+		if failed[serverKey(info)] {
+			continue
+		}
+		stale = append(stale, id)
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		if err := x.service.vectorProvider.DeleteDocs(ctx, stale); err != nil {
+			return nil, fmt.Errorf("failed to delete removed tools: %w", err)
+		}
+	}
+	result.Deleted = len(stale)
+	return result, nil
+}
+
+// discover lists the tools of the gateway and remote servers
+func (x *ToolIndexer) discover(ctx context.Context) []discoveredServer {
+	servers := make([]discoveredServer, 0)
+	if x.config.Gateway {
+		for _, instance := range common.GlobalRegistry.ListInstances() {
+			if instance.Type == toolSearchServerType || instance.Server == nil {
+				continue
+			}
+			info := ServerInfo{
+				Name:   instance.Name,
+				Source: toolSourceGateway,
+				Type:   instance.Type,
+				Path:   instance.Path,
+			}
+			tools, err := listGatewayServerTools(ctx, instance.Server)
+			servers = append(servers, discoveredServer{info: info, tools: tools, err: err})
+		}
+	}
+	for _, remote := range x.config.Servers {
+		info := ServerInfo{
+			Name:   remote.Name,
+			Source: toolSourceRemote,
+			URL:    remote.URL,
+		}
+		tools, err := listRemoteServerTools(ctx, remote)
+		servers = append(servers, discoveredServer{info: info, tools: tools, err: err})
+	}
+	return servers
+}
+
+// listRemoteServerTools lists the tools of a remote server and terminates the session afterwards
+func listRemoteServerTools(ctx context.Context, remote RemoteServerConfig) ([]listedTool, error) {
+	client := newRemoteMCPClient(remote.URL, remote.Headers)
+	defer client.Close(ctx)
+	return client.ListTools(ctx)
+}
+
+// collectionKey identifies the collection written by the indexer within this process
+func (x *ToolIndexer) collectionKey() string {
+	cfg := x.service.config
+	return fmt.Sprintf("%s/%s:%d/%s/%s/%s", cfg.Provider, cfg.Host, cfg.Port, cfg.Database, cfg.Path, cfg.Collection)
+}
+
+// buildRecord embeds a tool and builds its index record, the metadata holds the callable tool definition
+func (x *ToolIndexer) buildRecord(ctx context.Context, info ServerInfo, tool listedTool, id, hash string, version int) (schema.Document, error) {
+	content := toolEmbeddingText(info, tool)
+	vector, err := x.service.embeddingClient.GetEmbedding(ctx, content)
+	if err != nil {
+		return schema.Document{}, fmt.Errorf("failed to embed tool %s of server %s: %w", tool.Name, info.Name, err)
+	}
+
+	metadata := map[string]interface{}{
+		"name":         tool.Name,
+		"description":  tool.Description,
+		"server":       serverInfoMap(info),
+		"version":      version,
+		"content_hash": hash,
+		"indexed_at":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if len(tool.InputSchema) > 0 {
+		var inputSchema interface{}
+		if err := json.Unmarshal(tool.InputSchema, &inputSchema); err != nil {
+			return schema.Document{}, fmt.Errorf("invalid input schema of tool %s of server %s: %w", tool.Name, info.Name, err)
+		}
+		metadata["inputSchema"] = inputSchema
+	}
+	if len(tool.Annotations) > 0 {
+		var annotations interface{}
+		if err := json.Unmarshal(tool.Annotations, &annotations); err == nil {
+			metadata["annotations"] = annotations
+		}
+	}
+
+	return schema.Document{
+		ID:        id,
+		Content:   content,
+		Vector:    vector,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// toolEmbeddingText builds the text embedded for a tool from its server, name, description and parameters
+func toolEmbeddingText(info ServerInfo, tool listedTool) string {
+	var sb strings.Builder
+	sb.WriteString(tool.Name)
+	if tool.Description != "" {
+		sb.WriteString("\n" + tool.Description)
+	}
+	sb.WriteString("\nServer: " + info.Name)
+
+	var inputSchema struct {
+		Properties map[string]struct {
+			Type        interface{} `json:"type"`
+			Description string      `json:"description"`
+		} `json:"properties"`
+	}
+	if len(tool.InputSchema) > 0 && json.Unmarshal(tool.InputSchema, &inputSchema) == nil && len(inputSchema.Properties) > 0 {
+		names := make([]string, 0, len(inputSchema.Properties))
+		for name := range inputSchema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("\nParameters:")
+		for _, name := range names {
+			property := inputSchema.Properties[name]
+			sb.WriteString("\n- " + name)
+			if property.Type != nil {
+				sb.WriteString(fmt.Sprintf(" (%v)", property.Type))
+			}
+			if property.Description != "" {
+				sb.WriteString(": " + property.Description)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// toolRecordID derives a stable record ID from the owning server and the tool name
+func toolRecordID(info ServerInfo, toolName string) string {
+	sum := sha256.Sum256([]byte(serverKey(info) + "\x00" + toolName))
+	return hex.EncodeToString(sum[:16])
+}
+
+// toolHash hashes everything that ends up in the index record, so any change triggers a re-embedding
+func toolHash(info ServerInfo, tool listedTool) string {
+	data, _ := json.Marshal(struct {
+		Server ServerInfo `json:"server"`
+		Tool   listedTool `json:"tool"`
+	}{info, tool})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// serverKey identifies a server across syncs
+func serverKey(info ServerInfo) string {
+	return info.Source + "/" + info.Name
+}
+
+func serverInfoMap(info ServerInfo) map[string]interface{} {
+	m := map[string]interface{}{
+		"name":   info.Name,
+		"source": info.Source,
+	}
+	if info.Type != "" {
+		m["type"] = info.Type
+	}
+	if info.Path != "" {
+		m["path"] = info.Path
+	}
+	if info.URL != "" {
+		m["url"] = info.URL
+	}
+	return m
+}
+
+// indexedServer returns the owning server of a record written by the indexer
+func indexedServer(metadata map[string]interface{}) (ServerInfo, bool) {
+	server, ok := metadata["server"].(map[string]interface{})
+	if !ok {
+		return ServerInfo{}, false
+	}
+	info := ServerInfo{}
+	info.Name, _ = server["name"].(string)
+	info.Source, _ = server["source"].(string)
+	info.Type, _ = server["type"].(string)
+	info.Path, _ = server["path"].(string)
+	info.URL, _ = server["url"].(string)
+	if info.Name == "" || (info.Source != toolSourceGateway && info.Source != toolSourceRemote) {
+		return ServerInfo{}, false
+	}
+	return info, true
+}
+
+func metadataInt(metadata map[string]interface{}, key string) int {
+	switch v := metadata[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	}
+	return 0
+}
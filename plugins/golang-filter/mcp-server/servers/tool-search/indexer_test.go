@@ -0,0 +1,245 @@
+package tool_search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/vectordb"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-session/common"
+	"github.com/envoyproxy/envoy/contrib/golang/common/go/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testDimensions = 8
+
+// newTestEmbeddingServer serves an OpenAI compatible embeddings API returning a vector derived from the input hash
+func newTestEmbeddingServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid embedding request: %v", err)
+		}
+		sum := sha256.Sum256([]byte(req.Input))
+		vector := make([]float64, testDimensions)
+		for i := range vector {
+			vector[i] = float64(sum[i]) / 255
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"model":  "test",
+			"data":   []any{map[string]any{"object": "embedding", "index": 0, "embedding": vector}},
+			"usage":  map[string]any{"prompt_tokens": 1, "total_tokens": 1},
+		})
+	}))
+}
+
+// testRemoteMCPServer is a streamable HTTP MCP server answering tools/list with server-sent events
+type testRemoteMCPServer struct {
+	mu     sync.Mutex
+	tools  []map[string]any
+	failed bool
+	// closed counts the terminated sessions
+	closed int
+}
+
+func (s *testRemoteMCPServer) setTools(tools ...map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = tools
+}
+
+func (s *testRemoteMCPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if r.Header.Get(mcpSessionIDHeader) == "session-1" {
+			s.closed++
+		}
+		return
+	}
+	var req struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	switch req.Method {
+	case "initialize":
+		w.Header().Set(mcpSessionIDHeader, "session-1")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]any{"protocolVersion": mcpProtocolVersion}})
+	case "notifications/initialized":
+		w.WriteHeader(http.StatusAccepted)
+	case "tools/list":
+		if r.Header.Get(mcpSessionIDHeader) != "session-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]any{"tools": s.tools}})
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\"}\n\n")
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+	}
+}
+
+func TestToolIndexerSync(t *testing.T) {
+	api.SetCommonCAPI(&mockCommonCAPI{})
+
+	embeddingServer := newTestEmbeddingServer(t)
+	defer embeddingServer.Close()
+	remote := &testRemoteMCPServer{}
+	remote.setTools(
+		map[string]any{"name": "create_issue", "description": "Create a GitHub issue", "inputSchema": map[string]any{"type": "object", "properties": map[string]any{"title": map[string]any{"type": "string"}}}},
+		map[string]any{"name": "list_issues", "description": "List GitHub issues", "inputSchema": map[string]any{"type": "object"}},
+	)
+	remoteServer := httptest.NewServer(remote)
+	defer remoteServer.Close()
+
+	gateway := common.NewMCPServer("weather", "1.0.0")
+	gateway.AddTool(mcp.NewToolWithRawSchema("get_weather", "Get the weather of a city", json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`)),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) { return nil, nil })
+	common.GlobalRegistry.SetInstances([]common.ServerInstance{
+		{Name: "weather", Type: "weather", Path: "/mcp-servers/weather", Server: gateway},
+		{Name: "tool-search", Type: toolSearchServerType, Path: "/mcp-servers/tool-search", Server: common.NewMCPServer("tool-search", "1.0.0")},
+	})
+	defer common.GlobalRegistry.SetInstances(nil)
+
+	service, err := NewSearchService(&config.VectorDBConfig{Provider: vectordb.PROVIDER_TYPE_EMBEDDED, Collection: "tools"},
+		NewEmbeddingClient("test", embeddingServer.URL, "test", testDimensions), testDimensions, fixedMaxTools)
+	if err != nil {
+		t.Fatalf("Failed to create search service: %v", err)
+	}
+	indexer := NewToolIndexer(IndexerConfig{
+		Enable:  true,
+		Gateway: true,
+		Servers: []RemoteServerConfig{{Name: "github", URL: remoteServer.URL}},
+	}, service)
+	ctx := context.Background()
+
+	assertSync := func(step string, expected SyncResult) {
+		t.Helper()
+		result, err := indexer.Sync(ctx)
+		if err != nil {
+			t.Fatalf("%s: sync failed: %v", step, err)
+		}
+		if result.Added != expected.Added || result.Updated != expected.Updated || result.Unchanged != expected.Unchanged ||
+			result.Deleted != expected.Deleted || len(result.Failed) != len(expected.Failed) {
+			t.Fatalf("%s: expected %+v, got %+v", step, expected, *result)
+		}
+	}
+
+	assertSync("initial", SyncResult{Added: 3})
+	assertSync("no change", SyncResult{Unchanged: 3})
+	if remote.closed != 2 {
+		t.Errorf("Expected the 2 remote sessions to be terminated, got %d", remote.closed)
+	}
+
+	// Another indexer of the same collection is syncing
+	lock, _ := syncLocks.LoadOrStore(indexer.collectionKey(), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	result, err := indexer.Sync(ctx)
+	lock.(*sync.Mutex).Unlock()
+	if err != nil || !result.Skipped {
+		t.Fatalf("Expected the sync to be skipped, got %+v, %v", result, err)
+	}
+
+	remote.setTools(
+		map[string]any{"name": "create_issue", "description": "Create an issue in a GitHub repository", "inputSchema": map[string]any{"type": "object"}},
+	)
+	assertSync("update and removal", SyncResult{Updated: 1, Unchanged: 1, Deleted: 1})
+
+	all, err := service.GetAllTools()
+	if err != nil {
+		t.Fatalf("Failed to get all tools: %v", err)
+	}
+	if len(all.Tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(all.Tools))
+	}
+	for _, tool := range all.Tools {
+		server, ok := tool["server"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("Tool %v has no server", tool["name"])
+		}
+		if _, ok := tool["version"]; ok {
+			t.Errorf("Tool %v exposes the index version", tool["name"])
+		}
+		switch tool["name"] {
+		case "create_issue":
+			if server["url"] != remoteServer.URL || server["source"] != toolSourceRemote {
+				t.Errorf("Unexpected server of create_issue: %v", server)
+			}
+			if tool["description"] != "Create an issue in a GitHub repository" {
+				t.Errorf("Unexpected description of create_issue: %v", tool["description"])
+			}
+		case "get_weather":
+			if server["path"] != "/mcp-servers/weather" || server["source"] != toolSourceGateway {
+				t.Errorf("Unexpected server of get_weather: %v", server)
+			}
+			inputSchema, _ := tool["inputSchema"].(map[string]interface{})
+			if inputSchema["type"] != "object" {
+				t.Errorf("Unexpected input schema of get_weather: %v", tool["inputSchema"])
+			}
+		default:
+			t.Errorf("Unexpected tool %v", tool["name"])
+		}
+	}
+
+	docs, err := service.vectorProvider.ListDocs(ctx, fixedMaxTools)
+	if err != nil {
+		t.Fatalf("Failed to list docs: %v", err)
+	}
+	for _, doc := range docs {
+		if doc.Metadata["name"] == "create_issue" && metadataInt(doc.Metadata, "version") != 2 {
+			t.Errorf("Expected create_issue version 2, got %v", doc.Metadata["version"])
+		}
+	}
+
+	// Tools of an unreachable server are kept
+	remote.failed = true
+	assertSync("remote failure", SyncResult{Unchanged: 1, Failed: []string{"github"}})
+
+	// Tools of servers that are no longer configured are removed
+	common.GlobalRegistry.SetInstances(nil)
+	remote.failed = false
+	assertSync("gateway server removed", SyncResult{Unchanged: 1, Deleted: 1})
+}
+
+func TestParseIndexerConfig(t *testing.T) {
+	c := &ToolSearchConfig{}
+	err := c.parseIndexerConfig(map[string]any{
+		"syncInterval": float64(60),
+		"servers": []any{
+			map[string]any{"name": "github", "url": "http://github-mcp/mcp", "headers": map[string]any{"Authorization": "Bearer x"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse indexer config: %v", err)
+	}
+	if !c.Indexer.Enable || !c.Indexer.Gateway || c.Indexer.SyncInterval.Seconds() != 60 {
+		t.Errorf("Unexpected indexer config: %+v", c.Indexer)
+	}
+	if len(c.Indexer.Servers) != 1 || c.Indexer.Servers[0].Headers["Authorization"] != "Bearer x" {
+		t.Errorf("Unexpected indexer servers: %+v", c.Indexer.Servers)
+	}
+
+	if err := c.parseIndexerConfig(map[string]any{"servers": []any{map[string]any{"name": "github"}}}); err == nil {
+		t.Error("Expected error for server without url")
+	}
+	c = &ToolSearchConfig{}
+	if err := c.parseIndexerConfig(map[string]any{"gateway": false}); err == nil {
+		t.Error("Expected error for indexer without servers")
+	}
+}
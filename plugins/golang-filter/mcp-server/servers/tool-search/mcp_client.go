@@ -0,0 +1,273 @@
+package tool_search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-session/common"
+	"github.com/envoyproxy/envoy/contrib/golang/common/go/api"
+)
+
+const (
+	// MCP protocol version introducing the streamable HTTP transport
+	mcpProtocolVersion = "2025-03-26"
+	mcpSessionIDHeader = "Mcp-Session-Id"
+	maxToolsListPages  = 100
+	remoteMCPTimeout   = 30 * time.Second
+)
+
+// listedTool is a tool returned by tools/list, the input schema is kept as is
+type listedTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+	Annotations json.RawMessage `json:"annotations,omitempty"`
+}
+
+type listToolsResult struct {
+	Tools      []listedTool `json:"tools"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type jsonRPCResponse struct {
+	ID     interface{}     `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// decodeListToolsResponse decodes a JSON-RPC tools/list response
+func decodeListToolsResponse(data []byte) (*listToolsResult, error) {
+	var resp jsonRPCResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse tools/list response: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("tools/list failed, code: %d, message: %s", resp.Error.Code, resp.Error.Message)
+	}
+	var result listToolsResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse tools/list result: %w", err)
+	}
+	return &result, nil
+}
+
+// listGatewayServerTools calls tools/list on an MCP server running in this mcp-server filter
+func listGatewayServerTools(ctx context.Context, server *common.MCPServer) ([]listedTool, error) {
+	message := server.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`))
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tools/list response: %w", err)
+	}
+	result, err := decodeListToolsResponse(data)
+	if err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// remoteMCPClient lists tools of a remote MCP server over the streamable HTTP transport
+type remoteMCPClient struct {
+	httpClient *http.Client
+	url        string
+	headers    map[string]string
+	sessionID  string
+	nextID     int
+}
+
+func newRemoteMCPClient(url string, headers map[string]string) *remoteMCPClient {
+	return &remoteMCPClient{
+		httpClient: &http.Client{Timeout: remoteMCPTimeout},
+		url:        url,
+		headers:    headers,
+	}
+}
+
+// ListTools initializes a session and pages through tools/list
+func (c *remoteMCPClient) ListTools(ctx context.Context) ([]listedTool, error) {
+	if _, err := c.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "higress-tool-search",
+			"version": Version,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+	if err := c.notify(ctx, "notifications/initialized"); err != nil {
+		return nil, fmt.Errorf("initialized notification failed: %w", err)
+	}
+
+	tools := make([]listedTool, 0)
+	cursor := ""
+	for page := 0; page < maxToolsListPages; page++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		data, err := c.call(ctx, "tools/list", params)
+		if err != nil {
+			return nil, err
+		}
+		result, err := decodeListToolsResponse(data)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+	api.LogWarnf("tools/list of %s has more than %d pages, the remaining tools are ignored", c.url, maxToolsListPages)
+	return tools, nil
+}
+
+// Close terminates the session opened by ListTools, failures are only logged since the server expires it anyway
+func (c *remoteMCPClient) Close(ctx context.Context) {
+	if c.sessionID == "" {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.url, nil)
+	if err != nil {
+		api.LogWarnf("Failed to create session termination request of %s: %v", c.url, err)
+		return
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set(mcpSessionIDHeader, c.sessionID)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		api.LogWarnf("Failed to terminate session of %s: %v", c.url, err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	// Servers not supporting session termination answer 405
+	if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode != http.StatusMethodNotAllowed {
+		api.LogWarnf("Failed to terminate session of %s, status: %d", c.url, resp.StatusCode)
+	}
+	c.sessionID = ""
+}
+
+// call sends a JSON-RPC request and returns the raw response message
+func (c *remoteMCPClient) call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	c.nextID++
+	id := c.nextID
+	resp, err := c.post(ctx, jsonRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if sessionID := resp.Header.Get(mcpSessionIDHeader); sessionID != "" {
+		c.sessionID = sessionID
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s returned status %d: %s", method, resp.StatusCode, string(body))
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return io.ReadAll(resp.Body)
+	}
+	// The response is streamed as server-sent events, find the message answering this request
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var event bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		if data, ok := strings.CutPrefix(line, "data:"); ok {
+			event.WriteString(strings.TrimPrefix(data, " "))
+			continue
+		}
+		if line != "" || event.Len() == 0 {
+			continue
+		}
+		if data := event.Bytes(); isResponseTo(data, id) {
+			return data, nil
+		}
+		event.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s event stream: %w", method, err)
+	}
+	if data := event.Bytes(); isResponseTo(data, id) {
+		return data, nil
+	}
+	return nil, fmt.Errorf("no response to %s in event stream", method)
+}
+
+// notify sends a JSON-RPC notification
+func (c *remoteMCPClient) notify(ctx context.Context, method string) error {
+	resp, err := c.post(ctx, jsonRPCRequest{JSONRPC: "2.0", Method: method})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s returned status %d", method, resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *remoteMCPClient) post(ctx context.Context, message jsonRPCRequest) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", message.Method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", message.Method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	if c.sessionID != "" {
+		req.Header.Set(mcpSessionIDHeader, c.sessionID)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", message.Method, err)
+	}
+	return resp, nil
+}
+
+// isResponseTo reports whether data is the JSON-RPC response to the request with the given id
+func isResponseTo(data []byte, id int) bool {
+	var resp jsonRPCResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return false
+	}
+	if resp.Result == nil && resp.Error == nil {
+		return false
+	}
+	switch v := resp.ID.(type) {
+	case float64:
+		return int(v) == id
+	case string:
+		return v == fmt.Sprint(id)
+	}
+	return false
+}
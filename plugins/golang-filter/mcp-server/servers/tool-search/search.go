@@ -7,12 +7,13 @@ import (
 
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/schema"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/vectordb"
 	"github.com/envoyproxy/envoy/contrib/golang/common/go/api"
 )
 
 // SearchService handles tool search operations
 type SearchService struct {
-	milvusProvider  *MilvusVectorStoreProvider
+	vectorProvider  vectordb.VectorStoreProvider
 	config          *config.VectorDBConfig
 	tableName       string
 	dimensions      int
@@ -20,34 +21,22 @@ type SearchService struct {
 	embeddingClient *EmbeddingClient
 }
 
-// NewSearchService creates a new SearchService instance
-func NewSearchService(host string, port int, database, username, password, tableName string, embeddingClient *EmbeddingClient, dimensions int, maxTools int) *SearchService {
-	// Create Milvus configuration
-	cfg := &config.VectorDBConfig{
-		Provider:   "milvus",
-		Host:       host,
-		Port:       port,
-		Database:   database,
-		Collection: tableName,
-		Username:   username,
-		Password:   password,
-	}
-
-	// Create Milvus provider
-	provider, err := NewMilvusVectorStoreProvider(cfg, dimensions)
+// NewSearchService creates a new SearchService instance backed by the configured vector store provider
+func NewSearchService(cfg *config.VectorDBConfig, embeddingClient *EmbeddingClient, dimensions int, maxTools int) (*SearchService, error) {
+	provider, err := vectordb.NewVectorDBProvider(cfg, dimensions)
 	if err != nil {
-		api.LogErrorf("Failed to create Milvus provider: %v", err)
-		return nil
+		api.LogErrorf("Failed to create %s provider: %v", cfg.Provider, err)
+		return nil, fmt.Errorf("failed to create vector store provider: %w", err)
 	}
 
 	return &SearchService{
-		milvusProvider:  provider,
+		vectorProvider:  provider,
 		config:          cfg,
-		tableName:       tableName,
+		tableName:       cfg.Collection,
 		dimensions:      dimensions,
 		maxTools:        maxTools, // 使用写死的值
 		embeddingClient: embeddingClient,
-	}
+	}, nil
 }
 
 // ToolSearchResult represents the result of a tool search
@@ -58,6 +47,39 @@ type ToolSearchResult struct {
 // ToolDefinition represents a tool definition in the search result
 type ToolDefinition map[string]interface{}
 
+// toolDefinitionFields are the record metadata fields returned for tools written by the indexer:
+// the definition needed to call the tool plus the server owning it
+var toolDefinitionFields = []string{"name", "description", "inputSchema", "annotations", "server"}
+
+// toToolDefinition converts a database record to a tool definition
+func toToolDefinition(record ToolRecord) ToolDefinition {
+	var tool ToolDefinition
+
+	if _, ok := indexedServer(record.Metadata); ok {
+		// Indexed tools carry bookkeeping fields such as version and content_hash, only return the callable definition
+		tool = make(ToolDefinition, len(toolDefinitionFields))
+		for _, field := range toolDefinitionFields {
+			if value, exists := record.Metadata[field]; exists {
+				tool[field] = value
+			}
+		}
+	} else if len(record.Metadata) > 0 {
+		// Use metadata if available
+		tool = record.Metadata
+		api.LogDebugf("Successfully parsed metadata for tool %s", record.Name)
+	} else {
+		api.LogDebugf("No metadata found for tool %s, using basic definition", record.Name)
+		// If no metadata, create a basic tool definition
+		tool = ToolDefinition{
+			"name":        record.Name,
+			"description": record.Content,
+		}
+	}
+
+	tool["name"] = record.Name
+	return tool
+}
+
 // SearchTools performs semantic search for tools
 func (s *SearchService) SearchTools(ctx context.Context, query string, topK int) (*ToolSearchResult, error) {
 	api.LogInfof("Starting tool search for query: '%s', topK: %d", query, topK)
@@ -89,24 +111,7 @@ func (s *SearchService) convertRecordsToResult(records []ToolRecord) *ToolSearch
 
 	tools := make([]ToolDefinition, 0, len(records))
 	for i, record := range records {
-		var tool ToolDefinition
-
-		// Use metadata if available
-		if len(record.Metadata) > 0 {
-			tool = record.Metadata
-			api.LogDebugf("Successfully parsed metadata for tool %s", record.Name)
-		} else {
-			api.LogDebugf("No metadata found for tool %s, using basic definition", record.Name)
-			// If no metadata, create a basic tool definition
-			tool = ToolDefinition{
-				"name":        record.Name,
-				"description": record.Content,
-			}
-		}
-
-		// Update the name to include server name
-		tool["name"] = fmt.Sprintf("%s", record.Name)
-
+		tool := toToolDefinition(record)
 		tools = append(tools, tool)
 
 		api.LogDebugf("Tool %d: %s - %s", i+1, tool["name"], record.Content)
@@ -130,25 +135,7 @@ func (s *SearchService) GetAllTools() (*ToolSearchResult, error) {
 	// Convert records to tool definitions
 	tools := make([]ToolDefinition, 0, len(records))
 	for _, record := range records {
-		var tool ToolDefinition
-
-		// Use metadata if available
-		if len(record.Metadata) > 0 {
-			tool = record.Metadata
-			api.LogDebugf("Successfully parsed metadata for tool %s", record.Name)
-		} else {
-			api.LogDebugf("No metadata found for tool %s, using basic definition", record.Name)
-			// If no metadata, create a basic tool definition
-			tool = ToolDefinition{
-				"name":        record.Name,
-				"description": record.Content,
-			}
-		}
-
-		// Update the name to include server name
-		tool["name"] = fmt.Sprintf("%s", record.Name)
-
-		tools = append(tools, tool)
+		tools = append(tools, toToolDefinition(record))
 	}
 
 	api.LogInfof("Successfully converted %d tools", len(tools))
@@ -166,7 +153,6 @@ type ToolRecord struct {
 func (s *SearchService) searchToolsInDB(query string, vector []float32, topK int) ([]ToolRecord, error) {
 	api.LogInfof("Performing vector search for query: '%s', topK: %d", query, topK)
 
-	// For Milvus, we'll perform vector search directly
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -175,7 +161,7 @@ func (s *SearchService) searchToolsInDB(query string, vector []float32, topK int
 		TopK: topK,
 	}
 
-	results, err := s.milvusProvider.SearchDocs(ctx, vector, searchOptions)
+	results, err := s.vectorProvider.SearchDocs(ctx, vector, searchOptions)
 	if err != nil {
 		api.LogErrorf("Vector search failed: %v", err)
 		return nil, fmt.Errorf("failed to perform vector search: %w", err)
@@ -210,7 +196,7 @@ func (s *SearchService) getAllToolsFromDB() ([]ToolRecord, error) {
 	defer cancel()
 
 	// Retrieve all documents with limit
-	docs, err := s.milvusProvider.ListAllDocs(ctx, s.maxTools)
+	docs, err := s.vectorProvider.ListDocs(ctx, s.maxTools)
 	if err != nil {
 		api.LogErrorf("Failed to list documents: %v", err)
 		return nil, fmt.Errorf("failed to list documents: %w", err)
@@ -3,7 +3,10 @@ package tool_search
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/config"
+	"github.com/alibaba/higress/plugins/golang-filter/mcp-server/servers/rag/vectordb"
 	"github.com/alibaba/higress/plugins/golang-filter/mcp-session/common"
 	"github.com/envoyproxy/envoy/contrib/golang/common/go/api"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -19,6 +22,8 @@ const (
 	defaultDimensions = 1024
 	// 写死最大工具数量为1000，仅用于单测
 	fixedMaxTools = 1000
+	// 默认工具索引同步间隔
+	defaultSyncInterval = 5 * time.Minute
 )
 
 func init() {
@@ -34,6 +39,8 @@ type VectorConfig struct {
 	Database     string  `json:"database"`
 	Username     string  `json:"username"`
 	Password     string  `json:"password"`
+	SSLMode      string  `json:"sslmode"`
+	Path         string  `json:"path"`
 }
 
 type EmbeddingConfig struct {
@@ -43,9 +50,25 @@ type EmbeddingConfig struct {
 	Dimensions int    `json:"dimensions"`
 }
 
+// RemoteServerConfig is a remote MCP server reachable over the streamable HTTP transport
+type RemoteServerConfig struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+type IndexerConfig struct {
+	Enable bool `json:"enable"`
+	// Gateway enables indexing the MCP servers configured in the same mcp-server filter
+	Gateway      bool                 `json:"gateway"`
+	SyncInterval time.Duration        `json:"syncInterval"`
+	Servers      []RemoteServerConfig `json:"servers"`
+}
+
 type ToolSearchConfig struct {
 	Vector      VectorConfig    `json:"vector"`
 	Embedding   EmbeddingConfig `json:"embedding"`
+	Indexer     IndexerConfig   `json:"indexer"`
 	description string
 }
 
@@ -70,6 +93,14 @@ func (c *ToolSearchConfig) ParseConfig(config map[string]any) error {
 		return fmt.Errorf("failed to parse embedding config: %w", err)
 	}
 
+	// Optional indexer configuration
+	c.Indexer = IndexerConfig{}
+	if indexerConfig, ok := config["indexer"].(map[string]any); ok {
+		if err := c.parseIndexerConfig(indexerConfig); err != nil {
+			return fmt.Errorf("failed to parse indexer config: %w", err)
+		}
+	}
+
 	// Optional description
 	if description, ok := config["description"].(string); ok {
 		c.description = description
@@ -88,27 +119,32 @@ func (c *ToolSearchConfig) parseVectorConfig(config map[string]any) error {
 		return errors.New("missing vector.type")
 	}
 
-	if c.Vector.Type != "milvus" {
-		return fmt.Errorf("unsupported vector.type: %s, only 'milvus' is supported", c.Vector.Type)
-	}
-
-	if host, ok := config["host"].(string); ok {
-		c.Vector.Host = host
-	} else {
-		return errors.New("missing vector.host")
-	}
-
-	if port, ok := config["port"].(float64); ok {
-		c.Vector.Port = int(port)
-	} else if port, ok := config["port"].(int); ok {
-		c.Vector.Port = port
-	} else {
-		return errors.New("missing vector.port")
+	switch c.Vector.Type {
+	case vectordb.PROVIDER_TYPE_MILVUS, vectordb.PROVIDER_TYPE_PGVECTOR:
+		if host, ok := config["host"].(string); ok {
+			c.Vector.Host = host
+		} else {
+			return errors.New("missing vector.host")
+		}
+
+		if port, ok := config["port"].(float64); ok {
+			c.Vector.Port = int(port)
+		} else if port, ok := config["port"].(int); ok {
+			c.Vector.Port = port
+		} else {
+			return errors.New("missing vector.port")
+		}
+	case vectordb.PROVIDER_TYPE_EMBEDDED:
+		if path, ok := config["path"].(string); ok {
+			c.Vector.Path = path
+		}
+	default:
+		return fmt.Errorf("unsupported vector.type: %s, only 'milvus', 'pgvector' and 'embedded' are supported", c.Vector.Type)
 	}
 
 	if database, ok := config["database"].(string); ok {
 		c.Vector.Database = database
-	} else {
+	} else if c.Vector.Type == vectordb.PROVIDER_TYPE_MILVUS {
 		c.Vector.Database = "default" // 默认数据库
 	}
 
@@ -126,6 +162,10 @@ func (c *ToolSearchConfig) parseVectorConfig(config map[string]any) error {
 		c.Vector.Password = password
 	}
 
+	if sslMode, ok := config["sslmode"].(string); ok {
+		c.Vector.SSLMode = sslMode
+	}
+
 	// 移除maxTools的解析逻辑
 
 	return nil
@@ -163,6 +203,73 @@ func (c *ToolSearchConfig) parseEmbeddingConfig(config map[string]any) error {
 	return nil
 }
 
+func (c *ToolSearchConfig) parseIndexerConfig(config map[string]any) error {
+	c.Indexer.Enable = true
+	if enable, ok := config["enable"].(bool); ok {
+		c.Indexer.Enable = enable
+	}
+
+	c.Indexer.Gateway = true
+	if gateway, ok := config["gateway"].(bool); ok {
+		c.Indexer.Gateway = gateway
+	}
+
+	// syncInterval 单位为秒
+	c.Indexer.SyncInterval = defaultSyncInterval
+	if interval, ok := config["syncInterval"].(float64); ok {
+		c.Indexer.SyncInterval = time.Duration(interval * float64(time.Second))
+	} else if interval, ok := config["syncInterval"].(int); ok {
+		c.Indexer.SyncInterval = time.Duration(interval) * time.Second
+	}
+	if c.Indexer.SyncInterval <= 0 {
+		return errors.New("indexer.syncInterval must be positive")
+	}
+
+	if servers, ok := config["servers"].([]any); ok {
+		for i, item := range servers {
+			server, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("indexer.servers[%d] must be an object", i)
+			}
+			remote := RemoteServerConfig{Headers: make(map[string]string)}
+			if remote.Name, ok = server["name"].(string); !ok || remote.Name == "" {
+				return fmt.Errorf("missing indexer.servers[%d].name", i)
+			}
+			if remote.URL, ok = server["url"].(string); !ok || remote.URL == "" {
+				return fmt.Errorf("missing indexer.servers[%d].url", i)
+			}
+			if headers, ok := server["headers"].(map[string]any); ok {
+				for key, value := range headers {
+					if v, ok := value.(string); ok {
+						remote.Headers[key] = v
+					}
+				}
+			}
+			c.Indexer.Servers = append(c.Indexer.Servers, remote)
+		}
+	}
+
+	if c.Indexer.Enable && !c.Indexer.Gateway && len(c.Indexer.Servers) == 0 {
+		return errors.New("indexer has no gateway or remote servers to index")
+	}
+	return nil
+}
+
+// vectorDBConfig converts the vector configuration into the shared vectordb provider configuration
+func (c *ToolSearchConfig) vectorDBConfig() *config.VectorDBConfig {
+	return &config.VectorDBConfig{
+		Provider:   c.Vector.Type,
+		Host:       c.Vector.Host,
+		Port:       c.Vector.Port,
+		Database:   c.Vector.Database,
+		Collection: c.Vector.TableName,
+		Username:   c.Vector.Username,
+		Password:   c.Vector.Password,
+		SSLMode:    c.Vector.SSLMode,
+		Path:       c.Vector.Path,
+	}
+}
+
 func (c *ToolSearchConfig) NewServer(serverName string) (*common.MCPServer, error) {
 	mcpServer := common.NewMCPServer(
 		serverName,
@@ -174,17 +281,20 @@ func (c *ToolSearchConfig) NewServer(serverName string) (*common.MCPServer, erro
 	embeddingClient := NewEmbeddingClient(c.Embedding.APIKey, c.Embedding.BaseURL, c.Embedding.Model, c.Embedding.Dimensions)
 
 	// Create search service，使用写死的fixedMaxTools值
-	searchService := NewSearchService(
-		c.Vector.Host,
-		c.Vector.Port,
-		c.Vector.Database,
-		c.Vector.Username,
-		c.Vector.Password,
-		c.Vector.TableName,
+	searchService, err := NewSearchService(
+		c.vectorDBConfig(),
 		embeddingClient,
 		c.Embedding.Dimensions,
 		fixedMaxTools, // 使用写死的值
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search service: %w", err)
+	}
+
+	// Keep the tool index in sync with the MCP servers until the server is destroyed
+	if c.Indexer.Enable {
+		NewToolIndexer(c.Indexer, searchService).Start(mcpServer.GetDestoryChannel())
+	}
 
 	// Add tool search tool
 	mcpServer.AddTool(
@@ -1,5 +1,7 @@
 package common
 
+import "sync"
+
 var GlobalRegistry = NewServerRegistry()
 
 type Server interface {
@@ -7,8 +9,19 @@ type Server interface {
 	NewServer(serverName string) (*MCPServer, error)
 }
 
+// ServerInstance is a running MCP server configured in the mcp-server filter
+type ServerInstance struct {
+	Name   string
+	Type   string
+	Path   string
+	Server *MCPServer
+}
+
 type ServerRegistry struct {
 	servers map[string]Server
+
+	mu        sync.RWMutex
+	instances []ServerInstance
 }
 
 func NewServerRegistry() *ServerRegistry {
@@ -24,3 +37,17 @@ func (r *ServerRegistry) RegisterServer(name string, server Server) {
 func (r *ServerRegistry) GetServer(name string) Server {
 	return r.servers[name]
 }
+
+// SetInstances replaces the running server instances after the mcp-server filter config is parsed
+func (r *ServerRegistry) SetInstances(instances []ServerInstance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances = instances
+}
+
+// ListInstances returns the running server instances of the latest mcp-server filter config
+func (r *ServerRegistry) ListInstances() []ServerInstance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ServerInstance(nil), r.instances...)
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-agent/dashscope"
 	"github.com/higress-group/wasm-go/pkg/wrapper"
 	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v2"
@@ -15,13 +18,14 @@ type Message struct {
 }
 
 type Request struct {
-	Model            string    `json:"model"`
-	Messages         []Message `json:"messages"`
-	FrequencyPenalty float64   `json:"frequency_penalty"`
-	PresencePenalty  float64   `json:"presence_penalty"`
-	Stream           bool      `json:"stream"`
-	Temperature      float64   `json:"temperature"`
-	Topp             int32     `json:"top_p"`
+	Model            string           `json:"model"`
+	Messages         []Message        `json:"messages"`
+	FrequencyPenalty float64          `json:"frequency_penalty"`
+	PresencePenalty  float64          `json:"presence_penalty"`
+	Stream           bool             `json:"stream"`
+	Temperature      float64          `json:"temperature"`
+	Topp             int32            `json:"top_p"`
+	Tools            []dashscope.Tool `json:"tools,omitempty"`
 }
 
 type Choice struct {
@@ -53,6 +57,8 @@ type ToolsParam struct {
 	ParamName   []string `yaml:"paramName"`
 	Parameter   string   `yaml:"parameter"`
 	Description string   `yaml:"description"`
+	// function calling 模式下提供给大模型的参数 JSON Schema
+	Schema map[string]interface{} `yaml:"-"`
 }
 
 // 用于存放拆解出来的api相关信息
@@ -168,6 +174,33 @@ type APIs struct {
 	API         string      `required:"true" yaml:"api" json:"api"`
 }
 
+type MCPServer struct {
+	// @Title zh-CN MCP Server 名称
+	// @Description zh-CN 用于区分不同 MCP Server 的名称，提供给大模型的工具名格式为 ${name}___${toolName}
+	Name string `required:"true" yaml:"name" json:"name"`
+	// @Title zh-CN 服务名称
+	// @Description zh-CN 带服务类型的完整 FQDN 名称，例如 my-mcp.dns、mcp.my-ns.svc.cluster.local
+	ServiceName string `required:"true" yaml:"serviceName" json:"serviceName"`
+	// @Title zh-CN 服务端口
+	// @Description zh-CN 服务端口
+	ServicePort int64 `required:"true" yaml:"servicePort" json:"servicePort"`
+	// @Title zh-CN 服务域名
+	// @Description zh-CN 服务域名
+	Domain string `required:"true" yaml:"domain" json:"domain"`
+	// @Title zh-CN Streamable HTTP 端点路径
+	// @Description zh-CN MCP Server 的 Streamable HTTP 端点路径，默认 /mcp
+	Path string `yaml:"path" json:"path"`
+	// @Title zh-CN 请求头
+	// @Description zh-CN 访问 MCP Server 时附加的请求头，例如认证信息
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	// @Title zh-CN 允许使用的工具
+	// @Description zh-CN 允许提供给大模型的工具名列表，为空则使用全部工具
+	AllowTools []string `yaml:"allowTools" json:"allowTools"`
+	// @Title zh-CN 每一次请求 MCP Server 的超时时间
+	// @Description zh-CN 每一次请求 MCP Server 的超时时间，单位毫秒，默认50000
+	MaxExecutionTime int64 `yaml:"maxExecutionTime" json:"maxExecutionTime"`
+}
+
 type Template struct {
 	Question    string `yaml:"question" json:"question"`
 	Thought1    string `yaml:"thought1" json:"thought1"`
@@ -235,6 +268,17 @@ type PluginConfig struct {
 	APIsParam      []APIsParam        `yaml:"-" json:"-"`
 	PromptTemplate PromptTemplate     `yaml:"promptTemplate" json:"promptTemplate"`
 	JsonResp       JsonResp           `yaml:"jsonResp" json:"jsonResp"`
+	// @Title zh-CN Agent 模式
+	// @Description zh-CN react 为基于提示词解析的 ReAct 模式，function_calling 为使用大模型原生 tools/tool_calls 的模式，默认 react
+	Mode string `yaml:"mode" json:"mode"`
+	// @Title zh-CN MCP Server 工具来源
+	// @Description zh-CN 通过 Streamable HTTP 访问的 MCP Server，仅 function_calling 模式可用
+	MCPServers []MCPServer          `yaml:"mcpServers" json:"mcpServers"`
+	MCPClient  []wrapper.HttpClient `yaml:"-" json:"-"`
+	MCPState   *MCPState            `yaml:"-" json:"-"`
+	// @Title zh-CN 是否输出中间步骤
+	// @Description zh-CN function_calling 模式下流式请求是否将工具调用过程以 reasoning_content 的形式返回，默认 true
+	StreamIntermediateSteps bool `yaml:"streamIntermediateSteps" json:"streamIntermediateSteps"`
 }
 
 func initResponsePromptTpl(gjson gjson.Result, c *PluginConfig) {
@@ -245,13 +289,98 @@ func initResponsePromptTpl(gjson gjson.Result, c *PluginConfig) {
 	}
 }
 
+func initMode(gjson gjson.Result, c *PluginConfig) error {
+	c.Mode = gjson.Get("mode").String()
+	if c.Mode == "" {
+		c.Mode = ModeReAct
+	}
+	if c.Mode != ModeReAct && c.Mode != ModeFunctionCalling {
+		return fmt.Errorf("invalid mode: %s, must be one of %s, %s", c.Mode, ModeReAct, ModeFunctionCalling)
+	}
+	c.StreamIntermediateSteps = true
+	if streamIntermediateSteps := gjson.Get("streamIntermediateSteps"); streamIntermediateSteps.Exists() {
+		c.StreamIntermediateSteps = streamIntermediateSteps.Bool()
+	}
+	return nil
+}
+
+func initMCPServers(gjson gjson.Result, c *PluginConfig) error {
+	c.MCPState = &MCPState{}
+	mcpServers := gjson.Get("mcpServers").Array()
+	if len(mcpServers) == 0 {
+		return nil
+	}
+	if c.Mode != ModeFunctionCalling {
+		return errors.New("mcpServers is only supported in function_calling mode")
+	}
+
+	names := make(map[string]struct{})
+	for _, item := range mcpServers {
+		var server MCPServer
+		server.Name = item.Get("name").String()
+		if server.Name == "" {
+			return errors.New("mcpServers name is required")
+		}
+		if strings.Contains(server.Name, MCPToolNameSeparator) {
+			return fmt.Errorf("mcpServers name %s cannot contain %s", server.Name, MCPToolNameSeparator)
+		}
+		if _, ok := names[server.Name]; ok {
+			return fmt.Errorf("duplicate mcpServers name: %s", server.Name)
+		}
+		names[server.Name] = struct{}{}
+
+		server.ServiceName = item.Get("serviceName").String()
+		if server.ServiceName == "" {
+			return errors.New("mcpServers serviceName is required")
+		}
+		server.ServicePort = item.Get("servicePort").Int()
+		if server.ServicePort == 0 {
+			return errors.New("mcpServers servicePort is required")
+		}
+		server.Domain = item.Get("domain").String()
+		if server.Domain == "" {
+			return errors.New("mcpServers domain is required")
+		}
+		server.Path = item.Get("path").String()
+		if server.Path == "" {
+			server.Path = "/mcp"
+		}
+		server.MaxExecutionTime = item.Get("maxExecutionTime").Int()
+		if server.MaxExecutionTime == 0 {
+			server.MaxExecutionTime = 50000
+		}
+		server.Headers = make(map[string]string)
+		for key, value := range item.Get("headers").Map() {
+			server.Headers[key] = value.String()
+		}
+		for _, tool := range item.Get("allowTools").Array() {
+			server.AllowTools = append(server.AllowTools, tool.String())
+		}
+
+		c.MCPServers = append(c.MCPServers, server)
+		c.MCPClient = append(c.MCPClient, wrapper.NewClusterClient(wrapper.FQDNCluster{
+			FQDN: server.ServiceName,
+			Port: server.ServicePort,
+			Host: server.Domain,
+		}))
+	}
+	return nil
+}
+
 func initAPIs(gjson gjson.Result, c *PluginConfig) error {
 	//从插件配置中获取apis信息
 	apis := gjson.Get("apis")
 	if !apis.Exists() {
+		// 配置了 MCP Server 时 apis 可以为空
+		if len(c.MCPServers) > 0 {
+			return nil
+		}
 		return errors.New("apis is required")
 	}
 	if len(apis.Array()) == 0 {
+		if len(c.MCPServers) > 0 {
+			return nil
+		}
 		return errors.New("apis cannot be empty")
 	}
 
@@ -321,6 +450,7 @@ func initAPIs(gjson gjson.Result, c *PluginConfig) error {
 					out, _ := json.Marshal(submap.Parameters)
 					param.Parameter = string(out)
 					param.Description = submap.Description
+					param.Schema = parametersToSchema(submap.Parameters)
 				} else if method == "post" {
 					param.Method = "POST"
 					schema := submap.RequestBody.Content["application/json"].Schema
@@ -328,6 +458,7 @@ func initAPIs(gjson gjson.Result, c *PluginConfig) error {
 					param.Description = submap.Summary
 					out, _ := json.Marshal(schema.Properties)
 					param.Parameter = string(out)
+					param.Schema = requestBodyToSchema(schema)
 				}
 				allTool_param = append(allTool_param, param)
 			}
@@ -344,6 +475,70 @@ func initAPIs(gjson gjson.Result, c *PluginConfig) error {
 	return nil
 }
 
+// 将 GET 方法的 OpenAPI 参数转换为 JSON Schema
+func parametersToSchema(parameters []Parameter) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	for _, parameter := range parameters {
+		property := map[string]interface{}{
+			"type": "string",
+		}
+		if parameter.Schema.Type != "" {
+			property["type"] = parameter.Schema.Type
+		}
+		if parameter.Description != "" {
+			property["description"] = parameter.Description
+		}
+		if len(parameter.Schema.Enum) > 0 {
+			property["enum"] = parameter.Schema.Enum
+		}
+		properties[parameter.Name] = property
+		if parameter.Required {
+			required = append(required, parameter.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// 将 POST 方法的 OpenAPI 请求体转换为 JSON Schema
+func requestBodyToSchema(schema Schema) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for name, prop := range schema.Properties {
+		property := map[string]interface{}{
+			"type": "string",
+		}
+		if prop.Type != "" {
+			property["type"] = prop.Type
+		}
+		if prop.Description != "" {
+			property["description"] = prop.Description
+		}
+		if len(prop.Enum) > 0 {
+			property["enum"] = prop.Enum
+		}
+		if prop.Items != nil {
+			property["items"] = map[string]interface{}{"type": prop.Items.Type}
+		}
+		if prop.MaxItems > 0 {
+			property["maxItems"] = prop.MaxItems
+		}
+		properties[name] = property
+	}
+	required := schema.Required
+	if required == nil {
+		required = make([]string, 0)
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
 func initReActPromptTpl(gjson gjson.Result, c *PluginConfig) {
 	c.PromptTemplate.Language = gjson.Get("promptTemplate.language").String()
 	if c.PromptTemplate.Language != "EN" && c.PromptTemplate.Language != "CH" {
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-agent/dashscope"
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm"
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
+	"github.com/higress-group/wasm-go/pkg/log"
+	"github.com/higress-group/wasm-go/pkg/wrapper"
+	"github.com/tidwall/gjson"
+)
+
+// agent 模式
+const (
+	ModeReAct           = "react"
+	ModeFunctionCalling = "function_calling"
+)
+
+// function calling 模式下保存在请求上下文中的对话记录，以及是否已经开始流式输出中间步骤
+const (
+	MessagesContextKey       = "FunctionCallingMessages"
+	StepsStreamingContextKey = "StepsStreaming"
+)
+
+// 中间步骤中工具结果的最大长度，避免输出过长
+const maxStepResultLength = 500
+
+type streamDelta struct {
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
+type streamChoice struct {
+	Index        int         `json:"index"`
+	Delta        streamDelta `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
+
+type streamChunk struct {
+	ID      string         `json:"id"`
+	Choices []streamChoice `json:"choices"`
+	Model   string         `json:"model"`
+	Object  string         `json:"object"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// 汇总 OpenAPI 和 MCP Server 提供的工具
+func buildFunctionTools(config PluginConfig) []dashscope.Tool {
+	tools := make([]dashscope.Tool, 0)
+	for _, apisParam := range config.APIsParam {
+		for _, toolsParam := range apisParam.ToolsParam {
+			tools = append(tools, dashscope.Tool{
+				Type: "function",
+				Function: dashscope.ToolFunction{
+					Name:        toolsParam.ToolName,
+					Description: toolsParam.Description,
+					Parameters:  toolsParam.Schema,
+				},
+			})
+		}
+	}
+	if config.MCPState != nil {
+		for _, mcpTool := range config.MCPState.Tools {
+			tools = append(tools, mcpTool.Tool)
+		}
+	}
+	return tools
+}
+
+func onFunctionCallingRequestBody(ctx wrapper.HttpContext, config PluginConfig, rawRequest Request, log log.Log) types.Action {
+	if len(rawRequest.Messages) == 0 {
+		return types.ActionContinue
+	}
+
+	messages := make([]dashscope.Message, 0, len(rawRequest.Messages))
+	for _, message := range rawRequest.Messages {
+		messages = append(messages, dashscope.Message{Role: message.Role, Content: message.Content})
+	}
+	ctx.SetContext(ToolCallsCount, 0)
+	ctx.SetContext(MessagesContextKey, messages)
+	if rawRequest.Stream {
+		ctx.SetContext(StreamContextKey, struct{}{})
+		rawRequest.Stream = false
+	}
+
+	// 首次请求或上次加载失败时从 MCP Server 拉取工具列表
+	if loadMCPTools(config, log, func() {
+		replaceFunctionCallingRequest(config, rawRequest, log)
+		proxywasm.ResumeHttpRequest()
+	}) {
+		return types.ActionPause
+	}
+	replaceFunctionCallingRequest(config, rawRequest, log)
+	return types.ActionContinue
+}
+
+func replaceFunctionCallingRequest(config PluginConfig, rawRequest Request, log log.Log) {
+	rawRequest.Tools = buildFunctionTools(config)
+	newbody, err := json.Marshal(rawRequest)
+	if err != nil {
+		log.Debugf("[onHttpRequestBody] marshal request err: %s", err.Error())
+		return
+	}
+	log.Debugf("[onHttpRequestBody] newRequestBody: %s", string(newbody))
+	if err := proxywasm.ReplaceHttpRequestBody(newbody); err != nil {
+		log.Debugf("failed replace err: %s", err.Error())
+		proxywasm.SendHttpResponse(200, [][2]string{{"content-type", "application/json; charset=utf-8"}}, []byte(fmt.Sprintf(config.ReturnResponseTemplate, "替换失败"+err.Error())), -1)
+	}
+}
+
+func onFunctionCallingResponseBody(ctx wrapper.HttpContext, config PluginConfig, body []byte, rawResponse Response, log log.Log) types.Action {
+	var message dashscope.Message
+	if err := json.Unmarshal([]byte(gjson.GetBytes(body, "choices.0.message").Raw), &message); err != nil {
+		log.Debugf("[onHttpResponseBody] message to json err: %s", err.Error())
+		return types.ActionContinue
+	}
+
+	if len(message.ToolCalls) == 0 {
+		// 大模型直接给出了答案
+		if config.JsonResp.Enable {
+			jsonFormat(config.LLMClient, config.LLMInfo, config.JsonResp.JsonSchema, Message{}, message.Content, llmHeaders(config.LLMInfo), ctx.GetContext(StreamContextKey) != nil, rawResponse, log)
+			return types.ActionPause
+		}
+		if ctx.GetContext(StreamContextKey) != nil {
+			replaceAnswerStreamBody(ctx, message.Content, rawResponse)
+		}
+		return types.ActionContinue
+	}
+
+	if !runFunctionCalling(ctx, config, message, rawResponse, log) {
+		return types.ActionContinue
+	}
+	return types.ActionPause
+}
+
+func llmHeaders(llmInfo LLMInfo) [][2]string {
+	return [][2]string{{"Content-Type", "application/json"}, {"Authorization", "Bearer " + llmInfo.APIKey}}
+}
+
+// 并行执行本轮所有的工具调用，全部完成后将结果交给大模型，返回 false 表示已达到最大迭代次数
+func runFunctionCalling(ctx wrapper.HttpContext, config PluginConfig, message dashscope.Message, rawResponse Response, log log.Log) bool {
+	count := ctx.GetContext(ToolCallsCount).(int)
+	count++
+	log.Debugf("toolCallsCount:%d, config.LLMInfo.MaxIterations=%d", count, config.LLMInfo.MaxIterations)
+	// 达到了预设的循环次数，强制结束
+	if int64(count) > config.LLMInfo.MaxIterations {
+		ctx.SetContext(ToolCallsCount, 0)
+		return false
+	}
+	ctx.SetContext(ToolCallsCount, count)
+
+	messages, _ := ctx.GetContext(MessagesContextKey).([]dashscope.Message)
+	message.Role = dashscope.RoleAssistant
+	messages = append(messages, message)
+	ctx.SetContext(MessagesContextKey, messages)
+
+	results := make([]string, len(message.ToolCalls))
+	pending := len(message.ToolCalls)
+	finish := func(i int, result string) {
+		results[i] = result
+		pending--
+		if pending > 0 {
+			return
+		}
+		toolCallsResult(ctx, config, message.ToolCalls, results, rawResponse, log)
+	}
+
+	for i, toolCall := range message.ToolCalls {
+		i := i
+		emitStep(ctx, config, fmt.Sprintf("Action: %s\nAction Input: %s", toolCall.Function.Name, toolCall.Function.Arguments), rawResponse, log)
+		err := dispatchToolCall(config, toolCall, log, func(result string) {
+			finish(i, result)
+		})
+		if err != nil {
+			log.Debugf("tool calls error: %s", err.Error())
+			finish(i, "error: "+err.Error())
+		}
+	}
+	return true
+}
+
+func dispatchToolCall(config PluginConfig, toolCall dashscope.ToolCall, log log.Log, callback func(result string)) error {
+	name := toolCall.Function.Name
+	log.Infof("calls %s", name)
+	log.Infof("arguments: %s", toolCall.Function.Arguments)
+
+	if serverName, toolName, ok := splitMCPToolFunctionName(name); ok {
+		for _, mcpTool := range config.MCPState.Tools {
+			if mcpTool.Name == toolName && config.MCPServers[mcpTool.ServerIndex].Name == serverName {
+				return callMCPTool(config, mcpTool, toolCall.Function.Arguments, log, callback)
+			}
+		}
+	}
+
+	for i, apisParam := range config.APIsParam {
+		for _, toolsParam := range apisParam.ToolsParam {
+			if name != toolsParam.ToolName {
+				continue
+			}
+			arguments := toolCall.Function.Arguments
+			if strings.TrimSpace(arguments) == "" {
+				arguments = "{}"
+			}
+			method, urlStr, headers, reqBody, err := buildToolRequest(apisParam, toolsParam, arguments, log)
+			if err != nil {
+				return err
+			}
+			return config.APIClient[i].Call(method, urlStr, headers, reqBody,
+				func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+					if statusCode != http.StatusOK {
+						log.Debugf("statusCode: %d", statusCode)
+					}
+					callback(string(responseBody))
+				}, uint32(apisParam.MaxExecutionTime))
+		}
+	}
+	return fmt.Errorf("tool %s not found", name)
+}
+
+func toolCallsResult(ctx wrapper.HttpContext, config PluginConfig, toolCalls []dashscope.ToolCall, results []string, rawResponse Response, log log.Log) {
+	log.Info("========function result========")
+	messages, _ := ctx.GetContext(MessagesContextKey).([]dashscope.Message)
+	for i, toolCall := range toolCalls {
+		log.Infof("%s: %s", toolCall.Function.Name, results[i])
+		messages = append(messages, dashscope.Message{
+			Role:       dashscope.RoleTool,
+			Content:    results[i],
+			ToolCallID: toolCall.ID,
+		})
+		result := results[i]
+		if len([]rune(result)) > maxStepResultLength {
+			result = string([]rune(result)[:maxStepResultLength]) + "..."
+		}
+		emitStep(ctx, config, "Observation: "+result, rawResponse, log)
+	}
+	ctx.SetContext(MessagesContextKey, messages)
+
+	completion := dashscope.Completion{
+		Model:     config.LLMInfo.Model,
+		Messages:  messages,
+		MaxTokens: config.LLMInfo.MaxTokens,
+		Tools:     buildFunctionTools(config),
+	}
+	completionSerialized, _ := json.Marshal(completion)
+	err := config.LLMClient.Post(
+		config.LLMInfo.Path,
+		llmHeaders(config.LLMInfo),
+		completionSerialized,
+		func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+			var message dashscope.Message
+			if err := json.Unmarshal([]byte(gjson.GetBytes(responseBody, "choices.0.message").Raw), &message); err != nil {
+				log.Debugf("[toolCallsResult] message to json err: %s, body: %s", err.Error(), string(responseBody))
+				proxywasm.ResumeHttpResponse()
+				return
+			}
+			log.Infof("[toolCallsResult] content: %s", message.Content)
+			if len(message.ToolCalls) > 0 && runFunctionCalling(ctx, config, message, rawResponse, log) {
+				return
+			}
+			finalAnswer(ctx, config, message.Content, rawResponse, log)
+		}, uint32(config.LLMInfo.MaxExecutionTime))
+	if err != nil {
+		log.Debugf("[toolCallsResult] completion err: %s", err.Error())
+		proxywasm.ResumeHttpResponse()
+	}
+}
+
+func finalAnswer(ctx wrapper.HttpContext, config PluginConfig, content string, rawResponse Response, log log.Log) {
+	var assistantMessage Message
+	streamMode := ctx.GetContext(StreamContextKey) != nil
+	if config.JsonResp.Enable {
+		jsonFormat(config.LLMClient, config.LLMInfo, config.JsonResp.JsonSchema, assistantMessage, content, llmHeaders(config.LLMInfo), streamMode, rawResponse, log)
+		return
+	}
+	if !streamMode {
+		noneStream(assistantMessage, content, rawResponse, log)
+		return
+	}
+	replaceAnswerStreamBody(ctx, content, rawResponse)
+	log.Debug("[onHttpResponseBody] replace response success")
+	proxywasm.ResumeHttpResponse()
+}
+
+// 流式请求开启 streamIntermediateSteps 时，在响应头阶段就改为 SSE 响应并发送给客户端，
+// 之后每个中间步骤完成时立即输出，不必等到整个循环结束
+func startStepsStreaming(ctx wrapper.HttpContext, config PluginConfig, log log.Log) {
+	if !config.StreamIntermediateSteps || ctx.GetContext(StreamContextKey) == nil {
+		return
+	}
+	if status, _ := proxywasm.GetHttpResponseHeader(":status"); status != "200" {
+		return
+	}
+	proxywasm.RemoveHttpResponseHeader("content-length")
+	if err := proxywasm.ReplaceHttpResponseHeader("content-type", "text/event-stream; charset=utf-8"); err != nil {
+		log.Warnf("[onHttpResponseHeaders] replace content-type failed: %s", err.Error())
+		return
+	}
+	ctx.SetContext(StepsStreamingContextKey, struct{}{})
+}
+
+// 将中间步骤以 reasoning_content 的形式立即输出给客户端
+func emitStep(ctx wrapper.HttpContext, config PluginConfig, step string, rawResponse Response, log log.Log) {
+	if ctx.GetContext(StepsStreamingContextKey) == nil {
+		return
+	}
+	if err := proxywasm.InjectEncodedDataToFilterChain([]byte(buildStepChunk(step, rawResponse)), false); err != nil {
+		log.Warnf("[emitStep] inject step failed: %s", err.Error())
+	}
+}
+
+// 中间步骤已经输出，最终的响应体只包含答案
+func replaceAnswerStreamBody(ctx wrapper.HttpContext, content string, rawResponse Response) {
+	if ctx.GetContext(StepsStreamingContextKey) == nil {
+		proxywasm.ReplaceHttpResponseHeaders([][2]string{{"content-type", "text/event-stream; charset=utf-8"}})
+	}
+	proxywasm.ReplaceHttpResponseBody([]byte(buildAnswerStreamBody(content, rawResponse)))
+}
+
+func writeStreamChunk(builder *strings.Builder, chunk streamChunk) {
+	data, _ := json.Marshal(chunk)
+	builder.WriteString("data:")
+	builder.Write(data)
+	builder.WriteString("\n\n")
+}
+
+func buildStepChunk(step string, rawResponse Response) string {
+	var builder strings.Builder
+	writeStreamChunk(&builder, streamChunk{
+		ID:      rawResponse.ID,
+		Choices: []streamChoice{{Delta: streamDelta{Role: dashscope.RoleAssistant, ReasoningContent: step + "\n"}}},
+		Model:   rawResponse.Model,
+		Object:  "chat.completion.chunk",
+	})
+	return builder.String()
+}
+
+func buildAnswerStreamBody(content string, rawResponse Response) string {
+	var builder strings.Builder
+	finishReason := "stop"
+	usage := rawResponse.Usage
+	writeStreamChunk(&builder, streamChunk{
+		ID:      rawResponse.ID,
+		Choices: []streamChoice{{Delta: streamDelta{Role: dashscope.RoleAssistant, Content: content}, FinishReason: &finishReason}},
+		Model:   rawResponse.Model,
+		Object:  "chat.completion.chunk",
+		Usage:   &usage,
+	})
+	builder.WriteString("data:[DONE]\n\n")
+	return builder.String()
+}
@@ -47,7 +47,17 @@ func init() {
 func parseConfig(gjson gjson.Result, c *PluginConfig, log log.Log) error {
 	initResponsePromptTpl(gjson, c)
 
-	err := initAPIs(gjson, c)
+	err := initMode(gjson, c)
+	if err != nil {
+		return err
+	}
+
+	err = initMCPServers(gjson, c)
+	if err != nil {
+		return err
+	}
+
+	err = initAPIs(gjson, c)
 	if err != nil {
 		return err
 	}
@@ -109,6 +119,10 @@ func onHttpRequestBody(ctx wrapper.HttpContext, config PluginConfig, body []byte
 	}
 	log.Debugf("onHttpRequestBody rawRequest: %v", rawRequest)
 
+	if config.Mode == ModeFunctionCalling {
+		return onFunctionCallingRequestBody(ctx, config, rawRequest, log)
+	}
+
 	// 获取用户query
 	var query string
 	var history string
@@ -184,6 +198,9 @@ func onHttpResponseHeaders(ctx wrapper.HttpContext, config PluginConfig, log log
 	log.Debug("onHttpResponseHeaders start")
 	defer log.Debug("onHttpResponseHeaders end")
 
+	if config.Mode == ModeFunctionCalling {
+		startStepsStreaming(ctx, config, log)
+	}
 	return types.ActionContinue
 }
 
@@ -265,13 +282,18 @@ func noneStream(assistantMessage Message, actionInput string, rawResponse Respon
 	}
 }
 
+const returnStreamResponseTemplate = `data:{"id":"%s","choices":[{"index":0,"delta":{"role":"assistant","content":"%s"},"finish_reason":"stop"}],"model":"%s","object":"chat.completion","usage":{"prompt_tokens":%d,"completion_tokens":%d,"total_tokens":%d}}` + "\n\ndata:[DONE]\n\n"
+
+func streamBody(actionInput string, rawResponse Response) string {
+	// Remove quotes from actionInput
+	actionInput = strings.Trim(actionInput, "\"")
+	return fmt.Sprintf(returnStreamResponseTemplate, rawResponse.ID, actionInput, rawResponse.Model, rawResponse.Usage.PromptTokens, rawResponse.Usage.CompletionTokens, rawResponse.Usage.TotalTokens)
+}
+
 func stream(actionInput string, rawResponse Response, log log.Log) {
 	headers := [][2]string{{"content-type", "text/event-stream; charset=utf-8"}}
 	proxywasm.ReplaceHttpResponseHeaders(headers)
-	// Remove quotes from actionInput
-	actionInput = strings.Trim(actionInput, "\"")
-	returnStreamResponseTemplate := `data:{"id":"%s","choices":[{"index":0,"delta":{"role":"assistant","content":"%s"},"finish_reason":"stop"}],"model":"%s","object":"chat.completion","usage":{"prompt_tokens":%d,"completion_tokens":%d,"total_tokens":%d}}` + "\n\ndata:[DONE]\n\n"
-	newbody := fmt.Sprintf(returnStreamResponseTemplate, rawResponse.ID, actionInput, rawResponse.Model, rawResponse.Usage.PromptTokens, rawResponse.Usage.CompletionTokens, rawResponse.Usage.TotalTokens)
+	newbody := streamBody(actionInput, rawResponse)
 	log.Infof("[onHttpResponseBody] newResponseBody: ", newbody)
 	proxywasm.ReplaceHttpResponseBody([]byte(newbody))
 
@@ -423,78 +445,13 @@ func toolsCall(ctx wrapper.HttpContext, llmClient wrapper.HttpClient, llmInfo LL
 				log.Infof("calls %s", tools_param.ToolName)
 				log.Infof("actionInput: %s", actionInput)
 
-				// 将大模型需要的参数反序列化
-				var data map[string]interface{}
-				if err := json.Unmarshal([]byte(actionInput), &data); err != nil {
+				var err error
+				method, urlStr, headers, reqBody, err = buildToolRequest(apisParam, tools_param, actionInput, log)
+				if err != nil {
 					log.Debugf("Error: %s", err.Error())
 					return types.ActionContinue, ""
 				}
 
-				method = tools_param.Method
-
-				// 组装 URL 和请求体
-				urlStr = apisParam.URL + tools_param.Path
-
-				// 解析URL模板以查找路径参数
-				urlParts := strings.Split(urlStr, "/")
-				for i, part := range urlParts {
-					if strings.Contains(part, "{") && strings.Contains(part, "}") {
-						for _, param := range tools_param.ParamName {
-							paramNameInPath := part[1 : len(part)-1]
-							if paramNameInPath == param {
-								if value, ok := data[param]; ok {
-									// 删除已经使用过的
-									delete(data, param)
-									// 替换模板中的占位符
-									urlParts[i] = url.QueryEscape(value.(string))
-								}
-							}
-						}
-					}
-				}
-
-				// 重新组合URL
-				urlStr = strings.Join(urlParts, "/")
-
-				queryParams := make([][2]string, 0)
-				if method == "GET" {
-					for _, param := range tools_param.ParamName {
-						if value, ok := data[param]; ok {
-							queryParams = append(queryParams, [2]string{param, fmt.Sprintf("%v", value)})
-						}
-					}
-				} else if method == "POST" {
-					var err error
-					reqBody, err = json.Marshal(data)
-					if err != nil {
-						log.Debugf("Error marshaling JSON: %s", err.Error())
-						return types.ActionContinue, ""
-					}
-				}
-
-				// 组装 headers 和 key
-				headers = [][2]string{{"Content-Type", "application/json"}}
-				if apisParam.APIKey.Name != "" {
-					if apisParam.APIKey.In == "query" {
-						queryParams = append(queryParams, [2]string{apisParam.APIKey.Name, apisParam.APIKey.Value})
-					} else if apisParam.APIKey.In == "header" {
-						headers = append(headers, [2]string{"Authorization", apisParam.APIKey.Name + " " + apisParam.APIKey.Value})
-					}
-				}
-
-				if len(queryParams) > 0 {
-					// 将 key 拼接到 url 后面
-					urlStr += "?"
-					for i, param := range queryParams {
-						if i != 0 {
-							urlStr += "&"
-						}
-						urlStr += url.QueryEscape(param[0]) + "=" + url.QueryEscape(param[1])
-					}
-				}
-
-				log.Debugf("url: %s", urlStr)
-
 				apiClient = aPIClient[i]
 				break
 			}
@@ -521,6 +478,82 @@ func toolsCall(ctx wrapper.HttpContext, llmClient wrapper.HttpClient, llmInfo LL
 	return types.ActionPause, ""
 }
 
+// 根据 OpenAPI 描述组装工具调用的请求
+func buildToolRequest(apisParam APIsParam, tools_param ToolsParam, actionInput string, log log.Log) (string, string, [][2]string, []byte, error) {
+	// 将大模型需要的参数反序列化
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(actionInput), &data); err != nil {
+		return "", "", nil, nil, err
+	}
+
+	method := tools_param.Method
+	var reqBody []byte
+
+	// 组装 URL 和请求体
+	urlStr := apisParam.URL + tools_param.Path
+
+	// 解析URL模板以查找路径参数
+	urlParts := strings.Split(urlStr, "/")
+	for i, part := range urlParts {
+		if strings.Contains(part, "{") && strings.Contains(part, "}") {
+			for _, param := range tools_param.ParamName {
+				paramNameInPath := part[1 : len(part)-1]
+				if paramNameInPath == param {
+					if value, ok := data[param]; ok {
+						// 删除已经使用过的
+						delete(data, param)
+						// 替换模板中的占位符
+						urlParts[i] = url.QueryEscape(fmt.Sprintf("%v", value))
+					}
+				}
+			}
+		}
+	}
+
+	// 重新组合URL
+	urlStr = strings.Join(urlParts, "/")
+
+	queryParams := make([][2]string, 0)
+	if method == "GET" {
+		for _, param := range tools_param.ParamName {
+			if value, ok := data[param]; ok {
+				queryParams = append(queryParams, [2]string{param, fmt.Sprintf("%v", value)})
+			}
+		}
+	} else if method == "POST" {
+		var err error
+		reqBody, err = json.Marshal(data)
+		if err != nil {
+			log.Debugf("Error marshaling JSON: %s", err.Error())
+			return "", "", nil, nil, err
+		}
+	}
+
+	// 组装 headers 和 key
+	headers := [][2]string{{"Content-Type", "application/json"}}
+	if apisParam.APIKey.Name != "" {
+		if apisParam.APIKey.In == "query" {
+			queryParams = append(queryParams, [2]string{apisParam.APIKey.Name, apisParam.APIKey.Value})
+		} else if apisParam.APIKey.In == "header" {
+			headers = append(headers, [2]string{"Authorization", apisParam.APIKey.Name + " " + apisParam.APIKey.Value})
+		}
+	}
+
+	if len(queryParams) > 0 {
+		// 将 key 拼接到 url 后面
+		urlStr += "?"
+		for i, param := range queryParams {
+			if i != 0 {
+				urlStr += "&"
+			}
+			urlStr += url.QueryEscape(param[0]) + "=" + url.QueryEscape(param[1])
+		}
+	}
+
+	log.Debugf("url: %s", urlStr)
+	return method, urlStr, headers, reqBody, nil
+}
+
 // 从response接收到firstreq的大模型返回
 func onHttpResponseBody(ctx wrapper.HttpContext, config PluginConfig, body []byte, log log.Log) types.Action {
 	log.Debugf("onHttpResponseBody start")
@@ -533,6 +566,14 @@ func onHttpResponseBody(ctx wrapper.HttpContext, config PluginConfig, body []byt
 		log.Debugf("[onHttpResponseBody] body to json err: %s", err.Error())
 		return types.ActionContinue
 	}
+	if len(rawResponse.Choices) == 0 {
+		return types.ActionContinue
+	}
+
+	if config.Mode == ModeFunctionCalling {
+		return onFunctionCallingResponseBody(ctx, config, body, rawResponse, log)
+	}
+
 	log.Infof("first content: %s", rawResponse.Choices[0].Message.Content)
 	// 如果gpt返回的内容不是空的
 	if rawResponse.Choices[0].Message.Content != "" {
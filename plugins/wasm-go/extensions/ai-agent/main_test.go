@@ -2,11 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
 	"github.com/higress-group/wasm-go/pkg/test"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 // 测试配置：完整配置
@@ -319,6 +322,101 @@ paths:
 	return data
 }()
 
+// 测试配置：function calling 模式
+var functionCallingConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"mode": "function_calling",
+		"llm": map[string]interface{}{
+			"apiKey":      "test-api-key",
+			"serviceName": "llm-service",
+			"servicePort": 8080,
+			"domain":      "llm.example.com",
+			"path":        "/v1/chat/completions",
+			"model":       "qwen-turbo",
+		},
+		"apis": []map[string]interface{}{
+			{
+				"apiProvider": map[string]interface{}{
+					"serviceName": "api-service",
+					"servicePort": 9090,
+					"domain":      "api.example.com",
+				},
+				"api": `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /weather:
+    get:
+      operationId: getWeather
+      description: Get weather information
+      parameters:
+        - name: city
+          in: query
+          description: city name
+          required: true
+          schema:
+            type: string`,
+			},
+		},
+	})
+	return data
+}()
+
+// 测试配置：只使用 MCP Server 作为工具来源
+var mcpServerConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"mode": "function_calling",
+		"llm": map[string]interface{}{
+			"apiKey":      "test-api-key",
+			"serviceName": "llm-service",
+			"servicePort": 8080,
+			"domain":      "llm.example.com",
+			"path":        "/v1/chat/completions",
+			"model":       "qwen-turbo",
+		},
+		"mcpServers": []map[string]interface{}{
+			{
+				"name":        "amap",
+				"serviceName": "higress-gateway.dns",
+				"servicePort": 80,
+				"domain":      "mcp.example.com",
+				"headers": map[string]interface{}{
+					"Authorization": "Bearer mcp-token",
+				},
+				"allowTools": []string{"maps_weather"},
+			},
+		},
+		"streamIntermediateSteps": false,
+	})
+	return data
+}()
+
+// 测试配置：ReAct 模式下配置 MCP Server
+var mcpServerWithReActConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"llm": map[string]interface{}{
+			"apiKey":      "test-api-key",
+			"serviceName": "llm-service",
+			"servicePort": 8080,
+			"domain":      "llm.example.com",
+			"path":        "/v1/chat/completions",
+			"model":       "qwen-turbo",
+		},
+		"mcpServers": []map[string]interface{}{
+			{
+				"name":        "amap",
+				"serviceName": "higress-gateway.dns",
+				"servicePort": 80,
+				"domain":      "mcp.example.com",
+			},
+		},
+	})
+	return data
+}()
+
 func TestParseConfig(t *testing.T) {
 	test.RunGoTest(t, func(t *testing.T) {
 
@@ -401,6 +499,57 @@ func TestParseConfig(t *testing.T) {
 			// 缺少API提供者信息应该导致配置解析失败
 			require.Equal(t, types.OnPluginStartStatusFailed, status)
 		})
+
+		// 测试 function calling 模式配置
+		t.Run("function calling config", func(t *testing.T) {
+			host, status := test.NewTestHost(functionCallingConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			configRaw, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			config, ok := configRaw.(*PluginConfig)
+			require.True(t, ok, "config should be of type *PluginConfig")
+
+			require.Equal(t, ModeFunctionCalling, config.Mode)
+			require.True(t, config.StreamIntermediateSteps)
+
+			// 验证由 OpenAPI 生成的工具参数 JSON Schema
+			tools := buildFunctionTools(*config)
+			require.Len(t, tools, 1)
+			require.Equal(t, "getWeather", tools[0].Function.Name)
+			require.Equal(t, "Get weather information", tools[0].Function.Description)
+			require.Equal(t, []string{"city"}, tools[0].Function.Parameters["required"])
+		})
+
+		// 测试 MCP Server 配置
+		t.Run("mcp server config", func(t *testing.T) {
+			host, status := test.NewTestHost(mcpServerConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			configRaw, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			config, ok := configRaw.(*PluginConfig)
+			require.True(t, ok, "config should be of type *PluginConfig")
+
+			require.Len(t, config.MCPServers, 1)
+			require.Equal(t, "amap", config.MCPServers[0].Name)
+			require.Equal(t, "/mcp", config.MCPServers[0].Path)
+			require.Equal(t, int64(50000), config.MCPServers[0].MaxExecutionTime)
+			require.Equal(t, "Bearer mcp-token", config.MCPServers[0].Headers["Authorization"])
+			require.Equal(t, []string{"maps_weather"}, config.MCPServers[0].AllowTools)
+			require.False(t, config.StreamIntermediateSteps)
+			require.False(t, config.MCPState.Loaded)
+		})
+
+		// 测试 ReAct 模式下配置 MCP Server
+		t.Run("mcp server with react mode", func(t *testing.T) {
+			host, status := test.NewTestHost(mcpServerWithReActConfig)
+			defer host.Reset()
+			// MCP Server 只支持 function calling 模式
+			require.Equal(t, types.OnPluginStartStatusFailed, status)
+		})
 	})
 }
 
@@ -1833,3 +1982,276 @@ paths:
 		})
 	})
 }
+
+func TestFunctionCalling(t *testing.T) {
+	test.RunTest(t, func(t *testing.T) {
+		t.Run("request with tools", func(t *testing.T) {
+			host, status := test.NewTestHost(functionCallingConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/chat"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+			})
+
+			requestBody := `{
+				"model": "qwen-turbo",
+				"messages": [
+					{"role": "system", "content": "你是一个助手"},
+					{"role": "user", "content": "今天北京天气怎么样？"}
+				],
+				"stream": true
+			}`
+			action := host.CallOnHttpRequestBody([]byte(requestBody))
+			require.Equal(t, types.ActionContinue, action)
+
+			// 原始对话保持不变，不再拼接 ReAct 提示词，并带上工具定义
+			var modifiedRequest Request
+			err := json.Unmarshal(host.GetRequestBody(), &modifiedRequest)
+			require.NoError(t, err)
+			require.False(t, modifiedRequest.Stream)
+			require.Len(t, modifiedRequest.Messages, 2)
+			require.Equal(t, "今天北京天气怎么样？", modifiedRequest.Messages[1].Content)
+			require.Len(t, modifiedRequest.Tools, 1)
+			require.Equal(t, "function", modifiedRequest.Tools[0].Type)
+			require.Equal(t, "getWeather", modifiedRequest.Tools[0].Function.Name)
+		})
+
+		t.Run("response without tool calls in stream mode", func(t *testing.T) {
+			host, status := test.NewTestHost(functionCallingConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/chat"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+			})
+			host.CallOnHttpRequestBody([]byte(`{"model":"qwen-turbo","messages":[{"role":"user","content":"你好"}],"stream":true}`))
+
+			llmResponse := `{
+				"id": "chatcmpl-123",
+				"choices": [
+					{
+						"index": 0,
+						"message": {"role": "assistant", "content": "你好，有什么可以帮你？"},
+						"finish_reason": "stop"
+					}
+				],
+				"model": "qwen-turbo",
+				"object": "chat.completion",
+				"usage": {"prompt_tokens": 10, "completion_tokens": 20, "total_tokens": 30}
+			}`
+			action := host.CallOnHttpResponseBody([]byte(llmResponse))
+			require.Equal(t, types.ActionContinue, action)
+
+			responseBody := string(host.GetResponseBody())
+			require.Contains(t, responseBody, "你好，有什么可以帮你？")
+			require.Contains(t, responseBody, "data:[DONE]")
+		})
+
+		t.Run("parallel tool calls", func(t *testing.T) {
+			host, status := test.NewTestHost(functionCallingConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/chat"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+			})
+			host.CallOnHttpRequestBody([]byte(`{"model":"qwen-turbo","messages":[{"role":"user","content":"北京和上海的天气怎么样？"}],"stream":false}`))
+
+			// 模拟LLM在一轮中要求调用两次工具
+			llmResponse := `{
+				"id": "chatcmpl-123",
+				"choices": [
+					{
+						"index": 0,
+						"message": {
+							"role": "assistant",
+							"content": "",
+							"tool_calls": [
+								{"index": 0, "id": "call_1", "type": "function", "function": {"name": "getWeather", "arguments": "{\"city\": \"北京\"}"}},
+								{"index": 1, "id": "call_2", "type": "function", "function": {"name": "getWeather", "arguments": "{\"city\": \"上海\"}"}}
+							]
+						},
+						"finish_reason": "tool_calls"
+					}
+				],
+				"model": "qwen-turbo",
+				"object": "chat.completion",
+				"usage": {"prompt_tokens": 10, "completion_tokens": 20, "total_tokens": 30}
+			}`
+			action := host.CallOnHttpResponseBody([]byte(llmResponse))
+			// 需要等待工具调用结果
+			require.Equal(t, types.ActionPause, action)
+
+			// 模拟两个工具调用的响应
+			host.CallOnHttpCall([][2]string{
+				{"Content-Type", "application/json"},
+				{":status", "200"},
+			}, []byte(`{"temperature": 25, "condition": "晴朗"}`))
+			host.CallOnHttpCall([][2]string{
+				{"Content-Type", "application/json"},
+				{":status", "200"},
+			}, []byte(`{"temperature": 28, "condition": "多云"}`))
+
+			// 模拟LLM根据工具结果给出最终答案
+			llmFinalResponse := `{
+				"id": "chatcmpl-124",
+				"choices": [
+					{
+						"index": 0,
+						"message": {"role": "assistant", "content": "北京晴朗25度，上海多云28度"},
+						"finish_reason": "stop"
+					}
+				],
+				"model": "qwen-turbo",
+				"object": "chat.completion",
+				"usage": {"prompt_tokens": 15, "completion_tokens": 25, "total_tokens": 40}
+			}`
+			host.CallOnHttpCall([][2]string{
+				{"Content-Type", "application/json"},
+				{":status", "200"},
+			}, []byte(llmFinalResponse))
+
+			host.CompleteHttp()
+		})
+	})
+}
+
+func TestMCPToolsLoading(t *testing.T) {
+	test.RunTest(t, func(t *testing.T) {
+		sendRequest := func(host test.TestHost) types.Action {
+			host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/chat"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+			})
+			return host.CallOnHttpRequestBody([]byte(`{"model":"qwen-turbo","messages":[{"role":"user","content":"北京天气怎么样？"}],"stream":false}`))
+		}
+
+		t.Run("retry after failed load", func(t *testing.T) {
+			host, status := test.NewTestHost(mcpServerConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			configRaw, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			config := configRaw.(*PluginConfig)
+
+			// 首次请求需要等待 initialize 完成
+			require.Equal(t, types.ActionPause, sendRequest(host))
+			require.True(t, config.MCPState.Loading)
+			host.CallOnHttpCall([][2]string{{":status", "500"}}, []byte(`internal error`))
+			require.False(t, config.MCPState.Loading)
+			require.False(t, config.MCPState.Loaded)
+			require.False(t, config.MCPState.FailedAt.IsZero())
+			require.Empty(t, config.MCPState.Tools)
+
+			// 重试间隔内不会重新加载
+			require.Equal(t, types.ActionContinue, sendRequest(host))
+			require.False(t, config.MCPState.Loading)
+
+			// 超过重试间隔后重新加载
+			config.MCPState.FailedAt = time.Now().Add(-MCPRetryInterval)
+			require.Equal(t, types.ActionPause, sendRequest(host))
+			host.CallOnHttpCall([][2]string{{":status", "200"}, {"Content-Type", "application/json"}, {MCPSessionIdHeader, "session-1"}},
+				[]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26"}}`))
+			host.CallOnHttpCall([][2]string{{":status", "202"}}, nil)
+			host.CallOnHttpCall([][2]string{{":status", "200"}, {"Content-Type", "application/json"}},
+				[]byte(`{"jsonrpc":"2.0","id":2,"result":{"tools":[{"name":"maps_weather","description":"查询天气"},{"name":"maps_geo","description":"地理编码"}]}}`))
+			require.False(t, config.MCPState.Loading)
+			require.True(t, config.MCPState.Loaded)
+			require.True(t, config.MCPState.FailedAt.IsZero())
+			require.Equal(t, "session-1", config.MCPState.Sessions["amap"])
+			require.Len(t, config.MCPState.Tools, 1)
+			require.Equal(t, "maps_weather", config.MCPState.Tools[0].Name)
+		})
+
+		t.Run("concurrent requests do not reload", func(t *testing.T) {
+			host, status := test.NewTestHost(mcpServerConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			require.Equal(t, types.ActionPause, sendRequest(host))
+			// 加载过程中到达的请求直接使用当前的工具列表
+			require.Equal(t, types.ActionContinue, sendRequest(host))
+		})
+	})
+}
+
+func TestMCPHelpers(t *testing.T) {
+	t.Run("split tool function name", func(t *testing.T) {
+		serverName, toolName, ok := splitMCPToolFunctionName(mcpToolFunctionName("amap", "maps_weather"))
+		require.True(t, ok)
+		require.Equal(t, "amap", serverName)
+		require.Equal(t, "maps_weather", toolName)
+
+		_, _, ok = splitMCPToolFunctionName("getWeather")
+		require.False(t, ok)
+	})
+
+	t.Run("parse json response", func(t *testing.T) {
+		body := []byte(`{"jsonrpc":"2.0","id":2,"result":{"tools":[]}}`)
+		response, err := parseMCPResponse("application/json", body)
+		require.NoError(t, err)
+		require.Equal(t, body, response)
+
+		_, err = parseMCPResponse("application/json", []byte("not json"))
+		require.Error(t, err)
+	})
+
+	t.Run("parse sse response", func(t *testing.T) {
+		body := []byte("event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n" +
+			"event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":3,\"result\":{\"content\":[{\"type\":\"text\",\"text\":\"sunny\"}]}}\n\n")
+		response, err := parseMCPResponse("text/event-stream", body)
+		require.NoError(t, err)
+		require.Equal(t, "sunny", mcpToolResultToString(response))
+
+		_, err = parseMCPResponse("text/event-stream", []byte("event: ping\n\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("tool result to string", func(t *testing.T) {
+		require.Equal(t, "a\nb", mcpToolResultToString([]byte(`{"id":3,"result":{"content":[{"type":"text","text":"a"},{"type":"text","text":"b"}]}}`)))
+		require.Equal(t, "error: failed", mcpToolResultToString([]byte(`{"id":3,"result":{"content":[{"type":"text","text":"failed"}],"isError":true}}`)))
+		require.Equal(t, "error: tool not found", mcpToolResultToString([]byte(`{"id":3,"error":{"code":-32602,"message":"tool not found"}}`)))
+	})
+
+	t.Run("allow tools", func(t *testing.T) {
+		require.True(t, isToolAllowed(MCPServer{}, "any"))
+		require.True(t, isToolAllowed(MCPServer{AllowTools: []string{"a"}}, "a"))
+		require.False(t, isToolAllowed(MCPServer{AllowTools: []string{"a"}}, "b"))
+	})
+}
+
+func TestBuildStepsStreamBody(t *testing.T) {
+	rawResponse := Response{ID: "chatcmpl-123", Model: "qwen-turbo", Usage: Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3}}
+
+	t.Run("step chunk", func(t *testing.T) {
+		chunk := buildStepChunk("Observation: \"sunny\"", rawResponse)
+		require.True(t, strings.HasSuffix(chunk, "\n\n"))
+		data := strings.TrimPrefix(strings.TrimSuffix(chunk, "\n\n"), "data:")
+		require.Equal(t, "Observation: \"sunny\"\n", gjson.Get(data, "choices.0.delta.reasoning_content").String())
+		require.Equal(t, "chatcmpl-123", gjson.Get(data, "id").String())
+		require.False(t, gjson.Get(data, "usage").Exists())
+	})
+
+	t.Run("answer body", func(t *testing.T) {
+		body := buildAnswerStreamBody("今天晴天", rawResponse)
+		chunks := strings.Split(strings.TrimSuffix(body, "\n\n"), "\n\n")
+		require.Len(t, chunks, 2)
+		require.Equal(t, "今天晴天", gjson.Get(strings.TrimPrefix(chunks[0], "data:"), "choices.0.delta.content").String())
+		require.Equal(t, "stop", gjson.Get(strings.TrimPrefix(chunks[0], "data:"), "choices.0.finish_reason").String())
+		require.Equal(t, int64(3), gjson.Get(strings.TrimPrefix(chunks[0], "data:"), "usage.total_tokens").Int())
+		require.Equal(t, "data:[DONE]", chunks[1])
+	})
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-agent/dashscope"
+	"github.com/higress-group/wasm-go/pkg/log"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// 与 mcp-router 保持一致的工具名格式：${serverName}___${toolName}
+	MCPToolNameSeparator = "___"
+	MCPProtocolVersion   = "2025-03-26"
+	MCPSessionIdHeader   = "Mcp-Session-Id"
+	// 有 MCP Server 加载失败时，间隔一段时间后再重新加载
+	MCPRetryInterval = 30 * time.Second
+)
+
+// MCP Server 的会话以及工具列表，在插件配置生命周期内共享
+type MCPState struct {
+	// 所有 MCP Server 的工具都已加载成功
+	Loaded bool
+	// 正在加载，期间到达的请求直接使用当前的工具列表，不会重复加载
+	Loading bool
+	// 最近一次加载有 MCP Server 失败的时间，用于控制重试间隔
+	FailedAt time.Time
+	Sessions map[string]string
+	Tools    []MCPTool
+}
+
+// 一次加载过程中构建的会话和工具列表，所有 MCP Server 处理完成后才整体替换到 MCPState
+type mcpLoading struct {
+	sessions map[string]string
+	tools    []MCPTool
+	failed   bool
+}
+
+type MCPTool struct {
+	ServerIndex int
+	Name        string
+	Tool        dashscope.Tool
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int        `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+func newJSONRPCRequest(id int, method string, params interface{}) []byte {
+	request := jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+	if id > 0 {
+		request.ID = &id
+	}
+	body, _ := json.Marshal(request)
+	return body
+}
+
+func mcpToolFunctionName(serverName, toolName string) string {
+	return serverName + MCPToolNameSeparator + toolName
+}
+
+func splitMCPToolFunctionName(name string) (string, string, bool) {
+	index := strings.Index(name, MCPToolNameSeparator)
+	if index <= 0 {
+		return "", "", false
+	}
+	return name[:index], name[index+len(MCPToolNameSeparator):], true
+}
+
+func isToolAllowed(server MCPServer, toolName string) bool {
+	if len(server.AllowTools) == 0 {
+		return true
+	}
+	for _, allowTool := range server.AllowTools {
+		if allowTool == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+// Streamable HTTP 的响应可能是 JSON，也可能是 SSE，统一取出 JSON-RPC 响应
+func parseMCPResponse(contentType string, body []byte) ([]byte, error) {
+	if !strings.Contains(strings.ToLower(contentType), "text/event-stream") {
+		if !gjson.ValidBytes(body) {
+			return nil, fmt.Errorf("invalid mcp response: %s", string(body))
+		}
+		return body, nil
+	}
+	var result []byte
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if !gjson.Valid(data) {
+			continue
+		}
+		// 跳过服务端在同一个流中发送的通知，只保留带 id 的响应
+		if gjson.Get(data, "id").Exists() {
+			result = []byte(data)
+		}
+	}
+	if result == nil {
+		return nil, errors.New("no json-rpc response found in sse stream")
+	}
+	return result, nil
+}
+
+// 将 tools/call 的结果转换为提供给大模型的文本
+func mcpToolResultToString(response []byte) string {
+	if errMsg := gjson.GetBytes(response, "error.message"); errMsg.Exists() {
+		return "error: " + errMsg.String()
+	}
+	result := gjson.GetBytes(response, "result")
+	texts := make([]string, 0)
+	for _, content := range result.Get("content").Array() {
+		if content.Get("type").String() == "text" {
+			texts = append(texts, content.Get("text").String())
+		} else {
+			texts = append(texts, content.Raw)
+		}
+	}
+	if len(texts) == 0 {
+		if structured := result.Get("structuredContent"); structured.Exists() {
+			texts = append(texts, structured.Raw)
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if result.Get("isError").Bool() {
+		return "error: " + text
+	}
+	return text
+}
+
+func mcpRequestHeaders(server MCPServer, sessionId string) [][2]string {
+	headers := [][2]string{
+		{"Content-Type", "application/json"},
+		{"Accept", "application/json, text/event-stream"},
+		{"MCP-Protocol-Version", MCPProtocolVersion},
+	}
+	if sessionId != "" {
+		headers = append(headers, [2]string{MCPSessionIdHeader, sessionId})
+	}
+	for key, value := range server.Headers {
+		headers = append(headers, [2]string{key, value})
+	}
+	return headers
+}
+
+// 按需加载所有 MCP Server 的工具列表。返回 true 表示已经发起了异步请求，加载完成后会调用 onLoaded；
+// 返回 false 表示无需等待，可以直接使用当前的工具列表，onLoaded 不会被调用
+func loadMCPTools(config PluginConfig, log log.Log, onLoaded func()) bool {
+	state := config.MCPState
+	if len(config.MCPServers) == 0 || state.Loaded || state.Loading {
+		return false
+	}
+	if !state.FailedAt.IsZero() && time.Since(state.FailedAt) < MCPRetryInterval {
+		return false
+	}
+
+	state.Loading = true
+	loading := &mcpLoading{sessions: make(map[string]string)}
+	returned := false
+	fetchMCPTools(config, loading, 0, log, func() {
+		state.Sessions = loading.sessions
+		state.Tools = loading.tools
+		state.Loading = false
+		state.Loaded = !loading.failed
+		if loading.failed {
+			state.FailedAt = time.Now()
+		} else {
+			state.FailedAt = time.Time{}
+		}
+		// 同步完成时由调用方直接继续处理
+		if returned {
+			onLoaded()
+		}
+	})
+	returned = true
+	return state.Loading
+}
+
+// 依次对每个 MCP Server 完成 initialize 和 tools/list，所有 MCP Server 处理完成后调用 done
+func fetchMCPTools(config PluginConfig, loading *mcpLoading, index int, log log.Log, done func()) {
+	if index >= len(config.MCPServers) {
+		done()
+		return
+	}
+
+	server := config.MCPServers[index]
+	client := config.MCPClient[index]
+	next := func() {
+		fetchMCPTools(config, loading, index+1, log, done)
+	}
+	// 加载失败时丢弃本次拿到的部分工具，继续使用该 MCP Server 上一次加载成功的会话和工具
+	toolsBefore := len(loading.tools)
+	fail := func() {
+		loading.failed = true
+		loading.tools = loading.tools[:toolsBefore]
+		delete(loading.sessions, server.Name)
+		state := config.MCPState
+		if sessionId, ok := state.Sessions[server.Name]; ok {
+			loading.sessions[server.Name] = sessionId
+		}
+		for _, tool := range state.Tools {
+			if tool.ServerIndex == index {
+				loading.tools = append(loading.tools, tool)
+			}
+		}
+		next()
+	}
+
+	initializeParams := map[string]interface{}{
+		"protocolVersion": MCPProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "higress-ai-agent",
+			"version": "1.0.0",
+		},
+	}
+	err := client.Post(server.Path, mcpRequestHeaders(server, ""), newJSONRPCRequest(1, "initialize", initializeParams),
+		func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+			if statusCode != http.StatusOK {
+				log.Warnf("[mcp] initialize %s failed, status: %d, body: %s", server.Name, statusCode, string(responseBody))
+				fail()
+				return
+			}
+			sessionId := responseHeaders.Get(MCPSessionIdHeader)
+			loading.sessions[server.Name] = sessionId
+			headers := mcpRequestHeaders(server, sessionId)
+			err := client.Post(server.Path, headers, newJSONRPCRequest(0, "notifications/initialized", nil),
+				func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+					listMCPTools(config, loading, index, headers, "", log, next, fail)
+				}, uint32(server.MaxExecutionTime))
+			if err != nil {
+				log.Warnf("[mcp] notify %s initialized failed: %s", server.Name, err.Error())
+				fail()
+			}
+		}, uint32(server.MaxExecutionTime))
+	if err != nil {
+		log.Warnf("[mcp] initialize %s failed: %s", server.Name, err.Error())
+		fail()
+	}
+}
+
+func listMCPTools(config PluginConfig, loading *mcpLoading, index int, headers [][2]string, cursor string, log log.Log, next func(), fail func()) {
+	server := config.MCPServers[index]
+	var params interface{}
+	if cursor != "" {
+		params = map[string]string{"cursor": cursor}
+	}
+	err := config.MCPClient[index].Post(server.Path, headers, newJSONRPCRequest(2, "tools/list", params),
+		func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+			if statusCode != http.StatusOK {
+				log.Warnf("[mcp] list tools of %s failed, status: %d, body: %s", server.Name, statusCode, string(responseBody))
+				fail()
+				return
+			}
+			response, err := parseMCPResponse(responseHeaders.Get("Content-Type"), responseBody)
+			if err != nil {
+				log.Warnf("[mcp] list tools of %s failed: %s", server.Name, err.Error())
+				fail()
+				return
+			}
+			for _, tool := range gjson.GetBytes(response, "result.tools").Array() {
+				name := tool.Get("name").String()
+				if name == "" || !isToolAllowed(server, name) {
+					continue
+				}
+				var parameters map[string]interface{}
+				if schema, ok := tool.Get("inputSchema").Value().(map[string]interface{}); ok {
+					parameters = schema
+				} else {
+					parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+				}
+				loading.tools = append(loading.tools, MCPTool{
+					ServerIndex: index,
+					Name:        name,
+					Tool: dashscope.Tool{
+						Type: "function",
+						Function: dashscope.ToolFunction{
+							Name:        mcpToolFunctionName(server.Name, name),
+							Description: tool.Get("description").String(),
+							Parameters:  parameters,
+						},
+					},
+				})
+			}
+			if nextCursor := gjson.GetBytes(response, "result.nextCursor").String(); nextCursor != "" {
+				listMCPTools(config, loading, index, headers, nextCursor, log, next, fail)
+				return
+			}
+			log.Infof("[mcp] loaded tools from %s", server.Name)
+			next()
+		}, uint32(server.MaxExecutionTime))
+	if err != nil {
+		log.Warnf("[mcp] list tools of %s failed: %s", server.Name, err.Error())
+		fail()
+	}
+}
+
+// 调用 MCP Server 的工具，结果通过 callback 以文本形式返回
+func callMCPTool(config PluginConfig, tool MCPTool, arguments string, log log.Log, callback func(result string)) error {
+	server := config.MCPServers[tool.ServerIndex]
+	args := make(map[string]interface{})
+	if strings.TrimSpace(arguments) != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return fmt.Errorf("invalid arguments for tool %s: %s", tool.Name, err.Error())
+		}
+	}
+	params := map[string]interface{}{
+		"name":      tool.Name,
+		"arguments": args,
+	}
+	sessionId := config.MCPState.Sessions[server.Name]
+	return config.MCPClient[tool.ServerIndex].Post(server.Path, mcpRequestHeaders(server, sessionId), newJSONRPCRequest(3, "tools/call", params),
+		func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+			if statusCode == http.StatusNotFound && sessionId != "" {
+				// 会话已失效，下一次请求时重新初始化
+				config.MCPState.Loaded = false
+			}
+			if statusCode != http.StatusOK {
+				callback(fmt.Sprintf("error: mcp server %s returned status %d: %s", server.Name, statusCode, string(responseBody)))
+				return
+			}
+			response, err := parseMCPResponse(responseHeaders.Get("Content-Type"), responseBody)
+			if err != nil {
+				callback("error: " + err.Error())
+				return
+			}
+			callback(mcpToolResultToString(response))
+		}, uint32(server.MaxExecutionTime))
+}
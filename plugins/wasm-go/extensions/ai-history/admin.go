@@ -0,0 +1,314 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm"
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
+	"github.com/higress-group/wasm-go/pkg/log"
+	"github.com/higress-group/wasm-go/pkg/wrapper"
+	"github.com/tidwall/resp"
+)
+
+const (
+	DefaultAdminPath = "ai-history/admin"
+	// 未携带会话标识的请求使用的会话名，对应不带会话后缀的 key
+	DefaultSessionName = "default"
+	ConsumerHeader     = "x-mse-consumer"
+	AdminKeyHeader     = "x-ai-history-admin-key"
+)
+
+type AdminMode string
+
+const (
+	AdminModeNone     AdminMode = "none"
+	AdminModeSessions AdminMode = "sessions"
+	AdminModeExport   AdminMode = "export"
+	AdminModeDelete   AdminMode = "delete"
+)
+
+// KEYS[1]: 历史对话 key，KEYS[2]: 会话索引 key
+// ARGV[1]: 历史对话，ARGV[2]: 过期时间，ARGV[3]: 会话标识
+const SaveHistoryScript = `
+redis.call('SET', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+  redis.call('EXPIRE', KEYS[1], ttl)
+end
+if ARGV[3] ~= '' then
+  redis.call('SADD', KEYS[2], ARGV[3])
+  if ttl > 0 then
+    redis.call('EXPIRE', KEYS[2], ttl)
+  end
+end
+return 1
+`
+
+// KEYS[1]: 历史对话 key
+// ARGV[1]: 需要替换的历史对话前缀，ARGV[2]: 替换后的历史对话，ARGV[3]: 过期时间
+// 历史对话已不以 ARGV[1] 开头时不做修改并返回 0，前缀之后追加的对话会被保留
+const ReplaceHistoryPrefixScript = `
+local history = redis.call('GET', KEYS[1])
+if not history then
+  return 0
+end
+local prefix = string.sub(ARGV[1], 1, -2)
+if string.sub(history, 1, #prefix) ~= prefix then
+  return 0
+end
+local rest = string.sub(history, #prefix + 1)
+local next = string.sub(rest, 1, 1)
+if next ~= ',' and next ~= ']' then
+  return 0
+end
+redis.call('SET', KEYS[1], string.sub(ARGV[2], 1, -2) .. rest)
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+  redis.call('EXPIRE', KEYS[1], ttl)
+end
+return 1
+`
+
+// KEYS[1]: 默认会话的历史对话 key，KEYS[2]: 会话索引 key
+// 返回 [会话, 历史对话, 会话, 历史对话...]，已过期的会话会从索引中移除
+const ExportHistoryScript = `
+local result = {}
+local default = redis.call('GET', KEYS[1])
+if default then
+  table.insert(result, '')
+  table.insert(result, default)
+end
+for _, session in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+  local history = redis.call('GET', KEYS[1] .. ':' .. session)
+  if history then
+    table.insert(result, session)
+    table.insert(result, history)
+  else
+    redis.call('SREM', KEYS[2], session)
+  end
+end
+return result
+`
+
+// KEYS[1]: 默认会话的历史对话 key，KEYS[2]: 会话索引 key
+// ARGV[1]: 需要删除的会话，为 * 时删除全部会话，返回删除的 key 数量
+const DeleteHistoryScript = `
+local deleted = 0
+if ARGV[1] == '*' then
+  deleted = deleted + redis.call('DEL', KEYS[1])
+  for _, session in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+    deleted = deleted + redis.call('DEL', KEYS[1] .. ':' .. session)
+  end
+  redis.call('DEL', KEYS[2])
+elseif ARGV[1] == '' then
+  deleted = redis.call('DEL', KEYS[1])
+else
+  deleted = redis.call('DEL', KEYS[1] .. ':' .. ARGV[1])
+  redis.call('SREM', KEYS[2], ARGV[1])
+end
+return deleted
+`
+
+func normalizeIdentity(identity string) string {
+	return strings.ReplaceAll(identity, " ", "")
+}
+
+func historyKey(config PluginConfig, identityKey string, session string) string {
+	if session == "" {
+		return config.CacheKeyPrefix + identityKey
+	}
+	return config.CacheKeyPrefix + identityKey + ":" + session
+}
+
+func sessionIndexKey(config PluginConfig, identityKey string) string {
+	return config.CacheKeyPrefix + "sessions:" + identityKey
+}
+
+// 会话标识为 default 时与未携带会话标识等价
+func normalizeSession(session string) string {
+	if session == DefaultSessionName {
+		return ""
+	}
+	return session
+}
+
+func sessionName(session string) string {
+	if session == "" {
+		return DefaultSessionName
+	}
+	return session
+}
+
+func saveHistory(config PluginConfig, identityKey string, session string, chat []ChatHistory, log log.Log) {
+	str, err := json.Marshal(chat)
+	if err != nil {
+		log.Errorf("marshal chat:%v failed, err:%v", chat, err)
+		return
+	}
+	key := historyKey(config, identityKey, session)
+	log.Infof("start to Set history, key:%s, chat:%s", key, string(str))
+	keys := []interface{}{key, sessionIndexKey(config, identityKey)}
+	args := []interface{}{string(str), config.CacheTTL, session}
+	err = config.redisClient.Eval(SaveHistoryScript, 2, keys, args, func(response resp.Value) {
+		if err := response.Error(); err != nil {
+			log.Errorf("redis save history failed, key:%s, err:%v", key, err)
+		}
+	})
+	if err != nil {
+		log.Errorf("redis save history failed, key:%s, err:%v", key, err)
+	}
+}
+
+// replaceHistoryPrefix 将历史对话开头的 prefix 替换为 replacement，历史对话已被改写为不以 prefix 开头时放弃替换
+func replaceHistoryPrefix(config PluginConfig, identityKey string, session string, prefix []ChatHistory, replacement []ChatHistory, log log.Log) {
+	prefixStr, err := json.Marshal(prefix)
+	if err != nil {
+		log.Errorf("marshal chat:%v failed, err:%v", prefix, err)
+		return
+	}
+	replacementStr, err := json.Marshal(replacement)
+	if err != nil {
+		log.Errorf("marshal chat:%v failed, err:%v", replacement, err)
+		return
+	}
+	key := historyKey(config, identityKey, session)
+	args := []interface{}{string(prefixStr), string(replacementStr), config.CacheTTL}
+	err = config.redisClient.Eval(ReplaceHistoryPrefixScript, 1, []interface{}{key}, args, func(response resp.Value) {
+		if err := response.Error(); err != nil {
+			log.Errorf("redis replace history failed, key:%s, err:%v", key, err)
+			return
+		}
+		if response.Integer() == 0 {
+			log.Infof("history changed before the replacement, key:%s", key)
+		}
+	})
+	if err != nil {
+		log.Errorf("redis replace history failed, key:%s, err:%v", key, err)
+	}
+}
+
+func getAdminMode(config PluginConfig, path string, method string) AdminMode {
+	if config.AdminConsumer == "" {
+		return AdminModeNone
+	}
+	parsedURL, err := url.Parse(path)
+	if err != nil {
+		return AdminModeNone
+	}
+	p := strings.TrimSuffix(parsedURL.Path, "/")
+	switch {
+	case strings.HasSuffix(p, config.AdminPath+"/export") && method == http.MethodGet:
+		return AdminModeExport
+	case strings.HasSuffix(p, config.AdminPath) && method == http.MethodGet:
+		return AdminModeSessions
+	case strings.HasSuffix(p, config.AdminPath) && method == http.MethodDelete:
+		return AdminModeDelete
+	}
+	return AdminModeNone
+}
+
+func sendAdminResponse(statusCode uint32, body any) {
+	data, _ := json.Marshal(body)
+	_ = proxywasm.SendHttpResponseWithDetail(statusCode, "ai-history.admin", [][2]string{{"content-type", "application/json; charset=utf-8"}}, data, -1)
+}
+
+func sendAdminError(statusCode uint32, message string) {
+	sendAdminResponse(statusCode, map[string]string{"error": message})
+}
+
+// handleAdminRequest 处理管理接口请求：查询会话列表、导出以及删除指定用户的历史对话
+func handleAdminRequest(ctx wrapper.HttpContext, config PluginConfig, mode AdminMode, log log.Log) types.Action {
+	ctx.DontReadRequestBody()
+	// x-mse-consumer 可以被客户端伪造，必须同时校验管理密钥
+	adminKey, _ := proxywasm.GetHttpRequestHeader(AdminKeyHeader)
+	if subtle.ConstantTimeCompare([]byte(adminKey), []byte(config.AdminKey)) != 1 {
+		sendAdminError(http.StatusForbidden, "Request denied by ai history admin check. Invalid admin key.")
+		return types.ActionContinue
+	}
+	consumer, _ := proxywasm.GetHttpRequestHeader(ConsumerHeader)
+	if consumer != config.AdminConsumer {
+		sendAdminError(http.StatusForbidden, "Request denied by ai history admin check. Unauthorized admin consumer.")
+		return types.ActionContinue
+	}
+	parsedURL, err := url.Parse(ctx.Path())
+	if err != nil {
+		sendAdminError(http.StatusBadRequest, "invalid request path")
+		return types.ActionContinue
+	}
+	query := parsedURL.Query()
+	identity := normalizeIdentity(query.Get("identity"))
+	if identity == "" {
+		sendAdminError(http.StatusBadRequest, "identity must not be empty")
+		return types.ActionContinue
+	}
+	keys := []interface{}{historyKey(config, identity, ""), sessionIndexKey(config, identity)}
+
+	if mode == AdminModeDelete {
+		session := "*"
+		if query.Has("session") {
+			session = normalizeSession(query.Get("session"))
+		}
+		err = config.redisClient.Eval(DeleteHistoryScript, 2, keys, []interface{}{session}, func(response resp.Value) {
+			if err := response.Error(); err != nil {
+				sendAdminError(http.StatusServiceUnavailable, fmt.Sprintf("redis error:%v", err))
+				return
+			}
+			log.Infof("delete history, identity:%s, session:%s, deleted:%d", identity, session, response.Integer())
+			sendAdminResponse(http.StatusOK, map[string]any{"identity": identity, "deleted": response.Integer()})
+		})
+	} else {
+		session := query.Get("session")
+		err = config.redisClient.Eval(ExportHistoryScript, 2, keys, []interface{}{}, func(response resp.Value) {
+			if err := response.Error(); err != nil {
+				sendAdminError(http.StatusServiceUnavailable, fmt.Sprintf("redis error:%v", err))
+				return
+			}
+			histories, err := parseExportResponse(response.Array())
+			if err != nil {
+				sendAdminError(http.StatusInternalServerError, err.Error())
+				return
+			}
+			if mode == AdminModeSessions {
+				sessions := make([]string, 0, len(histories))
+				for name := range histories {
+					sessions = append(sessions, name)
+				}
+				sort.Strings(sessions)
+				sendAdminResponse(http.StatusOK, map[string]any{"identity": identity, "sessions": sessions})
+				return
+			}
+			if session != "" {
+				history, ok := histories[session]
+				if !ok {
+					sendAdminError(http.StatusNotFound, "session not found")
+					return
+				}
+				histories = map[string][]ChatHistory{session: history}
+			}
+			sendAdminResponse(http.StatusOK, map[string]any{"identity": identity, "histories": histories})
+		})
+	}
+	if err != nil {
+		sendAdminError(http.StatusServiceUnavailable, fmt.Sprintf("redis error:%v", err))
+		return types.ActionContinue
+	}
+	return types.HeaderStopAllIterationAndWatermark
+}
+
+func parseExportResponse(values []resp.Value) (map[string][]ChatHistory, error) {
+	histories := make(map[string][]ChatHistory, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		var chat []ChatHistory
+		if err := json.Unmarshal([]byte(values[i+1].String()), &chat); err != nil {
+			return nil, fmt.Errorf("unmarshal history of session %s failed: %v", sessionName(values[i].String()), err)
+		}
+		histories[sessionName(values[i].String())] = chat
+	}
+	return histories, nil
+}
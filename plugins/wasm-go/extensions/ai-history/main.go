@@ -27,6 +27,7 @@ const (
 	StreamContextKey         = "stream"
 	DefaultCacheKeyPrefix    = "higress-ai-history:"
 	IdentityKey              = "identity"
+	SessionKey               = "session"
 	ChatHistories            = "chatHistories"
 )
 
@@ -115,13 +116,31 @@ type PluginConfig struct {
 	FillHistoryCnt int `required:"false" yaml:"fillHistoryCnt" json:"fillHistoryCnt"`
 	// @Title zh-CN 缓存的过期时间
 	// @Description zh-CN 单位是秒，默认值为0，即永不过期
-	CacheTTL    int                 `required:"false" yaml:"cacheTTL" json:"cacheTTL"`
+	CacheTTL int `required:"false" yaml:"cacheTTL" json:"cacheTTL"`
+	// @Title zh-CN 会话解析方式
+	// @Description zh-CN 会话标识对应的请求头，配置后同一身份下不同会话的历史对话分别存储，默认不区分会话
+	SessionHeader string `required:"false" yaml:"sessionHeader" json:"sessionHeader"`
+	// @Title zh-CN 记忆策略
+	// @Description zh-CN 历史对话的保留和填充策略
+	Memory MemoryConfig `required:"false" yaml:"memory" json:"memory"`
+	// @Title zh-CN 管理接口的 consumer
+	// @Description zh-CN 允许调用管理接口的 consumer 名称，为空时不开启管理接口
+	AdminConsumer string `required:"false" yaml:"adminConsumer" json:"adminConsumer"`
+	// @Title zh-CN 管理接口的密钥
+	// @Description zh-CN 调用管理接口时需要通过 x-ai-history-admin-key 请求头携带的密钥，配置 adminConsumer 时必填
+	AdminKey string `required:"false" yaml:"adminKey" json:"adminKey"`
+	// @Title zh-CN 管理接口的路径后缀
+	// @Description zh-CN 默认值是"ai-history/admin"
+	AdminPath   string              `required:"false" yaml:"adminPath" json:"adminPath"`
+	memory      Memory              `yaml:"-" json:"-"`
 	redisClient wrapper.RedisClient `yaml:"-" json:"-"`
 }
 
 type ChatHistory struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// 标识该条记录是由更早的对话压缩而来的摘要
+	Summary bool `json:"summary,omitempty"`
 }
 
 func parseConfig(json gjson.Result, c *PluginConfig, log log.Log) error {
@@ -162,6 +181,19 @@ func parseConfig(json gjson.Result, c *PluginConfig, log log.Log) error {
 		c.FillHistoryCnt = 3
 	}
 	c.CacheTTL = int(json.Get("cacheTTL").Int())
+	c.SessionHeader = json.Get("sessionHeader").String()
+	c.AdminConsumer = json.Get("adminConsumer").String()
+	c.AdminKey = json.Get("adminKey").String()
+	if c.AdminConsumer != "" && c.AdminKey == "" {
+		return errors.New("adminKey must not be empty when adminConsumer is configured")
+	}
+	c.AdminPath = strings.Trim(json.Get("adminPath").String(), "/")
+	if c.AdminPath == "" {
+		c.AdminPath = DefaultAdminPath
+	}
+	if err := parseMemoryConfig(json, c); err != nil {
+		return err
+	}
 	c.redisClient = wrapper.NewRedisClusterClient(wrapper.FQDNCluster{
 		FQDN: c.RedisInfo.ServiceName,
 		Port: int64(c.RedisInfo.ServicePort),
@@ -171,6 +203,9 @@ func parseConfig(json gjson.Result, c *PluginConfig, log log.Log) error {
 
 func onHttpRequestHeaders(ctx wrapper.HttpContext, config PluginConfig, log log.Log) types.Action {
 	ctx.DisableReroute()
+	if adminMode := getAdminMode(config, ctx.Path(), ctx.Method()); adminMode != AdminModeNone {
+		return handleAdminRequest(ctx, config, adminMode, log)
+	}
 	contentType, _ := proxywasm.GetHttpRequestHeader("content-type")
 	if !strings.Contains(contentType, "application/json") {
 		log.Warnf("content is not json, can't process:%s", contentType)
@@ -183,8 +218,12 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config PluginConfig, log log.
 		log.Warnf("identity key is empty")
 		return types.ActionContinue
 	}
-	identityKey = strings.ReplaceAll(identityKey, " ", "")
+	identityKey = normalizeIdentity(identityKey)
 	ctx.SetContext(IdentityKey, identityKey)
+	if config.SessionHeader != "" {
+		session, _ := proxywasm.GetHttpRequestHeader(config.SessionHeader)
+		ctx.SetContext(SessionKey, normalizeSession(strings.TrimSpace(session)))
+	}
 	_ = proxywasm.RemoveHttpRequestHeader("Accept-Encoding")
 	_ = proxywasm.RemoveHttpRequestHeader("Content-Length")
 	// The request has a body and requires delaying the header transmission until a cache miss occurs,
@@ -202,20 +241,21 @@ func onHttpRequestBody(ctx wrapper.HttpContext, config PluginConfig, body []byte
 		ctx.SetContext(StreamContextKey, struct{}{})
 	}
 	identityKey := ctx.GetStringContext(IdentityKey, "")
+	session := ctx.GetStringContext(SessionKey, "")
 	question := TrimQuote(bodyJson.Get(config.QuestionFrom.RequestBody).String())
 	if question == "" {
 		log.Debug("parse question from request body failed")
 		return types.ActionContinue
 	}
 	ctx.SetContext(QuestionContextKey, question)
-	err := config.redisClient.Get(config.CacheKeyPrefix+identityKey, func(response resp.Value) {
+	err := config.redisClient.Get(historyKey(config, identityKey, session), func(response resp.Value) {
 		if err := response.Error(); err != nil {
 			log.Errorf("redis get  failed, err:%v", err)
 			_ = proxywasm.ResumeHttpRequest()
 			return
 		}
 		if response.IsNull() {
-			log.Debugf("cache miss, identityKey:%s", identityKey)
+			log.Debugf("cache miss, identityKey:%s, session:%s", identityKey, session)
 			_ = proxywasm.ResumeHttpRequest()
 			return
 		}
@@ -253,7 +293,8 @@ func onHttpRequestBody(ctx wrapper.HttpContext, config PluginConfig, body []byte
 			_ = proxywasm.ResumeHttpRequest()
 			return
 		}
-		finalChat := fillHistory(chat, currMessage, fillHistoryCnt)
+		history := config.memory.Select(chat, fillHistoryCnt)
+		finalChat := fillHistory(history, currMessage, len(history))
 		var parameter map[string]any
 		err = json.Unmarshal(body, &parameter)
 		if err != nil {
@@ -461,6 +502,7 @@ func onHttpStreamResponseBody(ctx wrapper.HttpContext, config PluginConfig, chun
 func saveChatHistory(ctx wrapper.HttpContext, config PluginConfig, questionI any, value string, log log.Log) {
 	question := questionI.(string)
 	identityKey := ctx.GetStringContext(IdentityKey, "")
+	session := ctx.GetStringContext(SessionKey, "")
 	var chat []ChatHistory
 	chatHistories := ctx.GetStringContext(ChatHistories, "")
 	if chatHistories != "" {
@@ -472,17 +514,19 @@ func saveChatHistory(ctx wrapper.HttpContext, config PluginConfig, questionI any
 	}
 	chat = append(chat, ChatHistory{Role: "user", Content: question})
 	chat = append(chat, ChatHistory{Role: "assistant", Content: value})
-	if len(chat) > config.FillHistoryCnt*2 {
-		chat = chat[len(chat)-config.FillHistoryCnt*2:]
-	}
-	str, err := json.Marshal(chat)
-	if err != nil {
-		log.Errorf("marshal chat:%v failed, err:%v", chat, err)
+	kept, evicted := config.memory.Trim(chat)
+	if len(evicted) == 0 || config.Memory.Strategy != MemoryStrategySummary {
+		saveHistory(config, identityKey, session, kept, log)
 		return
 	}
-	log.Infof("start to Set history, identityKey:%s, chat:%s", identityKey, string(str))
-	_ = config.redisClient.Set(config.CacheKeyPrefix+identityKey, string(str), nil)
-	if config.CacheTTL != 0 {
-		_ = config.redisClient.Expire(config.CacheKeyPrefix+identityKey, config.CacheTTL, nil)
+	// 先保存完整的历史对话，摘要生成后只替换被移出窗口的部分，避免摘要失败时丢失对话，
+	// 也避免覆盖摘要生成期间其他请求追加的对话
+	saveHistory(config, identityKey, session, chat, log)
+	err := summarize(config, evicted, log, func(summary string) {
+		replaceHistoryPrefix(config, identityKey, session, chat[:len(chat)-len(kept)],
+			[]ChatHistory{{Role: "system", Content: summary, Summary: true}}, log)
+	})
+	if err != nil {
+		log.Errorf("summarize chat history failed, err:%v", err)
 	}
 }
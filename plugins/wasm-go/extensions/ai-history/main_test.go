@@ -14,13 +14,17 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"net"
 	"reflect"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
 	"github.com/higress-group/wasm-go/pkg/test"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/resp"
 )
 
 // 测试配置：基本Redis配置
@@ -124,6 +128,82 @@ var authRedisConfig = func() json.RawMessage {
 	return data
 }()
 
+// 测试配置：按 token 数保留历史对话，并开启会话隔离与管理接口
+var tokensMemoryConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"redis": map[string]interface{}{
+			"serviceName": "redis.static",
+		},
+		"sessionHeader": "x-session-id",
+		"adminConsumer": "admin",
+		"adminKey":      "admin-secret",
+		"adminPath":     "/history/admin/",
+		"memory": map[string]interface{}{
+			"strategy":  "tokens",
+			"maxTokens": 100,
+		},
+	})
+	return data
+}()
+
+// 测试配置：开启管理接口但未配置管理密钥
+var adminWithoutKeyConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"redis": map[string]interface{}{
+			"serviceName": "redis.static",
+		},
+		"adminConsumer": "admin",
+	})
+	return data
+}()
+
+// 测试配置：超出 token 上限时将更早的对话压缩为摘要
+var summaryMemoryConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"redis": map[string]interface{}{
+			"serviceName": "redis.static",
+		},
+		"memory": map[string]interface{}{
+			"strategy": "summary",
+			"summary": map[string]interface{}{
+				"keepTurns": 1,
+				"llm": map[string]interface{}{
+					"serviceName": "llm.dns",
+					"apiKey":      "sk-xxx",
+					"model":       "qwen-turbo",
+				},
+			},
+		},
+	})
+	return data
+}()
+
+// 测试配置：summary 策略缺少大模型配置
+var summaryWithoutLLMConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"redis": map[string]interface{}{
+			"serviceName": "redis.static",
+		},
+		"memory": map[string]interface{}{
+			"strategy": "summary",
+		},
+	})
+	return data
+}()
+
+// 测试配置：未知的记忆策略
+var unknownMemoryConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"redis": map[string]interface{}{
+			"serviceName": "redis.static",
+		},
+		"memory": map[string]interface{}{
+			"strategy": "unknown",
+		},
+	})
+	return data
+}()
+
 func TestDistinctChat(t *testing.T) {
 	type args struct {
 		chat        []ChatHistory
@@ -319,6 +399,78 @@ func TestParseConfig(t *testing.T) {
 			require.Equal(t, 4, pluginConfig.FillHistoryCnt)
 			require.Equal(t, 1800, pluginConfig.CacheTTL)
 		})
+
+		// 测试默认的记忆策略以及管理接口配置
+		t.Run("default memory config", func(t *testing.T) {
+			host, status := test.NewTestHost(minimalRedisConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			config, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			pluginConfig := config.(*PluginConfig)
+			require.Equal(t, MemoryStrategyTurns, pluginConfig.Memory.Strategy)
+			require.Equal(t, DefaultMemoryMaxTokens, pluginConfig.Memory.MaxTokens)
+			require.Equal(t, "", pluginConfig.SessionHeader)
+			require.Equal(t, "", pluginConfig.AdminConsumer)
+			require.Equal(t, DefaultAdminPath, pluginConfig.AdminPath)
+		})
+
+		// 测试 tokens 策略、会话以及管理接口配置解析
+		t.Run("tokens memory config", func(t *testing.T) {
+			host, status := test.NewTestHost(tokensMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			config, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			pluginConfig := config.(*PluginConfig)
+			require.Equal(t, MemoryStrategyTokens, pluginConfig.Memory.Strategy)
+			require.Equal(t, 100, pluginConfig.Memory.MaxTokens)
+			require.Equal(t, "x-session-id", pluginConfig.SessionHeader)
+			require.Equal(t, "admin", pluginConfig.AdminConsumer)
+			require.Equal(t, "admin-secret", pluginConfig.AdminKey)
+			require.Equal(t, "history/admin", pluginConfig.AdminPath)
+		})
+
+		// 测试 summary 策略配置解析
+		t.Run("summary memory config", func(t *testing.T) {
+			host, status := test.NewTestHost(summaryMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			config, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			summary := config.(*PluginConfig).Memory.Summary
+			require.Equal(t, 1, summary.KeepTurns)
+			require.Equal(t, DefaultSummaryPrompt, summary.Prompt)
+			require.Equal(t, "llm.dns", summary.LLM.ServiceName)
+			require.Equal(t, int64(443), summary.LLM.ServicePort)
+			require.Equal(t, "/v1/chat/completions", summary.LLM.Path)
+			require.Equal(t, "qwen-turbo", summary.LLM.Model)
+			require.Equal(t, uint32(DefaultSummaryLLMTimeout), summary.LLM.Timeout)
+		})
+
+		// 测试缺少 LLM 配置的 summary 记忆策略
+		t.Run("summary memory without llm config", func(t *testing.T) {
+			host, status := test.NewTestHost(summaryWithoutLLMConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusFailed, status)
+		})
+
+		// 测试开启管理接口但未配置管理密钥
+		t.Run("admin consumer without admin key", func(t *testing.T) {
+			host, status := test.NewTestHost(adminWithoutKeyConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusFailed, status)
+		})
+
+		// 测试未知的记忆策略配置
+		t.Run("unknown memory config", func(t *testing.T) {
+			host, status := test.NewTestHost(unknownMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusFailed, status)
+		})
 	})
 }
 
@@ -398,6 +550,100 @@ func TestOnHttpRequestHeaders(t *testing.T) {
 			// 应该返回HeaderStopIteration
 			require.Equal(t, types.HeaderStopIteration, action)
 		})
+
+		// 测试携带会话标识的请求头处理
+		t.Run("session header", func(t *testing.T) {
+			host, status := test.NewTestHost(tokensMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/chat"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+				{"authorization", "Bearer user123"},
+				{"x-session-id", "session-1"},
+			})
+			require.Equal(t, types.HeaderStopIteration, action)
+		})
+
+		// 测试非管理员访问管理接口
+		t.Run("admin request from unauthorized consumer", func(t *testing.T) {
+			host, status := test.NewTestHost(tokensMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/history/admin?identity=Bearer%20user123"},
+				{":method", "GET"},
+				{"x-mse-consumer", "someone"},
+				{"x-ai-history-admin-key", "admin-secret"},
+			})
+			require.Equal(t, types.ActionContinue, action)
+
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(403), localResponse.StatusCode)
+		})
+
+		// 测试伪造 consumer 但缺少管理密钥
+		t.Run("admin request without admin key", func(t *testing.T) {
+			host, status := test.NewTestHost(tokensMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/history/admin/export?identity=Bearer%20user123"},
+				{":method", "GET"},
+				{"x-mse-consumer", "admin"},
+				{"x-ai-history-admin-key", "wrong"},
+			})
+			require.Equal(t, types.ActionContinue, action)
+
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(403), localResponse.StatusCode)
+		})
+
+		// 测试管理接口缺少 identity 参数
+		t.Run("admin request without identity", func(t *testing.T) {
+			host, status := test.NewTestHost(tokensMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/history/admin/export"},
+				{":method", "GET"},
+				{"x-mse-consumer", "admin"},
+				{"x-ai-history-admin-key", "admin-secret"},
+			})
+			require.Equal(t, types.ActionContinue, action)
+
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(400), localResponse.StatusCode)
+		})
+
+		// 测试管理员删除指定会话的历史对话
+		t.Run("admin delete request", func(t *testing.T) {
+			host, status := test.NewTestHost(tokensMemoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/history/admin?identity=Bearer%20user123&session=session-1"},
+				{":method", "DELETE"},
+				{"x-mse-consumer", "admin"},
+				{"x-ai-history-admin-key", "admin-secret"},
+			})
+			// 等待 Redis 响应
+			require.Equal(t, types.HeaderStopAllIterationAndWatermark, action)
+		})
 	})
 }
 
@@ -777,3 +1023,200 @@ func TestOnHttpStreamResponseBody(t *testing.T) {
 		})
 	})
 }
+
+func TestMemory(t *testing.T) {
+	turn := func(i string) []ChatHistory {
+		return []ChatHistory{{Role: "user", Content: "question " + i}, {Role: "assistant", Content: "answer " + i}}
+	}
+	chat := append(append(turn("1"), turn("2")...), turn("3")...)
+
+	t.Run("estimate tokens", func(t *testing.T) {
+		require.Equal(t, 0, estimateTokens(""))
+		require.Equal(t, 2, estimateTokens("hello"))
+		require.Equal(t, 2, estimateTokens("你好"))
+		require.Equal(t, 2*messageTokenOverhead+5, estimateChatTokens(turn("1")))
+	})
+
+	t.Run("turns memory", func(t *testing.T) {
+		m := &turnsMemory{fillHistoryCnt: 2}
+		require.Equal(t, chat[4:], m.Select(chat, 2))
+		require.Equal(t, chat, m.Select(chat, 10))
+		kept, evicted := m.Trim(chat)
+		require.Equal(t, chat[2:], kept)
+		require.Equal(t, chat[:2], evicted)
+	})
+
+	t.Run("tokens memory", func(t *testing.T) {
+		// 每轮对话约 13 个 token，上限 30 时只能保留最近两轮
+		m := &tokensMemory{maxTokens: 30}
+		require.Equal(t, chat[2:], m.Select(chat, 1))
+		kept, evicted := m.Trim(chat)
+		require.Equal(t, chat[2:], kept)
+		require.Equal(t, chat[:2], evicted)
+
+		m = &tokensMemory{maxTokens: 5}
+		require.Empty(t, m.Select(chat, 1))
+	})
+
+	t.Run("summary memory", func(t *testing.T) {
+		m := &summaryMemory{maxTokens: 25, keepTurns: 1}
+		summary := ChatHistory{Role: "system", Content: "earlier", Summary: true}
+		withSummary := append([]ChatHistory{summary}, chat...)
+
+		selected := m.Select(withSummary, 1)
+		require.Len(t, selected, 7)
+		require.Equal(t, ChatHistory{Role: "system", Content: SummaryMessagePrefix + "earlier"}, selected[0])
+
+		kept, evicted := m.Trim(withSummary)
+		require.Equal(t, chat[4:], kept)
+		require.Equal(t, append([]ChatHistory{summary}, chat[:4]...), evicted)
+
+		// 未超出 token 上限时不做压缩
+		kept, evicted = m.Trim(chat[4:])
+		require.Equal(t, chat[4:], kept)
+		require.Empty(t, evicted)
+
+		transcript := buildSummaryTranscript(evicted)
+		require.Equal(t, "", transcript)
+		transcript = buildSummaryTranscript([]ChatHistory{summary, chat[0]})
+		require.Equal(t, SummaryMessagePrefix+"earlier\n\nuser: question 1\n", transcript)
+	})
+}
+
+func TestAdminHelpers(t *testing.T) {
+	config := PluginConfig{CacheKeyPrefix: "higress-ai-history:", AdminConsumer: "admin", AdminPath: DefaultAdminPath}
+
+	t.Run("history key", func(t *testing.T) {
+		require.Equal(t, "higress-ai-history:user", historyKey(config, "user", ""))
+		require.Equal(t, "higress-ai-history:user:s1", historyKey(config, "user", "s1"))
+		require.Equal(t, "higress-ai-history:sessions:user", sessionIndexKey(config, "user"))
+		require.Equal(t, "", normalizeSession(DefaultSessionName))
+		require.Equal(t, "s1", normalizeSession("s1"))
+		require.Equal(t, DefaultSessionName, sessionName(""))
+	})
+
+	t.Run("admin mode", func(t *testing.T) {
+		require.Equal(t, AdminModeSessions, getAdminMode(config, "/ai-history/admin?identity=user", "GET"))
+		require.Equal(t, AdminModeExport, getAdminMode(config, "/v1/ai-history/admin/export?identity=user", "GET"))
+		require.Equal(t, AdminModeDelete, getAdminMode(config, "/ai-history/admin/?identity=user", "DELETE"))
+		require.Equal(t, AdminModeNone, getAdminMode(config, "/ai-history/admin", "POST"))
+		require.Equal(t, AdminModeNone, getAdminMode(config, "/v1/chat/completions", "GET"))
+
+		config.AdminConsumer = ""
+		require.Equal(t, AdminModeNone, getAdminMode(config, "/ai-history/admin?identity=user", "GET"))
+	})
+}
+
+// 测试配置：每轮对话约 13 个 token，超过两轮时将更早的对话压缩为摘要
+var summaryConcurrentConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"redis": map[string]interface{}{
+			"serviceName": "redis.static",
+		},
+		"memory": map[string]interface{}{
+			"strategy":  "summary",
+			"maxTokens": 30,
+			"summary": map[string]interface{}{
+				"keepTurns": 1,
+				"llm": map[string]interface{}{
+					"serviceName": "llm.dns",
+					"model":       "qwen-turbo",
+				},
+			},
+		},
+	})
+	return data
+}()
+
+// redisProxy 将插件发出的 Redis 命令转发给 miniredis 执行，并把结果返回给插件
+type redisProxy struct {
+	conn   net.Conn
+	reader *resp.Reader
+}
+
+func newRedisProxy(t *testing.T, server *miniredis.Miniredis) *redisProxy {
+	conn, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &redisProxy{conn: conn, reader: resp.NewReader(bufio.NewReader(conn))}
+}
+
+func (p *redisProxy) flush(t *testing.T, host test.TestHost, contextID uint32) {
+	for {
+		callouts := host.GetRedisCalloutAttributesFromContext(contextID)
+		if len(callouts) == 0 {
+			return
+		}
+		_, err := p.conn.Write(callouts[0].Query)
+		require.NoError(t, err)
+		value, _, err := p.reader.ReadValue()
+		require.NoError(t, err)
+		response, err := value.MarshalRESP()
+		require.NoError(t, err)
+		host.CallOnRedisCallResponse(callouts[0].CalloutID, 0, response)
+	}
+}
+
+func TestSummaryKeepsConcurrentTurns(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		server := miniredis.RunT(t)
+		key := historyKey(PluginConfig{CacheKeyPrefix: DefaultCacheKeyPrefix}, "user1", "")
+		turn := func(i string) []ChatHistory {
+			return []ChatHistory{{Role: "user", Content: "question " + i}, {Role: "assistant", Content: "answer " + i}}
+		}
+		history, _ := json.Marshal(append(turn("1"), turn("2")...))
+		require.NoError(t, server.Set(key, string(history)))
+
+		host, status := test.NewTestHost(summaryConcurrentConfig)
+		defer host.Reset()
+		require.Equal(t, types.OnPluginStartStatusOK, status)
+		proxy := newRedisProxy(t, server)
+
+		// 发起一轮对话，返回请求的 context id 和摘要请求的 callout id
+		chat := func(i string) (uint32, uint32) {
+			contextID := host.InitializeHttpContext()
+			host.CallOnRequestHeaders(contextID, [][2]string{
+				{":authority", "example.com"},
+				{":path", "/v1/chat/completions"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+				{"authorization", "user1"},
+			}, false)
+			host.CallOnRequestBody(contextID, []byte(`{"messages":[{"role":"user","content":"question `+i+`"}]}`), true)
+			proxy.flush(t, host, contextID)
+			host.CallOnResponseHeaders(contextID, [][2]string{{":status", "200"}, {"content-type", "application/json"}}, false)
+			host.CallOnResponseBody(contextID, []byte(`{"choices":[{"message":{"role":"assistant","content":"answer `+i+`"}}]}`), true)
+			proxy.flush(t, host, contextID)
+			callouts := host.GetCalloutAttributesFromContext(contextID)
+			require.Len(t, callouts, 1)
+			return contextID, callouts[0].CalloutID
+		}
+		summarized := func(contextID, calloutID uint32, summary string) {
+			host.CallOnHttpCallResponse(calloutID, [][2]string{{":status", "200"}}, nil,
+				[]byte(`{"choices":[{"message":{"role":"assistant","content":"`+summary+`"}}]}`))
+			proxy.flush(t, host, contextID)
+		}
+		stored := func() []ChatHistory {
+			value, err := server.Get(key)
+			require.NoError(t, err)
+			var chat []ChatHistory
+			require.NoError(t, json.Unmarshal([]byte(value), &chat))
+			return chat
+		}
+
+		// 第三轮对话触发摘要，摘要生成前第四轮对话已保存
+		firstContext, firstCallout := chat("3")
+		require.Equal(t, append(append(turn("1"), turn("2")...), turn("3")...), stored())
+		secondContext, secondCallout := chat("4")
+		require.Equal(t, append(append(append(turn("1"), turn("2")...), turn("3")...), turn("4")...), stored())
+
+		// 第一个摘要只替换第一、二轮对话，保留之后追加的对话
+		summarized(firstContext, firstCallout, "summary of 1 and 2")
+		expected := append([]ChatHistory{{Role: "system", Content: "summary of 1 and 2", Summary: true}}, append(turn("3"), turn("4")...)...)
+		require.Equal(t, expected, stored())
+
+		// 第二个摘要要替换的前缀已被改写，放弃替换
+		summarized(secondContext, secondCallout, "summary of 1, 2 and 3")
+		require.Equal(t, expected, stored())
+	})
+}
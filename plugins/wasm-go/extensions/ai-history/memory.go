@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/higress-group/wasm-go/pkg/log"
+	"github.com/higress-group/wasm-go/pkg/wrapper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	MemoryStrategyTurns   = "turns"
+	MemoryStrategyTokens  = "tokens"
+	MemoryStrategySummary = "summary"
+
+	DefaultMemoryMaxTokens   = 2000
+	DefaultSummaryKeepTurns  = 2
+	DefaultSummaryLLMTimeout = 10000
+	DefaultSummaryPrompt     = "Summarize the following conversation between a user and an AI assistant. Keep the key facts, user preferences, decisions and open questions, and reply with the summary only, in the language of the conversation."
+	SummaryMessagePrefix     = "Summary of the earlier conversation:\n"
+
+	// 每条消息额外的 token 开销，用于近似 role 等字段
+	messageTokenOverhead = 4
+)
+
+type SummaryLLM struct {
+	// @Title zh-CN 大模型服务名称
+	// @Description zh-CN 带服务类型的完整 FQDN 名称
+	ServiceName string `required:"true" yaml:"serviceName" json:"serviceName"`
+	// @Title zh-CN 大模型服务端口
+	ServicePort int64 `required:"true" yaml:"servicePort" json:"servicePort"`
+	// @Title zh-CN 大模型服务域名
+	Domain string `required:"false" yaml:"domain" json:"domain"`
+	// @Title zh-CN 大模型服务的请求路径
+	// @Description zh-CN 默认值是"/v1/chat/completions"
+	Path string `required:"false" yaml:"path" json:"path"`
+	// @Title zh-CN 大模型服务的 API Key
+	APIKey string `required:"false" yaml:"apiKey" json:"apiKey"`
+	// @Title zh-CN 模型名称
+	Model string `required:"true" yaml:"model" json:"model"`
+	// @Title zh-CN 请求超时
+	// @Description zh-CN 单位为毫秒，默认值是10000
+	Timeout uint32 `required:"false" yaml:"timeout" json:"timeout"`
+}
+
+type SummaryConfig struct {
+	// @Title zh-CN 保留的最近对话轮数
+	// @Description zh-CN 超出 token 上限时，除最近的轮数外，更早的对话会被压缩为摘要，默认值是 2
+	KeepTurns int `required:"false" yaml:"keepTurns" json:"keepTurns"`
+	// @Title zh-CN 生成摘要的提示词
+	Prompt string `required:"false" yaml:"prompt" json:"prompt"`
+	// @Title zh-CN 生成摘要使用的大模型
+	LLM SummaryLLM `required:"true" yaml:"llm" json:"llm"`
+
+	client wrapper.HttpClient `yaml:"-" json:"-"`
+}
+
+type MemoryConfig struct {
+	// @Title zh-CN 记忆策略
+	// @Description zh-CN turns 按轮数保留，tokens 按 token 数滑动窗口保留，summary 将更早的对话压缩为摘要，默认值是 turns
+	Strategy string `required:"false" yaml:"strategy" json:"strategy"`
+	// @Title zh-CN 历史对话的 token 上限
+	// @Description zh-CN tokens 和 summary 策略下生效，默认值是 2000
+	MaxTokens int `required:"false" yaml:"maxTokens" json:"maxTokens"`
+	// @Title zh-CN 摘要配置
+	// @Description zh-CN summary 策略下必填
+	Summary SummaryConfig `required:"false" yaml:"summary" json:"summary"`
+}
+
+// Memory 决定历史对话如何填充到请求中，以及保存前如何裁剪
+type Memory interface {
+	// Select 返回需要填充到本次请求中的历史对话
+	Select(chat []ChatHistory, fillHistoryCnt int) []ChatHistory
+	// Trim 返回需要保留的历史对话，以及被移出窗口的历史对话
+	Trim(chat []ChatHistory) (kept []ChatHistory, evicted []ChatHistory)
+}
+
+func parseMemoryConfig(json gjson.Result, c *PluginConfig) error {
+	m := &c.Memory
+	m.Strategy = json.Get("memory.strategy").String()
+	if m.Strategy == "" {
+		m.Strategy = MemoryStrategyTurns
+	}
+	m.MaxTokens = int(json.Get("memory.maxTokens").Int())
+	if m.MaxTokens == 0 {
+		m.MaxTokens = DefaultMemoryMaxTokens
+	}
+	if m.MaxTokens < 0 {
+		return errors.New("memory.maxTokens must be positive")
+	}
+	switch m.Strategy {
+	case MemoryStrategyTurns:
+		c.memory = &turnsMemory{fillHistoryCnt: c.FillHistoryCnt}
+	case MemoryStrategyTokens:
+		c.memory = &tokensMemory{maxTokens: m.MaxTokens}
+	case MemoryStrategySummary:
+		if err := parseSummaryConfig(json.Get("memory.summary"), &m.Summary); err != nil {
+			return err
+		}
+		c.memory = &summaryMemory{maxTokens: m.MaxTokens, keepTurns: m.Summary.KeepTurns}
+	default:
+		return fmt.Errorf("unknown memory strategy: %s", m.Strategy)
+	}
+	return nil
+}
+
+func parseSummaryConfig(json gjson.Result, s *SummaryConfig) error {
+	s.KeepTurns = int(json.Get("keepTurns").Int())
+	if s.KeepTurns <= 0 {
+		s.KeepTurns = DefaultSummaryKeepTurns
+	}
+	s.Prompt = json.Get("prompt").String()
+	if s.Prompt == "" {
+		s.Prompt = DefaultSummaryPrompt
+	}
+	s.LLM.ServiceName = json.Get("llm.serviceName").String()
+	if s.LLM.ServiceName == "" {
+		return errors.New("memory.summary.llm.serviceName must not be empty")
+	}
+	s.LLM.ServicePort = json.Get("llm.servicePort").Int()
+	if s.LLM.ServicePort == 0 {
+		if strings.HasSuffix(s.LLM.ServiceName, ".static") {
+			s.LLM.ServicePort = 80
+		} else {
+			s.LLM.ServicePort = 443
+		}
+	}
+	s.LLM.Domain = json.Get("llm.domain").String()
+	s.LLM.Path = json.Get("llm.path").String()
+	if s.LLM.Path == "" {
+		s.LLM.Path = "/v1/chat/completions"
+	}
+	s.LLM.APIKey = json.Get("llm.apiKey").String()
+	s.LLM.Model = json.Get("llm.model").String()
+	if s.LLM.Model == "" {
+		return errors.New("memory.summary.llm.model must not be empty")
+	}
+	s.LLM.Timeout = uint32(json.Get("llm.timeout").Uint())
+	if s.LLM.Timeout == 0 {
+		s.LLM.Timeout = DefaultSummaryLLMTimeout
+	}
+	s.client = wrapper.NewClusterClient(wrapper.FQDNCluster{
+		FQDN: s.LLM.ServiceName,
+		Port: s.LLM.ServicePort,
+		Host: s.LLM.Domain,
+	})
+	return nil
+}
+
+// estimateTokens 近似估算文本的 token 数：ASCII 字符按 4 个计 1 个 token，其他字符各计 1 个 token
+func estimateTokens(text string) int {
+	ascii, others := 0, 0
+	for _, r := range text {
+		if r < 128 {
+			ascii++
+		} else {
+			others++
+		}
+	}
+	return (ascii+3)/4 + others
+}
+
+func estimateChatTokens(chat []ChatHistory) int {
+	total := 0
+	for _, c := range chat {
+		total += estimateTokens(c.Content) + messageTokenOverhead
+	}
+	return total
+}
+
+func withoutSummary(chat []ChatHistory) []ChatHistory {
+	result := make([]ChatHistory, 0, len(chat))
+	for _, c := range chat {
+		if !c.Summary {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// 从后往前按轮选取不超过 token 上限的历史对话，保证不会把一轮对话拆开
+func recentWithinTokens(chat []ChatHistory, maxTokens int) []ChatHistory {
+	total := 0
+	start := len(chat)
+	for start >= 2 {
+		turnTokens := estimateChatTokens(chat[start-2 : start])
+		if total+turnTokens > maxTokens {
+			break
+		}
+		total += turnTokens
+		start -= 2
+	}
+	return chat[start:]
+}
+
+type turnsMemory struct {
+	fillHistoryCnt int
+}
+
+func (m *turnsMemory) Select(chat []ChatHistory, fillHistoryCnt int) []ChatHistory {
+	chat = withoutSummary(chat)
+	if fillHistoryCnt > len(chat) {
+		fillHistoryCnt = len(chat)
+	}
+	return chat[len(chat)-fillHistoryCnt:]
+}
+
+func (m *turnsMemory) Trim(chat []ChatHistory) ([]ChatHistory, []ChatHistory) {
+	chat = withoutSummary(chat)
+	if len(chat) > m.fillHistoryCnt*2 {
+		return chat[len(chat)-m.fillHistoryCnt*2:], chat[:len(chat)-m.fillHistoryCnt*2]
+	}
+	return chat, nil
+}
+
+type tokensMemory struct {
+	maxTokens int
+}
+
+func (m *tokensMemory) Select(chat []ChatHistory, fillHistoryCnt int) []ChatHistory {
+	return recentWithinTokens(withoutSummary(chat), m.maxTokens)
+}
+
+func (m *tokensMemory) Trim(chat []ChatHistory) ([]ChatHistory, []ChatHistory) {
+	chat = withoutSummary(chat)
+	kept := recentWithinTokens(chat, m.maxTokens)
+	return kept, chat[:len(chat)-len(kept)]
+}
+
+type summaryMemory struct {
+	maxTokens int
+	keepTurns int
+}
+
+func (m *summaryMemory) Select(chat []ChatHistory, fillHistoryCnt int) []ChatHistory {
+	result := make([]ChatHistory, 0, len(chat))
+	for _, c := range chat {
+		if c.Summary {
+			result = append(result, ChatHistory{Role: "system", Content: SummaryMessagePrefix + c.Content})
+		} else {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func (m *summaryMemory) Trim(chat []ChatHistory) ([]ChatHistory, []ChatHistory) {
+	if estimateChatTokens(withoutSummary(chat)) <= m.maxTokens {
+		return chat, nil
+	}
+	keep := m.keepTurns * 2
+	messages := withoutSummary(chat)
+	if keep >= len(messages) {
+		return chat, nil
+	}
+	// 旧的摘要和更早的对话一起被重新摘要
+	evicted := make([]ChatHistory, 0, len(chat))
+	for _, c := range chat {
+		if c.Summary {
+			evicted = append(evicted, c)
+		}
+	}
+	evicted = append(evicted, messages[:len(messages)-keep]...)
+	return messages[len(messages)-keep:], evicted
+}
+
+func buildSummaryTranscript(evicted []ChatHistory) string {
+	var builder strings.Builder
+	for _, c := range evicted {
+		if c.Summary {
+			builder.WriteString(SummaryMessagePrefix)
+			builder.WriteString(c.Content)
+			builder.WriteString("\n\n")
+			continue
+		}
+		builder.WriteString(c.Role)
+		builder.WriteString(": ")
+		builder.WriteString(c.Content)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// summarize 调用大模型将被移出窗口的对话压缩为摘要
+func summarize(config PluginConfig, evicted []ChatHistory, log log.Log, callback func(summary string)) error {
+	summaryConfig := config.Memory.Summary
+	body, _ := json.Marshal(map[string]any{
+		"model": summaryConfig.LLM.Model,
+		"messages": []ChatHistory{
+			{Role: "system", Content: summaryConfig.Prompt},
+			{Role: "user", Content: buildSummaryTranscript(evicted)},
+		},
+	})
+	headers := [][2]string{{"Content-Type", "application/json"}}
+	if summaryConfig.LLM.APIKey != "" {
+		headers = append(headers, [2]string{"Authorization", "Bearer " + summaryConfig.LLM.APIKey})
+	}
+	return summaryConfig.client.Post(summaryConfig.LLM.Path, headers, body, func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+		if statusCode != http.StatusOK {
+			log.Errorf("summarize chat history failed, status:%d, body:%s", statusCode, responseBody)
+			return
+		}
+		summary := gjson.GetBytes(responseBody, "choices.0.message.content").String()
+		if summary == "" {
+			log.Errorf("summarize chat history failed, empty summary, body:%s", responseBody)
+			return
+		}
+		callback(summary)
+	}, summaryConfig.LLM.Timeout)
+}
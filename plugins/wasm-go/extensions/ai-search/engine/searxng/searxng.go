@@ -0,0 +1,126 @@
+package searxng
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/higress-group/wasm-go/pkg/wrapper"
+	"github.com/tidwall/gjson"
+
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine"
+)
+
+type SearxngSearch struct {
+	optionArgs         map[string]string
+	path               string
+	categories         string
+	engines            string
+	count              int
+	timeoutMillisecond uint32
+	client             wrapper.HttpClient
+}
+
+func NewSearxngSearch(config *gjson.Result) (*SearxngSearch, error) {
+	engine := &SearxngSearch{}
+	serviceName := config.Get("serviceName").String()
+	if serviceName == "" {
+		return nil, errors.New("serviceName not found")
+	}
+	servicePort := config.Get("servicePort").Int()
+	if servicePort == 0 {
+		if strings.HasSuffix(serviceName, ".static") {
+			servicePort = 80
+		} else if strings.HasSuffix(serviceName, ".dns") {
+			servicePort = 443
+		} else {
+			return nil, errors.New("servicePort not found")
+		}
+	}
+	engine.client = wrapper.NewClusterClient(wrapper.FQDNCluster{
+		FQDN: serviceName,
+		Port: servicePort,
+		Host: config.Get("domain").String(),
+	})
+	engine.path = config.Get("path").String()
+	if engine.path == "" {
+		engine.path = "/search"
+	}
+	engine.categories = config.Get("categories").String()
+	if engine.categories == "" {
+		engine.categories = "general"
+	}
+	engine.engines = config.Get("engines").String()
+	// SearXNG does not support limiting the number of results, so they are truncated when parsing
+	engine.count = int(config.Get("count").Uint())
+	if engine.count == 0 {
+		engine.count = 10
+	}
+	engine.timeoutMillisecond = uint32(config.Get("timeoutMillisecond").Uint())
+	if engine.timeoutMillisecond == 0 {
+		engine.timeoutMillisecond = 5000
+	}
+	engine.optionArgs = map[string]string{}
+	for key, value := range config.Get("optionArgs").Map() {
+		valStr := value.String()
+		if valStr != "" {
+			engine.optionArgs[key] = value.String()
+		}
+	}
+	return engine, nil
+}
+
+func (s SearxngSearch) NeedExectue(ctx engine.SearchContext) bool {
+	return ctx.EngineType == "" || ctx.EngineType == "internet"
+}
+
+func (s SearxngSearch) Client() wrapper.HttpClient {
+	return s.client
+}
+
+func (s SearxngSearch) CallArgs(ctx engine.SearchContext) engine.CallArgs {
+	queryUrl := fmt.Sprintf("%s?q=%s&format=json&categories=%s",
+		s.path, url.QueryEscape(strings.Join(ctx.Querys, " ")), url.QueryEscape(s.categories))
+	var extraArgs []string
+	if s.engines != "" {
+		extraArgs = append(extraArgs, fmt.Sprintf("engines=%s", url.QueryEscape(s.engines)))
+	}
+	for key, value := range s.optionArgs {
+		extraArgs = append(extraArgs, fmt.Sprintf("%s=%s", key, url.QueryEscape(value)))
+	}
+	if ctx.Language != "" {
+		extraArgs = append(extraArgs, fmt.Sprintf("language=%s", url.QueryEscape(ctx.Language)))
+	}
+	if len(extraArgs) > 0 {
+		queryUrl = fmt.Sprintf("%s&%s", queryUrl, strings.Join(extraArgs, "&"))
+	}
+	return engine.CallArgs{
+		Method: http.MethodGet,
+		Url:    queryUrl,
+		Headers: [][2]string{
+			{"Accept", "application/json"},
+		},
+		TimeoutMillisecond: s.timeoutMillisecond,
+	}
+}
+
+func (s SearxngSearch) ParseResult(ctx engine.SearchContext, response []byte) []engine.SearchResult {
+	jsonObj := gjson.ParseBytes(response)
+	var results []engine.SearchResult
+	for _, item := range jsonObj.Get("results").Array() {
+		result := engine.SearchResult{
+			Title:   item.Get("title").String(),
+			Link:    item.Get("url").String(),
+			Content: item.Get("content").String(),
+		}
+		if result.Valid() {
+			results = append(results, result)
+		}
+		if len(results) >= s.count {
+			break
+		}
+	}
+	return results
+}
@@ -0,0 +1,80 @@
+package searxng
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/higress-group/wasm-go/pkg/test"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine"
+)
+
+func newTestSearch(t *testing.T, config string) *SearxngSearch {
+	json := gjson.Parse(config)
+	search, err := NewSearxngSearch(&json)
+	require.NoError(t, err)
+	return search
+}
+
+func TestNewSearxngSearch(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		search := newTestSearch(t, `{"serviceName":"searxng.dns","optionArgs":{"safesearch":"1","empty":""}}`)
+		require.Equal(t, "/search", search.path)
+		require.Equal(t, "general", search.categories)
+		require.Equal(t, 10, search.count)
+		require.Equal(t, uint32(5000), search.timeoutMillisecond)
+		require.Equal(t, map[string]string{"safesearch": "1"}, search.optionArgs)
+
+		for _, config := range []string{`{}`, `{"serviceName":"searxng"}`} {
+			json := gjson.Parse(config)
+			_, err := NewSearxngSearch(&json)
+			require.Error(t, err, config)
+		}
+	})
+}
+
+func TestSearxngCallArgs(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		search := newTestSearch(t, `{"serviceName":"searxng.static","path":"/api/search","categories":"news,it","engines":"bing,duckduckgo","timeoutMillisecond":3000}`)
+		args := search.CallArgs(engine.SearchContext{Querys: []string{"higress gateway", "a&b"}, Language: "zh-CN"})
+		require.Equal(t, http.MethodGet, args.Method)
+		require.Equal(t, uint32(3000), args.TimeoutMillisecond)
+		require.Equal(t, [][2]string{{"Accept", "application/json"}}, args.Headers)
+
+		parsed, err := url.Parse(args.Url)
+		require.NoError(t, err)
+		require.Equal(t, "/api/search", parsed.Path)
+		query := parsed.Query()
+		require.Equal(t, "higress gateway a&b", query.Get("q"))
+		require.Equal(t, "json", query.Get("format"))
+		require.Equal(t, "news,it", query.Get("categories"))
+		require.Equal(t, "bing,duckduckgo", query.Get("engines"))
+		require.Equal(t, "zh-CN", query.Get("language"))
+
+		args = newTestSearch(t, `{"serviceName":"searxng.dns"}`).CallArgs(engine.SearchContext{Querys: []string{"higress"}})
+		require.Equal(t, "/search?q=higress&format=json&categories=general", args.Url)
+	})
+}
+
+func TestSearxngParseResult(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		search := newTestSearch(t, `{"serviceName":"searxng.dns","count":2}`)
+		response := []byte(`{"query":"higress","results":[
+			{"title":"Higress","url":"https://higress.io","content":"AI native API gateway","engine":"bing"},
+			{"title":"","url":"https://empty-title.io","content":"invalid"},
+			{"title":"GitHub","url":"https://github.com/alibaba/higress","content":"source code"},
+			{"title":"Docs","url":"https://higress.io/docs","content":"truncated by count"}
+		]}`)
+		results := search.ParseResult(engine.SearchContext{}, response)
+		require.Equal(t, []engine.SearchResult{
+			{Title: "Higress", Link: "https://higress.io", Content: "AI native API gateway"},
+			{Title: "GitHub", Link: "https://github.com/alibaba/higress", Content: "source code"},
+		}, results)
+
+		require.Empty(t, search.ParseResult(engine.SearchContext{}, []byte(`{"results":[]}`)))
+		require.Empty(t, search.ParseResult(engine.SearchContext{}, []byte(`not json`)))
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Elements that never contain the main content of a page
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Canvas:   true,
+}
+
+// Elements that start a new line in the extracted text
+var blockElements = map[atom.Atom]bool{
+	atom.Address:    true,
+	atom.Article:    true,
+	atom.Blockquote: true,
+	atom.Br:         true,
+	atom.Dd:         true,
+	atom.Div:        true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Figcaption: true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Hr:         true,
+	atom.Li:         true,
+	atom.Main:       true,
+	atom.Ol:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Table:      true,
+	atom.Td:         true,
+	atom.Th:         true,
+	atom.Tr:         true,
+	atom.Ul:         true,
+}
+
+// Words in class or id attributes that indicate boilerplate such as menus and ads
+var boilerplateWords = map[string]bool{
+	"ad":         true,
+	"ads":        true,
+	"advert":     true,
+	"banner":     true,
+	"breadcrumb": true,
+	"comment":    true,
+	"comments":   true,
+	"cookie":     true,
+	"footer":     true,
+	"menu":       true,
+	"nav":        true,
+	"navbar":     true,
+	"popup":      true,
+	"related":    true,
+	"share":      true,
+	"sidebar":    true,
+	"social":     true,
+	"subscribe":  true,
+}
+
+// extractText extracts readable text from an HTML page, preferring the <article> or <main> element
+// and skipping scripts, navigation, footers and other boilerplate.
+func extractText(body []byte, contentType string) string {
+	reader, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		reader = bytes.NewReader(body)
+	}
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return ""
+	}
+	root := findElement(doc, atom.Article)
+	if root == nil {
+		root = findElement(doc, atom.Main)
+	}
+	if root == nil {
+		root = doc
+	}
+	extractor := &textExtractor{seen: make(map[string]bool)}
+	extractor.walk(root)
+	extractor.flush()
+	return strings.Join(extractor.lines, "\n")
+}
+
+func findElement(node *html.Node, tag atom.Atom) *html.Node {
+	if node.Type == html.ElementNode && node.DataAtom == tag {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func isBoilerplate(node *html.Node) bool {
+	if skippedElements[node.DataAtom] {
+		return true
+	}
+	switch node.DataAtom {
+	case atom.Html, atom.Body, atom.Main, atom.Article:
+		// Layout classes on these elements such as "has-sidebar" must not drop the whole page
+		return false
+	}
+	for _, attr := range node.Attr {
+		if attr.Key != "class" && attr.Key != "id" && attr.Key != "role" {
+			continue
+		}
+		words := strings.FieldsFunc(strings.ToLower(attr.Val), func(r rune) bool {
+			return r == ' ' || r == '-' || r == '_'
+		})
+		for _, word := range words {
+			if boilerplateWords[word] || word == "navigation" || word == "contentinfo" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type textExtractor struct {
+	lines   []string
+	current strings.Builder
+	seen    map[string]bool
+}
+
+func (e *textExtractor) walk(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		e.current.WriteString(node.Data)
+		return
+	case html.CommentNode, html.DoctypeNode:
+		return
+	case html.ElementNode:
+		if isBoilerplate(node) {
+			return
+		}
+	}
+	block := node.Type == html.ElementNode && blockElements[node.DataAtom]
+	if block {
+		e.flush()
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		e.walk(child)
+	}
+	if block {
+		e.flush()
+	}
+}
+
+// flush collapses whitespace of the current line and drops empty or repeated lines
+func (e *textExtractor) flush() {
+	line := strings.Join(strings.Fields(e.current.String()), " ")
+	e.current.Reset()
+	if line == "" || e.seen[line] {
+		return
+	}
+	e.seen[line] = true
+	e.lines = append(e.lines, line)
+}
+
+// truncateByTokens truncates text to approximately maxTokens tokens, counting every 4 ASCII characters
+// and every other character as one token.
+func truncateByTokens(text string, maxTokens int) string {
+	budget := maxTokens * 4
+	used := 0
+	for i, r := range text {
+		cost := 4
+		if r < 128 {
+			cost = 1
+		}
+		if used+cost > budget {
+			return text[:i] + "..."
+		}
+		used += cost
+	}
+	return text
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/higress-group/wasm-go/pkg/log"
+	"github.com/higress-group/wasm-go/pkg/wrapper"
+	"github.com/tidwall/gjson"
+
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine"
+)
+
+const (
+	DEFAULT_FETCH_TOP_N      = 3
+	DEFAULT_FETCH_MAX_TOKENS = 1500
+	DEFAULT_FETCH_TIMEOUT    = 5000
+	FETCH_USER_AGENT         = "Mozilla/5.0 (compatible; Higress-AI-Search/1.0)"
+)
+
+type FetchService struct {
+	domains []string
+	client  wrapper.HttpClient
+}
+
+type WebFetch struct {
+	topN               int
+	maxTokens          int // Token budget of the extracted text of each page
+	timeoutMillisecond uint32
+	services           []FetchService
+}
+
+func parseWebFetch(json gjson.Result) (*WebFetch, error) {
+	webFetch := &WebFetch{}
+	webFetch.topN = int(json.Get("topN").Int())
+	if webFetch.topN == 0 {
+		webFetch.topN = DEFAULT_FETCH_TOP_N
+	}
+	if webFetch.topN < 0 {
+		return nil, fmt.Errorf("invalid topN:%d", webFetch.topN)
+	}
+	webFetch.maxTokens = int(json.Get("maxTokens").Int())
+	if webFetch.maxTokens == 0 {
+		webFetch.maxTokens = DEFAULT_FETCH_MAX_TOKENS
+	}
+	if webFetch.maxTokens < 0 {
+		return nil, fmt.Errorf("invalid maxTokens:%d", webFetch.maxTokens)
+	}
+	webFetch.timeoutMillisecond = uint32(json.Get("timeoutMillisecond").Uint())
+	if webFetch.timeoutMillisecond == 0 {
+		webFetch.timeoutMillisecond = DEFAULT_FETCH_TIMEOUT
+	}
+	for _, s := range json.Get("services").Array() {
+		serviceName := s.Get("serviceName").String()
+		if serviceName == "" {
+			return nil, errors.New("serviceName not found")
+		}
+		servicePort := s.Get("servicePort").Int()
+		if servicePort == 0 {
+			if strings.HasSuffix(serviceName, ".static") {
+				servicePort = 80
+			} else if strings.HasSuffix(serviceName, ".dns") {
+				servicePort = 443
+			} else {
+				return nil, errors.New("servicePort not found")
+			}
+		}
+		var domains []string
+		for _, domain := range s.Get("domains").Array() {
+			if d := strings.ToLower(strings.TrimSpace(domain.String())); d != "" {
+				domains = append(domains, d)
+			}
+		}
+		if len(domains) == 0 {
+			return nil, fmt.Errorf("domains not found for service:%s", serviceName)
+		}
+		webFetch.services = append(webFetch.services, FetchService{
+			domains: domains,
+			client: wrapper.NewClusterClient(wrapper.FQDNCluster{
+				FQDN: serviceName,
+				Port: servicePort,
+			}),
+		})
+	}
+	if len(webFetch.services) == 0 {
+		return nil, errors.New("no fetch service found")
+	}
+	return webFetch, nil
+}
+
+// matchDomain supports exact domains, wildcard suffixes like "*.example.com" and "*" for all domains
+func matchDomain(pattern, host string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return pattern == host
+}
+
+// clientFor returns the client of the first service whose domains match the host of the link
+func (w *WebFetch) clientFor(link string) wrapper.HttpClient {
+	parsedUrl, err := url.Parse(link)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") {
+		return nil
+	}
+	host := strings.ToLower(parsedUrl.Hostname())
+	for _, service := range w.services {
+		for _, domain := range service.domains {
+			if matchDomain(domain, host) {
+				return service.client
+			}
+		}
+	}
+	return nil
+}
+
+// pageText extracts the readable text of a fetched page, only HTML and plain text pages are supported
+func (w *WebFetch) pageText(contentType string, body []byte) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	var text string
+	switch {
+	case mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		text = extractText(body, contentType)
+	case mediaType == "text/plain" || mediaType == "text/markdown":
+		text = strings.TrimSpace(string(body))
+	default:
+		return ""
+	}
+	return truncateByTokens(text, w.maxTokens)
+}
+
+// fetchPages fetches the top N results in parallel and replaces their snippets with the extracted page text.
+// The snippet is kept when the page can not be fetched or extracted. It returns false if no page is fetched,
+// in which case callback will not be called.
+func (w *WebFetch) fetchPages(results []engine.SearchResult, callback func(results []engine.SearchResult)) bool {
+	var fetching, finished int
+	for i := 0; i < len(results) && i < w.topN; i++ {
+		index := i
+		link := results[index].Link
+		client := w.clientFor(link)
+		if client == nil {
+			log.Debugf("no fetch service matches link: %s", link)
+			continue
+		}
+		err := client.Get(link, [][2]string{
+			{"Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5"},
+			{"User-Agent", FETCH_USER_AGENT},
+		}, func(statusCode int, responseHeaders http.Header, responseBody []byte) {
+			defer func() {
+				finished++
+				if finished == fetching {
+					callback(results)
+				}
+			}()
+			if statusCode != http.StatusOK {
+				log.Warnf("fetch page failed, status: %d, link: %s", statusCode, link)
+				return
+			}
+			text := w.pageText(responseHeaders.Get("Content-Type"), responseBody)
+			if text == "" {
+				log.Debugf("no readable text extracted from link: %s", link)
+				return
+			}
+			results[index].Content = text
+		}, w.timeoutMillisecond)
+		if err != nil {
+			log.Errorf("fetch page failed, link: %s, err: %v", link, err)
+			continue
+		}
+		fetching++
+	}
+	return fetching > 0
+}
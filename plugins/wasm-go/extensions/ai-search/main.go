@@ -36,6 +36,7 @@ import (
 	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine/elasticsearch"
 	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine/google"
 	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine/quark"
+	"github.com/alibaba/higress/plugins/wasm-go/extensions/ai-search/engine/searxng"
 )
 
 type SearchRewrite struct {
@@ -57,6 +58,7 @@ type Config struct {
 	needReference     bool
 	referenceLocation string // "head" or "tail"
 	searchRewrite     *SearchRewrite
+	webFetch          *WebFetch
 	defaultEnable     bool
 }
 
@@ -200,6 +202,14 @@ func parseConfig(json gjson.Result, config *Config) error {
 			}
 			config.engine = append(config.engine, searchEngine)
 			internetExists = true
+		case "searxng":
+			searchEngine, err := searxng.NewSearxngSearch(&e)
+			if err != nil {
+				return fmt.Errorf("searxng search engine init failed:%s", err)
+			}
+			config.engine = append(config.engine, searchEngine)
+			internetExists = true
+			onlyQuark = false
 		default:
 			return fmt.Errorf("unkown search engine:%s", e.Get("type").String())
 		}
@@ -270,6 +280,14 @@ func parseConfig(json gjson.Result, config *Config) error {
 		searchRewrite.prompt = strings.Replace(searchRewrite.prompt, "{max_count}", fmt.Sprintf("%d", searchRewrite.maxCount), -1)
 		config.searchRewrite = searchRewrite
 	}
+	webFetchJson := json.Get("webFetch")
+	if webFetchJson.Exists() {
+		webFetch, err := parseWebFetch(webFetchJson)
+		if err != nil {
+			return fmt.Errorf("web fetch init failed:%s", err)
+		}
+		config.webFetch = webFetch
+	}
 	if len(config.engine) == 0 {
 		return fmt.Errorf("no avaliable search engine found")
 	}
@@ -502,40 +520,12 @@ func executeSearch(ctx wrapper.HttpContext, config Config, queryIndex int, body
 								proxywasm.ResumeHttpRequest()
 								return
 							}
-							// Format search results for prompt template
-							var formattedResults []string
-							var formattedReferences []string
-							for j, result := range mergedResults {
-								if config.needReference {
-									formattedResults = append(formattedResults,
-										fmt.Sprintf("[webpage %d begin]\n%s\n[webpage %d end]", j+1, result.Content, j+1))
-									formattedReferences = append(formattedReferences,
-										fmt.Sprintf("[%d] [%s](%s)", j+1, result.Title, result.Link))
-								} else {
-									formattedResults = append(formattedResults,
-										fmt.Sprintf("[webpage begin]\n%s\n[webpage end]", result.Content))
-								}
-							}
-							// Prepare template variables
-							curDate := time.Now().In(time.FixedZone("CST", 8*3600)).Format("2006年1月2日")
-							searchResults := strings.Join(formattedResults, "\n")
-							log.Debugf("searchResults: %s", searchResults)
-							// Fill prompt template
-							prompt := strings.Replace(config.promptTemplate, "{search_results}", searchResults, 1)
-							prompt = strings.Replace(prompt, "{question}", searchContexts[0].Querys[0], 1)
-							prompt = strings.Replace(prompt, "{cur_date}", curDate, 1)
-							// Update request body with processed prompt
-							modifiedBody, err := sjson.SetBytes(body, fmt.Sprintf("messages.%d.content", queryIndex), prompt)
-							if err != nil {
-								log.Errorf("modify request message content failed, err:%v, body:%s", err, body)
-							} else {
-								log.Debugf("modifeid body:%s", modifiedBody)
-								proxywasm.ReplaceHttpRequestBody(modifiedBody)
-								if config.needReference {
-									ctx.SetContext("References", strings.Join(formattedReferences, "\n\n"))
-								}
+							if config.webFetch != nil && config.webFetch.fetchPages(mergedResults, func(results []engine.SearchResult) {
+								injectSearchResults(ctx, config, queryIndex, body, searchContexts, results)
+							}) {
+								return
 							}
-							proxywasm.ResumeHttpRequest()
+							injectSearchResults(ctx, config, queryIndex, body, searchContexts, mergedResults)
 						}
 					}()
 					if statusCode != http.StatusOK {
@@ -558,6 +548,44 @@ func executeSearch(ctx wrapper.HttpContext, config Config, queryIndex int, body
 	return types.ActionContinue
 }
 
+// injectSearchResults fills the search results into the prompt template and resumes the request
+func injectSearchResults(ctx wrapper.HttpContext, config Config, queryIndex int, body []byte, searchContexts []engine.SearchContext, results []engine.SearchResult) {
+	// Format search results for prompt template
+	var formattedResults []string
+	var formattedReferences []string
+	for j, result := range results {
+		if config.needReference {
+			formattedResults = append(formattedResults,
+				fmt.Sprintf("[webpage %d begin]\n%s\n[webpage %d end]", j+1, result.Content, j+1))
+			formattedReferences = append(formattedReferences,
+				fmt.Sprintf("[%d] [%s](%s)", j+1, result.Title, result.Link))
+		} else {
+			formattedResults = append(formattedResults,
+				fmt.Sprintf("[webpage begin]\n%s\n[webpage end]", result.Content))
+		}
+	}
+	// Prepare template variables
+	curDate := time.Now().In(time.FixedZone("CST", 8*3600)).Format("2006年1月2日")
+	searchResults := strings.Join(formattedResults, "\n")
+	log.Debugf("searchResults: %s", searchResults)
+	// Fill prompt template
+	prompt := strings.Replace(config.promptTemplate, "{search_results}", searchResults, 1)
+	prompt = strings.Replace(prompt, "{question}", searchContexts[0].Querys[0], 1)
+	prompt = strings.Replace(prompt, "{cur_date}", curDate, 1)
+	// Update request body with processed prompt
+	modifiedBody, err := sjson.SetBytes(body, fmt.Sprintf("messages.%d.content", queryIndex), prompt)
+	if err != nil {
+		log.Errorf("modify request message content failed, err:%v, body:%s", err, body)
+	} else {
+		log.Debugf("modifeid body:%s", modifiedBody)
+		proxywasm.ReplaceHttpRequestBody(modifiedBody)
+		if config.needReference {
+			ctx.SetContext("References", strings.Join(formattedReferences, "\n\n"))
+		}
+	}
+	proxywasm.ResumeHttpRequest()
+}
+
 func onHttpResponseHeaders(ctx wrapper.HttpContext, config Config) types.Action {
 	if !config.needReference {
 		ctx.DontReadResponseBody()
@@ -0,0 +1,215 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
+	"github.com/higress-group/wasm-go/pkg/test"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// 测试配置：SearXNG 搜索引擎，并通过代理服务抓取 example.com 下的网页
+var webFetchConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"searchFrom": []map[string]interface{}{
+			{
+				"type":        "searxng",
+				"serviceName": "searxng.dns",
+				"servicePort": 8080,
+				"count":       5,
+			},
+		},
+		"webFetch": map[string]interface{}{
+			"topN":      2,
+			"maxTokens": 100,
+			"services": []map[string]interface{}{
+				{
+					"serviceName": "fetch-proxy.dns",
+					"domains":     []string{"*.example.com"},
+				},
+			},
+		},
+	})
+	return data
+}()
+
+func TestParseWebFetch(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		t.Run("defaults", func(t *testing.T) {
+			webFetch, err := parseWebFetch(gjson.Parse(`{"services":[{"serviceName":"proxy.dns","domains":[" Example.COM "]}]}`))
+			require.NoError(t, err)
+			require.Equal(t, DEFAULT_FETCH_TOP_N, webFetch.topN)
+			require.Equal(t, DEFAULT_FETCH_MAX_TOKENS, webFetch.maxTokens)
+			require.Equal(t, uint32(DEFAULT_FETCH_TIMEOUT), webFetch.timeoutMillisecond)
+			require.Len(t, webFetch.services, 1)
+			require.Equal(t, []string{"example.com"}, webFetch.services[0].domains)
+		})
+
+		t.Run("invalid config", func(t *testing.T) {
+			for name, config := range map[string]string{
+				"no services":        `{}`,
+				"no domains":         `{"services":[{"serviceName":"proxy.dns"}]}`,
+				"no port":            `{"services":[{"serviceName":"proxy","domains":["*"]}]}`,
+				"negative topN":      `{"topN":-1,"services":[{"serviceName":"proxy.dns","domains":["*"]}]}`,
+				"negative maxTokens": `{"maxTokens":-1,"services":[{"serviceName":"proxy.dns","domains":["*"]}]}`,
+			} {
+				_, err := parseWebFetch(gjson.Parse(config))
+				require.Error(t, err, name)
+			}
+		})
+	})
+}
+
+func TestWebFetchClientFor(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		webFetch, err := parseWebFetch(gjson.Parse(`{"services":[
+			{"serviceName":"docs.dns","domains":["docs.example.com"]},
+			{"serviceName":"wildcard.dns","domains":["*.example.com"]},
+			{"serviceName":"fallback.dns","domains":["*"]}
+		]}`))
+		require.NoError(t, err)
+		docs, wildcard, fallback := webFetch.services[0].client, webFetch.services[1].client, webFetch.services[2].client
+
+		require.Equal(t, docs, webFetch.clientFor("https://docs.example.com/guide"))
+		require.Equal(t, docs, webFetch.clientFor("https://DOCS.example.com:8443/guide"))
+		require.Equal(t, wildcard, webFetch.clientFor("http://www.example.com/a?b=c"))
+		// 通配符只匹配子域名
+		require.Equal(t, fallback, webFetch.clientFor("https://example.com/"))
+		require.Equal(t, fallback, webFetch.clientFor("https://notexample.com/"))
+		// 只抓取 http 和 https 链接
+		require.Nil(t, webFetch.clientFor("ftp://docs.example.com/file"))
+		require.Nil(t, webFetch.clientFor("file:///etc/passwd"))
+		require.Nil(t, webFetch.clientFor("://invalid"))
+
+		require.True(t, matchDomain("*", "any.host"))
+		require.True(t, matchDomain("*.example.com", "a.b.example.com"))
+		require.False(t, matchDomain("*.example.com", "example.com"))
+		require.False(t, matchDomain("example.com", "www.example.com"))
+	})
+}
+
+func TestWebFetchPageText(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		webFetch := &WebFetch{maxTokens: 2}
+		require.Equal(t, "Title", webFetch.pageText("text/html; charset=utf-8", []byte("<html><body><p>Title</p></body></html>")))
+		require.Equal(t, "plain", webFetch.pageText("text/plain", []byte("  plain \n")))
+		require.Equal(t, "hello wo...", webFetch.pageText("text/markdown", []byte("hello world")))
+		require.Equal(t, "", webFetch.pageText("application/pdf", []byte("%PDF-1.4")))
+		require.Equal(t, "", webFetch.pageText("image/png", []byte{0x89, 'P', 'N', 'G'}))
+	})
+}
+
+func TestExtractText(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		t.Run("prefer article", func(t *testing.T) {
+			page := `<html><head><title>Page</title><script>var a = 1;</script></head><body>
+				<nav><a href="/">Home</a></nav>
+				<div class="sidebar">Sidebar link</div>
+				<article>
+					<h1>Higress   AI  Search</h1>
+					<p>First <b>paragraph</b>.</p>
+					<div class="share-buttons">Share this</div>
+					<p>First <b>paragraph</b>.</p>
+					<ul><li>Item one</li><li>Item two</li></ul>
+				</article>
+				<footer>Copyright</footer>
+			</body></html>`
+			require.Equal(t, "Higress AI Search\nFirst paragraph.\nItem one\nItem two", extractText([]byte(page), "text/html"))
+		})
+
+		t.Run("whole body without article", func(t *testing.T) {
+			page := `<html><body class="has-sidebar"><header>Logo</header><div id="content"><p>Body text</p><style>p{}</style></div>
+				<div role="navigation">Menu</div><noscript>Enable JS</noscript></body></html>`
+			require.Equal(t, "Body text", extractText([]byte(page), "text/html"))
+		})
+
+		t.Run("charset", func(t *testing.T) {
+			// "中文" 的 GBK 编码
+			page := append([]byte("<html><body><p>"), 0xd6, 0xd0, 0xce, 0xc4)
+			page = append(page, []byte("</p></body></html>")...)
+			require.Equal(t, "中文", extractText(page, "text/html; charset=gbk"))
+		})
+	})
+}
+
+func TestTruncateByTokens(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		require.Equal(t, "abcdefgh", truncateByTokens("abcdefgh", 2))
+		require.Equal(t, "abcdefgh...", truncateByTokens("abcdefghi", 2))
+		// 非 ASCII 字符每个算一个 token
+		require.Equal(t, "中文", truncateByTokens("中文", 2))
+		require.Equal(t, "中文...", truncateByTokens("中文测试", 2))
+		require.Equal(t, "abcd中...", truncateByTokens("abcd中文", 2))
+		require.Equal(t, "...", truncateByTokens("abc", 0))
+	})
+}
+
+func TestWebFetchSearch(t *testing.T) {
+	test.RunGoTest(t, func(t *testing.T) {
+		sendRequest := func(host test.TestHost) types.Action {
+			host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/v1/chat/completions"},
+				{":method", "POST"},
+				{"content-type", "application/json"},
+			})
+			return host.CallOnHttpRequestBody([]byte(`{"model":"qwen-turbo","messages":[{"role":"user","content":"Higress 是什么？"}]}`))
+		}
+		searchResponse := []byte(`{"results":[
+			{"title":"Higress","url":"https://www.example.com/higress","content":"snippet of higress"},
+			{"title":"Other","url":"https://other.org/page","content":"snippet of other"},
+			{"title":"Docs","url":"https://docs.example.com/guide","content":"snippet of docs"}
+		]}`)
+
+		t.Run("fetched pages replace snippets", func(t *testing.T) {
+			host, status := test.NewTestHost(webFetchConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			require.Equal(t, types.ActionPause, sendRequest(host))
+			host.CallOnHttpCall([][2]string{{":status", "200"}, {"content-type", "application/json"}}, searchResponse)
+			// topN 为 2，只有第一个结果匹配抓取服务
+			host.CallOnHttpCall([][2]string{{":status", "200"}, {"content-type", "text/html"}},
+				[]byte(`<html><body><nav>Menu</nav><article><p>Higress is a cloud native API gateway.</p></article></body></html>`))
+			require.Equal(t, types.ActionContinue, host.GetHttpStreamAction())
+
+			content := gjson.GetBytes(host.GetRequestBody(), "messages.0.content").String()
+			require.Contains(t, content, "Higress is a cloud native API gateway.")
+			require.NotContains(t, content, "snippet of higress")
+			require.NotContains(t, content, "Menu")
+			require.Contains(t, content, "snippet of other")
+			require.Contains(t, content, "snippet of docs")
+		})
+
+		t.Run("snippet kept when fetch fails", func(t *testing.T) {
+			host, status := test.NewTestHost(webFetchConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			require.Equal(t, types.ActionPause, sendRequest(host))
+			host.CallOnHttpCall([][2]string{{":status", "200"}, {"content-type", "application/json"}}, searchResponse)
+			host.CallOnHttpCall([][2]string{{":status", "302"}, {"location", "https://www.example.com/login"}}, nil)
+			require.Equal(t, types.ActionContinue, host.GetHttpStreamAction())
+
+			content := gjson.GetBytes(host.GetRequestBody(), "messages.0.content").String()
+			require.True(t, strings.Contains(content, "snippet of higress"))
+		})
+	})
+}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/alibaba/higress/plugins/wasm-go/pkg/consumer"
 	"github.com/higress-group/wasm-go/pkg/log"
 	"github.com/higress-group/wasm-go/pkg/wrapper"
 
@@ -79,6 +81,13 @@ type BasicAuthConfig struct {
 	// @Scope GLOBAL
 	consumers []Consumer `yaml:"consumers"`
 
+	// @Title 调用方目录
+	// @Title en-US Consumer Directory
+	// @Description 从 Kubernetes Secret 或 Redis 加载调用方，密码支持 SHA-256 或 bcrypt 哈希，支持元数据和过期时间。
+	// @Description en-US Consumers loaded from Kubernetes Secrets or Redis, with SHA-256 or bcrypt hashed passwords, metadata and expiry.
+	// @Scope GLOBAL
+	directory *consumer.Directory `yaml:"consumer_directory"`
+
 	// @Title 授权访问的调用方列表
 	// @Title en-US Allowed Consumers
 	// @Description 对于匹配上述条件的请求，允许访问的调用方列表。
@@ -115,12 +124,24 @@ func parseGlobalConfig(json gjson.Result, global *BasicAuthConfig, log log.Log)
 	global.credential2Name = make(map[string]string)
 	global.username2Passwd = make(map[string]string)
 
-	consumers := json.Get("consumers")
-	if !consumers.Exists() {
-		return errors.New("consumers is required")
+	directory := json.Get("consumer_directory")
+	if directory.Exists() {
+		d, err := consumer.ParseDirectory(consumer.KindBasic, directory)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse consumer_directory")
+		}
+		global.directory = d
 	}
-	if len(consumers.Array()) == 0 {
-		return errors.New("consumers cannot be empty")
+
+	// 配置了 consumer_directory 时可以不配置 consumers
+	consumers := json.Get("consumers")
+	if global.directory == nil {
+		if !consumers.Exists() {
+			return errors.New("consumers is required")
+		}
+		if len(consumers.Array()) == 0 {
+			return errors.New("consumers cannot be empty")
+		}
 	}
 
 	for _, item := range consumers.Array() {
@@ -195,7 +216,6 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config BasicAuthConfig, log l
 	var (
 		noAllow            = len(config.allow) == 0 // 未配置 allow 列表，表示插件在该 domain/route 未生效
 		globalAuthNoSet    = config.globalAuth == nil
-		globalAuthSetTrue  = !globalAuthNoSet && *config.globalAuth
 		globalAuthSetFalse = !globalAuthNoSet && !*config.globalAuth
 	)
 	// log.Debugf("global auth set: %t", !globalAuthNoSet)
@@ -242,6 +262,9 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config BasicAuthConfig, log l
 	}
 
 	user, passwd := userAndPasswd[0], userAndPasswd[1]
+	if _, ok := config.username2Passwd[user]; !ok && config.directory != nil {
+		return lookupDirectory(config, user, passwd, log)
+	}
 	if correctPasswd, ok := config.username2Passwd[user]; !ok {
 		log.Warnf("credential username %q is not configured", user)
 		return deniedInvalidCredentials()
@@ -259,24 +282,72 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config BasicAuthConfig, log l
 		return deniedUnauthorizedConsumer()
 	}
 
+	authorizeConsumer(config, &consumer.Consumer{Name: name}, log)
+	return types.ActionContinue
+}
+
+// lookupDirectory 在调用方目录中查找用户名并校验密码，需要访问 Redis 时暂停请求，在回调中恢复
+func lookupDirectory(config BasicAuthConfig, user, passwd string, log log.Log) types.Action {
+	var pending bool
+	pending = config.directory.Lookup(user, func(c *consumer.Consumer, err error) {
+		var allowed bool
+		switch {
+		case err != nil:
+			log.Errorf("failed to look up consumer directory: %v", err)
+			deniedDirectoryUnavailable()
+		case c == nil:
+			log.Warnf("credential username %q is not configured", user)
+			deniedInvalidCredentials()
+		case !c.VerifySecret(passwd):
+			log.Warnf("credential password is not correct for username %q", user)
+			deniedInvalidCredentials()
+		case !c.Valid(time.Now()):
+			log.Warnf("consumer %q is expired or disabled", c.Name)
+			deniedUnauthorizedConsumer()
+		default:
+			allowed = authorizeConsumer(config, c, log)
+		}
+		if allowed && pending {
+			proxywasm.ResumeHttpRequest()
+		}
+	})
+	if pending {
+		return types.HeaderStopAllIterationAndWatermark
+	}
+	return types.ActionContinue
+}
+
+// authorizeConsumer 根据 allow 列表判断已识别的调用方是否允许访问，不允许时发送拒绝响应并返回 false
+func authorizeConsumer(config BasicAuthConfig, c *consumer.Consumer, log log.Log) bool {
+	var (
+		noAllow            = len(config.allow) == 0
+		globalAuthNoSet    = config.globalAuth == nil
+		globalAuthSetTrue  = !globalAuthNoSet && *config.globalAuth
+		globalAuthSetFalse = !globalAuthNoSet && !*config.globalAuth
+		name               = c.Name
+	)
+
 	// 全局生效：
 	// - global_auth == true 且 当前 domain/route 未配置该插件
 	// - global_auth 未设置 且 没有任何一个 domain/route 配置该插件
 	if (globalAuthSetTrue && noAllow) || (globalAuthNoSet && !ruleSet) {
 		// log.Debug("authenticated case 1")
 		log.Infof("consumer %q authenticated", name)
-		return authenticated(name)
+		authenticated(config, c)
+		return true
 	}
 
 	// 全局生效，但当前 domain/route 配置了 allow 列表
 	if globalAuthSetTrue && !noAllow {
 		if !contains(config.allow, name) {
 			log.Warnf("consumer %q is not allowed", name)
-			return deniedUnauthorizedConsumer()
+			deniedUnauthorizedConsumer()
+			return false
 		}
 		// log.Debug("authenticated case 2")
 		log.Infof("consumer %q authenticated", name)
-		return authenticated(name)
+		authenticated(config, c)
+		return true
 	}
 
 	// 非全局生效
@@ -284,15 +355,17 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config BasicAuthConfig, log l
 		if !noAllow { // 配置了 allow 列表
 			if !contains(config.allow, name) {
 				log.Warnf("consumer %q is not allowed", name)
-				return deniedUnauthorizedConsumer()
+				deniedUnauthorizedConsumer()
+				return false
 			}
 			// log.Debug("authenticated case 3")
 			log.Infof("consumer %q authenticated", name)
-			return authenticated(name)
+			authenticated(config, c)
+			return true
 		}
 	}
 
-	return types.ActionContinue
+	return true
 }
 
 func deniedNoBasicAuthData() types.Action {
@@ -313,11 +386,17 @@ func deniedUnauthorizedConsumer() types.Action {
 	return types.ActionContinue
 }
 
-func authenticated(name string) types.Action {
-	_ = proxywasm.AddHttpRequestHeader("X-Mse-Consumer", name)
+func deniedDirectoryUnavailable() types.Action {
+	_ = proxywasm.SendHttpResponseWithDetail(http.StatusServiceUnavailable, "basic-auth.directory_unavailable", nil,
+		[]byte("Request denied by Basic Auth check. Consumer directory is unavailable."), -1)
 	return types.ActionContinue
 }
 
+func authenticated(config BasicAuthConfig, c *consumer.Consumer) {
+	consumer.SetIdentity(c.Name)
+	config.directory.SetMetadataHeaders(c)
+}
+
 func WWWAuthenticateHeader(realm string) [][2]string {
 	return [][2]string{
 		{"WWW-Authenticate", fmt.Sprintf("Basic realm=%s", realm)},
@@ -423,6 +423,30 @@ var emptyMatchConfig = func() json.RawMessage {
 	return data
 }()
 
+// 测试配置：调用方目录，admin 的密码为 123456 的 bcrypt 哈希，guest 的密码为 abc 的 SHA-256 摘要
+var consumerDirectoryConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"consumer_directory": map[string]interface{}{
+			"consumers": []map[string]interface{}{
+				{
+					"name":       "consumer1",
+					"credential": "admin:$2a$04$HhQ.8AhTyM3WdGY0V6Wg4uRwM0yTaSp/KLmFcnwN3tz66Ey2Na01a",
+				},
+				{
+					"name":       "consumer2",
+					"credential": "guest:sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+					"disabled":   true,
+				},
+			},
+			"redis": map[string]interface{}{
+				"service_name": "redis.static",
+			},
+		},
+		"global_auth": true,
+	})
+	return data
+}()
+
 func TestParseGlobalConfig(t *testing.T) {
 	test.RunGoTest(t, func(t *testing.T) {
 		// 测试基本全局配置解析
@@ -869,3 +893,98 @@ func TestCompleteFlow(t *testing.T) {
 		})
 	})
 }
+
+func TestConsumerDirectory(t *testing.T) {
+	test.RunTest(t, func(t *testing.T) {
+		// 测试 bcrypt 哈希的密码
+		t.Run("bcrypt password", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			encodedCredential := base64.StdEncoding.EncodeToString([]byte("admin:123456"))
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+				{"authorization", "Basic " + encodedCredential},
+			})
+
+			require.Equal(t, types.ActionContinue, action)
+			require.Nil(t, host.GetLocalResponse())
+			requestHeaders := host.GetRequestHeaders()
+			require.True(t, test.HasHeaderWithValue(requestHeaders, "X-Mse-Consumer", "consumer1"))
+
+			host.CompleteHttp()
+		})
+
+		// 测试错误的密码
+		t.Run("wrong password", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			encodedCredential := base64.StdEncoding.EncodeToString([]byte("admin:654321"))
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+				{"authorization", "Basic " + encodedCredential},
+			})
+
+			require.Equal(t, types.ActionContinue, action)
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(401), localResponse.StatusCode)
+
+			host.CompleteHttp()
+		})
+
+		// 测试已禁用的调用方
+		t.Run("disabled consumer", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			encodedCredential := base64.StdEncoding.EncodeToString([]byte("guest:abc"))
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+				{"authorization", "Basic " + encodedCredential},
+			})
+
+			require.Equal(t, types.ActionContinue, action)
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(403), localResponse.StatusCode)
+
+			host.CompleteHttp()
+		})
+
+		// 测试从 Redis 中查找调用方
+		t.Run("redis consumer", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			encodedCredential := base64.StdEncoding.EncodeToString([]byte("user3:abc"))
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+				{"authorization", "Basic " + encodedCredential},
+			})
+			require.Equal(t, types.HeaderStopAllIterationAndWatermark, action)
+
+			host.CallOnRedisCall(0, test.CreateRedisRespString(`{"name":"consumer3","credential":"user3:sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"}`))
+
+			require.Nil(t, host.GetLocalResponse())
+			require.Equal(t, types.ActionContinue, host.GetHttpStreamAction())
+			requestHeaders := host.GetRequestHeaders()
+			require.True(t, test.HasHeaderWithValue(requestHeaders, "X-Mse-Consumer", "consumer3"))
+
+			host.CompleteHttp()
+		})
+	})
+}
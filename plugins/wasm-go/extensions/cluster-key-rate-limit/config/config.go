@@ -19,6 +19,9 @@ type LimitRuleItemType string
 // LimitConfigItemType 限流配置项key类型
 type LimitConfigItemType string
 
+// LimitAlgorithm 限流算法
+type LimitAlgorithm string
+
 const (
 	LimitByHeaderType      LimitRuleItemType = "limit_by_header"
 	LimitByParamType       LimitRuleItemType = "limit_by_param"
@@ -40,9 +43,16 @@ const (
 	RemoteAddrSourceType = "remote-addr"
 	HeaderSourceType     = "header"
 
+	FixedWindowAlgorithm          LimitAlgorithm = "fixed_window"           // 固定窗口
+	SlidingWindowLogAlgorithm     LimitAlgorithm = "sliding_window_log"     // 滑动窗口日志
+	SlidingWindowCounterAlgorithm LimitAlgorithm = "sliding_window_counter" // 滑动窗口计数
+	GcraAlgorithm                 LimitAlgorithm = "gcra"                   // GCRA(令牌桶)
+
 	DefaultRejectedCode uint32 = 429
 	DefaultRejectedMsg  string = "Too many requests"
 
+	DefaultLocalPreLimitMaxKeys = 10000
+
 	Second           int64 = 1
 	SecondsPerMinute       = 60 * Second
 	SecondsPerHour         = 60 * SecondsPerMinute
@@ -63,6 +73,9 @@ type ClusterKeyRateLimitConfig struct {
 	ShowLimitQuotaHeader bool             // 响应头中是否显示X-RateLimit-Limit和X-RateLimit-Remaining
 	RejectedCode         uint32           // 当请求超过阈值被拒绝时,返回的HTTP状态码
 	RejectedMsg          string           // 当请求超过阈值被拒绝时,返回的响应体
+	Algorithm            LimitAlgorithm   // 限流算法
+	Burst                int64            // GCRA算法允许的突发请求数,为0时等于时间窗口内请求数
+	LocalPreLimit        *LocalPreLimit   // 本地预限流配置
 	RedisClient          wrapper.RedisClient
 }
 
@@ -158,9 +171,68 @@ func ParseClusterKeyRateLimitConfig(json gjson.Result, config *ClusterKeyRateLim
 	} else {
 		config.RejectedMsg = DefaultRejectedMsg
 	}
+
+	if err := initLimitAlgorithm(json, config); err != nil {
+		return err
+	}
+
+	localPreLimit := json.Get("local_pre_limit")
+	if localPreLimit.Exists() {
+		preLimit, err := parseLocalPreLimit(localPreLimit)
+		if err != nil {
+			return fmt.Errorf("failed to parse local_pre_limit: %w", err)
+		}
+		config.LocalPreLimit = preLimit
+	}
 	return nil
 }
 
+func initLimitAlgorithm(json gjson.Result, config *ClusterKeyRateLimitConfig) error {
+	algorithm := LimitAlgorithm(json.Get("algorithm").String())
+	switch algorithm {
+	case "":
+		config.Algorithm = FixedWindowAlgorithm
+	case FixedWindowAlgorithm, SlidingWindowLogAlgorithm, SlidingWindowCounterAlgorithm, GcraAlgorithm:
+		config.Algorithm = algorithm
+	default:
+		return fmt.Errorf("unsupported algorithm '%s', must be one of 'fixed_window', 'sliding_window_log', 'sliding_window_counter' or 'gcra'", algorithm)
+	}
+
+	burst := json.Get("burst")
+	if burst.Exists() {
+		if config.Algorithm != GcraAlgorithm {
+			return errors.New("'burst' can only be set when algorithm is 'gcra'")
+		}
+		if burst.Int() <= 0 {
+			return fmt.Errorf("'burst' must be a positive integer, got %d", burst.Int())
+		}
+		config.Burst = burst.Int()
+	}
+	return nil
+}
+
+func parseLocalPreLimit(json gjson.Result) (*LocalPreLimit, error) {
+	preLimit := &LocalPreLimit{
+		MaxKeys: DefaultLocalPreLimitMaxKeys,
+	}
+	thresholdRatio := json.Get("threshold_ratio")
+	if thresholdRatio.Exists() {
+		ratio := thresholdRatio.Float()
+		if ratio <= 0 || ratio > 1 {
+			return nil, fmt.Errorf("'threshold_ratio' must be in (0, 1], got %v", ratio)
+		}
+		preLimit.ThresholdRatio = ratio
+	}
+	maxKeys := json.Get("max_keys")
+	if maxKeys.Exists() {
+		if maxKeys.Int() <= 0 {
+			return nil, fmt.Errorf("'max_keys' must be a positive integer, got %d", maxKeys.Int())
+		}
+		preLimit.MaxKeys = int(maxKeys.Int())
+	}
+	return preLimit, nil
+}
+
 func initLimitRule(json gjson.Result, config *ClusterKeyRateLimitConfig) error {
 	globalThresholdResult := json.Get("global_threshold")
 	ruleItemsResult := json.Get("rule_items")
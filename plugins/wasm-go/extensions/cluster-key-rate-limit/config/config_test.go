@@ -46,6 +46,7 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 				},
 				RejectedCode: DefaultRejectedCode,
 				RejectedMsg:  DefaultRejectedMsg,
+				Algorithm:    FixedWindowAlgorithm,
 			},
 		},
 		{
@@ -64,6 +65,7 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 				},
 				RejectedCode: DefaultRejectedCode,
 				RejectedMsg:  DefaultRejectedMsg,
+				Algorithm:    FixedWindowAlgorithm,
 			},
 		},
 		{
@@ -112,6 +114,7 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 				},
 				RejectedCode: DefaultRejectedCode,
 				RejectedMsg:  DefaultRejectedMsg,
+				Algorithm:    FixedWindowAlgorithm,
 			},
 		},
 		{
@@ -163,6 +166,7 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 				},
 				RejectedCode: DefaultRejectedCode,
 				RejectedMsg:  DefaultRejectedMsg,
+				Algorithm:    FixedWindowAlgorithm,
 			},
 		},
 		{
@@ -197,6 +201,7 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 				},
 				RejectedCode: 403,
 				RejectedMsg:  "Forbidden",
+				Algorithm:    FixedWindowAlgorithm,
 			},
 		},
 		{
@@ -215,8 +220,80 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 				ShowLimitQuotaHeader: true,
 				RejectedCode:         DefaultRejectedCode,
 				RejectedMsg:          DefaultRejectedMsg,
+				Algorithm:            FixedWindowAlgorithm,
 			},
 		},
+		{
+			name: "Algorithm_Gcra",
+			json: `{
+				"rule_name": "gcra-limit",
+				"algorithm": "gcra",
+				"burst": 20,
+				"global_threshold": {"query_per_second": 100}
+			}`,
+			expected: ClusterKeyRateLimitConfig{
+				RuleName: "gcra-limit",
+				GlobalThreshold: &GlobalThreshold{
+					Count:      100,
+					TimeWindow: Second,
+				},
+				RejectedCode: DefaultRejectedCode,
+				RejectedMsg:  DefaultRejectedMsg,
+				Algorithm:    GcraAlgorithm,
+				Burst:        20,
+			},
+		},
+		{
+			name: "Algorithm_SlidingWindowLogWithLocalPreLimit",
+			json: `{
+				"rule_name": "sliding-window-log-limit",
+				"algorithm": "sliding_window_log",
+				"local_pre_limit": {"threshold_ratio": 0.5},
+				"global_threshold": {"query_per_minute": 100}
+			}`,
+			expected: ClusterKeyRateLimitConfig{
+				RuleName: "sliding-window-log-limit",
+				GlobalThreshold: &GlobalThreshold{
+					Count:      100,
+					TimeWindow: SecondsPerMinute,
+				},
+				RejectedCode: DefaultRejectedCode,
+				RejectedMsg:  DefaultRejectedMsg,
+				Algorithm:    SlidingWindowLogAlgorithm,
+				LocalPreLimit: &LocalPreLimit{
+					ThresholdRatio: 0.5,
+					MaxKeys:        DefaultLocalPreLimitMaxKeys,
+				},
+			},
+		},
+		{
+			name: "Algorithm_Unsupported",
+			json: `{
+				"rule_name": "unsupported-algorithm",
+				"algorithm": "leaky_bucket",
+				"global_threshold": {"query_per_second": 100}
+			}`,
+			expectedErr: errors.New("unsupported algorithm 'leaky_bucket', must be one of 'fixed_window', 'sliding_window_log', 'sliding_window_counter' or 'gcra'"),
+		},
+		{
+			name: "Burst_WithoutGcra",
+			json: `{
+				"rule_name": "burst-without-gcra",
+				"algorithm": "sliding_window_counter",
+				"burst": 10,
+				"global_threshold": {"query_per_second": 100}
+			}`,
+			expectedErr: errors.New("'burst' can only be set when algorithm is 'gcra'"),
+		},
+		{
+			name: "LocalPreLimit_InvalidThresholdRatio",
+			json: `{
+				"rule_name": "invalid-local-pre-limit",
+				"local_pre_limit": {"threshold_ratio": 1.5},
+				"global_threshold": {"query_per_second": 100}
+			}`,
+			expectedErr: errors.New("failed to parse local_pre_limit: 'threshold_ratio' must be in (0, 1], got 1.5"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -234,3 +311,44 @@ func TestParseClusterKeyRateLimitConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalPreLimit(t *testing.T) {
+	t.Run("BlockedByRedis", func(t *testing.T) {
+		preLimit := &LocalPreLimit{MaxKeys: DefaultLocalPreLimitMaxKeys}
+		allowed, _ := preLimit.Allow("key", 10, Second, 1000)
+		assert.True(t, allowed)
+
+		preLimit.Block("key", 1500, 1000)
+		allowed, waitMs := preLimit.Allow("key", 10, Second, 1200)
+		assert.False(t, allowed)
+		assert.Equal(t, int64(300), waitMs)
+
+		allowed, _ = preLimit.Allow("key", 10, Second, 1500)
+		assert.True(t, allowed)
+	})
+
+	t.Run("ThresholdRatio", func(t *testing.T) {
+		preLimit := &LocalPreLimit{ThresholdRatio: 0.5, MaxKeys: DefaultLocalPreLimitMaxKeys}
+		for i := 0; i < 5; i++ {
+			allowed, _ := preLimit.Allow("key", 10, Second, 1100)
+			assert.True(t, allowed)
+		}
+		allowed, waitMs := preLimit.Allow("key", 10, Second, 1200)
+		assert.False(t, allowed)
+		assert.Equal(t, int64(800), waitMs)
+
+		// 进入下一个时间窗口后重新计数
+		allowed, _ = preLimit.Allow("key", 10, Second, 2000)
+		assert.True(t, allowed)
+	})
+
+	t.Run("MaxKeys", func(t *testing.T) {
+		preLimit := &LocalPreLimit{MaxKeys: 2}
+		preLimit.Block("a", 5000, 1000)
+		preLimit.Allow("b", 10, Second, 1000)
+		preLimit.Allow("c", 10, Second, 1000)
+		assert.LessOrEqual(t, len(preLimit.entries), 2)
+		allowed, _ := preLimit.Allow("a", 10, Second, 1000)
+		assert.False(t, allowed)
+	})
+}
@@ -0,0 +1,79 @@
+package config
+
+import "math"
+
+// LocalPreLimit 本地预限流,在 Redis 之前于当前 VM 内拦截请求
+//   - 被 Redis 拒绝的限流 key 在 Retry-After 时间内直接在本地拒绝
+//   - 配置 ThresholdRatio 时,每个 VM 在时间窗口内最多放行 count*ThresholdRatio 个请求去访问 Redis
+type LocalPreLimit struct {
+	ThresholdRatio float64 // 每个 VM 的本地阈值占时间窗口内请求数的比例,为0时不限制
+	MaxKeys        int     // 本地记录的最大限流 key 数量
+
+	entries map[string]*localLimitEntry
+}
+
+type localLimitEntry struct {
+	windowStart  int64 // 本地时间窗口开始时间(毫秒)
+	windowEnd    int64 // 本地时间窗口结束时间(毫秒)
+	count        int64 // 本地时间窗口内放行的请求数
+	blockedUntil int64 // 被 Redis 拒绝后,在该时间(毫秒)前直接拒绝
+}
+
+// Allow 判断请求是否可以继续访问 Redis,不允许时返回需要等待的毫秒数
+func (l *LocalPreLimit) Allow(key string, count, timeWindow int64, nowMs int64) (bool, int64) {
+	entry := l.entry(key, nowMs)
+	if entry.blockedUntil > nowMs {
+		return false, entry.blockedUntil - nowMs
+	}
+	if l.ThresholdRatio <= 0 {
+		return true, 0
+	}
+	if nowMs >= entry.windowEnd {
+		// 与 Redis 中的固定窗口对齐,避免各 VM 的窗口边界不一致
+		windowMs := timeWindow * 1000
+		entry.windowStart = nowMs - nowMs%windowMs
+		entry.windowEnd = entry.windowStart + windowMs
+		entry.count = 0
+	}
+	threshold := int64(math.Ceil(float64(count) * l.ThresholdRatio))
+	if entry.count >= threshold {
+		return false, entry.windowEnd - nowMs
+	}
+	entry.count++
+	return true, 0
+}
+
+// Block 记录被 Redis 拒绝的限流 key,在 untilMs 之前的请求直接在本地拒绝
+func (l *LocalPreLimit) Block(key string, untilMs int64, nowMs int64) {
+	entry := l.entry(key, nowMs)
+	if untilMs > entry.blockedUntil {
+		entry.blockedUntil = untilMs
+	}
+}
+
+func (l *LocalPreLimit) entry(key string, nowMs int64) *localLimitEntry {
+	if l.entries == nil {
+		l.entries = make(map[string]*localLimitEntry)
+	}
+	if entry, ok := l.entries[key]; ok {
+		return entry
+	}
+	if len(l.entries) >= l.MaxKeys {
+		l.evict(nowMs)
+	}
+	entry := &localLimitEntry{}
+	l.entries[key] = entry
+	return entry
+}
+
+// evict 清理已过期的记录,仍然超过上限时清空全部记录
+func (l *LocalPreLimit) evict(nowMs int64) {
+	for key, entry := range l.entries {
+		if entry.windowEnd <= nowMs && entry.blockedUntil <= nowMs {
+			delete(l.entries, key)
+		}
+	}
+	if len(l.entries) >= l.MaxKeys {
+		l.entries = make(map[string]*localLimitEntry)
+	}
+}
@@ -16,10 +16,12 @@ package main
 
 import (
 	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"cluster-key-rate-limit/config"
 	"cluster-key-rate-limit/util"
@@ -71,6 +73,97 @@ const (
 		
 		return {threshold, current, redis.call('ttl', key)}
 	`
+	// 以下脚本均返回 {阈值, 当前请求数, 配额完全恢复的秒数, 被拒绝时需要等待的毫秒数}
+	SlidingWindowLogScript = `
+		if redis.replicate_commands then redis.replicate_commands() end
+		local key = KEYS[1]
+		local threshold = tonumber(ARGV[1])
+		local window = tonumber(ARGV[2]) * 1000
+		local member = ARGV[3]
+		local time = redis.call('time')
+		local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+		-- 移除时间窗口之外的请求记录
+		redis.call('zremrangebyscore', key, '-inf', now - window)
+		local current = redis.call('zcard', key)
+		if current >= threshold then
+			-- 最早的请求移出窗口后才能再次放行,最新的请求移出窗口后配额完全恢复
+			local oldest = tonumber(redis.call('zrange', key, 0, 0, 'withscores')[2])
+			local newest = tonumber(redis.call('zrange', key, -1, -1, 'withscores')[2])
+			return {threshold, current + 1, math.ceil((newest + window - now) / 1000), oldest + window - now}
+		end
+
+		redis.call('zadd', key, now, now .. ':' .. member)
+		redis.call('pexpire', key, window)
+		return {threshold, current + 1, math.ceil(window / 1000), 0}
+	`
+	SlidingWindowCounterScript = `
+		if redis.replicate_commands then redis.replicate_commands() end
+		local key = KEYS[1]
+		local threshold = tonumber(ARGV[1])
+		local window = tonumber(ARGV[2]) * 1000
+		local time = redis.call('time')
+		local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+		local currentWindow = math.floor(now / window)
+		local elapsed = now - currentWindow * window
+		local previous = tonumber(redis.call('hget', key, tostring(currentWindow - 1)) or "0")
+		local current = tonumber(redis.call('hget', key, tostring(currentWindow)) or "0")
+		-- 按上一个窗口在滑动窗口中的剩余占比估算请求数
+		local estimated = math.floor(previous * (window - elapsed) / window) + current
+		if estimated >= threshold then
+			local retry
+			if current < threshold then
+				-- 等待上一个窗口的占比下降到阈值以下
+				retry = math.floor(window - (threshold - current) * window / previous) + 1 - elapsed
+			else
+				-- 等待进入下一个窗口,且当前窗口的占比下降到阈值以下
+				retry = window - elapsed + math.floor(window - threshold * window / current) + 1
+			end
+			local reset = window - elapsed
+			if current > 0 then
+				reset = reset + window
+			end
+			return {threshold, estimated + 1, math.ceil(reset / 1000), retry}
+		end
+
+		redis.call('hincrby', key, tostring(currentWindow), 1)
+		-- 只保留当前和上一个窗口的计数
+		for _, field in ipairs(redis.call('hkeys', key)) do
+			if tonumber(field) < currentWindow - 1 then
+				redis.call('hdel', key, field)
+			end
+		end
+		redis.call('pexpire', key, window * 2)
+		return {threshold, estimated + 1, math.ceil((window * 2 - elapsed) / 1000), 0}
+	`
+	GcraScript = `
+		if redis.replicate_commands then redis.replicate_commands() end
+		local key = KEYS[1]
+		local threshold = tonumber(ARGV[1])
+		local window = tonumber(ARGV[2]) * 1000
+		local burst = tonumber(ARGV[3])
+		local time = redis.call('time')
+		local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+		-- 每个请求的发放间隔,以及允许的最大突发容量
+		local interval = window / threshold
+		local tolerance = interval * burst
+		-- tat(theoretical arrival time) 为令牌桶重新装满的时间
+		local tat = tonumber(redis.call('get', key) or "0")
+		if tat < now then
+			tat = now
+		end
+		local newTat = tat + interval
+		local allowAt = newTat - tolerance
+		if allowAt > now then
+			return {burst, burst + 1, math.ceil((tat - now) / 1000), math.ceil(allowAt - now)}
+		end
+
+		redis.call('set', key, string.format('%.3f', newTat), 'px', math.ceil(newTat - now))
+		local remaining = math.floor((now - allowAt) / interval)
+		return {burst, burst - remaining, math.ceil((newTat - now) / 1000), 0}
+	`
 
 	LimitContextKey = "LimitContext" // 限流上下文信息
 
@@ -78,13 +171,15 @@ const (
 
 	RateLimitLimitHeader     = "X-RateLimit-Limit"     // 限制的总请求数
 	RateLimitRemainingHeader = "X-RateLimit-Remaining" // 剩余还可以发送的请求数
-	RateLimitResetHeader     = "X-RateLimit-Reset"     // 限流重置时间（触发限流时返回）
+	RateLimitResetHeader     = "X-RateLimit-Reset"     // 限流重置时间
+	RetryAfterHeader         = "Retry-After"           // 触发限流后需要等待的秒数
 )
 
 type LimitContext struct {
-	count     int
-	remaining int
-	reset     int
+	count      int
+	remaining  int
+	reset      int
+	retryAfter int // 触发限流后需要等待的毫秒数
 }
 
 func parseConfig(json gjson.Result, cfg *config.ClusterKeyRateLimitConfig) error {
@@ -121,26 +216,51 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, cfg config.ClusterKeyRateLimi
 		timeWindow = configItem.TimeWindow
 	}
 
+	// 本地预限流,命中时不再访问 Redis
+	if cfg.LocalPreLimit != nil {
+		if allowed, waitMs := cfg.LocalPreLimit.Allow(limitKey, count, timeWindow, time.Now().UnixMilli()); !allowed {
+			limit := count
+			if cfg.Algorithm == config.GcraAlgorithm {
+				limit = gcraBurst(cfg, count)
+			}
+			rejected(cfg, LimitContext{
+				count:      int(limit),
+				reset:      ceilSeconds(waitMs),
+				retryAfter: int(waitMs),
+			})
+			return types.ActionContinue
+		}
+	}
+
 	// 执行限流逻辑
-	keys := []interface{}{limitKey}
-	args := []interface{}{count, timeWindow}
-	err := cfg.RedisClient.Eval(FixedWindowScript, 1, keys, args, func(response resp.Value) {
+	script, keys, args := buildLimitScript(cfg, limitKey, count, timeWindow)
+	err := cfg.RedisClient.Eval(script, 1, keys, args, func(response resp.Value) {
 		resultArray := response.Array()
-		if len(resultArray) != 3 {
+		if len(resultArray) != 3 && len(resultArray) != 4 {
 			log.Errorf("redis response parse error, response: %v", response)
 			proxywasm.ResumeHttpRequest()
 			return
 		}
 
 		// 获取限流结果
-		threshold, current, ttl := resultArray[0].Integer(), resultArray[1].Integer(), resultArray[2].Integer()
+		threshold, current, reset := resultArray[0].Integer(), resultArray[1].Integer(), resultArray[2].Integer()
+		// 固定窗口在窗口过期后才能再次放行
+		retryAfter := reset * 1000
+		if len(resultArray) == 4 {
+			retryAfter = resultArray[3].Integer()
+		}
 		context := LimitContext{
-			count:     threshold,
-			remaining: threshold - current,
-			reset:     ttl,
+			count:      threshold,
+			remaining:  threshold - current,
+			reset:      reset,
+			retryAfter: retryAfter,
 		}
 		if current > threshold {
 			// 触发限流
+			if cfg.LocalPreLimit != nil && retryAfter > 0 {
+				now := time.Now().UnixMilli()
+				cfg.LocalPreLimit.Block(limitKey, now+int64(retryAfter), now)
+			}
 			rejected(cfg, context)
 		} else {
 			ctx.SetContext(LimitContextKey, context)
@@ -163,10 +283,39 @@ func onHttpResponseHeaders(ctx wrapper.HttpContext, config config.ClusterKeyRate
 	if config.ShowLimitQuotaHeader {
 		_ = proxywasm.ReplaceHttpResponseHeader(RateLimitLimitHeader, strconv.Itoa(limitContext.count))
 		_ = proxywasm.ReplaceHttpResponseHeader(RateLimitRemainingHeader, strconv.Itoa(limitContext.remaining))
+		_ = proxywasm.ReplaceHttpResponseHeader(RateLimitResetHeader, strconv.Itoa(limitContext.reset))
 	}
 	return types.ActionContinue
 }
 
+// buildLimitScript 根据限流算法返回对应的 Lua 脚本及参数,不同算法使用不同的 key 避免数据结构冲突
+func buildLimitScript(cfg config.ClusterKeyRateLimitConfig, limitKey string, count, timeWindow int64) (string, []interface{}, []interface{}) {
+	switch cfg.Algorithm {
+	case config.SlidingWindowLogAlgorithm:
+		// 同一毫秒内的请求需要不同的成员
+		member := strconv.FormatInt(rand.Int63(), 36)
+		return SlidingWindowLogScript, []interface{}{limitKey + ":" + string(cfg.Algorithm)}, []interface{}{count, timeWindow, member}
+	case config.SlidingWindowCounterAlgorithm:
+		return SlidingWindowCounterScript, []interface{}{limitKey + ":" + string(cfg.Algorithm)}, []interface{}{count, timeWindow}
+	case config.GcraAlgorithm:
+		return GcraScript, []interface{}{limitKey + ":" + string(cfg.Algorithm)}, []interface{}{count, timeWindow, gcraBurst(cfg, count)}
+	default:
+		return FixedWindowScript, []interface{}{limitKey}, []interface{}{count, timeWindow}
+	}
+}
+
+func gcraBurst(cfg config.ClusterKeyRateLimitConfig, count int64) int64 {
+	if cfg.Burst > 0 {
+		return cfg.Burst
+	}
+	return count
+}
+
+// ceilSeconds 毫秒向上取整为秒
+func ceilSeconds(ms int64) int {
+	return int((ms + 999) / 1000)
+}
+
 func checkRequestAgainstLimitRule(ctx wrapper.HttpContext, ruleItems []config.LimitRuleItem) (string, *config.LimitRuleItem, *config.LimitConfigItem) {
 	if len(ruleItems) > 0 {
 		for _, rule := range ruleItems {
@@ -291,6 +440,11 @@ func getDownStreamIp(rule config.LimitRuleItem) (net.IP, error) {
 func rejected(config config.ClusterKeyRateLimitConfig, context LimitContext) {
 	headers := make(map[string][]string)
 	headers[RateLimitResetHeader] = []string{strconv.Itoa(context.reset)}
+	retryAfter := ceilSeconds(int64(context.retryAfter))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	headers[RetryAfterHeader] = []string{strconv.Itoa(retryAfter)}
 	if config.ShowLimitQuotaHeader {
 		headers[RateLimitLimitHeader] = []string{strconv.Itoa(context.count)}
 		headers[RateLimitRemainingHeader] = []string{strconv.Itoa(0)}
@@ -222,6 +222,42 @@ var regexpLimitConfig = func() json.RawMessage {
 	return data
 }()
 
+// 测试配置：滑动窗口日志算法,并开启本地预限流
+var slidingWindowLogConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"rule_name": "routeA-sliding-window-log-rule",
+		"global_threshold": map[string]interface{}{
+			"query_per_minute": 100,
+		},
+		"algorithm":       "sliding_window_log",
+		"local_pre_limit": map[string]interface{}{},
+		"redis": map[string]interface{}{
+			"service_name": "redis.static",
+			"service_port": 6379,
+		},
+		"show_limit_quota_header": true,
+	})
+	return data
+}()
+
+// 测试配置：GCRA算法
+var gcraConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"rule_name": "routeA-gcra-rule",
+		"global_threshold": map[string]interface{}{
+			"query_per_second": 10,
+		},
+		"algorithm": "gcra",
+		"burst":     5,
+		"redis": map[string]interface{}{
+			"service_name": "redis.static",
+			"service_port": 6379,
+		},
+		"show_limit_quota_header": true,
+	})
+	return data
+}()
+
 func TestParseConfig(t *testing.T) {
 	test.RunGoTest(t, func(t *testing.T) {
 		// 测试全局限流配置解析
@@ -243,6 +279,36 @@ func TestParseConfig(t *testing.T) {
 			require.True(t, parsedConfig.ShowLimitQuotaHeader)
 			require.Equal(t, uint32(429), parsedConfig.RejectedCode)
 			require.Equal(t, "Too many requests", parsedConfig.RejectedMsg)
+			require.Equal(t, config.FixedWindowAlgorithm, parsedConfig.Algorithm)
+			require.Nil(t, parsedConfig.LocalPreLimit)
+		})
+
+		// 测试滑动窗口日志算法及本地预限流配置解析
+		t.Run("sliding window log config", func(t *testing.T) {
+			host, status := test.NewTestHost(slidingWindowLogConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			cfg, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			parsedConfig := cfg.(*config.ClusterKeyRateLimitConfig)
+			require.Equal(t, config.SlidingWindowLogAlgorithm, parsedConfig.Algorithm)
+			require.NotNil(t, parsedConfig.LocalPreLimit)
+			require.Equal(t, float64(0), parsedConfig.LocalPreLimit.ThresholdRatio)
+			require.Equal(t, config.DefaultLocalPreLimitMaxKeys, parsedConfig.LocalPreLimit.MaxKeys)
+		})
+
+		// 测试GCRA算法配置解析
+		t.Run("gcra config", func(t *testing.T) {
+			host, status := test.NewTestHost(gcraConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			cfg, err := host.GetMatchConfig()
+			require.NoError(t, err)
+			parsedConfig := cfg.(*config.ClusterKeyRateLimitConfig)
+			require.Equal(t, config.GcraAlgorithm, parsedConfig.Algorithm)
+			require.Equal(t, int64(5), parsedConfig.Burst)
 		})
 
 		// 测试基于请求参数的限流配置解析
@@ -540,6 +606,71 @@ func TestOnHttpRequestHeaders(t *testing.T) {
 
 			host.CompleteHttp()
 		})
+
+		// 测试滑动窗口日志算法触发限流后,本地预限流直接拒绝后续请求
+		t.Run("local pre limit after rejected", func(t *testing.T) {
+			host, status := test.NewTestHost(slidingWindowLogConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+			})
+			require.Equal(t, types.HeaderStopAllIterationAndWatermark, action)
+
+			// 当前请求数(101)超过阈值(100),30秒后才能再次放行
+			resp := test.CreateRedisRespArray([]interface{}{100, 101, 60, 30000})
+			host.CallOnRedisCall(0, resp)
+
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(429), localResponse.StatusCode)
+			host.CompleteHttp()
+
+			// 后续请求不再访问 Redis,直接在本地拒绝
+			action = host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+			})
+			require.Equal(t, types.ActionContinue, action)
+
+			localResponse = host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(429), localResponse.StatusCode)
+			host.CompleteHttp()
+		})
+
+		// 测试GCRA算法放行请求
+		t.Run("gcra allowed", func(t *testing.T) {
+			host, status := test.NewTestHost(gcraConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/api/test"},
+				{":method", "GET"},
+			})
+			require.Equal(t, types.HeaderStopAllIterationAndWatermark, action)
+
+			resp := test.CreateRedisRespArray([]interface{}{5, 1, 1, 0})
+			host.CallOnRedisCall(0, resp)
+
+			action = host.CallOnHttpResponseHeaders([][2]string{
+				{":status", "200"},
+			})
+			require.Equal(t, types.ActionContinue, action)
+
+			responseHeaders := host.GetResponseHeaders()
+			require.True(t, test.HasHeader(responseHeaders, "x-ratelimit-limit"))
+			require.True(t, test.HasHeader(responseHeaders, "x-ratelimit-remaining"))
+			require.True(t, test.HasHeader(responseHeaders, "x-ratelimit-reset"))
+
+			host.CompleteHttp()
+		})
 	})
 }
 
@@ -672,3 +803,35 @@ func TestCompleteFlow(t *testing.T) {
 		})
 	})
 }
+
+func TestBuildLimitScript(t *testing.T) {
+	cfg := config.ClusterKeyRateLimitConfig{Algorithm: config.FixedWindowAlgorithm}
+	script, keys, args := buildLimitScript(cfg, "key", 10, 60)
+	require.Equal(t, FixedWindowScript, script)
+	require.Equal(t, []interface{}{"key"}, keys)
+	require.Equal(t, []interface{}{int64(10), int64(60)}, args)
+
+	cfg.Algorithm = config.SlidingWindowLogAlgorithm
+	script, keys, args = buildLimitScript(cfg, "key", 10, 60)
+	require.Equal(t, SlidingWindowLogScript, script)
+	require.Equal(t, []interface{}{"key:sliding_window_log"}, keys)
+	require.Len(t, args, 3)
+
+	cfg.Algorithm = config.SlidingWindowCounterAlgorithm
+	script, keys, _ = buildLimitScript(cfg, "key", 10, 60)
+	require.Equal(t, SlidingWindowCounterScript, script)
+	require.Equal(t, []interface{}{"key:sliding_window_counter"}, keys)
+
+	cfg.Algorithm = config.GcraAlgorithm
+	script, _, args = buildLimitScript(cfg, "key", 10, 60)
+	require.Equal(t, GcraScript, script)
+	require.Equal(t, []interface{}{int64(10), int64(60), int64(10)}, args)
+
+	cfg.Burst = 3
+	_, _, args = buildLimitScript(cfg, "key", 10, 60)
+	require.Equal(t, []interface{}{int64(10), int64(60), int64(3)}, args)
+
+	require.Equal(t, 0, ceilSeconds(0))
+	require.Equal(t, 1, ceilSeconds(1))
+	require.Equal(t, 2, ceilSeconds(1001))
+}
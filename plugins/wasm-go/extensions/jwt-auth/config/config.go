@@ -14,6 +14,8 @@
 
 package config
 
+import "github.com/alibaba/higress/plugins/wasm-go/pkg/consumer"
+
 var (
 	// DefaultClaimToHeaderOverride 是 claim_to_override 中 override 字段的默认值
 	DefaultClaimToHeaderOverride = true
@@ -52,6 +54,12 @@ type JWTAuthConfig struct {
 	// 若不配置则仅当没有域名和路由配置时全局生效（兼容机制）
 	GlobalAuth *bool `json:"global_auth,omitempty"`
 
+	// 全局配置
+	//
+	// Directory 调用方目录，其中随配置下发的调用方会追加到 Consumers 中；
+	// 配置了 redis 时，认证通过后还会在 Redis 中检查调用方是否已被禁用或过期
+	Directory *consumer.Directory `json:"-"`
+
 	// 域名和路由级配置
 	//
 	// Allow 对于符合匹配条件的请求，配置允许访问的consumer名称
@@ -100,6 +108,9 @@ type Consumer struct {
 	//
 	// 默认值为 true
 	KeepToken *bool `json:"keep_token,omitempty"`
+
+	// Record 调用方在调用方目录中的记录，只有从 consumer_directory 加载的调用方不为空
+	Record *consumer.Consumer `json:"-"`
 }
 
 // ClaimsToHeader 抽取JWT的payload中指定字段，设置到指定的请求头中转发给后端
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/alibaba/higress/plugins/wasm-go/pkg/consumer"
 	"github.com/go-jose/go-jose/v3"
 	"github.com/higress-group/wasm-go/pkg/log"
 	"github.com/tidwall/gjson"
@@ -30,8 +31,18 @@ var RuleSet bool
 // 此处解析的是全局配置，域名和路由级配置由 ParseRuleConfig 负责。
 func ParseGlobalConfig(json gjson.Result, config *JWTAuthConfig, log log.Log) error {
 	RuleSet = false
+	directory := json.Get("consumer_directory")
+	if directory.Exists() {
+		d, err := consumer.ParseDirectory(consumer.KindJWT, directory)
+		if err != nil {
+			return fmt.Errorf("failed to parse consumer_directory: %v", err)
+		}
+		config.Directory = d
+	}
+
 	consumers := json.Get("consumers")
-	if !consumers.IsArray() {
+	// 配置了 consumer_directory 时可以不配置 consumers
+	if !consumers.IsArray() && (config.Directory == nil || consumers.Exists()) {
 		return fmt.Errorf("failed to parse configuration for consumers: consumers is not a array")
 	}
 
@@ -44,6 +55,17 @@ func ParseGlobalConfig(json gjson.Result, config *JWTAuthConfig, log log.Log) er
 		}
 		config.Consumers = append(config.Consumers, c)
 	}
+	if config.Directory != nil {
+		for _, record := range config.Directory.Consumers() {
+			c, err := ParseConsumer(gjson.Parse(record.Raw), consumerNames)
+			if err != nil {
+				log.Warn(err.Error())
+				continue
+			}
+			c.Record = record
+			config.Consumers = append(config.Consumers, c)
+		}
+	}
 	if len(config.Consumers) == 0 {
 		return fmt.Errorf("at least one consumer should be configured for a rule")
 	}
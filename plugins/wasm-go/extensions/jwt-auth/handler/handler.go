@@ -18,6 +18,8 @@ import (
 	"time"
 
 	cfg "github.com/alibaba/higress/plugins/wasm-go/extensions/jwt-auth/config"
+	"github.com/alibaba/higress/plugins/wasm-go/pkg/consumer"
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm"
 	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
 	"github.com/higress-group/wasm-go/pkg/log"
 	"github.com/higress-group/wasm-go/pkg/wrapper"
@@ -70,12 +72,20 @@ func OnHTTPRequestHeaders(ctx wrapper.HttpContext, config cfg.JWTAuthConfig, log
 			continue
 		}
 
+		// 从调用方目录加载的调用方，需要检查是否已被禁用或过期
+		if record := config.Consumers[i].Record; record != nil && !record.Valid(time.Now()) {
+			log.Warnf("jwt verify failed, consumer %q is expired or disabled", config.Consumers[i].Name)
+			actionMap[config.Consumers[i].Name] = deniedUnauthorizedConsumer
+			unAuthzConsumer = config.Consumers[i].Name
+			continue
+		}
+
 		// 全局生效：
 		// - global_auth == true 且 当前 domain/route 未配置该插件
 		// - global_auth 未设置 且 没有任何一个 domain/route 配置该插件
 		if (globalAuthSetTrue && noAllow) || (globalAuthNoSet && !cfg.RuleSet) {
 			log.Infof("consumer %q authenticated", config.Consumers[i].Name)
-			return authenticated(config.Consumers[i].Name)
+			return authenticate(config, config.Consumers[i], log)
 		}
 
 		// 全局生效，但当前 domain/route 配置了 allow 列表
@@ -88,7 +98,7 @@ func OnHTTPRequestHeaders(ctx wrapper.HttpContext, config cfg.JWTAuthConfig, log
 				continue
 			}
 			log.Infof("consumer %q authenticated", config.Consumers[i].Name)
-			return authenticated(config.Consumers[i].Name)
+			return authenticate(config, config.Consumers[i], log)
 		}
 
 		// 非全局生效
@@ -102,7 +112,7 @@ func OnHTTPRequestHeaders(ctx wrapper.HttpContext, config cfg.JWTAuthConfig, log
 					continue
 				}
 				log.Infof("consumer %q authenticated", config.Consumers[i].Name)
-				return authenticated(config.Consumers[i].Name)
+				return authenticate(config, config.Consumers[i], log)
 			}
 		}
 
@@ -150,6 +160,36 @@ func OnHTTPRequestHeaders(ctx wrapper.HttpContext, config cfg.JWTAuthConfig, log
 	return deniedNotAllow()
 }
 
+// authenticate 认证通过，配置了调用方目录时还需要检查调用方是否已被吊销：
+// 从 Redis 查找调用方记录，记录存在且已被禁用或过期时拒绝请求，记录不存在时视为未吊销
+func authenticate(config cfg.JWTAuthConfig, c *cfg.Consumer, log log.Log) types.Action {
+	if config.Directory == nil {
+		return authenticated(config, c.Name, nil)
+	}
+	var pending bool
+	pending = config.Directory.Lookup(c.Name, func(record *consumer.Consumer, err error) {
+		var allowed bool
+		switch {
+		case err != nil:
+			log.Errorf("failed to look up consumer directory: %v", err)
+			deniedDirectoryUnavailable()
+		case record != nil && !record.Valid(time.Now()):
+			log.Warnf("consumer %q is revoked", c.Name)
+			deniedUnauthorizedConsumer()
+		default:
+			authenticated(config, c.Name, record)
+			allowed = true
+		}
+		if allowed && pending {
+			proxywasm.ResumeHttpRequest()
+		}
+	})
+	if pending {
+		return types.HeaderStopAllIterationAndWatermark
+	}
+	return types.ActionContinue
+}
+
 func contains(str string, arr []string) bool {
 	for _, i := range arr {
 		if i == str {
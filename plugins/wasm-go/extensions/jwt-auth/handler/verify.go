@@ -20,6 +20,7 @@ import (
 	"time"
 
 	cfg "github.com/alibaba/higress/plugins/wasm-go/extensions/jwt-auth/config"
+	"github.com/alibaba/higress/plugins/wasm-go/pkg/consumer"
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm"
@@ -201,8 +202,18 @@ func deniedNotAllow() types.Action {
 	return types.ActionContinue
 }
 
-func authenticated(name string) types.Action {
-	_ = proxywasm.AddHttpRequestHeader("X-Mse-Consumer", name)
+func deniedDirectoryUnavailable() types.Action {
+	_ = proxywasm.SendHttpResponseWithDetail(503, "jwt-auth.directory_unavailable", nil,
+		[]byte("Request denied by JWT Auth check. Consumer directory is unavailable."), -1)
+	return types.ActionContinue
+}
+
+// authenticated 设置调用方身份请求头，record 不为空时同时设置调用方元数据对应的请求头
+func authenticated(config cfg.JWTAuthConfig, name string, record *consumer.Consumer) types.Action {
+	consumer.SetIdentity(name)
+	if record != nil {
+		config.Directory.SetMetadataHeaders(record)
+	}
 	return types.ActionContinue
 }
 
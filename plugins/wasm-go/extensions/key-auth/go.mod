@@ -4,7 +4,10 @@ go 1.24.1
 
 toolchain go1.24.4
 
+replace github.com/alibaba/higress/plugins/wasm-go/pkg/consumer => ../../pkg/consumer
+
 require (
+	github.com/alibaba/higress/plugins/wasm-go/pkg/consumer v0.0.0
 	github.com/higress-group/proxy-wasm-go-sdk v0.0.0-20250822030947-8345453fddd0
 	github.com/higress-group/wasm-go v1.0.2-0.20250821081215-b573359becf8
 	github.com/stretchr/testify v1.9.0
@@ -20,5 +23,6 @@ require (
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/resp v0.1.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
+	golang.org/x/crypto v0.26.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -19,7 +19,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/alibaba/higress/plugins/wasm-go/pkg/consumer"
 	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm"
 	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm/types"
 	"github.com/higress-group/wasm-go/pkg/log"
@@ -121,6 +123,13 @@ type KeyAuthConfig struct {
 	// @Scope GLOBAL
 	consumers []Consumer `yaml:"consumers"`
 
+	// @Title 调用方目录
+	// @Title en-US Consumer Directory
+	// @Description 从 Kubernetes Secret 或 Redis 加载调用方，凭证支持 SHA-256 哈希，支持元数据和过期时间。
+	// @Description en-US Consumers loaded from Kubernetes Secrets or Redis, with SHA-256 hashed credentials, metadata and expiry.
+	// @Scope GLOBAL
+	directory *consumer.Directory `yaml:"consumer_directory"`
+
 	// @Title 授权访问的调用方列表
 	// @Title en-US Allowed Consumers
 	// @Description 对于匹配上述条件的请求，允许访问的调用方列表。
@@ -171,13 +180,25 @@ func parseGlobalConfig(json gjson.Result, global *KeyAuthConfig, log log.Log) er
 		global.InHeader = in_header.Bool()
 	}
 
-	// consumers
-	consumers := json.Get("consumers")
-	if !consumers.Exists() {
-		return errors.New("consumers is required")
+	// consumer_directory
+	directory := json.Get("consumer_directory")
+	if directory.Exists() {
+		d, err := consumer.ParseDirectory(consumer.KindKey, directory)
+		if err != nil {
+			return fmt.Errorf("failed to parse consumer_directory: %v", err)
+		}
+		global.directory = d
 	}
-	if len(consumers.Array()) == 0 {
-		return errors.New("consumers cannot be empty")
+
+	// consumers，配置了 consumer_directory 时可以不配置
+	consumers := json.Get("consumers")
+	if global.directory == nil {
+		if !consumers.Exists() {
+			return errors.New("consumers is required")
+		}
+		if len(consumers.Array()) == 0 {
+			return errors.New("consumers cannot be empty")
+		}
 	}
 
 	for _, item := range consumers.Array() {
@@ -240,7 +261,6 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config KeyAuthConfig, log log
 	var (
 		noAllow            = len(config.allow) == 0 // 未配置 allow 列表，表示插件在该 domain/route 未生效
 		globalAuthNoSet    = config.globalAuth == nil
-		globalAuthSetTrue  = !globalAuthNoSet && *config.globalAuth
 		globalAuthSetFalse = !globalAuthNoSet && !*config.globalAuth
 	)
 	// 不需要认证而直接放行的情况：
@@ -286,29 +306,71 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config KeyAuthConfig, log log
 
 	// 验证token
 	name, ok := config.credential2Name[tokens[0]]
-	if !ok {
+	if ok {
+		authorizeConsumer(config, &consumer.Consumer{Name: name}, log)
+		return types.ActionContinue
+	}
+	if config.directory == nil {
 		log.Warnf("credential %q is not configured", tokens[0])
 		return deniedUnauthorizedConsumer()
 	}
 
-	proxywasm.AddHttpRequestHeader("X-Mse-Consumer", name)
+	// 在调用方目录中查找，需要访问 Redis 时暂停请求，在回调中恢复
+	var pending bool
+	pending = config.directory.Lookup(consumer.HashKey(tokens[0]), func(c *consumer.Consumer, err error) {
+		var allowed bool
+		switch {
+		case err != nil:
+			log.Errorf("failed to look up consumer directory: %v", err)
+			deniedDirectoryUnavailable()
+		case c == nil || !c.VerifySecret(tokens[0]):
+			log.Warnf("credential %q is not configured", tokens[0])
+			deniedUnauthorizedConsumer()
+		case !c.Valid(time.Now()):
+			log.Warnf("consumer %q is expired or disabled", c.Name)
+			deniedUnauthorizedConsumer()
+		default:
+			allowed = authorizeConsumer(config, c, log)
+		}
+		if allowed && pending {
+			proxywasm.ResumeHttpRequest()
+		}
+	})
+	if pending {
+		return types.HeaderStopAllIterationAndWatermark
+	}
+	return types.ActionContinue
+}
+
+// authorizeConsumer 根据 allow 列表判断已识别的调用方是否允许访问，不允许时发送拒绝响应并返回 false
+func authorizeConsumer(config KeyAuthConfig, c *consumer.Consumer, log log.Log) bool {
+	var (
+		noAllow            = len(config.allow) == 0
+		globalAuthNoSet    = config.globalAuth == nil
+		globalAuthSetTrue  = !globalAuthNoSet && *config.globalAuth
+		globalAuthSetFalse = !globalAuthNoSet && !*config.globalAuth
+		name               = c.Name
+	)
 
 	// 全局生效：
 	// - global_auth == true 且 当前 domain/route 未配置该插件
 	// - global_auth 未设置 且 没有任何一个 domain/route 配置该插件
 	if (globalAuthSetTrue && noAllow) || (globalAuthNoSet && !ruleSet) {
 		log.Infof("consumer %q authenticated", name)
-		return authenticated(name)
+		authenticated(config, c)
+		return true
 	}
 
 	// 全局生效，但当前 domain/route 配置了 allow 列表
 	if globalAuthSetTrue && !noAllow {
 		if !contains(config.allow, name) {
 			log.Warnf("consumer %q is not allowed", name)
-			return deniedUnauthorizedConsumer()
+			deniedUnauthorizedConsumer()
+			return false
 		}
 		log.Infof("consumer %q authenticated", name)
-		return authenticated(name)
+		authenticated(config, c)
+		return true
 	}
 
 	// 非全局生效
@@ -316,14 +378,16 @@ func onHttpRequestHeaders(ctx wrapper.HttpContext, config KeyAuthConfig, log log
 		if !noAllow { // 配置了 allow 列表
 			if !contains(config.allow, name) {
 				log.Warnf("consumer %q is not allowed", name)
-				return deniedUnauthorizedConsumer()
+				deniedUnauthorizedConsumer()
+				return false
 			}
 			log.Infof("consumer %q authenticated", name)
-			return authenticated(name)
+			authenticated(config, c)
+			return true
 		}
 	}
 
-	return types.ActionContinue
+	return true
 }
 
 func deniedMultiKeyAuthData() types.Action {
@@ -344,10 +408,17 @@ func deniedUnauthorizedConsumer() types.Action {
 	return types.ActionContinue
 }
 
-func authenticated(name string) types.Action {
+func deniedDirectoryUnavailable() types.Action {
+	_ = proxywasm.SendHttpResponseWithDetail(http.StatusServiceUnavailable, "key-auth.directory_unavailable", nil,
+		[]byte("Request denied by Key Auth check. Consumer directory is unavailable."), -1)
 	return types.ActionContinue
 }
 
+func authenticated(config KeyAuthConfig, c *consumer.Consumer) {
+	consumer.SetIdentity(c.Name)
+	config.directory.SetMetadataHeaders(c)
+}
+
 func contains(arr []string, item string) bool {
 	for _, i := range arr {
 		if i == item {
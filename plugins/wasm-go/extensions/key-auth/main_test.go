@@ -237,6 +237,36 @@ var invalidRuleConfig = func() json.RawMessage {
 	return data
 }()
 
+// 测试配置：调用方目录 - 引用 Secret 的调用方列表（凭证为 token1 的 SHA-256 摘要）和 Redis
+var consumerDirectoryConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"consumer_directory": map[string]interface{}{
+			"consumers": `[{"name":"consumer1","credential":"sha256:df3e6b0bb66ceaadca4f84cbc371fd66e04d20fe51fc414da8d1b84d31d178de","metadata":{"tier":"gold"}},{"name":"consumer3","credential":"token3","expires_at":"2000-01-01T00:00:00Z"}]`,
+			"metadata_headers": map[string]string{
+				"tier": "x-consumer-tier",
+			},
+			"redis": map[string]interface{}{
+				"service_name": "redis.static",
+			},
+		},
+		"keys":        []string{"x-api-key"},
+		"in_header":   true,
+		"global_auth": true,
+	})
+	return data
+}()
+
+// 测试配置：无效配置 - 调用方目录中既没有调用方也没有 Redis
+var invalidConsumerDirectoryConfig = func() json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"consumer_directory": map[string]interface{}{},
+		"keys":               []string{"x-api-key"},
+		"in_header":          true,
+		"global_auth":        true,
+	})
+	return data
+}()
+
 func TestParseGlobalConfig(t *testing.T) {
 	test.RunGoTest(t, func(t *testing.T) {
 		// 测试基本 key-auth 配置解析
@@ -355,6 +385,19 @@ func TestParseGlobalConfig(t *testing.T) {
 			defer host.Reset()
 			require.Equal(t, types.OnPluginStartStatusFailed, status)
 		})
+		// 测试调用方目录配置，可以不配置 consumers
+		t.Run("consumer directory config", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+		})
+
+		// 测试无效的调用方目录配置
+		t.Run("invalid consumer directory config", func(t *testing.T) {
+			host, status := test.NewTestHost(invalidConsumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusFailed, status)
+		})
 	})
 }
 
@@ -576,5 +619,99 @@ func TestOnHTTPRequestHeaders(t *testing.T) {
 
 			host.CompleteHttp()
 		})
+
+		// 测试调用方目录 - 哈希凭证，设置调用方身份和元数据请求头
+		t.Run("consumer directory - hashed credential", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/test"},
+				{":method", "GET"},
+				{"x-api-key", "token1"},
+				{"x-mse-consumer", "fake"},
+			})
+
+			require.Equal(t, types.ActionContinue, action)
+			require.Nil(t, host.GetLocalResponse())
+			requestHeaders := host.GetRequestHeaders()
+			require.True(t, test.HasHeaderWithValue(requestHeaders, "x-mse-consumer", "consumer1"))
+			require.False(t, test.HasHeaderWithValue(requestHeaders, "x-mse-consumer", "fake"))
+			require.True(t, test.HasHeaderWithValue(requestHeaders, "x-consumer-tier", "gold"))
+
+			host.CompleteHttp()
+		})
+
+		// 测试调用方目录 - 已过期的调用方
+		t.Run("consumer directory - expired consumer", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/test"},
+				{":method", "GET"},
+				{"x-api-key", "token3"},
+			})
+
+			require.Equal(t, types.ActionContinue, action)
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(403), localResponse.StatusCode)
+
+			host.CompleteHttp()
+		})
+
+		// 测试调用方目录 - 从 Redis 中查找调用方
+		t.Run("consumer directory - redis consumer", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/test"},
+				{":method", "GET"},
+				{"x-api-key", "token2"},
+				{"x-consumer-tier", "fake"},
+			})
+			require.Equal(t, types.HeaderStopAllIterationAndWatermark, action)
+
+			host.CallOnRedisCall(0, test.CreateRedisRespString(`{"name":"consumer2"}`))
+
+			require.Nil(t, host.GetLocalResponse())
+			require.Equal(t, types.ActionContinue, host.GetHttpStreamAction())
+			requestHeaders := host.GetRequestHeaders()
+			require.True(t, test.HasHeaderWithValue(requestHeaders, "x-mse-consumer", "consumer2"))
+			require.False(t, test.HasHeader(requestHeaders, "x-consumer-tier"))
+
+			host.CompleteHttp()
+		})
+
+		// 测试调用方目录 - Redis 中不存在的调用方
+		t.Run("consumer directory - redis consumer not found", func(t *testing.T) {
+			host, status := test.NewTestHost(consumerDirectoryConfig)
+			defer host.Reset()
+			require.Equal(t, types.OnPluginStartStatusOK, status)
+
+			action := host.CallOnHttpRequestHeaders([][2]string{
+				{":authority", "example.com"},
+				{":path", "/test"},
+				{":method", "GET"},
+				{"x-api-key", "revoked-token"},
+			})
+			require.Equal(t, types.HeaderStopAllIterationAndWatermark, action)
+
+			host.CallOnRedisCall(0, test.CreateRedisRespNull())
+
+			localResponse := host.GetLocalResponse()
+			require.NotNil(t, localResponse)
+			require.Equal(t, uint32(403), localResponse.StatusCode)
+
+			host.CompleteHttp()
+		})
 	})
 }
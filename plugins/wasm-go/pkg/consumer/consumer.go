@@ -0,0 +1,223 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consumer 实现 key-auth、basic-auth、jwt-auth 等认证插件共用的调用方目录，
+// 调用方可以来自插件配置（例如由控制面从 Kubernetes Secret 同步到配置中）或 Redis，凭证支持以哈希形式保存。
+package consumer
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const sha256Prefix = "sha256:"
+
+// Kind 调用方目录的类型，决定调用方的索引方式和凭证格式
+type Kind string
+
+const (
+	// KindKey API Key，以 API Key 的 SHA-256 摘要（十六进制小写）作为索引
+	KindKey Kind = "key"
+	// KindBasic 用户名和密码，以用户名作为索引，凭证格式为 username:password
+	KindBasic Kind = "basic"
+	// KindJWT JWT，以调用方名称作为索引
+	KindJWT Kind = "jwt"
+)
+
+// Consumer 调用方目录中的一条记录
+type Consumer struct {
+	Name      string
+	Metadata  map[string]string // 调用方的元数据，可以通过 metadata_headers 转发给后端
+	ExpiresAt int64             // 过期时间（Unix 秒），为0时永不过期
+	Disabled  bool
+	Raw       string // 记录的原始 JSON，用于解析插件特有的字段，例如 jwt-auth 的 jwks
+
+	id     string // 调用方在目录中的索引
+	secret string // 凭证或凭证的哈希，basic 类型不包含用户名，为空时不校验
+}
+
+// HashKey 返回 API Key 的 SHA-256 摘要，用作 key 类型调用方的索引
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ID 返回调用方在目录中的索引
+func (c *Consumer) ID() string {
+	return c.id
+}
+
+// Valid 判断调用方在 now 时是否未被禁用且未过期
+func (c *Consumer) Valid(now time.Time) bool {
+	if c.Disabled {
+		return false
+	}
+	return c.ExpiresAt == 0 || now.Unix() < c.ExpiresAt
+}
+
+// VerifySecret 校验请求中携带的凭证，basic 类型只需传入密码
+func (c *Consumer) VerifySecret(secret string) bool {
+	if c.secret == "" {
+		return true
+	}
+	return verifySecret(c.secret, secret)
+}
+
+// ParseConsumer 解析配置中的调用方，key 和 basic 类型必须配置 credential
+func ParseConsumer(kind Kind, json gjson.Result) (*Consumer, error) {
+	c, err := parseCommon(json)
+	if err != nil {
+		return nil, err
+	}
+	credential := json.Get("credential").String()
+	switch kind {
+	case KindKey:
+		if credential == "" {
+			return nil, fmt.Errorf("credential is required for consumer: %s", c.Name)
+		}
+		if isBcrypt(credential) {
+			return nil, fmt.Errorf("bcrypt credential is not supported for api keys, use sha256 instead, consumer: %s", c.Name)
+		}
+		if strings.HasPrefix(credential, sha256Prefix) {
+			digest, err := parseSHA256(credential)
+			if err != nil {
+				return nil, fmt.Errorf("%v, consumer: %s", err, c.Name)
+			}
+			c.id = digest
+		} else {
+			c.id = HashKey(credential)
+		}
+		c.secret = credential
+	case KindBasic:
+		username, password, err := splitBasicCredential(credential)
+		if err != nil {
+			return nil, fmt.Errorf("%v, consumer: %s", err, c.Name)
+		}
+		c.id = username
+		c.secret = password
+	case KindJWT:
+		c.id = c.Name
+	default:
+		return nil, fmt.Errorf("unknown consumer kind: %s", kind)
+	}
+	return c, nil
+}
+
+// parseRecord 解析 Redis 中的调用方记录，key 类型的记录已经以 API Key 的摘要作为索引，可以不配置 credential
+func parseRecord(kind Kind, id string, raw string) (*Consumer, error) {
+	if !gjson.Valid(raw) {
+		return nil, errors.New("invalid consumer record")
+	}
+	json := gjson.Parse(raw)
+	c, err := parseCommon(json)
+	if err != nil {
+		return nil, err
+	}
+	c.id = id
+	credential := json.Get("credential").String()
+	switch kind {
+	case KindKey:
+		c.secret = credential
+	case KindBasic:
+		username, password, err := splitBasicCredential(credential)
+		if err != nil {
+			return nil, err
+		}
+		if username != id {
+			return nil, fmt.Errorf("username of credential does not match: %s", id)
+		}
+		c.secret = password
+	}
+	return c, nil
+}
+
+func parseCommon(json gjson.Result) (*Consumer, error) {
+	c := &Consumer{
+		Name:     json.Get("name").String(),
+		Disabled: json.Get("disabled").Bool(),
+		Raw:      json.Raw,
+	}
+	if c.Name == "" {
+		return nil, errors.New("consumer name is required")
+	}
+	expiresAt := json.Get("expires_at")
+	switch expiresAt.Type {
+	case gjson.Number:
+		c.ExpiresAt = expiresAt.Int()
+	case gjson.String:
+		t, err := time.Parse(time.RFC3339, expiresAt.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at: %s, consumer: %s", expiresAt.String(), c.Name)
+		}
+		c.ExpiresAt = t.Unix()
+	}
+	metadata := json.Get("metadata").Map()
+	if len(metadata) > 0 {
+		c.Metadata = make(map[string]string, len(metadata))
+		for key, value := range metadata {
+			c.Metadata[key] = value.String()
+		}
+	}
+	return c, nil
+}
+
+func splitBasicCredential(credential string) (string, string, error) {
+	userAndPasswd := strings.SplitN(credential, ":", 2)
+	if len(userAndPasswd) != 2 || userAndPasswd[0] == "" || userAndPasswd[1] == "" {
+		return "", "", errors.New("invalid credential format, must be username:password")
+	}
+	if strings.HasPrefix(userAndPasswd[1], sha256Prefix) {
+		if _, err := parseSHA256(userAndPasswd[1]); err != nil {
+			return "", "", err
+		}
+	}
+	return userAndPasswd[0], userAndPasswd[1], nil
+}
+
+func parseSHA256(credential string) (string, error) {
+	digest := strings.ToLower(strings.TrimPrefix(credential, sha256Prefix))
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+		return "", errors.New("invalid sha256 credential")
+	}
+	return digest, nil
+}
+
+func isBcrypt(credential string) bool {
+	return strings.HasPrefix(credential, "$2a$") || strings.HasPrefix(credential, "$2b$") || strings.HasPrefix(credential, "$2y$")
+}
+
+// verifySecret 校验凭证，stored 支持 sha256:<十六进制摘要>、bcrypt 哈希和明文
+func verifySecret(stored, provided string) bool {
+	switch {
+	case strings.HasPrefix(stored, sha256Prefix):
+		expected, err := hex.DecodeString(strings.TrimPrefix(stored, sha256Prefix))
+		if err != nil {
+			return false
+		}
+		sum := sha256.Sum256([]byte(provided))
+		return subtle.ConstantTimeCompare(sum[:], expected) == 1
+	case isBcrypt(stored):
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(provided)) == nil
+	default:
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(provided)) == 1
+	}
+}
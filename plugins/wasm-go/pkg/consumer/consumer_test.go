@@ -0,0 +1,173 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// sha256("token1")
+	token1Digest = "df3e6b0bb66ceaadca4f84cbc371fd66e04d20fe51fc414da8d1b84d31d178de"
+	// bcrypt("123456")
+	bcryptPassword = "$2a$04$HhQ.8AhTyM3WdGY0V6Wg4uRwM0yTaSp/KLmFcnwN3tz66Ey2Na01a"
+)
+
+func TestParseConsumer(t *testing.T) {
+	t.Run("key with sha256 credential", func(t *testing.T) {
+		c, err := ParseConsumer(KindKey, gjson.Parse(`{"name":"consumer1","credential":"sha256:`+token1Digest+`","metadata":{"tier":"gold"}}`))
+		require.NoError(t, err)
+		require.Equal(t, "consumer1", c.Name)
+		require.Equal(t, token1Digest, c.ID())
+		require.Equal(t, "gold", c.Metadata["tier"])
+		require.True(t, c.VerifySecret("token1"))
+		require.False(t, c.VerifySecret("token2"))
+	})
+
+	t.Run("key with plaintext credential", func(t *testing.T) {
+		c, err := ParseConsumer(KindKey, gjson.Parse(`{"name":"consumer1","credential":"token1"}`))
+		require.NoError(t, err)
+		require.Equal(t, HashKey("token1"), c.ID())
+		require.True(t, c.VerifySecret("token1"))
+	})
+
+	t.Run("key with bcrypt credential", func(t *testing.T) {
+		_, err := ParseConsumer(KindKey, gjson.Parse(`{"name":"consumer1","credential":"`+bcryptPassword+`"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("key with invalid sha256 credential", func(t *testing.T) {
+		_, err := ParseConsumer(KindKey, gjson.Parse(`{"name":"consumer1","credential":"sha256:abc"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("basic with bcrypt credential", func(t *testing.T) {
+		c, err := ParseConsumer(KindBasic, gjson.Parse(`{"name":"consumer1","credential":"admin:`+bcryptPassword+`"}`))
+		require.NoError(t, err)
+		require.Equal(t, "admin", c.ID())
+		require.True(t, c.VerifySecret("123456"))
+		require.False(t, c.VerifySecret("654321"))
+	})
+
+	t.Run("basic with invalid credential", func(t *testing.T) {
+		_, err := ParseConsumer(KindBasic, gjson.Parse(`{"name":"consumer1","credential":"admin"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("jwt", func(t *testing.T) {
+		c, err := ParseConsumer(KindJWT, gjson.Parse(`{"name":"consumer1","issuer":"abcd","expires_at":"2030-01-01T00:00:00Z"}`))
+		require.NoError(t, err)
+		require.Equal(t, "consumer1", c.ID())
+		require.Equal(t, "abcd", gjson.Get(c.Raw, "issuer").String())
+		require.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), c.ExpiresAt)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := ParseConsumer(KindJWT, gjson.Parse(`{"issuer":"abcd"}`))
+		require.Error(t, err)
+	})
+}
+
+func TestConsumerValid(t *testing.T) {
+	now := time.Unix(1000, 0)
+	require.True(t, (&Consumer{}).Valid(now))
+	require.True(t, (&Consumer{ExpiresAt: 1001}).Valid(now))
+	require.False(t, (&Consumer{ExpiresAt: 1000}).Valid(now))
+	require.False(t, (&Consumer{Disabled: true}).Valid(now))
+}
+
+func TestParseRecord(t *testing.T) {
+	c, err := parseRecord(KindKey, token1Digest, `{"name":"consumer1","expires_at":1000,"disabled":true}`)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), c.ExpiresAt)
+	require.True(t, c.Disabled)
+	require.True(t, c.VerifySecret("token1"))
+
+	c, err = parseRecord(KindBasic, "admin", `{"name":"consumer1","credential":"admin:sha256:8d969eef6ecad3c29a3a629280e686cf0c3f5d5a86aff3ca12020c923adc6c92"}`)
+	require.NoError(t, err)
+	require.True(t, c.VerifySecret("123456"))
+
+	_, err = parseRecord(KindBasic, "guest", `{"name":"consumer1","credential":"admin:123456"}`)
+	require.Error(t, err)
+
+	_, err = parseRecord(KindKey, token1Digest, `not json`)
+	require.Error(t, err)
+}
+
+func TestParseDirectory(t *testing.T) {
+	t.Run("consumers from secret", func(t *testing.T) {
+		d, err := ParseDirectory(KindKey, gjson.Parse(`{"consumers":"[{\"name\":\"consumer1\",\"credential\":\"sha256:`+token1Digest+`\"}]","metadata_headers":{"tier":"x-consumer-tier"}}`))
+		require.NoError(t, err)
+		require.Len(t, d.Consumers(), 1)
+		require.Equal(t, "x-consumer-tier", d.metadataHeaders["tier"])
+
+		var found *Consumer
+		pending := d.Lookup(HashKey("token1"), func(c *Consumer, err error) {
+			require.NoError(t, err)
+			found = c
+		})
+		require.False(t, pending)
+		require.NotNil(t, found)
+		require.Equal(t, "consumer1", found.Name)
+
+		found = nil
+		pending = d.Lookup(HashKey("token2"), func(c *Consumer, err error) {
+			require.NoError(t, err)
+			found = c
+		})
+		require.False(t, pending)
+		require.Nil(t, found)
+	})
+
+	t.Run("duplicate consumer", func(t *testing.T) {
+		_, err := ParseDirectory(KindKey, gjson.Parse(`{"consumers":[{"name":"consumer1","credential":"token1"},{"name":"consumer2","credential":"sha256:`+token1Digest+`"}]}`))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid consumers", func(t *testing.T) {
+		_, err := ParseDirectory(KindKey, gjson.Parse(`{"consumers":"not json"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("no source", func(t *testing.T) {
+		_, err := ParseDirectory(KindKey, gjson.Parse(`{}`))
+		require.Error(t, err)
+	})
+}
+
+func TestDirectoryCache(t *testing.T) {
+	d := &Directory{cacheTTL: 10, cacheMaxKeys: 2, cache: make(map[string]*cacheEntry)}
+	d.store("a", &Consumer{Name: "a"}, 100)
+	d.store("b", nil, 105)
+	require.Len(t, d.cache, 2)
+
+	// a 已过期，被清理
+	d.store("c", nil, 110)
+	require.Len(t, d.cache, 2)
+	require.NotContains(t, d.cache, "a")
+
+	// 没有过期的记录时清空缓存
+	d.store("d", nil, 111)
+	require.Len(t, d.cache, 1)
+	require.Contains(t, d.cache, "d")
+
+	d.cacheTTL = 0
+	d.store("e", nil, 112)
+	require.NotContains(t, d.cache, "e")
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/higress-group/proxy-wasm-go-sdk/proxywasm"
+	"github.com/higress-group/wasm-go/pkg/log"
+	"github.com/higress-group/wasm-go/pkg/wrapper"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/resp"
+)
+
+const (
+	// IdentityHeader 认证通过后设置的调用方身份请求头，所有认证插件保持一致
+	IdentityHeader = "X-Mse-Consumer"
+
+	DefaultKeyPrefix    = "higress-consumer:"
+	DefaultCacheTTL     = 10
+	DefaultCacheMaxKeys = 10000
+)
+
+// LookupCallback 返回查找到的调用方，未找到时 consumer 为 nil，访问 Redis 失败时 err 不为 nil
+type LookupCallback func(consumer *Consumer, err error)
+
+// Directory 调用方目录
+//   - consumers 中的调用方随插件配置下发，可以通过 ${secret.namespace/name.key} 引用 Kubernetes Secret 中的 JSON 数组，
+//     Secret 变更后由控制面自动同步
+//   - 配置 redis 时，consumers 中未找到的调用方从 Redis 中查找，查找结果在本地缓存 cache_ttl 秒，
+//     修改或删除 Redis 中的记录即可吊销调用方，无需下发配置
+type Directory struct {
+	kind            Kind
+	consumers       []*Consumer
+	local           map[string]*Consumer
+	metadataHeaders map[string]string // 元数据名称 -> 请求头名称
+
+	redisClient  wrapper.RedisClient
+	keyPrefix    string
+	cacheTTL     int64
+	cacheMaxKeys int
+	cache        map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	consumer *Consumer
+	expireAt int64
+}
+
+// ParseDirectory 解析插件配置中的 consumer_directory
+func ParseDirectory(kind Kind, json gjson.Result) (*Directory, error) {
+	d := &Directory{
+		kind:  kind,
+		local: make(map[string]*Consumer),
+		cache: make(map[string]*cacheEntry),
+	}
+	consumers := json.Get("consumers")
+	if consumers.Type == gjson.String {
+		// 引用 Secret 时，调用方列表以 JSON 字符串的形式下发
+		if !gjson.Valid(consumers.String()) {
+			return nil, errors.New("consumers must be a json array")
+		}
+		consumers = gjson.Parse(consumers.String())
+	}
+	if consumers.Exists() && !consumers.IsArray() {
+		return nil, errors.New("consumers must be a json array")
+	}
+	for _, item := range consumers.Array() {
+		c, err := ParseConsumer(kind, item)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := d.local[c.id]; ok {
+			return nil, fmt.Errorf("duplicate consumer: %s", c.Name)
+		}
+		d.local[c.id] = c
+		d.consumers = append(d.consumers, c)
+	}
+	for name, header := range json.Get("metadata_headers").Map() {
+		if d.metadataHeaders == nil {
+			d.metadataHeaders = make(map[string]string)
+		}
+		d.metadataHeaders[name] = header.String()
+	}
+	redisConfig := json.Get("redis")
+	if !redisConfig.Exists() {
+		if len(d.consumers) == 0 {
+			return nil, errors.New("consumers or redis is required")
+		}
+		return d, nil
+	}
+	d.keyPrefix = DefaultKeyPrefix
+	if keyPrefix := json.Get("key_prefix"); keyPrefix.Exists() {
+		d.keyPrefix = keyPrefix.String()
+	}
+	d.cacheTTL = DefaultCacheTTL
+	if cacheTTL := json.Get("cache_ttl"); cacheTTL.Exists() {
+		d.cacheTTL = cacheTTL.Int()
+		if d.cacheTTL < 0 {
+			return nil, fmt.Errorf("invalid cache_ttl: %d", d.cacheTTL)
+		}
+	}
+	d.cacheMaxKeys = int(json.Get("cache_max_keys").Int())
+	if d.cacheMaxKeys <= 0 {
+		d.cacheMaxKeys = DefaultCacheMaxKeys
+	}
+	return d, d.initRedis(redisConfig)
+}
+
+func (d *Directory) initRedis(json gjson.Result) error {
+	serviceName := json.Get("service_name").String()
+	if serviceName == "" {
+		return errors.New("redis service name must not be empty")
+	}
+	servicePort := json.Get("service_port").Int()
+	if servicePort == 0 {
+		if strings.HasSuffix(serviceName, ".static") {
+			// use default logic port which is 80 for static service
+			servicePort = 80
+		} else {
+			servicePort = 6379
+		}
+	}
+	timeout := json.Get("timeout").Int()
+	if timeout == 0 {
+		timeout = 1000
+	}
+	d.redisClient = wrapper.NewRedisClusterClient(wrapper.FQDNCluster{
+		FQDN: serviceName,
+		Port: servicePort,
+	})
+	database := int(json.Get("database").Int())
+	return d.redisClient.Init(json.Get("username").String(), json.Get("password").String(), timeout, wrapper.WithDataBase(database))
+}
+
+// Consumers 返回随配置下发的调用方
+func (d *Directory) Consumers() []*Consumer {
+	return d.consumers
+}
+
+// Lookup 根据索引查找调用方，优先查找随配置下发的调用方，然后查找本地缓存和 Redis。
+// 同步得到结果时在返回前调用 callback 并返回 false；需要访问 Redis 时返回 true，收到响应后再调用 callback。
+func (d *Directory) Lookup(id string, callback LookupCallback) bool {
+	if c, ok := d.local[id]; ok {
+		callback(c, nil)
+		return false
+	}
+	if d.redisClient == nil {
+		callback(nil, nil)
+		return false
+	}
+	now := time.Now().Unix()
+	if entry, ok := d.cache[id]; ok && entry.expireAt > now {
+		callback(entry.consumer, nil)
+		return false
+	}
+	key := d.keyPrefix + string(d.kind) + ":" + id
+	err := d.redisClient.Get(key, func(response resp.Value) {
+		if err := response.Error(); err != nil {
+			callback(nil, fmt.Errorf("failed to get consumer from redis, key: %s, err: %v", key, err))
+			return
+		}
+		var c *Consumer
+		if !response.IsNull() {
+			record, err := parseRecord(d.kind, id, response.String())
+			if err != nil {
+				log.Warnf("ignore invalid consumer record, key: %s, err: %v", key, err)
+			} else {
+				c = record
+			}
+		}
+		d.store(id, c, time.Now().Unix())
+		callback(c, nil)
+	})
+	if err != nil {
+		callback(nil, fmt.Errorf("failed to get consumer from redis, key: %s, err: %v", key, err))
+		return false
+	}
+	return true
+}
+
+// store 缓存 Redis 的查找结果，未找到的结果同样缓存，避免无效凭证反复访问 Redis
+func (d *Directory) store(id string, c *Consumer, now int64) {
+	if d.cacheTTL == 0 {
+		return
+	}
+	if _, ok := d.cache[id]; !ok && len(d.cache) >= d.cacheMaxKeys {
+		for key, entry := range d.cache {
+			if entry.expireAt <= now {
+				delete(d.cache, key)
+			}
+		}
+		if len(d.cache) >= d.cacheMaxKeys {
+			d.cache = make(map[string]*cacheEntry)
+		}
+	}
+	d.cache[id] = &cacheEntry{consumer: c, expireAt: now + d.cacheTTL}
+}
+
+// SetMetadataHeaders 将调用方的元数据设置到 metadata_headers 配置的请求头中，调用方没有对应元数据时删除该请求头，避免被客户端伪造
+func (d *Directory) SetMetadataHeaders(c *Consumer) {
+	if d == nil {
+		return
+	}
+	for name, header := range d.metadataHeaders {
+		if value, ok := c.Metadata[name]; ok {
+			_ = proxywasm.ReplaceHttpRequestHeader(header, value)
+		} else {
+			_ = proxywasm.RemoveHttpRequestHeader(header)
+		}
+	}
+}
+
+// SetIdentity 设置调用方身份请求头，覆盖客户端传入的同名请求头
+func SetIdentity(name string) {
+	_ = proxywasm.ReplaceHttpRequestHeader(IdentityHeader, name)
+}
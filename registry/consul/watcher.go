@@ -20,6 +20,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/wrappers"
 	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/api/watch"
 	"istio.io/api/networking/v1alpha3"
@@ -29,6 +30,7 @@ import (
 	"github.com/alibaba/higress/v2/pkg/common"
 	ingress "github.com/alibaba/higress/v2/pkg/ingress/kube/common"
 	provider "github.com/alibaba/higress/v2/registry"
+	"github.com/alibaba/higress/v2/registry/mcp"
 	"github.com/alibaba/higress/v2/registry/memory"
 )
 
@@ -36,6 +38,9 @@ const (
 	ConsulHealthPassing         = "passing"
 	DefaultRefreshInterval      = time.Second * 30
 	DefaultRefreshIntervalLimit = time.Second * 10
+	// DefaultMcpToolsKeyPrefix is the kv path of the tool spec of a mcp server if mcp-server-tools-key is not set
+	DefaultMcpToolsKeyPrefix = "mcp-servers/"
+	DefaultMcpToolsKeySuffix = "/tools"
 )
 
 type watcher struct {
@@ -54,6 +59,11 @@ type watcher struct {
 	isStop               bool
 	updateCacheWhenEmpty bool
 	authOption           provider.AuthOption
+	namespace            string
+	clusterId            string
+	mcpPublisher         *mcp.Publisher
+	mcpServers           map[string]*mcp.Server
+	mcpMutex             *sync.Mutex
 }
 
 type WatcherOption func(w *watcher)
@@ -109,6 +119,48 @@ func WithRefreshInterval(refreshInterval int64) WatcherOption {
 	}
 }
 
+func WithEnableMcpServer(enable *wrappers.BoolValue) WatcherOption {
+	return func(w *watcher) {
+		w.EnableMCPServer = enable
+	}
+}
+
+func WithMcpExportDomains(exportDomains []string) WatcherOption {
+	return func(w *watcher) {
+		w.McpServerExportDomains = exportDomains
+	}
+}
+
+func WithMcpBaseUrl(url string) WatcherOption {
+	return func(w *watcher) {
+		w.McpServerBaseUrl = url
+	}
+}
+
+func WithEnableScopeMcpServers(enable *wrappers.BoolValue) WatcherOption {
+	return func(w *watcher) {
+		w.EnableScopeMcpServers = enable
+	}
+}
+
+func WithAllowMcpServers(allowMcpServers []string) WatcherOption {
+	return func(w *watcher) {
+		w.AllowMcpServers = allowMcpServers
+	}
+}
+
+func WithNamespace(ns string) WatcherOption {
+	return func(w *watcher) {
+		w.namespace = ns
+	}
+}
+
+func WithClusterId(id string) WatcherOption {
+	return func(w *watcher) {
+		w.clusterId = id
+	}
+}
+
 func NewWatcher(cache memory.Cache, opts ...WatcherOption) (provider.Watcher, error) {
 	w := &watcher{
 		WatchingServices: make(map[string]bool),
@@ -118,6 +170,8 @@ func NewWatcher(cache memory.Cache, opts ...WatcherOption) (provider.Watcher, er
 		cache:            cache,
 		mutex:            &sync.Mutex{},
 		stop:             make(chan struct{}),
+		mcpServers:       make(map[string]*mcp.Server),
+		mcpMutex:         &sync.Mutex{},
 	}
 
 	// Set default
@@ -140,6 +194,9 @@ func NewWatcher(cache memory.Cache, opts ...WatcherOption) (provider.Watcher, er
 	}
 	w.consulClient = client
 	w.consulCatalog = client.Catalog()
+	if w.EnableMCPServer.GetValue() {
+		w.mcpPublisher = mcp.NewPublisher(cache, &w.RegistryConfig, w.namespace, w.clusterId)
+	}
 	return w, nil
 }
 
@@ -173,6 +230,7 @@ func (w *watcher) fetchAllServices() error {
 			err := w.unsubscribe(serviceName)
 			if err == nil {
 				delete(w.WatchingServices, serviceName)
+				w.removeMcpServer(serviceName)
 			}
 		}
 	}
@@ -189,6 +247,7 @@ func (w *watcher) fetchAllServices() error {
 		}
 	}
 
+	w.refreshMcpTools()
 	return nil
 }
 
@@ -240,6 +299,10 @@ func (w *watcher) Stop() {
 		host := strings.ReplaceAll(suffix, common.Underscore, common.Hyphen)
 		w.cache.DeleteServiceWrapper(host)
 	}
+	if w.mcpPublisher != nil {
+		w.mcpPublisher.RemoveAll()
+		w.UpdateService()
+	}
 	w.isStop = true
 	close(w.stop)
 	w.Ready(false)
@@ -306,8 +369,130 @@ func (w *watcher) getSubscribeCallback(serviceName string) func(idx uint64, data
 				log.Infof("consul serviceEntry %s is nil", host)
 				// w.cache.DeleteServiceWrapper(host)
 			}
+			if w.mcpPublisher != nil {
+				w.publishMcpServer(serviceName, host, services)
+			}
+		}
+	}
+}
+
+// publishMcpServer exports the service as a mcp server if it's announced by the service meta
+// or tags in key=value format, the tool spec of a http/https mcp server is read from consul kv.
+func (w *watcher) publishMcpServer(serviceName, host string, services []*consulapi.ServiceEntry) {
+	key := mcp.ServerKey(w.Type, w.Name, serviceName)
+	healthy := false
+	var server *mcp.Server
+	for _, service := range services {
+		if service.Checks.AggregatedStatus() != ConsulHealthPassing {
+			continue
+		}
+		healthy = true
+		if server = mcp.ParseServer(key, host, serviceName, mcpMetadata(service.Service)); server != nil {
+			break
+		}
+	}
+	// keep the mcp server like the service entry if there is no healthy instance
+	if !healthy {
+		return
+	}
+	if server == nil {
+		w.removeMcpServer(serviceName)
+		return
+	}
+	if mcp.IsRestProtocol(server.Protocol) && server.Tools == "" {
+		if server.ToolsKey == "" {
+			server.ToolsKey = DefaultMcpToolsKeyPrefix + server.Name + DefaultMcpToolsKeySuffix
+		}
+		tools, err := w.getMcpTools(server.ToolsKey)
+		if err != nil {
+			log.Errorf("consul get mcp server tools error:%v, key:%s", err, server.ToolsKey)
+			// keep the last published tools
+			w.mcpMutex.Lock()
+			if last, ok := w.mcpServers[serviceName]; ok && last.ToolsKey == server.ToolsKey {
+				tools = last.Tools
+			}
+			w.mcpMutex.Unlock()
 		}
+		server.Tools = tools
+	}
+
+	w.mcpMutex.Lock()
+	w.mcpServers[serviceName] = server
+	w.mcpMutex.Unlock()
+	w.mcpPublisher.Publish(server)
+}
+
+func (w *watcher) removeMcpServer(serviceName string) {
+	if w.mcpPublisher == nil {
+		return
+	}
+	w.mcpMutex.Lock()
+	delete(w.mcpServers, serviceName)
+	w.mcpMutex.Unlock()
+	if w.mcpPublisher.Remove(mcp.ServerKey(w.Type, w.Name, serviceName)) {
+		w.UpdateService()
+	}
+}
+
+// refreshMcpTools reloads the tool specs in consul kv, which are not watched by the service plans.
+func (w *watcher) refreshMcpTools() {
+	if w.mcpPublisher == nil {
+		return
+	}
+	w.mcpMutex.Lock()
+	servers := make([]*mcp.Server, 0, len(w.mcpServers))
+	for _, server := range w.mcpServers {
+		if server.ToolsKey != "" {
+			s := *server
+			servers = append(servers, &s)
+		}
+	}
+	w.mcpMutex.Unlock()
+
+	changed := false
+	for _, server := range servers {
+		tools, err := w.getMcpTools(server.ToolsKey)
+		if err != nil {
+			log.Errorf("consul get mcp server tools error:%v, key:%s", err, server.ToolsKey)
+			continue
+		}
+		server.Tools = tools
+		if w.mcpPublisher.Publish(server) {
+			changed = true
+		}
+	}
+	if changed {
+		w.UpdateService()
+	}
+}
+
+func (w *watcher) getMcpTools(key string) (string, error) {
+	q := &consulapi.QueryOptions{}
+	q.Datacenter = w.ConsulDatacenter
+	q.Token = w.authOption.ConsulToken
+	pair, _, err := w.consulClient.KV().Get(key, q)
+	if err != nil {
+		return "", err
+	}
+	if pair == nil {
+		log.Warnf("consul mcp server tools key %s not found", key)
+		return "", nil
+	}
+	return string(pair.Value), nil
+}
+
+// mcpMetadata merges the mcp server settings in the service tags and meta, the meta takes precedence.
+func mcpMetadata(service *consulapi.AgentService) map[string]string {
+	metadata := make(map[string]string)
+	for _, tag := range service.Tags {
+		if k, v, ok := strings.Cut(tag, "="); ok && strings.HasPrefix(k, "mcp-server-") {
+			metadata[k] = v
+		}
+	}
+	for k, v := range service.Meta {
+		metadata[k] = v
 	}
+	return metadata
 }
 
 func (w *watcher) generateServiceEntry(host string, services []*consulapi.ServiceEntry) *v1alpha3.ServiceEntry {
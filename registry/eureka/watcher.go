@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/hudl/fargo"
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/pkg/log"
@@ -30,6 +31,7 @@ import (
 	ingress "github.com/alibaba/higress/v2/pkg/ingress/kube/common"
 	provider "github.com/alibaba/higress/v2/registry"
 	. "github.com/alibaba/higress/v2/registry/eureka/client"
+	"github.com/alibaba/higress/v2/registry/mcp"
 	"github.com/alibaba/higress/v2/registry/memory"
 )
 
@@ -54,6 +56,10 @@ type watcher struct {
 	eurekaClient              EurekaHttpClient
 	fullRefreshIntervalLimit  time.Duration
 	deltaRefreshIntervalLimit time.Duration
+
+	namespace    string
+	clusterId    string
+	mcpPublisher *mcp.Publisher
 }
 
 type WatcherOption func(w *watcher)
@@ -77,6 +83,9 @@ func NewWatcher(cache memory.Cache, opts ...WatcherOption) (provider.Watcher, er
 	cfg := NewDefaultConfig()
 	cfg.BaseUrl = net.JoinHostPort(w.Domain, strconv.FormatUint(uint64(w.Port), 10))
 	w.eurekaClient = NewEurekaHttpClient(cfg)
+	if w.EnableMCPServer.GetValue() {
+		w.mcpPublisher = mcp.NewPublisher(cache, &w.RegistryConfig, w.namespace, w.clusterId)
+	}
 
 	return w, nil
 }
@@ -126,6 +135,48 @@ func WithUpdateCacheWhenEmpty(enable bool) WatcherOption {
 	}
 }
 
+func WithEnableMcpServer(enable *wrappers.BoolValue) WatcherOption {
+	return func(w *watcher) {
+		w.EnableMCPServer = enable
+	}
+}
+
+func WithMcpExportDomains(exportDomains []string) WatcherOption {
+	return func(w *watcher) {
+		w.McpServerExportDomains = exportDomains
+	}
+}
+
+func WithMcpBaseUrl(url string) WatcherOption {
+	return func(w *watcher) {
+		w.McpServerBaseUrl = url
+	}
+}
+
+func WithEnableScopeMcpServers(enable *wrappers.BoolValue) WatcherOption {
+	return func(w *watcher) {
+		w.EnableScopeMcpServers = enable
+	}
+}
+
+func WithAllowMcpServers(allowMcpServers []string) WatcherOption {
+	return func(w *watcher) {
+		w.AllowMcpServers = allowMcpServers
+	}
+}
+
+func WithNamespace(ns string) WatcherOption {
+	return func(w *watcher) {
+		w.namespace = ns
+	}
+}
+
+func WithClusterId(id string) WatcherOption {
+	return func(w *watcher) {
+		w.clusterId = id
+	}
+}
+
 func (w *watcher) Run() {
 	ticker := time.NewTicker(w.fullRefreshIntervalLimit)
 	defer ticker.Stop()
@@ -220,11 +271,13 @@ func (w *watcher) subscribe(service *fargo.Application) error {
 				RegistryType: w.Type,
 				RegistryName: w.Name,
 			})
+			w.publishMcpServer(service)
 			return nil
 		}
 
 		if w.updateCacheWhenEmpty {
 			w.cache.DeleteServiceWrapper(makeHost(service.Name))
+			w.removeMcpServer(service.Name)
 		}
 
 		return nil
@@ -241,11 +294,39 @@ func (w *watcher) subscribe(service *fargo.Application) error {
 func (w *watcher) unsubscribe(serviceName string) error {
 	w.WatchingServices[serviceName].Stop()
 	delete(w.WatchingServices, serviceName)
+	w.removeMcpServer(serviceName)
 	w.UpdateService()
 
 	return nil
 }
 
+// publishMcpServer exports the application as a mcp server if it's announced by the instance metadata,
+// the tool spec of a http/https mcp server is inlined in the metadata too.
+func (w *watcher) publishMcpServer(app *fargo.Application) {
+	if w.mcpPublisher == nil {
+		return
+	}
+	key := mcp.ServerKey(w.Type, w.Name, app.Name)
+	var server *mcp.Server
+	for _, instance := range app.Instances {
+		if server = mcp.ParseServer(key, makeHost(app.Name), app.Name, convertMap(instance.Metadata.GetMap())); server != nil {
+			break
+		}
+	}
+	if server == nil {
+		w.mcpPublisher.Remove(key)
+		return
+	}
+	w.mcpPublisher.Publish(server)
+}
+
+func (w *watcher) removeMcpServer(serviceName string) {
+	if w.mcpPublisher == nil {
+		return
+	}
+	w.mcpPublisher.Remove(mcp.ServerKey(w.Type, w.Name, serviceName))
+}
+
 func makeHost(serviceName string) string {
 	return serviceName + common.DotSeparator + suffix
 }
@@ -0,0 +1,399 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/api/networking/v1alpha3"
+	"istio.io/pkg/log"
+
+	apiv1 "github.com/alibaba/higress/v2/api/networking/v1"
+	"github.com/alibaba/higress/v2/pkg/common"
+)
+
+const (
+	TypeHTTP = "http"
+	TypeTCP  = "tcp"
+
+	DefaultPath               = "/"
+	DefaultInterval           = 5
+	DefaultTimeout            = 3
+	DefaultUnhealthyThreshold = 3
+	DefaultHealthyThreshold   = 1
+	DefaultExpectedStatus     = http.StatusOK
+
+	// SourceController marks the status as probed by the controller, not by the gateway
+	SourceController = "controller"
+)
+
+// Normalize returns a copy of the health check config with the defaults filled.
+func Normalize(config *apiv1.RegistryConfig_HealthCheck) *apiv1.RegistryConfig_HealthCheck {
+	normalized := config.DeepCopy()
+	normalized.Type = strings.ToLower(normalized.Type)
+	if normalized.Type == "" {
+		normalized.Type = TypeTCP
+	}
+	if normalized.Type == TypeHTTP {
+		if normalized.Path == "" {
+			normalized.Path = DefaultPath
+		}
+		if len(normalized.ExpectedStatuses) == 0 {
+			normalized.ExpectedStatuses = []uint32{DefaultExpectedStatus}
+		}
+	}
+	if normalized.Interval == 0 {
+		normalized.Interval = DefaultInterval
+	}
+	if normalized.Timeout == 0 {
+		normalized.Timeout = DefaultTimeout
+	}
+	if normalized.UnhealthyThreshold == 0 {
+		normalized.UnhealthyThreshold = DefaultUnhealthyThreshold
+	}
+	if normalized.HealthyThreshold == 0 {
+		normalized.HealthyThreshold = DefaultHealthyThreshold
+	}
+	return normalized
+}
+
+// Validate checks the health check config of a registry.
+func Validate(config *apiv1.RegistryConfig_HealthCheck) error {
+	switch strings.ToLower(config.Type) {
+	case "", TypeTCP:
+		if config.Path != "" || len(config.ExpectedStatuses) != 0 {
+			return fmt.Errorf("path and expectedStatuses are only supported by the http health check")
+		}
+	case TypeHTTP:
+		if config.Path != "" && !strings.HasPrefix(config.Path, "/") {
+			return fmt.Errorf("invalid health check path %s, it must start with /", config.Path)
+		}
+		for _, status := range config.ExpectedStatuses {
+			if status < 100 || status > 599 {
+				return fmt.Errorf("invalid health check expected status %d", status)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported health check type %s, only http and tcp are supported", config.Type)
+	}
+	return nil
+}
+
+// ClusterHealthChecks returns the envoy cluster health_checks of the normalized config, used to patch the
+// clusters of the services discovered by the registry.
+func ClusterHealthChecks(config *apiv1.RegistryConfig_HealthCheck) []map[string]interface{} {
+	healthCheck := map[string]interface{}{
+		"timeout":             fmt.Sprintf("%ds", config.Timeout),
+		"interval":            fmt.Sprintf("%ds", config.Interval),
+		"unhealthy_threshold": config.UnhealthyThreshold,
+		"healthy_threshold":   config.HealthyThreshold,
+	}
+	if config.Type == TypeHTTP {
+		var expectedStatuses []map[string]interface{}
+		for _, status := range config.ExpectedStatuses {
+			// The range end is exclusive
+			expectedStatuses = append(expectedStatuses, map[string]interface{}{
+				"start": status,
+				"end":   status + 1,
+			})
+		}
+		healthCheck["http_health_check"] = map[string]interface{}{
+			"path":              config.Path,
+			"expected_statuses": expectedStatuses,
+		}
+	} else {
+		healthCheck["tcp_health_check"] = map[string]interface{}{}
+	}
+	return []map[string]interface{}{healthCheck}
+}
+
+// Target is an endpoint probed by the checker.
+type Target struct {
+	Service string
+	Address string
+	Port    uint32
+	TLS     bool
+}
+
+func (t Target) key() string {
+	return t.Service + "/" + net.JoinHostPort(t.Address, strconv.FormatUint(uint64(t.Port), 10))
+}
+
+// TargetsOf returns the endpoints of the service entries discovered by a registry.
+func TargetsOf(serviceEntries []*v1alpha3.ServiceEntry) []Target {
+	var targets []Target
+	for _, se := range serviceEntries {
+		if se == nil || len(se.Hosts) == 0 || len(se.Ports) == 0 {
+			continue
+		}
+		port := se.Ports[0]
+		for _, endpoint := range se.Endpoints {
+			if endpoint.Address == "" || strings.HasPrefix(endpoint.Address, "unix://") {
+				continue
+			}
+			target := Target{
+				Service: se.Hosts[0],
+				Address: endpoint.Address,
+				Port:    port.Number,
+				TLS:     common.Protocol(port.Protocol).IsHTTPS(),
+			}
+			if endpointPort := endpoint.Ports[port.Name]; endpointPort != 0 {
+				target.Port = endpointPort
+			}
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+type EndpointStatus struct {
+	Service       string    `json:"service"`
+	Address       string    `json:"address"`
+	Port          uint32    `json:"port"`
+	Healthy       bool      `json:"healthy"`
+	LastError     string    `json:"lastError,omitempty"`
+	LastCheckTime time.Time `json:"lastCheckTime"`
+}
+
+// Status is the endpoint reachability seen from the controller. The gateway probes the same endpoints with the
+// cluster health checks patched by the controller and decides the routing on its own results, which may differ
+// when the network path of the controller and the gateway differ.
+type Status struct {
+	// Source is always controller, so the consumers of /registry/watcherStatus can tell it apart from the
+	// health status of the gateway clusters
+	Source             string           `json:"source"`
+	Type               string           `json:"type"`
+	HealthyEndpoints   int              `json:"healthyEndpoints"`
+	UnhealthyEndpoints int              `json:"unhealthyEndpoints"`
+	Endpoints          []EndpointStatus `json:"endpoints"`
+}
+
+type endpointState struct {
+	target    Target
+	checked   bool
+	healthy   bool
+	successes uint32
+	failures  uint32
+	lastError string
+	lastCheck time.Time
+}
+
+// Checker probes the endpoints of a registry periodically from the controller. The endpoint health is decided by
+// the first check, then changed after the configured number of consecutive failed or successful checks, in the
+// same way as the gateway does with the cluster health checks. The results are only reported, they do not change
+// the endpoints pushed to the gateway.
+type Checker struct {
+	config  *apiv1.RegistryConfig_HealthCheck
+	targets func() []Target
+	probe   func(ctx context.Context, target Target) error
+
+	mutex     sync.RWMutex
+	endpoints map[string]*endpointState
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewChecker creates a checker of the normalized config, targets is called before each round of checks to get
+// the endpoints to probe.
+func NewChecker(config *apiv1.RegistryConfig_HealthCheck, targets func() []Target) *Checker {
+	c := &Checker{
+		config:    config,
+		targets:   targets,
+		endpoints: make(map[string]*endpointState),
+		stop:      make(chan struct{}),
+	}
+	if config.Type == TypeHTTP {
+		c.probe = c.probeHTTP
+	} else {
+		c.probe = c.probeTCP
+	}
+	return c
+}
+
+func (c *Checker) Run() {
+	ticker := time.NewTicker(time.Duration(c.config.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		c.checkOnce()
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *Checker) Status() *Status {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	status := &Status{
+		Source:    SourceController,
+		Type:      c.config.Type,
+		Endpoints: make([]EndpointStatus, 0, len(c.endpoints)),
+	}
+	for _, state := range c.endpoints {
+		if !state.checked {
+			continue
+		}
+		if state.healthy {
+			status.HealthyEndpoints++
+		} else {
+			status.UnhealthyEndpoints++
+		}
+		status.Endpoints = append(status.Endpoints, EndpointStatus{
+			Service:       state.target.Service,
+			Address:       state.target.Address,
+			Port:          state.target.Port,
+			Healthy:       state.healthy,
+			LastError:     state.lastError,
+			LastCheckTime: state.lastCheck,
+		})
+	}
+	sort.Slice(status.Endpoints, func(i, j int) bool {
+		if status.Endpoints[i].Service != status.Endpoints[j].Service {
+			return status.Endpoints[i].Service < status.Endpoints[j].Service
+		}
+		if status.Endpoints[i].Address != status.Endpoints[j].Address {
+			return status.Endpoints[i].Address < status.Endpoints[j].Address
+		}
+		return status.Endpoints[i].Port < status.Endpoints[j].Port
+	})
+	return status
+}
+
+func (c *Checker) checkOnce() {
+	targets := c.targets()
+	current := make(map[string]Target, len(targets))
+	for _, target := range targets {
+		current[target.key()] = target
+	}
+
+	results := make(map[string]error, len(current))
+	var resultsMutex sync.Mutex
+	var wg sync.WaitGroup
+	for key, target := range current {
+		wg.Add(1)
+		go func(key string, target Target) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.config.Timeout)*time.Second)
+			defer cancel()
+			err := c.probe(ctx, target)
+			resultsMutex.Lock()
+			results[key] = err
+			resultsMutex.Unlock()
+		}(key, target)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key := range c.endpoints {
+		if _, exist := current[key]; !exist {
+			delete(c.endpoints, key)
+		}
+	}
+	for key, err := range results {
+		state, exist := c.endpoints[key]
+		if !exist {
+			state = &endpointState{target: current[key]}
+			c.endpoints[key] = state
+		}
+		c.update(state, err, now)
+	}
+}
+
+func (c *Checker) update(state *endpointState, err error, now time.Time) {
+	state.lastCheck = now
+	if err == nil {
+		state.lastError = ""
+		state.failures = 0
+		state.successes++
+		if !state.checked || (!state.healthy && state.successes >= c.config.HealthyThreshold) {
+			if state.checked {
+				log.Infof("endpoint %s of service %s becomes healthy", state.target.Address, state.target.Service)
+			}
+			state.healthy = true
+		}
+	} else {
+		state.lastError = err.Error()
+		state.successes = 0
+		state.failures++
+		if !state.checked || (state.healthy && state.failures >= c.config.UnhealthyThreshold) {
+			log.Warnf("endpoint %s of service %s becomes unhealthy, err:%v", state.target.Address, state.target.Service, err)
+			state.healthy = false
+		}
+	}
+	state.checked = true
+}
+
+func (c *Checker) probeTCP(ctx context.Context, target Target) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.Address, strconv.FormatUint(uint64(target.Port), 10)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (c *Checker) probeHTTP(ctx context.Context, target Target) error {
+	scheme := "http"
+	if target.TLS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(target.Address, strconv.FormatUint(uint64(target.Port), 10)), c.config.Path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			// The gateway doesn't verify the certificates of the registry services either
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	for _, status := range c.config.ExpectedStatuses {
+		if uint32(resp.StatusCode) == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
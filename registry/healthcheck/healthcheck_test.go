@@ -0,0 +1,203 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"istio.io/api/networking/v1alpha3"
+
+	apiv1 "github.com/alibaba/higress/v2/api/networking/v1"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.True(t, proto.Equal(&apiv1.RegistryConfig_HealthCheck{
+		Type:               TypeTCP,
+		Interval:           DefaultInterval,
+		Timeout:            DefaultTimeout,
+		UnhealthyThreshold: DefaultUnhealthyThreshold,
+		HealthyThreshold:   DefaultHealthyThreshold,
+	}, Normalize(&apiv1.RegistryConfig_HealthCheck{})))
+
+	assert.True(t, proto.Equal(&apiv1.RegistryConfig_HealthCheck{
+		Type:               TypeHTTP,
+		Path:               DefaultPath,
+		Interval:           10,
+		Timeout:            DefaultTimeout,
+		UnhealthyThreshold: DefaultUnhealthyThreshold,
+		HealthyThreshold:   2,
+		ExpectedStatuses:   []uint32{DefaultExpectedStatus},
+	}, Normalize(&apiv1.RegistryConfig_HealthCheck{Type: "HTTP", Interval: 10, HealthyThreshold: 2})))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *apiv1.RegistryConfig_HealthCheck
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			config: &apiv1.RegistryConfig_HealthCheck{},
+		},
+		{
+			name:   "http",
+			config: &apiv1.RegistryConfig_HealthCheck{Type: TypeHTTP, Path: "/health", ExpectedStatuses: []uint32{200, 204}},
+		},
+		{
+			name:    "unknown type",
+			config:  &apiv1.RegistryConfig_HealthCheck{Type: "grpc"},
+			wantErr: true,
+		},
+		{
+			name:    "tcp with path",
+			config:  &apiv1.RegistryConfig_HealthCheck{Type: TypeTCP, Path: "/health"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid path",
+			config:  &apiv1.RegistryConfig_HealthCheck{Type: TypeHTTP, Path: "health"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid status",
+			config:  &apiv1.RegistryConfig_HealthCheck{Type: TypeHTTP, ExpectedStatuses: []uint32{600}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, Validate(tt.config) != nil)
+		})
+	}
+}
+
+func TestClusterHealthChecks(t *testing.T) {
+	config := Normalize(&apiv1.RegistryConfig_HealthCheck{Type: TypeHTTP, Path: "/health"})
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"timeout":             "3s",
+			"interval":            "5s",
+			"unhealthy_threshold": uint32(3),
+			"healthy_threshold":   uint32(1),
+			"http_health_check": map[string]interface{}{
+				"path": "/health",
+				"expected_statuses": []map[string]interface{}{
+					{"start": uint32(200), "end": uint32(201)},
+				},
+			},
+		},
+	}, ClusterHealthChecks(config))
+
+	config = Normalize(&apiv1.RegistryConfig_HealthCheck{})
+	assert.Equal(t, map[string]interface{}{}, ClusterHealthChecks(config)[0]["tcp_health_check"])
+}
+
+func TestTargetsOf(t *testing.T) {
+	targets := TargetsOf([]*v1alpha3.ServiceEntry{
+		{
+			Hosts: []string{"legacy.static"},
+			Ports: []*v1alpha3.ServicePort{{Number: 80, Name: "HTTP", Protocol: "HTTP"}},
+			Endpoints: []*v1alpha3.WorkloadEntry{
+				{Address: "1.1.1.1", Ports: map[string]uint32{"HTTP": 8080}},
+				{Address: "2.2.2.2"},
+			},
+		},
+		{
+			Hosts:     []string{"foo.dns"},
+			Ports:     []*v1alpha3.ServicePort{{Number: 443, Name: "HTTPS", Protocol: "HTTPS"}},
+			Endpoints: []*v1alpha3.WorkloadEntry{{Address: "foo.com"}},
+		},
+		{},
+	})
+	assert.Equal(t, []Target{
+		{Service: "legacy.static", Address: "1.1.1.1", Port: 8080},
+		{Service: "legacy.static", Address: "2.2.2.2", Port: 80},
+		{Service: "foo.dns", Address: "foo.com", Port: 443, TLS: true},
+	}, targets)
+}
+
+func TestCheckerThresholds(t *testing.T) {
+	target := Target{Service: "legacy.static", Address: "1.1.1.1", Port: 80}
+	var targets []Target
+	var probeErr error
+	checker := NewChecker(Normalize(&apiv1.RegistryConfig_HealthCheck{UnhealthyThreshold: 2, HealthyThreshold: 2}), func() []Target {
+		return targets
+	})
+	checker.probe = func(context.Context, Target) error {
+		return probeErr
+	}
+	healthy := func() bool {
+		status := checker.Status()
+		assert.Len(t, status.Endpoints, 1)
+		return status.Endpoints[0].Healthy
+	}
+
+	targets = []Target{target}
+	checker.checkOnce()
+	assert.True(t, healthy())
+
+	probeErr = errors.New("connection refused")
+	checker.checkOnce()
+	assert.True(t, healthy())
+	checker.checkOnce()
+	assert.False(t, healthy())
+	assert.Equal(t, "connection refused", checker.Status().Endpoints[0].LastError)
+	assert.Equal(t, 1, checker.Status().UnhealthyEndpoints)
+	assert.Equal(t, SourceController, checker.Status().Source)
+
+	probeErr = nil
+	checker.checkOnce()
+	assert.False(t, healthy())
+	checker.checkOnce()
+	assert.True(t, healthy())
+
+	targets = nil
+	checker.checkOnce()
+	assert.Empty(t, checker.Status().Endpoints)
+}
+
+func TestCheckerProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.ParseUint(portStr, 10, 32)
+	target := Target{Service: "legacy.static", Address: host, Port: uint32(port)}
+
+	checker := NewChecker(Normalize(&apiv1.RegistryConfig_HealthCheck{Type: TypeHTTP, Path: "/health", ExpectedStatuses: []uint32{204}}), nil)
+	assert.NoError(t, checker.probe(context.Background(), target))
+
+	checker = NewChecker(Normalize(&apiv1.RegistryConfig_HealthCheck{Type: TypeHTTP}), nil)
+	assert.EqualError(t, checker.probe(context.Background(), target), "unexpected status code 503")
+
+	checker = NewChecker(Normalize(&apiv1.RegistryConfig_HealthCheck{}), nil)
+	assert.NoError(t, checker.probe(context.Background(), target))
+	server.Close()
+	assert.Error(t, checker.probe(context.Background(), target))
+}
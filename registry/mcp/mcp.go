@@ -0,0 +1,434 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/util/sets"
+
+	ingress "github.com/alibaba/higress/v2/pkg/ingress/kube/common"
+	higressmcpserver "github.com/alibaba/higress/v2/pkg/ingress/kube/mcpserver"
+	provider "github.com/alibaba/higress/v2/registry"
+)
+
+var (
+	SupportedProtocols = map[string]bool{
+		provider.HttpProtocol:          true,
+		provider.HttpsProtocol:         true,
+		provider.McpSSEProtocol:        true,
+		provider.McpStreamableProtocol: true,
+	}
+	protocolUpstreamTypeMapping = map[string]string{
+		provider.HttpProtocol:          higressmcpserver.UpstreamTypeRest,
+		provider.HttpsProtocol:         higressmcpserver.UpstreamTypeRest,
+		provider.McpSSEProtocol:        higressmcpserver.UpstreamTypeSSE,
+		provider.McpStreamableProtocol: higressmcpserver.UpstreamTypeStreamable,
+	}
+	routeRewriteProtocols = map[string]bool{
+		provider.McpSSEProtocol:        true,
+		provider.McpStreamableProtocol: true,
+	}
+	mcpServerRewriteProtocols = map[string]bool{
+		provider.McpSSEProtocol: true,
+	}
+)
+
+var mcpLog = log.RegisterScope("McpServer", "Registry Mcp Server process.")
+
+// ExportOptions describes how the mcp servers of a registry are exposed on the gateway.
+type ExportOptions struct {
+	ExportDomains []string
+	BaseUrl       string
+	Namespace     string
+	ClusterId     string
+}
+
+func (o *ExportOptions) domains() []string {
+	// if there is no export domain, use default *
+	if len(o.ExportDomains) == 0 {
+		return []string{"*"}
+	}
+	return o.ExportDomains
+}
+
+// IsRestProtocol reports whether the mcp server is a plain http service whose tools are
+// described by a tool spec and served by the mcp-server wasm plugin.
+func IsRestProtocol(protocol string) bool {
+	return protocol == provider.HttpProtocol || protocol == provider.HttpsProtocol
+}
+
+// BuildVirtualService builds the route of the mcp server, the path format is /{base-path}/{mcp-server-name}.
+func BuildVirtualService(opts ExportOptions, name, routeName, serverName, protocol, serviceHost string, se *v1alpha3.ServiceEntry) *config.Config {
+	hosts := opts.domains()
+	// find gateway resources by host
+	var gateways []string
+	for _, host := range hosts {
+		cleanHost := ingress.CleanHost(host)
+		// namespace/name, name format: (istio cluster id)-host
+		gateways = append(gateways, opts.Namespace+"/"+
+			ingress.CreateConvertedName(opts.ClusterId, cleanHost),
+			ingress.CreateConvertedName(constants.IstioIngressGatewayName, cleanHost))
+	}
+	mergePath := "/" + serverName
+	if opts.BaseUrl != "" && opts.BaseUrl != "/" {
+		mergePath = strings.TrimSuffix(opts.BaseUrl, "/") + mergePath
+	}
+
+	vs := &v1alpha3.VirtualService{
+		Hosts:    hosts,
+		Gateways: gateways,
+		Http: []*v1alpha3.HTTPRoute{{
+			Name: routeName,
+			// We need to use both exact and prefix matches here to ensure a proper matching.
+			// Also otherwise, prefix rewrite won't work correctly for Streamable HTTP transport, either.
+			// Example:
+			// Assume mergePath=/mcp/test prefixRewrite=/ requestPath=/mcp/test/abc
+			// If we only use prefix match, the rewritten path will be //abc.
+			Match: []*v1alpha3.HTTPMatchRequest{
+				{
+					Uri: &v1alpha3.StringMatch{
+						MatchType: &v1alpha3.StringMatch_Exact{
+							Exact: mergePath,
+						},
+					},
+				},
+				{
+					Uri: &v1alpha3.StringMatch{
+						MatchType: &v1alpha3.StringMatch_Prefix{
+							Prefix: mergePath + "/",
+						},
+					},
+				},
+			},
+			Route: []*v1alpha3.HTTPRouteDestination{{
+				Destination: &v1alpha3.Destination{
+					Host: serviceHost,
+				},
+			}},
+		}},
+	}
+
+	// we should rewrite path for sse and streamble
+	if routeRewriteProtocols[protocol] {
+		vs.Http[0].Rewrite = &v1alpha3.HTTPRewrite{
+			Uri: "/",
+		}
+	}
+	// we should rewrite host for dns service
+	if se != nil && se.Resolution == v1alpha3.ServiceEntry_DNS && len(se.Endpoints) > 0 {
+		if vs.Http[0].Rewrite == nil {
+			vs.Http[0].Rewrite = &v1alpha3.HTTPRewrite{
+				Authority: se.Endpoints[0].Address,
+			}
+		} else {
+			vs.Http[0].Rewrite.Authority = se.Endpoints[0].Address
+		}
+	}
+
+	mcpLog.Debugf("construct virtualservice %v", vs)
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.VirtualService,
+			Name:             name,
+			Namespace:        opts.Namespace,
+		},
+		Spec: vs,
+	}
+}
+
+// BuildMcpServer builds the McpServer config matching the route generated by BuildVirtualService.
+func BuildMcpServer(opts ExportOptions, name, protocol string, vs *v1alpha3.VirtualService) *config.Config {
+	if vs == nil || len(vs.Http) == 0 {
+		return nil
+	}
+	pathMatchValue := ""
+	for _, match := range vs.Http[0].Match {
+		if match.Uri != nil && match.Uri.GetExact() != "" {
+			pathMatchValue = match.Uri.GetExact()
+			break
+		}
+	}
+
+	mcpServer := &higressmcpserver.McpServer{
+		Name:           name,
+		Domains:        opts.domains(),
+		PathMatchType:  higressmcpserver.PrefixMatchType,
+		PathMatchValue: pathMatchValue,
+		UpstreamType:   protocolUpstreamTypeMapping[protocol],
+	}
+	if mcpServerRewriteProtocols[protocol] {
+		mcpServer.EnablePathRewrite = true
+		mcpServer.PathRewritePrefix = "/"
+	}
+
+	mcpLog.Debugf("construct mcpserver %v", mcpServer)
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: higressmcpserver.GvkMcpServer,
+			Name:             name,
+			Namespace:        opts.Namespace,
+		},
+		Spec: mcpServer,
+	}
+}
+
+// BuildDestinationRule returns the traffic policy required by the mcp server protocol:
+// sse sessions need ConsistentHash policy and https services need tls policy.
+func BuildDestinationRule(host, protocol string) *v1alpha3.DestinationRule {
+	switch protocol {
+	case provider.McpSSEProtocol:
+		return &v1alpha3.DestinationRule{
+			Host: host,
+			TrafficPolicy: &v1alpha3.TrafficPolicy{
+				LoadBalancer: &v1alpha3.LoadBalancerSettings{
+					LbPolicy: &v1alpha3.LoadBalancerSettings_ConsistentHash{
+						ConsistentHash: &v1alpha3.LoadBalancerSettings_ConsistentHashLB{
+							HashKey: &v1alpha3.LoadBalancerSettings_ConsistentHashLB_UseSourceIp{
+								UseSourceIp: true,
+							},
+						},
+					},
+				},
+			},
+		}
+	case provider.HttpsProtocol:
+		return &v1alpha3.DestinationRule{
+			Host: host,
+			TrafficPolicy: &v1alpha3.TrafficPolicy{
+				Tls: &v1alpha3.ClientTLSSettings{
+					Mode: v1alpha3.ClientTLSSettings_SIMPLE,
+				},
+			},
+		}
+	}
+	return nil
+}
+
+// BuildMcpServerRule converts the tool spec of a rest mcp server to the rule of the mcp-server wasm plugin.
+func BuildMcpServerRule(routeName, serverName, toolsSpec string, credentials map[string]interface{}) (*provider.McpServerRule, error) {
+	toolsDescription := &provider.McpToolConfig{}
+	if err := json.Unmarshal([]byte(toolsSpec), toolsDescription); err != nil {
+		return nil, fmt.Errorf("unmarshal toolsDescriptionRef to mcp tool config error:%v, data %v", err, toolsSpec)
+	}
+
+	rule := &provider.McpServerRule{
+		MatchRoute: []string{routeName},
+		Server: &provider.ServerConfig{
+			Name:   serverName,
+			Config: map[string]interface{}{},
+		},
+	}
+	rule.Server.Config["credentials"] = credentials
+	// process security schemas
+	if len(toolsDescription.SecuritySchemes) > 0 {
+		rule.Server.SecuritySchemes = toolsDescription.SecuritySchemes
+	}
+
+	allowTools := []string{}
+	for _, t := range toolsDescription.Tools {
+		convertTool := &provider.McpTool{Name: t.Name, Description: t.Description}
+
+		toolMeta := toolsDescription.ToolsMeta[t.Name]
+		if toolMeta != nil && toolMeta.Enabled {
+			allowTools = append(allowTools, t.Name)
+		}
+		argsPosition, err := getArgsPositionFromToolMeta(toolMeta)
+		if err != nil {
+			mcpLog.Errorf("get args position from tool meta error:%v, tool name %v", err, t.Name)
+		}
+
+		requiredMap := sets.Set[string]{}
+		for _, s := range t.InputSchema.Required {
+			requiredMap.Insert(s)
+		}
+
+		for argsName, args := range t.InputSchema.Properties {
+			convertArgs, err := parseMcpArgs(args)
+			if err != nil {
+				mcpLog.Errorf("parse mcp args error:%v, tool name %v, args name %v", err, t.Name, argsName)
+				continue
+			}
+			convertArgs.Name = argsName
+			convertArgs.Required = requiredMap.Contains(argsName)
+			if pos, exist := argsPosition[argsName]; exist {
+				convertArgs.Position = pos
+			}
+			convertTool.Args = append(convertTool.Args, convertArgs)
+			mcpLog.Debugf("parseMcpArgs, toolArgs:%v", convertArgs)
+		}
+
+		requestTemplate, err := getRequestTemplateFromToolMeta(toolMeta)
+		if err != nil {
+			mcpLog.Errorf("get request template from tool meta error:%v, tool name %v", err, t.Name)
+			continue
+		} else {
+			convertTool.RequestTemplate = requestTemplate
+		}
+
+		responseTemplate, errorResponseTemplate, err := getResponseTemplateFromToolMeta(toolMeta)
+		if err != nil {
+			mcpLog.Errorf("get response template from tool meta error:%v, tool name %v", err, t.Name)
+			continue
+		} else {
+			convertTool.ResponseTemplate = responseTemplate
+			convertTool.ErrorResponseTemplate = errorResponseTemplate
+		}
+
+		security, err := getSecurityFromToolMeta(toolMeta)
+		if err != nil {
+			mcpLog.Errorf("get security from tool meta error:%v, tool name %v", err, t.Name)
+			continue
+		} else {
+			convertTool.Security = security
+		}
+
+		rule.Tools = append(rule.Tools, convertTool)
+	}
+
+	rule.AllowTools = allowTools
+	return rule, nil
+}
+
+func parseMcpArgs(args interface{}) (*provider.ToolArgs, error) {
+	argsData, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	toolArgs := &provider.ToolArgs{}
+	if err = json.Unmarshal(argsData, toolArgs); err != nil {
+		return nil, err
+	}
+	return toolArgs, nil
+}
+
+func getArgsPositionFromToolMeta(toolMeta *provider.ToolsMeta) (map[string]string, error) {
+	result := map[string]string{}
+	if toolMeta == nil {
+		return result, nil
+	}
+	toolTemplate := toolMeta.Templates
+	for kind, meta := range toolTemplate {
+		switch kind {
+		case provider.JsonGoTemplateType:
+			templateData, err := json.Marshal(meta)
+			if err != nil {
+				return result, err
+			}
+			template := &provider.JsonGoTemplate{}
+			if err = json.Unmarshal(templateData, template); err != nil {
+				return result, err
+			}
+			result = mergeMaps(result, template.ArgsPosition)
+		default:
+			return result, fmt.Errorf("unsupport tool meta type %v", kind)
+		}
+	}
+	return result, nil
+}
+
+func getRequestTemplateFromToolMeta(toolMeta *provider.ToolsMeta) (*provider.RequestTemplate, error) {
+	if toolMeta == nil {
+		return nil, nil
+	}
+	toolTemplate := toolMeta.Templates
+	for kind, meta := range toolTemplate {
+		switch kind {
+		case provider.JsonGoTemplateType:
+			templateData, err := json.Marshal(meta)
+			if err != nil {
+				return nil, err
+			}
+			template := &provider.JsonGoTemplate{}
+			if err = json.Unmarshal(templateData, template); err != nil {
+				return nil, err
+			}
+			return &template.RequestTemplate, nil
+		default:
+			return nil, fmt.Errorf("unsupport tool meta type")
+		}
+	}
+	return nil, nil
+}
+
+func getResponseTemplateFromToolMeta(toolMeta *provider.ToolsMeta) (*provider.ResponseTemplate, string, error) {
+	if toolMeta == nil {
+		return nil, "", nil
+	}
+	toolTemplate := toolMeta.Templates
+	for kind, meta := range toolTemplate {
+		switch kind {
+		case provider.JsonGoTemplateType:
+			templateData, err := json.Marshal(meta)
+			if err != nil {
+				return nil, "", err
+			}
+			template := &provider.JsonGoTemplate{}
+			if err = json.Unmarshal(templateData, template); err != nil {
+				return nil, "", err
+			}
+			return &template.ResponseTemplate, template.ErrorResponseTemplate, nil
+		default:
+			return nil, "", fmt.Errorf("unsupported tool meta type: %s", kind)
+		}
+	}
+	return nil, "", nil
+}
+
+func getSecurityFromToolMeta(toolMeta *provider.ToolsMeta) (*provider.ToolSecurity, error) {
+	if toolMeta == nil {
+		return nil, nil
+	}
+	toolTemplate := toolMeta.Templates
+	for kind, meta := range toolTemplate {
+		switch kind {
+		case provider.JsonGoTemplateType:
+			templateData, err := json.Marshal(meta)
+			if err != nil {
+				return nil, err
+			}
+			template := &provider.JsonGoTemplate{}
+			if err = json.Unmarshal(templateData, template); err != nil {
+				return nil, err
+			}
+			return template.Security, nil
+		default:
+			return nil, fmt.Errorf("unsupported tool meta type: %s", kind)
+		}
+	}
+	return nil, nil
+}
+
+func mergeMaps(maps ...map[string]string) map[string]string {
+	if len(maps) == 0 {
+		return nil
+	}
+	res := make(map[string]string, len(maps[0]))
+	for _, m := range maps {
+		for k, v := range m {
+			res[k] = v
+		}
+	}
+	return res
+}
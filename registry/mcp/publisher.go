@@ -0,0 +1,225 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/util/sets"
+
+	apiv1 "github.com/alibaba/higress/v2/api/networking/v1"
+	higressmcpserver "github.com/alibaba/higress/v2/pkg/ingress/kube/mcpserver"
+	provider "github.com/alibaba/higress/v2/registry"
+	"github.com/alibaba/higress/v2/registry/memory"
+)
+
+// Metadata keys used by services of consul and eureka to announce themselves as mcp servers.
+const (
+	// MetadataServerProtocol marks the service as a mcp server, one of http, https, mcp-sse and mcp-streamable.
+	MetadataServerProtocol = "mcp-server-protocol"
+	// MetadataServerName is the name of the mcp server, defaults to the service name.
+	MetadataServerName = "mcp-server-name"
+	// MetadataServerTools is the inline tool spec of a http/https mcp server.
+	MetadataServerTools = "mcp-server-tools"
+	// MetadataServerToolsKey is the consul kv key holding the tool spec of a http/https mcp server.
+	MetadataServerToolsKey = "mcp-server-tools-key"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Server is a mcp server announced by the metadata of a registry service.
+type Server struct {
+	// Key identifies the server in the cache and is used in the names of the generated configs.
+	Key         string
+	Name        string
+	Protocol    string
+	ServiceHost string
+	// ToolsKey is where the tool spec is stored in the registry, if it's not inline.
+	ToolsKey string
+	Tools    string
+}
+
+// ServerKey returns the cache key of the mcp server exported by a service of the registry.
+func ServerKey(registryType, registryName, serviceName string) string {
+	key := strings.ToLower(strings.Join([]string{registryType, registryName, serviceName}, "-"))
+	return strings.Trim(invalidNameChars.ReplaceAllString(key, "-"), "-")
+}
+
+// ParseServer returns the mcp server announced by the service metadata, or nil if the service
+// is not a mcp server or its protocol is not supported.
+func ParseServer(key, serviceHost, serviceName string, metadata map[string]string) *Server {
+	protocol := strings.ToLower(strings.TrimSpace(metadata[MetadataServerProtocol]))
+	if protocol == "" {
+		return nil
+	}
+	// TODO support stdio and dubbo protocol
+	if !SupportedProtocols[protocol] {
+		mcpLog.Warnf("unsupported mcp server protocol %s of service %s", protocol, serviceName)
+		return nil
+	}
+	name := strings.TrimSpace(metadata[MetadataServerName])
+	if name == "" {
+		name = strings.ToLower(serviceName)
+	}
+	return &Server{
+		Key:         key,
+		Name:        name,
+		Protocol:    protocol,
+		ServiceHost: serviceHost,
+		ToolsKey:    strings.TrimSpace(metadata[MetadataServerToolsKey]),
+		Tools:       metadata[MetadataServerTools],
+	}
+}
+
+// Publisher writes the gateway configs of the mcp servers discovered by a registry into the cache.
+type Publisher struct {
+	cache   memory.Cache
+	options ExportOptions
+	// only the mcp servers in allowMcpServers are exported if enableScopeMcpServers is true
+	scoped  bool
+	allowed sets.Set[string]
+	mutex   sync.Mutex
+	servers map[string]*Server
+}
+
+func NewPublisher(cache memory.Cache, registry *apiv1.RegistryConfig, namespace, clusterId string) *Publisher {
+	return &Publisher{
+		cache: cache,
+		options: ExportOptions{
+			ExportDomains: registry.McpServerExportDomains,
+			BaseUrl:       registry.McpServerBaseUrl,
+			Namespace:     namespace,
+			ClusterId:     clusterId,
+		},
+		scoped:  registry.EnableScopeMcpServers.GetValue(),
+		allowed: sets.New(registry.AllowMcpServers...),
+		servers: make(map[string]*Server),
+	}
+}
+
+// Allowed reports whether the mcp server with the given name can be exported.
+func (p *Publisher) Allowed(name string) bool {
+	return !p.scoped || p.allowed.Contains(name)
+}
+
+// Publish writes the configs of the mcp server into the cache, it returns true if the cache is changed.
+func (p *Publisher) Publish(server *Server) bool {
+	if server == nil {
+		return false
+	}
+	if !p.Allowed(server.Name) {
+		mcpLog.Infof("mcp server %s is not in allowMcpServers, skip it", server.Name)
+		return p.Remove(server.Key)
+	}
+	if IsRestProtocol(server.Protocol) && server.Tools == "" {
+		mcpLog.Warnf("mcp server %s has no tool spec, skip it", server.Name)
+		return p.Remove(server.Key)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if existing, ok := p.servers[server.Key]; ok && reflect.DeepEqual(existing, server) {
+		return false
+	}
+
+	var rule *provider.McpServerRule
+	routeName := fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedHttpRouteName, server.Key)
+	if IsRestProtocol(server.Protocol) {
+		var err error
+		if rule, err = BuildMcpServerRule(routeName, server.Name, server.Tools, nil); err != nil {
+			mcpLog.Errorf("process tool config error:%v, mcp server:%s", err, server.Name)
+			return p.remove(server.Key)
+		}
+	}
+
+	// clean the configs of the last version, the protocol of the server may be changed
+	p.cache.UpdateConfigCache(config.GroupVersionKind{}, server.Key, nil, true)
+
+	vs := BuildVirtualService(p.options, fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedVsName, server.Key),
+		routeName, server.Name, server.Protocol, server.ServiceHost, nil)
+	p.cache.UpdateConfigCache(gvk.VirtualService, server.Key, vs, false)
+	ms := BuildMcpServer(p.options, fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedMcpServerName, server.Key),
+		server.Protocol, vs.Spec.(*v1alpha3.VirtualService))
+	p.cache.UpdateConfigCache(higressmcpserver.GvkMcpServer, server.Key, ms, false)
+	if dr := BuildDestinationRule(server.ServiceHost, server.Protocol); dr != nil {
+		p.cache.UpdateConfigCache(gvk.DestinationRule, server.Key, &config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: gvk.DestinationRule,
+				Name:             fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedDrName, server.Key),
+				Namespace:        p.options.Namespace,
+			},
+			Spec: dr,
+		}, false)
+	}
+	if rule != nil {
+		p.cache.UpdateConfigCache(gvk.WasmPlugin, server.Key, &config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: gvk.WasmPlugin,
+				Namespace:        p.options.Namespace,
+			},
+			Spec: rule,
+		}, false)
+	}
+
+	published := *server
+	p.servers[server.Key] = &published
+	mcpLog.Infof("publish mcp server %s of service %s, protocol %s", server.Name, server.ServiceHost, server.Protocol)
+	return true
+}
+
+// Remove deletes the configs of the mcp server from the cache, it returns true if the cache is changed.
+func (p *Publisher) Remove(key string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.remove(key)
+}
+
+func (p *Publisher) remove(key string) bool {
+	if _, ok := p.servers[key]; !ok {
+		return false
+	}
+	delete(p.servers, key)
+	p.cache.UpdateConfigCache(config.GroupVersionKind{}, key, nil, true)
+	return true
+}
+
+// RemoveAll deletes the configs of all the mcp servers published.
+func (p *Publisher) RemoveAll() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for key := range p.servers {
+		p.remove(key)
+	}
+}
+
+// Servers returns a copy of the mcp servers published.
+func (p *Publisher) Servers() []*Server {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	servers := make([]*Server, 0, len(p.servers))
+	for _, server := range p.servers {
+		s := *server
+		servers = append(servers, &s)
+	}
+	return servers
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	wrappers "google.golang.org/protobuf/types/known/wrapperspb"
+	"istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
+
+	apiv1 "github.com/alibaba/higress/v2/api/networking/v1"
+	ingress "github.com/alibaba/higress/v2/pkg/ingress/kube/common"
+	higressmcpserver "github.com/alibaba/higress/v2/pkg/ingress/kube/mcpserver"
+	provider "github.com/alibaba/higress/v2/registry"
+	"github.com/alibaba/higress/v2/registry/memory"
+)
+
+const testTools = `{
+	"tools": [
+		{
+			"name": "get-order",
+			"description": "get order by id",
+			"inputSchema": {
+				"type": "object",
+				"properties": {
+					"id": {
+						"type": "string",
+						"description": "order id"
+					}
+				},
+				"required": ["id"]
+			}
+		}
+	],
+	"toolsMeta": {
+		"get-order": {
+			"enabled": true,
+			"templates": {
+				"json-go-template": {
+					"requestTemplate": {
+						"method": "GET",
+						"url": "/orders/{{.args.id}}"
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestServerKey(t *testing.T) {
+	assert.Equal(t, "consul-my-consul-order-service", ServerKey("consul", "my-consul", "order_service"))
+	assert.Equal(t, "eureka-eureka-order-service", ServerKey("eureka", "eureka", "ORDER-SERVICE"))
+}
+
+func TestParseServer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		metadata map[string]string
+		want     *Server
+	}{
+		{
+			name:     "not mcp server",
+			metadata: map[string]string{"protocol": "http"},
+		},
+		{
+			name:     "unsupported protocol",
+			metadata: map[string]string{MetadataServerProtocol: "stdio"},
+		},
+		{
+			name:     "default name",
+			metadata: map[string]string{MetadataServerProtocol: "MCP-SSE"},
+			want: &Server{
+				Key:         "key",
+				Name:        "order-service",
+				Protocol:    provider.McpSSEProtocol,
+				ServiceHost: "ORDER-SERVICE.eureka",
+			},
+		},
+		{
+			name: "rest server",
+			metadata: map[string]string{
+				MetadataServerProtocol: "http",
+				MetadataServerName:     "orders",
+				MetadataServerTools:    testTools,
+				MetadataServerToolsKey: "tools/orders",
+			},
+			want: &Server{
+				Key:         "key",
+				Name:        "orders",
+				Protocol:    provider.HttpProtocol,
+				ServiceHost: "ORDER-SERVICE.eureka",
+				ToolsKey:    "tools/orders",
+				Tools:       testTools,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseServer("key", "ORDER-SERVICE.eureka", "ORDER-SERVICE", tc.metadata))
+		})
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	localCache := memory.NewCache()
+	publisher := NewPublisher(localCache, &apiv1.RegistryConfig{
+		McpServerExportDomains: []string{"mcp.com"},
+		McpServerBaseUrl:       "/mcp-servers/",
+		EnableScopeMcpServers:  wrappers.Bool(true),
+		AllowMcpServers:        []string{"orders", "chat"},
+	}, "higress-system", "higress")
+
+	key := "consul-consul-chat"
+	chat := &Server{
+		Key:         key,
+		Name:        "chat",
+		Protocol:    provider.McpSSEProtocol,
+		ServiceHost: "chat.dc1.consul",
+	}
+	assert.True(t, publisher.Publish(chat))
+	assert.False(t, publisher.Publish(chat))
+
+	wantVs := &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.VirtualService,
+			Name:             fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedVsName, key),
+			Namespace:        "higress-system",
+		},
+		Spec: &v1alpha3.VirtualService{
+			Hosts: []string{"mcp.com"},
+			Gateways: []string{"higress-system/" + ingress.CreateConvertedName("higress", ingress.CleanHost("mcp.com")),
+				ingress.CreateConvertedName(constants.IstioIngressGatewayName, ingress.CleanHost("mcp.com"))},
+			Http: []*v1alpha3.HTTPRoute{
+				{
+					Name: fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedHttpRouteName, key),
+					Match: []*v1alpha3.HTTPMatchRequest{
+						{
+							Uri: &v1alpha3.StringMatch{
+								MatchType: &v1alpha3.StringMatch_Exact{
+									Exact: "/mcp-servers/chat",
+								},
+							},
+						},
+						{
+							Uri: &v1alpha3.StringMatch{
+								MatchType: &v1alpha3.StringMatch_Prefix{
+									Prefix: "/mcp-servers/chat/",
+								},
+							},
+						},
+					},
+					Route: []*v1alpha3.HTTPRouteDestination{
+						{
+							Destination: &v1alpha3.Destination{
+								Host: "chat.dc1.consul",
+							},
+						},
+					},
+					Rewrite: &v1alpha3.HTTPRewrite{
+						Uri: "/",
+					},
+				},
+			},
+		},
+	}
+	vs := localCache.GetAllConfigs(gvk.VirtualService)[key]
+	if !reflect.DeepEqual(vs, wantVs) {
+		t.Errorf("vs is not equal, want %v\n, got %v", wantVs, vs)
+	}
+
+	wantMcpServer := &higressmcpserver.McpServer{
+		Name:              fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedMcpServerName, key),
+		Domains:           []string{"mcp.com"},
+		PathMatchType:     higressmcpserver.PrefixMatchType,
+		PathMatchValue:    "/mcp-servers/chat",
+		UpstreamType:      higressmcpserver.UpstreamTypeSSE,
+		EnablePathRewrite: true,
+		PathRewritePrefix: "/",
+	}
+	ms := localCache.GetAllConfigs(higressmcpserver.GvkMcpServer)[key]
+	if assert.NotNil(t, ms) {
+		assert.Equal(t, wantMcpServer, ms.Spec)
+	}
+	dr := localCache.GetAllConfigs(gvk.DestinationRule)[key]
+	if assert.NotNil(t, dr) {
+		assert.Equal(t, "chat.dc1.consul", dr.Spec.(*v1alpha3.DestinationRule).Host)
+	}
+
+	// rest server is exported with the tools converted to the wasm plugin rule
+	orders := &Server{
+		Key:         "eureka-eureka-order-service",
+		Name:        "orders",
+		Protocol:    provider.HttpProtocol,
+		ServiceHost: "ORDER-SERVICE.eureka",
+		Tools:       testTools,
+	}
+	assert.True(t, publisher.Publish(orders))
+	assert.Len(t, localCache.GetAllConfigs(higressmcpserver.GvkMcpServer), 2)
+	assert.NotContains(t, localCache.GetAllConfigs(gvk.DestinationRule), orders.Key)
+	wasm := localCache.GetAllConfigs(gvk.WasmPlugin)
+	assert.Len(t, wasm, 1)
+
+	// rest server without tools is skipped
+	orders.Tools = ""
+	assert.True(t, publisher.Publish(orders))
+	assert.NotContains(t, localCache.GetAllConfigs(gvk.VirtualService), orders.Key)
+	assert.Len(t, localCache.GetAllConfigs(gvk.WasmPlugin), 0)
+
+	// servers out of allowMcpServers are not exported
+	assert.False(t, publisher.Publish(&Server{
+		Key:         "consul-consul-payment",
+		Name:        "payment",
+		Protocol:    provider.McpStreamableProtocol,
+		ServiceHost: "payment.dc1.consul",
+	}))
+	assert.Len(t, localCache.GetAllConfigs(gvk.VirtualService), 1)
+
+	// protocol changed, the configs of the last version are cleaned
+	chat.Protocol = provider.McpStreamableProtocol
+	assert.True(t, publisher.Publish(chat))
+	assert.NotContains(t, localCache.GetAllConfigs(gvk.DestinationRule), key)
+	assert.Len(t, publisher.Servers(), 1)
+
+	assert.True(t, publisher.Remove(key))
+	assert.False(t, publisher.Remove(key))
+	assert.Len(t, localCache.GetAllConfigs(gvk.VirtualService), 0)
+	assert.Len(t, localCache.GetAllConfigs(higressmcpserver.GvkMcpServer), 0)
+}
+
+func TestBuildMcpServerRule(t *testing.T) {
+	rule, err := BuildMcpServerRule("route", "orders", testTools, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"route"}, rule.MatchRoute)
+	assert.Equal(t, []string{"get-order"}, rule.AllowTools)
+	if assert.Len(t, rule.Tools, 1) {
+		tool := rule.Tools[0]
+		assert.Equal(t, "/orders/{{.args.id}}", tool.RequestTemplate.URL)
+		if assert.Len(t, tool.Args, 1) {
+			assert.Equal(t, "id", tool.Args[0].Name)
+			assert.True(t, tool.Args[0].Required)
+		}
+	}
+
+	_, err = BuildMcpServerRule("route", "orders", "", nil)
+	assert.Error(t, err)
+}
@@ -48,6 +48,7 @@ type Cache interface {
 	GetServiceByEndpoints(requestVersions, endpoints map[string]bool, versionKey string, protocol common.Protocol) map[string][]string
 	GetAllServiceEntry() []*v1alpha3.ServiceEntry
 	GetAllServiceWrapper() []*ingress.ServiceWrapper
+	GetServiceEntriesByRegistry(registryType, registryName string) []*v1alpha3.ServiceEntry
 	GetAllProxyWrapper() []*ingress.ProxyWrapper
 	GetAllDestinationRuleWrapper() []*ingress.WrapperDestinationRule
 	GetIncrementalServiceWrapper() (updatedList []*ingress.ServiceWrapper, deletedList []*ingress.ServiceWrapper)
@@ -357,6 +358,24 @@ func (s *store) GetAllServiceWrapper() []*ingress.ServiceWrapper {
 	return sewList
 }
 
+// GetServiceEntriesByRegistry get the ServiceEntries discovered by a registry, the incremental updates are kept
+func (s *store) GetServiceEntriesByRegistry(registryType, registryName string) []*v1alpha3.ServiceEntry {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	seList := make([]*v1alpha3.ServiceEntry, 0)
+	for _, serviceEntryWrapper := range s.sew {
+		if serviceEntryWrapper.RegistryType != registryType || serviceEntryWrapper.RegistryName != registryName {
+			continue
+		}
+		if len(serviceEntryWrapper.ServiceEntry.Hosts) == 0 {
+			continue
+		}
+		seList = append(seList, serviceEntryWrapper.ServiceEntry.DeepCopy())
+	}
+	return seList
+}
+
 // GetAllProxyWrapper get all ServiceWrapper in the store for xds push
 func (s *store) GetAllProxyWrapper() []*ingress.ProxyWrapper {
 	s.mux.RLock()
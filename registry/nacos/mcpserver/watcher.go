@@ -26,9 +26,9 @@ import (
 
 	apiv1 "github.com/alibaba/higress/v2/api/networking/v1"
 	"github.com/alibaba/higress/v2/pkg/common"
-	common2 "github.com/alibaba/higress/v2/pkg/ingress/kube/common"
 	"github.com/alibaba/higress/v2/pkg/ingress/kube/mcpserver"
 	provider "github.com/alibaba/higress/v2/registry"
+	"github.com/alibaba/higress/v2/registry/mcp"
 	"github.com/alibaba/higress/v2/registry/memory"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
@@ -36,10 +36,8 @@ import (
 	"go.uber.org/atomic"
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pkg/config"
-	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/log"
-	"istio.io/istio/pkg/util/sets"
 )
 
 const (
@@ -56,28 +54,6 @@ const (
 	DefaultRefreshIntervalLimit = time.Second * 10
 )
 
-var (
-	supportedProtocols = map[string]bool{
-		provider.HttpProtocol:          true,
-		provider.HttpsProtocol:         true,
-		provider.McpSSEProtocol:        true,
-		provider.McpStreamableProtocol: true,
-	}
-	protocolUpstreamTypeMapping = map[string]string{
-		provider.HttpProtocol:          mcpserver.UpstreamTypeRest,
-		provider.HttpsProtocol:         mcpserver.UpstreamTypeRest,
-		provider.McpSSEProtocol:        mcpserver.UpstreamTypeSSE,
-		provider.McpStreamableProtocol: mcpserver.UpstreamTypeStreamable,
-	}
-	routeRewriteProtocols = map[string]bool{
-		provider.McpSSEProtocol:        true,
-		provider.McpStreamableProtocol: true,
-	}
-	mcpServerRewriteProtocols = map[string]bool{
-		provider.McpSSEProtocol: true,
-	}
-)
-
 var mcpServerLog = log.RegisterScope("McpServer", "Nacos Mcp Server Watcher process.")
 
 type watcher struct {
@@ -360,7 +336,7 @@ func (w *watcher) mcpServerListener(dataId string) func(info *McpServerConfig) {
 			mcpServerLog.Errorf("unmarshal config data to mcp server error:%v, dataId:%s", err, dataId)
 		}
 		// TODO support stdio and dubbo protocol
-		if !supportedProtocols[mcpServer.Protocol] {
+		if !mcp.SupportedProtocols[mcpServer.Protocol] {
 			return
 		}
 		if err := w.processServerConfig(dataId, info.ServiceInfo, mcpServer); err != nil {
@@ -396,7 +372,7 @@ func (w *watcher) processServerConfig(dataId string, services *model.Service, mc
 	}
 	// if protocol is sse, we should apply ConsistentHash policy for this service
 	// if protocol is https, we should apply tls policy for this service
-	destinationRule := generateDrForMcpServer(serviceHost, mcpServer.Protocol)
+	destinationRule := mcp.BuildDestinationRule(serviceHost, mcpServer.Protocol)
 	if destinationRule != nil {
 		dr := &config.Config{
 			Meta: config.Meta{
@@ -412,90 +388,14 @@ func (w *watcher) processServerConfig(dataId string, services *model.Service, mc
 }
 
 func (w *watcher) processToolConfig(dataId, data string, credentials map[string]interface{}, server *provider.McpServer) error {
-	if server.Protocol != provider.HttpProtocol && server.Protocol != provider.HttpsProtocol {
+	if !mcp.IsRestProtocol(server.Protocol) {
 		return nil
 	}
-	toolsDescription := &provider.McpToolConfig{}
-	if err := json.Unmarshal([]byte(data), toolsDescription); err != nil {
-		return fmt.Errorf("unmarshal toolsDescriptionRef to mcp tool config error:%v, data %v", err, data)
-	}
-
 	routeName := fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedHttpRouteName, strings.TrimSuffix(dataId, ".json"))
-	rule := &provider.McpServerRule{
-		MatchRoute: []string{routeName},
-		Server: &provider.ServerConfig{
-			Name:   server.Name,
-			Config: map[string]interface{}{},
-		},
-	}
-	rule.Server.Config["credentials"] = credentials
-	// process security schemas
-	if len(toolsDescription.SecuritySchemes) > 0 {
-		rule.Server.SecuritySchemes = toolsDescription.SecuritySchemes
-	}
-
-	allowTools := []string{}
-	for _, t := range toolsDescription.Tools {
-		convertTool := &provider.McpTool{Name: t.Name, Description: t.Description}
-
-		toolMeta := toolsDescription.ToolsMeta[t.Name]
-		if toolMeta != nil && toolMeta.Enabled {
-			allowTools = append(allowTools, t.Name)
-		}
-		argsPosition, err := getArgsPositionFromToolMeta(toolMeta)
-		if err != nil {
-			mcpServerLog.Errorf("get args position from tool meta error:%v, tool name %v", err, t.Name)
-		}
-
-		requiredMap := sets.Set[string]{}
-		for _, s := range t.InputSchema.Required {
-			requiredMap.Insert(s)
-		}
-
-		for argsName, args := range t.InputSchema.Properties {
-			convertArgs, err := parseMcpArgs(args)
-			if err != nil {
-				mcpServerLog.Errorf("parse mcp args error:%v, tool name %v, args name %v", err, t.Name, argsName)
-				continue
-			}
-			convertArgs.Name = argsName
-			convertArgs.Required = requiredMap.Contains(argsName)
-			if pos, exist := argsPosition[argsName]; exist {
-				convertArgs.Position = pos
-			}
-			convertTool.Args = append(convertTool.Args, convertArgs)
-			mcpServerLog.Debugf("parseMcpArgs, toolArgs:%v", convertArgs)
-		}
-
-		requestTemplate, err := getRequestTemplateFromToolMeta(toolMeta)
-		if err != nil {
-			mcpServerLog.Errorf("get request template from tool meta error:%v, tool name %v", err, t.Name)
-			continue
-		} else {
-			convertTool.RequestTemplate = requestTemplate
-		}
-
-		responseTemplate, errorResponseTemplate, err := getResponseTemplateFromToolMeta(toolMeta)
-		if err != nil {
-			mcpServerLog.Errorf("get response template from tool meta error:%v, tool name %v", err, t.Name)
-			continue
-		} else {
-			convertTool.ResponseTemplate = responseTemplate
-			convertTool.ErrorResponseTemplate = errorResponseTemplate
-		}
-
-		security, err := getSecurityFromToolMeta(toolMeta)
-		if err != nil {
-			mcpServerLog.Errorf("get security from tool meta error:%v, tool name %v", err, t.Name)
-			continue
-		} else {
-			convertTool.Security = security
-		}
-
-		rule.Tools = append(rule.Tools, convertTool)
+	rule, err := mcp.BuildMcpServerRule(routeName, server.Name, data, credentials)
+	if err != nil {
+		return err
 	}
-
-	rule.AllowTools = allowTools
 	wasmPluginConfig := &config.Config{
 		Meta: config.Meta{
 			GroupVersionKind: gvk.WasmPlugin,
@@ -507,287 +407,27 @@ func (w *watcher) processToolConfig(dataId, data string, credentials map[string]
 	return nil
 }
 
+func (w *watcher) exportOptions() mcp.ExportOptions {
+	return mcp.ExportOptions{
+		ExportDomains: w.McpServerExportDomains,
+		BaseUrl:       w.McpServerBaseUrl,
+		Namespace:     w.namespace,
+		ClusterId:     w.clusterId,
+	}
+}
+
 func (w *watcher) buildVirtualServiceForMcpServer(server *provider.McpServer, dataId, serviceName string, se *v1alpha3.ServiceEntry) *config.Config {
 	if server == nil {
 		return nil
 	}
-	// if there is no export domain, use default *
-	hosts := w.McpServerExportDomains
-	if len(hosts) == 0 {
-		hosts = []string{"*"}
-	}
-	// find gateway resources by host
-	var gateways []string
-	for _, host := range hosts {
-		cleanHost := common2.CleanHost(host)
-		// namespace/name, name format: (istio cluster id)-host
-		gateways = append(gateways, w.namespace+"/"+
-			common2.CreateConvertedName(w.clusterId, cleanHost),
-			common2.CreateConvertedName(constants.IstioIngressGatewayName, cleanHost))
-	}
 	routeName := fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedHttpRouteName, strings.TrimSuffix(dataId, ".json"))
-	// path format: /{base-path}/{mcp-server-name}
-	mergePath := "/" + server.Name
-	if w.McpServerBaseUrl != "" && w.McpServerBaseUrl != "/" {
-		mergePath = strings.TrimSuffix(w.McpServerBaseUrl, "/") + mergePath
-	}
-
-	vs := &v1alpha3.VirtualService{
-		Hosts:    hosts,
-		Gateways: gateways,
-		Http: []*v1alpha3.HTTPRoute{{
-			Name: routeName,
-			// We need to use both exact and prefix matches here to ensure a proper matching.
-			// Also otherwise, prefix rewrite won't work correctly for Streamable HTTP transport, either.
-			// Example:
-			// Assume mergePath=/mcp/test prefixRewrite=/ requestPath=/mcp/test/abc
-			// If we only use prefix match, the rewritten path will be //abc.
-			Match: []*v1alpha3.HTTPMatchRequest{
-				{
-					Uri: &v1alpha3.StringMatch{
-						MatchType: &v1alpha3.StringMatch_Exact{
-							Exact: mergePath,
-						},
-					},
-				},
-				{
-					Uri: &v1alpha3.StringMatch{
-						MatchType: &v1alpha3.StringMatch_Prefix{
-							Prefix: mergePath + "/",
-						},
-					},
-				},
-			},
-			Route: []*v1alpha3.HTTPRouteDestination{{
-				Destination: &v1alpha3.Destination{
-					Host: serviceName,
-				},
-			}},
-		}},
-	}
-
-	// we should rewrite path for sse and streamble
-	if routeRewriteProtocols[server.Protocol] {
-		vs.Http[0].Rewrite = &v1alpha3.HTTPRewrite{
-			Uri: "/",
-		}
-	}
-	// we should rewrite host for dns service
-	if se != nil && se.Resolution == v1alpha3.ServiceEntry_DNS && len(se.Endpoints) > 0 {
-		if vs.Http[0].Rewrite == nil {
-			vs.Http[0].Rewrite = &v1alpha3.HTTPRewrite{
-				Authority: se.Endpoints[0].Address,
-			}
-		} else {
-			vs.Http[0].Rewrite.Authority = se.Endpoints[0].Address
-		}
-	}
-
-	mcpServerLog.Debugf("construct virtualservice %v", vs)
-
-	return &config.Config{
-		Meta: config.Meta{
-			GroupVersionKind: gvk.VirtualService,
-			Name:             fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedVsName, dataId),
-			Namespace:        w.namespace,
-		},
-		Spec: vs,
-	}
+	return mcp.BuildVirtualService(w.exportOptions(), fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedVsName, dataId),
+		routeName, server.Name, server.Protocol, serviceName, se)
 }
 
 func (w *watcher) buildMcpServerForMcpServer(vs *v1alpha3.VirtualService, dataId string, server *provider.McpServer) *config.Config {
-	if vs == nil {
-		return nil
-	}
-	domains := w.McpServerExportDomains
-	if len(domains) == 0 {
-		domains = []string{"*"}
-	}
 	name := fmt.Sprintf("%s-%s", provider.IstioMcpAutoGeneratedMcpServerName, strings.TrimSuffix(dataId, ".json"))
-	httpRoute := vs.Http[0]
-	pathMatchValue := ""
-	for _, match := range httpRoute.Match {
-		if match.Uri != nil && match.Uri.GetExact() != "" {
-			pathMatchValue = match.Uri.GetExact()
-			break
-		}
-	}
-	protocol := server.Protocol
-
-	mcpServer := &mcpserver.McpServer{
-		Name:           name,
-		Domains:        domains,
-		PathMatchType:  mcpserver.PrefixMatchType,
-		PathMatchValue: pathMatchValue,
-		UpstreamType:   protocolUpstreamTypeMapping[protocol],
-	}
-	if mcpServerRewriteProtocols[protocol] {
-		mcpServer.EnablePathRewrite = true
-		mcpServer.PathRewritePrefix = "/"
-	}
-
-	mcpServerLog.Debugf("construct mcpserver %v", mcpServer)
-
-	return &config.Config{
-		Meta: config.Meta{
-			GroupVersionKind: mcpserver.GvkMcpServer,
-			Name:             name,
-			Namespace:        w.namespace,
-		},
-		Spec: mcpServer,
-	}
-}
-
-func generateDrForMcpServer(host, protocol string) *v1alpha3.DestinationRule {
-	switch protocol {
-	case provider.McpSSEProtocol:
-		return &v1alpha3.DestinationRule{
-			Host: host,
-			TrafficPolicy: &v1alpha3.TrafficPolicy{
-				LoadBalancer: &v1alpha3.LoadBalancerSettings{
-					LbPolicy: &v1alpha3.LoadBalancerSettings_ConsistentHash{
-						ConsistentHash: &v1alpha3.LoadBalancerSettings_ConsistentHashLB{
-							HashKey: &v1alpha3.LoadBalancerSettings_ConsistentHashLB_UseSourceIp{
-								UseSourceIp: true,
-							},
-						},
-					},
-				},
-			},
-		}
-	case provider.HttpsProtocol:
-		return &v1alpha3.DestinationRule{
-			Host: host,
-			TrafficPolicy: &v1alpha3.TrafficPolicy{
-				Tls: &v1alpha3.ClientTLSSettings{
-					Mode: v1alpha3.ClientTLSSettings_SIMPLE,
-				},
-			},
-		}
-	}
-	return nil
-}
-
-func parseMcpArgs(args interface{}) (*provider.ToolArgs, error) {
-	argsData, err := json.Marshal(args)
-	if err != nil {
-		return nil, err
-	}
-	toolArgs := &provider.ToolArgs{}
-	if err = json.Unmarshal(argsData, toolArgs); err != nil {
-		return nil, err
-	}
-	return toolArgs, nil
-}
-
-func getArgsPositionFromToolMeta(toolMeta *provider.ToolsMeta) (map[string]string, error) {
-	result := map[string]string{}
-	if toolMeta == nil {
-		return result, nil
-	}
-	toolTemplate := toolMeta.Templates
-	for kind, meta := range toolTemplate {
-		switch kind {
-		case provider.JsonGoTemplateType:
-			templateData, err := json.Marshal(meta)
-			if err != nil {
-				return result, err
-			}
-			template := &provider.JsonGoTemplate{}
-			if err = json.Unmarshal(templateData, template); err != nil {
-				return result, err
-			}
-			result = mergeMaps(result, template.ArgsPosition)
-		default:
-			return result, fmt.Errorf("unsupport tool meta type %v", kind)
-		}
-	}
-	return result, nil
-}
-
-func getRequestTemplateFromToolMeta(toolMeta *provider.ToolsMeta) (*provider.RequestTemplate, error) {
-	if toolMeta == nil {
-		return nil, nil
-	}
-	toolTemplate := toolMeta.Templates
-	for kind, meta := range toolTemplate {
-		switch kind {
-		case provider.JsonGoTemplateType:
-			templateData, err := json.Marshal(meta)
-			if err != nil {
-				return nil, err
-			}
-			template := &provider.JsonGoTemplate{}
-			if err = json.Unmarshal(templateData, template); err != nil {
-				return nil, err
-			}
-			return &template.RequestTemplate, nil
-		default:
-			return nil, fmt.Errorf("unsupport tool meta type")
-		}
-	}
-	return nil, nil
-}
-
-func getResponseTemplateFromToolMeta(toolMeta *provider.ToolsMeta) (*provider.ResponseTemplate, string, error) {
-	if toolMeta == nil {
-		return nil, "", nil
-	}
-	toolTemplate := toolMeta.Templates
-	for kind, meta := range toolTemplate {
-		switch kind {
-		case provider.JsonGoTemplateType:
-			templateData, err := json.Marshal(meta)
-			if err != nil {
-				return nil, "", err
-			}
-			template := &provider.JsonGoTemplate{}
-			if err = json.Unmarshal(templateData, template); err != nil {
-				return nil, "", err
-			}
-			return &template.ResponseTemplate, template.ErrorResponseTemplate, nil
-		default:
-			return nil, "", fmt.Errorf("unsupported tool meta type: %s", kind)
-		}
-	}
-	return nil, "", nil
-}
-
-func getSecurityFromToolMeta(toolMeta *provider.ToolsMeta) (*provider.ToolSecurity, error) {
-	if toolMeta == nil {
-		return nil, nil
-	}
-	toolTemplate := toolMeta.Templates
-	for kind, meta := range toolTemplate {
-		switch kind {
-		case provider.JsonGoTemplateType:
-			templateData, err := json.Marshal(meta)
-			if err != nil {
-				return nil, err
-			}
-			template := &provider.JsonGoTemplate{}
-			if err = json.Unmarshal(templateData, template); err != nil {
-				return nil, err
-			}
-			return template.Security, nil
-		default:
-			return nil, fmt.Errorf("unsupported tool meta type: %s", kind)
-		}
-	}
-	return nil, nil
-}
-
-func mergeMaps(maps ...map[string]string) map[string]string {
-	if len(maps) == 0 {
-		return nil
-	}
-	res := make(map[string]string, len(maps[0]))
-	for _, m := range maps {
-		for k, v := range m {
-			res[k] = v
-		}
-	}
-	return res
+	return mcp.BuildMcpServer(w.exportOptions(), name, server.Protocol, vs)
 }
 
 func getServiceFullHostFromMcpServer(server *provider.McpServer) string {
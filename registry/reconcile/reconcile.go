@@ -34,6 +34,7 @@ import (
 	"github.com/alibaba/higress/v2/registry/consul"
 	"github.com/alibaba/higress/v2/registry/direct"
 	"github.com/alibaba/higress/v2/registry/eureka"
+	"github.com/alibaba/higress/v2/registry/healthcheck"
 	"github.com/alibaba/higress/v2/registry/memory"
 	"github.com/alibaba/higress/v2/registry/nacos"
 	nacosv2 "github.com/alibaba/higress/v2/registry/nacos/v2"
@@ -54,18 +55,27 @@ type Reconciler struct {
 	client        kube.Client
 	namespace     string
 	clusterId     string
+
+	healthCheckMutex sync.RWMutex
+	healthCheckers   map[string]*registryHealthChecker
+}
+
+type registryHealthChecker struct {
+	config  *apiv1.RegistryConfig_HealthCheck
+	checker *healthcheck.Checker
 }
 
 func NewReconciler(serviceUpdate func(), client kube.Client, namespace, clusterId string) *Reconciler {
 	return &Reconciler{
-		Cache:         memory.NewCache(),
-		registries:    make(map[string]*apiv1.RegistryConfig),
-		proxies:       make(map[string]*apiv1.ProxyConfig),
-		watchers:      make(map[string]Watcher),
-		serviceUpdate: serviceUpdate,
-		client:        client,
-		namespace:     namespace,
-		clusterId:     clusterId,
+		Cache:          memory.NewCache(),
+		registries:     make(map[string]*apiv1.RegistryConfig),
+		proxies:        make(map[string]*apiv1.ProxyConfig),
+		watchers:       make(map[string]Watcher),
+		serviceUpdate:  serviceUpdate,
+		healthCheckers: make(map[string]*registryHealthChecker),
+		client:         client,
+		namespace:      namespace,
+		clusterId:      clusterId,
 	}
 }
 
@@ -135,11 +145,13 @@ func (r *Reconciler) reconcileRegistries(registries []*apiv1.RegistryConfig) err
 		len(toBeCreated), len(toBeUpdated), len(toBeDeleted))
 	for k := range toBeDeleted {
 		r.watchers[k].Stop()
+		r.stopHealthChecker(k)
 		delete(r.registries, k)
 		delete(r.watchers, k)
 	}
 	for k, v := range toBeUpdated {
 		r.watchers[k].Stop()
+		r.stopHealthChecker(k)
 		delete(r.registries, k)
 		delete(r.watchers, k)
 		watcher, err := r.generateWatcherFromRegistryConfig(v, &wg)
@@ -152,6 +164,7 @@ func (r *Reconciler) reconcileRegistries(registries []*apiv1.RegistryConfig) err
 		go watcher.Run()
 		r.watchers[k] = watcher
 		r.registries[k] = v
+		r.startHealthChecker(k, v)
 	}
 	for k, v := range toBeCreated {
 		watcher, err := r.generateWatcherFromRegistryConfig(v, &wg)
@@ -164,6 +177,7 @@ func (r *Reconciler) reconcileRegistries(registries []*apiv1.RegistryConfig) err
 		go watcher.Run()
 		r.watchers[k] = watcher
 		r.registries[k] = v
+		r.startHealthChecker(k, v)
 	}
 	if errHappened {
 		return errors.New("ReconcileRegistries failed, Init Watchers failed")
@@ -249,6 +263,13 @@ func (r *Reconciler) generateWatcherFromRegistryConfig(registry *apiv1.RegistryC
 			consul.WithServiceTag(registry.ConsulServiceTag),
 			consul.WithRefreshInterval(registry.ConsulRefreshInterval),
 			consul.WithAuthOption(authOption),
+			consul.WithEnableMcpServer(registry.EnableMCPServer),
+			consul.WithMcpExportDomains(registry.McpServerExportDomains),
+			consul.WithMcpBaseUrl(registry.McpServerBaseUrl),
+			consul.WithEnableScopeMcpServers(registry.EnableScopeMcpServers),
+			consul.WithAllowMcpServers(registry.AllowMcpServers),
+			consul.WithClusterId(r.clusterId),
+			consul.WithNamespace(r.namespace),
 		)
 	case string(Static), string(DNS):
 		watcher, err = direct.NewWatcher(
@@ -269,6 +290,13 @@ func (r *Reconciler) generateWatcherFromRegistryConfig(registry *apiv1.RegistryC
 			eureka.WithType(registry.Type),
 			eureka.WithPort(registry.Port),
 			eureka.WithVport(registry.Vport),
+			eureka.WithEnableMcpServer(registry.EnableMCPServer),
+			eureka.WithMcpExportDomains(registry.McpServerExportDomains),
+			eureka.WithMcpBaseUrl(registry.McpServerBaseUrl),
+			eureka.WithEnableScopeMcpServers(registry.EnableScopeMcpServers),
+			eureka.WithAllowMcpServers(registry.AllowMcpServers),
+			eureka.WithClusterId(r.clusterId),
+			eureka.WithNamespace(r.namespace),
 		)
 	default:
 		return nil, errors.New("unsupported registry type:" + registry.Type)
@@ -389,11 +417,66 @@ func (r *Reconciler) GetMcpServers() []*higressmcpserver.McpServer {
 	return servers
 }
 
+func (r *Reconciler) startHealthChecker(key string, registry *apiv1.RegistryConfig) {
+	if registry.HealthCheck == nil {
+		return
+	}
+	if err := healthcheck.Validate(registry.HealthCheck); err != nil {
+		log.Errorf("Invalid health check of registry %s, health checking is disabled, err:%v", key, err)
+		return
+	}
+	config := healthcheck.Normalize(registry.HealthCheck)
+	registryType, registryName := registry.Type, registry.Name
+	checker := healthcheck.NewChecker(config, func() []healthcheck.Target {
+		return healthcheck.TargetsOf(r.Cache.GetServiceEntriesByRegistry(registryType, registryName))
+	})
+	go checker.Run()
+
+	r.healthCheckMutex.Lock()
+	defer r.healthCheckMutex.Unlock()
+	r.healthCheckers[key] = &registryHealthChecker{
+		config:  config,
+		checker: checker,
+	}
+}
+
+func (r *Reconciler) stopHealthChecker(key string) {
+	r.healthCheckMutex.Lock()
+	defer r.healthCheckMutex.Unlock()
+	if healthChecker, ok := r.healthCheckers[key]; ok {
+		healthChecker.checker.Stop()
+		delete(r.healthCheckers, key)
+	}
+}
+
+// GetRegistryHealthChecks returns the normalized health check configs keyed by registry type and name, which are
+// rendered as the cluster health checks of the services discovered by the registries.
+func (r *Reconciler) GetRegistryHealthChecks() map[string]*apiv1.RegistryConfig_HealthCheck {
+	r.healthCheckMutex.RLock()
+	defer r.healthCheckMutex.RUnlock()
+	healthChecks := make(map[string]*apiv1.RegistryConfig_HealthCheck, len(r.healthCheckers))
+	for key, healthChecker := range r.healthCheckers {
+		healthChecks[key] = healthChecker.config
+	}
+	return healthChecks
+}
+
+func (r *Reconciler) getHealthCheckStatus(key string) *healthcheck.Status {
+	r.healthCheckMutex.RLock()
+	defer r.healthCheckMutex.RUnlock()
+	if healthChecker, ok := r.healthCheckers[key]; ok {
+		return healthChecker.checker.Status()
+	}
+	return nil
+}
+
 type RegistryWatcherStatus struct {
 	Name    string `json:"name"`
 	Type    string `json:"type"`
 	Healthy bool   `json:"healthy"`
 	Ready   bool   `json:"ready"`
+	// HealthCheck is the endpoint reachability probed by the controller, see healthcheck.Status
+	HealthCheck *healthcheck.Status `json:"healthCheck,omitempty"`
 }
 
 func (r *Reconciler) GetRegistryWatcherStatusList() []RegistryWatcherStatus {
@@ -401,10 +484,11 @@ func (r *Reconciler) GetRegistryWatcherStatusList() []RegistryWatcherStatus {
 	for key, watcher := range r.watchers {
 		_, name := path.Split(key)
 		registryStatus := RegistryWatcherStatus{
-			Name:    name,
-			Type:    watcher.GetRegistryType(),
-			Healthy: watcher.IsHealthy(),
-			Ready:   watcher.IsReady(),
+			Name:        name,
+			Type:        watcher.GetRegistryType(),
+			Healthy:     watcher.IsHealthy(),
+			Ready:       watcher.IsReady(),
+			HealthCheck: r.getHealthCheckStatus(key),
 		}
 		registryStatusList = append(registryStatusList, registryStatus)
 	}